@@ -29,6 +29,7 @@ pSrc=plugin/builtin
 
 _builtinplugins = \
 	AnnotationsTransformer.go \
+	ApplySettersTransformer.go \
 	ConfigMapGenerator.go \
 	IAMPolicyGenerator.go \
 	HashTransformer.go \
@@ -58,6 +59,7 @@ builtinplugins = $(patsubst %,$(pGen)/%,$(_builtinplugins))
 # is modified, the corresponding generated file, and only
 # that file, will be recreated.
 $(pGen)/AnnotationsTransformer.go: $(pSrc)/annotationstransformer/AnnotationsTransformer.go
+$(pGen)/ApplySettersTransformer.go: $(pSrc)/applysetterstransformer/ApplySettersTransformer.go
 $(pGen)/ConfigMapGenerator.go: $(pSrc)/configmapgenerator/ConfigMapGenerator.go
 $(pGen)/GkeSaGenerator.go: $(pSrc)/gkesagenerator/GkeSaGenerator.go
 $(pGen)/HashTransformer.go: $(pSrc)/hashtransformer/HashTransformer.go
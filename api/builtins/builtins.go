@@ -10,6 +10,7 @@ import (
 
 type (
 	AnnotationsTransformerPlugin         = internal.AnnotationsTransformerPlugin
+	ApplySettersTransformerPlugin        = internal.ApplySettersTransformerPlugin
 	ConfigMapGeneratorPlugin             = internal.ConfigMapGeneratorPlugin
 	HashTransformerPlugin                = internal.HashTransformerPlugin
 	HelmChartInflationGeneratorPlugin    = internal.HelmChartInflationGeneratorPlugin
@@ -30,6 +31,7 @@ type (
 
 var (
 	NewAnnotationsTransformerPlugin         = internal.NewAnnotationsTransformerPlugin
+	NewApplySettersTransformerPlugin        = internal.NewApplySettersTransformerPlugin
 	NewConfigMapGeneratorPlugin             = internal.NewConfigMapGeneratorPlugin
 	NewHashTransformerPlugin                = internal.NewHashTransformerPlugin
 	NewHelmChartInflationGeneratorPlugin    = internal.NewHelmChartInflationGeneratorPlugin
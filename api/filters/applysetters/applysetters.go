@@ -0,0 +1,149 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package applysetters
+
+import (
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// SetterCommentPrefix is the prefix of the comments marking the fields set by setters,
+// e.g. `image: nginx:1.21 # kustomize-set: ${image}:${tag}`.
+const SetterCommentPrefix = "kustomize-set:"
+
+// KptSetterCommentPrefix is the prefix of the comments marking the fields set by
+// setters in packages written for kpt apply-setters, which are also applied.
+const KptSetterCommentPrefix = "kpt-set:"
+
+// setterReference matches the references to setters in a setter comment.
+var setterReference = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// Filter sets the fields marked with setter comments to the values of the setters.
+//
+// The setter comment of a scalar field is a pattern of the field value, in which
+// references to setters, e.g. ${tag}, are replaced with the values of the setters.
+// The setter comment of a sequence field references a single setter whose value
+// is a YAML sequence, e.g. "[a, b]", which replaces the elements of the field.
+// Fields which reference setters without a value are not changed.
+type Filter struct {
+	// Setters are the values of the setters by name.
+	Setters map[string]string `json:"setters,omitempty" yaml:"setters,omitempty"`
+}
+
+var _ kio.Filter = Filter{}
+
+func (f Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return kio.FilterAll(yaml.FilterFunc(f.run)).Filter(nodes)
+}
+
+func (f Filter) run(node *yaml.RNode) (*yaml.RNode, error) {
+	return node, f.visit(node.YNode())
+}
+
+// visit applies the setters to the fields of n.
+func (f Filter) visit(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			if err := f.visit(c); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if err := f.apply(value, key.LineComment, value.LineComment); err != nil {
+				return errors.WrapPrefixf(err, "field %s", key.Value)
+			}
+			if err := f.visit(value); err != nil {
+				return errors.WrapPrefixf(err, "field %s", key.Value)
+			}
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			if c.Kind == yaml.ScalarNode {
+				if err := f.apply(c, c.LineComment); err != nil {
+					return err
+				}
+			} else if err := f.visit(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// apply sets the value of the field n if one of its comments is a setter comment.
+func (f Filter) apply(n *yaml.Node, comments ...string) error {
+	pattern, found := setterPattern(comments...)
+	if !found {
+		return nil
+	}
+	switch n.Kind {
+	case yaml.ScalarNode:
+		value, ok := f.substitute(pattern)
+		if !ok {
+			return nil
+		}
+		n.Value = value
+		if n.ShortTag() != yaml.NodeTagString {
+			// let the value determine the type of the field
+			n.Tag = ""
+		} else if n.Style == 0 && yaml.IsYaml1_1NonString(n) {
+			// keep string fields strings for YAML 1.1 parsers
+			n.Style = yaml.DoubleQuotedStyle
+		}
+		return nil
+	case yaml.SequenceNode:
+		name := setterReference.FindStringSubmatch(pattern)
+		if name == nil || name[0] != strings.TrimSpace(pattern) {
+			return errors.Errorf(
+				"setter comment of a sequence must reference a single setter: %q", pattern)
+		}
+		value, ok := f.Setters[name[1]]
+		if !ok {
+			return nil
+		}
+		elements, err := yaml.Parse(value)
+		if err != nil {
+			return errors.WrapPrefixf(err, "setter %s", name[1])
+		}
+		if elements.YNode().Kind != yaml.SequenceNode {
+			return errors.Errorf("setter %s of a sequence must be a sequence: %q", name[1], value)
+		}
+		n.Content = elements.YNode().Content
+		return nil
+	default:
+		return errors.Errorf("setters can only set scalars and sequences")
+	}
+}
+
+// substitute replaces the references to setters in the pattern with their values.
+// It returns false if a setter has no value.
+func (f Filter) substitute(pattern string) (string, bool) {
+	ok := true
+	value := setterReference.ReplaceAllStringFunc(pattern, func(ref string) string {
+		v, found := f.Setters[setterReference.FindStringSubmatch(ref)[1]]
+		ok = ok && found
+		return v
+	})
+	return value, ok
+}
+
+// setterPattern returns the pattern of the setter comment among the comments.
+func setterPattern(comments ...string) (string, bool) {
+	for _, c := range comments {
+		c = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(c), "#"))
+		for _, prefix := range []string{SetterCommentPrefix, KptSetterCommentPrefix} {
+			if strings.HasPrefix(c, prefix) {
+				return strings.TrimSpace(strings.TrimPrefix(c, prefix)), true
+			}
+		}
+	}
+	return "", false
+}
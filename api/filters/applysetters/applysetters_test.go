@@ -0,0 +1,152 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package applysetters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	filtertest "sigs.k8s.io/kustomize/api/testutils/filtertest"
+)
+
+func TestFilter(t *testing.T) {
+	testCases := map[string]struct {
+		input          string
+		expectedOutput string
+		setters        map[string]string
+		expectedError  string
+	}{
+		"scalars": {
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app # kustomize-set: ${app}
+spec:
+  replicas: 1 # kustomize-set: ${replicas}
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.0 # kustomize-set: ${image}:${tag}
+        args:
+        - --debug=false # kpt-set: --debug=${debug}
+      - name: sidecar
+        image: sidecar # kustomize-set: ${sidecar}
+`,
+			setters: map[string]string{
+				"app": "web", "replicas": "3", "image": "nginx", "tag": "1.21", "debug": "true",
+			},
+			expectedOutput: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web # kustomize-set: ${app}
+spec:
+  replicas: 3 # kustomize-set: ${replicas}
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.21 # kustomize-set: ${image}:${tag}
+        args:
+        - --debug=true # kpt-set: --debug=${debug}
+      - name: sidecar
+        image: sidecar # kustomize-set: ${sidecar}
+`,
+		},
+		"types": {
+			input: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  mode: "0644" # kustomize-set: ${mode}
+  enabled: "true" # kustomize-set: ${enabled}
+  flag: a # kustomize-set: ${flag}
+  count: 1 # kustomize-set: ${count}
+`,
+			setters: map[string]string{"mode": "0755", "enabled": "false", "flag": "on", "count": "two"},
+			expectedOutput: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+data:
+  mode: "0755" # kustomize-set: ${mode}
+  enabled: "false" # kustomize-set: ${enabled}
+  flag: "on" # kustomize-set: ${flag}
+  count: two # kustomize-set: ${count}
+`,
+		},
+		"sequences": {
+			input: `
+apiVersion: v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  block: # kustomize-set: ${block}
+  - a
+  flow: [a] # kustomize-set: ${flow}
+`,
+			setters: map[string]string{"block": "[b, c]", "flow": "- d\n- e"},
+			expectedOutput: `
+apiVersion: v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  block: # kustomize-set: ${block}
+  - b
+  - c
+  flow: [d, e] # kustomize-set: ${flow}
+`,
+		},
+		"sequence setter not a sequence": {
+			input: `
+apiVersion: v1
+kind: Foo
+metadata:
+  name: foo
+spec:
+  list: # kustomize-set: ${list}
+  - a
+`,
+			setters:       map[string]string{"list": "b"},
+			expectedError: `field spec: field list: setter list of a sequence must be a sequence: "b"`,
+		},
+		"mapping": {
+			input: `
+apiVersion: v1
+kind: Foo
+metadata:
+  name: foo
+spec: # kustomize-set: ${spec}
+  a: b
+`,
+			setters:       map[string]string{"spec": "b"},
+			expectedError: "field spec: setters can only set scalars and sequences",
+		},
+	}
+
+	for name := range testCases {
+		tc := testCases[name]
+		t.Run(name, func(t *testing.T) {
+			f := Filter{Setters: tc.setters}
+			if tc.expectedError != "" {
+				_, err := filtertest.RunFilterE(t, tc.input, f)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			assert.Equal(t,
+				strings.TrimSpace(tc.expectedOutput),
+				strings.TrimSpace(filtertest.RunFilter(t, tc.input, f)))
+		})
+	}
+}
@@ -0,0 +1,31 @@
+// Code generated by pluginator on ApplySettersTransformer; DO NOT EDIT.
+// pluginator {(devel)  unknown   }
+
+package builtins
+
+import (
+	"sigs.k8s.io/kustomize/api/filters/applysetters"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/yaml"
+)
+
+// Set the fields marked with setter comments, e.g.
+// `image: nginx:1.21 # kustomize-set: ${image}:${tag}`,
+// to the values of the setters in the data of the config.
+type ApplySettersTransformerPlugin struct {
+	Data map[string]string `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+func (p *ApplySettersTransformerPlugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Data = nil
+	return yaml.Unmarshal(c, p)
+}
+
+func (p *ApplySettersTransformerPlugin) Transform(m resmap.ResMap) error {
+	return m.ApplyFilter(applysetters.Filter{Setters: p.Data})
+}
+
+func NewApplySettersTransformerPlugin() resmap.TransformerPlugin {
+	return &ApplySettersTransformerPlugin{}
+}
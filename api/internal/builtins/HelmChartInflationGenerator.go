@@ -57,6 +57,13 @@ func (p *HelmChartInflationGeneratorPlugin) Config(
 	if err = yaml.Unmarshal(config, p); err != nil {
 		return
 	}
+	// the charts may target a cluster version given on the command line
+	if p.KubeVersion == "" {
+		p.KubeVersion = h.GeneralConfig().HelmConfig.KubeVersion
+	}
+	if len(p.ApiVersions) == 0 {
+		p.ApiVersions = h.GeneralConfig().HelmConfig.ApiVersions
+	}
 	return p.validateArgs()
 }
 
@@ -149,6 +156,7 @@ func (p *HelmChartInflationGeneratorPlugin) runHelmCommand(
 		fmt.Sprintf("HELM_CONFIG_HOME=%s", p.ConfigHome),
 		fmt.Sprintf("HELM_CACHE_HOME=%s/.cache", p.ConfigHome),
 		fmt.Sprintf("HELM_DATA_HOME=%s/.data", p.ConfigHome)}
+	env = append(env, p.h.GeneralConfig().HelmConfig.HTTP.ProxyEnv()...)
 	cmd.Env = append(os.Environ(), env...)
 	err := cmd.Run()
 	if err != nil {
@@ -236,7 +244,11 @@ func (p *HelmChartInflationGeneratorPlugin) Generate() (rm resmap.ResMap, err er
 			return nil, fmt.Errorf(
 				"no repo specified for pull, no chart found at '%s'", path)
 		}
-		if _, err := p.runHelmCommand(p.pullCommand()); err != nil {
+		args, err := p.pullCommand()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.runHelmCommand(args); err != nil {
 			return nil, err
 		}
 	}
@@ -276,7 +288,7 @@ func (p *HelmChartInflationGeneratorPlugin) Generate() (rm resmap.ResMap, err er
 	return nil, fmt.Errorf("could not parse bytes into resource map: %w", resMapErr)
 }
 
-func (p *HelmChartInflationGeneratorPlugin) pullCommand() []string {
+func (p *HelmChartInflationGeneratorPlugin) pullCommand() ([]string, error) {
 	args := []string{
 		"pull",
 		"--untar",
@@ -286,7 +298,18 @@ func (p *HelmChartInflationGeneratorPlugin) pullCommand() []string {
 	if p.Version != "" {
 		args = append(args, "--version", p.Version)
 	}
-	return args
+	httpConfig := p.h.GeneralConfig().HelmConfig.HTTP
+	if len(httpConfig.CAFiles) > 0 {
+		if err := p.establishTmpDir(); err != nil {
+			return nil, errors.WrapPrefixf(err, "unable to create tmp dir for the CA bundle")
+		}
+		caFile, err := httpConfig.WriteCABundle(p.tmpDir)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--ca-file", caFile)
+	}
+	return args, nil
 }
 
 // chartExistsLocally will return true if the chart does exist in
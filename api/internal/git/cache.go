@@ -0,0 +1,155 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/copyutil"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// supersededCloneGracePeriod is how long a clone superseded by a fresh
+// clone of its ref is kept after it was last used, for the builds which
+// may still be using it.
+const supersededCloneGracePeriod = time.Hour
+
+// commitHashPattern matches the sha1 and sha256 hashes of commits.
+var commitHashPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// IsCommitHash returns true if ref is the full hash of a commit, the only
+// ref which can't be moved to other content.
+func IsCommitHash(ref string) bool {
+	return commitHashPattern.MatchString(ref)
+}
+
+// CachingCloner returns a cloner which clones the repos with cloner into
+// cacheDir, keyed by their url, ref and checkout options, and reuses the
+// clones of earlier builds.  The clones of commit hashes are reused
+// forever, those of other refs, e.g. branches or tags, which may be moved,
+// until they're older than ttl.  The clones in cacheDir are never cleaned
+// up by their loaders; those superseded by fresh clones are removed once
+// they haven't been used for supersededCloneGracePeriod, as other builds
+// sharing cacheDir may still be using them.
+func CachingCloner(cloner Cloner, cacheDir string, ttl time.Duration) Cloner {
+	return func(repoSpec *RepoSpec) error {
+		dir := filepath.Join(cacheDir, cacheKey(repoSpec))
+		entries := cachedClones(dir)
+		if len(entries) > 0 {
+			newest := entries[len(entries)-1]
+			if IsCommitHash(repoSpec.Ref) || time.Since(newest.fetched) < ttl {
+				markUsed(newest.path)
+				repoSpec.Dir = filesys.ConfirmedDir(newest.path)
+				repoSpec.Commit = headCommit(repoSpec.Dir, repoSpec.Timeout)
+				repoSpec.cached = true
+				return nil
+			}
+		}
+		if err := cloner(repoSpec); err != nil {
+			return err
+		}
+		entry := filepath.Join(dir, strconv.FormatInt(time.Now().UnixNano(), 10))
+		if err := moveClone(repoSpec.Dir.String(), entry); err != nil {
+			// the clone is still usable, it's only not cached
+			log.Printf("Warning: unable to cache the clone of %s: %v", repoSpec.Raw(), err)
+			return nil
+		}
+		markUsed(entry)
+		repoSpec.Dir = filesys.ConfirmedDir(entry)
+		repoSpec.cached = true
+		removeUnusedClones(entries, supersededCloneGracePeriod)
+		return nil
+	}
+}
+
+// markUsed sets the modification time of the clone at path to now, which
+// keeps it from being removed by removeUnusedClones once superseded.
+func markUsed(path string) {
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+}
+
+// removeUnusedClones removes the superseded clones which haven't been
+// used for gracePeriod.  The others are left to the removals of later
+// fresh clones.
+func removeUnusedClones(superseded []cachedClone, gracePeriod time.Duration) {
+	for _, c := range superseded {
+		info, err := os.Stat(c.path)
+		if err != nil || time.Since(info.ModTime()) < gracePeriod {
+			continue
+		}
+		_ = os.RemoveAll(c.path)
+	}
+}
+
+// cacheKey returns the name of the directory of the clones of repoSpec in
+// a cache directory.
+func cacheKey(repoSpec *RepoSpec) string {
+	parts := []string{repoSpec.CloneSpec(), repoSpec.Ref, strconv.FormatBool(repoSpec.Submodules)}
+	if repoSpec.Fetch != (types.GitFetchConfig{}) {
+		parts = append(parts, "fetch", strconv.Itoa(repoSpec.Fetch.Depth),
+			strconv.FormatBool(repoSpec.Fetch.AllBranches), strconv.FormatBool(repoSpec.Fetch.Tags))
+	}
+	if repoSpec.Sparse {
+		parts = append(append(parts, "sparse"), repoSpec.sparseCheckoutPaths()...)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedClone is a clone in a cache directory.
+type cachedClone struct {
+	path    string
+	fetched time.Time
+}
+
+// cachedClones returns the clones in dir, from the oldest to the newest.
+func cachedClones(dir string) []cachedClone {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var clones []cachedClone
+	for _, f := range files {
+		nanos, err := strconv.ParseInt(f.Name(), 10, 64)
+		if err != nil || !f.IsDir() {
+			continue
+		}
+		clones = append(clones, cachedClone{path: filepath.Join(dir, f.Name()), fetched: time.Unix(0, nanos)})
+	}
+	sort.Slice(clones, func(i, j int) bool { return clones[i].fetched.Before(clones[j].fetched) })
+	return clones
+}
+
+// moveClone moves the clone at src to dst, copying it if they're on
+// different file systems.
+func moveClone(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	// copy to a temporary directory first, so that dst is never partial
+	tmp := dst + ".tmp"
+	if err := copyutil.CopyDir(filesys.MakeFsOnDisk(), src, tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	return os.RemoveAll(src)
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestIsCommitHash(t *testing.T) {
+	for ref, expected := range map[string]bool{
+		"v1.2.3": false,
+		"a428de44a9059f31a59237a5881c2d2cffa93757": true,
+		"a428de44": false,
+		"A428DE44A9059F31A59237A5881C2D2CFFA93757": false,
+		strings.Repeat("0", 64):                    true,
+	} {
+		assert.Equal(t, expected, IsCommitHash(ref), ref)
+	}
+}
+
+func TestCachingCloner(t *testing.T) {
+	cacheDir := t.TempDir()
+	var clones int
+	// fakeCloner clones the repo into a temporary directory, like
+	// ClonerUsingGitExec, holding the number of the clone.
+	fakeCloner := func(repoSpec *RepoSpec) error {
+		clones++
+		dir, err := filesys.NewTmpConfirmedDir()
+		if err != nil {
+			return err
+		}
+		repoSpec.Dir = dir
+		return os.WriteFile(dir.Join("clone"), []byte{byte('0' + clones)}, 0o600)
+	}
+	clone := func(url string, ttl time.Duration) string {
+		t.Helper()
+		repoSpec, err := NewRepoSpecFromURL(url)
+		require.NoError(t, err)
+		require.NoError(t, CachingCloner(fakeCloner, cacheDir, ttl)(repoSpec))
+		require.True(t, repoSpec.Dir.HasPrefix(filesys.ConfirmedDir(cacheDir)))
+		// the loaders don't clean up the clones in the cache
+		require.NoError(t, repoSpec.Cleaner(filesys.MakeFsOnDisk())())
+		content, err := os.ReadFile(repoSpec.Dir.Join("clone"))
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	const commit = "a428de44a9059f31a59237a5881c2d2cffa93757"
+	// the clones of commit hashes are reused regardless of their age
+	assert.Equal(t, "1", clone("https://github.com/org/repo//base?ref="+commit, 0))
+	assert.Equal(t, "1", clone("https://github.com/org/repo//overlay?ref="+commit, 0))
+	assert.Equal(t, "2", clone("https://github.com/org/repo?ref="+commit+"&sparse=true", 0))
+
+	// those of branches and tags, which may be moved, until they're older
+	// than the ttl
+	assert.Equal(t, "3", clone("https://github.com/org/repo?ref=main", time.Hour))
+	assert.Equal(t, "3", clone("https://github.com/org/repo?ref=main", time.Hour))
+	assert.Equal(t, "4", clone("https://github.com/org/repo?ref=main", 0))
+	assert.Equal(t, "4", clone("https://github.com/org/repo?ref=main", time.Hour))
+
+	// clones fetching more aren't shared with those fetching less
+	assert.Equal(t, "5", clone("https://github.com/org/repo?ref=main&depth=-1", time.Hour))
+	assert.Equal(t, "5", clone("https://github.com/org/repo?depth=-1&ref=main", time.Hour))
+
+	// version tags may be moved too
+	assert.Equal(t, "6", clone("https://github.com/org/repo?ref=v1.0.0", 0))
+	assert.Equal(t, "7", clone("https://github.com/org/repo?ref=v1.0.0", 0))
+
+	// the superseded clone is kept, as other builds may still use it
+	repoSpec, err := NewRepoSpecFromURL("https://github.com/org/repo?ref=main")
+	require.NoError(t, err)
+	dir := filepath.Join(cacheDir, cacheKey(repoSpec))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// until it hasn't been used for the grace period
+	old := time.Now().Add(-supersededCloneGracePeriod)
+	for _, e := range entries {
+		require.NoError(t, os.Chtimes(filepath.Join(dir, e.Name()), old, old))
+	}
+	assert.Equal(t, "8", clone("https://github.com/org/repo?ref=main", 0))
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestRemoveUnusedClones(t *testing.T) {
+	dir := t.TempDir()
+	var clones []cachedClone
+	for i, age := range []time.Duration{2 * time.Hour, time.Minute} {
+		path := filepath.Join(dir, strconv.Itoa(i))
+		require.NoError(t, os.Mkdir(path, 0o700))
+		used := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(path, used, used))
+		clones = append(clones, cachedClone{path: path})
+	}
+	removeUnusedClones(clones, time.Hour)
+	assert.NoDirExists(t, clones[0].path)
+	assert.DirExists(t, clones[1].path)
+}
@@ -4,20 +4,35 @@
 package git
 
 import (
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 // Cloner is a function that can clone a git repo.
 type Cloner func(repoSpec *RepoSpec) error
 
+// allBranchesRefspec fetches all the branches of a repo as the branches
+// of its origin remote.  They're fetched before the ref, which the
+// FETCH_HEAD checked out must be.
+const allBranchesRefspec = "+refs/heads/*:refs/remotes/origin/*"
+
 // ClonerUsingGitExec uses a local git install, as opposed
 // to say, some remote API, to obtain a local clone of
 // a remote repo.
 func ClonerUsingGitExec(repoSpec *RepoSpec) error {
+	return cloneUsingGitExec(repoSpec, nil)
+}
+
+// cloneUsingGitExec clones the repo of repoSpec with git commands run
+// with the additional environment variables env.
+func cloneUsingGitExec(repoSpec *RepoSpec, env []string) error {
 	r, err := newCmdRunner(repoSpec.Timeout)
 	if err != nil {
 		return err
 	}
+	r.env = env
 	repoSpec.Dir = r.dir
 	if err = r.run("init"); err != nil {
 		return err
@@ -30,20 +45,60 @@ func ClonerUsingGitExec(repoSpec *RepoSpec) error {
 	if repoSpec.Ref != "" {
 		ref = repoSpec.Ref
 	}
+	if repoSpec.Sparse {
+		return sparseCloneUsingGitExec(r, repoSpec, ref)
+	}
+	if repoSpec.Fetch.AllBranches {
+		if err = r.run(append(append([]string{"fetch"}, repoSpec.fetchArgs()...),
+			"origin", allBranchesRefspec)...); err != nil {
+			return err
+		}
+	}
 	// we use repoSpec.CloneSpec() instead of origin because on error,
 	// the prior prints the actual repo url for the user.
-	if err = r.run("fetch", "--depth=1", repoSpec.CloneSpec(), ref); err != nil {
+	if err = r.run(append(append([]string{"fetch"}, repoSpec.fetchArgs()...),
+		repoSpec.CloneSpec(), ref)...); err != nil {
 		return err
 	}
 	if err = r.run("checkout", "FETCH_HEAD"); err != nil {
 		return err
 	}
+	repoSpec.Commit = headCommit(r.dir, repoSpec.Timeout)
 	if repoSpec.Submodules {
 		return r.run("submodule", "update", "--init", "--recursive")
 	}
 	return nil
 }
 
+// sparseCloneUsingGitExec checks out only the sparse checkout paths of
+// repoSpec, fetching only their files if the server supports it, and
+// initializes only their submodules.
+func sparseCloneUsingGitExec(r *gitRunner, repoSpec *RepoSpec, ref string) error {
+	paths := repoSpec.sparseCheckoutPaths()
+	if err := r.run(append([]string{"sparse-checkout", "set", "--cone"}, paths...)...); err != nil {
+		return err
+	}
+	// a filter can only be used with the configured remote; the files
+	// outside of the paths are never fetched.
+	args := append(append([]string{"fetch"}, repoSpec.fetchArgs()...), "--filter=blob:none", "origin")
+	if repoSpec.Fetch.AllBranches {
+		if err := r.run(append(args, allBranchesRefspec)...); err != nil {
+			return err
+		}
+	}
+	if err := r.run(append(args, ref)...); err != nil {
+		return err
+	}
+	if err := r.run("checkout", "FETCH_HEAD"); err != nil {
+		return err
+	}
+	repoSpec.Commit = headCommit(r.dir, repoSpec.Timeout)
+	if repoSpec.Submodules {
+		return r.run(append([]string{"submodule", "update", "--init", "--recursive", "--"}, paths...)...)
+	}
+	return nil
+}
+
 // DoNothingCloner returns a cloner that only sets
 // cloneDir field in the repoSpec.  It's assumed that
 // the cloneDir is associated with some fake filesystem
@@ -54,3 +109,18 @@ func DoNothingCloner(dir filesys.ConfirmedDir) Cloner {
 		return nil
 	}
 }
+
+// ClonerWithFetchConfig returns a cloner which clones the repos with
+// cloner, fetching what fetch configures unless the query parameters of
+// their urls set otherwise.
+func ClonerWithFetchConfig(cloner Cloner, fetch types.GitFetchConfig) Cloner {
+	return func(repoSpec *RepoSpec) error {
+		_, query, _ := strings.Cut(repoSpec.raw, "?")
+		var err error
+		repoSpec.Fetch, err = parseFetchQuery(query, fetch)
+		if err != nil {
+			return err
+		}
+		return cloner(repoSpec)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// localRepo returns the path of a repo with three commits on its main
+// branch, the first tagged v1.0.0, and a feature branch.
+func localRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	src := t.TempDir()
+	commit := []string{"-C", src, "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-q", "--allow-empty", "-m"}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main", src},
+		append(commit, "first"),
+		{"-C", src, "tag", "v1.0.0"},
+		append(commit, "second"),
+		append(commit, "third"),
+		{"-C", src, "branch", "feature"},
+	} {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return src
+}
+
+func TestClonerWithFetchConfig(t *testing.T) {
+	src := localRepo(t)
+	// clone returns the output of the git command args run in the clone
+	// of url.
+	clone := func(url string, fetch types.GitFetchConfig, args ...string) string {
+		t.Helper()
+		repoSpec, err := NewRepoSpecFromURL(url)
+		require.NoError(t, err)
+		require.NoError(t, ClonerWithFetchConfig(ClonerUsingGitExec, fetch)(repoSpec))
+		defer os.RemoveAll(repoSpec.Dir.String())
+		out, err := exec.Command("git", append([]string{"-C", repoSpec.Dir.String()}, args...)...).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+	url := "file://" + src + "?ref=main"
+
+	// by default only the commit of the ref is fetched
+	assert.Equal(t, "1", clone(url, types.GitFetchConfig{}, "rev-list", "--count", "HEAD"))
+	assert.Equal(t, "2", clone(url+"&depth=2", types.GitFetchConfig{}, "rev-list", "--count", "HEAD"))
+	assert.Equal(t, "3", clone(url+"&depth=-1", types.GitFetchConfig{}, "rev-list", "--count", "HEAD"))
+	assert.Equal(t, "3", clone(url, types.GitFetchConfig{Depth: -1}, "rev-list", "--count", "HEAD"))
+	// the query parameters take precedence
+	assert.Equal(t, "1", clone(url+"&depth=1", types.GitFetchConfig{Depth: -1}, "rev-list", "--count", "HEAD"))
+
+	assert.Empty(t, clone(url, types.GitFetchConfig{}, "branch", "-r"))
+	assert.Equal(t, "origin/feature\n  origin/main",
+		clone(url+"&singleBranch=false", types.GitFetchConfig{}, "branch", "-r"))
+	assert.Empty(t, clone(url+"&singleBranch=true", types.GitFetchConfig{AllBranches: true}, "branch", "-r"))
+
+	assert.Empty(t, clone(url, types.GitFetchConfig{}, "tag"))
+	assert.Equal(t, "v1.0.0", clone(url+"&tags=true&depth=-1", types.GitFetchConfig{}, "tag"))
+	assert.Equal(t, "v1.0.0", clone(url+"&depth=-1", types.GitFetchConfig{Tags: true}, "tag"))
+}
+
+func TestParseFetchQuery(t *testing.T) {
+	testcases := []struct {
+		name     string
+		input    string
+		defaults types.GitFetchConfig
+		expected types.GitFetchConfig
+		errMsg   string
+	}{
+		{
+			name:  "empty",
+			input: "",
+		},
+		{
+			name:     "all",
+			input:    "ref=v1.0.0&depth=10&singleBranch=false&tags=true",
+			expected: types.GitFetchConfig{Depth: 10, AllBranches: true, Tags: true},
+		},
+		{
+			name:     "full_history",
+			input:    "depth=-1",
+			expected: types.GitFetchConfig{Depth: -1},
+		},
+		{
+			name:     "bad_depth",
+			input:    "depth=deep",
+			defaults: types.GitFetchConfig{Depth: 5},
+			errMsg:   `invalid depth "deep": must be an integer`,
+		},
+		{
+			name:   "bad_single_branch",
+			input:  "singleBranch=maybe",
+			errMsg: `invalid singleBranch "maybe": must be a boolean`,
+		},
+		{
+			name:   "bad_tags",
+			input:  "depth=1&tags=some",
+			errMsg: `invalid tags "some": must be a boolean`,
+		},
+		{
+			name:     "overridden_defaults",
+			input:    "singleBranch=true&tags=false",
+			defaults: types.GitFetchConfig{Depth: 5, AllBranches: true, Tags: true},
+			expected: types.GitFetchConfig{Depth: 5},
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetch, err := parseFetchQuery(tc.input, tc.defaults)
+			if tc.errMsg != "" {
+				require.EqualError(t, err, tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, fetch)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+// defaultTokenUsername is the user of tokens without a username, which
+// e.g. GitHub and GitLab accept for any token.
+const defaultTokenUsername = "x-access-token"
+
+// ClonerWithCredentials returns a cloner like ClonerUsingGitExec, which
+// authenticates the clone of a repo with the first of credentials matching
+// it, if any.
+func ClonerWithCredentials(credentials []types.GitCredential) Cloner {
+	return ClonerWithHTTPConfig(credentials, types.HTTPConfig{})
+}
+
+// ClonerWithHTTPConfig returns a cloner like ClonerWithCredentials, whose
+// git commands connect to https remotes through the proxy of httpConfig,
+// and trust its certificate authorities in addition to the system ones.
+func ClonerWithHTTPConfig(credentials []types.GitCredential, httpConfig types.HTTPConfig) Cloner {
+	return func(repoSpec *RepoSpec) error {
+		env, err := credentialEnv(repoSpec, credentials)
+		if err != nil {
+			return err
+		}
+		env = append(env, httpConfig.ProxyEnv()...)
+		if len(httpConfig.CAFiles) > 0 {
+			dir, err := os.MkdirTemp("", "kustomize-ca-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(dir)
+			bundle, err := httpConfig.WriteCABundle(dir)
+			if err != nil {
+				return err
+			}
+			env = append(env, "GIT_SSL_CAINFO="+bundle)
+		}
+		return cloneUsingGitExec(repoSpec, env)
+	}
+}
+
+// remote returns the scheme, the host with its port, and the path of the
+// repo of repoSpec, without its .git suffix.  The scheme is empty for
+// scp-like ssh urls, e.g. git@github.com:org/repo.
+func (x *RepoSpec) remote() (string, string, string) {
+	scheme, rest := extractScheme(x.Host)
+	_, rest = extractUsername(rest)
+	host := strings.TrimRight(rest, "/:")
+	return scheme, host, strings.TrimSuffix(x.RepoPath, gitSuffix)
+}
+
+// matchCredential returns the first of credentials matching the repo of
+// repoSpec, or nil if there's none.
+func matchCredential(repoSpec *RepoSpec, credentials []types.GitCredential) *types.GitCredential {
+	_, host, path := repoSpec.remote()
+	// the port of a host, which differs between https and ssh, isn't matched
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	repo := strings.ToLower(host + pathSeparator + path)
+	for i := range credentials {
+		prefix := strings.ToLower(strings.Trim(credentials[i].Host, pathSeparator))
+		if repo == prefix || strings.HasPrefix(repo, prefix+pathSeparator) {
+			return &credentials[i]
+		}
+	}
+	return nil
+}
+
+// credentialEnv returns the environment variables of the git commands
+// authenticating the clone of the repo of repoSpec with the first of
+// credentials matching it.  Https clones send the token in an http header
+// scoped to the host, and ssh clones use the private key.  Tokens aren't
+// sent to http remotes, which would leak them in cleartext.
+func credentialEnv(repoSpec *RepoSpec, credentials []types.GitCredential) ([]string, error) {
+	c := matchCredential(repoSpec, credentials)
+	if c == nil {
+		return nil, nil
+	}
+	// fail rather than prompt for credentials which don't work
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	scheme, host, _ := repoSpec.remote()
+	switch scheme {
+	case httpScheme:
+		if c.Token != "" || c.TokenEnv != "" {
+			return nil, fmt.Errorf(
+				"git credential of %s has a token, which isn't sent in cleartext to clone %s",
+				c.Host, repoSpec.CloneSpec())
+		}
+		return nil, fmt.Errorf(
+			"git credential of %s has no token to clone %s", c.Host, repoSpec.CloneSpec())
+	case httpsScheme:
+		token := c.Token
+		if c.TokenEnv != "" {
+			token = os.Getenv(c.TokenEnv)
+			if token == "" {
+				return nil, fmt.Errorf(
+					"environment variable %s of the git credential of %s is empty", c.TokenEnv, c.Host)
+			}
+		}
+		if token == "" {
+			return nil, fmt.Errorf(
+				"git credential of %s has no token to clone %s", c.Host, repoSpec.CloneSpec())
+		}
+		username := c.Username
+		if username == "" {
+			username = defaultTokenUsername
+		}
+		header := "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+token))
+		// the header is passed in the environment rather than the
+		// arguments of git, which other users may see, after the
+		// configuration the user may already pass in it
+		count := 0
+		if v := os.Getenv("GIT_CONFIG_COUNT"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid GIT_CONFIG_COUNT %q", v)
+			}
+			count = n
+		}
+		return append(env,
+			"GIT_CONFIG_COUNT="+strconv.Itoa(count+1),
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=http.%s%s%s.extraHeader", count, scheme, host, pathSeparator),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count, header)), nil
+	case sshScheme, "":
+		if c.SSHKeyPath == "" {
+			return nil, fmt.Errorf(
+				"git credential of %s has no sshKeyPath to clone %s", c.Host, repoSpec.CloneSpec())
+		}
+		command := "ssh -i " + shellQuote(c.SSHKeyPath) + " -o IdentitiesOnly=yes -o BatchMode=yes"
+		if c.SSHKnownHostsPath != "" {
+			command += " -o UserKnownHostsFile=" + shellQuote(c.SSHKnownHostsPath)
+		}
+		return append(env, "GIT_SSH_COMMAND="+command), nil
+	default:
+		// local repos aren't authenticated
+		return nil, nil
+	}
+}
+
+// shellQuote quotes s for the shell running GIT_SSH_COMMAND.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
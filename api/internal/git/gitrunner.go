@@ -4,7 +4,9 @@
 package git
 
 import (
+	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"sigs.k8s.io/kustomize/api/internal/utils"
@@ -17,6 +19,8 @@ type gitRunner struct {
 	gitProgram string
 	duration   time.Duration
 	dir        filesys.ConfirmedDir
+	// env are environment variables added to those of the commands.
+	env []string
 }
 
 // newCmdRunner returns a gitRunner if it can find the binary.
@@ -42,6 +46,9 @@ func (r gitRunner) run(args ...string) error {
 	//nolint: gosec
 	cmd := exec.Command(r.gitProgram, args...)
 	cmd.Dir = r.dir.String()
+	if len(r.env) > 0 {
+		cmd.Env = append(os.Environ(), r.env...)
+	}
 	return utils.TimedCall(
 		cmd.String(),
 		r.duration,
@@ -53,3 +60,43 @@ func (r gitRunner) run(args ...string) error {
 			return err
 		})
 }
+
+// output runs a command with a timeout, returning its trimmed standard
+// output.
+func (r gitRunner) output(args ...string) (string, error) {
+	//nolint: gosec
+	cmd := exec.Command(r.gitProgram, args...)
+	cmd.Dir = r.dir.String()
+	if len(r.env) > 0 {
+		cmd.Env = append(os.Environ(), r.env...)
+	}
+	var out []byte
+	err := utils.TimedCall(
+		cmd.String(),
+		r.duration,
+		func() error {
+			var err error
+			out, err = cmd.Output()
+			if err != nil {
+				return errors.WrapPrefixf(err, "failed to run '%s'", cmd.String())
+			}
+			return nil
+		})
+	return strings.TrimSpace(string(out)), err
+}
+
+// headCommit returns the hash of the commit checked out in the clone
+// dir, or the empty string if it can't be resolved, e.g. if dir isn't
+// a git repository.
+func headCommit(dir filesys.ConfirmedDir, timeout time.Duration) string {
+	gitProgram, err := exec.LookPath("git")
+	if err != nil {
+		return ""
+	}
+	r := gitRunner{gitProgram: gitProgram, duration: timeout, dir: dir}
+	commit, err := r.output("rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return commit
+}
@@ -0,0 +1,27 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package git
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// FindRepoRoot returns the root of the git repository enclosing dir, i.e.
+// the closest of dir and its ancestors holding a .git directory, or a .git
+// file of a worktree or submodule.  It returns false if dir isn't in a git
+// repository.
+func FindRepoRoot(fSys filesys.FileSystem, dir filesys.ConfirmedDir) (filesys.ConfirmedDir, bool) {
+	for d := dir; ; {
+		if fSys.Exists(d.Join(".git")) {
+			return d, true
+		}
+		parent := filesys.ConfirmedDir(filepath.Dir(d.String()))
+		if parent == d {
+			return "", false
+		}
+		d = parent
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
@@ -47,11 +48,32 @@ type RepoSpec struct {
 	// Branch or tag reference.
 	Ref string
 
+	// Commit is the hash of the commit checked out in Dir, resolved
+	// after cloning, or empty if it's unknown.
+	Commit string
+
 	// Submodules indicates whether or not to clone git submodules.
 	Submodules bool
 
+	// Sparse indicates whether to check out only the directories of
+	// KustRootPath and SparsePaths, and to fetch only their files,
+	// rather than the whole repository.
+	Sparse bool
+
+	// SparsePaths are the directories in the repository checked out in
+	// addition to KustRootPath if Sparse, e.g. those of its bases.
+	SparsePaths []string
+
+	// Fetch configures the history, the branches and the tags of the
+	// repository fetched in addition to the commit of Ref.
+	Fetch types.GitFetchConfig
+
 	// Timeout is the maximum duration allowed for execing git commands.
 	Timeout time.Duration
+
+	// cached indicates whether Dir is a clone in a cache directory, which
+	// isn't cleaned up.
+	cached bool
 }
 
 // CloneSpec returns a string suitable for "git clone {spec}".
@@ -72,7 +94,12 @@ func (x *RepoSpec) AbsPath() string {
 }
 
 func (x *RepoSpec) Cleaner(fSys filesys.FileSystem) func() error {
-	return func() error { return fSys.RemoveAll(x.Dir.String()) }
+	return func() error {
+		if x.cached {
+			return nil
+		}
+		return fSys.RemoveAll(x.Dir.String())
+	}
 }
 
 const (
@@ -103,8 +130,13 @@ func NewRepoSpecFromURL(n string) (*RepoSpec, error) {
 	// Note that parseQuery returns default values for empty parameters.
 	n, query, _ := strings.Cut(n, "?")
 	repoSpec.Ref, repoSpec.Timeout, repoSpec.Submodules = parseQuery(query)
+	repoSpec.Sparse, repoSpec.SparsePaths = parseSparseQuery(query)
 
 	var err error
+	repoSpec.Fetch, err = parseFetchQuery(query, types.GitFetchConfig{})
+	if err != nil {
+		return nil, err
+	}
 
 	// Parse the host (e.g. scheme, username, domain) segment.
 	repoSpec.Host, n, err = extractHost(n)
@@ -118,6 +150,11 @@ func NewRepoSpecFromURL(n string) (*RepoSpec, error) {
 	if err != nil {
 		return nil, err
 	}
+	for _, p := range repoSpec.SparsePaths {
+		if kustRootPathExitsRepo(p) {
+			return nil, fmt.Errorf("sparse path exits repo: %s", p)
+		}
+	}
 
 	return repoSpec, nil
 }
@@ -249,6 +286,104 @@ func parseQuery(query string) (string, time.Duration, bool) {
 	return ref, duration, submodules
 }
 
+// sparseCheckoutPaths returns the directories checked out if Sparse.
+func (x *RepoSpec) sparseCheckoutPaths() []string {
+	var paths []string
+	for _, p := range append([]string{x.KustRootPath}, x.SparsePaths...) {
+		if p = strings.Trim(p, pathSeparator); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+func parseSparseQuery(query string) (bool, []string) {
+	values, err := url.ParseQuery(query)
+	// in event of parse failure, return defaults
+	if err != nil {
+		return false, nil
+	}
+
+	// sparsePath are directories in the repo to check out in addition to
+	// the kustomization root, e.g. ?sparsePath=base&sparsePath=components.
+	// Specifying them implies a sparse checkout.
+	paths := values["sparsePath"]
+
+	// sparse indicates if a sparse checkout of only the kustomization root
+	// and the sparse paths is desired. Can be specified by in a git URL
+	// with ?sparse=<bool>.
+	sparse := len(paths) > 0
+	if queryValue := values.Get("sparse"); queryValue != "" {
+		if boolValue, err := strconv.ParseBool(queryValue); err == nil {
+			sparse = boolValue
+		}
+	}
+	if !sparse {
+		return false, nil
+	}
+	return true, paths
+}
+
+// parseFetchQuery returns the fetch configuration of query, whose
+// parameters override defaults. It returns an error if a parameter
+// has a malformed value.
+func parseFetchQuery(query string, defaults types.GitFetchConfig) (types.GitFetchConfig, error) {
+	values, err := url.ParseQuery(query)
+	// in event of parse failure, return defaults
+	if err != nil {
+		return defaults, nil
+	}
+	fetch := defaults
+
+	// depth is the number of commits of the history of the ref fetched,
+	// a negative depth the full history. Can be specified by in a git URL
+	// with ?depth=<int>.
+	if queryValue := values.Get("depth"); queryValue != "" {
+		intValue, err := strconv.Atoi(queryValue)
+		if err != nil {
+			return defaults, fmt.Errorf("invalid depth %q: must be an integer", queryValue)
+		}
+		fetch.Depth = intValue
+	}
+
+	// singleBranch indicates if only the ref, rather than all the branches,
+	// is fetched. Can be specified by in a git URL with ?singleBranch=<bool>.
+	if queryValue := values.Get("singleBranch"); queryValue != "" {
+		boolValue, err := strconv.ParseBool(queryValue)
+		if err != nil {
+			return defaults, fmt.Errorf("invalid singleBranch %q: must be a boolean", queryValue)
+		}
+		fetch.AllBranches = !boolValue
+	}
+
+	// tags indicates if all the tags are fetched. Can be specified by in a
+	// git URL with ?tags=<bool>.
+	if queryValue := values.Get("tags"); queryValue != "" {
+		boolValue, err := strconv.ParseBool(queryValue)
+		if err != nil {
+			return defaults, fmt.Errorf("invalid tags %q: must be a boolean", queryValue)
+		}
+		fetch.Tags = boolValue
+	}
+	return fetch, nil
+}
+
+// fetchArgs returns the arguments of the fetches of the clone of x
+// configuring its depth and tags.
+func (x *RepoSpec) fetchArgs() []string {
+	var args []string
+	switch {
+	case x.Fetch.Depth == 0:
+		args = append(args, "--depth=1")
+	case x.Fetch.Depth > 0:
+		args = append(args, "--depth="+strconv.Itoa(x.Fetch.Depth))
+	}
+	if x.Fetch.Tags {
+		return append(args, "--tags")
+	}
+	return append(args, "--no-tags")
+}
+
 func extractHost(n string) (string, string, error) {
 	n = ignoreForcedGitProtocol(n)
 	scheme, n := extractScheme(n)
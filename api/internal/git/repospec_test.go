@@ -114,6 +114,22 @@ func TestNewRepoSpecFromUrlErrors(t *testing.T) {
 			"https://github.com/org/repo.git//path/../../exits/repo",
 			"url path exits repo",
 		},
+		"sparse_path_exits_repo": {
+			"https://github.com/org/repo.git//path?sparsePath=../exits",
+			"sparse path exits repo",
+		},
+		"bad_depth": {
+			"https://github.com/org/repo.git//path?depth=deep",
+			"invalid depth",
+		},
+		"bad_single_branch": {
+			"https://github.com/org/repo.git//path?singleBranch=maybe",
+			"invalid singleBranch",
+		},
+		"bad_tags": {
+			"https://github.com/org/repo.git//path?tags=some",
+			"invalid tags",
+		},
 		"bad github separator": {
 			"github.com!org/repo.git//path",
 			"failed to parse scheme",
@@ -841,3 +857,49 @@ func TestParseQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSparseQuery(t *testing.T) {
+	testcases := []struct {
+		name   string
+		input  string
+		sparse bool
+		paths  []string
+	}{
+		{
+			name:  "empty",
+			input: "",
+		},
+		{
+			name:   "sparse",
+			input:  "ref=v1.0.0&sparse=true",
+			sparse: true,
+		},
+		{
+			name: "bad_sparse",
+			// Malformed sparse value uses default.
+			input: "sparse=maybe",
+		},
+		{
+			name:   "sparse_paths",
+			input:  "sparsePath=base&sparsePath=components/debug",
+			sparse: true,
+			paths:  []string{"base", "components/debug"},
+		},
+		{
+			name: "sparse_false_with_paths",
+			// An explicit false sparse value disables the sparse paths.
+			input: "sparse=false&sparsePath=base",
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			sparse, paths := parseSparseQuery(tc.input)
+			assert.Equal(t, tc.sparse, sparse, "sparse mismatch")
+			assert.Equal(t, tc.paths, paths, "paths mismatch")
+		})
+	}
+
+	repoSpec, err := NewRepoSpecFromURL("https://github.com/org/repo//overlays/dev/?sparsePath=/base/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"overlays/dev", "base"}, repoSpec.sparseCheckoutPaths())
+}
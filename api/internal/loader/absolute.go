@@ -0,0 +1,61 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// absolutePath returns the cleaned path of path if it's an absolute path,
+// or a file:// url which isn't the url of a git repo, i.e. which has no
+// query, e.g. ?ref=v1, and no // separating a directory in the repo.
+func absolutePath(path string) (string, bool) {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), true
+	}
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") ||
+		u.RawQuery != "" || strings.Contains(u.Path, "//") || !filepath.IsAbs(u.Path) {
+		return "", false
+	}
+	return filepath.Clean(u.Path), true
+}
+
+// allowedAbsolutePath returns the absolute path of path, see absolutePath,
+// if the loader at the root of the chain of referrers allows them.
+func (fl *FileLoader) allowedAbsolutePath(path string) (string, bool) {
+	if !fl.getAllowAbsolutePaths() {
+		return "", false
+	}
+	return absolutePath(path)
+}
+
+// getAllowAbsolutePaths returns true if the loader at the root of the
+// chain of referrers allows absolute paths.
+func (fl *FileLoader) getAllowAbsolutePaths() bool {
+	for l := fl; l != nil; l = l.referrer {
+		if l.allowAbsolutePaths {
+			return true
+		}
+	}
+	return false
+}
+
+// loadAbsolute returns the content of the file at the absolute path,
+// which must be within the repo or package of the loader, if any.
+func (fl *FileLoader) loadAbsolute(path string) ([]byte, error) {
+	dir, f, err := fl.fSys.CleanedAbs(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = fl.errIfGitContainmentViolation(dir); err != nil {
+		return nil, err
+	}
+	if err = fl.errIfPackageContainmentViolation(dir); err != nil {
+		return nil, err
+	}
+	return fl.fSys.ReadFile(dir.Join(f))
+}
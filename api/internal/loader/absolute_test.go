@@ -0,0 +1,71 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestAbsolutePath(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/nix/store/abc/cm.yaml":                   "/nix/store/abc/cm.yaml",
+		"/nix/store/abc/../def":                    "/nix/store/def",
+		"file:///nix/store/abc/cm.yaml":            "/nix/store/abc/cm.yaml",
+		"file://localhost/nix/store/abc":           "/nix/store/abc",
+		"file:///repos/repo//base":                 "",
+		"file:///repos/repo?ref=v1":                "",
+		"file://host/nix/store/abc":                "",
+		"cm.yaml":                                  "",
+		"https://example.com/cm.yaml":              "",
+		"https://github.com/org/repo//base?ref=v1": "",
+	} {
+		abs, ok := absolutePath(path)
+		assert.Equal(t, expected != "", ok, path)
+		assert.Equal(t, expected, abs, path)
+	}
+}
+
+func TestAllowAbsolutePaths(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/app"))
+	require.NoError(t, fSys.MkdirAll("/nix/store/def/base"))
+	require.NoError(t, fSys.WriteFile("/nix/store/abc/cm.yaml", []byte("kind: ConfigMap\n")))
+
+	ldr, err := NewLoaderWithOptions(RestrictionRootOnly, "/app", fSys, Options{})
+	require.NoError(t, err)
+	_, err = ldr.Load("/nix/store/abc/cm.yaml")
+	assert.ErrorContains(t, err, "security; file '/nix/store/abc/cm.yaml' is not in or below '/app'")
+	_, err = ldr.New("/nix/store/def/base")
+	assert.EqualError(t, err, "new root '/nix/store/def/base' cannot be absolute")
+
+	ldr, err = NewLoaderWithOptions(RestrictionRootOnly, "/app", fSys, Options{AllowAbsolutePaths: true})
+	require.NoError(t, err)
+	for _, path := range []string{"/nix/store/abc/cm.yaml", "file:///nix/store/abc/cm.yaml"} {
+		b, err := ldr.Load(path)
+		require.NoError(t, err)
+		assert.Equal(t, "kind: ConfigMap\n", string(b))
+	}
+	base, err := ldr.New("file:///nix/store/def/base")
+	require.NoError(t, err)
+	assert.Equal(t, "/nix/store/def/base", base.Root())
+	// the descendants allow absolute paths too
+	_, err = base.Load("/nix/store/abc/cm.yaml")
+	require.NoError(t, err)
+
+	// remote kustomizations can't load local files
+	require.NoError(t, fSys.MkdirAll("/clone/base"))
+	repoSpec, err := git.NewRepoSpecFromURL("https://github.com/org/repo//base?ref=v1")
+	require.NoError(t, err)
+	clone, err := newLoaderAtGitClone(repoSpec, fSys, ldr.(*FileLoader), git.DoNothingCloner("/clone"))
+	require.NoError(t, err)
+	_, err = clone.Load("/nix/store/abc/cm.yaml")
+	assert.ErrorContains(t, err, "must be within the repo, but base '/nix/store/abc' is outside '/clone'")
+	_, err = clone.New("/nix/store/def/base")
+	assert.ErrorContains(t, err, "must be within the repo, but base '/nix/store/def/base' is outside '/clone'")
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// AllowPaths lets the loader load the files below the directories of
+// paths, relative to its root, regardless of its LoadRestrictorFunc, if the
+// loader at the root of the chain of referrers enables allowed paths, see
+// Options.EnableAllowedPaths.  Otherwise, paths are ignored.  Unlike the
+// other settings of loaders, the allowed paths aren't inherited by the
+// descendants of the loader.
+func (fl *FileLoader) AllowPaths(paths []string) error {
+	if !fl.getEnableAllowedPaths() {
+		return nil
+	}
+	dirs := make([]filesys.ConfirmedDir, 0, len(paths))
+	for _, path := range paths {
+		if path == "" || filepath.IsAbs(path) {
+			return fmt.Errorf("allowed path '%s' must be a relative path", path)
+		}
+		dir, err := filesys.ConfirmDir(fl.fSys, fl.root.Join(path))
+		if err != nil {
+			return fmt.Errorf("allowed path '%s' must be a directory: %w", path, err)
+		}
+		if err = fl.errIfGitContainmentViolation(dir); err != nil {
+			return err
+		}
+		if err = fl.errIfPackageContainmentViolation(dir); err != nil {
+			return err
+		}
+		dirs = append(dirs, dir)
+	}
+	fl.allowedDirs = dirs
+	return nil
+}
+
+// allowedPath returns the cleaned absolute path of path, if it's a file
+// below one of the allowed directories of the loader.
+func (fl *FileLoader) allowedPath(path string) (string, bool) {
+	if len(fl.allowedDirs) == 0 {
+		return "", false
+	}
+	if !filepath.IsAbs(path) {
+		path = fl.root.Join(path)
+	}
+	d, f, err := fl.fSys.CleanedAbs(path)
+	if err != nil || f == "" {
+		return "", false
+	}
+	for _, dir := range fl.allowedDirs {
+		if d.HasPrefix(dir) {
+			return d.Join(f), true
+		}
+	}
+	return "", false
+}
+
+// getEnableAllowedPaths returns true if the loader at the root of the
+// chain of referrers enables allowed paths.
+func (fl *FileLoader) getEnableAllowedPaths() bool {
+	for l := fl; l != nil; l = l.referrer {
+		if l.enableAllowedPaths {
+			return true
+		}
+	}
+	return false
+}
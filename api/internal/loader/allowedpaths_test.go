@@ -0,0 +1,64 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestAllowPaths(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/repo/apps/foo"))
+	require.NoError(t, fSys.WriteFile("/repo/shared/patch.yaml", []byte("kind: Deployment\n")))
+	require.NoError(t, fSys.WriteFile("/repo/secrets/key", []byte("secret")))
+
+	// the allowed paths are ignored unless enabled
+	ldr, err := NewLoaderWithOptions(RestrictionRootOnly, "/repo/apps/foo", fSys, Options{})
+	require.NoError(t, err)
+	require.NoError(t, ldr.(*FileLoader).AllowPaths([]string{"../../shared"}))
+	_, err = ldr.Load("../../shared/patch.yaml")
+	assert.ErrorContains(t, err, "security; file '/repo/shared/patch.yaml' is not in or below '/repo/apps/foo'")
+
+	ldr, err = NewLoaderWithOptions(RestrictionRootOnly, "/repo/apps/foo", fSys, Options{EnableAllowedPaths: true})
+	require.NoError(t, err)
+	fl := ldr.(*FileLoader)
+	require.NoError(t, fl.AllowPaths([]string{"../../shared"}))
+	b, err := fl.Load("../../shared/patch.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "kind: Deployment\n", string(b))
+	_, err = fl.Load("/repo/shared/patch.yaml")
+	require.NoError(t, err)
+	_, err = fl.Load("../../secrets/key")
+	assert.ErrorContains(t, err, "security; file '/repo/secrets/key' is not in or below '/repo/apps/foo'")
+
+	// the descendants don't inherit the allowed paths
+	require.NoError(t, fSys.MkdirAll("/repo/apps/foo/sub"))
+	sub, err := fl.New("sub")
+	require.NoError(t, err)
+	_, err = sub.Load("../../../shared/patch.yaml")
+	assert.Error(t, err)
+
+	for paths, expectedErr := range map[string]string{
+		"":            "allowed path '' must be a relative path",
+		"/repo":       "allowed path '/repo' must be a relative path",
+		"../missing":  "allowed path '../missing' must be a directory",
+		"../../../x/": "allowed path '../../../x/' must be a directory",
+	} {
+		assert.ErrorContains(t, fl.AllowPaths([]string{paths}), expectedErr)
+	}
+
+	// remote kustomizations can only allow paths in their repo
+	require.NoError(t, fSys.MkdirAll("/clone/base"))
+	repoSpec, err := git.NewRepoSpecFromURL("https://github.com/org/repo//base?ref=v1")
+	require.NoError(t, err)
+	clone, err := newLoaderAtGitClone(repoSpec, fSys, fl, git.DoNothingCloner("/clone"))
+	require.NoError(t, err)
+	assert.ErrorContains(t, clone.AllowPaths([]string{"../../repo/shared"}),
+		"must be within the repo, but base '/repo/shared' is outside '/clone'")
+}
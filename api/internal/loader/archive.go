@@ -0,0 +1,187 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// archiveSuffixes are the suffixes of the urls of remote archives, and the
+// formats of the archives.
+var archiveSuffixes = []struct {
+	suffix string
+	format kio.ArchiveFormat
+}{
+	{".tar.gz", kio.TarGzipArchive},
+	{".tgz", kio.TarGzipArchive},
+	{".zip", kio.ZipArchive},
+}
+
+var sha256HexPattern = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// remoteArchive is a tar.gz or zip archive of kustomizations, e.g. a
+// release asset, referenced by its https url, an optional path in the
+// archive following a double slash, and its required sha256 digest, e.g.
+// https://example.com/releases/v1.0.0/config.tar.gz//config/base?sha256=...
+type remoteArchive struct {
+	// raw is the original reference.
+	raw string
+	// url is where the archive is downloaded from.
+	url string
+	// format is the format of the archive.
+	format kio.ArchiveFormat
+	// path is the path of the kustomization in the archive.
+	path string
+	// sha256 is the hex encoded sha256 digest of the archive.
+	sha256 string
+}
+
+// parseRemoteArchive returns the remote archive of ref, or nil if ref
+// isn't the url of an archive.
+func parseRemoteArchive(ref string) (*remoteArchive, error) {
+	if !IsRemoteFile(ref) {
+		return nil, nil
+	}
+	u, query, _ := strings.Cut(ref, "?")
+	scheme, rest, _ := strings.Cut(u, "://")
+	rest, path, _ := strings.Cut(rest, "//")
+	var format kio.ArchiveFormat
+	for _, s := range archiveSuffixes {
+		if strings.HasSuffix(strings.ToLower(rest), s.suffix) {
+			format = s.format
+			break
+		}
+	}
+	if format == "" {
+		return nil, nil
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid query of remote archive %q", ref)
+	}
+	digest := strings.ToLower(values.Get("sha256"))
+	if !sha256HexPattern.MatchString(digest) {
+		return nil, fmt.Errorf(
+			"remote archive %q must be pinned by the hex encoded sha256 digest of its content, "+
+				"e.g. ?sha256=<digest>", ref)
+	}
+	if kustRootPathExitsArchive(path) {
+		return nil, fmt.Errorf("path of remote archive %q exits the archive", ref)
+	}
+	values.Del("sha256")
+	archive := &remoteArchive{
+		raw:    ref,
+		url:    scheme + "://" + rest,
+		format: format,
+		path:   strings.Trim(path, "/"),
+		sha256: digest,
+	}
+	if len(values) > 0 {
+		archive.url += "?" + values.Encode()
+	}
+	return archive, nil
+}
+
+func kustRootPathExitsArchive(path string) bool {
+	cleaned := filepath.ToSlash(filepath.Clean(filepath.FromSlash(strings.TrimPrefix(path, "/"))))
+	return cleaned == filesys.ParentDir || strings.HasPrefix(cleaned, filesys.ParentDir+"/")
+}
+
+// IsRemoteArchive returns whether path is the url of a remote archive of
+// kustomizations, rather than of a file.
+func IsRemoteArchive(path string) bool {
+	archive, err := parseRemoteArchive(path)
+	return archive != nil || err != nil
+}
+
+// download returns the content of the archive, verified against its
+// digest.
+func (a *remoteArchive) download(hc *http.Client) ([]byte, error) {
+	resp, err := hc.Get(a.url)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: status code %d (%s)", ErrHTTP, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if err := checkSha256(content, a.sha256); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// checkSha256 returns an error if the hex encoded sha256 digest of content
+// isn't pinned.
+func checkSha256(content []byte, pinned string) error {
+	sum := sha256.Sum256(content)
+	if digest := hex.EncodeToString(sum[:]); digest != pinned {
+		return fmt.Errorf("sha256 digest %s of the content doesn't match the pinned %s", digest, pinned)
+	}
+	return nil
+}
+
+// archiveHandler is the packageHandler of remote archives, which downloads
+// them with client.
+type archiveHandler struct {
+	client *http.Client
+}
+
+func (h archiveHandler) handles(ref string) bool {
+	return IsRemoteArchive(ref)
+}
+
+func (h archiveHandler) fetch(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, filesys.ConfirmedDir, error) {
+	archive, err := parseRemoteArchive(ref)
+	if err != nil {
+		return "", "", err
+	}
+	content, err := archive.download(h.client)
+	if err != nil {
+		return "", "", errors.WrapPrefixf(err, "downloading remote archive %q", ref)
+	}
+	dir, err := filesys.NewTmpConfirmedDir()
+	if err != nil {
+		return "", "", errors.Wrap(err)
+	}
+	cleaner := func() error { return fSys.RemoveAll(dir.String()) }
+	err = kio.ArchiveReader{Reader: bytes.NewReader(content), Format: archive.format}.Extract(fSys, dir.String())
+	if err != nil {
+		_ = cleaner()
+		return "", "", errors.WrapPrefixf(err, "extracting remote archive %q", ref)
+	}
+	root, f, err := fSys.CleanedAbs(dir.Join(archive.path))
+	if err != nil {
+		_ = cleaner()
+		return "", "", errors.WrapPrefixf(err, "remote archive %q", ref)
+	}
+	if f != "" {
+		_ = cleaner()
+		return "", "", fmt.Errorf("'%s' refers to file '%s'; expecting directory", ref, f)
+	}
+	return dir, root, nil
+}
+
+// errIfNotPinned returns nil, the remote archives are always pinned by
+// their sha256.
+func (h archiveHandler) errIfNotPinned(string) error {
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+const archiveURL = "https://example.com/releases/v1.0.0/config.tar.gz"
+
+// makeTarGzip returns a tar.gz archive of files.
+func makeTarGzip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestParseRemoteArchive(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	testCases := map[string]struct {
+		ref      string
+		expected *remoteArchive
+		err      string
+	}{
+		"archive": {
+			ref: archiveURL + "?sha256=" + digest,
+			expected: &remoteArchive{
+				url: archiveURL, format: kio.TarGzipArchive, sha256: digest},
+		},
+		"path in zip archive": {
+			ref: "https://example.com/config.zip//overlays/prod/?sha256=" + strings.ToUpper(digest) + "&token=x",
+			expected: &remoteArchive{
+				url: "https://example.com/config.zip?token=x", format: kio.ZipArchive, path: "overlays/prod", sha256: digest},
+		},
+		"file": {
+			ref: "https://example.com/resource.yaml",
+		},
+		"git repo": {
+			ref: "https://github.com/org/repo//base?ref=v1.0.0",
+		},
+		"not pinned": {
+			ref: archiveURL,
+			err: "must be pinned by the hex encoded sha256 digest of its content",
+		},
+		"invalid digest": {
+			ref: archiveURL + "?sha256=abc",
+			err: "must be pinned by the hex encoded sha256 digest of its content",
+		},
+		"path exits archive": {
+			ref: archiveURL + "//base/../../etc?sha256=" + digest,
+			err: "exits the archive",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			archive, err := parseRemoteArchive(tc.ref)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				assert.True(t, IsRemoteArchive(tc.ref))
+				return
+			}
+			require.NoError(t, err)
+			if tc.expected == nil {
+				assert.Nil(t, archive)
+				assert.False(t, IsRemoteArchive(tc.ref))
+				return
+			}
+			tc.expected.raw = tc.ref
+			assert.Equal(t, tc.expected, archive)
+		})
+	}
+}
+
+func TestNewLoaderAtRemoteArchive(t *testing.T) {
+	fSys, dir := setupOnDisk(t)
+	require.NoError(t, fSys.WriteFile(dir.Join("kustomization.yaml"), []byte("resources: []\n")))
+	content := makeTarGzip(t, map[string]string{
+		"config/base/kustomization.yaml": "resources:\n- deployment.yaml\n",
+		"config/base/deployment.yaml":    "kind: Deployment\n",
+	})
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+	requests := 0
+	overlay := newLoaderAtConfirmedDir(RestrictionNone, dir, fSys, nil, git.ClonerUsingGitExec)
+	overlay.http = makeFakeHTTPClient(func(req *http.Request) *http.Response {
+		requests++
+		if req.URL.String() != archiveURL {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(&bytes.Buffer{})}
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(content))}
+	})
+
+	ref := archiveURL + "//config/base?sha256=" + digest
+	l, err := overlay.New(ref)
+	require.NoError(t, err)
+	b, err := l.Load("deployment.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "kind: Deployment\n", string(b))
+
+	// the archive can't have bases outside of it
+	_, err = l.New("../../../" + dir.String())
+	assert.Error(t, err)
+	_, err = l.New(ref)
+	assert.EqualError(t, err, "cycle detected: '"+ref+"' referenced by itself")
+
+	root := l.Root()
+	require.NoError(t, l.Cleanup())
+	assert.False(t, fSys.Exists(root))
+
+	// archives are bases rather than files
+	_, err = overlay.Load(ref)
+	assert.ErrorContains(t, err, "is a remote archive; expecting file")
+
+	requests = 0
+	_, err = overlay.New(archiveURL + "?sha256=" + strings.Repeat("0", 64))
+	assert.ErrorContains(t, err, "sha256 digest "+digest+" of the content doesn't match the pinned "+strings.Repeat("0", 64))
+	assert.Equal(t, 1, requests)
+
+	_, err = overlay.New("https://example.com/missing.tgz?sha256=" + digest)
+	assert.ErrorIs(t, err, ErrHTTP)
+
+	requests = 0
+	_, err = overlay.New(archiveURL)
+	assert.ErrorContains(t, err, "must be pinned by the hex encoded sha256 digest")
+	assert.Equal(t, 0, requests)
+}
+
+func TestNewLoaderWithOptionsHTTPClient(t *testing.T) {
+	content := makeTarGzip(t, map[string]string{
+		"kustomization.yaml": "resources:\n- deployment.yaml\n",
+		"deployment.yaml":    "kind: Deployment\n",
+	})
+	sum := sha256.Sum256(content)
+	ref := archiveURL + "?sha256=" + hex.EncodeToString(sum[:])
+	var requested []string
+	hc := makeFakeHTTPClient(func(req *http.Request) *http.Response {
+		requested = append(requested, req.URL.String())
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(content))}
+	})
+	l, err := NewLoaderWithOptions(RestrictionRootOnly, ref, filesys.MakeFsOnDisk(), Options{HTTPClient: hc})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, l.Cleanup()) }()
+	b, err := l.Load("deployment.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "kind: Deployment\n", string(b))
+
+	// the descendants of the loader fetch with its client too
+	_, err = l.Load("https://example.com/configmap.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{archiveURL, "https://example.com/configmap.yaml"}, requested)
+}
+
+func TestNewLoaderAtRemoteArchiveVendored(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/app/vendor"))
+	ref := archiveURL + "?sha256=" + strings.Repeat("0", 64)
+	ldr, err := NewLoaderWithOptions(RestrictionRootOnly, "/app", fSys, Options{VendorDir: "/app/vendor"})
+	require.NoError(t, err)
+	_, err = ldr.New(ref)
+	assert.ErrorIs(t, err, ErrNotVendored)
+}
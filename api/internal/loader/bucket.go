@@ -0,0 +1,121 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	s3Scheme  = "s3://"
+	gcsScheme = "gs://"
+
+	// bucketFetchTimeout is the time a bucket fetch is allowed to take.
+	bucketFetchTimeout = 5 * time.Minute
+)
+
+// IsBucketReference returns true if ref is a directory in an S3 or GCS
+// bucket, e.g. s3://bucket/platform/base or gs://bucket/platform/base.
+func IsBucketReference(ref string) bool {
+	return strings.HasPrefix(ref, s3Scheme) || strings.HasPrefix(ref, gcsScheme)
+}
+
+// BucketFetcher is a function that fetches the objects under the
+// directory of a bucket reference into a new directory, and returns the
+// directory.
+type BucketFetcher func(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, error)
+
+// FetcherUsingCloudCLI fetches the objects with the aws CLI for S3 and the
+// gcloud CLI for GCS, which read the credentials of the buckets from the
+// standard credential chains of their SDKs, e.g. AWS_PROFILE, the
+// instance metadata or GOOGLE_APPLICATION_CREDENTIALS.
+func FetcherUsingCloudCLI(ref string, _ filesys.FileSystem) (filesys.ConfirmedDir, error) {
+	if err := validateBucketReference(ref); err != nil {
+		return "", err
+	}
+	// both sync the objects under the prefix into the directory, unlike
+	// their recursive copies, which differ in whether they copy the
+	// prefix itself
+	src := strings.TrimSuffix(ref, "/") + "/"
+	var program string
+	var args []string
+	if strings.HasPrefix(ref, s3Scheme) {
+		program, args = "aws", []string{"s3", "sync", "--only-show-errors", "--no-progress", src}
+	} else {
+		program, args = "gcloud", []string{"storage", "rsync", "--recursive", src}
+	}
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return "", errors.WrapPrefixf(err, "no '%s' program on path", program)
+	}
+	dir, err := filesys.NewTmpConfirmedDir()
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	//nolint: gosec
+	cmd := exec.Command(path, append(args, dir.String())...)
+	err = utils.TimedCall(cmd.String(), bucketFetchTimeout, func() error {
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return errors.WrapPrefixf(err, "failed to run '%s': %s", cmd.String(), string(out))
+		}
+		return nil
+	})
+	if err != nil {
+		_ = filesys.MakeFsOnDisk().RemoveAll(dir.String())
+		return "", err
+	}
+	return dir, nil
+}
+
+// validateBucketReference returns an error if ref has no bucket, or a
+// path exiting the bucket.
+func validateBucketReference(ref string) error {
+	rest := strings.TrimPrefix(strings.TrimPrefix(ref, s3Scheme), gcsScheme)
+	bucket, path, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return fmt.Errorf("bucket reference %q has no bucket", ref)
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if elem == filesys.ParentDir || elem == filesys.SelfDir {
+			return fmt.Errorf("bucket reference %q has a relative path", ref)
+		}
+	}
+	return nil
+}
+
+// bucketHandler is the packageHandler of bucket directories, which fetches
+// them with fetcher.
+type bucketHandler struct {
+	fetcher BucketFetcher
+}
+
+func (h bucketHandler) handles(ref string) bool {
+	return IsBucketReference(ref)
+}
+
+func (h bucketHandler) fetch(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, filesys.ConfirmedDir, error) {
+	if err := validateBucketReference(ref); err != nil {
+		return "", "", err
+	}
+	dir, err := h.fetcher(ref, fSys)
+	if err != nil {
+		return "", "", errors.WrapPrefixf(err, "fetching bucket directory %q", ref)
+	}
+	if !fSys.IsDir(dir.String()) {
+		_ = fSys.RemoveAll(dir.String())
+		return "", "", fmt.Errorf("bucket directory %q has no objects", ref)
+	}
+	return dir, dir, nil
+}
+
+func (h bucketHandler) errIfNotPinned(ref string) error {
+	return fmt.Errorf("%w: bucket directory %q can't be pinned", ErrNotPinned, ref)
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestNewLoaderAtBucket(t *testing.T) {
+	const ref = "s3://config/platform/base"
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/app/overlay"))
+	require.NoError(t, fSys.WriteFile("/fetched/base/kustomization.yaml", []byte("resources: []\n")))
+
+	overlay := newLoaderAtConfirmedDir(
+		RestrictionNone, filesys.ConfirmedDir("/app/overlay"), fSys, nil, git.ClonerUsingGitExec)
+	fake := bucketHandler{fetcher: BucketFetcher(fakePuller(map[string]string{ref: "/fetched/base"}))}
+	overlay.packageHandlers = map[string]packageHandler{"s3": fake, "gs": fake}
+
+	l, err := overlay.New(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "/fetched/base", l.Root())
+	content, err := l.Load("kustomization.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "resources: []\n", string(content))
+
+	_, err = l.New("../../app")
+	assert.ErrorContains(t, err, "bases in kustomizations found in '"+ref+"' must be within it")
+	_, err = l.New(ref)
+	assert.EqualError(t, err, "cycle detected: '"+ref+"' referenced by itself")
+
+	_, err = overlay.New("gs://config/platform/missing")
+	assert.EqualError(t, err,
+		`fetching bucket directory "gs://config/platform/missing": manifest unknown`)
+	_, err = overlay.New("s3:///platform/base")
+	assert.EqualError(t, err, `bucket reference "s3:///platform/base" has no bucket`)
+	_, err = overlay.New("gs://config/platform/../secrets")
+	assert.EqualError(t, err, `bucket reference "gs://config/platform/../secrets" has a relative path`)
+
+	require.NoError(t, l.Cleanup())
+	assert.False(t, fSys.Exists("/fetched/base"))
+
+	require.NoError(t, fSys.MkdirAll("/app/vendor"))
+	_, err = NewLoaderWithOptions(RestrictionRootOnly, ref, fSys, Options{VendorDir: "/app/vendor"})
+	assert.ErrorIs(t, err, ErrNotVendored)
+}
+
+func TestFetcherUsingCloudCLI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake CLIs are shell scripts")
+	}
+	// the fake CLIs record their arguments, and "sync" a kustomization
+	// into their last argument
+	bin := t.TempDir()
+	script := `#!/bin/sh
+echo "$0 $*" > "` + bin + `/args"
+for dir; do :; done
+echo "resources: []" > "$dir/kustomization.yaml"
+`
+	for _, program := range []string{"aws", "gcloud"} {
+		require.NoError(t, os.WriteFile(filepath.Join(bin, program), []byte(script), 0o700)) //nolint:gosec
+	}
+	t.Setenv("PATH", bin)
+	fSys := filesys.MakeFsOnDisk()
+
+	for ref, args := range map[string]string{
+		"s3://config/platform/base":  "/aws s3 sync --only-show-errors --no-progress s3://config/platform/base/ ",
+		"gs://config/platform/base/": "/gcloud storage rsync --recursive gs://config/platform/base/ ",
+	} {
+		dir, err := FetcherUsingCloudCLI(ref, fSys)
+		require.NoError(t, err)
+		assert.True(t, fSys.Exists(dir.Join("kustomization.yaml")))
+		recorded, err := os.ReadFile(filepath.Join(bin, "args"))
+		require.NoError(t, err)
+		assert.Equal(t, bin+args+dir.String()+"\n", string(recorded))
+		require.NoError(t, fSys.RemoveAll(dir.String()))
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	_, err := FetcherUsingCloudCLI("s3://config/platform/base", fSys)
+	assert.ErrorContains(t, err, "no 'aws' program on path")
+}
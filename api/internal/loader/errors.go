@@ -8,4 +8,10 @@ import "sigs.k8s.io/kustomize/kyaml/errors"
 var (
 	ErrHTTP     = errors.Errorf("HTTP Error")
 	ErrRtNotDir = errors.Errorf("must build at directory")
+	// ErrNotVendored is wrapped by the errors of remote references that
+	// are missing from the vendor directory of a loader.
+	ErrNotVendored = errors.Errorf("not vendored")
+	// ErrNotPinned is wrapped by the errors of remote references that
+	// aren't pinned to immutable content, if a loader requires them to be.
+	ErrNotPinned = errors.Errorf("not pinned")
 )
@@ -11,9 +11,12 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/profile"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
@@ -50,11 +53,14 @@ func IsRemoteFile(path string) bool {
 //
 //	`New` is used to load bases.
 //
-//	A base can be either a remote git repo URL, or
-//	a directory specified relative to the current
-//	root. In the former case, the repo is locally
+//	A base can be either a remote git repo URL, an
+//	OCI artifact reference (oci://...), or a
+//	directory specified relative to the current
+//	root. In the first case, the repo is locally
 //	cloned, and the new loader is rooted on a path
-//	in that clone.
+//	in that clone.  In the second, the package of
+//	the artifact is pulled, and the new loader is
+//	rooted on it.
 //
 //	As loaders create new loaders, a root history
 //	is established, and used to disallow:
@@ -99,11 +105,66 @@ type FileLoader struct {
 	// Used to load from HTTP
 	http *http.Client
 
+	// If this is non-empty, the files were pulled
+	// from the OCI artifact, downloaded from the remote
+	// archive, or fetched from the bucket directory, of
+	// this reference into packageDir.
+	packageRef string
+	packageDir filesys.ConfirmedDir
+
 	// Used to clone repositories.
 	cloner git.Cloner
 
 	// Used to clean up, as needed.
 	cleaner func() error
+
+	// If this is non-nil, the remote fetches of this loader and
+	// its descendants are recorded in it.
+	profile *profile.Profile
+
+	// If this is non-empty, the remote references of this loader and
+	// its descendants are loaded from their copies in it.
+	vendorDir filesys.ConfirmedDir
+
+	// If this is true, the remote references of this loader and its
+	// descendants must be pinned to immutable content.
+	requirePinned bool
+
+	// If this is non-nil, the remote packages of this loader and its
+	// descendants are fetched by the handlers of their schemes, rather
+	// than by the builtin ones, see newPackageHandlers.
+	packageHandlers map[string]packageHandler
+
+	// If this is true, this loader and its descendants load absolute
+	// paths and file:// urls in place, regardless of loadRestrictor.
+	allowAbsolutePaths bool
+
+	// If this is true, this loader and its descendants honor the
+	// allowed paths of their kustomizations, see AllowPaths.
+	enableAllowedPaths bool
+
+	// The directories outside of root whose files this loader may load,
+	// regardless of loadRestrictor.
+	allowedDirs []filesys.ConfirmedDir
+
+	// If this is non-zero, the number of remote bases prefetched
+	// concurrently by this loader and its descendants.
+	fetchConcurrency int
+
+	// The results of the New of the paths prefetched by Prefetch.
+	prefetchMu sync.Mutex
+	prefetched map[string]prefetched
+}
+
+// getProfile returns the profile of the loader at the root of the
+// chain of referrers.
+func (fl *FileLoader) getProfile() *profile.Profile {
+	for l := fl; l != nil; l = l.referrer {
+		if l.profile != nil {
+			return l.profile
+		}
+	}
+	return nil
 }
 
 // Repo returns the absolute path to the repo that contains Root if this fileLoader was created from a url
@@ -115,6 +176,16 @@ func (fl *FileLoader) Repo() string {
 	return ""
 }
 
+// Commit returns the hash of the commit of the repo checked out at Repo if
+// this fileLoader was created from a url and it's known, or the empty
+// string otherwise.
+func (fl *FileLoader) Commit() string {
+	if fl.repoSpec != nil {
+		return fl.repoSpec.Commit
+	}
+	return ""
+}
+
 // Root returns the absolute path that is prepended to any
 // relative paths used in Load.
 func (fl *FileLoader) Root() string {
@@ -148,11 +219,44 @@ func newLoaderAtConfirmedDir(
 }
 
 // New returns a new Loader, rooted relative to current loader,
-// or rooted in a temp directory holding a git repo clone.
+// or rooted in a temp directory holding a git repo clone, or
+// the remote package fetched by the handler of its scheme, e.g.
+// an OCI artifact, a remote archive or a bucket directory.
 func (fl *FileLoader) New(path string) (ifc.Loader, error) {
 	if path == "" {
 		return nil, errors.Errorf("new root cannot be empty")
 	}
+	if result, found := fl.takePrefetched(path); found {
+		return result.ldr, result.err
+	}
+	if dir, ok := fl.allowedAbsolutePath(path); ok {
+		return fl.newLoaderAtDir(dir)
+	}
+	// Archives are handled before git repos, whose urls they may look like.
+	handler := fl.getPackageHandler(path)
+	if fl.getRequirePinned() {
+		if err := errIfNotPinned(path, handler); err != nil {
+			return nil, err
+		}
+	}
+
+	if handler != nil {
+		if err := fl.errIfPackageCycle(path); err != nil {
+			return nil, err
+		}
+		if vendorDir := fl.getVendorDir(); vendorDir != "" {
+			return nil, notVendoredPackageError(path, vendorDir)
+		}
+		start := time.Now()
+		ldr, err := newLoaderAtPackage(
+			path, fl.fSys, fl, fl.cloner, handler)
+		fl.getProfile().Add(profile.Entry{
+			Kind: profile.KindRemote, Name: path, Root: fl.Root(), Duration: time.Since(start)})
+		if err != nil {
+			return nil, err
+		}
+		return ldr, nil
+	}
 
 	repoSpec, err := git.NewRepoSpecFromURL(path)
 	if err == nil {
@@ -160,20 +264,40 @@ func (fl *FileLoader) New(path string) (ifc.Loader, error) {
 		if err = fl.errIfRepoCycle(repoSpec); err != nil {
 			return nil, err
 		}
-		return newLoaderAtGitClone(
+		if vendorDir := fl.getVendorDir(); vendorDir != "" {
+			return newLoaderAtVendoredRepo(repoSpec, fl.fSys, vendorDir, fl, fl.cloner)
+		}
+		start := time.Now()
+		ldr, err := newLoaderAtGitClone(
 			repoSpec, fl.fSys, fl, fl.cloner)
+		fl.getProfile().Add(profile.Entry{
+			Kind: profile.KindRemote, Name: path, Root: fl.Root(), Duration: time.Since(start)})
+		if err != nil {
+			return nil, err
+		}
+		return ldr, nil
 	}
 
 	if filepath.IsAbs(path) {
 		return nil, fmt.Errorf("new root '%s' cannot be absolute", path)
 	}
-	root, err := filesys.ConfirmDir(fl.fSys, fl.root.Join(path))
+	return fl.newLoaderAtDir(fl.root.Join(path))
+}
+
+// newLoaderAtDir returns a new Loader rooted at the local directory dir,
+// which must be within the repo or package of the loader, if any, and
+// not equal to or above the root of an ancestor.
+func (fl *FileLoader) newLoaderAtDir(dir string) (ifc.Loader, error) {
+	root, err := filesys.ConfirmDir(fl.fSys, dir)
 	if err != nil {
 		return nil, errors.WrapPrefixf(err, ErrRtNotDir.Error())
 	}
 	if err = fl.errIfGitContainmentViolation(root); err != nil {
 		return nil, err
 	}
+	if err = fl.errIfPackageContainmentViolation(root); err != nil {
+		return nil, err
+	}
 	if err = fl.errIfArgEqualOrHigher(root); err != nil {
 		return nil, err
 	}
@@ -185,13 +309,21 @@ func (fl *FileLoader) New(path string) (ifc.Loader, error) {
 // directory holding a cloned git repo.
 func newLoaderAtGitClone(
 	repoSpec *git.RepoSpec, fSys filesys.FileSystem,
-	referrer *FileLoader, cloner git.Cloner) (ifc.Loader, error) {
+	referrer *FileLoader, cloner git.Cloner) (*FileLoader, error) {
 	cleaner := repoSpec.Cleaner(fSys)
 	err := cloner(repoSpec)
 	if err != nil {
 		cleaner()
 		return nil, err
 	}
+	return newLoaderAtRepo(repoSpec, fSys, referrer, cloner, cleaner)
+}
+
+// newLoaderAtRepo returns a new Loader pinned to the directory
+// holding the repo of repoSpec.
+func newLoaderAtRepo(
+	repoSpec *git.RepoSpec, fSys filesys.FileSystem,
+	referrer *FileLoader, cloner git.Cloner, cleaner func() error) (*FileLoader, error) {
 	root, f, err := fSys.CleanedAbs(repoSpec.AbsPath())
 	if err != nil {
 		cleaner()
@@ -291,27 +423,71 @@ func (fl *FileLoader) errIfRepoCycle(newRepoSpec *git.RepoSpec) error {
 // else an error. Relative paths are taken relative
 // to the root.
 func (fl *FileLoader) Load(path string) ([]byte, error) {
+	if IsRemoteArchive(path) {
+		// Archives are bases, loaded by New, rather than files.
+		return nil, fmt.Errorf("'%s' is a remote archive; expecting file", path)
+	}
 	if IsRemoteFile(path) {
-		return fl.httpClientGetContent(path)
+		return fl.loadRemoteFile(path)
+	}
+	if abs, ok := fl.allowedAbsolutePath(path); ok {
+		return fl.loadAbsolute(abs)
 	}
 	if !filepath.IsAbs(path) {
 		path = fl.root.Join(path)
 	}
-	path, err := fl.loadRestrictor(fl.fSys, fl.root, path)
+	restricted, err := fl.loadRestrictor(fl.fSys, fl.root, path)
 	if err != nil {
+		if allowed, ok := fl.allowedPath(path); ok {
+			return fl.fSys.ReadFile(allowed)
+		}
 		return nil, err
 	}
-	return fl.fSys.ReadFile(path)
+	return fl.fSys.ReadFile(restricted)
 }
 
-func (fl *FileLoader) httpClientGetContent(path string) ([]byte, error) {
-	var hc *http.Client
-	if fl.http != nil {
-		hc = fl.http
+// loadRemoteFile returns the content of the remote file at path, from the
+// vendor directory if there's one, verified against its sha256 digest if
+// it has one.
+func (fl *FileLoader) loadRemoteFile(path string) ([]byte, error) {
+	fileURL, digest, err := splitRemoteFileDigest(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := fl.errIfRemoteFileNotPinned(path, digest); err != nil {
+		return nil, err
+	}
+	var content []byte
+	if vendorDir := fl.getVendorDir(); vendorDir != "" {
+		content, err = loadVendored(fl.fSys, vendorDir, path)
 	} else {
-		hc = &http.Client{}
+		start := time.Now()
+		content, err = fl.httpClientGetContent(fileURL)
+		fl.getProfile().Add(profile.Entry{
+			Kind: profile.KindRemote, Name: path, Root: fl.Root(), Duration: time.Since(start)})
+	}
+	if err != nil || digest == "" {
+		return content, err
+	}
+	if err := checkSha256(content, digest); err != nil {
+		return nil, errors.WrapPrefixf(err, "remote file %q", path)
 	}
-	resp, err := hc.Get(path)
+	return content, nil
+}
+
+// httpClient returns the http client of the loader at the root of the
+// chain of referrers, or a default one if there's none.
+func (fl *FileLoader) httpClient() *http.Client {
+	for l := fl; l != nil; l = l.referrer {
+		if l.http != nil {
+			return l.http
+		}
+	}
+	return &http.Client{}
+}
+
+func (fl *FileLoader) httpClientGetContent(path string) ([]byte, error) {
+	resp, err := fl.httpClient().Get(path)
 	if err != nil {
 		return nil, errors.Wrap(err)
 	}
@@ -328,7 +504,9 @@ func (fl *FileLoader) httpClientGetContent(path string) ([]byte, error) {
 	return content, errors.Wrap(err)
 }
 
-// Cleanup runs the cleaner.
+// Cleanup runs the cleaner, after cleaning up the prefetched loaders
+// which weren't used.
 func (fl *FileLoader) Cleanup() error {
+	fl.cleanupPrefetched()
 	return fl.cleaner()
 }
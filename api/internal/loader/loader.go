@@ -5,13 +5,21 @@
 package loader
 
 import (
+	"net/http"
+	"time"
+
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/profile"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 // NewLoader returns a Loader pointed at the given target.
+// The target may be a git repo URL, an OCI artifact
+// reference, e.g. oci://registry.example.com/base:v1,
+// the https URL of an archive pinned by its sha256, or
+// a bucket directory, e.g. s3://bucket/base.
 // If the target is remote, the loader will be restricted
 // to the root and below only.  If the target is local, the
 // loader will have the restrictions passed in.  Regardless,
@@ -20,16 +28,151 @@ import (
 func NewLoader(
 	lr LoadRestrictorFunc,
 	target string, fSys filesys.FileSystem) (ifc.Loader, error) {
+	return NewLoaderWithOptions(lr, target, fSys, Options{})
+}
+
+// Options are the options of a Loader and its descendants.
+type Options struct {
+	// If non-nil, the fetches of remote files and repositories are
+	// recorded in Profile.
+	Profile *profile.Profile
+
+	// If non-empty, the remote references are loaded from their copies in
+	// VendorDir rather than fetched.  The copies are laid out like the
+	// remote content of a localized directory, see VendoredFilePath and
+	// VendoredRepoPath, and it's an error for one to be missing.
+	VendorDir string
+
+	// Cloner clones the git repos which aren't vendored, defaults to
+	// git.ClonerUsingGitExec.
+	Cloner git.Cloner
+
+	// If true, the target and the remote references must be pinned to
+	// immutable content: git repos by commit hashes, OCI artifacts by
+	// digests and remote files by their sha256, e.g. ?sha256=<digest>.
+	// Remote archives are always pinned by their sha256, and bucket
+	// directories can't be pinned.  The errors of the references which
+	// aren't pinned wrap ErrNotPinned.
+	RequirePinned bool
+
+	// SchemeHandlers load the target and the remote references with
+	// custom url schemes, e.g. artifactory://repo/platform/base, by
+	// scheme.  The handlers take precedence over the builtin schemes,
+	// e.g. oci or s3, but http, https and file can't be handled.
+	SchemeHandlers map[string]SchemeHandler
+
+	// HTTPClient fetches the remote files, archives and OCI artifacts,
+	// defaults to a client without a timeout.
+	HTTPClient *http.Client
+
+	// FetchConcurrency is the number of remote bases fetched concurrently
+	// by Prefetch, defaults to DefaultFetchConcurrency.  With 1, they're
+	// fetched one at a time.
+	FetchConcurrency int
+
+	// If true, absolute paths and file:// urls, e.g. file:///nix/store/...,
+	// are loaded in place, even if they're outside of the root of their
+	// kustomization, rather than rejected by the LoadRestrictorFunc.  They
+	// must still be within the git repo or package of a remote
+	// kustomization.  The file:// urls with a query or a // are git repos.
+	AllowAbsolutePaths bool
+
+	// If true, the kustomizations may load the files below the directories
+	// of their allowedPaths field, regardless of the LoadRestrictorFunc,
+	// see FileLoader.AllowPaths.
+	EnableAllowedPaths bool
+}
+
+// NewLoaderWithOptions returns a Loader like NewLoader with the options of
+// opts.
+func NewLoaderWithOptions(
+	lr LoadRestrictorFunc,
+	target string, fSys filesys.FileSystem, opts Options) (ifc.Loader, error) {
+	if err := validateSchemeHandlers(opts.SchemeHandlers); err != nil {
+		return nil, err
+	}
+	if opts.Cloner == nil {
+		opts.Cloner = git.ClonerUsingGitExec
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{}
+	}
+	handlers := newPackageHandlers(opts.HTTPClient, opts.SchemeHandlers)
+	handler := packageHandlerOf(handlers, target)
+	if opts.RequirePinned {
+		if err := errIfNotPinned(target, handler); err != nil {
+			return nil, err
+		}
+	}
+	var ldr *FileLoader
+	var err error
+	if handler != nil {
+		if opts.VendorDir != "" {
+			return nil, notVendoredPackageError(target, filesys.ConfirmedDir(opts.VendorDir))
+		}
+		start := time.Now()
+		ldr, err = newLoaderAtPackage(target, fSys, nil, opts.Cloner, handler)
+		opts.Profile.Add(profile.Entry{Kind: profile.KindRemote, Name: target, Duration: time.Since(start)})
+		if err != nil {
+			return nil, err
+		}
+		ldr.profile = opts.Profile
+	} else {
+		ldr, err = newVendoredLoader(lr, target, fSys, opts.Profile, opts.VendorDir, opts.Cloner)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ldr.requirePinned = opts.RequirePinned
+	ldr.packageHandlers = handlers
+	ldr.http = opts.HTTPClient
+	ldr.fetchConcurrency = opts.FetchConcurrency
+	ldr.allowAbsolutePaths = opts.AllowAbsolutePaths
+	ldr.enableAllowedPaths = opts.EnableAllowedPaths
+	return ldr, nil
+}
+
+func newVendoredLoader(
+	lr LoadRestrictorFunc,
+	target string, fSys filesys.FileSystem, p *profile.Profile,
+	vendorDir string, cloner git.Cloner) (*FileLoader, error) {
+	var vDir filesys.ConfirmedDir
+	if vendorDir != "" {
+		var err error
+		vDir, err = filesys.ConfirmDir(fSys, vendorDir)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "invalid vendor directory")
+		}
+	}
 	repoSpec, err := git.NewRepoSpecFromURL(target)
+	if err == nil && vDir != "" {
+		ldr, err := newLoaderAtVendoredRepo(repoSpec, fSys, vDir, nil, cloner)
+		if err != nil {
+			return nil, err
+		}
+		ldr.profile = p
+		ldr.vendorDir = vDir
+		return ldr, nil
+	}
 	if err == nil {
 		// The target qualifies as a remote git target.
-		return newLoaderAtGitClone(
-			repoSpec, fSys, nil, git.ClonerUsingGitExec)
+		start := time.Now()
+		ldr, err := newLoaderAtGitClone(
+			repoSpec, fSys, nil, cloner)
+		p.Add(profile.Entry{Kind: profile.KindRemote, Name: target, Duration: time.Since(start)})
+		if err != nil {
+			return nil, err
+		}
+		ldr.profile = p
+		return ldr, nil
 	}
 	root, err := filesys.ConfirmDir(fSys, target)
 	if err != nil {
 		return nil, errors.WrapPrefixf(err, ErrRtNotDir.Error())
 	}
-	return newLoaderAtConfirmedDir(
-		lr, root, fSys, nil, git.ClonerUsingGitExec), nil
+	ldr := newLoaderAtConfirmedDir(
+		lr, root, fSys, nil, cloner)
+	ldr.profile = p
+	ldr.vendorDir = vDir
+	return ldr, nil
 }
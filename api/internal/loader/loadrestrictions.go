@@ -6,6 +6,7 @@ package loader
 import (
 	"fmt"
 
+	"sigs.k8s.io/kustomize/api/internal/git"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
@@ -29,6 +30,30 @@ func RestrictionRootOnly(
 	return d.Join(f), nil
 }
 
+// RestrictionRootOfRepo is like RestrictionRootOnly, but permits the files
+// anywhere in the git repository enclosing root, e.g. the shared files of a
+// monorepo.  If root isn't in a git repository, it's RestrictionRootOnly.
+func RestrictionRootOfRepo(
+	fSys filesys.FileSystem, root filesys.ConfirmedDir, path string) (string, error) {
+	repoRoot, found := git.FindRepoRoot(fSys, root)
+	if !found {
+		return RestrictionRootOnly(fSys, root, path)
+	}
+	d, f, err := fSys.CleanedAbs(path)
+	if err != nil {
+		return "", err
+	}
+	if f == "" {
+		return "", fmt.Errorf("'%s' must resolve to a file", path)
+	}
+	if !d.HasPrefix(repoRoot) {
+		return "", fmt.Errorf(
+			"security; file '%s' is not in or below '%s', the root of the git repository of '%s'",
+			path, repoRoot, root)
+	}
+	return d.Join(f), nil
+}
+
 func RestrictionNone(
 	_ filesys.FileSystem, _ filesys.ConfirmedDir, path string) (string, error) {
 	return path, nil
@@ -65,3 +65,38 @@ func TestRestrictionRootOnly(t *testing.T) {
 		t.Fatalf("unexpected err: %s", err)
 	}
 }
+
+func TestRestrictionRootOfRepo(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	root := filesys.ConfirmedDir(
+		filesys.Separator + filepath.Join("repo", "apps", "foo"))
+	shared := filepath.Join(filesys.Separator+"repo", "shared", "beans")
+	fSys.Create(shared)
+
+	// Without a repository, it's restricted to the root.
+	_, err := RestrictionRootOfRepo(fSys, root, shared)
+	if err == nil || !strings.Contains(err.Error(),
+		"file '/repo/shared/beans' is not in or below '/repo/apps/foo'") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if err = fSys.MkdirAll(filepath.Join(filesys.Separator+"repo", ".git")); err != nil {
+		t.Fatal(err)
+	}
+	p, err := RestrictionRootOfRepo(fSys, root, shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != shared {
+		t.Fatalf("expected '%s', got '%s'", shared, p)
+	}
+
+	// Illegal; file exists but is outside of the repository.
+	path := filepath.Join(filesys.Separator+"tmp", "illegal")
+	fSys.Create(path)
+	_, err = RestrictionRootOfRepo(fSys, root, path)
+	if err == nil || !strings.Contains(err.Error(),
+		"file '/tmp/illegal' is not in or below '/repo', the root of the git repository of '/repo/apps/foo'") {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"fmt"
+	"net/http"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// OCIPuller is a function that pulls the kustomize package of an OCI
+// artifact, e.g. oci://registry.example.com/platform/base:v1.2.3, into a
+// new directory, and returns the directory.
+type OCIPuller func(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, error)
+
+// PullerUsingOCIClient pulls the package of the artifact from its registry
+// into a temporary directory.  The digest of an artifact referenced by
+// digest, e.g. oci://registry.example.com/platform/base@sha256:..., is
+// verified.
+func PullerUsingOCIClient(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, error) {
+	return pullerUsingHTTPClient(nil)(ref, fSys)
+}
+
+// pullerUsingHTTPClient returns a puller like PullerUsingOCIClient, which
+// accesses the registries with hc, or http.DefaultClient if it's nil.
+func pullerUsingHTTPClient(hc *http.Client) OCIPuller {
+	return func(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, error) {
+		dir, err := filesys.NewTmpConfirmedDir()
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		if err := (kio.OCIReader{Reference: ref, Client: hc}).Extract(fSys, dir.String()); err != nil {
+			_ = fSys.RemoveAll(dir.String())
+			return "", err
+		}
+		return dir, nil
+	}
+}
+
+// ociHandler is the packageHandler of OCI artifacts, which pulls them
+// with puller.
+type ociHandler struct {
+	puller OCIPuller
+}
+
+func (h ociHandler) handles(ref string) bool {
+	return kio.IsOCIReference(ref)
+}
+
+func (h ociHandler) fetch(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, filesys.ConfirmedDir, error) {
+	dir, err := h.puller(ref, fSys)
+	if err != nil {
+		return "", "", errors.WrapPrefixf(err, "pulling OCI artifact %q", ref)
+	}
+	if !fSys.IsDir(dir.String()) {
+		_ = fSys.RemoveAll(dir.String())
+		return "", "", fmt.Errorf("OCI artifact %q has no package", ref)
+	}
+	return dir, dir, nil
+}
+
+func (h ociHandler) errIfNotPinned(ref string) error {
+	if !kio.IsPinnedOCIReference(ref) {
+		return fmt.Errorf("%w: OCI artifact %q must be referenced by digest, e.g. @sha256:<digest>",
+			ErrNotPinned, ref)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const baseRef = "oci://registry.example.com/platform/base:v1.2.3"
+
+// fakePuller "pulls" the artifacts by returning their directories in
+// dirs, which the test has written the packages to.
+func fakePuller(dirs map[string]string) OCIPuller {
+	return func(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, error) {
+		dir, ok := dirs[ref]
+		if !ok {
+			return "", errors.New("manifest unknown")
+		}
+		return filesys.ConfirmedDir(dir), nil
+	}
+}
+
+func TestNewLoaderAtOCIArtifact(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/app/overlay"))
+	require.NoError(t, fSys.MkdirAll("/pulled/base/components"))
+	require.NoError(t, fSys.WriteFile("/pulled/base/kustomization.yaml", []byte("resources: []\n")))
+	require.NoError(t, fSys.WriteFile("/app/secret.env", []byte("TOKEN=x\n")))
+
+	overlay := newLoaderAtConfirmedDir(
+		RestrictionNone, filesys.ConfirmedDir("/app/overlay"), fSys, nil, git.ClonerUsingGitExec)
+	overlay.packageHandlers = map[string]packageHandler{
+		"oci": ociHandler{puller: fakePuller(map[string]string{baseRef: "/pulled/base"})},
+	}
+
+	l, err := overlay.New(baseRef)
+	require.NoError(t, err)
+	assert.Equal(t, "/pulled/base", l.Root())
+	content, err := l.Load("kustomization.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "resources: []\n", string(content))
+
+	// the package can't load files outside of it
+	_, err = l.Load("/app/secret.env")
+	assert.ErrorContains(t, err, "is not in or below '/pulled/base'")
+
+	// nor have bases outside of it
+	components, err := l.New("components")
+	require.NoError(t, err)
+	assert.Equal(t, "/pulled/base/components", components.Root())
+	_, err = components.New("../../../app")
+	assert.ErrorContains(t, err,
+		"bases in kustomizations found in '"+baseRef+"' must be within it")
+
+	_, err = components.New(baseRef)
+	assert.EqualError(t, err, "cycle detected: '"+baseRef+"' referenced by itself")
+
+	_, err = overlay.New("oci://registry.example.com/platform/missing:v1")
+	assert.EqualError(t, err,
+		`pulling OCI artifact "oci://registry.example.com/platform/missing:v1": manifest unknown`)
+
+	require.NoError(t, l.Cleanup())
+	assert.False(t, fSys.Exists("/pulled/base"))
+}
+
+func TestNewLoaderAtOCIArtifactVendored(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/app/vendor"))
+	ldr, err := NewLoaderWithOptions(RestrictionRootOnly, "/app", fSys, Options{VendorDir: "/app/vendor"})
+	require.NoError(t, err)
+	_, err = ldr.New(baseRef)
+	assert.ErrorIs(t, err, ErrNotVendored)
+
+	_, err = NewLoaderWithOptions(RestrictionRootOnly, baseRef, fSys, Options{VendorDir: "/app/vendor"})
+	assert.ErrorIs(t, err, ErrNotVendored)
+}
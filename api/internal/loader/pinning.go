@@ -0,0 +1,74 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/git"
+)
+
+// errIfNotPinned returns an error if ref is a remote reference which may
+// load other content in later builds: a git repo whose ref isn't a commit
+// hash, or a remote package which handler, if non-nil, doesn't consider
+// pinned, e.g. an OCI artifact referenced by tag.  Remote files are
+// checked by Load, see splitRemoteFileDigest.
+func errIfNotPinned(ref string, handler packageHandler) error {
+	if handler != nil {
+		return handler.errIfNotPinned(ref)
+	}
+	repoSpec, err := git.NewRepoSpecFromURL(ref)
+	if err == nil && !git.IsCommitHash(repoSpec.Ref) {
+		return fmt.Errorf("%w: git repo %q must be referenced by the full hash of a commit, e.g. ?ref=<hash>",
+			ErrNotPinned, ref)
+	}
+	return nil
+}
+
+// getRequirePinned returns true if the loader at the root of the chain of
+// referrers requires the remote references to be pinned.
+func (fl *FileLoader) getRequirePinned() bool {
+	for l := fl; l != nil; l = l.referrer {
+		if l.requirePinned {
+			return true
+		}
+	}
+	return false
+}
+
+// splitRemoteFileDigest returns the url of the remote file fileURL without
+// its sha256 query parameter, which pins its content like that of a remote
+// archive, e.g. https://example.com/deployment.yaml?sha256=<digest>, and
+// the digest, or the empty string if it has none.
+func splitRemoteFileDigest(fileURL string) (string, string, error) {
+	u, query, _ := strings.Cut(fileURL, "?")
+	values, err := url.ParseQuery(query)
+	// a malformed query is left to the server
+	if err != nil || !values.Has("sha256") {
+		return fileURL, "", nil
+	}
+	digest := strings.ToLower(values.Get("sha256"))
+	if !sha256HexPattern.MatchString(digest) {
+		return "", "", fmt.Errorf(
+			"remote file %q must be pinned by the hex encoded sha256 digest of its content, "+
+				"e.g. ?sha256=<digest>", fileURL)
+	}
+	values.Del("sha256")
+	if len(values) > 0 {
+		u += "?" + values.Encode()
+	}
+	return u, digest, nil
+}
+
+// errIfRemoteFileNotPinned returns an error if the remote file fileURL
+// has no digest while the remote references must be pinned.
+func (fl *FileLoader) errIfRemoteFileNotPinned(fileURL, digest string) error {
+	if digest == "" && fl.getRequirePinned() {
+		return fmt.Errorf("%w: remote file %q must be pinned by the sha256 digest of its content, "+
+			"e.g. ?sha256=<digest>", ErrNotPinned, fileURL)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestErrIfNotPinned(t *testing.T) {
+	digest := strings.Repeat("0", 64)
+	for ref, pinned := range map[string]bool{
+		"base":                                   true,
+		"../base":                                true,
+		"https://example.com/resource.yaml":      true,
+		"https://github.com/org/repo//base":      false,
+		"https://github.com/org/repo?ref=main":   false,
+		"https://github.com/org/repo?ref=v1.0.0": false,
+		"https://github.com/org/repo?ref=a428de44a9059f31a59237a5881c2d2cffa93757": true,
+		"oci://registry.example.com/platform/base:v1.2.3":                          false,
+		"oci://registry.example.com/platform/base@sha256:" + digest:                true,
+		"https://example.com/config.tgz?sha256=" + digest:                          true,
+		"s3://bucket/platform/base":                                                false,
+	} {
+		err := errIfNotPinned(ref, packageHandlerOf(builtinPackageHandlers(nil), ref))
+		if pinned {
+			assert.NoError(t, err, ref)
+		} else {
+			assert.ErrorIs(t, err, ErrNotPinned, ref)
+		}
+	}
+}
+
+func TestNewLoaderWithOptionsRequirePinned(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/app/base"))
+	ldr, err := NewLoaderWithOptions(RestrictionRootOnly, "/app", fSys, Options{RequirePinned: true})
+	require.NoError(t, err)
+	_, err = ldr.New("base")
+	require.NoError(t, err)
+	_, err = ldr.New("https://github.com/org/repo//base?ref=main")
+	assert.ErrorIs(t, err, ErrNotPinned)
+
+	_, err = NewLoaderWithOptions(RestrictionRootOnly, "s3://bucket/base", fSys, Options{RequirePinned: true})
+	assert.ErrorIs(t, err, ErrNotPinned)
+}
+
+func TestSplitRemoteFileDigest(t *testing.T) {
+	digest := strings.Repeat("a", 64)
+	for ref, expected := range map[string][2]string{
+		"https://example.com/cm.yaml":                                   {"https://example.com/cm.yaml", ""},
+		"https://example.com/cm.yaml?token=x":                           {"https://example.com/cm.yaml?token=x", ""},
+		"https://example.com/cm.yaml?sha256=" + digest:                  {"https://example.com/cm.yaml", digest},
+		"https://example.com/cm.yaml?token=x&sha256=" + digest:          {"https://example.com/cm.yaml?token=x", digest},
+		"https://example.com/cm.yaml?sha256=" + strings.ToUpper(digest): {"https://example.com/cm.yaml", digest},
+	} {
+		fileURL, d, err := splitRemoteFileDigest(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, expected, [2]string{fileURL, d}, ref)
+	}
+	_, _, err := splitRemoteFileDigest("https://example.com/cm.yaml?sha256=abc")
+	require.ErrorContains(t, err, "must be pinned by the hex encoded sha256 digest of its content")
+}
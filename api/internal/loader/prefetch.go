@@ -0,0 +1,119 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/git"
+)
+
+// DefaultFetchConcurrency is the number of remote bases fetched
+// concurrently by Prefetch, unless set by Options.FetchConcurrency.
+const DefaultFetchConcurrency = 4
+
+// prefetched is the result of the New of a prefetched path.
+type prefetched struct {
+	ldr ifc.Loader
+	err error
+}
+
+// Prefetch fetches the remote bases of paths concurrently, at most
+// Options.FetchConcurrency at a time, so that the later calls of New with
+// them return without fetching them.  The other paths, e.g. local
+// directories, are left to New.  Nothing is fetched with a vendor
+// directory, or a concurrency of 1.
+func (fl *FileLoader) Prefetch(paths []string) {
+	concurrency := fl.getFetchConcurrency()
+	if concurrency <= 1 || fl.getVendorDir() != "" {
+		return
+	}
+	var remote []string
+	seen := make(map[string]bool)
+	fl.prefetchMu.Lock()
+	for _, path := range paths {
+		if _, done := fl.prefetched[path]; done || seen[path] || !fl.isRemoteBase(path) {
+			continue
+		}
+		seen[path] = true
+		remote = append(remote, path)
+	}
+	fl.prefetchMu.Unlock()
+	if len(remote) < 2 {
+		// nothing to gain
+		return
+	}
+	results := make([]prefetched, len(remote))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range remote {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			results[i].ldr, results[i].err = fl.New(remote[i])
+		}(i)
+	}
+	wg.Wait()
+	fl.prefetchMu.Lock()
+	defer fl.prefetchMu.Unlock()
+	if fl.prefetched == nil {
+		fl.prefetched = make(map[string]prefetched, len(remote))
+	}
+	for i, path := range remote {
+		fl.prefetched[path] = results[i]
+	}
+}
+
+// isRemoteBase returns true if New fetches path from a remote source.
+func (fl *FileLoader) isRemoteBase(path string) bool {
+	if fl.getPackageHandler(path) != nil {
+		return true
+	}
+	// New warns about the git:: prefix, which shouldn't be repeated here
+	if len(path) > len("git::") && strings.EqualFold(path[:len("git::")], "git::") {
+		path = path[len("git::"):]
+	}
+	_, err := git.NewRepoSpecFromURL(path)
+	return err == nil
+}
+
+// takePrefetched returns the result of the New of path by Prefetch, if
+// it was prefetched and not yet taken.
+func (fl *FileLoader) takePrefetched(path string) (prefetched, bool) {
+	fl.prefetchMu.Lock()
+	defer fl.prefetchMu.Unlock()
+	result, found := fl.prefetched[path]
+	if found {
+		delete(fl.prefetched, path)
+	}
+	return result, found
+}
+
+// cleanupPrefetched cleans up the loaders of the prefetched paths which
+// weren't taken.
+func (fl *FileLoader) cleanupPrefetched() {
+	fl.prefetchMu.Lock()
+	defer fl.prefetchMu.Unlock()
+	for path, result := range fl.prefetched {
+		if result.ldr != nil {
+			_ = result.ldr.Cleanup()
+		}
+		delete(fl.prefetched, path)
+	}
+}
+
+// getFetchConcurrency returns the fetch concurrency of the loader at the
+// root of the chain of referrers, or DefaultFetchConcurrency if there's
+// none.
+func (fl *FileLoader) getFetchConcurrency() int {
+	for l := fl; l != nil; l = l.referrer {
+		if l.fetchConcurrency != 0 {
+			return l.fetchConcurrency
+		}
+	}
+	return DefaultFetchConcurrency
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// countingCloner "clones" the repos of github.com/org into their
+// directories in /repos, counting the clones of each repo and the most
+// clones at a time.
+type countingCloner struct {
+	mu        sync.Mutex
+	active    int
+	maxActive int
+	clones    map[string]int
+}
+
+func (c *countingCloner) clone(repoSpec *git.RepoSpec) error {
+	name := strings.TrimSuffix(path.Base(repoSpec.CloneSpec()), ".git")
+	c.mu.Lock()
+	c.active++
+	if c.active > c.maxActive {
+		c.maxActive = c.active
+	}
+	c.clones[name]++
+	c.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	c.mu.Lock()
+	c.active--
+	c.mu.Unlock()
+	repoSpec.Dir = filesys.ConfirmedDir("/repos/" + name)
+	return nil
+}
+
+func TestPrefetch(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/app/local"))
+	var refs []string
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, fSys.MkdirAll("/repos/"+name+"/base"))
+		refs = append(refs, "https://github.com/org/"+name+"//base?ref=v1")
+	}
+	cloner := &countingCloner{clones: map[string]int{}}
+	ldr, err := NewLoaderWithOptions(RestrictionRootOnly, "/app", fSys, Options{
+		Cloner: cloner.clone, FetchConcurrency: 2})
+	require.NoError(t, err)
+	fl, ok := ldr.(*FileLoader)
+	require.True(t, ok)
+
+	fl.Prefetch(append([]string{"local", refs[0]}, refs...))
+	assert.Equal(t, 2, cloner.maxActive)
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1, "d": 1, "e": 1}, cloner.clones)
+
+	// the prefetched loaders are used once
+	l, err := fl.New(refs[0])
+	require.NoError(t, err)
+	assert.Equal(t, "/repos/a/base", l.Root())
+	assert.Equal(t, 1, cloner.clones["a"])
+	_, err = fl.New(refs[0])
+	require.NoError(t, err)
+	assert.Equal(t, 2, cloner.clones["a"])
+	l, err = fl.New("local")
+	require.NoError(t, err)
+	assert.Equal(t, "/app/local", l.Root())
+
+	// the prefetched loaders which weren't used are cleaned up
+	require.NoError(t, fl.Cleanup())
+	assert.True(t, fSys.Exists("/repos/a"))
+	assert.False(t, fSys.Exists("/repos/b"))
+}
+
+func TestPrefetch_disabled(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("/app/vendor"))
+	cloner := &countingCloner{clones: map[string]int{}}
+	for _, opts := range []Options{
+		{Cloner: cloner.clone, FetchConcurrency: 1},
+		{Cloner: cloner.clone, VendorDir: "/app/vendor"},
+	} {
+		ldr, err := NewLoaderWithOptions(RestrictionNone, "/app", fSys, opts)
+		require.NoError(t, err)
+		ldr.(*FileLoader).Prefetch([]string{
+			"https://github.com/org/a//base?ref=v1", "https://github.com/org/b//base?ref=v1"})
+	}
+	assert.Empty(t, cloner.clones)
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// errIfPackageContainmentViolation returns an error if base is outside the
+// OCI artifact, remote archive or bucket directory the loader, or one of
+// its referrers, loads.
+func (fl *FileLoader) errIfPackageContainmentViolation(
+	base filesys.ConfirmedDir) error {
+	for l := fl; l != nil; l = l.referrer {
+		if l.packageRef != "" && !base.HasPrefix(l.packageDir) {
+			return fmt.Errorf(
+				"security; bases in kustomizations found in "+
+					"'%s' must be within it, "+
+					"but base '%s' is outside '%s'",
+				l.packageRef, base, l.packageDir)
+		}
+	}
+	return nil
+}
+
+// errIfPackageCycle returns an error if the loader, or one of its
+// referrers, loads the OCI artifact, remote archive or bucket directory
+// of ref.
+func (fl *FileLoader) errIfPackageCycle(ref string) error {
+	for l := fl; l != nil; l = l.referrer {
+		if l.packageRef == ref {
+			return fmt.Errorf(
+				"cycle detected: '%s' referenced by itself", ref)
+		}
+	}
+	return nil
+}
+
+func notVendoredPackageError(ref string, vendorDir filesys.ConfirmedDir) error {
+	return fmt.Errorf("%w: remote reference %q can't be loaded from vendor directory %q", ErrNotVendored, ref, vendorDir)
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// SchemeHandler loads the remote references with a custom url scheme,
+// e.g. artifactory://repo/platform/base, in resources and components, by
+// fetching their files into directories which are built like local
+// directories.  The handlers are registered by scheme, without "://".
+// Fetch may be called concurrently, see Prefetch.
+type SchemeHandler interface {
+	// Fetch fetches the files of ref into a new directory, and returns
+	// the directory.  The directory is removed when the loader of ref is
+	// cleaned up.
+	Fetch(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, error)
+
+	// IsPinned returns true if ref always fetches the same files, e.g.
+	// because it has a version which can't be moved.  Loaders requiring
+	// pinned references fail on the others.
+	IsPinned(ref string) bool
+}
+
+// reservedSchemes are the schemes which can't be handled by a SchemeHandler,
+// because remote files are loaded with them too.
+var reservedSchemes = []string{"http", "https", "file"}
+
+// validateSchemeHandlers returns an error if a scheme of handlers is
+// reserved or malformed.
+func validateSchemeHandlers(handlers map[string]SchemeHandler) error {
+	for scheme, handler := range handlers {
+		if scheme == "" || strings.ContainsAny(scheme, ":/") {
+			return fmt.Errorf("invalid scheme %q of a scheme handler, expecting e.g. %q", scheme, "artifactory")
+		}
+		for _, reserved := range reservedSchemes {
+			if strings.EqualFold(scheme, reserved) {
+				return fmt.Errorf("scheme %q can't be handled by a scheme handler", scheme)
+			}
+		}
+		if handler == nil {
+			return fmt.Errorf("scheme handler of %q is nil", scheme)
+		}
+	}
+	return nil
+}
+
+// packageHandler fetches the packages of the remote references it handles,
+// e.g. OCI artifacts, into directories which are built like local
+// directories.  The handlers are registered by scheme, see
+// newPackageHandlers.
+type packageHandler interface {
+	// handles returns true if ref, which has the scheme of the handler,
+	// is a package of the handler rather than e.g. a git repo.
+	handles(ref string) bool
+
+	// fetch fetches the package of ref into a new directory, and returns
+	// the directory and the root of the kustomization of ref in it.
+	fetch(ref string, fSys filesys.FileSystem) (dir, root filesys.ConfirmedDir, err error)
+
+	// errIfNotPinned returns an error wrapping ErrNotPinned if ref may
+	// fetch other content in later builds.
+	errIfNotPinned(ref string) error
+}
+
+// builtinPackageHandlers returns the handlers of the builtin remote
+// packages by scheme: OCI artifacts, bucket directories and remote
+// archives, which are fetched with hc.
+func builtinPackageHandlers(hc *http.Client) map[string]packageHandler {
+	bucket := bucketHandler{fetcher: FetcherUsingCloudCLI}
+	archive := archiveHandler{client: hc}
+	return map[string]packageHandler{
+		"oci":   ociHandler{puller: pullerUsingHTTPClient(hc)},
+		"s3":    bucket,
+		"gs":    bucket,
+		"http":  archive,
+		"https": archive,
+	}
+}
+
+// newPackageHandlers returns the builtin package handlers and the scheme
+// handlers, which take precedence over them, by lower case scheme.
+func newPackageHandlers(hc *http.Client, schemeHandlers map[string]SchemeHandler) map[string]packageHandler {
+	handlers := builtinPackageHandlers(hc)
+	for scheme, h := range schemeHandlers {
+		handlers[strings.ToLower(scheme)] = customSchemeHandler{SchemeHandler: h}
+	}
+	return handlers
+}
+
+// packageHandlerOf returns the handler of ref in handlers, or nil if ref
+// isn't a remote package.
+func packageHandlerOf(handlers map[string]packageHandler, ref string) packageHandler {
+	scheme, _, found := strings.Cut(ref, "://")
+	if !found {
+		return nil
+	}
+	handler := handlers[strings.ToLower(scheme)]
+	if handler == nil || !handler.handles(ref) {
+		return nil
+	}
+	return handler
+}
+
+// getPackageHandler returns the handler of ref of the loader at the root
+// of the chain of referrers, or of the builtin packages if there's none,
+// or nil if ref isn't a remote package.
+func (fl *FileLoader) getPackageHandler(ref string) packageHandler {
+	for l := fl; l != nil; l = l.referrer {
+		if l.packageHandlers != nil {
+			return packageHandlerOf(l.packageHandlers, ref)
+		}
+	}
+	return packageHandlerOf(builtinPackageHandlers(fl.httpClient()), ref)
+}
+
+// newLoaderAtPackage returns a new Loader pinned to the root of ref in the
+// directory handler fetches the package of ref into.
+func newLoaderAtPackage(
+	ref string, fSys filesys.FileSystem,
+	referrer *FileLoader, cloner git.Cloner, handler packageHandler) (*FileLoader, error) {
+	dir, root, err := handler.fetch(ref, fSys)
+	if err != nil {
+		return nil, err
+	}
+	return &FileLoader{
+		// Packages never allowed to escape root.
+		loadRestrictor: RestrictionRootOnly,
+		root:           root,
+		referrer:       referrer,
+		packageRef:     ref,
+		packageDir:     dir,
+		fSys:           fSys,
+		cloner:         cloner,
+		cleaner:        func() error { return fSys.RemoveAll(dir.String()) },
+	}, nil
+}
+
+// customSchemeHandler is the packageHandler of a SchemeHandler.
+type customSchemeHandler struct {
+	SchemeHandler
+}
+
+func (h customSchemeHandler) handles(string) bool {
+	return true
+}
+
+func (h customSchemeHandler) fetch(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, filesys.ConfirmedDir, error) {
+	dir, err := h.Fetch(ref, fSys)
+	if err != nil {
+		return "", "", errors.WrapPrefixf(err, "fetching %q", ref)
+	}
+	if !fSys.IsDir(dir.String()) {
+		_ = fSys.RemoveAll(dir.String())
+		return "", "", fmt.Errorf("%q was fetched to %q, which isn't a directory", ref, dir)
+	}
+	return dir, dir, nil
+}
+
+func (h customSchemeHandler) errIfNotPinned(ref string) error {
+	if !h.IsPinned(ref) {
+		return fmt.Errorf("%w: %q isn't pinned by the handler of its scheme", ErrNotPinned, ref)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// fakeSchemeHandler fetches the references by returning their
+// directories in dirs.
+type fakeSchemeHandler map[string]string
+
+func (h fakeSchemeHandler) Fetch(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, error) {
+	return fakePuller(h)(ref, fSys)
+}
+
+func (h fakeSchemeHandler) IsPinned(string) bool {
+	return false
+}
+
+func TestNewLoaderWithOptionsSchemeHandlers(t *testing.T) {
+	const ref = "Artifactory://repo/base"
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("/fetched/base/kustomization.yaml", []byte("resources: []\n")))
+	require.NoError(t, fSys.MkdirAll("/app"))
+	// the schemes are case insensitive
+	handlers := map[string]SchemeHandler{"ARTIFACTORY": fakeSchemeHandler{ref: "/fetched/base"}}
+
+	// the target can be fetched by a handler too
+	ldr, err := NewLoaderWithOptions(
+		RestrictionRootOnly, ref, fSys, Options{SchemeHandlers: handlers})
+	require.NoError(t, err)
+	assert.Equal(t, "/fetched/base", ldr.Root())
+	_, err = ldr.New("../../app")
+	assert.ErrorContains(t, err, "must be within it")
+	require.NoError(t, ldr.Cleanup())
+	assert.False(t, fSys.Exists("/fetched/base"))
+
+	_, err = NewLoaderWithOptions(
+		RestrictionRootOnly, ref, fSys, Options{RequirePinned: true, SchemeHandlers: handlers})
+	assert.ErrorIs(t, err, ErrNotPinned)
+
+	ldr, err = NewLoaderWithOptions(
+		RestrictionRootOnly, "/app", fSys, Options{SchemeHandlers: handlers})
+	require.NoError(t, err)
+	_, err = ldr.New("artifactory://repo/missing")
+	assert.EqualError(t, err, `fetching "artifactory://repo/missing": manifest unknown`)
+
+	for scheme, expected := range map[string]string{
+		"":               "invalid scheme \"\" of a scheme handler",
+		"artifactory://": "invalid scheme \"artifactory://\" of a scheme handler",
+		"HTTPS":          "scheme \"HTTPS\" can't be handled by a scheme handler",
+		"file":           "scheme \"file\" can't be handled by a scheme handler",
+	} {
+		_, err = NewLoaderWithOptions(RestrictionRootOnly, "/app", fSys,
+			Options{SchemeHandlers: map[string]SchemeHandler{scheme: fakeSchemeHandler{}}})
+		assert.ErrorContains(t, err, expected)
+	}
+}
+
+func TestNewLoaderWithOptionsSchemeHandlers_overridesBuiltin(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("/fetched/base/kustomization.yaml", []byte("resources: []\n")))
+	require.NoError(t, fSys.MkdirAll("/app"))
+	handlers := map[string]SchemeHandler{"oci": fakeSchemeHandler{baseRef: "/fetched/base"}}
+
+	ldr, err := NewLoaderWithOptions(
+		RestrictionRootOnly, "/app", fSys, Options{SchemeHandlers: handlers})
+	require.NoError(t, err)
+	base, err := ldr.New(baseRef)
+	require.NoError(t, err)
+	assert.Equal(t, "/fetched/base", base.Root())
+
+	// the other builtin handlers are kept
+	_, err = ldr.New("s3:///base")
+	assert.EqualError(t, err, `bucket reference "s3:///base" has no bucket`)
+}
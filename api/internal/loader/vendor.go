@@ -0,0 +1,191 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// FileSchemeDir is the name of the directory of a vendor directory used
+// to store file-schemed repos.
+const FileSchemeDir = "file-schemed"
+
+// VendoredFilePath converts a remote file URL to the path of its copy
+// relative to a vendor directory, e.g.
+// https://raw.githubusercontent.com/kubernetes-sigs/kustomize/master/api/krusty/testdata/localize/simple/service.yaml ->
+// raw.githubusercontent.com/kubernetes-sigs/kustomize/master/api/krusty/testdata/localize/simple/service.yaml.
+func VendoredFilePath(fileURL string) (string, error) {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+
+	// HTTP requests use the escaped path, so we use it here. Escaped paths also help us
+	// preserve percent-encoding in the original path, in the absence of illegal characters,
+	// in case they have special meaning to the host.
+	// Extraneous '..' parent directory dot-segments should be removed.
+	path := filepath.Join(string(filepath.Separator), filepath.FromSlash(u.EscapedPath()))
+
+	// We intentionally exclude userinfo and port.
+	// Raw github urls are the only type of file urls kustomize officially accepts.
+	// In this case, the path already consists of org, repo, version, and path in repo, in order,
+	// so we can use it as is.
+	return filepath.Join(u.Hostname(), path), nil
+}
+
+// VendoredRepoPath returns the path of the copy of the repo of repoSpec at
+// its ref relative to a vendor directory, e.g. the repo of
+// https://github.com/kubernetes-sigs/kustomize//examples/multibases?ref=v3.3.1 ->
+// github.com/kubernetes-sigs/kustomize/v3.3.1.
+func VendoredRepoPath(repoSpec *git.RepoSpec) (string, error) {
+	host, err := vendoredHost(repoSpec)
+	if err != nil {
+		return "", err
+	}
+	// the git-server-side directory name conventionally (but not universally) ends in .git, which
+	// is conventionally stripped from the client-side directory name used for the clone.
+	localRepoPath := strings.TrimSuffix(repoSpec.RepoPath, ".git")
+
+	// We do not need to escape RepoPath, a path on the git server.
+	// However, like git, we clean dot-segments from RepoPath.
+	// Git does not allow ref value to contain dot-segments, so we reject
+	// those that do rather than let them escape the vendor directory.
+	if err := checkVendoredRef(repoSpec.Ref); err != nil {
+		return "", err
+	}
+	return filepath.Join(host,
+		filepath.Join(string(filepath.Separator), filepath.FromSlash(localRepoPath)),
+		filepath.FromSlash(repoSpec.Ref)), nil
+}
+
+// checkVendoredRef returns an error if ref is absolute or has empty, . or
+// .. segments, none of which git allows.
+func checkVendoredRef(ref string) error {
+	if strings.HasPrefix(ref, "/") || filepath.IsAbs(filepath.FromSlash(ref)) {
+		return fmt.Errorf("invalid ref %q: must not be absolute", ref)
+	}
+	for _, segment := range strings.Split(ref, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid ref %q: must not have empty, . or .. segments", ref)
+		}
+	}
+	return nil
+}
+
+// vendoredHost returns the vendor directory path corresponding to repoSpec.Host
+func vendoredHost(repoSpec *git.RepoSpec) (string, error) {
+	var target string
+	switch scheme, _, _ := strings.Cut(repoSpec.Host, "://"); scheme {
+	case "gh:":
+		// 'gh' was meant to be a local github.com shorthand, in which case
+		// the .gitconfig file could map it to any host. See origin here:
+		// https://github.com/kubernetes-sigs/kustomize/blob/kustomize/v4.5.7/api/internal/git/repospec.go#L203
+		// We give it a special host directory here under the assumption
+		// that we are unlikely to have another host simply named 'gh'.
+		return "gh", nil
+	case "file":
+		// We put file-scheme repos under a special directory to avoid
+		// colluding local absolute paths with hosts.
+		return FileSchemeDir, nil
+	case "https", "http", "ssh":
+		target = repoSpec.Host
+	default:
+		// We must have relative ssh url; in other words, the url has scp-like syntax.
+		// We attach a scheme to avoid url.Parse errors.
+		target = "ssh://" + repoSpec.Host
+	}
+	// url.Parse will not recognize ':' delimiter that both RepoSpec and git accept.
+	target = strings.TrimSuffix(target, ":")
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	// strip scheme, userinfo, port, and any trailing slashes.
+	return u.Hostname(), nil
+}
+
+// getVendorDir returns the vendor directory of the loader at the root
+// of the chain of referrers, or the empty string if there's none.
+func (fl *FileLoader) getVendorDir() filesys.ConfirmedDir {
+	for l := fl; l != nil; l = l.referrer {
+		if l.vendorDir != "" {
+			return l.vendorDir
+		}
+	}
+	return ""
+}
+
+// loadVendored returns the content of the copy of the remote file
+// fileURL in vendorDir.
+func loadVendored(fSys filesys.FileSystem, vendorDir filesys.ConfirmedDir, fileURL string) ([]byte, error) {
+	path, err := VendoredFilePath(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	path, err = joinVendorDir(vendorDir, path)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "remote reference %q", fileURL)
+	}
+	if !fSys.Exists(path) || fSys.IsDir(path) {
+		return nil, notVendoredError(fileURL, vendorDir, path)
+	}
+	return fSys.ReadFile(path)
+}
+
+// newLoaderAtVendoredRepo returns a new Loader pinned to the copy of
+// the repo of repoSpec in vendorDir.
+func newLoaderAtVendoredRepo(
+	repoSpec *git.RepoSpec, fSys filesys.FileSystem, vendorDir filesys.ConfirmedDir,
+	referrer *FileLoader, cloner git.Cloner) (*FileLoader, error) {
+	if repoSpec.Ref == "" {
+		return nil, fmt.Errorf(
+			"remote reference %q missing ref query string parameter, which is required to vendor it",
+			repoSpec.Raw())
+	}
+	path, err := VendoredRepoPath(repoSpec)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "unable to vendor remote reference %q", repoSpec.Raw())
+	}
+	path, err = joinVendorDir(vendorDir, path)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "remote reference %q", repoSpec.Raw())
+	}
+	dir, err := filesys.ConfirmDir(fSys, path)
+	if err != nil {
+		return nil, notVendoredError(repoSpec.Raw(), vendorDir, path)
+	}
+	// the copy mustn't be a symlink out of the vendor directory either
+	if !dir.HasPrefix(vendorDir) {
+		return nil, fmt.Errorf("remote reference %q: copy %q is outside of vendor directory %q",
+			repoSpec.Raw(), dir, vendorDir)
+	}
+	repoSpec.Dir = dir
+	if !fSys.Exists(repoSpec.AbsPath()) {
+		return nil, notVendoredError(repoSpec.Raw(), vendorDir, repoSpec.AbsPath())
+	}
+	// the vendor directory is never cleaned up
+	return newLoaderAtRepo(repoSpec, fSys, referrer, cloner, func() error { return nil })
+}
+
+// joinVendorDir returns the path of rel, a path returned by
+// VendoredFilePath or VendoredRepoPath, in vendorDir, or an error if it
+// isn't in vendorDir, e.g. because the host of a url is "..".
+func joinVendorDir(vendorDir filesys.ConfirmedDir, rel string) (string, error) {
+	path := vendorDir.Join(rel)
+	if path == vendorDir.String() || !filesys.ConfirmedDir(path).HasPrefix(vendorDir) {
+		return "", fmt.Errorf("path %q is outside of vendor directory %q", path, vendorDir)
+	}
+	return path, nil
+}
+
+func notVendoredError(ref string, vendorDir filesys.ConfirmedDir, path string) error {
+	return fmt.Errorf("%w: remote reference %q expected in %q at %q", ErrNotVendored, ref, vendorDir, path)
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package loader
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestVendoredRepoPath(t *testing.T) {
+	for url, expected := range map[string]string{
+		"https://github.com/org/repo//path?ref=v1.0.0":       "github.com/org/repo/v1.0.0",
+		"https://github.com/org/repo.git?ref=release/1.2.3":  "github.com/org/repo/release/1.2.3",
+		"git@github.com:org/repo.git?ref=7c5a9f2":            "github.com/org/repo/7c5a9f2",
+		"file:///tmp/repo?ref=main":                          "file-schemed/tmp/repo/main",
+		"https://github.com/org/repo?ref=release/v1..v2.x.y": "github.com/org/repo/release/v1..v2.x.y",
+	} {
+		repoSpec, err := git.NewRepoSpecFromURL(url)
+		require.NoError(t, err)
+		path, err := VendoredRepoPath(repoSpec)
+		require.NoError(t, err, url)
+		assert.Equal(t, filepath.FromSlash(expected), path, url)
+	}
+
+	for url, expectedErr := range map[string]string{
+		"https://github.com/org/repo?ref=../../secret":    `invalid ref "../../secret": must not have empty, . or .. segments`,
+		"https://github.com/org/repo?ref=v1/./x":          `invalid ref "v1/./x": must not have empty, . or .. segments`,
+		"https://github.com/org/repo?ref=release//v1":     `invalid ref "release//v1": must not have empty, . or .. segments`,
+		"https://github.com/org/repo?ref=/etc":            `invalid ref "/etc": must not be absolute`,
+		"https://github.com/org/repo?ref=v1/../../../etc": `invalid ref "v1/../../../etc": must not have empty, . or .. segments`,
+	} {
+		repoSpec, err := git.NewRepoSpecFromURL(url)
+		require.NoError(t, err)
+		_, err = VendoredRepoPath(repoSpec)
+		require.EqualError(t, err, expectedErr, url)
+	}
+}
+
+func TestJoinVendorDir(t *testing.T) {
+	vendorDir := filesys.ConfirmedDir(filepath.FromSlash("/app/vendor"))
+	path, err := joinVendorDir(vendorDir, filepath.FromSlash("github.com/org/repo/v1"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/app/vendor/github.com/org/repo/v1"), path)
+
+	for _, rel := range []string{"..", "../vendored", "github.com/../..", "."} {
+		_, err = joinVendorDir(vendorDir, filepath.FromSlash(rel))
+		require.Error(t, err, rel)
+	}
+}
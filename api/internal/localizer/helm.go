@@ -0,0 +1,94 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package localizer
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// pullChart pulls the remote chart into locChartHome, the localized chartHome,
+// unless the chart is already in chartHome. A pulled chart no longer needs
+// its repo, which pullChart removes so that the localized chart is used offline.
+func (lc *localizer) pullChart(chart *types.HelmChart, chartHome, locChartHome string) error {
+	if chart.Repo == "" || chart.Name == "" {
+		return nil
+	}
+	if lc.fSys.Exists(filepath.Join(lc.root.Join(chartHome), chart.Name)) {
+		return nil
+	}
+	dst := filepath.Join(lc.dst, locChartHome, chart.Name)
+	if lc.fSys.Exists(dst) {
+		// pulled by another entry
+		chart.Repo = ""
+		return nil
+	}
+	if lc.helmCommand == "" {
+		log.Printf("chart %q of repo %q is not localized, as helm is not enabled", chart.Name, chart.Repo)
+		return nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "kustomize-localize-helm-")
+	if err != nil {
+		return errors.WrapPrefixf(err, "unable to create directory to pull chart")
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	args := []string{"pull", "--untar", "--untardir", tmpDir, "--repo", chart.Repo, chart.Name}
+	if chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+	cmd := exec.Command(lc.helmCommand, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("HELM_CONFIG_HOME=%s", filepath.Join(tmpDir, ".config")),
+		fmt.Sprintf("HELM_CACHE_HOME=%s", filepath.Join(tmpDir, ".cache")),
+		fmt.Sprintf("HELM_DATA_HOME=%s", filepath.Join(tmpDir, ".data")))
+	if err = cmd.Run(); err != nil {
+		return errors.WrapPrefixf(err, "unable to run '%s %s': %s",
+			lc.helmCommand, strings.Join(args, " "), stderr.String())
+	}
+	if err = lc.copyOSDir(filepath.Join(tmpDir, chart.Name), dst); err != nil {
+		return errors.WrapPrefixf(err, "unable to copy pulled chart %q", chart.Name)
+	}
+	lc.lock.record(LockedSource{URL: chart.Repo, Chart: chart.Name, Version: chart.Version}, dst)
+	lc.lock.recordChartHome(filepath.Dir(dst))
+	chart.Repo = ""
+	return nil
+}
+
+// copyOSDir copies the directory src of the real file system to dst on
+// the file system of lc.
+func (lc *localizer) copyOSDir(src, dst string) error {
+	return errors.Wrap(filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			log.Panicf("no path from %q to child file %q: %s", src, path, err)
+		}
+		pathInDst := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			return lc.fSys.MkdirAll(pathInDst)
+		case !d.Type().IsRegular():
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return lc.fSys.WriteFile(pathInDst, content)
+	}))
+}
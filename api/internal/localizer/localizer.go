@@ -11,6 +11,7 @@ import (
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/generators"
+	"sigs.k8s.io/kustomize/api/internal/git"
 	"sigs.k8s.io/kustomize/api/internal/loader"
 	"sigs.k8s.io/kustomize/api/internal/target"
 	"sigs.k8s.io/kustomize/api/provider"
@@ -35,11 +36,30 @@ type localizer struct {
 
 	// destination directory in newDir that mirrors root
 	dst string
+
+	// helm command used to pull remote charts, if non-empty
+	helmCommand string
+
+	// records the remote sources localized, shared by all roots
+	lock *lockRecorder
+}
+
+// Options are the optional settings of RunWithOptions.
+type Options struct {
+	// HelmCommand is the helm executable used to pull the remote charts of
+	// helmCharts into the localized chart home. Remote charts are left
+	// un-localized if empty.
+	HelmCommand string
 }
 
 // Run attempts to localize the kustomization root at target with the given localize arguments
 // and returns the path to the created newDir.
 func Run(target, scope, newDir string, fSys filesys.FileSystem) (string, error) {
+	return RunWithOptions(target, scope, newDir, fSys, Options{})
+}
+
+// RunWithOptions is Run with opts.
+func RunWithOptions(target, scope, newDir string, fSys filesys.FileSystem, opts Options) (string, error) {
 	ldr, args, err := NewLoader(target, scope, newDir, fSys)
 	if err != nil {
 		return "", errors.Wrap(err)
@@ -55,13 +75,26 @@ func Run(target, scope, newDir string, fSys filesys.FileSystem) (string, error)
 		return "", errors.WrapPrefixf(err, "unable to create directory in localize destination")
 	}
 
+	lock := newLockRecorder(args.NewDir.String())
+	if repo := ldr.Repo(); repo != "" {
+		repoSpec, err := git.NewRepoSpecFromURL(target)
+		if err != nil {
+			log.Panicf("unable to parse validated remote target %q: %s", target, err)
+		}
+		lock.record(LockedSource{URL: target, Ref: repoSpec.Ref, Commit: resolveCommit(repo)}, args.NewDir.String())
+	}
 	err = (&localizer{
-		fSys:     fSys,
-		ldr:      ldr,
-		root:     args.Target,
-		rFactory: resmap.NewFactory(provider.NewDepProvider().GetResourceFactory()),
-		dst:      dst,
+		fSys:        fSys,
+		ldr:         ldr,
+		root:        args.Target,
+		rFactory:    resmap.NewFactory(provider.NewDepProvider().GetResourceFactory()),
+		dst:         dst,
+		helmCommand: opts.HelmCommand,
+		lock:        lock,
 	}).localize()
+	if err == nil {
+		err = lock.write(fSys)
+	}
 	if err != nil {
 		errCleanup := fSys.RemoveAll(args.NewDir.String())
 		if errCleanup != nil {
@@ -143,6 +176,10 @@ func (lc *localizer) localizeNativeFields(kust *types.Kustomization) error {
 			kust.Components,
 			lc.localizeRoot,
 		},
+		"catalogs": {
+			kust.Catalogs,
+			lc.localizeFile,
+		},
 		"configurations": {
 			kust.Configurations,
 			lc.localizeFile,
@@ -151,6 +188,10 @@ func (lc *localizer) localizeNativeFields(kust *types.Kustomization) error {
 			kust.Crds,
 			lc.localizeFile,
 		},
+		"openapiMerge": {
+			kust.OpenAPIMerge,
+			lc.localizeFile,
+		},
 		"resources": {
 			kust.Resources,
 			lc.localizeResource,
@@ -272,7 +313,8 @@ func (lc *localizer) localizeHelmInflationGenerator(kust *types.Kustomization) e
 }
 
 // localizeHelmCharts localizes helmCharts and helmGlobals on kust.
-// localizeHelmCharts localizes values files and copies a local chart home.
+// localizeHelmCharts localizes values files, copies a local chart home and
+// pulls remote charts into it.
 func (lc *localizer) localizeHelmCharts(kust *types.Kustomization) error {
 	for i, chart := range kust.HelmCharts {
 		locFile, err := lc.localizeFile(chart.ValuesFile)
@@ -289,11 +331,15 @@ func (lc *localizer) localizeHelmCharts(kust *types.Kustomization) error {
 			kust.HelmCharts[i].AdditionalValuesFiles[j] = locFile
 		}
 	}
+	chartHome, locChartHome := types.HelmDefaultHome, types.HelmDefaultHome
 	if kust.HelmGlobals != nil {
 		locDir, err := lc.copyChartHomeEntry(kust.HelmGlobals.ChartHome)
 		if err != nil {
 			return errors.WrapPrefixf(err, "unable to copy helmGlobals")
 		}
+		if kust.HelmGlobals.ChartHome != "" {
+			chartHome, locChartHome = kust.HelmGlobals.ChartHome, locDir
+		}
 		kust.HelmGlobals.ChartHome = locDir
 	} else if len(kust.HelmCharts) > 0 {
 		_, err := lc.copyChartHomeEntry("")
@@ -301,6 +347,11 @@ func (lc *localizer) localizeHelmCharts(kust *types.Kustomization) error {
 			return errors.WrapPrefixf(err, "unable to copy default chart home")
 		}
 	}
+	for i := range kust.HelmCharts {
+		if err := lc.pullChart(&kust.HelmCharts[i], chartHome, locChartHome); err != nil {
+			return errors.WrapPrefixf(err, "unable to pull helmCharts entry %d", i)
+		}
+	}
 	return nil
 }
 
@@ -390,6 +441,9 @@ func (lc *localizer) localizeFileWithContent(path string, content []byte) (strin
 	if err := lc.fSys.WriteFile(absPath, content); err != nil {
 		return "", errors.WrapPrefixf(err, "unable to localize file %q", path)
 	}
+	if loader.IsRemoteFile(path) {
+		lc.lock.record(LockedSource{URL: path}, absPath)
+	}
 	return locPath, nil
 }
 
@@ -417,6 +471,12 @@ func (lc *localizer) localizeRoot(path string) (string, error) {
 		if err != nil {
 			return "", err
 		}
+		repoSpec, err := git.NewRepoSpecFromURL(path)
+		if err != nil {
+			log.Panicf("unable to parse validated remote root %q: %s", path, err)
+		}
+		lc.lock.record(LockedSource{URL: path, Ref: repoSpec.Ref, Commit: resolveCommit(repo)},
+			filepath.Join(lc.dst, locPath))
 	} else {
 		locPath, err = filepath.Rel(lc.root.String(), root.String())
 		if err != nil {
@@ -428,11 +488,13 @@ func (lc *localizer) localizeRoot(path string) (string, error) {
 		return "", errors.WrapPrefixf(err, "unable to create root %q in localize destination", path)
 	}
 	err = (&localizer{
-		fSys:     lc.fSys,
-		ldr:      ldr,
-		root:     root,
-		rFactory: lc.rFactory,
-		dst:      newDst,
+		fSys:        lc.fSys,
+		ldr:         ldr,
+		root:        root,
+		rFactory:    lc.rFactory,
+		dst:         newDst,
+		helmCommand: lc.helmCommand,
+		lock:        lc.lock,
 	}).localize()
 	if err != nil {
 		return "", errors.WrapPrefixf(err, "unable to localize root %q", path)
@@ -6,7 +6,9 @@ package localizer_test
 import (
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -108,6 +110,13 @@ func checkRun(t *testing.T, fSys filesys.FileSystem, target, scope, dst string)
 	actualDst, err := Run(target, scope, dst, fSys)
 	require.NoError(t, err)
 	require.Equal(t, dst, actualDst)
+
+	// the lockfile of local content records no sources; remove it to
+	// compare the file systems
+	lock, err := fSys.ReadFile(filepath.Join(dst, LockFileName))
+	require.NoError(t, err)
+	require.Equal(t, "sources: []\n", string(lock))
+	require.NoError(t, fSys.RemoveAll(filepath.Join(dst, LockFileName)))
 }
 
 func makeFileSystems(t *testing.T, target string, files map[string]string) (expected filesys.FileSystem, actual filesys.FileSystem) {
@@ -424,6 +433,21 @@ kind: Kustomization
 	checkLocalizeInTargetSuccess(t, kustAndConfigs)
 }
 
+func TestLocalizeCatalogs(t *testing.T) {
+	kustAndCatalogs := map[string]string{
+		"kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+catalogs:
+- functions/catalog.yaml
+kind: Kustomization
+`,
+		"functions/catalog.yaml": `apiVersion: config.kubernetes.io/v1alpha1
+kind: Catalog
+metadata:
+  name: example-co-functions`,
+	}
+	checkLocalizeInTargetSuccess(t, kustAndCatalogs)
+}
+
 func TestLocalizeCrds(t *testing.T) {
 	kustAndCrds := map[string]string{
 		"kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
@@ -1295,6 +1319,15 @@ helmGlobals:
 				"charts/default/values.yaml": valuesFile,
 			},
 		},
+		{
+			name: "remote_without_helm",
+			files: map[string]string{
+				"kustomization.yaml": `helmCharts:
+- name: remote
+  repo: https://helm.releases.hashicorp.com
+`,
+			},
+		},
 		{
 			name: "home_only",
 			files: map[string]string{
@@ -1602,3 +1635,236 @@ func TestLocalizeEmpty(t *testing.T) {
 		})
 	}
 }
+
+// fakeHelm writes a helm executable that "pulls" a chart by writing its
+// Chart.yaml, and returns its path.
+func fakeHelm(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake helm is a shell script")
+	}
+	helm := filepath.Join(t.TempDir(), "helm")
+	require.NoError(t, os.WriteFile(helm, []byte(`#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    pull|--untar) ;;
+    --untardir) dir="$2"; shift ;;
+    --repo) repo="$2"; shift ;;
+    --version) version="$2"; shift ;;
+    *) name="$1" ;;
+  esac
+  shift
+done
+if [ "$name" = "missing" ]; then
+  echo "chart not found" >&2
+  exit 1
+fi
+mkdir -p "$dir/$name"
+printf 'name: %s\nversion: %s\n' "$name" "$version" > "$dir/$name/Chart.yaml"
+`), 0700))
+	return helm
+}
+
+func TestLocalizeHelmChartsPull(t *testing.T) {
+	for name, test := range map[string]struct {
+		files       map[string]string
+		copiedFiles map[string]string
+	}{
+		"default_home": {
+			files: map[string]string{
+				"kustomization.yaml": `helmCharts:
+- name: minecraft
+  repo: https://itzg.github.io/minecraft-server-charts
+  version: 3.1.3
+- name: minecraft
+  releaseName: other
+  repo: https://itzg.github.io/minecraft-server-charts
+  version: 3.1.3
+`,
+			},
+			copiedFiles: map[string]string{
+				"kustomization.yaml": `helmCharts:
+- name: minecraft
+  version: 3.1.3
+- name: minecraft
+  releaseName: other
+  version: 3.1.3
+`,
+				"charts/minecraft/Chart.yaml": `name: minecraft
+version: 3.1.3
+`,
+			},
+		},
+		"chart_home": {
+			files: map[string]string{
+				"kustomization.yaml": `helmCharts:
+- name: minecraft
+  repo: https://itzg.github.io/minecraft-server-charts
+  version: 3.1.2
+- name: local
+  repo: https://itzg.github.io/minecraft-server-charts
+helmGlobals:
+  chartHome: home
+`,
+				"home/local/values.yaml": valuesFile,
+			},
+			copiedFiles: map[string]string{
+				"kustomization.yaml": `helmCharts:
+- name: minecraft
+  version: 3.1.2
+- name: local
+  repo: https://itzg.github.io/minecraft-server-charts
+helmGlobals:
+  chartHome: home
+`,
+				"home/local/values.yaml": valuesFile,
+				"home/minecraft/Chart.yaml": `name: minecraft
+version: 3.1.2
+`,
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			expected, actual := makeFileSystems(t, "/a", test.files)
+
+			dst, err := RunWithOptions("/a", "", "/dst", actual, Options{HelmCommand: fakeHelm(t)})
+			require.NoError(t, err)
+			require.Equal(t, "/dst", dst)
+			require.NoError(t, Verify("/dst", actual))
+
+			lock, err := actual.ReadFile(filepath.Join("/dst", LockFileName))
+			require.NoError(t, err)
+			require.Contains(t, string(lock), `- chart: minecraft
+  digest: sha256:`)
+			require.NoError(t, actual.RemoveAll(filepath.Join("/dst", LockFileName)))
+
+			addFiles(t, expected, "/dst", test.copiedFiles)
+			checkFSys(t, expected, actual)
+		})
+	}
+}
+
+func TestLocalizeHelmChartsPullError(t *testing.T) {
+	kustAndValues := map[string]string{
+		"kustomization.yaml": `helmCharts:
+- name: missing
+  repo: https://itzg.github.io/minecraft-server-charts
+`,
+	}
+	expected, actual := makeFileSystems(t, "/a", kustAndValues)
+
+	_, err := RunWithOptions("/a", "", "/dst", actual, Options{HelmCommand: fakeHelm(t)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unable to pull helmCharts entry 0")
+	require.Contains(t, err.Error(), "chart not found")
+	checkFSys(t, expected, actual)
+}
+
+func TestLockFile(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	addFiles(t, fSys, "/a", map[string]string{
+		"kustomization.yaml": `helmCharts:
+- name: minecraft
+  repo: https://itzg.github.io/minecraft-server-charts
+  version: 3.1.3
+`,
+	})
+	_, err := RunWithOptions("/a", "", "/dst", fSys, Options{HelmCommand: fakeHelm(t)})
+	require.NoError(t, err)
+
+	lock, err := fSys.ReadFile(filepath.Join("/dst", LockFileName))
+	require.NoError(t, err)
+	require.Equal(t, `chartHomes:
+- digest: sha256:ec289596f0c3f312349eada2f27ea4c526c9dab6153dd9bee8c328b194482831
+  path: charts
+sources:
+- chart: minecraft
+  digest: sha256:544e920514ea53ca34ecee021b618f6d1565100e1f559632b6d216bdeffd14a9
+  path: charts/minecraft
+  url: https://itzg.github.io/minecraft-server-charts
+  version: 3.1.3
+`, string(lock))
+	require.NoError(t, Verify("/dst", fSys))
+
+	require.NoError(t, fSys.WriteFile("/dst/charts/minecraft/values.yaml", []byte(valuesFile)))
+	err = Verify("/dst", fSys)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `localized directory /dst does not verify:
+  charts/minecraft (https://itzg.github.io/minecraft-server-charts): expected digest sha256:`)
+
+	require.NoError(t, fSys.RemoveAll("/dst/charts"))
+	err = Verify("/dst", fSys)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "charts/minecraft (https://itzg.github.io/minecraft-server-charts): ")
+}
+
+func TestLockFileWithoutRemoteContent(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	addFiles(t, fSys, "/a", map[string]string{
+		"kustomization.yaml": "resources:\n- pod.yaml\n",
+		"pod.yaml":           podConfiguration,
+	})
+	_, err := Run("/a", "/a", "/dst", fSys)
+	require.NoError(t, err)
+	lock, err := fSys.ReadFile(filepath.Join("/dst", LockFileName))
+	require.NoError(t, err)
+	require.Equal(t, "sources: []\n", string(lock))
+	require.NoError(t, Verify("/dst", fSys))
+
+	addFiles(t, fSys, "/dst", map[string]string{
+		"base/kustomization.yaml": "helmCharts:\n- name: minecraft\n  repo: https://example.com/charts\n",
+	})
+	require.EqualError(t, Verify("/dst", fSys), `localized directory /dst does not verify:
+  base/kustomization.yaml: remote reference helmCharts "https://example.com/charts minecraft"`)
+
+	require.NoError(t, fSys.RemoveAll(filepath.Join("/dst", LockFileName)))
+	require.EqualError(t, Verify("/dst", fSys),
+		"localized directory /dst has no localize-lock.yaml: it must be created by kustomize localize")
+}
+
+func TestVerify(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	addFiles(t, fSys, "/a", map[string]string{
+		"kustomization.yaml": `resources:
+- pod.yaml
+helmCharts:
+- name: minecraft
+  repo: https://itzg.github.io/minecraft-server-charts
+  version: 3.1.3
+`,
+		"pod.yaml": podConfiguration,
+	})
+	_, err := RunWithOptions("/a", "", "/dst", fSys, Options{HelmCommand: fakeHelm(t)})
+	require.NoError(t, err)
+	require.NoError(t, Verify("/dst", fSys))
+
+	addFiles(t, fSys, "/dst", map[string]string{
+		"kustomization.yaml": `resources:
+- pod.yaml
+- https://github.com/kubernetes-sigs/kustomize//examples/multibases?ref=v1.0.6
+patches:
+- path: https://example.com/patch.yaml
+helmCharts:
+- name: minecraft
+  version: 3.1.3
+`,
+		"localized-files/example.com/remote.yaml": podConfiguration,
+	})
+	err = Verify("/dst", fSys)
+	require.EqualError(t, err, `localized directory /dst does not verify:
+  localized-files/example.com/remote.yaml: not recorded in localize-lock.yaml
+  kustomization.yaml: remote reference resources "https://github.com/kubernetes-sigs/kustomize//examples/multibases?ref=v1.0.6"
+  kustomization.yaml: remote reference patches "https://example.com/patch.yaml"`)
+
+	// files added to the chart home next to the pulled charts
+	require.NoError(t, fSys.WriteFile("/dst/charts/other/Chart.yaml", []byte("name: other\n")))
+	err = Verify("/dst", fSys)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "\n  charts (chart home): expected digest sha256:")
+	require.NotContains(t, err.Error(), "charts/minecraft")
+
+	require.NoError(t, fSys.RemoveAll("/dst/charts"))
+	err = Verify("/dst", fSys)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "\n  charts/minecraft (https://itzg.github.io/minecraft-server-charts): ")
+}
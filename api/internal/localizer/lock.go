@@ -0,0 +1,419 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package localizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/loader"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// LockFileName is the name of the lockfile that localize writes to the
+// localize destination.
+const LockFileName = "localize-lock.yaml"
+
+// Lock is the content of the lockfile.
+type Lock struct {
+	// Sources are the remote sources localized, sorted by Path.
+	Sources []LockedSource `json:"sources" yaml:"sources"`
+
+	// ChartHomes are the chart homes that remote charts were pulled into,
+	// sorted by Path.  Their digests cover the files next to the pulled
+	// charts too, e.g. the charts copied from the localize target.
+	ChartHomes []LockedChartHome `json:"chartHomes,omitempty" yaml:"chartHomes,omitempty"`
+}
+
+// LockedSource is a remote source localized to the localize destination.
+type LockedSource struct {
+	// URL is the remote root, file or helm chart repo.
+	URL string `json:"url" yaml:"url"`
+
+	// Ref is the ref of a remote root.
+	Ref string `json:"ref,omitempty" yaml:"ref,omitempty"`
+
+	// Commit is the commit that Ref of a remote root resolved to.
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+
+	// Chart and Version are the name and version of a helm chart.
+	Chart   string `json:"chart,omitempty" yaml:"chart,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+
+	// Path is the location of the localized content, relative to the
+	// localize destination.
+	Path string `json:"path" yaml:"path"`
+
+	// Digest is the digest of the localized content at Path.
+	Digest string `json:"digest" yaml:"digest"`
+}
+
+// LockedChartHome is a chart home of the localize destination.
+type LockedChartHome struct {
+	// Path is the location of the chart home, relative to the localize
+	// destination.
+	Path string `json:"path" yaml:"path"`
+
+	// Digest is the digest of the content of the chart home.
+	Digest string `json:"digest" yaml:"digest"`
+}
+
+// lockRecorder collects the remote sources localized to newDir.
+type lockRecorder struct {
+	newDir     string
+	sources    map[string]LockedSource
+	chartHomes map[string]bool
+}
+
+func newLockRecorder(newDir string) *lockRecorder {
+	return &lockRecorder{newDir: newDir, sources: make(map[string]LockedSource), chartHomes: make(map[string]bool)}
+}
+
+// recordChartHome records the chart home at the absolute path dir, that a
+// remote chart was pulled into.
+func (r *lockRecorder) recordChartHome(dir string) {
+	path, err := filepath.Rel(r.newDir, dir)
+	if err != nil {
+		log.Panicf("no path from localize destination %q to %q: %s", r.newDir, dir, err)
+	}
+	r.chartHomes[filepath.ToSlash(path)] = true
+}
+
+// record records source, localized to the absolute path dst.
+func (r *lockRecorder) record(source LockedSource, dst string) {
+	path, err := filepath.Rel(r.newDir, dst)
+	if err != nil {
+		log.Panicf("no path from localize destination %q to %q: %s", r.newDir, dst, err)
+	}
+	source.Path = filepath.ToSlash(path)
+	if _, exists := r.sources[source.Path]; !exists {
+		r.sources[source.Path] = source
+	}
+}
+
+// write writes the lockfile of the recorded sources to newDir.  The
+// lockfile is written even if there are none, so that its absence fails
+// Verify.
+func (r *lockRecorder) write(fSys filesys.FileSystem) error {
+	lock := Lock{Sources: []LockedSource{}}
+	for _, source := range r.sources {
+		var err error
+		source.Digest, err = digest(fSys, r.newDir, source.Path)
+		if err != nil {
+			return errors.WrapPrefixf(err, "unable to compute digest of %q", source.Path)
+		}
+		lock.Sources = append(lock.Sources, source)
+	}
+	sort.Slice(lock.Sources, func(i, j int) bool {
+		return lock.Sources[i].Path < lock.Sources[j].Path
+	})
+	for path := range r.chartHomes {
+		d, err := digest(fSys, r.newDir, path)
+		if err != nil {
+			return errors.WrapPrefixf(err, "unable to compute digest of %q", path)
+		}
+		lock.ChartHomes = append(lock.ChartHomes, LockedChartHome{Path: path, Digest: d})
+	}
+	sort.Slice(lock.ChartHomes, func(i, j int) bool {
+		return lock.ChartHomes[i].Path < lock.ChartHomes[j].Path
+	})
+	content, err := yaml.Marshal(lock)
+	if err != nil {
+		return errors.WrapPrefixf(err, "unable to serialize lockfile")
+	}
+	return errors.WrapPrefixf(fSys.WriteFile(filepath.Join(r.newDir, LockFileName), content),
+		"unable to write lockfile")
+}
+
+// digest returns the sha256 digest of the file or directory at path,
+// relative to newDir. The digest of a directory covers the relative paths
+// and contents of its files, excluding the lockfile.
+func digest(fSys filesys.FileSystem, newDir, path string) (string, error) {
+	root := filepath.Join(newDir, filepath.FromSlash(path))
+	lockFile := filepath.Join(newDir, LockFileName)
+	h := sha256.New()
+	if !fSys.IsDir(root) {
+		content, err := fSys.ReadFile(root)
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		h.Write(content)
+		return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+	}
+	var files []string
+	err := fSys.Walk(root, func(file string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && file != lockFile {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		content, err := fSys.ReadFile(file)
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			log.Panicf("no path from %q to child file %q: %s", root, file, err)
+		}
+		fileDigest := sha256.Sum256(content)
+		fmt.Fprintf(h, "%s\x00%s\n", filepath.ToSlash(rel), hex.EncodeToString(fileDigest[:]))
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// resolveCommit returns the commit checked out in the clone at repoDir,
+// or the empty string if it cannot be determined.
+func resolveCommit(repoDir string) string {
+	out, err := exec.Command("git", "-C", repoDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// Verify checks the localize destination newDir: that it has a lockfile,
+// that the content localized to it, including the chart homes that remote
+// charts were pulled into, matches the digests recorded in the lockfile,
+// that all the content localized to LocalizeDir is recorded in the
+// lockfile, and that the kustomizations in newDir have no remote
+// references left.
+func Verify(newDir string, fSys filesys.FileSystem) error {
+	lock, err := readLock(newDir, fSys)
+	if err != nil {
+		return err
+	}
+	problems := checkDigests(newDir, fSys, lock)
+	unrecorded, err := findUnrecorded(newDir, fSys, lock)
+	if err != nil {
+		return err
+	}
+	problems = append(problems, unrecorded...)
+	remotes, err := findRemoteReferences(newDir, fSys)
+	if err != nil {
+		return err
+	}
+	problems = append(problems, remotes...)
+	if len(problems) > 0 {
+		return errors.Errorf("localized directory %s does not verify:\n  %s",
+			newDir, strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// readLock reads the lockfile of newDir, which must exist.
+func readLock(newDir string, fSys filesys.FileSystem) (Lock, error) {
+	var lock Lock
+	path := filepath.Join(newDir, LockFileName)
+	if !fSys.Exists(path) {
+		return lock, errors.Errorf("localized directory %s has no %s: it must be created by kustomize localize",
+			newDir, LockFileName)
+	}
+	content, err := fSys.ReadFile(path)
+	if err != nil {
+		return lock, errors.WrapPrefixf(err, "unable to read lockfile")
+	}
+	if err = yaml.Unmarshal(content, &lock); err != nil {
+		return lock, errors.WrapPrefixf(err, "unable to parse lockfile")
+	}
+	return lock, nil
+}
+
+// checkDigests returns the sources and chart homes of lock whose content
+// in newDir doesn't match their digest.
+func checkDigests(newDir string, fSys filesys.FileSystem, lock Lock) []string {
+	var mismatches []string
+	check := func(path, description, expected string) {
+		actual, err := digest(fSys, newDir, path)
+		switch {
+		case err != nil:
+			mismatches = append(mismatches, fmt.Sprintf("%s (%s): %s", path, description, err))
+		case actual != expected:
+			mismatches = append(mismatches, fmt.Sprintf("%s (%s): expected digest %s, got %s",
+				path, description, expected, actual))
+		}
+	}
+	for _, source := range lock.Sources {
+		check(source.Path, source.URL, source.Digest)
+	}
+	for _, home := range lock.ChartHomes {
+		check(home.Path, "chart home", home.Digest)
+	}
+	return mismatches
+}
+
+// findUnrecorded returns the files of LocalizeDir in newDir which aren't
+// part of a source of lock.
+func findUnrecorded(newDir string, fSys filesys.FileSystem, lock Lock) ([]string, error) {
+	localizeDir := filepath.Join(newDir, LocalizeDir)
+	if !fSys.IsDir(localizeDir) {
+		return nil, nil
+	}
+	var unrecorded []string
+	err := fSys.Walk(localizeDir, func(file string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(newDir, file)
+		if err != nil {
+			log.Panicf("no path from localize destination %q to child file %q: %s", newDir, file, err)
+		}
+		rel = filepath.ToSlash(rel)
+		for _, source := range lock.Sources {
+			if rel == source.Path || strings.HasPrefix(rel, source.Path+"/") {
+				return nil
+			}
+		}
+		unrecorded = append(unrecorded, fmt.Sprintf("%s: not recorded in %s", rel, LockFileName))
+		return nil
+	})
+	return unrecorded, errors.Wrap(err)
+}
+
+// findRemoteReferences returns the remote references of the kustomizations
+// in newDir.
+func findRemoteReferences(newDir string, fSys filesys.FileSystem) ([]string, error) {
+	names := make(map[string]bool)
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		names[name] = true
+	}
+	var remotes []string
+	err := fSys.Walk(newDir, func(file string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !names[info.Name()] {
+			return err
+		}
+		content, err := fSys.ReadFile(file)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		var kust types.Kustomization
+		if err = kust.Unmarshal(content); err != nil {
+			return errors.WrapPrefixf(err, "unable to parse %s", file)
+		}
+		rel, err := filepath.Rel(newDir, file)
+		if err != nil {
+			log.Panicf("no path from localize destination %q to child file %q: %s", newDir, file, err)
+		}
+		chartExists := func(chartHome, name string) bool {
+			if chartHome == "" {
+				chartHome = types.HelmDefaultHome
+			}
+			return fSys.Exists(filepath.Join(filepath.Dir(file), chartHome, name))
+		}
+		for _, remote := range remoteReferences(&kust, chartExists) {
+			remotes = append(remotes, fmt.Sprintf("%s: remote reference %s", filepath.ToSlash(rel), remote))
+		}
+		return nil
+	})
+	return remotes, errors.Wrap(err)
+}
+
+// remoteReferences returns the remote references of kust, as the field
+// and the reference.  Helm charts in their chart home, according to
+// chartExists, aren't pulled from their repo and so aren't remote.
+func remoteReferences(kust *types.Kustomization, chartExists func(chartHome, name string) bool) []string {
+	var remotes []string
+	add := func(field string, paths ...string) {
+		for _, path := range paths {
+			if isRemote(path) {
+				remotes = append(remotes, fmt.Sprintf("%s %q", field, path))
+			}
+		}
+	}
+	add("openapi", kust.OpenAPI["path"])
+	add("openapiMerge", kust.OpenAPIMerge...)
+	//nolint:staticcheck
+	add("bases", kust.Bases...)
+	add("components", kust.Components...)
+	add("catalogs", kust.Catalogs...)
+	add("configurations", kust.Configurations...)
+	add("crds", kust.Crds...)
+	add("resources", kust.Resources...)
+	add("generators", kust.Generators...)
+	add("transformers", kust.Transformers...)
+	add("validators", kust.Validators...)
+	for _, generator := range kust.ConfigMapGenerator {
+		add("configMapGenerator", generatorPaths(generator.GeneratorArgs)...)
+	}
+	for _, generator := range kust.SecretGenerator {
+		add("secretGenerator", generatorPaths(generator.GeneratorArgs)...)
+	}
+	for _, patch := range kust.Patches {
+		add("patches", patch.Path)
+	}
+	//nolint:staticcheck
+	for _, patch := range kust.PatchesJson6902 {
+		add("patchesJson6902", patch.Path)
+	}
+	//nolint:staticcheck
+	for _, patch := range kust.PatchesStrategicMerge {
+		add("patchesStrategicMerge", string(patch))
+	}
+	for _, replacement := range kust.Replacements {
+		add("replacements", replacement.Path)
+	}
+	var chartHome string
+	if kust.HelmGlobals != nil {
+		chartHome = kust.HelmGlobals.ChartHome
+	}
+	for _, chart := range kust.HelmCharts {
+		if chart.Repo != "" && !chartExists(chartHome, chart.Name) {
+			remotes = append(remotes, fmt.Sprintf("helmCharts %q", chart.Repo+" "+chart.Name))
+		}
+	}
+	//nolint:staticcheck
+	for _, chart := range kust.HelmChartInflationGenerator {
+		if chart.ChartRepoURL != "" && !chartExists(chart.ChartHome, chart.ChartName) {
+			remotes = append(remotes, fmt.Sprintf("helmChartInflationGenerator %q",
+				chart.ChartRepoURL+" "+chart.ChartName))
+		}
+	}
+	return remotes
+}
+
+// generatorPaths returns the file paths of generator.
+func generatorPaths(generator types.GeneratorArgs) []string {
+	paths := append([]string{generator.EnvSource}, generator.EnvSources...)
+	for _, file := range generator.FileSources {
+		// file sources may be prefixed with a key
+		_, path, found := strings.Cut(file, "=")
+		if !found {
+			path = file
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// isRemote returns whether path is a remote file or root.  Inline
+// content, which spans several lines, isn't.
+func isRemote(path string) bool {
+	if path == "" || strings.Contains(path, "\n") {
+		return false
+	}
+	if loader.IsRemoteFile(path) {
+		return true
+	}
+	_, err := git.NewRepoSpecFromURL(path)
+	return err == nil
+}
@@ -5,12 +5,12 @@ package localizer
 
 import (
 	"log"
-	"net/url"
 	"path/filepath"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/loader"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
@@ -23,7 +23,7 @@ const (
 	LocalizeDir = "localized-files"
 
 	// FileSchemeDir is the name of the directory immediately inside LocalizeDir used to store file-schemed repos
-	FileSchemeDir = "file-schemed"
+	FileSchemeDir = loader.FileSchemeDir
 )
 
 // establishScope returns the effective scope given localize arguments and targetLdr at rawTarget. For remote rawTarget,
@@ -133,22 +133,11 @@ func cleanFilePath(fSys filesys.FileSystem, root filesys.ConfirmedDir, file stri
 // fileURL must be a validated file URL.
 func locFilePath(fileURL string) string {
 	// File urls must have http or https scheme, so it is safe to use url.Parse.
-	u, err := url.Parse(fileURL)
+	path, err := loader.VendoredFilePath(fileURL)
 	if err != nil {
 		log.Panicf("cannot parse validated file url %q: %s", fileURL, err)
 	}
-
-	// HTTP requests use the escaped path, so we use it here. Escaped paths also help us
-	// preserve percent-encoding in the original path, in the absence of illegal characters,
-	// in case they have special meaning to the host.
-	// Extraneous '..' parent directory dot-segments should be removed.
-	path := filepath.Join(string(filepath.Separator), filepath.FromSlash(u.EscapedPath()))
-
-	// We intentionally exclude userinfo and port.
-	// Raw github urls are the only type of file urls kustomize officially accepts.
-	// In this case, the path already consists of org, repo, version, and path in repo, in order,
-	// so we can use it as is.
-	return filepath.Join(LocalizeDir, u.Hostname(), path)
+	return filepath.Join(LocalizeDir, path)
 }
 
 // locRootPath returns the relative localized path of the validated root url rootURL, where the local copy of its repo
@@ -158,7 +147,7 @@ func locRootPath(rootURL, repoDir string, root filesys.ConfirmedDir, fSys filesy
 	if err != nil {
 		log.Panicf("cannot parse validated repo url %q: %s", rootURL, err)
 	}
-	host, err := parseHost(repoSpec)
+	repoPath, err := loader.VendoredRepoPath(repoSpec)
 	if err != nil {
 		return "", errors.WrapPrefixf(err, "unable to parse host of remote root %q", rootURL)
 	}
@@ -171,48 +160,6 @@ func locRootPath(rootURL, repoDir string, root filesys.ConfirmedDir, fSys filesy
 	if err != nil {
 		log.Panicf("cannot find path from %q to child directory %q: %s", repo, root, err)
 	}
-	// the git-server-side directory name conventionally (but not universally) ends in .git, which
-	// is conventionally stripped from the client-side directory name used for the clone.
-	localRepoPath := strings.TrimSuffix(repoSpec.RepoPath, ".git")
-
-	// We do not need to escape RepoPath, a path on the git server.
-	// However, like git, we clean dot-segments from RepoPath.
-	// Git does not allow ref value to contain dot-segments.
-	return filepath.Join(LocalizeDir,
-		host,
-		filepath.Join(string(filepath.Separator), filepath.FromSlash(localRepoPath)),
-		filepath.FromSlash(repoSpec.Ref),
-		inRepo), nil
+	return filepath.Join(LocalizeDir, repoPath, inRepo), nil
 }
 
-// parseHost returns the localize directory path corresponding to repoSpec.Host
-func parseHost(repoSpec *git.RepoSpec) (string, error) {
-	var target string
-	switch scheme, _, _ := strings.Cut(repoSpec.Host, "://"); scheme {
-	case "gh:":
-		// 'gh' was meant to be a local github.com shorthand, in which case
-		// the .gitconfig file could map it to any host. See origin here:
-		// https://github.com/kubernetes-sigs/kustomize/blob/kustomize/v4.5.7/api/internal/git/repospec.go#L203
-		// We give it a special host directory here under the assumption
-		// that we are unlikely to have another host simply named 'gh'.
-		return "gh", nil
-	case "file":
-		// We put file-scheme repos under a special directory to avoid
-		// colluding local absolute paths with hosts.
-		return FileSchemeDir, nil
-	case "https", "http", "ssh":
-		target = repoSpec.Host
-	default:
-		// We must have relative ssh url; in other words, the url has scp-like syntax.
-		// We attach a scheme to avoid url.Parse errors.
-		target = "ssh://" + repoSpec.Host
-	}
-	// url.Parse will not recognize ':' delimiter that both RepoSpec and git accept.
-	target = strings.TrimSuffix(target, ":")
-	u, err := url.Parse(target)
-	if err != nil {
-		return "", errors.Wrap(err)
-	}
-	// strip scheme, userinfo, port, and any trailing slashes.
-	return u.Hostname(), nil
-}
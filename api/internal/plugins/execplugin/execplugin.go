@@ -187,5 +187,13 @@ func (p *ExecPlugin) getEnv() []string {
 	env = append(env,
 		"KUSTOMIZE_PLUGIN_CONFIG_STRING="+string(p.cfg),
 		"KUSTOMIZE_PLUGIN_CONFIG_ROOT="+p.h.Loader().Root())
+	// the variables given to functions, e.g. by kustomize build --env-file
+	if c := p.h.GeneralConfig(); c != nil {
+		for _, e := range c.FnpLoadingOptions.Env {
+			if strings.Contains(e, "=") {
+				env = append(env, e)
+			}
+		}
+	}
 	return env
 }
@@ -0,0 +1,43 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fnplugin
+
+import (
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ResolveFromCatalogs sets the function annotation of res, if it has no
+// function spec, to the function of the first of catalogs listing the
+// group, version and kind of res.  It returns false if none does.
+func ResolveFromCatalogs(res *resource.Resource, catalogs []*types.Catalog) (bool, error) {
+	if len(catalogs) == 0 {
+		return false, nil
+	}
+	spec, err := GetFunctionSpec(res)
+	if err != nil || spec != nil {
+		return false, err
+	}
+	gvk := res.GetGvk()
+	for _, c := range catalogs {
+		_, v := c.Function(gvk.Group, gvk.Version, gvk.Kind)
+		if v == nil {
+			continue
+		}
+		fn := runtimeutil.FunctionSpec{Container: runtimeutil.ContainerSpec{
+			Image:   v.Runtime.Container.PinnedImage(),
+			Network: v.Runtime.Container.RequireNetwork,
+		}}
+		b, err := yaml.Marshal(fn)
+		if err != nil {
+			return false, err
+		}
+		annotations := res.GetAnnotations()
+		annotations[runtimeutil.FunctionAnnotationKey] = string(b)
+		return true, res.SetAnnotations(annotations)
+	}
+	return false, nil
+}
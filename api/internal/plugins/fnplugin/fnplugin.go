@@ -75,14 +75,28 @@ func toStorageMounts(mounts []string) []runtimeutil.StorageMount {
 func NewFnPlugin(o *types.FnPluginLoadingOptions) *FnPlugin {
 	return &FnPlugin{
 		runFns: runfn.RunFns{
-			Functions:      []*yaml.RNode{},
-			Network:        o.Network,
-			EnableStarlark: o.EnableStar,
-			EnableExec:     o.EnableExec,
-			StorageMounts:  toStorageMounts(o.Mounts),
-			Env:            o.Env,
-			AsCurrentUser:  o.AsCurrentUser,
-			WorkingDir:     o.WorkingDir,
+			Functions:          []*yaml.RNode{},
+			Network:            o.Network,
+			EnableStarlark:     o.EnableStar,
+			EnableExec:         o.EnableExec,
+			ExecAllowlist:      o.ExecAllowlist,
+			ExecServers:        o.ExecServers,
+			SandboxExec:        o.SandboxExec,
+			EnableWasm:         o.EnableWasm,
+			WasmRuntime:        o.WasmRuntime,
+			EnableGo:           o.EnableGo,
+			StorageMounts:      toStorageMounts(o.Mounts),
+			Env:                o.Env,
+			AsCurrentUser:      o.AsCurrentUser,
+			ContainerRuntime:   o.ContainerRuntime,
+			ContainerPool:      o.ContainerPool,
+			ContainerIsolation: o.ContainerIsolation,
+			OutputCache:        o.OutputCache,
+			ImagePullPolicy:    o.ImagePullPolicy,
+			Limits:             o.Limits,
+			ResultsFunc:        o.ResultsFunc,
+			LogFunc:            o.LogFunc,
+			WorkingDir:         o.WorkingDir,
 		},
 	}
 }
@@ -96,6 +110,8 @@ func (p *FnPlugin) Cfg() []byte {
 func (p *FnPlugin) Config(h *resmap.PluginHelpers, config []byte) error {
 	p.h = h
 	p.cfg = config
+	// starlark modules are subject to the load restrictions of the kustomization
+	p.runFns.StarlarkReadModule = h.Loader().Load
 
 	fn, err := bytesToRNode(p.cfg)
 	if err != nil {
@@ -0,0 +1,98 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fnplugin
+
+import (
+	"fmt"
+	"reflect"
+
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ExpandPipelines replaces the Pipelines of rm with the configurations of
+// their functions, in order, annotated with the function specs of the
+// Pipelines.  The configurations have the origin of their Pipeline.
+func ExpandPipelines(rm resmap.ResMap, rmF *resmap.Factory) error {
+	resources := rm.Resources()
+	expanded := false
+	for _, res := range resources {
+		expanded = expanded || isPipeline(res)
+	}
+	if !expanded {
+		return nil
+	}
+	rm.Clear()
+	for _, res := range resources {
+		if !isPipeline(res) {
+			if err := rm.Append(res); err != nil {
+				return err
+			}
+			continue
+		}
+		fns, err := pipelineFunctions(res, rmF)
+		if err != nil {
+			return fmt.Errorf("expanding pipeline %s: %w", res.GetName(), err)
+		}
+		for _, fn := range fns {
+			if err := rm.Append(fn); err != nil {
+				return fmt.Errorf("expanding pipeline %s: %w", res.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func isPipeline(res *resource.Resource) bool {
+	return res.GetApiVersion() == types.PipelineVersion && res.GetKind() == types.PipelineKind
+}
+
+// pipelineFunctions returns the configurations of the functions of the
+// Pipeline res.
+func pipelineFunctions(res *resource.Resource, rmF *resmap.Factory) ([]*resource.Resource, error) {
+	b, err := res.AsYAML()
+	if err != nil {
+		return nil, err
+	}
+	p, err := types.ParsePipeline(b)
+	if err != nil {
+		return nil, err
+	}
+	var fns []*resource.Resource
+	for i, f := range p.Spec.Functions {
+		b, err := yaml.Marshal(f.Config)
+		if err != nil {
+			return nil, err
+		}
+		m, err := rmF.NewResMapFromBytes(b)
+		if err != nil {
+			return nil, fmt.Errorf("config of function %d: %w", i, err)
+		}
+		if m.Size() != 1 {
+			return nil, fmt.Errorf("config of function %d isn't a single object", i)
+		}
+		fn := m.Resources()[0]
+		if !reflect.DeepEqual(f.FunctionSpec, runtimeutil.FunctionSpec{}) {
+			spec, err := yaml.Marshal(f.FunctionSpec)
+			if err != nil {
+				return nil, err
+			}
+			annotations := fn.GetAnnotations()
+			annotations[runtimeutil.FunctionAnnotationKey] = string(spec)
+			if err := fn.SetAnnotations(annotations); err != nil {
+				return nil, err
+			}
+		}
+		if origin, err := res.GetOrigin(); err == nil && origin != nil {
+			if err := fn.SetOrigin(origin); err != nil {
+				return nil, err
+			}
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/ifc"
+	"sigs.k8s.io/kustomize/api/internal/git"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
 	"sigs.k8s.io/kustomize/api/internal/plugins/execplugin"
 	"sigs.k8s.io/kustomize/api/internal/plugins/fnplugin"
@@ -23,6 +24,8 @@ import (
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/resid"
 )
 
@@ -32,6 +35,14 @@ type Loader struct {
 	rf *resmap.Factory
 	fs filesys.FileSystem
 
+	// extraBuiltins are plugins linked into the program using the api,
+	// loaded like the builtins, by the GVK of their config.
+	extraBuiltins map[resid.Gvk]func() resmap.Configurable
+
+	// lr restricts the bind mounts of functions like the files of the
+	// kustomizations.
+	lr types.LoadRestrictions
+
 	// absolutePluginHome caches the location of a valid plugin root directory.
 	// It should only be set once the directory's existence has been confirmed.
 	absolutePluginHome string
@@ -52,7 +63,22 @@ func (l *Loader) LoaderWithWorkingDir(wd string) *Loader {
 		HelmConfig:         l.pc.HelmConfig,
 	}
 	lpc.FnpLoadingOptions.WorkingDir = wd
-	return &Loader{pc: lpc, rf: l.rf, fs: l.fs}
+	return &Loader{pc: lpc, rf: l.rf, fs: l.fs, extraBuiltins: l.extraBuiltins, lr: l.lr}
+}
+
+// SetExtraBuiltins sets the factories of the plugins linked into the
+// program using the api, by the GVK of their config.  Like the builtins,
+// they're loaded regardless of the plugin restrictions.
+func (l *Loader) SetExtraBuiltins(extraBuiltins map[resid.Gvk]func() resmap.Configurable) {
+	l.extraBuiltins = extraBuiltins
+}
+
+// SetLoadRestrictions sets the restrictions of the bind mounts of the
+// functions: unless they're LoadRestrictionsNone, the mounts must be below
+// the kustomization root, or in its git repository with
+// LoadRestrictionsRootOfRepo, like the files of the kustomizations.
+func (l *Loader) SetLoadRestrictions(lr types.LoadRestrictions) {
+	l.lr = lr
 }
 
 // Config provides the global (not plugin specific) PluginConfig data.
@@ -184,10 +210,12 @@ func (l *Loader) loadAndConfigurePlugin(
 	ldr ifc.Loader,
 	v ifc.Validator,
 	res *resource.Resource) (c resmap.Configurable, err error) {
-	if isBuiltinPlugin(res) {
+	if f, ok := l.extraBuiltins[res.GetGvk()]; ok {
+		c = f()
+	} else if isBuiltinPlugin(res) {
 		switch l.pc.BpLoadingOptions {
 		case types.BploLoadFromFileSys:
-			c, err = l.loadPlugin(res)
+			c, err = l.loadPlugin(ldr, res)
 		case types.BploUseStaticallyLinked:
 			// Instead of looking for and loading a .so file,
 			// instantiate the plugin from a generated factory
@@ -202,7 +230,7 @@ func (l *Loader) loadAndConfigurePlugin(
 	} else {
 		switch l.pc.PluginRestrictions {
 		case types.PluginRestrictionsNone:
-			c, err = l.loadPlugin(res)
+			c, err = l.loadPlugin(ldr, res)
 		case types.PluginRestrictionsBuiltinsOnly:
 			err = types.NewErrOnlyBuiltinPluginsAllowed(res.OrgId().Kind)
 		default:
@@ -237,28 +265,66 @@ func (l *Loader) makeBuiltinPlugin(r resid.Gvk) (resmap.Configurable, error) {
 	return nil, errors.Errorf("unable to load builtin %s", r)
 }
 
-func (l *Loader) loadPlugin(res *resource.Resource) (resmap.Configurable, error) {
+func (l *Loader) loadPlugin(ldr ifc.Loader, res *resource.Resource) (resmap.Configurable, error) {
 	spec, err := fnplugin.GetFunctionSpec(res)
 	if err != nil {
 		return nil, fmt.Errorf("loader: %w", err)
 	}
 	if spec != nil {
-		// validation check that function mounts are under the current kustomization directory
-		for _, mount := range spec.Container.StorageMounts {
-			if filepath.IsAbs(mount.Src) {
-				return nil, errors.Errorf("plugin %s with mount path '%s' is not permitted; "+
-					"mount paths must be relative to the current kustomization directory", res.OrgId(), mount.Src)
-			}
-			if strings.HasPrefix(filepath.Clean(mount.Src), "../") {
-				return nil, errors.Errorf("plugin %s with mount path '%s' is not permitted; "+
-					"mount paths must be under the current kustomization directory", res.OrgId(), mount.Src)
-			}
+		if err := l.validateMounts(ldr, res, spec.Container.StorageMounts); err != nil {
+			return nil, err
 		}
 		return fnplugin.NewFnPlugin(&l.pc.FnpLoadingOptions), nil
 	}
 	return l.loadExecOrGoPlugin(res.OrgId())
 }
 
+// validateMounts validates the mounts of the function of res.  Its bind
+// mounts are relative to the kustomization root and, unless the load
+// restrictions are lifted, must stay below it, or in its git repository
+// with LoadRestrictionsRootOfRepo, following symlinks.
+func (l *Loader) validateMounts(
+	ldr ifc.Loader, res *resource.Resource, mounts []runtimeutil.StorageMount) error {
+	for _, mount := range mounts {
+		if err := mount.Validate(); err != nil {
+			return errors.WrapPrefixf(err, "plugin %s", res.OrgId())
+		}
+		if mount.MountType != runtimeutil.MountTypeBind {
+			continue
+		}
+		if filepath.IsAbs(mount.Src) {
+			return errors.Errorf("plugin %s with mount path '%s' is not permitted; "+
+				"mount paths must be relative to the current kustomization directory", res.OrgId(), mount.Src)
+		}
+		if l.lr == types.LoadRestrictionsNone {
+			continue
+		}
+		root, err := filesys.ConfirmDir(l.fs, ldr.Root())
+		if err != nil {
+			return errors.WrapPrefixf(err, "plugin %s", res.OrgId())
+		}
+		errOutside := errors.Errorf("plugin %s with mount path '%s' is not permitted; "+
+			"mount paths must be under the current kustomization directory", res.OrgId(), mount.Src)
+		bound := root
+		if repoRoot, found := git.FindRepoRoot(l.fs, root); found && l.lr == types.LoadRestrictionsRootOfRepo {
+			bound = repoRoot
+			errOutside = errors.Errorf("plugin %s with mount path '%s' is not permitted; "+
+				"mount paths must be in the git repository of the current kustomization directory", res.OrgId(), mount.Src)
+		} else if src := filepath.Clean(mount.Src); src == ".." || strings.HasPrefix(src, "../") {
+			return errOutside
+		}
+		dir, _, err := l.fs.CleanedAbs(root.Join(mount.Src))
+		if err != nil {
+			// a missing source fails when the function runs
+			continue
+		}
+		if !dir.HasPrefix(bound) {
+			return errOutside
+		}
+	}
+	return nil
+}
+
 func (l *Loader) loadExecOrGoPlugin(resId resid.ResId) (resmap.Configurable, error) {
 	absPluginPath, err := l.AbsolutePluginPath(resId)
 	if err != nil {
@@ -267,6 +333,9 @@ func (l *Loader) loadExecOrGoPlugin(resId resid.ResId) (resmap.Configurable, err
 	// First try to load the plugin as an executable.
 	p := execplugin.NewExecPlugin(absPluginPath)
 	if err = p.ErrIfNotExecutable(); err == nil {
+		if err := exec.CheckAllowed(absPluginPath, l.pc.FnpLoadingOptions.ExecAllowlist); err != nil {
+			return nil, errors.WrapPrefixf(err, "loading exec plugin %s", resId)
+		}
 		return p, nil
 	}
 	if !os.IsNotExist(err) {
@@ -4,8 +4,11 @@
 package loader_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/api/internal/loader"
 	. "sigs.k8s.io/kustomize/api/internal/plugins/loader"
@@ -96,3 +99,67 @@ func TestLoaderWithWorkingDir(t *testing.T) {
 		npLdr.Config().FnpLoadingOptions.WorkingDir,
 		"the plugin working dir is not updated")
 }
+
+func TestLoaderMounts(t *testing.T) {
+	fSys := filesys.MakeFsOnDisk()
+	base := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, fSys.MkdirAll(filepath.Join(base, "app", "charts")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(base, "app", "linked")))
+	fLdr, err := loader.NewLoader(loader.RestrictionRootOnly, filepath.Join(base, "app"), fSys)
+	require.NoError(t, err)
+	rmF := resmap.NewFactory(provider.NewDefaultDepProvider().GetResourceFactory())
+
+	load := func(lr types.LoadRestrictions, mount string) error {
+		t.Helper()
+		rm, err := rmF.NewResMapFromBytes([]byte(`
+apiVersion: example.com/v1
+kind: RenderCharts
+metadata:
+  name: render
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: example.com/render-charts
+        mounts:
+        - ` + mount + `
+`))
+		require.NoError(t, err)
+		pLdr := NewLoader(types.EnabledPluginConfig(types.BploUseStaticallyLinked), rmF, fSys)
+		pLdr.SetLoadRestrictions(lr)
+		_, err = pLdr.LoadTransformers(fLdr, valtest_test.MakeFakeValidator(), rm)
+		return err
+	}
+
+	require.NoError(t, load(types.LoadRestrictionsRootOnly, "{type: bind, src: charts, dst: /charts}"))
+	require.NoError(t, load(types.LoadRestrictionsRootOnly, "{type: tmpfs, dst: /tmp}"))
+	for mount, expectedErr := range map[string]string{
+		"{type: bind, src: ../charts, dst: /charts}": "with mount path '../charts' is not permitted; " +
+			"mount paths must be under the current kustomization directory",
+		"{type: bind, src: linked, dst: /charts}": "with mount path 'linked' is not permitted; " +
+			"mount paths must be under the current kustomization directory",
+		"{type: bind, src: " + outside + ", dst: /charts}": "with mount path '" + outside + "' is not permitted; " +
+			"mount paths must be relative to the current kustomization directory",
+		"{type: bind, src: charts, dst: charts}": `mount dst "charts" must be an absolute path in the container`,
+		"{type: nfs, src: charts, dst: /charts}": `mount to "/charts" has the unsupported type "nfs"`,
+		"{type: bind, dst: /charts}":             `bind mount to "/charts" has no src`,
+	} {
+		err := load(types.LoadRestrictionsRootOnly, mount)
+		if assert.Error(t, err, mount) {
+			assert.Contains(t, err.Error(), expectedErr, mount)
+		}
+	}
+
+	// lifting the load restrictions allows mounts outside of the root
+	require.NoError(t, load(types.LoadRestrictionsNone, "{type: bind, src: ../charts, dst: /charts}"))
+	require.NoError(t, load(types.LoadRestrictionsNone, "{type: bind, src: linked, dst: /charts}"))
+
+	// the root of the repository allows mounts elsewhere in the repository
+	err = load(types.LoadRestrictionsRootOfRepo, "{type: bind, src: ../charts, dst: /charts}")
+	assert.ErrorContains(t, err, "mount paths must be under the current kustomization directory")
+	require.NoError(t, fSys.MkdirAll(filepath.Join(base, ".git")))
+	require.NoError(t, load(types.LoadRestrictionsRootOfRepo, "{type: bind, src: ../charts, dst: /charts}"))
+	err = load(types.LoadRestrictionsRootOfRepo, "{type: bind, src: linked, dst: /charts}")
+	assert.ErrorContains(t, err, "with mount path 'linked' is not permitted; "+
+		"mount paths must be in the git repository of the current kustomization directory")
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package profile records where the time of a kustomize build is spent.
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Kind is the kind of a step of a build.
+type Kind string
+
+const (
+	// KindBuild is the whole build of a kustomization.
+	KindBuild Kind = "build"
+	// KindAccumulation is the accumulation of the resources of a
+	// kustomization, including the builds of its bases.
+	KindAccumulation Kind = "accumulation"
+	// KindGenerator is the run of a generator.
+	KindGenerator Kind = "generator"
+	// KindTransformer is the run of a transformer.
+	KindTransformer Kind = "transformer"
+	// KindValidator is the run of a validator.
+	KindValidator Kind = "validator"
+	// KindRemote is the fetch of a remote file or repository.
+	KindRemote Kind = "remote"
+)
+
+// Entry is a step of a build.
+type Entry struct {
+	Kind Kind `json:"kind"`
+	// Name names the step, e.g. the kind and name of a plugin
+	// or the url of a remote.
+	Name string `json:"name"`
+	// Root is the root of the kustomization the step belongs to.
+	Root string `json:"root,omitempty"`
+	// Plugin is true for the runs of non-builtin plugins.
+	Plugin bool `json:"plugin,omitempty"`
+	// Duration is the time spent in the step.
+	Duration time.Duration `json:"duration"`
+	// Resources is the number of resources after the step.
+	Resources int `json:"resources"`
+}
+
+// Profile collects the entries of the steps of builds.
+// A nil Profile doesn't record anything.
+type Profile struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// New returns an empty Profile.
+func New() *Profile {
+	return &Profile{}
+}
+
+// Add records e.
+func (p *Profile) Add(e Entry) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = append(p.entries, e)
+}
+
+// Entries returns the recorded entries, in the order in which the
+// steps finished.
+func (p *Profile) Entries() []Entry {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Entry(nil), p.entries...)
+}
+
+// WriteText writes the entries as a table, followed by the total time
+// and count of the steps of each kind.
+func (p *Profile) WriteText(w io.Writer) error {
+	entries := p.Entries()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAME\tROOT\tTIME\tRESOURCES")
+	var kinds []Kind
+	totals := map[Kind]time.Duration{}
+	counts := map[Kind]int{}
+	for _, e := range entries {
+		name := e.Name
+		if e.Plugin {
+			name += " (plugin)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n",
+			e.Kind, name, e.Root, e.Duration.Round(time.Microsecond), e.Resources)
+		if _, found := counts[e.Kind]; !found {
+			kinds = append(kinds, e.Kind)
+		}
+		totals[e.Kind] += e.Duration
+		counts[e.Kind]++
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "KIND\tSTEPS\tTOTAL TIME")
+	for _, k := range kinds {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", k, counts[k], totals[k].Round(time.Microsecond))
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the entries as a JSON array.  Durations are in
+// nanoseconds.
+func (p *Profile) WriteJSON(w io.Writer) error {
+	entries := p.Entries()
+	if entries == nil {
+		entries = []Entry{}
+	}
+	b, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
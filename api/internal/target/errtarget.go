@@ -0,0 +1,154 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/api/internal/kusterr"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// ErrorCode is a stable code of the cause of an Error.
+type ErrorCode string
+
+const (
+	ErrorCodeKustomizationNotFound ErrorCode = "KustomizationNotFound"
+	ErrorCodeKustomizationInvalid  ErrorCode = "KustomizationInvalid"
+	ErrorCodeResourceLoad          ErrorCode = "ResourceLoadFailed"
+	ErrorCodeMalformedYAML         ErrorCode = "MalformedYAML"
+	ErrorCodeConfiguration         ErrorCode = "ConfigurationFailed"
+	ErrorCodeGenerator             ErrorCode = "GeneratorFailed"
+	ErrorCodeComponent             ErrorCode = "ComponentFailed"
+	ErrorCodeTransformer           ErrorCode = "TransformerFailed"
+	ErrorCodeValidator             ErrorCode = "ValidatorFailed"
+	ErrorCodeVars                  ErrorCode = "VarsFailed"
+)
+
+// Error is an error of the build of a kustomization, locating its cause
+// in the kustomization.  The errors of the kustomizations of resources
+// and components are wrapped in the errors of the kustomizations using
+// them.  Its message is the message of the error it wraps.
+type Error struct {
+	Code ErrorCode
+	// Root is the directory of the kustomization.
+	Root string
+	// File is the name of the kustomization file, if found.
+	File string
+	// Field is the field of the kustomization involved, if known,
+	// e.g. resources or patches.
+	Field string
+	// Step is the generator, transformer or validator which failed,
+	// if any, e.g. PatchTransformer.
+	Step string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// builtinFields are the fields of kustomizations configuring the
+// builtin generators and transformers.
+var builtinFields = map[string]string{
+	"ConfigMapGenerator":             "configMapGenerator",
+	"SecretGenerator":                "secretGenerator",
+	"HelmChartInflationGenerator":    "helmCharts",
+	"PatchStrategicMergeTransformer": "patchesStrategicMerge",
+	"PatchTransformer":               "patches",
+	"NamespaceTransformer":           "namespace",
+	"PrefixTransformer":              "namePrefix",
+	"SuffixTransformer":              "nameSuffix",
+	"LabelTransformer":               "labels",
+	"AnnotationsTransformer":         "commonAnnotations",
+	"PatchJson6902Transformer":       "patchesJson6902",
+	"ReplicaCountTransformer":        "replicas",
+	"ImageTagTransformer":            "images",
+	"ReplacementTransformer":         "replacements",
+}
+
+// newError returns err as an Error of the field of the kustomization,
+// or nil if err is nil.
+func (kt *KustTarget) newError(code ErrorCode, field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{
+		Code:  code,
+		Root:  kt.ldr.Root(),
+		File:  kt.kustFileName,
+		Field: field,
+		Err:   err,
+	}
+}
+
+// newStepError returns the error err of a generator, transformer or
+// validator as an Error.  Builtin steps are configured by the fields
+// of the kustomization, and the others by pluginsField.
+func (kt *KustTarget) newStepError(
+	code ErrorCode, builtin bool, pluginsField string,
+	origin *resource.Origin, step interface{}, err error) error {
+	if err == nil {
+		return nil
+	}
+	name, _ := stepName(origin, step)
+	field := pluginsField
+	if f, ok := builtinFields[name]; ok && builtin {
+		field = f
+	}
+	return &Error{
+		Code:  code,
+		Root:  kt.ldr.Root(),
+		File:  kt.kustFileName,
+		Field: field,
+		Step:  name,
+		Err:   err,
+	}
+}
+
+// resourceErrorCode returns the code of an error loading resources.
+func resourceErrorCode(err error) ErrorCode {
+	if kusterr.IsMalformedYAMLError(err) {
+		return ErrorCodeMalformedYAML
+	}
+	var yamlErr kusterr.YamlFormatError
+	if errors.As(err, &yamlErr) {
+		return ErrorCodeMalformedYAML
+	}
+	return ErrorCodeResourceLoad
+}
+
+// quotedError is an error whose message quotes the message of the
+// error it wraps, e.g. the errors of components.
+type quotedError struct {
+	prefix string
+	err    error
+}
+
+func (e *quotedError) Error() string {
+	return fmt.Sprintf("%s%q", e.prefix, e.err.Error())
+}
+
+func (e *quotedError) Unwrap() error {
+	return e.err
+}
+
+// stepError is the error of the step at index of a multiTransformer.
+type stepError struct {
+	index int
+	err   error
+}
+
+func (e *stepError) Error() string {
+	return e.err.Error()
+}
+
+func (e *stepError) Unwrap() error {
+	return e.err
+}
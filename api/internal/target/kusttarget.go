@@ -7,7 +7,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kustomize/api/ifc"
 	"sigs.k8s.io/kustomize/api/internal/accumulator"
@@ -16,7 +19,9 @@ import (
 	load "sigs.k8s.io/kustomize/api/internal/loader"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinconfig"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/internal/plugins/fnplugin"
 	"sigs.k8s.io/kustomize/api/internal/plugins/loader"
+	"sigs.k8s.io/kustomize/api/internal/profile"
 	"sigs.k8s.io/kustomize/api/internal/utils"
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/resmap"
@@ -36,6 +41,9 @@ type KustTarget struct {
 	rFactory      *resmap.Factory
 	pLdr          *loader.Loader
 	origin        *resource.Origin
+	trackOrigins  bool
+	profile       *profile.Profile
+	requirePinned bool
 }
 
 // NewKustTarget returns a new instance of KustTarget.
@@ -56,12 +64,16 @@ func NewKustTarget(
 func (kt *KustTarget) Load() error {
 	content, kustFileName, err := LoadKustFile(kt.ldr)
 	if err != nil {
-		return err
+		if IsMissingKustomizationFileError(err) {
+			return kt.newError(ErrorCodeKustomizationNotFound, "", err)
+		}
+		return kt.newError(ErrorCodeKustomizationInvalid, "", err)
 	}
+	kt.kustFileName = kustFileName
 
 	var k types.Kustomization
 	if err := k.Unmarshal(content); err != nil {
-		return err
+		return kt.newError(ErrorCodeKustomizationInvalid, "", err)
 	}
 
 	// show warning message when using deprecated fields.
@@ -75,20 +87,32 @@ func (kt *KustTarget) Load() error {
 
 	// check that Kustomization is empty
 	if err := k.CheckEmpty(); err != nil {
-		return err
+		return kt.newError(ErrorCodeKustomizationInvalid, "", err)
 	}
 
 	errs := k.EnforceFields()
 	if len(errs) > 0 {
-		return fmt.Errorf(
+		return kt.newError(ErrorCodeKustomizationInvalid, "", fmt.Errorf(
 			"Failed to read kustomization file under %s:\n"+
-				strings.Join(errs, "\n"), kt.ldr.Root())
+				strings.Join(errs, "\n"), kt.ldr.Root()))
+	}
+	if len(k.AllowedPaths) > 0 {
+		if a, ok := kt.ldr.(pathAllower); ok {
+			if err := a.AllowPaths(k.AllowedPaths); err != nil {
+				return kt.newError(ErrorCodeKustomizationInvalid, "allowedPaths", err)
+			}
+		}
 	}
 	kt.kustomization = &k
-	kt.kustFileName = kustFileName
 	return nil
 }
 
+// pathAllower is implemented by the loaders which can load the files of
+// the allowedPaths of kustomizations, outside of their root.
+type pathAllower interface {
+	AllowPaths(paths []string) error
+}
+
 // Kustomization returns a copy of the immutable, internal kustomization object.
 func (kt *KustTarget) Kustomization() types.Kustomization {
 	var result types.Kustomization
@@ -120,6 +144,48 @@ func LoadKustFile(ldr ifc.Loader) ([]byte, string, error) {
 	}
 }
 
+// TrackOrigins makes the target record the origin annotations of the
+// resources, even if the kustomization doesn't request them.
+func (kt *KustTarget) TrackOrigins() {
+	kt.trackOrigins = true
+}
+
+// AddComponents appends the components at paths, relative to the root
+// of the target, to those of its kustomization.  They are applied after
+// the components of the kustomization.  Load must be called first.
+func (kt *KustTarget) AddComponents(paths ...string) {
+	kt.kustomization.Components = append(kt.kustomization.Components, paths...)
+}
+
+// SetProfile makes the target record the time spent in the accumulation
+// of resources and in each generator, transformer and validator in p.
+func (kt *KustTarget) SetProfile(p *profile.Profile) {
+	kt.profile = p
+}
+
+// SetRequirePinned makes the target require the helm charts of it and its
+// bases to be pinned to exact versions.  The remote bases are required to
+// be pinned by the loader.
+func (kt *KustTarget) SetRequirePinned(requirePinned bool) {
+	kt.requirePinned = requirePinned
+}
+
+// exactVersionPattern matches the exact semantic versions of charts, e.g.
+// 1.2.3 or v1.2.3-rc.1, rather than version ranges, e.g. ~1.2 or >=1.2.0.
+var exactVersionPattern = regexp.MustCompile(
+	`^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// errIfChartNotPinned returns an error if the remote chart isn't pinned to
+// an exact version, rather than e.g. the latest one or a version range.
+func errIfChartNotPinned(chart types.HelmChart) error {
+	if chart.Repo == "" || exactVersionPattern.MatchString(chart.Version) {
+		// charts without a repo are local
+		return nil
+	}
+	return fmt.Errorf("%w: helm chart %q of repo %q must have an exact version, not %q",
+		load.ErrNotPinned, chart.Name, chart.Repo, chart.Version)
+}
+
 // MakeCustomizedResMap creates a fully customized ResMap
 // per the instructions contained in its kustomization instance.
 func (kt *KustTarget) MakeCustomizedResMap() (resmap.ResMap, error) {
@@ -128,7 +194,7 @@ func (kt *KustTarget) MakeCustomizedResMap() (resmap.ResMap, error) {
 
 func (kt *KustTarget) makeCustomizedResMap() (resmap.ResMap, error) {
 	var origin *resource.Origin
-	if len(kt.kustomization.BuildMetadata) != 0 {
+	if len(kt.kustomization.BuildMetadata) != 0 || kt.trackOrigins {
 		origin = &resource.Origin{}
 	}
 	kt.origin = origin
@@ -155,7 +221,7 @@ func (kt *KustTarget) makeCustomizedResMap() (resmap.ResMap, error) {
 	// With all the back references fixed, it's OK to resolve Vars.
 	err = ra.ResolveVars()
 	if err != nil {
-		return nil, err
+		return nil, kt.newError(ErrorCodeVars, "vars", err)
 	}
 
 	err = kt.IgnoreLocal(ra)
@@ -198,29 +264,36 @@ func (kt *KustTarget) AccumulateTarget() (
 // (or empty if the Component does not have a parent).
 func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	resRa *accumulator.ResAccumulator, err error) {
+	start := time.Now()
+	kt.prefetchRemoteBases()
 	ra, err = kt.accumulateResources(ra, kt.kustomization.Resources)
 	if err != nil {
-		return nil, errors.WrapPrefixf(err, "accumulating resources")
+		return nil, kt.newError(resourceErrorCode(err), "resources",
+			errors.WrapPrefixf(err, "accumulating resources"))
 	}
+	kt.profile.Add(profile.Entry{
+		Kind: profile.KindAccumulation, Name: "resources", Root: kt.ldr.Root(),
+		Duration: time.Since(start), Resources: ra.ResMap().Size(),
+	})
 	tConfig, err := builtinconfig.MakeTransformerConfig(
 		kt.ldr, kt.kustomization.Configurations)
 	if err != nil {
-		return nil, err
+		return nil, kt.newError(ErrorCodeConfiguration, "configurations", err)
 	}
 	err = ra.MergeConfig(tConfig)
 	if err != nil {
-		return nil, errors.WrapPrefixf(
-			err, "merging config %v", tConfig)
+		return nil, kt.newError(ErrorCodeConfiguration, "configurations",
+			errors.WrapPrefixf(err, "merging config %v", tConfig))
 	}
 	crdTc, err := accumulator.LoadConfigFromCRDs(kt.ldr, kt.kustomization.Crds)
 	if err != nil {
-		return nil, errors.WrapPrefixf(
-			err, "loading CRDs %v", kt.kustomization.Crds)
+		return nil, kt.newError(ErrorCodeConfiguration, "crds",
+			errors.WrapPrefixf(err, "loading CRDs %v", kt.kustomization.Crds))
 	}
 	err = ra.MergeConfig(crdTc)
 	if err != nil {
-		return nil, errors.WrapPrefixf(
-			err, "merging CRDs %v", crdTc)
+		return nil, kt.newError(ErrorCodeConfiguration, "crds",
+			errors.WrapPrefixf(err, "merging CRDs %v", crdTc))
 	}
 	err = kt.runGenerators(ra)
 	if err != nil {
@@ -231,7 +304,8 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	// https://github.com/kubernetes-sigs/kustomize/pull/5170#discussion_r1212101287
 	ra, err = kt.accumulateComponents(ra, kt.kustomization.Components)
 	if err != nil {
-		return nil, errors.WrapPrefixf(err, "accumulating components")
+		return nil, kt.newError(ErrorCodeComponent, "components",
+			errors.WrapPrefixf(err, "accumulating components"))
 	}
 
 	err = kt.runTransformers(ra)
@@ -244,8 +318,8 @@ func (kt *KustTarget) accumulateTarget(ra *accumulator.ResAccumulator) (
 	}
 	err = ra.MergeVars(kt.kustomization.Vars)
 	if err != nil {
-		return nil, errors.WrapPrefixf(
-			err, "merging vars %v", kt.kustomization.Vars)
+		return nil, kt.newError(ErrorCodeVars, "vars", errors.WrapPrefixf(
+			err, "merging vars %v", kt.kustomization.Vars))
 	}
 	return ra, nil
 }
@@ -271,16 +345,31 @@ func (kt *KustTarget) runGenerators(
 		return err
 	}
 	generators = append(generators, gs...)
+	builtinCount := len(gs)
 
 	gs, err = kt.configureExternalGenerators()
 	if err != nil {
-		return errors.WrapPrefixf(err, "loading generator plugins")
+		return kt.newError(ErrorCodeGenerator, "generators",
+			errors.WrapPrefixf(err, "loading generator plugins"))
 	}
 	generators = append(generators, gs...)
 	for i, g := range generators {
+		start := time.Now()
 		resMap, err := g.Generate()
 		if err != nil {
-			return err
+			return kt.newStepError(ErrorCodeGenerator, i < builtinCount, "generators",
+				g.Origin, g.Generator, err)
+		}
+		if kt.profile != nil {
+			name, plugin := stepName(g.Origin, g.Generator)
+			resources := 0
+			if resMap != nil {
+				resources = resMap.Size()
+			}
+			kt.profile.Add(profile.Entry{
+				Kind: profile.KindGenerator, Name: name, Root: kt.ldr.Root(), Plugin: plugin,
+				Duration: time.Since(start), Resources: resources,
+			})
 		}
 		if resMap != nil {
 			err = resMap.AddOriginAnnotation(generators[i].Origin)
@@ -324,7 +413,11 @@ func (kt *KustTarget) configureExternalGenerators() (
 	if err != nil {
 		return nil, err
 	}
-	return kt.pLdr.LoadGenerators(kt.ldr, kt.validator, ra.ResMap())
+	rm := ra.ResMap()
+	if err := kt.resolveFunctions(rm); err != nil {
+		return nil, err
+	}
+	return kt.pLdr.LoadGenerators(kt.ldr, kt.validator, rm)
 }
 
 func (kt *KustTarget) runTransformers(ra *accumulator.ResAccumulator) error {
@@ -335,12 +428,20 @@ func (kt *KustTarget) runTransformers(ra *accumulator.ResAccumulator) error {
 		return err
 	}
 	r = append(r, lts...)
+	builtinCount := len(lts)
 	lts, err = kt.configureExternalTransformers(kt.kustomization.Transformers)
 	if err != nil {
-		return err
+		return kt.newError(ErrorCodeTransformer, "transformers", err)
 	}
 	r = append(r, lts...)
-	return ra.Transform(newMultiTransformer(r))
+	err = ra.Transform(newMultiTransformer(r, kt.profile, kt.ldr.Root()))
+	var stepErr *stepError
+	if errors.As(err, &stepErr) {
+		t := r[stepErr.index]
+		return kt.newStepError(ErrorCodeTransformer, stepErr.index < builtinCount, "transformers",
+			t.Origin, t.Transformer, stepErr.err)
+	}
+	return err
 }
 
 func (kt *KustTarget) configureExternalTransformers(transformers []string) ([]*resmap.TransformerWithProperties, error) {
@@ -371,27 +472,70 @@ func (kt *KustTarget) configureExternalTransformers(transformers []string) ([]*r
 	if err != nil {
 		return nil, err
 	}
-	return kt.pLdr.LoadTransformers(kt.ldr, kt.validator, ra.ResMap())
+	rm := ra.ResMap()
+	if err := kt.resolveFunctions(rm); err != nil {
+		return nil, err
+	}
+	return kt.pLdr.LoadTransformers(kt.ldr, kt.validator, rm)
+}
+
+// resolveFunctions replaces the Pipelines of rm with the configurations
+// of their functions, and resolves the function configurations without a
+// function annotation to the functions of the trusted catalogs or of the
+// catalogs of the kustomization, in this order.
+func (kt *KustTarget) resolveFunctions(rm resmap.ResMap) error {
+	if err := fnplugin.ExpandPipelines(rm, kt.rFactory); err != nil {
+		return err
+	}
+	catalogs := append([]*types.Catalog{}, kt.pLdr.Config().FnpLoadingOptions.Catalogs...)
+	for _, path := range kt.kustomization.Catalogs {
+		content, err := kt.ldr.Load(path)
+		if err != nil {
+			return errors.WrapPrefixf(err, "loading catalog %s", path)
+		}
+		c, err := types.ParseCatalog(content)
+		if err != nil {
+			return errors.WrapPrefixf(err, "loading catalog %s", path)
+		}
+		catalogs = append(catalogs, c)
+	}
+	for _, res := range rm.Resources() {
+		if _, err := fnplugin.ResolveFromCatalogs(res, catalogs); err != nil {
+			return errors.WrapPrefixf(err, "resolving function %s", res.OrgId())
+		}
+	}
+	return nil
 }
 
 func (kt *KustTarget) runValidators(ra *accumulator.ResAccumulator) error {
 	validators, err := kt.configureExternalTransformers(kt.kustomization.Validators)
 	if err != nil {
-		return err
+		return kt.newError(ErrorCodeValidator, "validators", err)
 	}
 	for _, v := range validators {
 		// Validators shouldn't modify the resource map
 		orignal := ra.ResMap().DeepCopy()
+		start := time.Now()
 		err = v.Transform(ra.ResMap())
 		if err != nil {
-			return err
+			return kt.newStepError(ErrorCodeValidator, false, "validators",
+				v.Origin, v.Transformer, err)
+		}
+		if kt.profile != nil {
+			name, plugin := stepName(v.Origin, v.Transformer)
+			kt.profile.Add(profile.Entry{
+				Kind: profile.KindValidator, Name: name, Root: kt.ldr.Root(), Plugin: plugin,
+				Duration: time.Since(start), Resources: ra.ResMap().Size(),
+			})
 		}
 		newMap := ra.ResMap().DeepCopy()
 		if err = kt.removeValidatedByLabel(newMap); err != nil {
 			return err
 		}
 		if err = orignal.ErrorIfNotEqualSets(newMap); err != nil {
-			return fmt.Errorf("validator shouldn't modify the resource map: %v", err)
+			return kt.newStepError(ErrorCodeValidator, false, "validators",
+				v.Origin, v.Transformer,
+				fmt.Errorf("validator shouldn't modify the resource map: %v", err))
 		}
 	}
 	return nil
@@ -412,6 +556,74 @@ func (kt *KustTarget) removeValidatedByLabel(rm resmap.ResMap) error {
 	return nil
 }
 
+// prefetcher is implemented by the loaders which can fetch the remote
+// bases of a kustomization concurrently, before they're accumulated.
+type prefetcher interface {
+	Prefetch(paths []string)
+}
+
+// prefetchRemoteBases fetches the remote bases of the resources and
+// components of the kustomization concurrently, if the loader can.  The
+// resources which look like files, e.g. raw urls of yaml files, aren't
+// prefetched.
+func (kt *KustTarget) prefetchRemoteBases() {
+	p, ok := kt.ldr.(prefetcher)
+	if !ok {
+		return
+	}
+	paths := make([]string, 0, len(kt.kustomization.Resources)+len(kt.kustomization.Components))
+	for _, path := range kt.kustomization.Resources {
+		switch strings.ToLower(filepath.Ext(strings.SplitN(path, "?", 2)[0])) {
+		case ".yaml", ".yml", ".json":
+			continue
+		}
+		paths = append(paths, path)
+	}
+	p.Prefetch(append(paths, kt.kustomization.Components...))
+}
+
+// SetOpenAPISchema sets the schema of the openapi field of the
+// kustomization, i.e. its builtin version or the schema of its path, and
+// the schemas of its openapiMerge field merged over it.  Unless reset, the
+// schema is only set if no other kustomization has set it.
+func (kt *KustTarget) SetOpenAPISchema(reset bool) error {
+	var schema []byte
+	if path, exists := kt.kustomization.OpenAPI[types.OpenAPIPathKey]; exists {
+		var err error
+		if schema, err = kt.ldr.Load(path); err != nil {
+			return err
+		}
+	}
+	var overlays [][]byte
+	for _, path := range kt.kustomization.OpenAPIMerge {
+		overlay, err := kt.ldr.Load(path)
+		if err != nil {
+			return err
+		}
+		overlays = append(overlays, overlay)
+	}
+	err := openapi.SetSchemaWithOverlays(kt.kustomization.OpenAPI, schema, overlays, reset)
+	if err != nil && len(overlays) > 0 {
+		return errors.WrapPrefixf(err, "setting openapiMerge schemas %v", kt.kustomization.OpenAPIMerge)
+	}
+	return err
+}
+
+// committer is implemented by the loaders which know the commit of the
+// git repository they were cloned from.
+type committer interface {
+	Commit() string
+}
+
+// loaderCommit returns the commit of the git repository cloned by ldr,
+// or the empty string if ldr wasn't cloned or the commit is unknown.
+func loaderCommit(ldr ifc.Loader) string {
+	if c, ok := ldr.(committer); ok {
+		return c.Commit()
+	}
+	return ""
+}
+
 // accumulateResources fills the given resourceAccumulator
 // with resources read from the given list of paths.
 func (kt *KustTarget) accumulateResources(
@@ -434,7 +646,7 @@ func (kt *KustTarget) accumulateResources(
 			// store the origin, we'll need it later
 			origin := kt.origin.Copy()
 			if kt.origin != nil {
-				kt.origin = kt.origin.Append(path)
+				kt.origin = kt.origin.Append(path).WithCommit(loaderCommit(ldr))
 				ra, err = kt.accumulateDirectory(ra, ldr, false)
 				// after we are done recursing through the directory, reset the origin
 				kt.origin = &origin
@@ -467,7 +679,7 @@ func (kt *KustTarget) accumulateComponents(
 		// store the origin, we'll need it later
 		origin := kt.origin.Copy()
 		if kt.origin != nil {
-			kt.origin = kt.origin.Append(path)
+			kt.origin = kt.origin.Append(path).WithCommit(loaderCommit(ldr))
 			ra, errD = kt.accumulateDirectory(ra, ldr, true)
 			// after we are done recursing through the directory, reset the origin
 			kt.origin = &origin
@@ -475,7 +687,7 @@ func (kt *KustTarget) accumulateComponents(
 			ra, errD = kt.accumulateDirectory(ra, ldr, true)
 		}
 		if errD != nil {
-			return nil, fmt.Errorf("accumulateDirectory: %q", errD)
+			return nil, &quotedError{prefix: "accumulateDirectory: ", err: errD}
 		}
 	}
 	return ra, nil
@@ -492,15 +704,9 @@ func (kt *KustTarget) accumulateDirectory(
 	}
 	subKt.kustomization.BuildMetadata = kt.kustomization.BuildMetadata
 	subKt.origin = kt.origin
-	var bytes []byte
-	if openApiPath, exists := subKt.Kustomization().OpenAPI["path"]; exists {
-		bytes, err = ldr.Load(openApiPath)
-		if err != nil {
-			return nil, err
-		}
-	}
-	err = openapi.SetSchema(subKt.Kustomization().OpenAPI, bytes, false)
-	if err != nil {
+	subKt.profile = kt.profile
+	subKt.requirePinned = kt.requirePinned
+	if err = subKt.SetOpenAPISchema(false); err != nil {
 		return nil, err
 	}
 	if isComponent && subKt.kustomization.Kind != types.ComponentKind {
@@ -576,3 +782,16 @@ func (kt *KustTarget) configureBuiltinPlugin(
 	}
 	return nil
 }
+
+// stepName returns the name of a generator, transformer or validator in
+// profiles, and whether it's a non-builtin plugin.
+func stepName(origin *resource.Origin, step interface{}) (name string, plugin bool) {
+	if origin == nil || origin.ConfiguredBy.Kind == "" {
+		name = strings.TrimPrefix(fmt.Sprintf("%T", step), "*builtins.")
+		return strings.TrimSuffix(name, "Plugin"), false
+	}
+	if origin.ConfiguredBy.APIVersion == "builtin" {
+		return origin.ConfiguredBy.Kind, false
+	}
+	return origin.ConfiguredBy.Kind + "/" + origin.ConfiguredBy.Name, true
+}
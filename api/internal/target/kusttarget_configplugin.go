@@ -41,7 +41,7 @@ func (kt *KustTarget) configureBuiltinGenerators() (
 		r, err := generatorConfigurators[bpt](
 			kt, bpt, builtinhelpers.GeneratorFactories[bpt])
 		if err != nil {
-			return nil, err
+			return nil, kt.newError(ErrorCodeGenerator, builtinFields[bpt.String()], err)
 		}
 
 		var generatorOrigin *resource.Origin
@@ -49,6 +49,7 @@ func (kt *KustTarget) configureBuiltinGenerators() (
 			generatorOrigin = &resource.Origin{
 				Repo:         kt.origin.Repo,
 				Ref:          kt.origin.Ref,
+				Commit:       kt.origin.Commit,
 				ConfiguredIn: filepath.Join(kt.origin.Path, kt.kustFileName),
 				ConfiguredBy: yaml.ResourceIdentifier{
 					TypeMeta: yaml.TypeMeta{
@@ -85,13 +86,14 @@ func (kt *KustTarget) configureBuiltinTransformers(
 		r, err := transformerConfigurators[bpt](
 			kt, bpt, builtinhelpers.TransformerFactories[bpt], tc)
 		if err != nil {
-			return nil, err
+			return nil, kt.newError(ErrorCodeTransformer, builtinFields[bpt.String()], err)
 		}
 		var transformerOrigin *resource.Origin
 		if kt.origin != nil {
 			transformerOrigin = &resource.Origin{
 				Repo:         kt.origin.Repo,
 				Ref:          kt.origin.Ref,
+				Commit:       kt.origin.Commit,
 				ConfiguredIn: filepath.Join(kt.origin.Path, kt.kustFileName),
 				ConfiguredBy: yaml.ResourceIdentifier{
 					TypeMeta: yaml.TypeMeta{
@@ -164,6 +166,11 @@ var generatorConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 			globals = *kt.kustomization.HelmGlobals
 		}
 		for _, chart := range kt.kustomization.HelmCharts {
+			if kt.requirePinned {
+				if err = errIfChartNotPinned(chart); err != nil {
+					return nil, err
+				}
+			}
 			c.HelmGlobals = globals
 			c.HelmChart = chart
 			p := f()
@@ -443,4 +450,10 @@ var transformerConfigurators = map[builtinhelpers.BuiltinPluginType]func(
 		result []resmap.Transformer, err error) {
 		return nil, fmt.Errorf("valueadd keyword not yet defined")
 	},
+	// No kustomization file keyword for this yet.
+	builtinhelpers.ApplySettersTransformer: func(
+		kt *KustTarget, bpt builtinhelpers.BuiltinPluginType, f tFactory, tc *builtinconfig.TransformerConfig) (
+		result []resmap.Transformer, err error) {
+		return nil, fmt.Errorf("applysetters keyword not yet defined")
+	},
 }
@@ -4,20 +4,30 @@
 package target
 
 import (
+	"time"
+
+	"sigs.k8s.io/kustomize/api/internal/profile"
 	"sigs.k8s.io/kustomize/api/resmap"
 )
 
 // multiTransformer contains a list of transformers.
 type multiTransformer struct {
 	transformers []*resmap.TransformerWithProperties
+	// profile, if non-nil, records the time spent in each transformer
+	// of the kustomization at root.
+	profile *profile.Profile
+	root    string
 }
 
 var _ resmap.Transformer = &multiTransformer{}
 
 // newMultiTransformer constructs a multiTransformer.
-func newMultiTransformer(t []*resmap.TransformerWithProperties) resmap.Transformer {
+func newMultiTransformer(
+	t []*resmap.TransformerWithProperties, p *profile.Profile, root string) resmap.Transformer {
 	r := &multiTransformer{
 		transformers: make([]*resmap.TransformerWithProperties, len(t)),
+		profile:      p,
+		root:         root,
 	}
 	copy(r.transformers, t)
 	return r
@@ -26,9 +36,17 @@ func newMultiTransformer(t []*resmap.TransformerWithProperties) resmap.Transform
 // Transform applies the member transformers in order to the resources,
 // optionally detecting and erroring on commutation conflict.
 func (o *multiTransformer) Transform(m resmap.ResMap) error {
-	for _, t := range o.transformers {
+	for i, t := range o.transformers {
+		start := time.Now()
 		if err := t.Transform(m); err != nil {
-			return err
+			return &stepError{index: i, err: err}
+		}
+		if o.profile != nil {
+			name, plugin := stepName(t.Origin, t.Transformer)
+			o.profile.Add(profile.Entry{
+				Kind: profile.KindTransformer, Name: name, Root: o.root, Plugin: plugin,
+				Duration: time.Since(start), Resources: m.Size(),
+			})
 		}
 		if t.Origin != nil {
 			if err := m.AddTransformerAnnotation(t.Origin); err != nil {
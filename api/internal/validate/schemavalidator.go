@@ -0,0 +1,273 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	extIntOrString           = "x-kubernetes-int-or-string"
+	extPreserveUnknownFields = "x-kubernetes-preserve-unknown-fields"
+	quantityRefSuffix        = "api.resource.Quantity"
+)
+
+// implicitFields are the fields of all resources.
+var implicitFields = map[string]bool{"apiVersion": true, "kind": true, "metadata": true}
+
+// SchemaValidator validates resources against their openapi schema,
+// reporting unknown fields and values of the wrong type.
+type SchemaValidator struct {
+	// Strict makes unknown fields and resources without a schema errors.
+	// Otherwise unknown fields are warnings, and resources without a schema
+	// aren't validated.
+	Strict bool
+
+	// Schemas are the schemas of resources, e.g. read from CRDs by
+	// SchemasFromCRDs.  They take precedence over the global openapi schema.
+	Schemas map[yaml.TypeMeta]*spec.Schema
+}
+
+// Validate validates the resources of m, and returns the errors and warnings found.
+// Each message starts with the origin of the resource if it is known, and the path
+// of the field.
+func (v SchemaValidator) Validate(m resmap.ResMap) (errs []string, warnings []string) {
+	for _, res := range m.Resources() {
+		prefix := resourcePrefix(res)
+		typeMeta := yaml.TypeMeta{APIVersion: res.GetApiVersion(), Kind: res.GetKind()}
+		schema, found := v.Schemas[typeMeta]
+		if !found {
+			if rs := openapi.SchemaForResourceType(typeMeta); rs != nil {
+				schema = rs.Schema
+			}
+		}
+		if schema == nil {
+			if v.Strict {
+				errs = append(errs, prefix+"no schema found for "+typeMeta.APIVersion+" "+typeMeta.Kind)
+			}
+			continue
+		}
+		w := schemaWalker{prefix: prefix}
+		w.walk(res.YNode(), schema, "")
+		errs = append(errs, w.errs...)
+		if v.Strict {
+			errs = append(errs, w.unknown...)
+		} else {
+			warnings = append(warnings, w.unknown...)
+		}
+	}
+	return errs, warnings
+}
+
+// resourcePrefix returns the origin and id of res for messages.
+func resourcePrefix(res *resource.Resource) string {
+	prefix := res.CurId().String() + ": "
+	origin, err := res.GetOrigin()
+	if err != nil || origin == nil {
+		return prefix
+	}
+	path := origin.Path
+	if path == "" {
+		path = origin.ConfiguredIn
+	}
+	if origin.Repo != "" {
+		path = origin.Repo + "/" + path
+	}
+	if path == "" {
+		return prefix
+	}
+	return path + ": " + prefix
+}
+
+// schemaWalker collects the problems of a node and its fields.
+type schemaWalker struct {
+	prefix  string
+	errs    []string
+	unknown []string
+}
+
+func (w *schemaWalker) walk(node *yaml.Node, s *spec.Schema, path string) {
+	quantity := false
+	for s != nil && s.Ref.String() != "" {
+		quantity = quantity || strings.HasSuffix(s.Ref.String(), quantityRefSuffix)
+		resolved, err := openapi.Resolve(&s.Ref, openapi.Schema())
+		if err != nil {
+			return
+		}
+		s = resolved
+	}
+	if s == nil || node == nil || node.ShortTag() == yaml.NodeTagNull {
+		return
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch {
+	case quantity:
+		w.expectScalar(node, path, "quantity", yaml.NodeTagString, yaml.NodeTagInt, yaml.NodeTagFloat)
+		return
+	case isTrue(s.Extensions[extIntOrString]) || s.Format == "int-or-string":
+		w.expectScalar(node, path, "integer or string", yaml.NodeTagString, yaml.NodeTagInt)
+		return
+	}
+	switch {
+	case s.Type.Contains("object") || (len(s.Type) == 0 && len(s.Properties) > 0):
+		w.walkObject(node, s, path)
+	case s.Type.Contains("array"):
+		if node.Kind != yaml.SequenceNode {
+			w.mismatch(node, path, "array")
+			return
+		}
+		if s.Items == nil || s.Items.Schema == nil {
+			return
+		}
+		for i, item := range node.Content {
+			w.walk(item, s.Items.Schema, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case s.Type.Contains("string"):
+		w.expectScalar(node, path, "string", yaml.NodeTagString)
+	case s.Type.Contains("integer"):
+		w.expectScalar(node, path, "integer", yaml.NodeTagInt)
+	case s.Type.Contains("number"):
+		w.expectScalar(node, path, "number", yaml.NodeTagInt, yaml.NodeTagFloat)
+	case s.Type.Contains("boolean"):
+		w.expectScalar(node, path, "boolean", yaml.NodeTagBool)
+	}
+}
+
+func (w *schemaWalker) walkObject(node *yaml.Node, s *spec.Schema, path string) {
+	if node.Kind != yaml.MappingNode {
+		w.mismatch(node, path, "object")
+		return
+	}
+	preserveUnknown := isTrue(s.Extensions[extPreserveUnknownFields])
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		fieldPath := key
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		if prop, found := s.Properties[key]; found {
+			w.walk(value, &prop, fieldPath)
+			continue
+		}
+		switch {
+		case path == "" && implicitFields[key]:
+			// the schemas of CRDs don't need to declare them
+		case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+			w.walk(value, s.AdditionalProperties.Schema, fieldPath)
+		case s.AdditionalProperties != nil && s.AdditionalProperties.Allows,
+			preserveUnknown, len(s.Properties) == 0:
+			// any field is allowed
+		default:
+			w.unknown = append(w.unknown, w.prefix+fieldPath+": unknown field")
+		}
+	}
+}
+
+func (w *schemaWalker) expectScalar(node *yaml.Node, path, expected string, tags ...string) {
+	if node.Kind == yaml.ScalarNode {
+		tag := node.ShortTag()
+		for _, t := range tags {
+			if tag == t {
+				return
+			}
+		}
+	}
+	w.mismatch(node, path, expected)
+}
+
+func (w *schemaWalker) mismatch(node *yaml.Node, path, expected string) {
+	if path == "" {
+		path = "."
+	}
+	w.errs = append(w.errs, fmt.Sprintf("%s%s: expected %s, got %s",
+		w.prefix, path, expected, nodeType(node)))
+}
+
+// nodeType returns the openapi type name of node.
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.ShortTag() {
+	case yaml.NodeTagString:
+		return "string"
+	case yaml.NodeTagInt:
+		return "integer"
+	case yaml.NodeTagFloat:
+		return "number"
+	case yaml.NodeTagBool:
+		return "boolean"
+	default:
+		return node.ShortTag()
+	}
+}
+
+func isTrue(ext interface{}) bool {
+	b, ok := ext.(bool)
+	return ok && b
+}
+
+// SchemasFromCRDs returns the schemas of the versions of the
+// CustomResourceDefinitions in b, by the type of their resources.
+// The other resources of b are ignored.
+func SchemasFromCRDs(b []byte) (map[yaml.TypeMeta]*spec.Schema, error) {
+	nodes, err := kio.FromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	schemas := map[yaml.TypeMeta]*spec.Schema{}
+	for _, node := range nodes {
+		if node.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+		group, err := node.GetString("spec.group")
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "CustomResourceDefinition %s", node.GetName())
+		}
+		kind, err := node.GetString("spec.names.kind")
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "CustomResourceDefinition %s", node.GetName())
+		}
+		versions, err := node.GetSlice("spec.versions")
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "CustomResourceDefinition %s", node.GetName())
+		}
+		for _, version := range versions {
+			v, ok := version.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := v["name"].(string)
+			schema, found := v["schema"].(map[string]interface{})
+			if !found || name == "" {
+				continue
+			}
+			s := &spec.Schema{}
+			b, err := json.Marshal(schema["openAPIV3Schema"])
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			if err := json.Unmarshal(b, s); err != nil {
+				return nil, errors.WrapPrefixf(err, "schema of CustomResourceDefinition %s", node.GetName())
+			}
+			schemas[yaml.TypeMeta{APIVersion: group + "/" + name, Kind: kind}] = s
+		}
+	}
+	return schemas, nil
+}
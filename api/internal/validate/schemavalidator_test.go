@@ -0,0 +1,100 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package validate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "sigs.k8s.io/kustomize/api/internal/validate"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+)
+
+const crds = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              port:
+                x-kubernetes-int-or-string: true
+              labels:
+                type: object
+                additionalProperties:
+                  type: string
+              config:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+                properties:
+                  enabled:
+                    type: boolean
+  - name: v2
+    served: false
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+`
+
+func TestSchemaValidator(t *testing.T) {
+	schemas, err := SchemasFromCRDs([]byte(crds))
+	require.NoError(t, err)
+	require.Len(t, schemas, 1)
+
+	m, err := resmap.NewFactory(provider.NewDefaultDepProvider().GetResourceFactory()).NewResMapFromBytes([]byte(`
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: valid
+spec:
+  port: http
+  labels:
+    a: b
+  config:
+    enabled: true
+    other: 1
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: invalid
+spec:
+  port: 1.5
+  labels:
+    a: 1
+  config:
+    enabled: 1
+  size: 3
+`))
+	require.NoError(t, err)
+
+	errs, warnings := SchemaValidator{Schemas: schemas}.Validate(m)
+	assert.Equal(t, []string{
+		"Widget.v1.example.com/invalid.[noNs]: spec.port: expected integer or string, got number",
+		"Widget.v1.example.com/invalid.[noNs]: spec.labels.a: expected string, got integer",
+		"Widget.v1.example.com/invalid.[noNs]: spec.config.enabled: expected boolean, got integer",
+	}, errs)
+	assert.Equal(t, []string{
+		"Widget.v1.example.com/invalid.[noNs]: spec.size: unknown field",
+	}, warnings)
+
+	errs, warnings = SchemaValidator{Schemas: schemas, Strict: true}.Validate(m)
+	assert.Len(t, errs, 4)
+	assert.Empty(t, warnings)
+}
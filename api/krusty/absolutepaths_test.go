@@ -0,0 +1,68 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestAllowAbsolutePaths(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/nix/store/abc-base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("/nix/store/abc-base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+`)
+	th.WriteF("/nix/store/def-config/configmap.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`)
+	th.WriteF("/nix/store/ghi-patches/replicas.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+`)
+	th.WriteK("/app", `
+resources:
+- file:///nix/store/abc-base
+- /nix/store/def-config/configmap.yaml
+patches:
+- path: /nix/store/ghi-patches/replicas.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	// without the option, file:// urls are git repos
+	err := th.RunWithErr("/app", opts)
+	assert.ErrorContains(t, err, "accumulating resources from 'file:///nix/store/abc-base'")
+
+	opts.AllowAbsolutePaths = true
+	m := th.Run("/app", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`)
+}
@@ -441,6 +441,149 @@ spec:
 `)
 }
 
+func TestSharedPatchAllowedPaths(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSmallBase(th)
+	th.WriteK("overlay", `
+resources:
+- ../base
+patchesStrategicMerge:
+- ../shared/deployment-patch.yaml
+allowedPaths:
+- ../shared
+`)
+	th.WriteF("shared/deployment-patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeployment
+spec:
+  replicas: 1000
+`)
+	th.WriteF("secrets/deployment-patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeployment
+spec:
+  replicas: 1
+`)
+	opts := th.MakeDefaultOptions()
+	// the allowed paths are ignored unless enabled
+	err := th.RunWithErr("overlay", opts)
+	if err == nil || !strings.Contains(err.Error(),
+		"security; file '/shared/deployment-patch.yaml' is not in or below '/overlay'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	opts.EnableAllowedPaths = true
+	m := th.Run("overlay", opts)
+	yml, err := m.AsYaml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(yml), "replicas: 1000") {
+		t.Fatalf("expected the shared patch to be applied:\n%s", yml)
+	}
+
+	th.WriteK("overlay", `
+resources:
+- ../base
+patchesStrategicMerge:
+- ../secrets/deployment-patch.yaml
+allowedPaths:
+- ../shared
+`)
+	err = th.RunWithErr("overlay", opts)
+	if err == nil || !strings.Contains(err.Error(),
+		"security; file '/secrets/deployment-patch.yaml' is not in or below '/overlay'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	th.WriteK("overlay", `
+resources:
+- ../base
+allowedPaths:
+- ../missing
+`)
+	err = th.RunWithErr("overlay", opts)
+	if err == nil || !strings.Contains(err.Error(), "allowed path '../missing' must be a directory") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSharedPatchInRepo(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("repo/app", `
+resources:
+- ../base
+patchesStrategicMerge:
+- ../shared/deployment-patch.yaml
+`)
+	th.WriteK("repo/base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("repo/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeployment
+spec:
+  replicas: 1
+`)
+	th.WriteF("repo/shared/deployment-patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeployment
+spec:
+  replicas: 1000
+`)
+	opts := th.MakeDefaultOptions()
+	opts.LoadRestrictions = types.LoadRestrictionsRootOfRepo
+	// without a repository, files outside of the root are forbidden
+	err := th.RunWithErr("repo/app", opts)
+	if err == nil || !strings.Contains(err.Error(),
+		"security; file '/repo/shared/deployment-patch.yaml' is not in or below '/repo/app'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = th.GetFSys().MkdirAll("/repo/.git"); err != nil {
+		t.Fatal(err)
+	}
+	m := th.Run("repo/app", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeployment
+spec:
+  replicas: 1000
+`)
+
+	th.WriteF("outside/deployment-patch.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeployment
+spec:
+  replicas: 3
+`)
+	th.WriteK("repo/app", `
+resources:
+- ../base
+patchesStrategicMerge:
+- ../../outside/deployment-patch.yaml
+`)
+	err = th.RunWithErr("repo/app", opts)
+	if err == nil || !strings.Contains(err.Error(),
+		"security; file '/outside/deployment-patch.yaml' is not in or below '/repo', "+
+			"the root of the git repository of '/repo/app'") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestSmallOverlayJSONPatch(t *testing.T) {
 	th := kusttest_test.MakeHarness(t)
 	writeSmallBase(th)
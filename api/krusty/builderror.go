@@ -0,0 +1,47 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty
+
+import (
+	"errors"
+
+	"sigs.k8s.io/kustomize/api/internal/target"
+)
+
+// BuildError locates the cause of a failed build in a kustomization:
+// its directory and file, the field involved and the generator,
+// transformer or validator which failed, if any.  The errors of the
+// kustomizations of resources and components are wrapped in the
+// errors of the kustomizations using them.
+type BuildError = target.Error
+
+// ErrorCode is a stable code of the cause of a BuildError.
+type ErrorCode = target.ErrorCode
+
+// The codes of BuildErrors.
+const (
+	ErrorCodeKustomizationNotFound = target.ErrorCodeKustomizationNotFound
+	ErrorCodeKustomizationInvalid  = target.ErrorCodeKustomizationInvalid
+	ErrorCodeResourceLoad          = target.ErrorCodeResourceLoad
+	ErrorCodeMalformedYAML         = target.ErrorCodeMalformedYAML
+	ErrorCodeConfiguration         = target.ErrorCodeConfiguration
+	ErrorCodeGenerator             = target.ErrorCodeGenerator
+	ErrorCodeComponent             = target.ErrorCodeComponent
+	ErrorCodeTransformer           = target.ErrorCodeTransformer
+	ErrorCodeValidator             = target.ErrorCodeValidator
+	ErrorCodeVars                  = target.ErrorCodeVars
+)
+
+// BuildErrors returns the BuildErrors in the chain of err, from the
+// kustomization built to the one where the build failed.
+func BuildErrors(err error) []*BuildError {
+	var result []*BuildError
+	for err != nil {
+		if e, ok := err.(*BuildError); ok { //nolint:errorlint
+			result = append(result, e)
+		}
+		err = errors.Unwrap(err)
+	}
+	return result
+}
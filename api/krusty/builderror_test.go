@@ -0,0 +1,103 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+// codesAndFields returns the codes, roots and fields of the BuildErrors of err.
+func codesAndFields(err error) [][3]string {
+	var result [][3]string
+	for _, e := range krusty.BuildErrors(err) {
+		result = append(result, [3]string{string(e.Code), e.Root, e.Field})
+	}
+	return result
+}
+
+func TestBuildErrors(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- missing.yaml
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+`)
+	err := th.RunWithErr("overlay", th.MakeDefaultOptions())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"accumulating resources: accumulation err='accumulating resources from '../base'")
+	assert.Equal(t, [][3]string{
+		{"ResourceLoadFailed", "/overlay", "resources"},
+		{"ResourceLoadFailed", "/base", "resources"},
+	}, codesAndFields(err))
+	assert.Equal(t, "kustomization.yaml", krusty.BuildErrors(err)[1].File)
+
+	th.WriteK("overlay", `
+resources:
+- ../missing
+`)
+	err = th.RunWithErr("overlay", th.MakeDefaultOptions())
+	require.Error(t, err)
+	assert.Equal(t, krusty.ErrorCodeResourceLoad, krusty.BuildErrors(err)[0].Code)
+
+	th.WriteF("base/missing.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+components:
+- ../component
+`)
+	th.WriteC("component", `
+patches:
+- path: missing-patch.yaml
+`)
+	err = th.RunWithErr("overlay", th.MakeDefaultOptions())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `accumulating components: accumulateDirectory: "`)
+	errs := krusty.BuildErrors(err)
+	assert.Equal(t, [][3]string{
+		{"ComponentFailed", "/overlay", "components"},
+		{"TransformerFailed", "/component", "patches"},
+	}, codesAndFields(err))
+	assert.Equal(t, "kustomization.yaml", errs[1].File)
+
+	th.WriteK("overlay", `
+resources:
+- ../base
+patches:
+- patch: |-
+    - op: replace
+      path: /data/missing
+      value: x
+  target:
+    kind: ConfigMap
+`)
+	err = th.RunWithErr("overlay", th.MakeDefaultOptions())
+	require.Error(t, err)
+	errs = krusty.BuildErrors(err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, krusty.ErrorCodeTransformer, errs[0].Code)
+	assert.Equal(t, "patches", errs[0].Field)
+	assert.Equal(t, "PatchTransformer", errs[0].Step)
+
+	th.WriteF("empty/deployment.yaml", "")
+	err = th.RunWithErr("empty", th.MakeDefaultOptions())
+	require.Error(t, err)
+	assert.Equal(t, [][3]string{
+		{"KustomizationNotFound", "/empty", ""},
+	}, codesAndFields(err))
+}
@@ -767,3 +767,68 @@ spec:
 		})
 	}
 }
+
+func TestOptionsComponents(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("overlay", `
+resources:
+- deployment.yaml
+components:
+- ../components/replicas
+`)
+	th.WriteF("overlay/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+`)
+	th.WriteC("components/replicas", `
+replicas:
+- name: app
+  count: 3
+`)
+	th.WriteC("ci/debug", `
+patches:
+- patch: |-
+    - op: add
+      path: /spec/template/spec/containers/-
+      value:
+        name: debug
+        image: busybox
+  target:
+    kind: Deployment
+`)
+	th.WriteC("ci/canary", `
+nameSuffix: -canary
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Components = []string{"ci/debug", "/ci/canary"}
+	m := th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app-canary
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: app
+        name: app
+      - image: busybox
+        name: debug
+`)
+
+	opts.Components = []string{"ci/missing"}
+	err := th.RunWithErr("overlay", opts)
+	if err == nil || !strings.Contains(err.Error(), `component "ci/missing"`) {
+		t.Fatalf("expected an error about the missing component, got %v", err)
+	}
+}
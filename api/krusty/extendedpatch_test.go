@@ -1213,3 +1213,101 @@ spec:
     app: busybox
 `)
 }
+
+func TestExtendedPatchVersionWildcard(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("base/widgets.yaml", `
+apiVersion: example.com/v1beta1
+kind: Widget
+metadata:
+  name: beta
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: stable
+---
+apiVersion: example.com/v2
+kind: Widget
+metadata:
+  name: next
+`)
+	th.WriteK("base", `
+resources:
+- widgets.yaml
+patches:
+- patch: |-
+    - op: add
+      path: /metadata/annotations
+      value:
+        selected: "true"
+  target:
+    group: example.com
+    version: "glob:v1*"
+    kind: Widget
+`)
+	m := th.Run("base", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: example.com/v1beta1
+kind: Widget
+metadata:
+  annotations:
+    selected: "true"
+  name: beta
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  annotations:
+    selected: "true"
+  name: stable
+---
+apiVersion: example.com/v2
+kind: Widget
+metadata:
+  name: next
+`)
+}
+
+func TestExtendedPatchVersionRegex(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteF("base/widgets.yaml", `
+apiVersion: example.com/v1beta1
+kind: Widget
+metadata:
+  name: beta
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: stable
+`)
+	th.WriteK("base", `
+resources:
+- widgets.yaml
+patches:
+- patch: |-
+    - op: add
+      path: /metadata/annotations
+      value:
+        selected: "true"
+  target:
+    group: example.com
+    version: v1*
+    kind: Widget
+`)
+	m := th.Run("base", th.MakeDefaultOptions())
+	th.AssertActualEqualsExpected(m, `
+apiVersion: example.com/v1beta1
+kind: Widget
+metadata:
+  name: beta
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  annotations:
+    selected: "true"
+  name: stable
+`)
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const setTeamCatalog = `
+apiVersion: config.kubernetes.io/v1alpha1
+kind: Catalog
+metadata:
+  name: example-co-functions
+spec:
+  krmFunctions:
+  - group: example.co
+    names:
+      kind: SetTeam
+    versions:
+    - name: v1
+      runtime:
+        container:
+          image: docker.example.co/functions/set-team:v1.0.0
+          sha256: a428de44a9059f31a59237a5881c2d2cffa93757d99026156e4ea544577ab7f3
+          requireNetwork: true
+`
+
+func writeSetTeamKustomization(th kusttest_test.Harness) {
+	th.WriteK(".", `
+resources:
+- deployment.yaml
+transformers:
+- set-team.yaml
+catalogs:
+- catalog.yaml
+`)
+	th.WriteF("deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+`)
+	th.WriteF("set-team.yaml", `
+apiVersion: example.co/v1
+kind: SetTeam
+metadata:
+  name: set-team
+team: platform
+`)
+}
+
+func TestFnCatalogResolvesFunction(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSetTeamKustomization(th)
+	th.WriteF("catalog.yaml", setTeamCatalog)
+	// the function of the catalog requires the network, which isn't enabled
+	err := th.RunWithErr(".", th.MakeOptionsPluginsEnabled())
+	assert.Contains(t, err.Error(), "network required but not enabled")
+}
+
+func TestFnCatalogTrustedCatalogTakesPrecedence(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSetTeamKustomization(th)
+	th.WriteF("catalog.yaml", `
+apiVersion: config.kubernetes.io/v1alpha1
+kind: Catalog
+metadata:
+  name: local-functions
+spec:
+  krmFunctions:
+  - group: example.co
+    names:
+      kind: SetTeam
+    versions:
+    - name: v2
+      runtime:
+        container:
+          image: docker.example.co/functions/set-team:v2.0.0
+`)
+	// the catalog of the kustomization doesn't list the version of set-team.yaml
+	err := th.RunWithErr(".", th.MakeOptionsPluginsEnabled())
+	assert.NotContains(t, err.Error(), "network required but not enabled")
+
+	trusted, err := types.ParseCatalog([]byte(setTeamCatalog))
+	require.NoError(t, err)
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.Catalogs = []*types.Catalog{trusted}
+	err = th.RunWithErr(".", o)
+	assert.Contains(t, err.Error(), "network required but not enabled")
+}
+
+func TestFnCatalogInvalid(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeSetTeamKustomization(th)
+	th.WriteF("catalog.yaml", `
+apiVersion: v1
+kind: ConfigMap
+`)
+	err := th.RunWithErr(".", th.MakeOptionsPluginsEnabled())
+	assert.Contains(t, err.Error(),
+		"loading catalog catalog.yaml: invalid catalog: expected apiVersion config.kubernetes.io/v1alpha1 and kind Catalog, got v1 ConfigMap")
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+)
+
+func TestFnExecAllowlist(t *testing.T) {
+	fSys := filesys.MakeFsOnDisk()
+	th := kusttest_test.MakeHarnessWithFs(t, fSys)
+
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer fSys.RemoveAll(tmpDir.String())
+	base := tmpDir.String()
+	th.WriteK(base, `
+resources:
+- secret.yaml
+transformers:
+- krm-transformer.yaml
+`)
+	th.WriteF(filepath.Join(base, "secret.yaml"), `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: dummy
+type: Opaque
+stringData:
+  foo: bar
+`)
+	th.WriteF(filepath.Join(base, "krmTransformer.sh"), krmTransformerDotSh)
+	require.NoError(t, os.Chmod(filepath.Join(base, "krmTransformer.sh"), 0o700))
+	th.WriteF(filepath.Join(base, "krm-transformer.yaml"), `
+apiVersion: examples.config.kubernetes.io/v1beta1
+kind: MyPlugin
+metadata:
+  name: notImportantHere
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: ./krmTransformer.sh
+`)
+	sum := sha256.Sum256([]byte(krmTransformerDotSh))
+	script := filepath.Join(base, "krmTransformer.sh")
+
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableExec = true
+	o.PluginConfig.FnpLoadingOptions.ExecAllowlist = []exec.AllowedExecutable{
+		{Path: script, Sha256: hex.EncodeToString(sum[:])},
+	}
+	m := th.Run(base, o)
+	yml, err := m.AsYaml()
+	require.NoError(t, err)
+	assert.Contains(t, string(yml), "name: dummyTransformed")
+
+	o.PluginConfig.FnpLoadingOptions.ExecAllowlist = []exec.AllowedExecutable{
+		{Path: script, Sha256: strings.Repeat("0", 64)},
+	}
+	err = th.RunWithErr(base, o)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "executable "+script+" doesn't have the sha256 digest allowed for it")
+
+	o.PluginConfig.FnpLoadingOptions.ExecAllowlist = []exec.AllowedExecutable{}
+	err = th.RunWithErr(base, o)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "executable "+script+" isn't in the allowlist of executables")
+}
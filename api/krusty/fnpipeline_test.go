@@ -0,0 +1,111 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestFnPipelineSharedByKustomizations(t *testing.T) {
+	fSys := filesys.MakeFsOnDisk()
+	th := kusttest_test.MakeHarnessWithFs(t, fSys)
+	o := th.MakeOptionsPluginsEnabled()
+	o.PluginConfig.FnpLoadingOptions.EnableExec = true
+	o.LoadRestrictions = types.LoadRestrictionsNone
+
+	tmpDir, err := filesys.NewTmpConfirmedDir()
+	require.NoError(t, err)
+	defer fSys.RemoveAll(tmpDir.String())
+	shared := filepath.Join(tmpDir.String(), "shared")
+	require.NoError(t, fSys.MkdirAll(shared))
+	// the functions run in order: the second one replaces what the first one set
+	for name, script := range map[string]string{
+		"first.sh":  "#!/bin/sh\nsed 's/team: unset/team: platform/'\n",
+		"second.sh": "#!/bin/sh\nsed 's/team: platform/team: payments/'\n",
+	} {
+		th.WriteF(filepath.Join(shared, name), script)
+		require.NoError(t, os.Chmod(filepath.Join(shared, name), 0o700))
+	}
+	th.WriteF(filepath.Join(shared, "pipeline.yaml"), `
+apiVersion: config.kubernetes.io/v1alpha1
+kind: Pipeline
+metadata:
+  name: team-defaults
+spec:
+  functions:
+  - exec:
+      path: `+filepath.Join(shared, "first.sh")+`
+    config:
+      apiVersion: example.co/v1
+      kind: SetTeam
+      metadata:
+        name: first
+  - exec:
+      path: `+filepath.Join(shared, "second.sh")+`
+    config:
+      apiVersion: example.co/v1
+      kind: SetTeam
+      metadata:
+        name: second
+`)
+
+	for _, app := range []string{"app1", "app2"} {
+		dir := filepath.Join(tmpDir.String(), app)
+		require.NoError(t, fSys.MkdirAll(dir))
+		th.WriteK(dir, `
+resources:
+- configmap.yaml
+transformers:
+- ../shared/pipeline.yaml
+`)
+		th.WriteF(filepath.Join(dir, "configmap.yaml"), `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: `+app+`
+data:
+  team: unset
+`)
+		m := th.Run(dir, o)
+		yml, err := m.AsYaml()
+		require.NoError(t, err)
+		assert.Equal(t, `apiVersion: v1
+data:
+  team: payments
+kind: ConfigMap
+metadata:
+  name: `+app+`
+`, string(yml))
+	}
+}
+
+func TestFnPipelineInvalid(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK(".", `
+transformers:
+- pipeline.yaml
+`)
+	th.WriteF("pipeline.yaml", `
+apiVersion: config.kubernetes.io/v1alpha1
+kind: Pipeline
+metadata:
+  name: team-defaults
+spec:
+  functions:
+  - exec:
+      path: ./set-team.sh
+`)
+	err := th.RunWithErr(".", th.MakeOptionsPluginsEnabled())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"expanding pipeline team-defaults: invalid pipeline: function 0 has no config")
+}
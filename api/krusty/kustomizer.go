@@ -6,20 +6,31 @@ package krusty
 import (
 	"fmt"
 	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
 
 	"sigs.k8s.io/kustomize/api/internal/builtins"
+	"sigs.k8s.io/kustomize/api/internal/git"
 	fLdr "sigs.k8s.io/kustomize/api/internal/loader"
 	pLdr "sigs.k8s.io/kustomize/api/internal/plugins/loader"
+	"sigs.k8s.io/kustomize/api/internal/profile"
 	"sigs.k8s.io/kustomize/api/internal/target"
 	"sigs.k8s.io/kustomize/api/internal/utils"
+	"sigs.k8s.io/kustomize/api/internal/validate"
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/provenance"
 	"sigs.k8s.io/kustomize/api/provider"
 	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
-	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 // Kustomizer performs kustomizations.
@@ -54,38 +65,87 @@ func MakeKustomizer(o *Options) *Kustomizer {
 // and Run can be called on each of them).
 func (b *Kustomizer) Run(
 	fSys filesys.FileSystem, path string) (resmap.ResMap, error) {
+	start := time.Now()
 	resmapFactory := resmap.NewFactory(b.depProvider.GetResourceFactory())
 	lr := fLdr.RestrictionNone
-	if b.options.LoadRestrictions == types.LoadRestrictionsRootOnly {
+	switch b.options.LoadRestrictions {
+	case types.LoadRestrictionsRootOnly:
 		lr = fLdr.RestrictionRootOnly
+	case types.LoadRestrictionsRootOfRepo:
+		lr = fLdr.RestrictionRootOfRepo
+	}
+	cloner := git.ClonerUsingGitExec
+	if len(b.options.GitCredentials) > 0 || !b.options.HTTP.IsZero() {
+		cloner = git.ClonerWithHTTPConfig(b.options.GitCredentials, b.options.HTTP)
+	}
+	if b.options.RemoteCacheDir != "" {
+		cloner = git.CachingCloner(cloner, b.options.RemoteCacheDir, b.options.RemoteCacheTTL)
 	}
-	ldr, err := fLdr.NewLoader(lr, path, fSys)
+	if b.options.GitFetch != (types.GitFetchConfig{}) {
+		// set before the clones are looked up in the cache, whose keys
+		// include what's fetched
+		cloner = git.ClonerWithFetchConfig(cloner, b.options.GitFetch)
+	}
+	var hc *http.Client
+	if !b.options.HTTP.IsZero() {
+		transport, err := b.options.HTTP.Transport()
+		if err != nil {
+			return nil, err
+		}
+		hc = &http.Client{Transport: transport}
+	}
+	ldr, err := fLdr.NewLoaderWithOptions(lr, path, fSys, fLdr.Options{
+		Profile:            b.options.Profile,
+		VendorDir:          b.options.VendorDir,
+		Cloner:             cloner,
+		RequirePinned:      b.options.RequirePinnedRemotes,
+		SchemeHandlers:     b.options.SchemeHandlers,
+		HTTPClient:         hc,
+		FetchConcurrency:   b.options.RemoteFetchConcurrency,
+		AllowAbsolutePaths: b.options.AllowAbsolutePaths,
+		EnableAllowedPaths: b.options.EnableAllowedPaths,
+	})
 	if err != nil {
 		return nil, err
 	}
 	defer ldr.Cleanup()
+	// The plugin configs are always located on disk, regardless of the fSys passed in
+	pc := b.options.PluginConfig
+	if pc != nil && !b.options.HTTP.IsZero() {
+		c := *pc
+		c.HelmConfig.HTTP = b.options.HTTP
+		pc = &c
+	}
+	pl := pLdr.NewLoader(pc, resmapFactory, filesys.MakeFsOnDisk())
+	pl.SetExtraBuiltins(b.options.ExtraBuiltins)
+	pl.SetLoadRestrictions(b.options.LoadRestrictions)
 	kt := target.NewKustTarget(
 		ldr,
 		b.depProvider.GetFieldValidator(),
 		resmapFactory,
-		// The plugin configs are always located on disk, regardless of the fSys passed in
-		pLdr.NewLoader(b.options.PluginConfig, resmapFactory, filesys.MakeFsOnDisk()),
+		pl,
 	)
 	err = kt.Load()
 	if err != nil {
 		return nil, err
 	}
-	var bytes []byte
-	if openApiPath, exists := kt.Kustomization().OpenAPI["path"]; exists {
-		bytes, err = ldr.Load(openApiPath)
+	if len(b.options.Components) > 0 {
+		paths, err := componentPaths(fSys, ldr.Root(), b.options.Components)
 		if err != nil {
 			return nil, err
 		}
+		kt.AddComponents(paths...)
 	}
-	err = openapi.SetSchema(kt.Kustomization().OpenAPI, bytes, true)
-	if err != nil {
+	if err = kt.SetOpenAPISchema(true); err != nil {
 		return nil, err
 	}
+	if b.options.Validation != ValidationOptionNone || b.options.Profile != nil {
+		// the origins of the resources locate validation errors,
+		// and name the generators and transformers in profiles
+		kt.TrackOrigins()
+	}
+	kt.SetProfile(b.options.Profile)
+	kt.SetRequirePinned(b.options.RequirePinnedRemotes)
 	var m resmap.ResMap
 	m, err = kt.MakeCustomizedResMap()
 	if err != nil {
@@ -110,6 +170,11 @@ func (b *Kustomizer) Run(
 			return nil, err
 		}
 	}
+	if b.options.Validation != ValidationOptionNone {
+		if err = b.validate(fSys, m); err != nil {
+			return nil, err
+		}
+	}
 	m.RemoveBuildAnnotations()
 	if !utils.StringSliceContains(kt.Kustomization().BuildMetadata, types.OriginAnnotations) {
 		err = m.RemoveOriginAnnotations()
@@ -123,9 +188,42 @@ func (b *Kustomizer) Run(
 			return nil, errors.WrapPrefixf(err, "failed to clean up transformer annotations")
 		}
 	}
+	b.options.Profile.Add(profile.Entry{
+		Kind: profile.KindBuild, Name: path, Root: ldr.Root(),
+		Duration: time.Since(start), Resources: m.Size(),
+	})
 	return m, nil
 }
 
+// validate validates m against the openapi schema of its resources.
+func (b *Kustomizer) validate(fSys filesys.FileSystem, m resmap.ResMap) error {
+	v := validate.SchemaValidator{
+		Strict:  b.options.Validation == ValidationOptionStrict,
+		Schemas: map[yaml.TypeMeta]*spec.Schema{},
+	}
+	for _, path := range b.options.ValidationSchemaPaths {
+		content, err := fSys.ReadFile(path)
+		if err != nil {
+			return errors.WrapPrefixf(err, "reading validation schemas")
+		}
+		schemas, err := validate.SchemasFromCRDs(content)
+		if err != nil {
+			return errors.WrapPrefixf(err, "reading validation schemas from %s", path)
+		}
+		for t, s := range schemas {
+			v.Schemas[t] = s
+		}
+	}
+	errs, warnings := v.Validate(m)
+	for _, w := range warnings {
+		log.Println("Warning: " + w)
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("validation failed:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
+
 func (b *Kustomizer) applySortOrder(m resmap.ResMap, kt *target.KustTarget) error {
 	// Sort order can be defined in two places:
 	// - (new) kustomization file
@@ -158,6 +256,68 @@ func (b *Kustomizer) applySortOrder(m resmap.ResMap, kt *target.KustTarget) erro
 			},
 		}
 		return errors.Wrap(pl.Transform(m))
+	} else if b.options.Reorder == ReorderOptionCustom {
+		// Case 3: Custom sort order set in CLI flag.
+		return errors.Wrap(applyCustomOrder(m, b.options.CustomOrder))
+	}
+	return nil
+}
+
+// CustomOrderWildcard is the kind of the entry of a custom order which
+// selects the resources no other entry selects.
+const CustomOrderWildcard = "*"
+
+// applyCustomOrder sorts the resources of m in the given order.
+// See Options.CustomOrder.
+func applyCustomOrder(m resmap.ResMap, order []resid.Gvk) error {
+	wildcard := len(order)
+	for i, gvk := range order {
+		if gvk.Kind == CustomOrderWildcard {
+			wildcard = i
+			break
+		}
+	}
+	buckets := make([][]*resource.Resource, len(order)+1)
+	for _, r := range m.Resources() {
+		bucket := wildcard
+		gvk := r.GetGvk()
+		for i := range order {
+			if order[i].Kind != CustomOrderWildcard && gvk.IsSelected(&order[i]) {
+				bucket = i
+				break
+			}
+		}
+		buckets[bucket] = append(buckets[bucket], r)
+	}
+	m.Clear()
+	for _, bucket := range buckets {
+		for _, r := range bucket {
+			if err := m.Append(r); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
+
+// componentPaths returns the paths of the given components relative to
+// the root of the kustomization.  Git repository urls are left as is.
+func componentPaths(fSys filesys.FileSystem, root string, components []string) ([]string, error) {
+	paths := make([]string, 0, len(components))
+	for _, c := range components {
+		if _, err := git.NewRepoSpecFromURL(c); err == nil {
+			paths = append(paths, c)
+			continue
+		}
+		dir, err := filesys.ConfirmDir(fSys, c)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "component %q", c)
+		}
+		path, err := filepath.Rel(root, dir.String())
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "component %q", c)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
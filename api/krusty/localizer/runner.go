@@ -9,9 +9,38 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
+// LockFileName is the name of the lockfile written to the localize destination,
+// recording the url, resolved commit and content digest of each remote source.
+const LockFileName = localizer.LockFileName
+
+// Options are the optional settings of `kustomize localize`.
+type Options struct {
+	// HelmCommand is the helm executable used to pull the remote charts of
+	// helmCharts entries into the localized chart home, so that the localized
+	// kustomization builds offline. Remote charts are left un-localized if empty.
+	HelmCommand string
+}
+
 // Run executes `kustomize localize` on fSys given the `localize` arguments and
 // returns the path to the created newDir.
 func Run(fSys filesys.FileSystem, target, scope, newDir string) (string, error) {
-	dst, err := localizer.Run(target, scope, newDir, fSys)
+	return RunWithOptions(fSys, target, scope, newDir, Options{})
+}
+
+// RunWithOptions is Run with the optional settings opts.
+func RunWithOptions(fSys filesys.FileSystem, target, scope, newDir string, opts Options) (string, error) {
+	dst, err := localizer.RunWithOptions(target, scope, newDir, fSys, localizer.Options{
+		HelmCommand: opts.HelmCommand,
+	})
 	return dst, errors.Wrap(err)
 }
+
+// Verify checks the directory newDir on fSys created by `kustomize localize`:
+// that it has a lockfile, that the content localized to it, including the
+// chart homes that remote charts were pulled into, matches the digests
+// recorded in the lockfile, that no remote content is localized without
+// being recorded in the lockfile, and that the kustomizations in newDir have
+// no remote references left.
+func Verify(fSys filesys.FileSystem, newDir string) error {
+	return errors.Wrap(localizer.Verify(newDir, fSys))
+}
@@ -269,6 +269,7 @@ func TestWorkingDir(t *testing.T) {
 	require.Equal(t, wd.Join("localized-target"), dst)
 
 	SetupDir(t, fsExpected, dst, files)
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, wd.String(), fsExpected, fsActual)
 }
 
@@ -315,9 +316,19 @@ func TestLoaderSymlinks(t *testing.T) {
 `,
 		filepath.Join("nested", "file"): simpleDeployment,
 	})
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, dst, fsExpected, fsActual)
 }
 
+// checkAndRemoveLockFile checks that the content localized to dst on fSys
+// matches its lockfile, and removes the lockfile.
+func checkAndRemoveLockFile(t *testing.T, fSys filesys.FileSystem, dst string) {
+	t.Helper()
+
+	require.NoError(t, localizer.Verify(fSys, dst))
+	require.NoError(t, fSys.RemoveAll(filepath.Join(dst, localizer.LockFileName)))
+}
+
 func TestRemoteTargetDefaultDst(t *testing.T) {
 	fsExpected, fsActual, testDir := PrepareFs(t, nil, nil)
 	SetWorkingDir(t, testDir.String())
@@ -331,6 +342,7 @@ func TestRemoteTargetDefaultDst(t *testing.T) {
 	SetupDir(t, fsExpected,
 		filepath.Join(dst, "api", "krusty", "testdata", "localize", "simple"),
 		files)
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, testDir.String(), fsExpected, fsActual)
 }
 
@@ -396,6 +408,7 @@ openapi:
 		"kustomization.yaml": fmt.Sprintf(kustf, localizedPath),
 		localizedPath:        customSchema,
 	})
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, testDir.String(), fsExpected, fsActual)
 }
 
@@ -418,6 +431,7 @@ func TestRemoteRoot(t *testing.T) {
 `, localizedPath),
 	})
 	SetupDir(t, fsExpected, filepath.Join(dst, localizedPath), files)
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, testDir.String(), fsExpected, fsActual)
 }
 
@@ -442,6 +456,7 @@ func TestNestedRemoteRoots(t *testing.T) {
 `, localizedPath),
 	})
 	SetupDir(t, fsExpected, filepath.Join(dst, localizedPath), files)
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, testDir.String(), fsExpected, fsActual)
 }
 
@@ -528,6 +543,7 @@ minecraftServer:
 	require.Equal(t, newDir, dst)
 
 	SetupDir(t, fsExpected, dst, files)
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, dst, fsExpected, fsActual)
 }
 
@@ -573,6 +589,7 @@ helmGlobals:
 		filepath.Join("target", "myValues.yaml"):     valuesFile,
 		filepath.Join("home", "name", "values.yaml"): valuesFile,
 	})
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, dst, fsExpected, fsActual)
 }
 
@@ -613,6 +630,7 @@ helmCharts:
 		filepath.Join("charts", "default", "values.yaml"): valuesFile,
 		filepath.Join("charts", "same", "values.yaml"):    valuesFile,
 	})
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, dst, fsExpected, fsActual)
 }
 
@@ -650,6 +668,7 @@ func TestHelmHomeEscapesScope(t *testing.T) {
 `,
 	})
 	require.NoError(t, fsExpected.Mkdir(filepath.Join(dst, "home")))
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, dst, fsExpected, fsActual)
 }
 
@@ -681,5 +700,6 @@ func TestSymlinkedFileSource(t *testing.T) {
 `,
 		"different-key": "properties",
 	})
+	checkAndRemoveLockFile(t, fsActual, dst)
 	CheckFs(t, dst, fsExpected, fsActual)
 }
@@ -11,6 +11,7 @@ import (
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/openapi/kubernetesapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func writeTestSchema(th kusttest_test.Harness, filepath string) {
@@ -642,3 +643,132 @@ spec:
 		assert.Equal(t, kubernetesapi.DefaultOpenAPI, openapi.GetSchemaVersion())
 	})
 }
+
+// myCRDSchema is a schema of only MyCRD, whose template refers to the
+// pod template of the builtin schema.
+const myCRDSchema = `{
+  "definitions": {
+    "v1alpha1.MyCRD": {
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"type": "object"},
+        "spec": {
+          "properties": {
+            "template": {"$ref": "#/definitions/io.k8s.api.core.v1.PodTemplateSpec"}
+          },
+          "type": "object"
+        }
+      },
+      "type": "object",
+      "x-kubernetes-group-version-kind": [
+        {"group": "example.com", "kind": "MyCRD", "version": "v1alpha1"}
+      ]
+    }
+  }
+}`
+
+func TestCustomOpenApiFieldMerge(t *testing.T) {
+	runOpenApiTest(t, func(t *testing.T) {
+		t.Helper()
+		th := kusttest_test.MakeHarness(t)
+		th.WriteK(".", `
+resources:
+- mycrd.yaml
+- deployment.yaml
+openapiMerge:
+- mycrd_schema.json
+- other_schema.yaml
+`+customSchemaPatch+`
+- |-
+  apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: myDeployment
+  spec:
+    template:
+      spec:
+        containers:
+        - name: server
+          image: nginx
+`)
+		writeCustomResource(th, "mycrd.yaml")
+		th.WriteF("mycrd_schema.json", myCRDSchema)
+		th.WriteF("other_schema.yaml", `
+definitions:
+  v1.OtherCRD:
+    type: object
+    x-kubernetes-group-version-kind:
+    - group: example.com
+      kind: OtherCRD
+      version: v1
+`)
+		th.WriteF("deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: server
+        image: server
+        command: example
+`)
+		// the containers of both the custom resource and the deployment
+		// are merged by name
+		m := th.Run(".", th.MakeDefaultOptions())
+		th.AssertActualEqualsExpected(m, patchedCustomResource+`---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: myDeployment
+spec:
+  template:
+    spec:
+      containers:
+      - command: example
+        image: nginx
+        name: server
+`)
+		// the schemas of every openapiMerge path are merged
+		assert.NotNil(t, openapi.SchemaForResourceType(
+			yaml.TypeMeta{APIVersion: "example.com/v1", Kind: "OtherCRD"}))
+	})
+}
+
+func TestCustomOpenApiFieldMergeFileNotFound(t *testing.T) {
+	runOpenApiTest(t, func(t *testing.T) {
+		t.Helper()
+		th := kusttest_test.MakeHarness(t)
+		th.WriteK(".", `
+resources:
+- mycrd.yaml
+openapiMerge:
+- mycrd_schema.json
+`)
+		writeCustomResource(th, "mycrd.yaml")
+		err := th.RunWithErr(".", th.MakeDefaultOptions())
+		assert.ErrorContains(t, err, "mycrd_schema.json")
+	})
+}
+
+func TestCustomOpenApiFieldMergeInvalid(t *testing.T) {
+	runOpenApiTest(t, func(t *testing.T) {
+		t.Helper()
+		th := kusttest_test.MakeHarness(t)
+		th.WriteK(".", `
+resources:
+- mycrd.yaml
+openapiMerge:
+- mycrd_schema.json
+- invalid_schema.yaml
+`)
+		writeCustomResource(th, "mycrd.yaml")
+		th.WriteF("mycrd_schema.json", myCRDSchema)
+		th.WriteF("invalid_schema.yaml", `definitions: [1, 2]`)
+		err := th.RunWithErr(".", th.MakeDefaultOptions())
+		assert.ErrorContains(t, err, "invalid schema overlay 1")
+	})
+}
@@ -4,15 +4,63 @@
 package krusty
 
 import (
+	"time"
+
+	fLdr "sigs.k8s.io/kustomize/api/internal/loader"
 	"sigs.k8s.io/kustomize/api/internal/plugins/builtinhelpers"
+	"sigs.k8s.io/kustomize/api/internal/profile"
+	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/resid"
 )
 
 type ReorderOption string
 
+// Profile records the time spent in the steps of builds, e.g. in
+// accumulation, each generator and transformer, and remote fetches.
+type Profile = profile.Profile
+
+// ProfileEntry is a step of a build recorded in a Profile.
+type ProfileEntry = profile.Entry
+
+// The kinds of the steps of builds recorded in a Profile.
+const (
+	ProfileKindBuild        = profile.KindBuild
+	ProfileKindAccumulation = profile.KindAccumulation
+	ProfileKindGenerator    = profile.KindGenerator
+	ProfileKindTransformer  = profile.KindTransformer
+	ProfileKindValidator    = profile.KindValidator
+	ProfileKindRemote       = profile.KindRemote
+)
+
+// NewProfile returns an empty Profile.
+func NewProfile() *Profile {
+	return profile.New()
+}
+
+// SchemeHandler loads the remote bases and components with a custom url
+// scheme, e.g. artifactory://repo/platform/base, by fetching their files
+// into directories.  See Options.SchemeHandlers.
+type SchemeHandler = fLdr.SchemeHandler
+
+type ValidationOption string
+
+const (
+	// ValidationOptionNone doesn't validate the output.
+	ValidationOptionNone ValidationOption = ""
+	// ValidationOptionEnabled validates the output against the openapi schema
+	// of the resources, failing on values of the wrong type and warning about
+	// unknown fields.
+	ValidationOptionEnabled ValidationOption = "enabled"
+	// ValidationOptionStrict validates like ValidationOptionEnabled, and also
+	// fails on unknown fields and resources without a schema.
+	ValidationOptionStrict ValidationOption = "strict"
+)
+
 const (
 	ReorderOptionLegacy      ReorderOption = "legacy"
 	ReorderOptionNone        ReorderOption = "none"
+	ReorderOptionCustom      ReorderOption = "custom"
 	ReorderOptionUnspecified ReorderOption = "unspecified"
 )
 
@@ -30,10 +78,19 @@ type Options struct {
 	//   compatibility.
 	// - "none": Respect the depth-first resource input order as specified by the
 	//   kustomization file.
+	// - "custom": Use the order of CustomOrder.
 	// - "unspecified": The user didn't specify any preference. Kustomize will
 	//   select the appropriate default.
 	Reorder ReorderOption
 
+	// The order of the resources when Reorder is "custom".  Resources are
+	// emitted in the order of the first entry selecting their GVK; empty
+	// fields of an entry select any value.  The entry of kind "*" selects
+	// the resources no other entry selects, which are emitted last if
+	// there's no such entry.  Resources selected by the same entry keep
+	// their input order.
+	CustomOrder []resid.Gvk
+
 	// When true, a label
 	//     app.kubernetes.io/managed-by: kustomize-<version>
 	// is added to all the resources in the build out.
@@ -45,6 +102,91 @@ type Options struct {
 
 	// Options related to kustomize plugins.
 	PluginConfig *types.PluginConfig
+
+	// Validation of the output against the openapi schema of the resources.
+	Validation ValidationOption
+
+	// Paths to files containing CustomResourceDefinitions, whose schemas
+	// are used to validate custom resources.
+	ValidationSchemaPaths []string
+
+	// If non-nil, the steps of builds are recorded in Profile.
+	Profile *Profile
+
+	// Components applied on top of the kustomization, after its own
+	// components.  They are directories, relative to the current
+	// directory of the file system, or git repository urls.
+	Components []string
+
+	// If non-empty, remote git repositories and files are loaded from
+	// their copies in VendorDir rather than fetched, and it's an error
+	// for one to be missing.  The copies are laid out like the
+	// localized-files directory of kustomize localize, e.g.
+	//   github.com/org/repo/v1.0.0/path/in/repo
+	//   raw.githubusercontent.com/org/repo/v1.0.0/file.yaml
+	VendorDir string
+
+	// Credentials authenticating the clones of the git repositories of
+	// remote bases, in addition to those of the git configuration.  The
+	// first credential matching a repository is used.
+	GitCredentials []types.GitCredential
+
+	// What the clones of the git repositories of remote bases fetch in
+	// addition to the commit of their ref: more of its history, all the
+	// branches or the tags, unless set by the query parameters of their
+	// urls, e.g. ?depth=10.
+	GitFetch types.GitFetchConfig
+
+	// If non-empty, the clones of the git repositories of remote bases
+	// are cached in RemoteCacheDir, and reused by later builds.  Those of
+	// commit hashes are reused forever, those of other refs, e.g. branches
+	// or tags, until they're older than RemoteCacheTTL.
+	RemoteCacheDir string
+	RemoteCacheTTL time.Duration
+
+	// When true, remote bases, files and helm charts must be pinned to
+	// immutable content: git repositories to commit hashes, OCI artifacts
+	// to digests, remote archives and files to their sha256, and the
+	// charts of helm repositories to exact versions.  Bucket directories
+	// can't be pinned.
+	RequirePinnedRemotes bool
+
+	// Handlers of custom url schemes, e.g. artifactory, by scheme.  The
+	// remote bases and components with these schemes, e.g.
+	// artifactory://repo/platform/base, are fetched by their handler into
+	// directories, and built like local directories.  The handlers take
+	// precedence over the builtin schemes, e.g. oci, but http, https and
+	// file can't be handled.
+	SchemeHandlers map[string]SchemeHandler
+
+	// The proxy and extra certificate authorities of the remote fetches:
+	// the clones of git repositories over https, remote files, archives
+	// and OCI artifacts, and the pulls of helm charts.
+	HTTP types.HTTPConfig
+
+	// The number of remote bases and components of a kustomization
+	// fetched concurrently before they're accumulated, defaults to 4.
+	// With 1, they're fetched one at a time.  The SchemeHandlers must
+	// be safe for concurrent use unless it's 1.
+	RemoteFetchConcurrency int
+
+	// When true, resources, patches and other files, and bases and
+	// components may be absolute paths or file:// urls, e.g. of the inputs
+	// materialized by build systems like Bazel or Nix, which are loaded
+	// in place regardless of the LoadRestrictions.  They must still be
+	// within the repositories of remote bases.
+	AllowAbsolutePaths bool
+
+	// When true, kustomizations may read the files below the directories
+	// of their allowedPaths field, outside of their root, regardless of
+	// the LoadRestrictions.  Otherwise, the field is ignored.
+	EnableAllowedPaths bool
+
+	// Generators and transformers linked into the program, by the GVK of
+	// their config.  Like the builtins, they're loaded regardless of the
+	// plugin restrictions, e.g. by the transformers field of
+	// kustomizations.
+	ExtraBuiltins map[resid.Gvk]func() resmap.Configurable
 }
 
 // MakeDefaultOptions returns a default instance of Options.
@@ -0,0 +1,116 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestRequirePinnedRemotes(t *testing.T) {
+	const commit = "a428de44a9059f31a59237a5881c2d2cffa93757"
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("vendor/github.com/org/repo/"+commit+"/base", `
+resources:
+- configmap.yaml
+`)
+	th.WriteF("vendor/github.com/org/repo/"+commit+"/base/configmap.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+	th.WriteK("vendor/github.com/org/repo/main/base", `
+resources:
+- https://github.com/org/repo//base?ref=`+commit+`
+`)
+	th.WriteK("overlay", `
+resources:
+- https://github.com/org/repo//base?ref=`+commit+`
+`)
+	opts := th.MakeDefaultOptions()
+	opts.VendorDir = "/vendor"
+	opts.RequirePinnedRemotes = true
+	m := th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+
+	th.WriteK("overlay", `
+resources:
+- https://github.com/org/repo//base?ref=main
+`)
+	err := th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `not pinned: git repo "https://github.com/org/repo//base?ref=main" `+
+		`must be referenced by the full hash of a commit`)
+
+	// a branch is only rejected if pinned remotes are required
+	opts.RequirePinnedRemotes = false
+	th.Run("overlay", opts)
+
+	opts.RequirePinnedRemotes = true
+	th.WriteK("overlay", `
+resources:
+- oci://registry.example.com/platform/base:v1
+`)
+	err = th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `not pinned: OCI artifact "oci://registry.example.com/platform/base:v1" `+
+		`must be referenced by digest`)
+
+	// remote files are pinned by their sha256 digest
+	th.WriteF("vendor/raw.githubusercontent.com/org/repo/main/cm.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: remote
+`)
+	th.WriteK("overlay", `
+resources:
+- https://raw.githubusercontent.com/org/repo/main/cm.yaml
+`)
+	err = th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `not pinned: remote file "https://raw.githubusercontent.com/org/repo/main/cm.yaml" `+
+		`must be pinned by the sha256 digest of its content`)
+
+	const digest = "e2e0c5571ba6eba77d70a3ef37e8c5ba1bc628c8f89763a15ff0f0cb08fc5a90"
+	th.WriteK("overlay", `
+resources:
+- https://raw.githubusercontent.com/org/repo/main/cm.yaml?sha256=`+digest+`
+`)
+	m = th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: remote
+`)
+
+	th.WriteK("overlay", `
+resources:
+- https://raw.githubusercontent.com/org/repo/main/cm.yaml?sha256=`+strings.Repeat("0", 64)+`
+`)
+	err = th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't match the pinned")
+
+	th.WriteK("overlay", `
+helmCharts:
+- name: minecraft
+  repo: https://itzg.github.io/minecraft-server-charts
+  version: ^3.1.0
+`)
+	err = th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `not pinned: helm chart "minecraft" of repo `+
+		`"https://itzg.github.io/minecraft-server-charts" must have an exact version, not "^3.1.0"`)
+}
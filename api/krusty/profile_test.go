@@ -0,0 +1,95 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestProfile(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- deployment.yaml
+configMapGenerator:
+- name: config
+  literals:
+  - a=b
+`)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+namePrefix: prod-
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Profile = krusty.NewProfile()
+	m := th.Run("overlay", opts)
+
+	// the output doesn't keep the origin annotations used by the profile
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-app
+---
+apiVersion: v1
+data:
+  a: b
+kind: ConfigMap
+metadata:
+  name: prod-config-4h2mbtbbt6
+`)
+
+	type step struct {
+		kind, name, root string
+		resources        int
+	}
+	var steps []step
+	for _, e := range opts.Profile.Entries() {
+		if e.Kind == "transformer" && e.Resources == 0 {
+			t.Errorf("transformer %s has no resources", e.Name)
+		}
+		if e.Kind != "transformer" {
+			steps = append(steps, step{string(e.Kind), e.Name, e.Root, e.Resources})
+		}
+	}
+	assert.Equal(t, []step{
+		{"accumulation", "resources", "/base", 1},
+		{"generator", "ConfigMapGenerator", "/base", 1},
+		{"accumulation", "resources", "/overlay", 2},
+		{"build", "overlay", "/overlay", 2},
+	}, steps)
+
+	var transformers []string
+	for _, e := range opts.Profile.Entries() {
+		if e.Kind == "transformer" && e.Root == "/overlay" {
+			transformers = append(transformers, e.Name)
+		}
+	}
+	assert.Contains(t, transformers, "PrefixTransformer")
+
+	var text bytes.Buffer
+	require.NoError(t, opts.Profile.WriteText(&text))
+	assert.Contains(t, text.String(), "KIND")
+	assert.Contains(t, text.String(), "ConfigMapGenerator")
+
+	var b bytes.Buffer
+	require.NoError(t, opts.Profile.WriteJSON(&b))
+	var entries []krusty.ProfileEntry
+	require.NoError(t, json.Unmarshal(b.Bytes(), &entries))
+	assert.Equal(t, opts.Profile.Entries(), entries)
+}
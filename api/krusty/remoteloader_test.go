@@ -31,6 +31,8 @@ func TestRemoteLoad_LocalProtocol(t *testing.T) {
 		hash          string
 		multiBaseDev  string
 		withSubmodule string
+		// the commit of the main branch of multibase.git
+		multiBaseCommit string
 	}
 
 	// creates git repos under a root temporary directory with the following structure
@@ -108,14 +110,17 @@ cp -r $ROOT/simple.git/. $ROOT/$HASH_DIR
 	git commit -m "submodule"	
 )
 `, root, hashDir))
+		out, err := exec.Command("git", "-C", filepath.Join(root, "multibase.git"), "rev-parse", "main").Output()
+		require.NoError(t, err)
 		return testRepos{
 			root: root,
 			// The strings below aren't currently used, and more serve as documentation.
-			simple:        "simple.git",
-			noSuffix:      "nosuffix",
-			hash:          hashDir,
-			multiBaseDev:  "multibase.git",
-			withSubmodule: "with-submodule.git",
+			simple:          "simple.git",
+			noSuffix:        "nosuffix",
+			hash:            hashDir,
+			multiBaseDev:    "multibase.git",
+			withSubmodule:   "with-submodule.git",
+			multiBaseCommit: strings.TrimSpace(string(out)),
 		}
 	}
 
@@ -173,6 +178,30 @@ resources:
 `,
 			expected: multibaseDevExampleBuild,
 		},
+		{
+			name: "has sparse path",
+			kustomization: `
+resources:
+- file://$ROOT/multibase.git/dev?sparsePath=base
+`,
+			expected: multibaseDevExampleBuild,
+		},
+		{
+			name: "sparse without the path of the base",
+			kustomization: `
+resources:
+- file://$ROOT/multibase.git/dev?sparse=true
+`,
+			err: "must build at directory",
+		},
+		{
+			name: "has submodule and sparse path",
+			kustomization: `
+resources:
+- file://$ROOT/with-submodule.git/submodule?sparse=true
+`,
+			expected: simpleBuild,
+		},
 		{
 			name: "has ref",
 			kustomization: `
@@ -263,6 +292,7 @@ metadata:
       path: base/pod.yaml
       repo: file://$ROOT/multibase.git
       ref: main
+      commit: $COMMIT
   labels:
     app: myapp
   name: dev-myapp-pod
@@ -301,7 +331,8 @@ resources:
 				require.Regexp(t, test.err, err.Error())
 			} else {
 				require.NoError(t, err)
-				checkYaml(t, m, strings.ReplaceAll(test.expected, "$ROOT", repos.root))
+				expected := strings.ReplaceAll(test.expected, "$ROOT", repos.root)
+				checkYaml(t, m, strings.ReplaceAll(expected, "$COMMIT", repos.multiBaseCommit))
 			}
 		})
 	}
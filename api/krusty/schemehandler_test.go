@@ -0,0 +1,113 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// artifactoryHandler "fetches" the packages of
+// artifactory://repo/name?version=<version> references by copying them from
+// their directories in /artifactory.
+type artifactoryHandler struct {
+	fetched []string
+}
+
+func (h *artifactoryHandler) Fetch(ref string, fSys filesys.FileSystem) (filesys.ConfirmedDir, error) {
+	h.fetched = append(h.fetched, ref)
+	pkg := strings.Replace(strings.TrimPrefix(ref, "artifactory://"), "?version=", "/", 1)
+	files, err := fSys.Glob("/artifactory/" + pkg + "/*")
+	if err != nil || len(files) == 0 {
+		return "", fmt.Errorf("package %s not found", pkg)
+	}
+	dir := filesys.ConfirmedDir(fmt.Sprintf("/fetched/%d", len(h.fetched)))
+	for _, f := range files {
+		content, err := fSys.ReadFile(f)
+		if err != nil {
+			return "", err
+		}
+		if err = fSys.WriteFile(dir.Join(f[strings.LastIndex(f, "/")+1:]), content); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+func (h *artifactoryHandler) IsPinned(ref string) bool {
+	return strings.Contains(ref, "?version=")
+}
+
+func TestSchemeHandlers(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("/artifactory/repo/base/1.0.0", `
+resources:
+- configmap.yaml
+- artifactory://repo/common?version=2.0.0
+`)
+	th.WriteF("/artifactory/repo/base/1.0.0/configmap.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: base
+`)
+	th.WriteK("/artifactory/repo/common/2.0.0", `
+resources:
+- secret.yaml
+`)
+	th.WriteF("/artifactory/repo/common/2.0.0/secret.yaml", `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: common
+`)
+	th.WriteK("/app", `
+namePrefix: prod-
+resources:
+- artifactory://repo/base?version=1.0.0
+`)
+	handler := &artifactoryHandler{}
+	opts := th.MakeDefaultOptions()
+	opts.SchemeHandlers = map[string]krusty.SchemeHandler{"artifactory": handler}
+	m := th.Run("/app", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: prod-base
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: prod-common
+`)
+	assert.Equal(t, []string{"artifactory://repo/base?version=1.0.0", "artifactory://repo/common?version=2.0.0"}, handler.fetched)
+	// the fetched directories are cleaned up
+	assert.False(t, th.GetFSys().Exists("/fetched/1"))
+
+	th.WriteK("/app", `
+resources:
+- artifactory://repo/base
+`)
+	opts.RequirePinnedRemotes = true
+	err := th.RunWithErr("/app", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `not pinned: "artifactory://repo/base" isn't pinned by the handler of its scheme`)
+
+	opts.RequirePinnedRemotes = false
+	err = th.RunWithErr("/app", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `fetching "artifactory://repo/base": package repo/base not found`)
+
+	opts.SchemeHandlers = map[string]krusty.SchemeHandler{"https": handler}
+	err = th.RunWithErr("/app", opts)
+	assert.EqualError(t, err, `scheme "https" can't be handled by a scheme handler`)
+}
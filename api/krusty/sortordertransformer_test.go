@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/api/krusty"
 	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+	"sigs.k8s.io/kustomize/kyaml/resid"
 )
 
 //nolint:gochecknoglobals
@@ -283,6 +284,119 @@ resources:
 	th.AssertActualEqualsExpected(th.Run("base", kustOptions), legacyOrderResources)
 }
 
+func TestCLICustomOrdering(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("base", `
+resources:
+- resources.yaml
+`)
+	th.WriteF("base/resources.yaml", sortOrderResources)
+	kustOptions := th.MakeDefaultOptions()
+	kustOptions.Reorder = krusty.ReorderOptionCustom
+	kustOptions.CustomOrder = []resid.Gvk{
+		{Kind: "Namespace"},
+		{Version: "v1", Kind: "ConfigMap"},
+		{Kind: krusty.CustomOrderWildcard},
+		{Kind: "ValidatingWebhookConfiguration"},
+		{Kind: "Service"},
+	}
+	th.AssertActualEqualsExpected(th.Run("base", kustOptions), `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: apricot
+---
+apiVersion: v1
+kind: Role
+metadata:
+  name: banana
+---
+apiVersion: v1
+kind: LimitRange
+metadata:
+  name: peach
+---
+apiVersion: v1
+kind: Deployment
+metadata:
+  name: pear
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quince
+---
+apiVersion: v1
+kind: Ingress
+metadata:
+  name: durian
+---
+apiVersion: v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: pomegranate
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: papaya
+`)
+
+	// without a wildcard entry, the other resources are last
+	kustOptions.CustomOrder = []resid.Gvk{{Kind: "Ingress"}}
+	th.AssertActualEqualsExpected(th.Run("base", kustOptions), `
+apiVersion: v1
+kind: Ingress
+metadata:
+  name: durian
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: papaya
+---
+apiVersion: v1
+kind: Role
+metadata:
+  name: banana
+---
+apiVersion: v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: pomegranate
+---
+apiVersion: v1
+kind: LimitRange
+metadata:
+  name: peach
+---
+apiVersion: v1
+kind: Deployment
+metadata:
+  name: pear
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apple
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: quince
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: apricot
+`)
+}
+
 func TestChildKustomizationSortOrder(t *testing.T) {
 	th := kusttest_test.MakeHarness(t)
 	th.WriteK("base", `
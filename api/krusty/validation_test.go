@@ -0,0 +1,135 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func writeValidationBase(th kusttest_test.Harness) {
+	th.WriteK("base", `
+resources:
+- deployment.yaml
+`)
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: "3"
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app
+        imagePullPolicy: true
+        resources:
+          limits:
+            cpu: 1
+            memory: 1Gi
+        ports:
+        - containerPort: 80
+          unknownField: x
+`)
+	th.WriteK("overlay", `
+resources:
+- ../base
+- widget.yaml
+`)
+	th.WriteF("overlay/widget.yaml", `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+spec:
+  size: large
+`)
+}
+
+func TestValidation(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeValidationBase(th)
+	opts := th.MakeDefaultOptions()
+	opts.Validation = krusty.ValidationOptionEnabled
+	err := th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Equal(t, `validation failed:
+  ../base/deployment.yaml: Deployment.v1.apps/app.[noNs]: spec.replicas: expected integer, got string
+  ../base/deployment.yaml: Deployment.v1.apps/app.[noNs]: spec.template.spec.containers[0].imagePullPolicy: expected string, got boolean`,
+		err.Error())
+
+	// the output doesn't keep the origin annotations used by the validation
+	th.WriteF("base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+`)
+	m := th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+spec:
+  size: large
+`)
+}
+
+func TestValidation_strict(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	writeValidationBase(th)
+	th.WriteF("crds.yaml", `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+`)
+	opts := th.MakeDefaultOptions()
+	opts.Validation = krusty.ValidationOptionStrict
+	err := th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Equal(t, `validation failed:
+  ../base/deployment.yaml: Deployment.v1.apps/app.[noNs]: spec.replicas: expected integer, got string
+  ../base/deployment.yaml: Deployment.v1.apps/app.[noNs]: spec.template.spec.containers[0].imagePullPolicy: expected string, got boolean
+  ../base/deployment.yaml: Deployment.v1.apps/app.[noNs]: spec.template.spec.containers[0].ports[0].unknownField: unknown field
+  widget.yaml: Widget.v1.example.com/widget.[noNs]: no schema found for example.com/v1 Widget`,
+		err.Error())
+
+	opts.ValidationSchemaPaths = []string{"crds.yaml"}
+	err = th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"widget.yaml: Widget.v1.example.com/widget.[noNs]: spec.size: expected integer, got string")
+}
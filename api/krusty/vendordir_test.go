@@ -0,0 +1,111 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package krusty_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestVendorDir(t *testing.T) {
+	th := kusttest_test.MakeHarness(t)
+	th.WriteK("vendor/github.com/org/repo/v1.0.0/base", `
+resources:
+- deployment.yaml
+- https://github.com/org/other//app?ref=v2
+`)
+	th.WriteF("vendor/github.com/org/repo/v1.0.0/base/deployment.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: base
+`)
+	th.WriteF("vendor/github.com/org/other/v2/app/service.yaml", `
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+`)
+	th.WriteK("vendor/github.com/org/other/v2/app", `
+resources:
+- service.yaml
+`)
+	th.WriteF("vendor/raw.githubusercontent.com/org/repo/v1.0.0/cm.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)
+	th.WriteK("overlay", `
+namePrefix: prod-
+resources:
+- https://github.com/org/repo//base?ref=v1.0.0
+- https://raw.githubusercontent.com/org/repo/v1.0.0/cm.yaml
+`)
+	opts := th.MakeDefaultOptions()
+	opts.VendorDir = "/vendor"
+	m := th.Run("overlay", opts)
+	th.AssertActualEqualsExpected(m, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-base
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: prod-app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: prod-cm
+`)
+
+	th.WriteK("overlay", `
+resources:
+- https://github.com/org/repo//missing?ref=v1.0.0
+`)
+	err := th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `not vendored: remote reference "https://github.com/org/repo//missing?ref=v1.0.0" `+
+		`expected in "/vendor" at "/vendor/github.com/org/repo/v1.0.0/missing"`)
+
+	th.WriteK("overlay", `
+resources:
+- https://raw.githubusercontent.com/org/repo/v2/cm.yaml
+`)
+	err = th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `not vendored: remote reference "https://raw.githubusercontent.com/org/repo/v2/cm.yaml" `+
+		`expected in "/vendor" at "/vendor/raw.githubusercontent.com/org/repo/v2/cm.yaml"`)
+
+	th.WriteK("overlay", `
+resources:
+- https://github.com/org/repo//base
+`)
+	err = th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `remote reference "https://github.com/org/repo//base" `+
+		`missing ref query string parameter, which is required to vendor it`)
+
+	// refs can't escape the vendor directory
+	for _, ref := range []string{"../../../../overlay", "v1/../../../../../overlay"} {
+		th.WriteK("overlay", `
+resources:
+- https://github.com/org/repo?ref=`+ref+`
+`)
+		err = th.RunWithErr("overlay", opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid ref "`+ref+`": must not have empty, . or .. segments`)
+	}
+
+	opts.VendorDir = "/missing"
+	err = th.RunWithErr("overlay", opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid vendor directory")
+}
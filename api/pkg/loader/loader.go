@@ -5,6 +5,7 @@
 package loader
 
 import (
+	"sigs.k8s.io/kustomize/api/internal/git"
 	"sigs.k8s.io/kustomize/api/internal/loader"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
@@ -22,3 +23,15 @@ func NewFileLoaderAtRoot(fSys filesys.FileSystem) *loader.FileLoader {
 	return loader.NewLoaderOrDie(
 		loader.RestrictionRootOnly, fSys, filesys.Separator)
 }
+
+// RepoURL returns the url of the git repository of a remote
+// kustomization url, suitable for "git clone", and the ref of the url.
+// It fails if url isn't a git url.
+// A convenience for commands updating remote refs.
+func RepoURL(url string) (repo string, ref string, err error) {
+	repoSpec, err := git.NewRepoSpecFromURL(url)
+	if err != nil {
+		return "", "", err
+	}
+	return repoSpec.CloneSpec(), repoSpec.Ref, nil
+}
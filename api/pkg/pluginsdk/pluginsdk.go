@@ -0,0 +1,146 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pluginsdk lets a transformer be written once, and run both
+// linked into a program using the api, as a builtin of its builds, and
+// as a standalone KRM function.
+//
+// A transformer is written against resmap, as a resmap.TransformerPlugin
+// like the builtins, or against kyaml, as a Transformer.  The Plugin
+// naming it by the GVK of its config is then registered in the
+// ExtraBuiltins of krusty.Options, and run as the main of a KRM function
+// binary:
+//
+//	var SetTeam = pluginsdk.ForKYAML(
+//		"example.com/v1", "SetTeam", func() pluginsdk.Transformer { return &setTeam{} })
+//
+//	func main() {
+//		if err := SetTeam.Run(); err != nil {
+//			os.Exit(1)
+//		}
+//	}
+//
+// The pluginsdktest package tests that a transformer has the same output
+// in both ways.
+package pluginsdk
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/kustomize/api/pkg/loader"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Transformer is a transformer written against kyaml.
+type Transformer interface {
+	// Config configures the transformer with the YAML of its config.
+	Config(config []byte) error
+
+	// Transform returns the transformed resources.  It can modify, add
+	// and remove resources.
+	Transform(nodes []*yaml.RNode) ([]*yaml.RNode, error)
+}
+
+// Plugin is a transformer, named by the GVK of its config.
+type Plugin struct {
+	// APIVersion and Kind are the apiVersion and kind of the config of
+	// the transformer.
+	APIVersion string
+	Kind       string
+
+	// New returns a new, unconfigured instance of the transformer.
+	New func() resmap.TransformerPlugin
+}
+
+// ForKYAML returns the Plugin of a transformer written against kyaml.
+func ForKYAML(apiVersion, kind string, newTransformer func() Transformer) Plugin {
+	return Plugin{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		New: func() resmap.TransformerPlugin {
+			return &kyamlPlugin{t: newTransformer()}
+		},
+	}
+}
+
+// kyamlPlugin adapts a Transformer to resmap.TransformerPlugin.
+type kyamlPlugin struct {
+	t Transformer
+}
+
+func (p *kyamlPlugin) Config(_ *resmap.PluginHelpers, config []byte) error {
+	return p.t.Config(config)
+}
+
+func (p *kyamlPlugin) Transform(m resmap.ResMap) error {
+	return m.ApplyFilter(kio.FilterFunc(p.t.Transform))
+}
+
+// Gvk returns the GVK of the config of the transformer.
+func (p Plugin) Gvk() resid.Gvk {
+	g, v := resid.ParseGroupVersion(p.APIVersion)
+	return resid.Gvk{Group: g, Version: v, Kind: p.Kind}
+}
+
+// Register adds the transformer to extraBuiltins, e.g. the ExtraBuiltins
+// of krusty.Options.
+func (p Plugin) Register(extraBuiltins map[resid.Gvk]func() resmap.Configurable) {
+	extraBuiltins[p.Gvk()] = func() resmap.Configurable { return p.New() }
+}
+
+// Process runs the transformer as a KRM function, configured by the
+// functionConfig of the ResourceList.  It makes Plugin a
+// framework.ResourceListProcessor, e.g. to build a command with the
+// kyaml/fn/framework/command package.
+func (p Plugin) Process(rl *framework.ResourceList) error {
+	if rl.FunctionConfig == nil {
+		return errors.Errorf("%s requires a functionConfig", p.Kind)
+	}
+	if apiVersion, kind := rl.FunctionConfig.GetApiVersion(), rl.FunctionConfig.GetKind(); apiVersion != p.APIVersion || kind != p.Kind {
+		return fmt.Errorf("expected a functionConfig of apiVersion %s and kind %s, got %s %s",
+			p.APIVersion, p.Kind, apiVersion, kind)
+	}
+	config, err := rl.FunctionConfig.String()
+	if err != nil {
+		return errors.WrapPrefixf(err, "marshalling the functionConfig")
+	}
+	depProvider := provider.NewDefaultDepProvider()
+	rf := resmap.NewFactory(depProvider.GetResourceFactory())
+	m, err := rf.NewResMapFromRNodeSlice(rl.Items)
+	if err != nil {
+		return err
+	}
+	t := p.New()
+	// KRM functions load the files their config references from their
+	// working directory
+	h := resmap.NewPluginHelpers(
+		loader.NewFileLoaderAtCwd(filesys.MakeFsOnDisk()),
+		depProvider.GetFieldValidator(), rf, types.DisabledPluginConfig())
+	if err := t.Config(h, []byte(config)); err != nil {
+		return errors.WrapPrefixf(err, "plugin %s fails configuration", p.Kind)
+	}
+	if err := t.Transform(m); err != nil {
+		return err
+	}
+	rl.Items = m.ToRNodeSlice()
+	return nil
+}
+
+// Run runs the transformer as a KRM function, reading the ResourceList
+// from stdin and writing it to stdout, and prints its error to stderr.
+func (p Plugin) Run() error {
+	err := framework.Execute(p, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return err
+}
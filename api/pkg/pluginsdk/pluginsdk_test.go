@@ -0,0 +1,157 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginsdk_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/pkg/pluginsdk"
+	"sigs.k8s.io/kustomize/api/pkg/pluginsdk/pluginsdktest"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+type teamConfig struct {
+	Team string `json:"team,omitempty" yaml:"team,omitempty"`
+}
+
+// setTeam labels the resources with a team, against kyaml.
+type setTeam struct {
+	teamConfig
+}
+
+func (p *setTeam) Config(config []byte) error {
+	if err := sigsyaml.Unmarshal(config, &p.teamConfig); err != nil {
+		return err
+	}
+	if p.Team == "" {
+		return errors.Errorf("team is required")
+	}
+	return nil
+}
+
+func (p *setTeam) Transform(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	for _, n := range nodes {
+		if err := n.PipeE(yaml.SetLabel("team", p.Team)); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// dropTeam removes the resources of a team, against resmap.
+type dropTeam struct {
+	teamConfig
+}
+
+func (p *dropTeam) Config(_ *resmap.PluginHelpers, config []byte) error {
+	return sigsyaml.Unmarshal(config, &p.teamConfig)
+}
+
+func (p *dropTeam) Transform(m resmap.ResMap) error {
+	for _, r := range m.Resources() {
+		if r.GetLabels()["team"] == p.Team {
+			if err := m.Remove(r.CurId()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	setTeamPlugin = pluginsdk.ForKYAML("example.com/v1", "SetTeam",
+		func() pluginsdk.Transformer { return &setTeam{} })
+	dropTeamPlugin = pluginsdk.Plugin{
+		APIVersion: "example.com/v1",
+		Kind:       "DropTeam",
+		New:        func() resmap.TransformerPlugin { return &dropTeam{} },
+	}
+)
+
+const teamInput = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: db
+  labels:
+    team: data
+`
+
+func TestForKYAML(t *testing.T) {
+	pluginsdktest.AssertTransform(t, setTeamPlugin, `
+apiVersion: example.com/v1
+kind: SetTeam
+metadata:
+  name: set-team
+team: platform
+`, teamInput, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    team: platform
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    team: platform
+  name: db
+`)
+	pluginsdktest.AssertError(t, setTeamPlugin, `
+apiVersion: example.com/v1
+kind: SetTeam
+metadata:
+  name: set-team
+`, teamInput, "team is required")
+}
+
+func TestPlugin(t *testing.T) {
+	pluginsdktest.AssertTransform(t, dropTeamPlugin, `
+apiVersion: example.com/v1
+kind: DropTeam
+metadata:
+  name: drop-team
+team: data
+`, teamInput, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+`)
+}
+
+func TestPlugin_Register(t *testing.T) {
+	extraBuiltins := map[resid.Gvk]func() resmap.Configurable{}
+	setTeamPlugin.Register(extraBuiltins)
+	f, ok := extraBuiltins[resid.Gvk{Group: "example.com", Version: "v1", Kind: "SetTeam"}]
+	require.True(t, ok)
+	assert.Implements(t, (*resmap.TransformerPlugin)(nil), f())
+}
+
+func TestPlugin_Process(t *testing.T) {
+	rl := &framework.ResourceList{FunctionConfig: yaml.MustParse(`
+apiVersion: example.com/v1
+kind: DropTeam
+metadata:
+  name: drop-team
+`)}
+	assert.EqualError(t, setTeamPlugin.Process(rl),
+		"expected a functionConfig of apiVersion example.com/v1 and kind SetTeam, got example.com/v1 DropTeam")
+
+	assert.EqualError(t, setTeamPlugin.Process(&framework.ResourceList{}), "SetTeam requires a functionConfig")
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pluginsdktest tests the transformers written with pluginsdk,
+// running each test case both as a builtin and as a KRM function.
+package pluginsdktest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/pkg/pluginsdk"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+)
+
+// RunBuiltin returns the resources of input transformed by p, configured
+// by config, run as a builtin of a kustomize build.
+func RunBuiltin(t *testing.T, p pluginsdk.Plugin, config, input string) (string, error) {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("/app/kustomization.yaml", []byte(`
+resources:
+- input.yaml
+transformers:
+- config.yaml
+`)))
+	require.NoError(t, fSys.WriteFile("/app/input.yaml", []byte(input)))
+	require.NoError(t, fSys.WriteFile("/app/config.yaml", []byte(config)))
+	o := krusty.MakeDefaultOptions()
+	o.ExtraBuiltins = map[resid.Gvk]func() resmap.Configurable{}
+	p.Register(o.ExtraBuiltins)
+	m, err := krusty.MakeKustomizer(o).Run(fSys, "/app")
+	if err != nil {
+		return "", err
+	}
+	yml, err := m.AsYaml()
+	require.NoError(t, err)
+	return string(yml), nil
+}
+
+// RunFunction returns the resources of input transformed by p, configured
+// by config, run as a KRM function.
+func RunFunction(t *testing.T, p pluginsdk.Plugin, config, input string) (string, error) {
+	t.Helper()
+	functionConfig, err := kio.FromBytes([]byte(config))
+	require.NoError(t, err)
+	require.Len(t, functionConfig, 1, "config must be a single object")
+	items, err := kio.FromBytes([]byte(input))
+	require.NoError(t, err)
+	rl := &framework.ResourceList{Items: items, FunctionConfig: functionConfig[0]}
+	if err := p.Process(rl); err != nil {
+		return "", err
+	}
+	// format the output like the builds do
+	m, err := resmap.NewFactory(provider.NewDefaultDepProvider().GetResourceFactory()).
+		NewResMapFromRNodeSlice(rl.Items)
+	require.NoError(t, err)
+	yml, err := m.AsYaml()
+	require.NoError(t, err)
+	return string(yml), nil
+}
+
+// AssertTransform asserts that p, configured by config, transforms input
+// into expected both as a builtin and as a KRM function.
+func AssertTransform(t *testing.T, p pluginsdk.Plugin, config, input, expected string) {
+	t.Helper()
+	actual, err := RunBuiltin(t, p, config, input)
+	if assert.NoError(t, err, "builtin") {
+		assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(actual), "builtin")
+	}
+	actual, err = RunFunction(t, p, config, input)
+	if assert.NoError(t, err, "KRM function") {
+		assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(actual), "KRM function")
+	}
+}
+
+// AssertError asserts that p, configured by config, fails to transform
+// input with an error containing expected both as a builtin and as a KRM
+// function.
+func AssertError(t *testing.T, p pluginsdk.Plugin, config, input, expected string) {
+	t.Helper()
+	_, err := RunBuiltin(t, p, config, input)
+	assert.ErrorContains(t, err, expected, "builtin")
+	_, err = RunFunction(t, p, config, input)
+	assert.ErrorContains(t, err, expected, "KRM function")
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/internal/git"
+	"sigs.k8s.io/kustomize/api/internal/loader"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -27,6 +29,10 @@ type Origin struct {
 	// if it is not from a local file
 	Ref string `json:"ref,omitempty" yaml:"ref,omitempty"`
 
+	// Commit is the hash of the commit of the remote git repository that the resource or
+	// transformer originated from, which Ref resolved to when it was cloned
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+
 	// The following fields only apply to resources that have been
 	// generated by fields other than the `resources` field, or to transformer
 	// configs.
@@ -50,6 +56,15 @@ func (origin *Origin) Copy() Origin {
 // Append returns a copy of origin with a path appended to it
 func (origin *Origin) Append(path string) *Origin {
 	originCopy := origin.Copy()
+	if kio.IsOCIReference(path) || loader.IsRemoteArchive(path) || loader.IsBucketReference(path) {
+		// the artifact, archive or bucket directory is the repo,
+		// its package is rooted at its root
+		originCopy.Repo = path
+		originCopy.Path = ""
+		originCopy.Ref = ""
+		originCopy.Commit = ""
+		return &originCopy
+	}
 	repoSpec, err := git.NewRepoSpecFromURL(path)
 	if err == nil {
 		originCopy.Repo = repoSpec.CloneSpec()
@@ -57,11 +72,30 @@ func (origin *Origin) Append(path string) *Origin {
 		path = absPath[strings.Index(absPath[1:], "/")+1:][1:]
 		originCopy.Path = ""
 		originCopy.Ref = repoSpec.Ref
+		originCopy.Commit = ""
+	} else if strings.Contains(path, "://") {
+		// the references with custom url schemes are loaded like
+		// packages, rooted at their root
+		originCopy.Repo = path
+		originCopy.Path = ""
+		originCopy.Ref = ""
+		originCopy.Commit = ""
+		return &originCopy
 	}
 	originCopy.Path = filepath.Join(originCopy.Path, path)
 	return &originCopy
 }
 
+// WithCommit returns a copy of origin with the commit of its repo set to
+// commit, if it's known.
+func (origin *Origin) WithCommit(commit string) *Origin {
+	originCopy := origin.Copy()
+	if commit != "" {
+		originCopy.Commit = commit
+	}
+	return &originCopy
+}
+
 // String returns a string version of origin
 func (origin *Origin) String() (string, error) {
 	anno, err := kyaml.Marshal(origin)
@@ -89,6 +123,7 @@ func OriginFromCustomPlugin(res *Resource) (*Origin, error) {
 		result = &Origin{
 			Repo:         origin.Repo,
 			Ref:          origin.Ref,
+			Commit:       origin.Commit,
 			ConfiguredIn: origin.Path,
 			ConfiguredBy: kyaml.ResourceIdentifier{
 				TypeMeta: kyaml.TypeMeta{
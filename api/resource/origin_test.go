@@ -4,6 +4,7 @@
 package resource_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,6 +33,65 @@ func TestOriginAppend(t *testing.T) {
 			path: "github.com/kubernetes-sigs/kustomize/examples/multibases/dev/",
 			expected: `path: examples/multibases/dev
 repo: https://github.com/kubernetes-sigs/kustomize
+`,
+		},
+		{
+			in: &Origin{
+				Path:   "examples/multibases/dev",
+				Repo:   "https://github.com/kubernetes-sigs/kustomize",
+				Ref:    "v1.0.6",
+				Commit: "a428de44a9059f31a59237a5881c2d2cffa93757",
+			},
+			path: "service.yaml",
+			expected: `path: examples/multibases/dev/service.yaml
+repo: https://github.com/kubernetes-sigs/kustomize
+ref: v1.0.6
+commit: a428de44a9059f31a59237a5881c2d2cffa93757
+`,
+		},
+		{
+			in: &Origin{
+				Path:   "examples/multibases/dev",
+				Repo:   "https://github.com/kubernetes-sigs/kustomize",
+				Ref:    "v1.0.6",
+				Commit: "a428de44a9059f31a59237a5881c2d2cffa93757",
+			},
+			path: "github.com/org/repo/base?ref=main",
+			expected: `path: base
+repo: https://github.com/org/repo
+ref: main
+`,
+		},
+		{
+			in: &Origin{
+				Path: "overlay/prod",
+			},
+			path: "oci://registry.example.com/platform/base:v1.2.3",
+			expected: `repo: oci://registry.example.com/platform/base:v1.2.3
+`,
+		},
+		{
+			in: &Origin{
+				Path: "overlay/prod",
+			},
+			path: "https://github.com/org/repo/releases/download/v1.0.0/config.tar.gz?sha256=" + strings.Repeat("a", 64),
+			expected: `repo: https://github.com/org/repo/releases/download/v1.0.0/config.tar.gz?sha256=` + strings.Repeat("a", 64) + `
+`,
+		},
+		{
+			in: &Origin{
+				Path: "overlay/prod",
+			},
+			path: "s3://config/platform/base",
+			expected: `repo: s3://config/platform/base
+`,
+		},
+		{
+			in: &Origin{
+				Path: "overlay/prod",
+			},
+			path: "artifactory://repo/platform/base",
+			expected: `repo: artifactory://repo/platform/base
 `,
 		},
 	}
@@ -42,6 +102,24 @@ repo: https://github.com/kubernetes-sigs/kustomize
 	}
 }
 
+func TestOriginWithCommit(t *testing.T) {
+	origin := &Origin{
+		Repo: "https://github.com/org/repo",
+		Ref:  "main",
+	}
+	actual, err := origin.WithCommit("a428de44a9059f31a59237a5881c2d2cffa93757").String()
+	assert.NoError(t, err)
+	assert.Equal(t, `repo: https://github.com/org/repo
+ref: main
+commit: a428de44a9059f31a59237a5881c2d2cffa93757
+`, actual)
+	assert.Empty(t, origin.Commit)
+
+	// an unknown commit keeps that of the origin
+	assert.Equal(t, "a428de44a9059f31a59237a5881c2d2cffa93757",
+		origin.WithCommit("a428de44a9059f31a59237a5881c2d2cffa93757").WithCommit("").Commit)
+}
+
 func TestOriginString(t *testing.T) {
 	tests := []struct {
 		in       *Origin
@@ -0,0 +1,122 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	CatalogVersion = "config.kubernetes.io/v1alpha1"
+	CatalogKind    = "Catalog"
+)
+
+// Catalog lists KRM functions by the group, versions and kind of their
+// configuration, so that a function configuration without a function
+// annotation can be resolved to the function approved for it.
+// See KEP-2906.
+type Catalog struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	MetaData *ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	Spec CatalogSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type CatalogSpec struct {
+	KrmFunctions []KrmFunctionDefinition `json:"krmFunctions,omitempty" yaml:"krmFunctions,omitempty"`
+}
+
+// KrmFunctionDefinition is a function of a Catalog.
+type KrmFunctionDefinition struct {
+	// Group is the API group of the function configuration.
+	Group string `json:"group" yaml:"group"`
+
+	// Names holds the kind of the function configuration.
+	Names KrmFunctionNames `json:"names" yaml:"names"`
+
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	Publisher string `json:"publisher,omitempty" yaml:"publisher,omitempty"`
+
+	// Versions are the API versions of the function configuration, each
+	// with the runtime of the function configured by it.
+	Versions []KrmFunctionVersion `json:"versions" yaml:"versions"`
+}
+
+type KrmFunctionNames struct {
+	Kind string `json:"kind" yaml:"kind"`
+}
+
+type KrmFunctionVersion struct {
+	// Name is the API version, e.g. v1.
+	Name string `json:"name" yaml:"name"`
+
+	Runtime KrmFunctionRuntime `json:"runtime" yaml:"runtime"`
+}
+
+// KrmFunctionRuntime is how a function runs.  Only containers are supported.
+type KrmFunctionRuntime struct {
+	Container *KrmFunctionContainer `json:"container,omitempty" yaml:"container,omitempty"`
+}
+
+type KrmFunctionContainer struct {
+	// Image is the image of the function.
+	Image string `json:"image" yaml:"image"`
+
+	// Sha256 is the hex-encoded sha256 digest the image is pinned to.
+	Sha256 string `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+
+	// RequireNetwork is true if the function requires network access.
+	RequireNetwork bool `json:"requireNetwork,omitempty" yaml:"requireNetwork,omitempty"`
+}
+
+// PinnedImage returns the image of c, pinned to its digest if it has one.
+func (c *KrmFunctionContainer) PinnedImage() string {
+	if c.Sha256 == "" {
+		return c.Image
+	}
+	return c.Image + "@sha256:" + c.Sha256
+}
+
+// ParseCatalog parses the Catalog in data.
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var c Catalog
+	if err := yaml.UnmarshalStrict(data, &c); err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid catalog")
+	}
+	if c.APIVersion != CatalogVersion || c.Kind != CatalogKind {
+		return nil, fmt.Errorf("invalid catalog: expected apiVersion %s and kind %s, got %s %s",
+			CatalogVersion, CatalogKind, c.APIVersion, c.Kind)
+	}
+	for _, f := range c.Spec.KrmFunctions {
+		for _, v := range f.Versions {
+			if v.Runtime.Container == nil || v.Runtime.Container.Image == "" {
+				return nil, fmt.Errorf("invalid catalog: function %s/%s, kind %s has no container image",
+					f.Group, v.Name, f.Names.Kind)
+			}
+		}
+	}
+	return &c, nil
+}
+
+// Function returns the definition and version of the function of c
+// configured by the kind of the group and version, or nil if c has none.
+func (c *Catalog) Function(group, version, kind string) (*KrmFunctionDefinition, *KrmFunctionVersion) {
+	for i := range c.Spec.KrmFunctions {
+		f := &c.Spec.KrmFunctions[i]
+		if f.Group != group || f.Names.Kind != kind {
+			continue
+		}
+		for j := range f.Versions {
+			if f.Versions[j].Name == version {
+				return f, &f.Versions[j]
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCatalog(t *testing.T) {
+	c, err := ParseCatalog([]byte(`
+apiVersion: config.kubernetes.io/v1alpha1
+kind: Catalog
+metadata:
+  name: example-co-functions
+spec:
+  krmFunctions:
+  - group: example.co
+    names:
+      kind: SetTeam
+    description: Sets the team label
+    versions:
+    - name: v1
+      runtime:
+        container:
+          image: docker.example.co/functions/set-team:v1.0.0
+          sha256: a428de44a9059f31a59237a5881c2d2cffa93757d99026156e4ea544577ab7f3
+    - name: v2
+      runtime:
+        container:
+          image: docker.example.co/functions/set-team:v2.0.0
+          requireNetwork: true
+`))
+	require.NoError(t, err)
+
+	f, v := c.Function("example.co", "v1", "SetTeam")
+	require.NotNil(t, v)
+	assert.Equal(t, "Sets the team label", f.Description)
+	assert.Equal(t, "docker.example.co/functions/set-team:v1.0.0@sha256:"+
+		"a428de44a9059f31a59237a5881c2d2cffa93757d99026156e4ea544577ab7f3", v.Runtime.Container.PinnedImage())
+	_, v = c.Function("example.co", "v2", "SetTeam")
+	require.NotNil(t, v)
+	assert.Equal(t, "docker.example.co/functions/set-team:v2.0.0", v.Runtime.Container.PinnedImage())
+	assert.True(t, v.Runtime.Container.RequireNetwork)
+	_, v = c.Function("example.co", "v3", "SetTeam")
+	assert.Nil(t, v)
+	_, v = c.Function("other.co", "v1", "SetTeam")
+	assert.Nil(t, v)
+}
+
+func TestParseCatalogErrors(t *testing.T) {
+	for content, expected := range map[string]string{
+		`
+apiVersion: v1
+kind: ConfigMap
+`: "invalid catalog: expected apiVersion config.kubernetes.io/v1alpha1 and kind Catalog, got v1 ConfigMap",
+		`
+apiVersion: config.kubernetes.io/v1alpha1
+kind: Catalog
+spec:
+  krmFunctions:
+  - group: example.co
+    names:
+      kind: SetTeam
+    versions:
+    - name: v1
+      runtime:
+        exec:
+          path: set-team
+`: "invalid catalog",
+		`
+apiVersion: config.kubernetes.io/v1alpha1
+kind: Catalog
+spec:
+  krmFunctions:
+  - group: example.co
+    names:
+      kind: SetTeam
+    versions:
+    - name: v1
+      runtime: {}
+`: "invalid catalog: function example.co/v1, kind SetTeam has no container image",
+	} {
+		_, err := ParseCatalog([]byte(content))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), expected)
+		}
+	}
+}
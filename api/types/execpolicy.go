@@ -0,0 +1,61 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	ExecPolicyVersion = "kustomize.config.k8s.io/v1alpha1"
+	ExecPolicyKind    = "ExecPolicy"
+)
+
+// ExecPolicy restricts the executables of exec functions and exec plugins
+// to those it allows, so that exec functions can be enabled without
+// running any executable referenced by the kustomizations.  It restricts
+// the binaries built for Go module functions, and both the runtimes and
+// the modules of wasm functions, too.
+type ExecPolicy struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	MetaData *ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// AllowedExecutables are the only executables allowed to run, matched
+	// by their absolute path and sha256 digest.
+	AllowedExecutables []exec.AllowedExecutable `json:"allowedExecutables" yaml:"allowedExecutables"`
+}
+
+var sha256Pattern = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
+// ParseExecPolicy parses the ExecPolicy in data.
+func ParseExecPolicy(data []byte) (*ExecPolicy, error) {
+	var p ExecPolicy
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid exec policy")
+	}
+	if p.APIVersion != ExecPolicyVersion || p.Kind != ExecPolicyKind {
+		return nil, fmt.Errorf("invalid exec policy: expected apiVersion %s and kind %s, got %s %s",
+			ExecPolicyVersion, ExecPolicyKind, p.APIVersion, p.Kind)
+	}
+	for _, e := range p.AllowedExecutables {
+		if !filepath.IsAbs(e.Path) {
+			return nil, fmt.Errorf("invalid exec policy: path %q of an allowed executable isn't absolute", e.Path)
+		}
+		if !sha256Pattern.MatchString(e.Sha256) {
+			return nil, fmt.Errorf("invalid exec policy: executable %s has no hex-encoded sha256 digest", e.Path)
+		}
+	}
+	// a policy allowing no executables still restricts them
+	if p.AllowedExecutables == nil {
+		p.AllowedExecutables = []exec.AllowedExecutable{}
+	}
+	return &p, nil
+}
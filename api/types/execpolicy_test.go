@@ -0,0 +1,44 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExecPolicy(t *testing.T) {
+	p, err := ParseExecPolicy([]byte(`
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: ExecPolicy
+allowedExecutables:
+- path: /usr/local/bin/set-team
+  sha256: a428de44a9059f31a59237a5881c2d2cffa93757d99026156e4ea544577ab7f3
+`))
+	require.NoError(t, err)
+	require.Len(t, p.AllowedExecutables, 1)
+	assert.Equal(t, "/usr/local/bin/set-team", p.AllowedExecutables[0].Path)
+
+	p, err = ParseExecPolicy([]byte(`
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: ExecPolicy
+`))
+	require.NoError(t, err)
+	assert.NotNil(t, p.AllowedExecutables)
+	assert.Empty(t, p.AllowedExecutables)
+
+	for data, expected := range map[string]string{
+		"apiVersion: kustomize.config.k8s.io/v1alpha1\nkind: ExecPolicy\nallowedExecutables:\n" +
+			"- path: bin/set-team\n  sha256: a428de44a9059f31a59237a5881c2d2cffa93757d99026156e4ea544577ab7f3\n": `invalid exec policy: path "bin/set-team" of an allowed executable isn't absolute`,
+		"apiVersion: kustomize.config.k8s.io/v1alpha1\nkind: ExecPolicy\nallowedExecutables:\n" +
+			"- path: /usr/local/bin/set-team\n": "invalid exec policy: executable /usr/local/bin/set-team has no hex-encoded sha256 digest",
+		"apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\n": "invalid exec policy: expected apiVersion " +
+			"kustomize.config.k8s.io/v1alpha1 and kind ExecPolicy, got kustomize.config.k8s.io/v1beta1 Kustomization",
+	} {
+		_, err := ParseExecPolicy([]byte(data))
+		assert.EqualError(t, err, expected)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	GitCredentialsVersion = "kustomize.config.k8s.io/v1alpha1"
+	GitCredentialsKind    = "GitCredentials"
+)
+
+// GitCredentials authenticate the clones of the git repositories of
+// remote bases, without relying on the credential helpers of the git
+// configuration, which e.g. CI containers often lack.
+type GitCredentials struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	MetaData *ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	// Credentials authenticate the repositories they match.  The first
+	// one matching a repository is used.
+	Credentials []GitCredential `json:"credentials" yaml:"credentials"`
+}
+
+// GitCredential authenticates the clones of the git repositories of a host,
+// by https with a token or by ssh with a private key.
+type GitCredential struct {
+	// Host matches the repositories of the host, e.g. github.com, or those
+	// below a path of the host, e.g. github.com/my-org.
+	Host string `json:"host" yaml:"host"`
+
+	// Username is the user of the token, defaults to x-access-token, which
+	// e.g. GitHub and GitLab accept for any token.
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+
+	// Token is the token or password authenticating https clones.  It's
+	// never sent to http remotes.  Prefer TokenEnv, to keep it out of files.
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+
+	// TokenEnv is the environment variable holding the token.
+	TokenEnv string `json:"tokenEnv,omitempty" yaml:"tokenEnv,omitempty"`
+
+	// SSHKeyPath is the path of the private key authenticating ssh clones.
+	SSHKeyPath string `json:"sshKeyPath,omitempty" yaml:"sshKeyPath,omitempty"`
+
+	// SSHKnownHostsPath is the path of the known hosts file verifying the
+	// hosts of ssh clones, defaults to that of the ssh configuration.
+	SSHKnownHostsPath string `json:"sshKnownHostsPath,omitempty" yaml:"sshKnownHostsPath,omitempty"`
+}
+
+// Validate returns an error if c can't authenticate any clone.
+func (c GitCredential) Validate() error {
+	if c.Host == "" || strings.Contains(c.Host, "://") {
+		return fmt.Errorf("the host %q of a git credential must be a host, e.g. github.com, "+
+			"optionally followed by a path", c.Host)
+	}
+	if c.Token != "" && c.TokenEnv != "" {
+		return fmt.Errorf("git credential of %s may have either a token or a tokenEnv, not both", c.Host)
+	}
+	if c.Token == "" && c.TokenEnv == "" && c.SSHKeyPath == "" {
+		return fmt.Errorf("git credential of %s must have a token, tokenEnv or sshKeyPath", c.Host)
+	}
+	if c.SSHKnownHostsPath != "" && c.SSHKeyPath == "" {
+		return fmt.Errorf("git credential of %s has an sshKnownHostsPath without an sshKeyPath", c.Host)
+	}
+	return nil
+}
+
+// ParseGitCredentials parses the GitCredentials in data.
+func ParseGitCredentials(data []byte) (*GitCredentials, error) {
+	var c GitCredentials
+	if err := yaml.UnmarshalStrict(data, &c); err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid git credentials")
+	}
+	if c.APIVersion != GitCredentialsVersion || c.Kind != GitCredentialsKind {
+		return nil, fmt.Errorf("invalid git credentials: expected apiVersion %s and kind %s, got %s %s",
+			GitCredentialsVersion, GitCredentialsKind, c.APIVersion, c.Kind)
+	}
+	for _, credential := range c.Credentials {
+		if err := credential.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid git credentials: %w", err)
+		}
+	}
+	return &c, nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitCredentials(t *testing.T) {
+	c, err := ParseGitCredentials([]byte(`
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: GitCredentials
+credentials:
+- host: github.com/my-org
+  tokenEnv: GITHUB_TOKEN
+- host: gitlab.example.com
+  sshKeyPath: /keys/deploy
+  sshKnownHostsPath: /keys/known_hosts
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []GitCredential{
+		{Host: "github.com/my-org", TokenEnv: "GITHUB_TOKEN"},
+		{Host: "gitlab.example.com", SSHKeyPath: "/keys/deploy", SSHKnownHostsPath: "/keys/known_hosts"},
+	}, c.Credentials)
+
+	for data, expected := range map[string]string{
+		"apiVersion: kustomize.config.k8s.io/v1alpha1\nkind: GitCredentials\ncredentials:\n" +
+			"- host: https://github.com\n  token: secret\n": `invalid git credentials: the host "https://github.com" ` +
+			"of a git credential must be a host, e.g. github.com, optionally followed by a path",
+		"apiVersion: kustomize.config.k8s.io/v1alpha1\nkind: GitCredentials\ncredentials:\n" +
+			"- host: github.com\n  username: bot\n": "invalid git credentials: git credential of github.com " +
+			"must have a token, tokenEnv or sshKeyPath",
+		"apiVersion: kustomize.config.k8s.io/v1alpha1\nkind: GitCredentials\ncredentials:\n" +
+			"- host: github.com\n  token: secret\n  sshKnownHostsPath: /keys/known_hosts\n": "invalid git credentials: " +
+			"git credential of github.com has an sshKnownHostsPath without an sshKeyPath",
+		"apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\n": "invalid git credentials: " +
+			"expected apiVersion kustomize.config.k8s.io/v1alpha1 and kind GitCredentials, " +
+			"got kustomize.config.k8s.io/v1beta1 Kustomization",
+	} {
+		_, err := ParseGitCredentials([]byte(data))
+		assert.EqualError(t, err, expected)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+// GitFetchConfig configures what the clones of the git repositories of
+// remote bases fetch, unless set by the query parameters of their urls.
+// Its zero value fetches only the commit of the ref of a base, without
+// any other branch or tag, which suffices to build it.
+type GitFetchConfig struct {
+	// Depth is the number of commits of the history of the ref fetched.
+	// 0 fetches only the commit of the ref, a negative depth the full
+	// history.
+	Depth int `json:"depth,omitempty" yaml:"depth,omitempty"`
+
+	// AllBranches fetches all the branches of the repository, rather
+	// than only the ref.
+	AllBranches bool `json:"allBranches,omitempty" yaml:"allBranches,omitempty"`
+
+	// Tags fetches all the tags of the repository.
+	Tags bool `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
@@ -88,6 +88,10 @@ type HelmChart struct {
 	// ApiVersions is the kubernetes apiversions used for Capabilities.APIVersions
 	ApiVersions []string `json:"apiVersions,omitempty" yaml:"apiVersions,omitempty"`
 
+	// KubeVersion is the kubernetes version used for Capabilities.KubeVersion,
+	// e.g. '1.27.0'.
+	KubeVersion string `json:"kubeVersion,omitempty" yaml:"kubeVersion,omitempty"`
+
 	// NameTemplate is for specifying the name template used to name the release.
 	NameTemplate string `json:"nameTemplate,omitempty" yaml:"nameTemplate,omitempty"`
 
@@ -172,6 +176,9 @@ func (h HelmChart) AsHelmArgs(absChartHome string) []string {
 	for _, apiVer := range h.ApiVersions {
 		args = append(args, "--api-versions", apiVer)
 	}
+	if h.KubeVersion != "" {
+		args = append(args, "--kube-version", h.KubeVersion)
+	}
 	if h.IncludeCRDs {
 		args = append(args, "--include-crds")
 	}
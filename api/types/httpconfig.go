@@ -0,0 +1,199 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// systemCABundles are the usual locations of the bundle of the system
+// certificate authorities, see crypto/x509.
+var systemCABundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",
+	"/etc/pki/tls/certs/ca-bundle.crt",
+	"/etc/ssl/ca-bundle.pem",
+	"/etc/pki/tls/cacert.pem",
+	"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem",
+	"/etc/ssl/cert.pem",
+}
+
+// HTTPConfig configures the http(s) connections of the remote fetches of
+// builds: the clones of git repositories over https, remote files and
+// archives, and helm charts.  Unless set, the proxy is read from the
+// environment, e.g. HTTPS_PROXY.
+type HTTPConfig struct {
+	// Proxy is the url of the proxy of the connections, e.g.
+	// http://proxy.example.com:3128.
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+
+	// NoProxy are the hosts connected to directly rather than through
+	// Proxy, e.g. internal.example.com, matching its subdomains too,
+	// 10.0.0.0/8 or *, like NO_PROXY.
+	NoProxy []string `json:"noProxy,omitempty" yaml:"noProxy,omitempty"`
+
+	// CAFiles are the PEM files of certificate authorities trusted in
+	// addition to those of the system, e.g. of a TLS intercepting proxy.
+	CAFiles []string `json:"caFiles,omitempty" yaml:"caFiles,omitempty"`
+}
+
+// Validate returns an error if the proxy isn't a url, or a CA file has no
+// certificates.
+func (c HTTPConfig) Validate() error {
+	if c.Proxy != "" {
+		if _, err := c.proxyURL(); err != nil {
+			return err
+		}
+	}
+	for _, f := range c.CAFiles {
+		if _, err := readCAFile(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c HTTPConfig) proxyURL() (*url.URL, error) {
+	u, err := url.Parse(c.Proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q, expecting a url like http://proxy.example.com:3128", c.Proxy)
+	}
+	return u, nil
+}
+
+func readCAFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "reading CA file")
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("CA file %s has no PEM encoded certificates", path)
+	}
+	return content, nil
+}
+
+// IsZero returns true if c doesn't change the connections.
+func (c HTTPConfig) IsZero() bool {
+	return c.Proxy == "" && len(c.NoProxy) == 0 && len(c.CAFiles) == 0
+}
+
+// Transport returns a transport like http.DefaultTransport connecting
+// through the proxy of c, and trusting the certificate authorities of c in
+// addition to those of the system.
+func (c HTTPConfig) Transport() (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if c.Proxy != "" {
+		proxy, err := c.proxyURL()
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			if c.bypassesProxy(req.URL.Hostname()) {
+				return nil, nil
+			}
+			return proxy, nil
+		}
+	}
+	if len(c.CAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, f := range c.CAFiles {
+			content, err := readCAFile(f)
+			if err != nil {
+				return nil, err
+			}
+			pool.AppendCertsFromPEM(content)
+		}
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{} //nolint:gosec
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+	return t, nil
+}
+
+// bypassesProxy returns true if host matches NoProxy.
+func (c HTTPConfig) bypassesProxy(host string) bool {
+	ip := net.ParseIP(host)
+	for _, p := range c.NoProxy {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case p == "*":
+			return true
+		case ip != nil && strings.Contains(p, "/"):
+			if _, cidr, err := net.ParseCIDR(p); err == nil && cidr.Contains(ip) {
+				return true
+			}
+		default:
+			p = strings.TrimPrefix(p, ".")
+			host = strings.ToLower(host)
+			if p != "" && (host == p || strings.HasSuffix(host, "."+p)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ProxyEnv returns the proxy environment variables of the programs run to
+// fetch remote content, e.g. git and helm, for the proxy of c.
+func (c HTTPConfig) ProxyEnv() []string {
+	if c.Proxy == "" {
+		return nil
+	}
+	noProxy := strings.Join(c.NoProxy, ",")
+	var env []string
+	// programs differ in the case of the variables they read
+	for _, name := range []string{"http_proxy", "https_proxy", "HTTP_PROXY", "HTTPS_PROXY"} {
+		env = append(env, name+"="+c.Proxy)
+	}
+	return append(env, "no_proxy="+noProxy, "NO_PROXY="+noProxy)
+}
+
+// WriteCABundle writes the certificate authorities of the system and of c
+// to a bundle file in dir, for programs run to fetch remote content which
+// can only be given one file, e.g. git and helm, and returns its path.  It
+// returns the empty string if c has no CA files.
+func (c HTTPConfig) WriteCABundle(dir string) (string, error) {
+	if len(c.CAFiles) == 0 {
+		return "", nil
+	}
+	var bundle bytes.Buffer
+	systemBundles := systemCABundles
+	if f := os.Getenv("SSL_CERT_FILE"); f != "" {
+		systemBundles = []string{f}
+	}
+	for _, f := range systemBundles {
+		if content, err := os.ReadFile(f); err == nil {
+			bundle.Write(content)
+			bundle.WriteString("\n")
+			break
+		}
+	}
+	for _, f := range c.CAFiles {
+		content, err := readCAFile(f)
+		if err != nil {
+			return "", err
+		}
+		bundle.Write(content)
+		bundle.WriteString("\n")
+	}
+	path := filepath.Join(dir, "ca-bundle.pem")
+	if err := os.WriteFile(path, bundle.Bytes(), 0o600); err != nil {
+		return "", errors.WrapPrefixf(err, "writing CA bundle")
+	}
+	return path, nil
+}
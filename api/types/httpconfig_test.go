@@ -0,0 +1,102 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPConfig_Validate(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+	for c, expected := range map[*HTTPConfig]string{
+		{}:                                       "",
+		{Proxy: "http://proxy.example.com:3128"}: "",
+		{Proxy: "proxy.example.com:3128"}:        `invalid proxy "proxy.example.com:3128", expecting a url like http://proxy.example.com:3128`,
+		{CAFiles: []string{notPEM}}:              "CA file " + notPEM + " has no PEM encoded certificates",
+		{CAFiles: []string{notPEM + ".missing"}}: "reading CA file",
+	} {
+		err := c.Validate()
+		if expected == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorContains(t, err, expected)
+		}
+	}
+}
+
+func TestHTTPConfig_bypassesProxy(t *testing.T) {
+	c := HTTPConfig{NoProxy: []string{"internal.example.com", ".corp", "10.0.0.0/8"}}
+	for host, expected := range map[string]bool{
+		"internal.example.com":     true,
+		"git.internal.example.com": true,
+		"Git.Corp":                 true,
+		"10.1.2.3":                 true,
+		"example.com":              false,
+		"notinternal.example.com":  false,
+		"192.168.0.1":              false,
+	} {
+		assert.Equal(t, expected, c.bypassesProxy(host), host)
+	}
+	assert.True(t, HTTPConfig{NoProxy: []string{"*"}}.bypassesProxy("example.com"))
+}
+
+func TestHTTPConfig_Transport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(
+		&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	transport, err := HTTPConfig{}.Transport()
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	require.Error(t, err)
+
+	transport, err = HTTPConfig{CAFiles: []string{caFile}}.Transport()
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	transport, err = HTTPConfig{Proxy: "http://proxy.example.com:3128", NoProxy: []string{"internal.example.com"}}.Transport()
+	require.NoError(t, err)
+	for url, expected := range map[string]string{
+		"https://github.com/org/repo":       "http://proxy.example.com:3128",
+		"https://internal.example.com/repo": "",
+	} {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		proxy, err := transport.Proxy(req)
+		require.NoError(t, err)
+		if expected == "" {
+			assert.Nil(t, proxy, url)
+		} else {
+			assert.Equal(t, expected, proxy.String(), url)
+		}
+	}
+}
+
+func TestHTTPConfig_ProxyEnv(t *testing.T) {
+	assert.Empty(t, HTTPConfig{}.ProxyEnv())
+	assert.Equal(t, []string{
+		"http_proxy=http://proxy.example.com:3128",
+		"https_proxy=http://proxy.example.com:3128",
+		"HTTP_PROXY=http://proxy.example.com:3128",
+		"HTTPS_PROXY=http://proxy.example.com:3128",
+		"no_proxy=internal.example.com,10.0.0.0/8",
+		"NO_PROXY=internal.example.com,10.0.0.0/8",
+	}, HTTPConfig{Proxy: "http://proxy.example.com:3128", NoProxy: []string{"internal.example.com", "10.0.0.0/8"}}.ProxyEnv())
+}
@@ -23,6 +23,8 @@ const (
 	MetadataNamespaceApiVersion = "v1"
 	MetadataNamePath            = "metadata/name"
 
+	OpenAPIPathKey = "path"
+
 	OriginAnnotations      = "originAnnotations"
 	TransformerAnnotations = "transformerAnnotations"
 	ManagedByLabelOption   = "managedByLabel"
@@ -40,6 +42,10 @@ type Kustomization struct {
 	// OpenAPI contains information about what kubernetes schema to use.
 	OpenAPI map[string]string `json:"openapi,omitempty" yaml:"openapi,omitempty"`
 
+	// OpenAPIMerge specifies relative paths to schemas, e.g. of CRDs, whose
+	// definitions are merged over the schema of the openapi field.
+	OpenAPIMerge []string `json:"openapiMerge,omitempty" yaml:"openapiMerge,omitempty"`
+
 	//
 	// Operators - what kustomize can do.
 	//
@@ -124,6 +130,12 @@ type Kustomization struct {
 	// via relative paths, absolute paths, or URLs.
 	Components []string `json:"components,omitempty" yaml:"components,omitempty"`
 
+	// AllowedPaths specifies relative paths to directories outside the root
+	// of the kustomization whose files it may read, e.g. the patches shared
+	// by the overlays of a repository.  They're only honored when enabled,
+	// e.g. by kustomize build --enable-allowed-paths.
+	AllowedPaths []string `json:"allowedPaths,omitempty" yaml:"allowedPaths,omitempty"`
+
 	// Crds specifies relative paths to Custom Resource Definition files.
 	// This allows custom resources to be recognized as operands, making
 	// it possible to add them to the Resources list.
@@ -176,6 +188,11 @@ type Kustomization struct {
 	// Validators is a list of files containing validators
 	Validators []string `json:"validators,omitempty" yaml:"validators,omitempty"`
 
+	// Catalogs is a list of files containing catalogs of functions, which
+	// resolve the generators, transformers and validators without a
+	// function annotation to functions by their apiVersion and kind.
+	Catalogs []string `json:"catalogs,omitempty" yaml:"catalogs,omitempty"`
+
 	// BuildMetadata is a list of strings used to toggle different build options
 	BuildMetadata []string `json:"buildMetadata,omitempty" yaml:"buildMetadata,omitempty"`
 }
@@ -21,4 +21,10 @@ const (
 	// relative paths to patch or resources files outside
 	// its own tree.
 	LoadRestrictionsNone
+
+	// Files referenced by a kustomization file must be in
+	// the git repository holding the kustomization file,
+	// or under the directory holding the kustomization file
+	// if it isn't in a git repository.
+	LoadRestrictionsRootOfRepo
 )
@@ -0,0 +1,63 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	PipelineVersion = "config.kubernetes.io/v1alpha1"
+	PipelineKind    = "Pipeline"
+)
+
+// Pipeline is a sequence of functions, referenced by the generators,
+// transformers or validators of kustomizations like a function
+// configuration, so that they can share a single maintained sequence,
+// e.g. from a remote ref.
+type Pipeline struct {
+	TypeMeta `json:",inline" yaml:",inline"`
+
+	MetaData *ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+
+	Spec PipelineSpec `json:"spec,omitempty" yaml:"spec,omitempty"`
+}
+
+type PipelineSpec struct {
+	// Functions are run in order.
+	Functions []PipelineFunction `json:"functions,omitempty" yaml:"functions,omitempty"`
+}
+
+// PipelineFunction is a function of a Pipeline.
+type PipelineFunction struct {
+	// FunctionSpec is how the function runs, e.g. its container.  If set, it
+	// replaces the function annotation of the configuration, otherwise the
+	// function is resolved from the configuration like any other.
+	runtimeutil.FunctionSpec `json:",inline" yaml:",inline"`
+
+	// Config is the configuration of the function.
+	Config map[string]interface{} `json:"config" yaml:"config"`
+}
+
+// ParsePipeline parses the Pipeline in data.
+func ParsePipeline(data []byte) (*Pipeline, error) {
+	var p Pipeline
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid pipeline")
+	}
+	if p.APIVersion != PipelineVersion || p.Kind != PipelineKind {
+		return nil, fmt.Errorf("invalid pipeline: expected apiVersion %s and kind %s, got %s %s",
+			PipelineVersion, PipelineKind, p.APIVersion, p.Kind)
+	}
+	for i, f := range p.Spec.Functions {
+		if len(f.Config) == 0 {
+			return nil, fmt.Errorf("invalid pipeline: function %d has no config", i)
+		}
+	}
+	return &p, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePipeline(t *testing.T) {
+	p, err := ParsePipeline([]byte(`
+apiVersion: config.kubernetes.io/v1alpha1
+kind: Pipeline
+metadata:
+  name: team-defaults
+spec:
+  functions:
+  - container:
+      image: docker.example.co/functions/set-team:v1.0.0
+    config:
+      apiVersion: example.co/v1
+      kind: SetTeam
+      metadata:
+        name: set-team
+  - config:
+      apiVersion: example.co/v1
+      kind: Validate
+      metadata:
+        name: validate
+`))
+	require.NoError(t, err)
+	require.Len(t, p.Spec.Functions, 2)
+	assert.Equal(t, "docker.example.co/functions/set-team:v1.0.0", p.Spec.Functions[0].Container.Image)
+	assert.Equal(t, "SetTeam", p.Spec.Functions[0].Config["kind"])
+	assert.Empty(t, p.Spec.Functions[1].Container.Image)
+
+	_, err = ParsePipeline([]byte(`
+apiVersion: config.kubernetes.io/v1alpha1
+kind: Pipeline
+spec:
+  functions:
+  - image: docker.example.co/functions/set-team:v1.0.0
+`))
+	assert.ErrorContains(t, err, `unknown field "image"`)
+
+	_, err = ParsePipeline([]byte(`
+apiVersion: config.kubernetes.io/v1alpha1
+kind: Catalog
+`))
+	assert.EqualError(t, err, "invalid pipeline: expected apiVersion config.kubernetes.io/v1alpha1 "+
+		"and kind Pipeline, got config.kubernetes.io/v1alpha1 Catalog")
+}
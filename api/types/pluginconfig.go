@@ -6,6 +6,18 @@ package types
 type HelmConfig struct {
 	Enabled bool
 	Command string
+
+	// KubeVersion is the kubeVersion of the helm charts which don't
+	// set one.
+	KubeVersion string
+
+	// ApiVersions are the apiVersions of the helm charts which don't
+	// set any.
+	ApiVersions []string
+
+	// HTTP is the proxy and extra certificate authorities of the pulls
+	// of the charts.
+	HTTP HTTPConfig
 }
 
 // PluginConfig holds plugin configuration.
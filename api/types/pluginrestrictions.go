@@ -3,6 +3,13 @@
 
 package types
 
+import (
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
 // Some plugin classes
 // - builtin: plugins defined in the kustomize repo.
 //   May be freely used and re-configured.
@@ -46,8 +53,25 @@ const (
 type FnPluginLoadingOptions struct {
 	// Allow to run executables
 	EnableExec bool
+	// Executables exec functions and exec plugins are restricted to,
+	// if not nil
+	ExecAllowlist []exec.AllowedExecutable
+	// Keeps the exec functions run as servers running across their runs,
+	// required by them.  The caller closes it, stopping the servers.
+	ExecServers *exec.ServerPool
+	// Run exec functions in a sandbox on Linux, with access to the mounts,
+	// and to the network only if Network is set
+	SandboxExec bool
 	// Allow to run starlark
 	EnableStar bool
+	// Allow to run WebAssembly modules
+	EnableWasm bool
+	// WebAssembly runtime to run modules with, wasmtime or wazero.
+	// Autodetected if empty.
+	WasmRuntime string
+	// Allow to run functions published as Go modules, built with the
+	// go command and run on the host
+	EnableGo bool
 	// Allow container access to network
 	Network     bool
 	NetworkName string
@@ -57,6 +81,33 @@ type FnPluginLoadingOptions struct {
 	Env []string
 	// Run as uid and gid of the command executor
 	AsCurrentUser bool
+	// Container runtime to run containers with, e.g. docker or podman.
+	// Autodetected if empty.
+	ContainerRuntime string
+	// Isolation of Windows containers, process or hyperv. The runtime's
+	// default if empty.
+	ContainerIsolation string
+	// Reuses the containers of functions across their runs, if set.
+	// The caller closes it, removing the containers.
+	ContainerPool *container.Pool
+	// Caches the outputs of the exec functions, and of the container
+	// functions whose image is pinned by digest, if set.
+	OutputCache *runtimeutil.OutputCache
+	// Image pull policy of the container functions which don't declare one:
+	// Always, IfNotPresent or Never. The runtime's default if empty.
+	ImagePullPolicy string
+	// Called with the results functions emit in the results field of
+	// their ResourceList, if set
+	ResultsFunc func(function string, results framework.Results)
+	// Resource limits of the container and exec functions which don't
+	// declare them
+	Limits runtimeutil.ResourceLimits
+	// Called with the lines functions write to their stderr, as records
+	// identifying the function, rather than writing them to stderr, if set
+	LogFunc func(record runtimeutil.LogRecord)
 	// Run in this working directory
 	WorkingDir string
+	// Trusted catalogs resolving the functions of all kustomizations,
+	// taking precedence over the catalogs of the kustomizations
+	Catalogs []*Catalog
 }
@@ -6,6 +6,7 @@ package types
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/resid"
 )
@@ -40,6 +41,8 @@ func (s *Selector) String() string {
 // SelectorRegex is a Selector with regex in GVK
 // Any resource that matches intersection of all conditions
 // is included in this set.
+// A group, version or kind with the resid.GlobPrefix is a glob pattern
+// matched like by resid.Gvk.IsSelected, e.g. "glob:v1*" matches "v1beta1".
 type SelectorRegex struct {
 	selector       *Selector
 	groupRegex     *regexp.Regexp
@@ -55,15 +58,15 @@ func NewSelectorRegex(s *Selector) (*SelectorRegex, error) {
 	sr := new(SelectorRegex)
 	var err error
 	sr.selector = s
-	sr.groupRegex, err = regexp.Compile(anchorRegex(s.Gvk.Group))
+	sr.groupRegex, err = compileGvkPattern(s.Gvk.Group)
 	if err != nil {
 		return nil, err
 	}
-	sr.versionRegex, err = regexp.Compile(anchorRegex(s.Gvk.Version))
+	sr.versionRegex, err = compileGvkPattern(s.Gvk.Version)
 	if err != nil {
 		return nil, err
 	}
-	sr.kindRegex, err = regexp.Compile(anchorRegex(s.Gvk.Kind))
+	sr.kindRegex, err = compileGvkPattern(s.Gvk.Kind)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +81,15 @@ func NewSelectorRegex(s *Selector) (*SelectorRegex, error) {
 	return sr, nil
 }
 
+// compileGvkPattern compiles the group, version or kind of a selector,
+// which is a glob pattern if it has the resid.GlobPrefix, or else a regex.
+func compileGvkPattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, resid.GlobPrefix) {
+		return resid.CompileGlob(pattern)
+	}
+	return regexp.Compile(anchorRegex(pattern))
+}
+
 func anchorRegex(pattern string) string {
 	if pattern == "" {
 		return pattern
@@ -118,6 +118,129 @@ func TestSelectorRegexMatchGvk(t *testing.T) {
 			},
 			Expected: false,
 		},
+		{
+			S: Selector{
+				ResId: resid.ResId{
+					Gvk: resid.Gvk{
+						Group:   "glob:*.example.com",
+						Version: "v1",
+					},
+				},
+			},
+			G: resid.Gvk{
+				Group:   "widgets.example.com",
+				Version: "v1",
+				Kind:    "Widget",
+			},
+			Expected: true,
+		},
+		{
+			S: Selector{
+				ResId: resid.ResId{
+					Gvk: resid.Gvk{
+						Group: "glob:*.example.com",
+					},
+				},
+			},
+			G: resid.Gvk{
+				Group:   "widgets.exampleXcom",
+				Version: "v1",
+				Kind:    "Widget",
+			},
+			Expected: false,
+		},
+		{
+			S: Selector{
+				ResId: resid.ResId{
+					Gvk: resid.Gvk{
+						Version: "glob:v1*",
+					},
+				},
+			},
+			G: resid.Gvk{
+				Group:   "example.com",
+				Version: "v1beta1",
+				Kind:    "Widget",
+			},
+			Expected: true,
+		},
+		// patterns without the glob prefix keep matching as regexes
+		{
+			S: Selector{
+				ResId: resid.ResId{
+					Gvk: resid.Gvk{
+						Version: "v1*",
+					},
+				},
+			},
+			G: resid.Gvk{
+				Group:   "example.com",
+				Version: "v1beta1",
+				Kind:    "Widget",
+			},
+			Expected: false,
+		},
+		{
+			S: Selector{
+				ResId: resid.ResId{
+					Gvk: resid.Gvk{
+						Version: "v1?",
+					},
+				},
+			},
+			G: resid.Gvk{
+				Group:   "example.com",
+				Version: "v10",
+				Kind:    "Widget",
+			},
+			Expected: false,
+		},
+		{
+			S: Selector{
+				ResId: resid.ResId{
+					Gvk: resid.Gvk{
+						Version: "v1?",
+					},
+				},
+			},
+			G: resid.Gvk{
+				Group:   "example.com",
+				Version: "v",
+				Kind:    "Widget",
+			},
+			Expected: true,
+		},
+		{
+			S: Selector{
+				ResId: resid.ResId{
+					Gvk: resid.Gvk{
+						Group: "apps.*",
+						Kind:  "Deploy.*",
+					},
+				},
+			},
+			G: resid.Gvk{
+				Group:   "apps.openshift.io",
+				Version: "v1",
+				Kind:    "DeploymentConfig",
+			},
+			Expected: true,
+		},
+		{
+			S: Selector{
+				ResId: resid.ResId{
+					Gvk: resid.Gvk{
+						Group: "apps.k8s.io",
+					},
+				},
+			},
+			G: resid.Gvk{
+				Group:   "appsXk8s.io",
+				Version: "v1",
+				Kind:    "Widget",
+			},
+			Expected: true,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -15,5 +15,6 @@ func GetFn(name string) *cobra.Command {
 	}
 
 	cmd.AddCommand(commands.RunCommand(name))
+	cmd.AddCommand(commands.RenderCommand(name))
 	return cmd
 }
@@ -35,6 +35,8 @@ func GetCatRunner(name string) *CatRunner {
 	runner.FixDocs(name, c)
 	c.Flags().BoolVar(&r.Format, "format", true,
 		"format resource config yaml before printing.")
+	c.Flags().BoolVar(&r.Canonical, "canonical", false,
+		"format resource config yaml in the canonical field order of the resource schema.")
 	c.Flags().BoolVar(&r.KeepAnnotations, "annotate", false,
 		"annotate resources with their file origins.")
 	c.Flags().StringVar(&r.WrapKind, "wrap-kind", "",
@@ -67,6 +69,7 @@ func CatCommand(name string) *cobra.Command {
 // CatRunner contains the run function
 type CatRunner struct {
 	Format             bool
+	Canonical          bool
 	KeepAnnotations    bool
 	WrapKind           string
 	WrapApiVersion     string
@@ -157,7 +160,9 @@ func (r *CatRunner) catFilters() []kio.Filter {
 		IncludeLocalConfig:    r.IncludeLocal,
 		ExcludeNonLocalConfig: r.ExcludeNonLocal,
 	})
-	if r.Format {
+	if r.Canonical {
+		fltrs = append(fltrs, filters.CanonicalFormatFilter{})
+	} else if r.Format {
 		fltrs = append(fltrs, filters.FormatFilter{})
 	}
 	if r.StripComments {
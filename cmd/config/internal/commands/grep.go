@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"regexp"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -49,12 +50,15 @@ type GrepRunner struct {
 	KeepAnnotations bool
 	Command         *cobra.Command
 	filters.GrepFilter
+	// And are the further conditions of the query, which the Resources
+	// must match too.
+	And                []filters.GrepFilter
 	Format             bool
 	RecurseSubPackages bool
 }
 
 func (r *GrepRunner) preRunE(c *cobra.Command, args []string) error {
-	r.GrepFilter.Compare = func(a, b string) (int, error) {
+	compare := func(a, b string) (int, error) {
 		qa, err := resource.ParseQuantity(a)
 		if err != nil {
 			return 0, fmt.Errorf("%s: %v", a, err)
@@ -66,41 +70,116 @@ func (r *GrepRunner) preRunE(c *cobra.Command, args []string) error {
 
 		return qa.Cmp(qb), err
 	}
-	parts, err := runner.ParseFieldPath(args[0])
-	if err != nil {
-		return err
+	r.And = nil
+	for i, condition := range strings.Split(args[0], conditionSeparator) {
+		f, err := parseCondition(strings.TrimSpace(condition))
+		if err != nil {
+			return err
+		}
+		f.Compare = compare
+		// a negated condition selects the Resources not matching it
+		f.InvertMatch = f.InvertMatch != r.InvertMatch
+		if i == 0 {
+			r.GrepFilter = f
+		} else {
+			r.And = append(r.And, f)
+		}
 	}
+	return nil
+}
 
-	var last []string
-	switch {
-	case strings.Contains(parts[len(parts)-1], ">="):
-		last = strings.Split(parts[len(parts)-1], ">=")
-		r.MatchType = filters.GreaterThanEq
-	case strings.Contains(parts[len(parts)-1], "<="):
-		last = strings.Split(parts[len(parts)-1], "<=")
-		r.MatchType = filters.LessThanEq
-	case strings.Contains(parts[len(parts)-1], ">"):
-		last = strings.Split(parts[len(parts)-1], ">")
-		r.MatchType = filters.GreaterThan
-	case strings.Contains(parts[len(parts)-1], "<"):
-		last = strings.Split(parts[len(parts)-1], "<")
-		r.MatchType = filters.LessThan
-	default:
-		last = strings.Split(parts[len(parts)-1], "=")
-		r.MatchType = filters.Regexp
+// conditionSeparator separates the conditions of a query.
+const conditionSeparator = "&&"
+
+// parseCondition parses a condition of a query, e.g.
+// "metadata.annotations['team']=payments", into a GrepFilter.
+func parseCondition(condition string) (filters.GrepFilter, error) {
+	f := filters.GrepFilter{MatchType: filters.Regexp}
+	var path []string
+	var element strings.Builder
+	flush := func() {
+		if e := strings.TrimSpace(element.String()); e != "" {
+			path = append(path, e)
+		}
+		element.Reset()
 	}
-	if len(last) > 2 {
-		return fmt.Errorf(
-			"ambiguous match -- multiple of ['<', '>', '<=', '>=', '=' in final path element: %s",
-			parts[len(parts)-1])
+	i := 0
+	op := ""
+	for i < len(condition) && op == "" {
+		switch c := condition[i]; {
+		case c == '\\' && i+1 < len(condition):
+			// '.' as part of a key can be escaped as '\.'
+			element.WriteByte(condition[i+1])
+			i += 2
+		case c == '.':
+			flush()
+			i++
+		case c == '[':
+			end := strings.IndexByte(condition[i:], ']')
+			if end < 0 || strings.Contains(condition[i+1:i+end], "[") {
+				return f, fmt.Errorf("unrecognized path element: %s.  "+
+					"Should be of the form 'list[field=value]' or \"map['key']\"",
+					element.String()+condition[i:])
+			}
+			flush()
+			inner := condition[i+1 : i+end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				// a quoted map key, which may contain '.'
+				path = append(path, inner[1:len(inner)-1])
+			} else {
+				path = append(path, "["+inner+"]")
+			}
+			i += end + 1
+		case strings.IndexByte("=!<>", c) >= 0:
+			op = string(c)
+			if i+1 < len(condition) && condition[i+1] == '=' {
+				op += "="
+			}
+			i += len(op)
+		default:
+			element.WriteByte(c)
+			i++
+		}
 	}
-
-	if len(last) > 1 {
-		r.Value = last[1]
+	flush()
+	if len(path) == 0 {
+		return f, fmt.Errorf("missing field path in query condition: %s", condition)
 	}
+	f.Path = path
+	value := strings.TrimSpace(strings.ReplaceAll(condition[i:], "\\.", "."))
+	if strings.ContainsAny(value, "=<>") {
+		return f, fmt.Errorf(
+			"ambiguous match -- multiple of ['<', '>', '<=', '>=', '=', '==', '!='] in condition: %s",
+			condition)
+	}
+	f.Value = value
+	switch op {
+	case "", "=":
+	case "==":
+		f.Value = "^" + regexp.QuoteMeta(value) + "$"
+	case "!=":
+		f.InvertMatch = true
+	case ">":
+		f.MatchType = filters.GreaterThan
+	case ">=":
+		f.MatchType = filters.GreaterThanEq
+	case "<":
+		f.MatchType = filters.LessThan
+	case "<=":
+		f.MatchType = filters.LessThanEq
+	default:
+		return f, fmt.Errorf("unrecognized operator %q in condition: %s", op, condition)
+	}
+	return f, nil
+}
 
-	r.Path = append(parts[:len(parts)-1], last[0]) //nolint:gocritic
-	return nil
+// filters returns the filters of the conditions of the query.
+func (r *GrepRunner) filters() []kio.Filter {
+	result := []kio.Filter{r.GrepFilter}
+	for _, f := range r.And {
+		result = append(result, f)
+	}
+	return result
 }
 
 func (r *GrepRunner) runE(c *cobra.Command, args []string) error {
@@ -108,7 +187,7 @@ func (r *GrepRunner) runE(c *cobra.Command, args []string) error {
 		input := &kio.ByteReader{Reader: c.InOrStdin()}
 		return runner.HandleError(c, kio.Pipeline{
 			Inputs:  []kio.Reader{input},
-			Filters: []kio.Filter{r.GrepFilter},
+			Filters: r.filters(),
 			Outputs: []kio.Writer{kio.ByteWriter{
 				Writer:                c.OutOrStdout(),
 				KeepReaderAnnotations: r.KeepAnnotations,
@@ -143,7 +222,7 @@ func (r *GrepRunner) ExecuteCmd(w io.Writer, pkgPath string) error {
 	out := &bytes.Buffer{}
 	err := kio.Pipeline{
 		Inputs:  []kio.Reader{input},
-		Filters: []kio.Filter{r.GrepFilter},
+		Filters: r.filters(),
 		Outputs: []kio.Writer{kio.ByteWriter{
 			Writer:                out,
 			KeepReaderAnnotations: r.KeepAnnotations,
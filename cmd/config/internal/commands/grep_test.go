@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -14,6 +15,7 @@ import (
 	"sigs.k8s.io/kustomize/cmd/config/internal/commands"
 	"sigs.k8s.io/kustomize/kyaml/copyutil"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 )
 
 // TestGrepCommand_files verifies grep reads the files and filters them
@@ -420,3 +422,70 @@ spec:
 		})
 	}
 }
+
+// TestGrepCommand_expressions verifies grep matches path expressions with
+// comparison operators, quoted map keys and several conditions
+func TestGrepCommand_expressions(t *testing.T) {
+	input := `kind: Deployment
+metadata:
+  name: api
+  annotations:
+    example.com/team: payments
+spec:
+  replicas: 5
+---
+kind: Deployment
+metadata:
+  name: web
+  annotations:
+    example.com/team: payments-eu
+spec:
+  replicas: 2
+---
+kind: Service
+metadata:
+  name: api
+  annotations:
+    example.com/team: payments
+`
+	for _, test := range []struct {
+		query    string
+		invert   bool
+		expected []string
+	}{
+		{query: "spec.replicas>3", expected: []string{"Deployment/api"}},
+		{query: "spec.replicas <= 2", expected: []string{"Deployment/web"}},
+		{query: "metadata.annotations['example.com/team']=payments",
+			expected: []string{"Deployment/api", "Deployment/web", "Service/api"}},
+		{query: `metadata.annotations["example.com/team"]==payments`,
+			expected: []string{"Deployment/api", "Service/api"}},
+		{query: "kind!=Deployment", expected: []string{"Service/api"}},
+		{query: "kind=Deployment && metadata.annotations['example.com/team']==payments",
+			expected: []string{"Deployment/api"}},
+		{query: "kind=Deployment && spec.replicas>=2 && metadata.name!=api",
+			expected: []string{"Deployment/web"}},
+		{query: "kind=Deployment && spec.replicas>3", invert: true, expected: []string{"Service/api"}},
+		{query: "kind!=Deployment", invert: true, expected: []string{"Deployment/api", "Deployment/web"}},
+	} {
+		t.Run(test.query, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			r := commands.GetGrepRunner("")
+			r.Command.SetArgs([]string{test.query, "--annotate=false",
+				"--invert-match=" + strconv.FormatBool(test.invert)})
+			r.Command.SetOut(b)
+			r.Command.SetIn(bytes.NewBufferString(input))
+			if !assert.NoError(t, r.Command.Execute()) {
+				return
+			}
+			nodes, err := kio.FromBytes(b.Bytes())
+			if !assert.NoError(t, err) {
+				return
+			}
+			actual := []string{}
+			for _, node := range nodes {
+				actual = append(actual, node.GetKind()+"/"+node.GetName())
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/cmd/config/ext"
+	"sigs.k8s.io/kustomize/cmd/config/internal/generateddocs/commands"
+	"sigs.k8s.io/kustomize/cmd/config/runner"
+	"sigs.k8s.io/kustomize/kyaml/copyutil"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/pathutil"
+	"sigs.k8s.io/kustomize/kyaml/runfn"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// GetRenderRunner returns a RenderRunner.
+func GetRenderRunner(name string) *RenderRunner {
+	r := &RenderRunner{}
+	c := &cobra.Command{
+		Use:     "render [DIR]",
+		Short:   commands.RenderShort,
+		Long:    commands.RenderLong,
+		Example: commands.RenderExamples,
+		Args:    cobra.MaximumNArgs(1),
+		RunE:    r.runE,
+	}
+	runner.FixDocs(name, c)
+	r.Command = c
+	r.Command.Flags().BoolVar(
+		&r.DryRun, "dry-run", false, "print the hydrated resources to stdout instead of writing them")
+	// NOTE: exec plugins execute arbitrary code -- never change the default value of this flag!!!
+	r.Command.Flags().BoolVar(
+		&r.EnableExec, "enable-exec", false, /*do not change!*/
+		"enable support for exec functions -- note: exec functions run arbitrary code -- do not use for untrusted configs!!! (Alpha)")
+	r.Command.Flags().BoolVar(
+		&r.Network, "network", false, "enable network access for functions that declare it")
+	r.Command.Flags().BoolVar(
+		&r.LogSteps, "log-steps", false, "log steps to stderr")
+	r.Command.Flags().StringArrayVarP(
+		&r.Env, "env", "e", []string{},
+		"a list of environment variables to be used by functions")
+	r.Command.Flags().StringVar(
+		&r.ContainerRuntime, "container-runtime", "",
+		"the container runtime to run container functions with: docker, podman or nerdctl "+
+			"(autodetected from the PATH if empty)")
+	r.Command.Flags().StringVar(
+		&r.ImagePullPolicy, "function-pull-policy", "",
+		"when to pull the images of container functions which don't declare an imagePullPolicy: "+
+			"Always, IfNotPresent or Never (the container runtime's default if empty)")
+	return r
+}
+
+func RenderCommand(name string) *cobra.Command {
+	return GetRenderRunner(name).Command
+}
+
+// RenderRunner contains the render function
+type RenderRunner struct {
+	Command          *cobra.Command
+	DryRun           bool
+	EnableExec       bool
+	Network          bool
+	LogSteps         bool
+	Env              []string
+	ContainerRuntime string
+	ImagePullPolicy  string
+}
+
+// Pipeline is the function pipeline declared in the pipeline field of
+// a package's Krmfile.
+type Pipeline struct {
+	// Mutators are run in order against the resources of the package and
+	// its subpackages, and their output is written back to the package.
+	Mutators []PipelineFunction `yaml:"mutators,omitempty"`
+
+	// Validators are run against the mutated resources, and their output
+	// is discarded.
+	Validators []PipelineFunction `yaml:"validators,omitempty"`
+}
+
+// PipelineFunction is a function of a Pipeline.
+type PipelineFunction struct {
+	// Image is the image of a container function, which may be pinned
+	// to a digest with image@sha256:...
+	Image string `yaml:"image,omitempty"`
+
+	// ImagePullPolicy is when to pull the image: Always, IfNotPresent or
+	// Never.  Defaults to --function-pull-policy.
+	ImagePullPolicy string `yaml:"imagePullPolicy,omitempty"`
+
+	// Exec is the path of an exec function, run in the package directory.
+	Exec string `yaml:"exec,omitempty"`
+
+	// ConfigPath is the path, relative to the package, of the file
+	// containing the function config.
+	ConfigPath string `yaml:"configPath,omitempty"`
+
+	// ConfigMap is the data of a ConfigMap used as the function config.
+	ConfigMap map[string]string `yaml:"configMap,omitempty"`
+}
+
+func (r *RenderRunner) runE(c *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	if !r.DryRun {
+		return runner.HandleError(c, r.Render(dir))
+	}
+
+	// render a copy of the packages and print the result
+	tmp, err := os.MkdirTemp("", "kustomize-fn-render-")
+	if err != nil {
+		return runner.HandleError(c, errors.Wrap(err))
+	}
+	defer os.RemoveAll(tmp)
+	if err := copyPackages(dir, tmp); err != nil {
+		return runner.HandleError(c, err)
+	}
+	if err := r.Render(tmp); err != nil {
+		return runner.HandleError(c, err)
+	}
+	return runner.HandleError(c, kio.Pipeline{
+		Inputs:  []kio.Reader{kio.LocalPackageReader{PackagePath: tmp, MatchFilesGlob: kio.MatchAll}},
+		Outputs: []kio.Writer{kio.ByteWriter{Writer: c.OutOrStdout()}},
+	}.Execute())
+}
+
+// copyPackages copies the src directory to dst.  Unlike copyutil.CopyDir,
+// it keeps the modes of the files, so that exec functions remain executable.
+func copyPackages(src, dst string) error {
+	return errors.Wrap(filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if copyutil.IsDotGitFolder(rel) {
+			return nil
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), info.Mode().Perm())
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), b, info.Mode().Perm())
+	}))
+}
+
+// Render runs the pipelines of the packages under dir, the packages being
+// the directories containing a Krmfile.  The packages are rendered bottom-up,
+// so that the pipeline of a package runs against the resources of its
+// subpackages after they have been rendered.
+func (r *RenderRunner) Render(dir string) error {
+	pkgs, err := pathutil.DirsWithFile(dir, ext.KRMFileName(), true)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if len(pkgs) == 0 {
+		return errors.Errorf("unable to find %q in package %q", ext.KRMFileName(), dir)
+	}
+	// render the deepest packages first
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		return depth(pkgs[i]) > depth(pkgs[j])
+	})
+	for _, pkg := range pkgs {
+		if err := r.renderPackage(pkg); err != nil {
+			return errors.WrapPrefixf(err, "rendering package %q", pkg)
+		}
+	}
+	return nil
+}
+
+func depth(path string) int {
+	return strings.Count(filepath.ToSlash(filepath.Clean(path)), "/")
+}
+
+// renderPackage runs the pipeline declared by the Krmfile of pkg.
+func (r *RenderRunner) renderPackage(pkg string) error {
+	pipeline, err := readPipeline(pkg)
+	if err != nil {
+		return err
+	}
+	wd, err := filepath.Abs(pkg)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	for i, fn := range pipeline.Mutators {
+		if err := r.runFunction(wd, fn, nil); err != nil {
+			return errors.WrapPrefixf(err, "mutator %d", i)
+		}
+	}
+	for i, fn := range pipeline.Validators {
+		if err := r.runFunction(wd, fn, io.Discard); err != nil {
+			return errors.WrapPrefixf(err, "validator %d", i)
+		}
+	}
+	return nil
+}
+
+// runFunction runs fn against the resources of the package pkg, writing
+// the result to output, or back to the package if output is nil.
+func (r *RenderRunner) runFunction(pkg string, fn PipelineFunction, output io.Writer) error {
+	config, err := fn.config(pkg, r.Network, r.EnableExec)
+	if err != nil {
+		return err
+	}
+	return runfn.RunFns{
+		Path:             pkg,
+		Functions:        []*yaml.RNode{config},
+		Output:           output,
+		Network:          r.Network,
+		EnableExec:       r.EnableExec,
+		LogSteps:         r.LogSteps,
+		Env:              r.Env,
+		ContainerRuntime: r.ContainerRuntime,
+		ImagePullPolicy:  r.ImagePullPolicy,
+		WorkingDir:       pkg,
+	}.Execute()
+}
+
+// readPipeline reads the pipeline of the Krmfile of pkg.
+func readPipeline(pkg string) (*Pipeline, error) {
+	b, err := os.ReadFile(filepath.Join(pkg, ext.KRMFileName()))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	krmfile := struct {
+		Pipeline Pipeline `yaml:"pipeline,omitempty"`
+	}{}
+	if err := yaml.Unmarshal(b, &krmfile); err != nil {
+		return nil, errors.WrapPrefixf(err, "parsing %s", ext.KRMFileName())
+	}
+	return &krmfile.Pipeline, nil
+}
+
+// config returns the function config of fn, annotated with the function.
+func (fn PipelineFunction) config(pkg string, network, enableExec bool) (*yaml.RNode, error) {
+	var fnAnnotation *yaml.RNode
+	var err error
+	switch {
+	case fn.Image != "" && fn.Exec != "":
+		return nil, errors.Errorf("only one of image and exec may be set")
+	case fn.Image != "":
+		fnAnnotation, err = fnAnnotationForImage(fn.Image, network)
+		if err == nil && fn.ImagePullPolicy != "" {
+			err = fnAnnotation.PipeE(
+				yaml.Lookup("container"),
+				yaml.SetField("imagePullPolicy", yaml.NewScalarRNode(fn.ImagePullPolicy)))
+		}
+	case fn.ImagePullPolicy != "":
+		return nil, errors.Errorf("imagePullPolicy may only be set with image")
+	case fn.Exec != "":
+		if !enableExec {
+			return nil, errors.Errorf("must specify --enable-exec to run exec function %s", fn.Exec)
+		}
+		fnAnnotation, err = fnAnnotationForExec(fn.Exec)
+	default:
+		return nil, errors.Errorf("one of image and exec must be set")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var res *yaml.RNode
+	switch {
+	case fn.ConfigPath != "" && fn.ConfigMap != nil:
+		return nil, errors.Errorf("only one of configPath and configMap may be set")
+	case fn.ConfigPath != "":
+		res, err = yaml.ReadFile(filepath.Join(pkg, fn.ConfigPath))
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "reading function config")
+		}
+	default:
+		var dataItems []string
+		for k, v := range fn.ConfigMap {
+			dataItems = append(dataItems, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(dataItems)
+		// the kind comes first, so that no data item is taken for it
+		res, err = buildFnConfigResource(append([]string{"ConfigMap"}, dataItems...))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// set the function annotation on the function config, so that it is parsed by RunFns
+	value, err := fnAnnotation.String()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	err = res.PipeE(
+		yaml.LookupCreate(yaml.MappingNode, "metadata", "annotations"),
+		yaml.SetField(runtimeutil.FunctionAnnotationKey, yaml.NewScalarRNode(value)))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return res, nil
+}
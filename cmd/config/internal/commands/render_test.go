@@ -0,0 +1,154 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package commands_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/cmd/config/internal/commands"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0700))
+	}
+}
+
+func configMap(name, value string) string {
+	return `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ` + name + `
+data:
+  value: ` + value + `
+`
+}
+
+// TestRenderCommand verifies render runs the pipelines of the packages
+// bottom-up and writes the result back to the packages.
+func TestRenderCommand(t *testing.T) {
+	d := t.TempDir()
+	writeFiles(t, d, map[string]string{
+		"Krmfile": `apiVersion: config.k8s.io/v1alpha1
+kind: Krmfile
+pipeline:
+  mutators:
+  - exec: ./b-to-c.sh
+  validators:
+  - exec: ./reject-a.sh
+`,
+		"b-to-c.sh":            "#!/bin/sh\nsed 's/value: b$/value: c/'\n",
+		"reject-a.sh":          "#!/bin/sh\nin=$(cat)\necho \"$in\" | grep -q 'value: a$' && exit 1\necho \"$in\"\n",
+		"cm.yaml":              configMap("root", "b"),
+		"sub/Krmfile":          "apiVersion: config.k8s.io/v1alpha1\nkind: Krmfile\npipeline:\n  mutators:\n  - exec: ./a-to-b.sh\n",
+		"sub/a-to-b.sh":        "#!/bin/sh\nsed 's/value: a$/value: b/'\n",
+		"sub/cm.yaml":          configMap("sub", "a"),
+		"sub/nested/cm.yaml":   configMap("nested", "a"),
+		"other/Krmfile":        "apiVersion: config.k8s.io/v1alpha1\nkind: Krmfile\n",
+		"other/cm.yaml":        configMap("other", "c"),
+		"sub/nested/README.md": "not a resource\n",
+	})
+
+	var out bytes.Buffer
+	r := commands.GetRenderRunner("")
+	r.Command.SetArgs([]string{d, "--enable-exec", "--dry-run"})
+	r.Command.SetOut(&out)
+	require.NoError(t, r.Command.Execute())
+	assert.Equal(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: root
+  annotations:
+    config.kubernetes.io/path: 'cm.yaml'
+    internal.config.kubernetes.io/path: 'cm.yaml'
+data:
+  value: c
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: other
+  annotations:
+    config.kubernetes.io/path: 'other/cm.yaml'
+    internal.config.kubernetes.io/path: 'other/cm.yaml'
+data:
+  value: c
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: sub
+  annotations:
+    config.kubernetes.io/path: 'sub/cm.yaml'
+    internal.config.kubernetes.io/path: 'sub/cm.yaml'
+data:
+  value: c
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nested
+  annotations:
+    config.kubernetes.io/path: 'sub/nested/cm.yaml'
+    internal.config.kubernetes.io/path: 'sub/nested/cm.yaml'
+data:
+  value: c
+`, out.String())
+	b, err := os.ReadFile(filepath.Join(d, "sub", "cm.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, configMap("sub", "a"), string(b))
+
+	r = commands.GetRenderRunner("")
+	r.Command.SetArgs([]string{d, "--enable-exec"})
+	require.NoError(t, r.Command.Execute())
+	for _, name := range []string{"cm.yaml", "sub/cm.yaml", "sub/nested/cm.yaml", "other/cm.yaml"} {
+		b, err := os.ReadFile(filepath.Join(d, name))
+		require.NoError(t, err)
+		assert.Contains(t, string(b), "value: c\n", name)
+	}
+}
+
+func TestRenderCommand_errors(t *testing.T) {
+	d := t.TempDir()
+	writeFiles(t, d, map[string]string{
+		"Krmfile": `apiVersion: config.k8s.io/v1alpha1
+kind: Krmfile
+pipeline:
+  validators:
+  - exec: ./reject-a.sh
+`,
+		"reject-a.sh": "#!/bin/sh\nin=$(cat)\necho \"$in\" | grep -q 'value: a$' && exit 1\necho \"$in\"\n",
+		"cm.yaml":     configMap("root", "a"),
+	})
+
+	r := commands.GetRenderRunner("")
+	r.Command.SetArgs([]string{d})
+	r.Command.SetOut(&bytes.Buffer{})
+	r.Command.SetErr(&bytes.Buffer{})
+	err := r.Command.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "validator 0: must specify --enable-exec to run exec function ./reject-a.sh")
+
+	r = commands.GetRenderRunner("")
+	r.Command.SetArgs([]string{d, "--enable-exec"})
+	r.Command.SetOut(&bytes.Buffer{})
+	r.Command.SetErr(&bytes.Buffer{})
+	err = r.Command.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "validator 0: exit status 1")
+
+	r = commands.GetRenderRunner("")
+	r.Command.SetArgs([]string{filepath.Join(d, "missing")})
+	r.Command.SetOut(&bytes.Buffer{})
+	r.Command.SetErr(&bytes.Buffer{})
+	require.Error(t, r.Command.Execute())
+}
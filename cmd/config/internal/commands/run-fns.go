@@ -14,6 +14,7 @@ import (
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/runfn"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 
@@ -59,6 +60,22 @@ func GetRunFnRunner(name string) *RunFnRunner {
 		&r.StarURL, "star-url", "", "run a starlark script as a function. (Alpha)")
 	r.Command.Flags().StringVar(
 		&r.StarName, "star-name", "", "name of starlark program. (Alpha)")
+	r.Command.Flags().BoolVar(
+		&r.EnableWasm, "enable-wasm", false,
+		"enable support for WebAssembly functions, run sandboxed by a wasm runtime. (Alpha)")
+	r.Command.Flags().StringVar(
+		&r.WasmPath, "wasm-path", "", "run a WebAssembly module as a function. (Alpha)")
+	r.Command.Flags().StringVar(
+		&r.WasmRuntime, "wasm-runtime", "",
+		"the WebAssembly runtime to run wasm functions with: wasmtime or wazero "+
+			"(autodetected from the PATH if empty)")
+	r.Command.Flags().BoolVar(
+		&r.EnableGo, "enable-go", false,
+		"enable support for functions published as Go modules, built with the go command; "+
+			"do not use for untrusted configs! (Alpha)")
+	r.Command.Flags().StringVar(
+		&r.GoModule, "go-module", "",
+		"run a Go module pinned to a version, e.g. example.com/fn@v1.0.0, as a function. (Alpha)")
 
 	r.Command.Flags().StringVar(
 		&r.ResultsDir, "results-dir", "", "write function results to this dir")
@@ -75,6 +92,22 @@ func GetRunFnRunner(name string) *RunFnRunner {
 		"a list of environment variables to be used by functions")
 	r.Command.Flags().BoolVar(
 		&r.AsCurrentUser, "as-current-user", false, "use the uid and gid of the command executor to run the function in the container")
+	r.Command.Flags().StringVar(
+		&r.Source, "source", "",
+		"read the Resources from this OCI artifact, e.g. oci://registry.example.com/app:v1, "+
+			"instead of DIR or stdin")
+	r.Command.Flags().StringVar(
+		&r.Sink, "sink", "",
+		"publish the result as this OCI artifact, e.g. oci://registry.example.com/app:v2, "+
+			"instead of writing it to DIR or stdout")
+	r.Command.Flags().StringVar(
+		&r.ContainerRuntime, "container-runtime", "",
+		"the container runtime to run container functions with: docker, podman or nerdctl "+
+			"(autodetected from the PATH if empty)")
+	r.Command.Flags().StringVar(
+		&r.ImagePullPolicy, "function-pull-policy", "",
+		"when to pull the images of container functions which don't declare an imagePullPolicy: "+
+			"Always, IfNotPresent or Never (the container runtime's default if empty)")
 
 	return r
 }
@@ -97,6 +130,11 @@ type RunFnRunner struct {
 	StarName           string
 	EnableExec         bool
 	ExecPath           string
+	EnableWasm         bool
+	WasmPath           string
+	WasmRuntime        string
+	EnableGo           bool
+	GoModule           string
 	RunFns             runfn.RunFns
 	ResultsDir         string
 	Network            bool
@@ -104,6 +142,10 @@ type RunFnRunner struct {
 	LogSteps           bool
 	Env                []string
 	AsCurrentUser      bool
+	ContainerRuntime   string
+	ImagePullPolicy    string
+	Source             string
+	Sink               string
 }
 
 func (r *RunFnRunner) runE(c *cobra.Command, args []string) error {
@@ -114,7 +156,8 @@ func (r *RunFnRunner) runE(c *cobra.Command, args []string) error {
 // Functions to run.
 func (r *RunFnRunner) getContainerFunctions(dataItems []string) (
 	[]*yaml.RNode, error) {
-	if r.Image == "" && r.StarPath == "" && r.ExecPath == "" && r.StarURL == "" {
+	if r.Image == "" && r.StarPath == "" && r.ExecPath == "" && r.StarURL == "" && r.WasmPath == "" &&
+		r.GoModule == "" {
 		return nil, nil
 	}
 
@@ -132,6 +175,10 @@ func (r *RunFnRunner) getContainerFunctions(dataItems []string) (
 		fnAnnotation, err = fnAnnotationForStar(r.StarPath, r.StarURL, r.StarName)
 	case r.EnableExec && r.ExecPath != "":
 		fnAnnotation, err = fnAnnotationForExec(r.ExecPath)
+	case r.EnableWasm && r.WasmPath != "":
+		fnAnnotation, err = fnAnnotationForWasm(r.WasmPath)
+	case r.EnableGo && r.GoModule != "":
+		fnAnnotation, err = fnAnnotationForGo(r.GoModule)
 	}
 	if err != nil {
 		return nil, err
@@ -203,6 +250,36 @@ data: {}
 	return rc, nil
 }
 
+func fnAnnotationForWasm(path string) (*yaml.RNode, error) {
+	fn, err := yaml.Parse(`wasm: {}`)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	err = fn.PipeE(
+		yaml.Lookup("wasm"),
+		yaml.SetField("path", yaml.NewScalarRNode(path)))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return fn, nil
+}
+
+func fnAnnotationForGo(module string) (*yaml.RNode, error) {
+	fn, err := yaml.Parse(`go: {}`)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	err = fn.PipeE(
+		yaml.Lookup("go"),
+		yaml.SetField("module", yaml.NewScalarRNode(module)))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return fn, nil
+}
+
 func fnAnnotationForExec(path string) (*yaml.RNode, error) {
 	fn, err := yaml.Parse(`exec: {}`)
 	if err != nil {
@@ -294,8 +371,17 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 		return errors.Errorf("must specify --enable-exec with --exec-path")
 	}
 
+	if !r.EnableWasm && r.WasmPath != "" {
+		return errors.Errorf("must specify --enable-wasm with --wasm-path")
+	}
+
+	if !r.EnableGo && r.GoModule != "" {
+		return errors.Errorf("must specify --enable-go with --go-module")
+	}
+
 	if c.ArgsLenAtDash() >= 0 && r.Image == "" &&
-		!(r.EnableStar && (r.StarPath != "" || r.StarURL != "")) && !(r.EnableExec && r.ExecPath != "") {
+		!(r.EnableStar && (r.StarPath != "" || r.StarURL != "")) && !(r.EnableExec && r.ExecPath != "") &&
+		!(r.EnableWasm && r.WasmPath != "") && !(r.EnableGo && r.GoModule != "") {
 		return errors.Errorf("must specify --image")
 	}
 
@@ -307,6 +393,18 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 	if len(args) > 1 {
 		return errors.Errorf("0 or 1 arguments supported, function arguments go after '--'")
 	}
+	if r.Source != "" && !kio.IsOCIReference(r.Source) {
+		return errors.Errorf("--source must be an oci:// reference")
+	}
+	if r.Sink != "" && !kio.IsOCIReference(r.Sink) {
+		return errors.Errorf("--sink must be an oci:// reference")
+	}
+	if r.Source != "" && len(args) == 1 {
+		return errors.Errorf("DIR may not be specified with --source")
+	}
+	if r.Sink != "" && r.DryRun {
+		return errors.Errorf("--dry-run may not be specified with --sink")
+	}
 
 	fns, err := r.getContainerFunctions(dataItems)
 	if err != nil {
@@ -323,6 +421,16 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 		output = c.OutOrStdout()
 	}
 
+	// read from and publish to OCI artifacts if specified
+	var source kio.Reader
+	var sink kio.Writer
+	if r.Source != "" {
+		source = kio.OCIReader{Reference: r.Source}
+	}
+	if r.Sink != "" {
+		sink = kio.OCIWriter{Reference: r.Sink}
+	}
+
 	// set the path if specified as an argument
 	var path string
 	if len(args) == 1 {
@@ -339,21 +447,28 @@ func (r *RunFnRunner) preRunE(c *cobra.Command, args []string) error {
 	}
 
 	r.RunFns = runfn.RunFns{
-		FunctionPaths:  r.FnPaths,
-		GlobalScope:    r.GlobalScope,
-		Functions:      fns,
-		Output:         output,
-		Input:          input,
-		Path:           path,
-		Network:        r.Network,
-		EnableStarlark: r.EnableStar,
-		EnableExec:     r.EnableExec,
-		StorageMounts:  storageMounts,
-		ResultsDir:     r.ResultsDir,
-		LogSteps:       r.LogSteps,
-		Env:            r.Env,
-		AsCurrentUser:  r.AsCurrentUser,
-		WorkingDir:     wd,
+		FunctionPaths:    r.FnPaths,
+		GlobalScope:      r.GlobalScope,
+		Functions:        fns,
+		Output:           output,
+		Input:            input,
+		Source:           source,
+		Sink:             sink,
+		Path:             path,
+		Network:          r.Network,
+		EnableStarlark:   r.EnableStar,
+		EnableExec:       r.EnableExec,
+		EnableWasm:       r.EnableWasm,
+		WasmRuntime:      r.WasmRuntime,
+		EnableGo:         r.EnableGo,
+		StorageMounts:    storageMounts,
+		ResultsDir:       r.ResultsDir,
+		LogSteps:         r.LogSteps,
+		Env:              r.Env,
+		AsCurrentUser:    r.AsCurrentUser,
+		ContainerRuntime: r.ContainerRuntime,
+		ImagePullPolicy:  r.ImagePullPolicy,
+		WorkingDir:       wd,
 	}
 
 	// don't consider args for the function
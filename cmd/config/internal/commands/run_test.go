@@ -11,6 +11,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/runfn"
 )
 
@@ -205,6 +206,59 @@ apiVersion: v1
 				WorkingDir:     wd,
 			},
 		},
+		{
+			name: "wasm",
+			args: []string{"run", "dir",
+				"--enable-wasm",
+				"--wasm-path", "fns/validate.wasm",
+				"--wasm-runtime", "wazero",
+				"--", "Foo", "g=h"},
+			path: "dir",
+			expected: `
+metadata:
+  name: function-input
+  annotations:
+    config.kubernetes.io/function: |
+      wasm: {path: fns/validate.wasm}
+data: {g: h}
+kind: Foo
+apiVersion: v1
+`,
+		},
+		{
+			name: "wasm-not-enabled",
+			args: []string{"run", "dir",
+				"--wasm-path", "fns/validate.wasm",
+				"--", "Foo", "g=h"},
+			path: "dir",
+			err:  "must specify --enable-wasm with --wasm-path",
+		},
+		{
+			name: "go",
+			args: []string{"run", "dir",
+				"--enable-go",
+				"--go-module", "example.com/fn@v1.0.0",
+				"--", "Foo", "g=h"},
+			path: "dir",
+			expected: `
+metadata:
+  name: function-input
+  annotations:
+    config.kubernetes.io/function: |
+      go: {module: example.com/fn@v1.0.0}
+data: {g: h}
+kind: Foo
+apiVersion: v1
+`,
+		},
+		{
+			name: "go-not-enabled",
+			args: []string{"run", "dir",
+				"--go-module", "example.com/fn@v1.0.0",
+				"--", "Foo", "g=h"},
+			path: "dir",
+			err:  "must specify --enable-go with --go-module",
+		},
 		{
 			name:          "function paths",
 			args:          []string{"run", "dir", "--fn-path", "path1", "--fn-path", "path2"},
@@ -305,6 +359,35 @@ apiVersion: v1
 				WorkingDir: wd,
 			},
 		},
+		{
+			name:   "oci source and sink",
+			args:   []string{"run", "--source", "oci://localhost:5000/app:v1", "--sink", "oci://localhost:5000/app:v2"},
+			input:  os.Stdin,
+			output: os.Stdout,
+			expectedStruct: &runfn.RunFns{
+				Input:      os.Stdin,
+				Output:     os.Stdout,
+				Source:     kio.OCIReader{Reference: "oci://localhost:5000/app:v1"},
+				Sink:       kio.OCIWriter{Reference: "oci://localhost:5000/app:v2"},
+				Env:        []string{},
+				WorkingDir: wd,
+			},
+		},
+		{
+			name: "source not oci",
+			args: []string{"run", "--source", "dir"},
+			err:  "--source must be an oci:// reference",
+		},
+		{
+			name: "source with dir",
+			args: []string{"run", "dir", "--source", "oci://localhost:5000/app:v1"},
+			err:  "DIR may not be specified with --source",
+		},
+		{
+			name: "sink with dry-run",
+			args: []string{"run", "dir", "--dry-run", "--sink", "oci://localhost:5000/app:v2"},
+			err:  "--dry-run may not be specified with --sink",
+		},
 		{
 			name: "as current user",
 			args: []string{"run", "dir", "--as-current-user"},
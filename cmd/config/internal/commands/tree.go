@@ -45,6 +45,9 @@ func GetTreeRunner(name string) *TreeRunner {
 		"if true, include local-config in the output.")
 	c.Flags().BoolVar(&r.excludeNonLocal, "exclude-non-local", false,
 		"if true, exclude non-local-config in the output.")
+	c.Flags().BoolVar(&r.origin, "origin", false,
+		"print the file, base or generator that produced each Resource, from the origin "+
+			"annotations of kustomize build.")
 	c.Flags().StringVar(&r.structure, "graph-structure", "",
 		"Graph structure to use for printing the tree.  may be any of: "+
 			strings.Join(kio.GraphStructures, ","))
@@ -73,6 +76,7 @@ type TreeRunner struct {
 	includeLocal    bool
 	excludeNonLocal bool
 	structure       string
+	origin          bool
 }
 
 func (r *TreeRunner) runE(c *cobra.Command, args []string) error {
@@ -163,6 +167,7 @@ func (r *TreeRunner) runE(c *cobra.Command, args []string) error {
 			Fields:          fields,
 			Structure:       kio.TreeStructure(r.structure),
 			OpenAPIFileName: ext.KRMFileName(),
+			Origin:          r.origin,
 		}},
 	}.Execute())
 }
@@ -556,3 +556,38 @@ spec:
 		return
 	}
 }
+
+func TestTreeCommand_origin(t *testing.T) {
+	b := &bytes.Buffer{}
+	r := commands.GetTreeRunner("")
+	r.Command.SetArgs([]string{"-", "--origin"})
+	r.Command.SetIn(bytes.NewBufferString(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/origin: |
+      path: ../base/deployment.yaml
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  annotations:
+    config.kubernetes.io/origin: |
+      configuredIn: kustomization.yaml
+      configuredBy:
+        apiVersion: builtin
+        kind: ConfigMapGenerator
+        name: app-config
+`))
+	r.Command.SetOut(b)
+	if !assert.NoError(t, r.Command.Execute()) {
+		return
+	}
+
+	assert.Equal(t, `.
+├── [.]  Deployment app  (from ../base/deployment.yaml)
+└── [.]  ConfigMap app-config  (generated by ConfigMapGenerator app-config in kustomization.yaml)
+`, b.String())
+}
@@ -157,7 +157,11 @@ Following is an example of running the ` + "`" + `kustomize fn run` + "`" + ` us
 When run by ` + "`" + `kustomize fn run` + "`" + `, functions are run in containers with the
 following environment:
 
-- Network: ` + "`" + `none` + "`" + `
+- Network: ` + "`" + `none` + "`" + `, or the host network for functions declaring ` + "`" + `network: true` + "`" + `
+  when run with ` + "`" + `--network` + "`" + `.  Functions declaring ` + "`" + `allowedHosts` + "`" + ` too, e.g.
+  ` + "`" + `[api.example.com, "*.googleapis.com"]` + "`" + `, run in an internal network instead,
+  from which they reach only these hosts, through the HTTP proxy of the
+  ` + "`" + `HTTP_PROXY` + "`" + ` and ` + "`" + `HTTPS_PROXY` + "`" + ` environment variables
 - User: ` + "`" + `nobody` + "`" + `
 - Security Options: ` + "`" + `no-new-privileges` + "`" + `
 - Volumes: the volume containing the ` + "`" + `functionConfig` + "`" + ` yaml is mounted under ` + "`" + `/local` + "`" + ` as ` + "`" + `ro` + "`" + `
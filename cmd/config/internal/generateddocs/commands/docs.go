@@ -153,9 +153,13 @@ var GrepLong = `
   QUERY:
     Query to match expressed as 'path.to.field=value'.
     Maps and fields are matched as '.field-name' or '.map-key'
+    Map keys containing '.' are matched as "['map.key']"
     List elements are matched as '[list-elem-field=field-value]'
-    The value to match is expressed as '=value'
+    The value to match is expressed as '=regexp', '==value' or '!=regexp'
+    Quantities are compared as '>value', '>=value', '<value' or '<=value'
     '.' as part of a key or value can be escaped as '\.'
+    Several conditions are joined as 'condition && condition'; with
+    --invert-match, Resources matching none of them are selected
 
   DIR:
     Path to local directory.
@@ -171,7 +175,10 @@ var GrepExamples = `
     kustomize cfg grep "metadata.name=nginx" my-dir/ | kustomize cfg tree
 
     # look for Resources matching a specific container image
-    kustomize cfg grep "spec.template.spec.containers[name=nginx].image=nginx:1\.7\.9" my-dir/ | kustomize cfg tree`
+    kustomize cfg grep "spec.template.spec.containers[name=nginx].image=nginx:1\.7\.9" my-dir/ | kustomize cfg tree
+
+    # find Deployments of the payments team with more than 3 replicas
+    kustomize cfg grep "kind=Deployment && spec.replicas>3 && metadata.annotations['example.com/team']==payments" my-dir/`
 
 var InitShort = `[Alpha] Initialize a directory with a Krmfile.`
 var InitLong = `
@@ -245,6 +252,58 @@ For information on merge rules, run:
 var Merge3Examples = `
     kustomize cfg merge3 --ancestor a/ --from b/ --to c/`
 
+var RenderShort = `[Alpha] Render the function pipelines of packages and their subpackages.`
+var RenderLong = `
+[Alpha] Render the function pipelines of packages and their subpackages.
+
+render hydrates the packages in a directory, the packages being the directories
+containing a Krmfile.  Each package may declare a pipeline of functions in its
+Krmfile, which is run against the Resources of the package and its subpackages.
+Packages are rendered bottom-up: the pipelines of the subpackages of a package
+are run before its own, so that it operates on their hydrated Resources.
+
+#### Arguments:
+
+  DIR:
+    Path to local directory.  Defaults to the current directory.
+
+#### Pipelines:
+
+  Mutators are run in order, and their output is written back to the package.
+  Validators are run afterwards, and their output is discarded; the rendering
+  fails if one of them fails.
+
+  Each function runs either an image as a container or, with --enable-exec, an
+  executable in the package directory.  Its function config is read from the file
+  at configPath in the package, or is a ConfigMap with the data of configMap.
+  An image may be pinned to a digest, and pulled according to imagePullPolicy,
+  which defaults to --function-pull-policy.
+
+  Example Krmfile:
+
+	apiVersion: config.k8s.io/v1alpha1
+	kind: Krmfile
+	pipeline:
+	  mutators:
+	  - image: gcr.io/example/set-labels:v1.0.1
+	    configMap:
+	      app: wordpress
+	  - exec: ./hack/set-namespace.sh
+	    configPath: namespace-fn.yaml
+	  validators:
+	  - image: gcr.io/example/kubeval@sha256:4a3f1c2d0e5b6a7980f1e2d3c4b5a6978e0f1d2c3b4a5968778695a4b3c2d1e0
+	    imagePullPolicy: IfNotPresent
+`
+var RenderExamples = `
+    # render the packages of the current directory
+    kustomize fn render
+
+    # print the hydrated Resources of my-dir/ without writing them
+    kustomize fn render my-dir/ --dry-run
+
+    # render packages with exec functions
+    kustomize fn render my-dir/ --enable-exec`
+
 var RunFnsShort = `[Alpha] Reoncile config functions to Resources.`
 var RunFnsLong = `
 [Alpha] Reconcile config functions to Resources.
@@ -290,9 +349,58 @@ order they appear in the file).
   file contents.
 
   See ` + "`" + `kustomize help cfg docs-fn` + "`" + ` for more details on writing functions.
+
+#### OCI Artifacts:
+
+  --source reads the Resources from a package published as an OCI artifact, rather
+  than from DIR or stdin, and --sink publishes the result as an OCI artifact, rather
+  than writing it to DIR or stdout, e.g. to promote a package from one registry or
+  tag to another.  The artifact has a single layer, a gzip compressed tar archive of
+  the package files.  Registry credentials are read from the docker config file, and
+  registries on the loopback interface, e.g. localhost:5000, are accessed over http.
+
+#### Image Pinning:
+
+  The image of a container function may be pinned to a digest, e.g.
+  gcr.io/example/examplefunction@sha256:<digest>, so that the function doesn't change
+  when a tag is moved.  The container field may declare an imagePullPolicy of Always,
+  IfNotPresent or Never; --function-pull-policy sets it for the functions which don't.
+  If neither is set, the default policy of the container runtime is used.
+
+#### WebAssembly Functions:
+
+  With --enable-wasm, functions may run as WebAssembly modules built for WASI rather
+  than as containers, without requiring docker:
+
+	config.kubernetes.io/function: |
+	  wasm:
+	    path: fns/validate.wasm
+
+  The module reads the ResourceList from stdin and writes it to stdout.  It's run by a
+  WebAssembly runtime CLI, wasmtime or wazero, autodetected from the PATH unless set with
+  --wasm-runtime.  The module is sandboxed: it has no access to the filesystem or the
+  network, and only sees the environment variables given by --env.  --wasm-path runs
+  a module as a function instead of discovering them.
+
+#### Go Functions:
+
+  With --enable-go, functions published as Go modules may run without requiring docker:
+
+	config.kubernetes.io/function: |
+	  go:
+	    module: example.com/functions/set-labels@v1.2.0
+
+  The module is the path of the main package of the function pinned to a version, as for
+  go run.  The function is built with the go command the first time it runs, and its
+  binary is cached by the hash of the module and the platform.  Like exec functions, Go
+  functions run on the host, unsandboxed.  --go-module runs a module as a function
+  instead of discovering them.
 `
 var RunFnsExamples = `
-kustomize fn run example/`
+kustomize fn run example/
+
+kustomize fn run --source oci://registry.example.com/team/app:v1 \
+  --sink oci://registry.example.com/team/app:v2 --image gcr.io/example/set-env:v1 -- env=prod`
 
 var SetShort = `[Alpha] Set values on Resources fields values.`
 var SetLong = `
@@ -427,6 +535,10 @@ container names, etc.
 
 kustomize cfg tree supports printing arbitrary fields using the '--field' flag.
 
+The '--origin' flag prints the file, base or generator that produced each Resource, as recorded in
+the config.kubernetes.io/origin annotation by kustomize build when the kustomization has
+'buildMetadata: [originAnnotations]'.
+
 By default, kustomize cfg tree uses Resource graph structure if any relationships between resources (ownerReferences)
 are detected, as is typically the case when printing from a cluster. Otherwise, directory graph structure is used. The
 graph structure can also be selected explicitly using the '--graph-structure' flag.
@@ -444,6 +556,9 @@ var TreeExamples = `
     # print the "foo"" annotation
     kustomize cfg tree my-dir/ --field "metadata.annotations.foo"
 
+    # print the origin of each Resource built by kustomize
+    kustomize build my-dir/ | kustomize cfg tree - --origin
+
     # print the "foo"" annotation
     kubectl get all -o yaml | kustomize cfg tree \
       --field="status.conditions[type=Completed].status"
@@ -4,6 +4,7 @@
 
 
 builtinPlugins=(AnnotationsTransformer \
+	ApplySettersTransformer \
 	ConfigMapGenerator \
 	HashTransformer \
 	ImageTagTransformer \
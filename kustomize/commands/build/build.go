@@ -4,33 +4,75 @@
 package build
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 	"sigs.k8s.io/kustomize/api/konfig"
 	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 var theArgs struct {
-	kustomizationPath string
+	kustomizationPaths []string
 }
 
 var theFlags struct {
-	outputPath string
-	enable     struct {
+	outputPath        string
+	outputFileName    string
+	outputGroupBy     string
+	outputFormat      string
+	outputList        bool
+	watch             bool
+	watchInterval     time.Duration
+	validate          string
+	validationSchemas []string
+	enable            struct {
 		plugins        bool
 		managedByLabel bool
 		helm           bool
+		allowedPaths   bool
 	}
-	helmCommand    string
-	loadRestrictor string
-	reorderOutput  string
-	fnOptions      types.FnPluginLoadingOptions
+	helmCommand          string
+	helmKubeVersion      string
+	helmApiVersions      []string
+	loadRestrictor       string
+	reorderOutput        string
+	reorderFile          string
+	envFile              string
+	reuseContainers      bool
+	cacheFnOutputs       bool
+	fnOutputCacheDir     string
+	openAPIFromCluster   bool
+	profile              string
+	filename             string
+	root                 string
+	selects              []string
+	excludes             []string
+	components           []string
+	metadata             string
+	errorFormat          string
+	vendorDir            string
+	failOnResults        string
+	fnLogLevel           string
+	resultsFile          string
+	trustedCatalogs      []string
+	execPolicy           string
+	gitCredentials       string
+	gitFetch             types.GitFetchConfig
+	remoteCacheDir       string
+	remoteCacheTTL       time.Duration
+	requirePinnedRemotes bool
+	http                 types.HTTPConfig
+	allowAbsolutePaths   bool
+	fnOptions            types.FnPluginLoadingOptions
 }
 
 type Help struct {
@@ -47,9 +89,18 @@ func MakeHelp(pgmName, cmdName string) *Help {
 		Short: "Build a kustomization target from a directory or URL",
 		Long: fmt.Sprintf(`Build a set of KRM resources using a '%s' file.
 The DIR argument must be a path to a directory containing
-'%s', or a git repository URL with a path suffix
-specifying same with respect to the repository root.
+'%s', a git repository URL with a path suffix
+specifying same with respect to the repository root,
+a reference to an OCI artifact holding a kustomization,
+e.g. oci://registry.example.com/platform/base:v1.2.3,
+the https URL of a tar.gz or zip archive pinned by its
+digest, e.g. https://example.com/config.tgz?sha256=...,
+or a directory in an S3 or GCS bucket, e.g. s3://bucket/base.
 If DIR is omitted, '.' is assumed.
+Several DIR arguments, or glob patterns matching several
+directories, build each of them in turn.  Their outputs are
+written to stdout preceded by a '# Source: DIR' comment, or
+to a subdirectory of the output path if it is a directory.
 `, fN, fN),
 		Example: fmt.Sprintf(`# Build the current working directory
   %s %s
@@ -59,7 +110,20 @@ If DIR is omitted, '.' is assumed.
 
 # Build from github
   %s %s https://github.com/kubernetes-sigs/kustomize.git/examples/helloWorld?ref=v1.0.6
-`, pgmName, cmdName, pgmName, cmdName, pgmName, cmdName),
+
+# Build all the overlays into a directory per overlay
+  %s %s 'overlays/*' -o out
+
+# Build only the deployments whose name starts with api-
+  %s %s --select 'kind=Deployment,name=api-*'
+
+# Build the production overlay with a debug sidecar component
+  %s %s overlays/production --components components/debug
+
+# Build a kustomization read from stdin, with resources relative to base/
+  echo 'resources: [deployment.yaml]' | %s %s -f - --root base
+`, pgmName, cmdName, pgmName, cmdName, pgmName, cmdName, pgmName, cmdName, pgmName, cmdName,
+			pgmName, cmdName, pgmName, cmdName),
 	}
 }
 
@@ -76,36 +140,82 @@ func NewCmdBuild(
 			if err := Validate(args); err != nil {
 				return err
 			}
-			k := krusty.MakeKustomizer(
-				HonorKustomizeFlags(krusty.MakeDefaultOptions(), cmd.Flags()),
-			)
-			m, err := k.Run(fSys, theArgs.kustomizationPath)
+			stdinContent, err := readFlagFilename(cmd.InOrStdin())
 			if err != nil {
 				return err
 			}
-			if theFlags.outputPath != "" && fSys.IsDir(theFlags.outputPath) {
-				// Ignore writer; write to o.outputPath directly.
-				return MakeWriter(fSys).WriteIndividualFiles(
-					theFlags.outputPath, m)
+			if theFlags.openAPIFromCluster {
+				if err := addSchemaFromCluster(); err != nil {
+					return err
+				}
 			}
-			yml, err := m.AsYaml()
-			if err != nil {
+			if !theFlags.watch {
+				kFSys, err := withFlagFilename(fSys, stdinContent)
+				if err != nil {
+					return err
+				}
+				err = runBuild(kFSys, cmd.Flags(), writer, cmd.ErrOrStderr())
+				if err != nil && isFlagErrorFormatJSON() {
+					// the JSON error replaces the message of the error
+					cmd.SilenceErrors = true
+					if errW := writeFlagErrorFormat(cmd.ErrOrStderr(), err); errW != nil {
+						return errW
+					}
+				}
 				return err
 			}
-			if theFlags.outputPath != "" {
-				// Ignore writer; write to o.outputPath directly.
-				return fSys.WriteFile(theFlags.outputPath, yml)
+			builds := 0
+			build := func(fSys filesys.FileSystem) error {
+				if builds > 0 && theFlags.outputPath == "" && theFlags.outputFormat == outputFormatYAML {
+					// separate the successive outputs
+					if _, err := writer.Write([]byte("---\n")); err != nil {
+						return err
+					}
+				}
+				builds++
+				kFSys, err := withFlagFilename(fSys, stdinContent)
+				if err != nil {
+					return err
+				}
+				return runBuild(kFSys, cmd.Flags(), writer, cmd.ErrOrStderr())
+			}
+			ctx := cmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
 			}
-			_, err = writer.Write(yml)
-			return err
+			watch(ctx, fSys, theFlags.watchInterval, cmd.ErrOrStderr(), build)
+			return nil
 		},
 	}
 	AddFlagOutputPath(cmd.Flags())
+	AddFlagOutputFileName(cmd.Flags())
+	AddFlagOutputFormat(cmd.Flags())
+	AddFlagWatch(cmd.Flags())
+	AddFlagValidate(cmd.Flags())
 	AddFunctionBasicsFlags(cmd.Flags())
 	AddFlagLoadRestrictor(cmd.Flags())
 	AddFlagEnablePlugins(cmd.Flags())
 	AddFlagReorderOutput(cmd.Flags())
 	AddFlagEnableManagedbyLabel(cmd.Flags())
+	AddFlagOpenAPIFromCluster(cmd.Flags())
+	AddFlagProfile(cmd.Flags())
+	AddFlagFilename(cmd.Flags())
+	AddFlagSelect(cmd.Flags())
+	AddFlagComponents(cmd.Flags())
+	AddFlagMetadata(cmd.Flags())
+	AddFlagErrorFormat(cmd.Flags())
+	AddFlagVendorDir(cmd.Flags())
+	AddFlagFnResults(cmd.Flags())
+	AddFlagFnLogLevel(cmd.Flags())
+	AddFlagTrustedCatalogs(cmd.Flags())
+	AddFlagExecPolicy(cmd.Flags())
+	AddFlagGitCredentials(cmd.Flags())
+	AddFlagGitFetch(cmd.Flags())
+	AddFlagRemoteCache(cmd.Flags())
+	AddFlagRequirePinnedRemotes(cmd.Flags())
+	AddFlagHTTP(cmd.Flags())
+	AddFlagAllowAbsolutePaths(cmd.Flags())
+	AddFlagEnableAllowedPaths(cmd.Flags())
 	msg := "Error marking flag '%s' as deprecated: %v"
 	err := cmd.Flags().MarkDeprecated(flagReorderOutputName,
 		"use the new 'sortOptions' field in kustomization.yaml instead.")
@@ -119,24 +229,193 @@ func NewCmdBuild(
 	}
 
 	AddFlagEnableHelm(cmd.Flags())
+	addCompletions(cmd, fSys)
 	return cmd
 }
 
+// runBuild builds the kustomization paths and writes the output,
+// and the profile and the report of the builds if requested.
+func runBuild(fSys filesys.FileSystem, flags *flag.FlagSet, writer, stderr io.Writer) error {
+	paths, err := expandKustomizationPaths(fSys, theArgs.kustomizationPaths)
+	if err != nil {
+		return err
+	}
+	fSys = startFlagMetadata(fSys, paths)
+	kOpts := HonorKustomizeFlags(krusty.MakeDefaultOptions(), flags)
+	if kOpts.CustomOrder, err = readFlagReorderFile(fSys); err != nil {
+		return err
+	}
+	if kOpts.GitCredentials, err = readFlagGitCredentials(fSys); err != nil {
+		return err
+	}
+	env, err := readFlagEnvFile(fSys)
+	if err != nil {
+		return err
+	}
+	// the variables of --env take precedence over those of the file
+	fnOptions := &kOpts.PluginConfig.FnpLoadingOptions
+	fnOptions.Env = append(env, fnOptions.Env...)
+	if fnOptions.Catalogs, err = readFlagTrustedCatalogs(fSys); err != nil {
+		return err
+	}
+	if fnOptions.ExecAllowlist, err = readFlagExecPolicy(fSys); err != nil {
+		return err
+	}
+	if fnOptions.OutputCache, err = makeFlagFnOutputCache(); err != nil {
+		return err
+	}
+	defer startFlagReuseContainers(fnOptions, stderr)()
+	defer startExecServers(fnOptions, stderr)()
+	results := collectFlagFnResults(fnOptions)
+	logs := collectFlagFnLogs(fnOptions)
+	kOpts.Profile = makeFlagProfile()
+	k := krusty.MakeKustomizer(kOpts)
+	err = buildPaths(fSys, k, paths, writer)
+	writeFlagFnLogs(stderr, logs)
+	// the results of the functions may explain a failed build
+	if errR := writeFlagFnResults(fSys, stderr, results); errR != nil && err == nil {
+		err = errR
+	}
+	// the profile of a failed build shows how far it got
+	if errP := writeFlagProfile(fSys, stderr, kOpts.Profile); errP != nil && err == nil {
+		err = errP
+	}
+	if err != nil {
+		return err
+	}
+	return writeFlagMetadata(fSys, kOpts.Profile)
+}
+
+// buildPaths builds the kustomization paths with k and writes the output.
+func buildPaths(fSys filesys.FileSystem, k *krusty.Kustomizer, paths []string, writer io.Writer) error {
+	if len(paths) > 1 {
+		return buildMultiple(fSys, k, paths, writer)
+	}
+	m, err := k.Run(fSys, paths[0])
+	if err != nil {
+		return err
+	}
+	if err := filterFlagSelect(m); err != nil {
+		return err
+	}
+	if err := recordFlagMetadata(paths[0], m); err != nil {
+		return err
+	}
+	return writeOutput(fSys, writer, theFlags.outputPath, m)
+}
+
+// writeOutput writes the resources of m to the output path, which may
+// be a directory, or to writer if the output path is empty.
+func writeOutput(
+	fSys filesys.FileSystem, writer io.Writer, outputPath string, m resmap.ResMap) error {
+	if outputPath != "" && fSys.IsDir(outputPath) {
+		if !isDefaultOutputFormat() {
+			return fmt.Errorf("--%s and --%s are not supported when --output is a directory",
+				flagOutputFormatName, flagOutputListName)
+		}
+		// Ignore writer; write to outputPath directly.
+		if template := getFlagOutputFileName(); template != "" {
+			return MakeWriter(fSys).WriteTemplatedFiles(outputPath, template, m)
+		}
+		return MakeWriter(fSys).WriteIndividualFiles(outputPath, m)
+	}
+	if getFlagOutputFileName() != "" {
+		return fmt.Errorf("--%s and --%s require --output to be a directory",
+			flagOutputFileNameName, flagOutputGroupByName)
+	}
+	out, err := formatOutput(m)
+	if err != nil {
+		return err
+	}
+	if outputPath != "" {
+		// Ignore writer; write to outputPath directly.
+		return fSys.WriteFile(outputPath, out)
+	}
+	_, err = writer.Write(out)
+	return err
+}
+
+// flagFilePath returns the absolute path of the file of the flag.  The
+// file may be anywhere, not only below the current directory.
+func flagFilePath(fSys filesys.FileSystem, flagName, path string) (string, error) {
+	dir, name, err := fSys.CleanedAbs(path)
+	if err != nil {
+		return "", fmt.Errorf("reading --%s %s: %w", flagName, path, err)
+	}
+	return filepath.Join(dir.String(), name), nil
+}
+
+// readFlagFile returns the content of the file of the flag.
+func readFlagFile(fSys filesys.FileSystem, flagName, path string) ([]byte, error) {
+	abs, err := flagFilePath(fSys, flagName, path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := fSys.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("reading --%s %s: %w", flagName, path, err)
+	}
+	return content, nil
+}
+
 // Validate validates build command args and flags.
 func Validate(args []string) error {
-	if len(args) > 1 {
-		return fmt.Errorf(
-			"specify one path to " +
-				konfig.DefaultKustomizationFileName())
-	}
 	if len(args) == 0 {
-		theArgs.kustomizationPath = filesys.SelfDir
+		theArgs.kustomizationPaths = []string{filesys.SelfDir}
 	} else {
-		theArgs.kustomizationPath = args[0]
+		theArgs.kustomizationPaths = args
+	}
+	if err := validateFlagFilename(args); err != nil {
+		return err
 	}
 	if err := validateFlagLoadRestrictor(); err != nil {
 		return err
 	}
+	if err := validateFlagOutputFileName(); err != nil {
+		return err
+	}
+	if err := validateFlagOutputFormat(); err != nil {
+		return err
+	}
+	if err := validateFlagWatch(); err != nil {
+		return err
+	}
+	if err := validateFlagValidate(); err != nil {
+		return err
+	}
+	if err := validateFlagSelect(); err != nil {
+		return err
+	}
+	if err := validateFlagErrorFormat(); err != nil {
+		return err
+	}
+	if err := validateFlagFunctionPullPolicy(); err != nil {
+		return err
+	}
+	if err := validateFlagMounts(); err != nil {
+		return err
+	}
+	if err := validateFlagWasmRuntime(); err != nil {
+		return err
+	}
+	if err := validateFlagFnLimits(); err != nil {
+		return err
+	}
+	if err := validateFlagContainerIsolation(); err != nil {
+		return err
+	}
+	if err := validateFlagFnResults(); err != nil {
+		return err
+	}
+	if err := validateFlagFnLogLevel(); err != nil {
+		return err
+	}
+	if err := validateFlagRemoteCache(); err != nil {
+		return err
+	}
+	if err := validateFlagHTTP(); err != nil {
+		return err
+	}
 	return validateFlagReorderOutput()
 }
 
@@ -153,6 +432,19 @@ func HonorKustomizeFlags(kOpts *krusty.Options, flags *flag.FlagSet) *krusty.Opt
 		kOpts.PluginConfig.HelmConfig.Enabled = theFlags.enable.helm
 	}
 	kOpts.PluginConfig.HelmConfig.Command = theFlags.helmCommand
+	kOpts.PluginConfig.HelmConfig.KubeVersion = theFlags.helmKubeVersion
+	kOpts.PluginConfig.HelmConfig.ApiVersions = theFlags.helmApiVersions
 	kOpts.AddManagedbyLabel = isManagedByLabelEnabled()
+	kOpts.Validation = getFlagValidate()
+	kOpts.ValidationSchemaPaths = theFlags.validationSchemas
+	kOpts.Components = theFlags.components
+	kOpts.VendorDir = theFlags.vendorDir
+	kOpts.GitFetch = theFlags.gitFetch
+	kOpts.RemoteCacheDir = theFlags.remoteCacheDir
+	kOpts.RemoteCacheTTL = theFlags.remoteCacheTTL
+	kOpts.RequirePinnedRemotes = theFlags.requirePinnedRemotes
+	kOpts.HTTP = getFlagHTTP()
+	kOpts.AllowAbsolutePaths = theFlags.allowAbsolutePaths
+	kOpts.EnableAllowedPaths = theFlags.enable.allowedPaths
 	return kOpts
 }
@@ -5,14 +5,32 @@ package build_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
 	"sigs.k8s.io/kustomize/api/provenance"
+	"sigs.k8s.io/kustomize/api/types"
 	. "sigs.k8s.io/kustomize/kustomize/v5/commands/build"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework/command"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 func loadFileSystem(fSys filesys.FileSystem) {
@@ -197,6 +215,363 @@ type: Opaque
 	}
 }
 
+func TestBuildWithTemplatedOutput(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	fSys.Mkdir("someDir")
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("output", "someDir")
+	cmd.Flags().Set("output-filename", "{namespace}/{kind}-{name}.yaml")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fSys.ReadFile("someDir/_cluster/namespace-ns1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "kind: Namespace") {
+		t.Fatalf("Unexpected namespace file:\n%s\n", string(data))
+	}
+	data, err = fSys.ReadFile("someDir/ns1/deployment-foo-dply1-bar.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "name: foo-dply1-bar") {
+		t.Fatalf("Unexpected deployment file:\n%s\n", string(data))
+	}
+}
+
+func TestBuildWithGroupedOutput(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	fSys.Mkdir("someDir")
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("output", "someDir")
+	cmd.Flags().Set("output-group-by", "namespace")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := fSys.ReadFile("someDir/ns1.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := expectedContent[strings.Index(expectedContent, "---\n")+len("---\n"):]
+	if string(data) != expected {
+		t.Fatalf("Expected:\n%s\nBut got:\n%s\n", expected, string(data))
+	}
+	if !fSys.Exists("someDir/_cluster.yaml") {
+		t.Fatal("expected a file with the cluster-scoped resources")
+	}
+}
+
+func TestBuildWithTemplatedOutput_invalid(t *testing.T) {
+	var cases = map[string]struct {
+		flags map[string]string
+		erMsg string
+	}{
+		"unknownPlaceholder": {
+			map[string]string{"output-filename": "{kind}-{uid}.yaml"},
+			`unknown placeholder {uid} in file name template "{kind}-{uid}.yaml"`,
+		},
+		"parentDirectory": {
+			map[string]string{"output-filename": "../{kind}.yaml"},
+			`file name template "../{kind}.yaml" must not refer to a parent directory`,
+		},
+		"unknownGroup": {
+			map[string]string{"output-group-by": "label"},
+			"illegal flag value --output-group-by label; legal values: [kind namespace]",
+		},
+		"bothFlags": {
+			map[string]string{"output-group-by": "kind", "output-filename": "{kind}.yaml"},
+			"--output-filename and --output-group-by are mutually exclusive",
+		},
+		"notADirectory": {
+			map[string]string{"output": "out.yaml", "output-filename": "{kind}.yaml"},
+			"--output-filename and --output-group-by require --output to be a directory",
+		},
+	}
+	for n := range cases {
+		tc := cases[n]
+		t.Run(n, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			loadFileSystem(fSys)
+			buffy := new(bytes.Buffer)
+			cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+			for k, v := range tc.flags {
+				cmd.Flags().Set(k, v)
+			}
+			err := cmd.RunE(cmd, []string{})
+			if err == nil || err.Error() != tc.erMsg {
+				t.Fatalf("Expected error %q, but got %v", tc.erMsg, err)
+			}
+		})
+	}
+}
+
+func TestBuildWithOutputFormat(t *testing.T) {
+	var cases = map[string]struct {
+		flags    map[string]string
+		expected string
+	}{
+		"json": {
+			map[string]string{"output-format": "json"},
+			`{
+  "apiVersion": "v1",
+  "data": {
+    "a": "b"
+  },
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "cm1"
+  }
+}
+{
+  "apiVersion": "v1",
+  "kind": "Service",
+  "metadata": {
+    "name": "svc1"
+  }
+}
+`,
+		},
+		"jsonlines": {
+			map[string]string{"output-format": "jsonlines"},
+			`{"apiVersion":"v1","data":{"a":"b"},"kind":"ConfigMap","metadata":{"name":"cm1"}}
+{"apiVersion":"v1","kind":"Service","metadata":{"name":"svc1"}}
+`,
+		},
+		"jsonList": {
+			map[string]string{"output-format": "json", "output-list": "true"},
+			`{
+  "apiVersion": "v1",
+  "items": [
+    {
+      "apiVersion": "v1",
+      "data": {
+        "a": "b"
+      },
+      "kind": "ConfigMap",
+      "metadata": {
+        "name": "cm1"
+      }
+    },
+    {
+      "apiVersion": "v1",
+      "kind": "Service",
+      "metadata": {
+        "name": "svc1"
+      }
+    }
+  ],
+  "kind": "List"
+}
+`,
+		},
+		"yamlList": {
+			map[string]string{"output-list": "true"},
+			`apiVersion: v1
+items:
+- apiVersion: v1
+  data:
+    a: b
+  kind: ConfigMap
+  metadata:
+    name: cm1
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: svc1
+kind: List
+`,
+		},
+	}
+	for n := range cases {
+		tc := cases[n]
+		t.Run(n, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- resources.yaml
+`))
+			fSys.WriteFile("resources.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+data:
+  a: b
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: svc1
+`))
+			buffy := new(bytes.Buffer)
+			cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+			for k, v := range tc.flags {
+				cmd.Flags().Set(k, v)
+			}
+			if err := cmd.RunE(cmd, []string{}); err != nil {
+				t.Fatal(err)
+			}
+			if buffy.String() != tc.expected {
+				t.Fatalf("Expected output:\n%s\n But got output:\n%s", tc.expected, buffy)
+			}
+		})
+	}
+}
+
+func TestBuildWithOutputFormat_invalid(t *testing.T) {
+	var cases = map[string]struct {
+		flags map[string]string
+		erMsg string
+	}{
+		"unknownFormat": {
+			map[string]string{"output-format": "toml"},
+			"illegal flag value --output-format toml; legal values: [yaml json jsonlines]",
+		},
+		"jsonLinesList": {
+			map[string]string{"output-format": "jsonlines", "output-list": "true"},
+			"--output-list is not supported by --output-format jsonlines",
+		},
+		"directory": {
+			map[string]string{"output": "someDir", "output-format": "json"},
+			"--output-format and --output-list are not supported when --output is a directory",
+		},
+	}
+	for n := range cases {
+		tc := cases[n]
+		t.Run(n, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			loadFileSystem(fSys)
+			fSys.Mkdir("someDir")
+			buffy := new(bytes.Buffer)
+			cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+			for k, v := range tc.flags {
+				cmd.Flags().Set(k, v)
+			}
+			err := cmd.RunE(cmd, []string{})
+			if err == nil || err.Error() != tc.erMsg {
+				t.Fatalf("Expected error %q, but got %v", tc.erMsg, err)
+			}
+		})
+	}
+}
+
+func loadOverlays(fSys filesys.FileSystem) {
+	fSys.WriteFile("base/"+konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- configmap.yaml
+`))
+	fSys.WriteFile("base/configmap.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`))
+	for _, overlay := range []string{"dev", "prod"} {
+		fSys.WriteFile("overlays/"+overlay+"/"+konfig.DefaultKustomizationFileName(), []byte(`
+namePrefix: `+overlay+`-
+resources:
+- ../../base
+`))
+	}
+}
+
+func TestBuildMultiple(t *testing.T) {
+	const expected = `# Source: overlays/dev
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dev-cm
+---
+# Source: overlays/prod
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: prod-cm
+`
+	for name, args := range map[string][]string{
+		"paths": {"overlays/dev", "overlays/prod"},
+		"glob":  {"overlays/*"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			loadOverlays(fSys)
+			buffy := new(bytes.Buffer)
+			cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+			if err := cmd.RunE(cmd, args); err != nil {
+				t.Fatal(err)
+			}
+			if buffy.String() != expected {
+				t.Fatalf("Expected output:\n%s\n But got output:\n%s", expected, buffy)
+			}
+		})
+	}
+}
+
+func TestBuildMultipleWithShardedOutput(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadOverlays(fSys)
+	fSys.Mkdir("someDir")
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("output", "someDir")
+	if err := cmd.RunE(cmd, []string{"overlays/*"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, overlay := range []string{"dev", "prod"} {
+		data, err := fSys.ReadFile("someDir/overlays/" + overlay + "/v1_configmap_" + overlay + "-cm.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "name: "+overlay+"-cm") {
+			t.Fatalf("Unexpected output of %s:\n%s\n", overlay, string(data))
+		}
+	}
+}
+
+func TestBuildWithValidation(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- deployment.yaml
+`))
+	fSys.WriteFile("deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+  replica: 2
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("validate", "true")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatalf("unknown fields should only be warnings: %v", err)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("validate", "strict")
+	err := cmd.RunE(cmd, []string{})
+	const expected = `validation failed:
+  deployment.yaml: Deployment.v1.apps/app.[noNs]: spec.replica: unknown field`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected error %q, but got %v", expected, err)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("validate", "loose")
+	err = cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "illegal flag value --validate loose") {
+		t.Fatalf("Expected an illegal flag value error, but got %v", err)
+	}
+}
+
 func TestHelp(t *testing.T) {
 	fSys := filesys.MakeFsInMemory()
 	buffy := new(bytes.Buffer)
@@ -224,9 +599,8 @@ func TestValidation(t *testing.T) {
 		"dotArg":    {[]string{"."}, "unable to find one of "},
 		"file":      {[]string{"beans"}, "'beans' doesn't exist"},
 		"directory": {[]string{"a/b/c"}, "'a/b/c' doesn't exist"},
-		"tooManyArgs": {[]string{"too", "many"},
-			"specify one path to " +
-				konfig.DefaultKustomizationFileName()},
+		"twoArgs":   {[]string{"too", "many"}, "building too: "},
+		"glob":      {[]string{"a/*"}, `no kustomization directories match "a/*"`},
 	}
 	for n := range cases {
 		tc := cases[n]
@@ -249,3 +623,1325 @@ func TestValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildWithProfile(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	buffy := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.SetErr(stderr)
+	cmd.Flags().Set("profile", "-")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if buffy.String() != expectedContent {
+		t.Fatalf("Expected output:\n%s\n But got output:\n%s", expectedContent, buffy)
+	}
+	for _, expected := range []string{"accumulation", "ConfigMapGenerator", "SecretGenerator",
+		"PatchJson6902Transformer", "NamespaceTransformer", "build"} {
+		if !strings.Contains(stderr.String(), expected) {
+			t.Errorf("Expected %q in the profile, but got:\n%s", expected, stderr)
+		}
+	}
+
+	buffy.Reset()
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("profile", "profile.json")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := fSys.ReadFile("profile.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"kind": "build"`) {
+		t.Fatalf("Expected the JSON profile of the build, but got:\n%s", content)
+	}
+}
+
+func TestBuildWithMetadata(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("metadata", "report.json")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if buffy.String() != expectedContent {
+		t.Fatalf("Expected output:\n%s\n But got output:\n%s", expectedContent, buffy)
+	}
+	content, err := fSys.ReadFile("report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Paths  []string
+		Inputs []struct {
+			Path   string
+			SHA256 string
+		}
+		Remotes []string
+		Plugins []struct {
+			Kind    string
+			Name    string
+			Builtin bool
+		}
+		Warnings  []string
+		Resources []struct {
+			Source string
+			Kind   string
+			Name   string
+			SHA256 string
+		}
+	}
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatal(err)
+	}
+	var inputs []string
+	for _, in := range report.Inputs {
+		if !strings.HasPrefix(in.SHA256, "sha256:") {
+			t.Errorf("Expected the hash of %s, but got %q", in.Path, in.SHA256)
+		}
+		inputs = append(inputs, in.Path)
+	}
+	expectedInputs := "[/deployment.yaml /jsonpatch.json /kustomization.yaml /namespace.yaml]"
+	if fmt.Sprint(inputs) != expectedInputs {
+		t.Errorf("Expected inputs %s, but got %v", expectedInputs, inputs)
+	}
+	if fmt.Sprint(report.Paths) != "[.]" || len(report.Remotes) != 0 {
+		t.Errorf("Expected the path . without remotes, but got %v and %v", report.Paths, report.Remotes)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "patchesJson6902") {
+		t.Errorf("Expected a warning about patchesJson6902, but got %v", report.Warnings)
+	}
+	var plugins []string
+	for _, p := range report.Plugins {
+		if !p.Builtin {
+			t.Errorf("Expected builtin plugins, but got %+v", p)
+		}
+		plugins = append(plugins, p.Kind+" "+p.Name)
+	}
+	for _, expected := range []string{"generator ConfigMapGenerator", "transformer NamespaceTransformer"} {
+		if !strings.Contains(strings.Join(plugins, ","), expected) {
+			t.Errorf("Expected plugin %q, but got %v", expected, plugins)
+		}
+	}
+	var resources []string
+	for _, r := range report.Resources {
+		resources = append(resources, r.Source+" "+r.Kind+"/"+r.Name)
+	}
+	expectedResources := "[. Namespace/ns1 . ConfigMap/foo-literalConfigMap-bar-g5f6t456f5 " +
+		". Secret/foo-secret-bar-82c2g5f8f6 . Deployment/foo-dply1-bar]"
+	if fmt.Sprint(resources) != expectedResources {
+		t.Errorf("Expected resources %s, but got %v", expectedResources, resources)
+	}
+}
+
+func TestBuildFromStdin(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	// the kustomization file of the root is replaced
+	fSys.WriteFile("kustomization.yml", []byte("resources: [missing.yaml]\n"))
+	kustomization, err := fSys.ReadFile(konfig.DefaultKustomizationFileName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fSys.RemoveAll(konfig.DefaultKustomizationFileName())
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.SetIn(bytes.NewReader(kustomization))
+	cmd.Flags().Set("filename", "-")
+	cmd.Flags().Set("root", ".")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if buffy.String() != expectedContent {
+		t.Fatalf("Expected output:\n%s\n But got output:\n%s", expectedContent, buffy)
+	}
+}
+
+func TestBuildFromFile(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("app/deployment.yaml", []byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`))
+	fSys.WriteFile("app/prod.yaml", []byte(`
+resources:
+- deployment.yaml
+namePrefix: prod-
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("filename", "app/prod.yaml")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	const expected = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: prod-app
+`
+	if buffy.String() != expected {
+		t.Fatalf("Expected output:\n%s\n But got output:\n%s", expected, buffy)
+	}
+
+	for _, tc := range []struct {
+		args        []string
+		flags       map[string]string
+		expectedErr string
+	}{
+		{[]string{"app"}, nil, "--filename can't be used with a DIR argument, use --root instead"},
+		{nil, map[string]string{"watch": "true"}, "--watch can't watch a kustomization read from stdin"},
+		{nil, map[string]string{"root": "missing"}, "not a valid directory"},
+	} {
+		cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+		cmd.SetIn(strings.NewReader(""))
+		cmd.Flags().Set("filename", "-")
+		for name, value := range tc.flags {
+			cmd.Flags().Set(name, value)
+		}
+		err := cmd.RunE(cmd, tc.args)
+		if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+			t.Errorf("expected error %q, but got %v", tc.expectedErr, err)
+		}
+	}
+}
+
+func TestBuildWithSelect(t *testing.T) {
+	var cases = map[string]struct {
+		selects  []string
+		excludes []string
+		expected []string
+	}{
+		"kind": {
+			selects:  []string{"kind=Namespace"},
+			expected: []string{"Namespace"},
+		},
+		"pattern": {
+			selects:  []string{"kind=*Map,name=foo-*", "group=apps"},
+			expected: []string{"ConfigMap", "Deployment"},
+		},
+		"exclude": {
+			excludes: []string{"kind=Secret", "namespace=ns1,group="},
+			expected: []string{"Namespace", "Deployment"},
+		},
+		"selectAndExclude": {
+			selects:  []string{"namespace=ns1"},
+			excludes: []string{"kind=Deployment"},
+			expected: []string{"ConfigMap", "Secret"},
+		},
+		"none": {
+			selects: []string{"name=missing"},
+		},
+	}
+	for n := range cases {
+		tc := cases[n]
+		t.Run(n, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			loadFileSystem(fSys)
+			buffy := new(bytes.Buffer)
+			cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+			for _, s := range tc.selects {
+				cmd.Flags().Set("select", s)
+			}
+			for _, s := range tc.excludes {
+				cmd.Flags().Set("exclude", s)
+			}
+			if err := cmd.RunE(cmd, []string{}); err != nil {
+				t.Fatal(err)
+			}
+			var kinds []string
+			for _, line := range strings.Split(buffy.String(), "\n") {
+				if strings.HasPrefix(line, "kind: ") {
+					kinds = append(kinds, strings.TrimPrefix(line, "kind: "))
+				}
+			}
+			if fmt.Sprint(kinds) != fmt.Sprint(tc.expected) {
+				t.Fatalf("Expected kinds %v, but got %v in output:\n%s", tc.expected, kinds, buffy)
+			}
+		})
+	}
+}
+
+func TestBuildWithSelect_invalid(t *testing.T) {
+	var cases = map[string]struct {
+		flag  string
+		value string
+		erMsg string
+	}{
+		"noValue": {
+			"select", "kind",
+			`invalid --select "kind"; expected comma-separated key=pattern terms`,
+		},
+		"unknownKey": {
+			"exclude", "kind=Secret,label=app",
+			`invalid --exclude "kind=Secret,label=app"; unknown key "label", legal keys: [group version kind name namespace]`,
+		},
+		"badPattern": {
+			"select", "name=[",
+			`invalid --select "name=["; bad pattern "[": syntax error in pattern`,
+		},
+	}
+	for n := range cases {
+		tc := cases[n]
+		t.Run(n, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			loadFileSystem(fSys)
+			cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+			cmd.Flags().Set(tc.flag, tc.value)
+			err := cmd.RunE(cmd, []string{})
+			if err == nil || err.Error() != tc.erMsg {
+				t.Fatalf("Expected error %q, but got %v", tc.erMsg, err)
+			}
+		})
+	}
+}
+
+func TestBuildWithComponents(t *testing.T) {
+	const expected = `# Source: overlays/dev
+apiVersion: v1
+data:
+  debug: "true"
+kind: ConfigMap
+metadata:
+  name: dev-cm
+---
+# Source: overlays/prod
+apiVersion: v1
+data:
+  debug: "true"
+kind: ConfigMap
+metadata:
+  name: prod-cm
+`
+	fSys := filesys.MakeFsInMemory()
+	loadOverlays(fSys)
+	fSys.WriteFile("components/debug/"+konfig.DefaultKustomizationFileName(), []byte(`
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+patches:
+- patch: |-
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: cm
+    data:
+      debug: "true"
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("components", "components/debug")
+	if err := cmd.RunE(cmd, []string{"overlays/*"}); err != nil {
+		t.Fatal(err)
+	}
+	if buffy.String() != expected {
+		t.Fatalf("Expected output:\n%s\n But got output:\n%s", expected, buffy)
+	}
+}
+
+func TestBuildWithCustomReorder(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	if err := fSys.WriteFile("order.yaml", []byte(`
+- kind: Secret
+- kind: '*'
+- group: apps
+  kind: Deployment
+`)); err != nil {
+		t.Fatal(err)
+	}
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("reorder", "custom")
+	cmd.Flags().Set("reorder-file", "order.yaml")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, line := range strings.Split(buffy.String(), "\n") {
+		if strings.HasPrefix(line, "kind: ") {
+			kinds = append(kinds, strings.TrimPrefix(line, "kind: "))
+		}
+	}
+	expected := []string{"Secret", "Namespace", "ConfigMap", "Deployment"}
+	if fmt.Sprint(kinds) != fmt.Sprint(expected) {
+		t.Fatalf("Expected kinds %v, but got %v in output:\n%s", expected, kinds, buffy)
+	}
+}
+
+func TestBuildWithCustomReorder_invalid(t *testing.T) {
+	var cases = map[string]struct {
+		reorder string
+		file    string
+		content string
+		erMsg   string
+	}{
+		"noFile": {
+			"custom", "", "",
+			"--reorder custom requires --reorder-file",
+		},
+		"notCustom": {
+			"legacy", "order.yaml", "- kind: Secret\n",
+			"--reorder-file requires --reorder custom",
+		},
+		"empty": {
+			"custom", "order.yaml", "",
+			"--reorder-file order.yaml is empty",
+		},
+		"notList": {
+			"custom", "order.yaml", "kind: Secret\n",
+			"invalid --reorder-file order.yaml, expected a list of GVKs: " +
+				"yaml: unmarshal errors:\n  line 1: cannot unmarshal !!map into []resid.Gvk",
+		},
+		"severalWildcards": {
+			"custom", "order.yaml", "- kind: '*'\n- kind: Secret\n- kind: '*'\n",
+			"invalid --reorder-file order.yaml: several entries of kind '*'",
+		},
+	}
+	for n := range cases {
+		tc := cases[n]
+		t.Run(n, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			loadFileSystem(fSys)
+			if err := fSys.WriteFile("order.yaml", []byte(tc.content)); err != nil {
+				t.Fatal(err)
+			}
+			cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+			cmd.Flags().Set("reorder", tc.reorder)
+			cmd.Flags().Set("reorder-file", tc.file)
+			err := cmd.RunE(cmd, []string{})
+			if err == nil || err.Error() != tc.erMsg {
+				t.Fatalf("Expected error %q, but got %v", tc.erMsg, err)
+			}
+		})
+	}
+}
+
+func TestHelmCapabilityFlags(t *testing.T) {
+	cmd := NewCmdBuild(filesys.MakeFsInMemory(), MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("enable-helm", "true")
+	cmd.Flags().Set("helm-kube-version", "1.27.0")
+	cmd.Flags().Set("helm-api-versions", "monitoring.coreos.com/v1,policy/v1")
+	kOpts := HonorKustomizeFlags(krusty.MakeDefaultOptions(), cmd.Flags())
+	helm := kOpts.PluginConfig.HelmConfig
+	if helm.KubeVersion != "1.27.0" {
+		t.Errorf("Expected kube version 1.27.0, but got %q", helm.KubeVersion)
+	}
+	expected := []string{"monitoring.coreos.com/v1", "policy/v1"}
+	if fmt.Sprint(helm.ApiVersions) != fmt.Sprint(expected) {
+		t.Errorf("Expected api versions %v, but got %v", expected, helm.ApiVersions)
+	}
+}
+
+func TestRemoteCacheFlags(t *testing.T) {
+	cmd := NewCmdBuild(filesys.MakeFsInMemory(), MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("remote-cache-dir", "/cache/kustomize")
+	kOpts := HonorKustomizeFlags(krusty.MakeDefaultOptions(), cmd.Flags())
+	if kOpts.RemoteCacheDir != "/cache/kustomize" || kOpts.RemoteCacheTTL != time.Hour {
+		t.Errorf("Expected the remote cache /cache/kustomize with a ttl of 1h, but got %q with %s",
+			kOpts.RemoteCacheDir, kOpts.RemoteCacheTTL)
+	}
+
+	cmd.Flags().Set("remote-cache-ttl", "-1m")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || err.Error() != "--remote-cache-ttl must not be negative" {
+		t.Fatalf("Expected an error about the negative ttl, but got %v", err)
+	}
+}
+
+func TestGitFetchFlags(t *testing.T) {
+	cmd := NewCmdBuild(filesys.MakeFsInMemory(), MakeHelp("foo", "bar"), new(bytes.Buffer))
+	kOpts := HonorKustomizeFlags(krusty.MakeDefaultOptions(), cmd.Flags())
+	if kOpts.GitFetch != (types.GitFetchConfig{}) {
+		t.Errorf("Expected the default git fetch configuration, but got %+v", kOpts.GitFetch)
+	}
+
+	cmd.Flags().Set("git-fetch-depth", "-1")
+	cmd.Flags().Set("git-fetch-all-branches", "true")
+	cmd.Flags().Set("git-fetch-tags", "true")
+	kOpts = HonorKustomizeFlags(krusty.MakeDefaultOptions(), cmd.Flags())
+	expected := types.GitFetchConfig{Depth: -1, AllBranches: true, Tags: true}
+	if kOpts.GitFetch != expected {
+		t.Errorf("Expected the git fetch configuration %+v, but got %+v", expected, kOpts.GitFetch)
+	}
+	for _, name := range []string{"git-fetch-depth", "git-fetch-all-branches", "git-fetch-tags"} {
+		cmd.Flags().Set(name, cmd.Flags().Lookup(name).DefValue)
+	}
+}
+
+func TestRequirePinnedRemotesFlag(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	if err := fSys.WriteFile("kustomization.yaml", []byte(`resources:
+- https://github.com/org/repo//base?ref=main
+`)); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("require-pinned-remotes", "true")
+	if kOpts := HonorKustomizeFlags(krusty.MakeDefaultOptions(), cmd.Flags()); !kOpts.RequirePinnedRemotes {
+		t.Errorf("Expected pinned remotes to be required")
+	}
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "must be referenced by the full hash of a commit") {
+		t.Fatalf("Expected an error about the branch, but got %v", err)
+	}
+	cmd.Flags().Set("require-pinned-remotes", "false")
+}
+
+func TestAllowAbsolutePathsFlag(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	if err := fSys.WriteFile("app/kustomization.yaml", []byte(`resources:
+- /nix/store/abc/configmap.yaml
+`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := fSys.WriteFile("/nix/store/abc/configmap.yaml", []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`)); err != nil {
+		t.Fatal(err)
+	}
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	if err := cmd.RunE(cmd, []string{"app"}); err == nil || !strings.Contains(err.Error(), "is not in or below") {
+		t.Fatalf("Expected an error about the absolute path, but got %v", err)
+	}
+	cmd.Flags().Set("allow-absolute-paths", "true")
+	if err := cmd.RunE(cmd, []string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffy.String(), "name: config") {
+		t.Fatalf("Unexpected output:\n%s", buffy)
+	}
+	cmd.Flags().Set("allow-absolute-paths", "false")
+}
+
+func TestHTTPFlags(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("proxy", "http://proxy.example.com:3128")
+	cmd.Flags().Set("no-proxy", "internal.example.com,10.0.0.0/8")
+	if err := Validate([]string{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kOpts := HonorKustomizeFlags(krusty.MakeDefaultOptions(), cmd.Flags())
+	if kOpts.HTTP.Proxy != "http://proxy.example.com:3128" ||
+		strings.Join(kOpts.HTTP.NoProxy, ",") != "internal.example.com,10.0.0.0/8" {
+		t.Errorf("Expected the proxy of the flags, but got %v", kOpts.HTTP)
+	}
+
+	cmd.Flags().Set("proxy", "proxy.example.com")
+	err := Validate([]string{})
+	if err == nil || !strings.Contains(err.Error(), `invalid proxy "proxy.example.com"`) {
+		t.Errorf("Expected an error about the proxy, but got %v", err)
+	}
+
+	cmd.Flags().Set("proxy", "http://proxy.example.com:3128")
+	cmd.Flags().Set("ca-file", "/missing/ca.pem")
+	err = Validate([]string{})
+	if err == nil || !strings.Contains(err.Error(), "reading CA file") {
+		t.Errorf("Expected an error about the CA file, but got %v", err)
+	}
+	cmd.Flags().Set("proxy", "")
+	for _, name := range []string{"no-proxy", "ca-file"} {
+		cmd.Flags().Lookup(name).Value.(pflag.SliceValue).Replace([]string{})
+	}
+}
+
+func TestBuildWithEnvFile(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	dir := t.TempDir()
+	fSys := filesys.MakeFsOnDisk()
+	for name, content := range map[string]string{
+		"kustomization.yaml": `resources:
+- configmap.yaml
+transformers:
+- fn.yaml
+`,
+		"configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  team: TEAM
+  region: REGION
+`,
+		"fn.yaml": `apiVersion: example.com/v1
+kind: EnvTransformer
+metadata:
+  name: env
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: ./fn.sh
+`,
+		"fn.sh":  "#!/bin/sh\nsed -e \"s/TEAM/$TEAM/\" -e \"s/REGION/$REGION/\"\n",
+		"ci.env": "# the environment of the CI\nTEAM=payments\nREGION=eu\n",
+	} {
+		if err := fSys.WriteFile(filepath.Join(dir, name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(dir, "fn.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	AddFunctionAlphaEnablementFlags(cmd.Flags())
+	cmd.Flags().Set("enable-alpha-plugins", "true")
+	cmd.Flags().Set("enable-exec", "true")
+	cmd.Flags().Set("env-file", filepath.Join(dir, "ci.env"))
+	cmd.Flags().Set("env", "REGION=us")
+	if err := cmd.RunE(cmd, []string{dir}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+data:
+  region: us
+  team: payments
+kind: ConfigMap
+metadata:
+  name: config
+`
+	if buffy.String() != expected {
+		t.Fatalf("Expected output:\n%s\nbut got:\n%s", expected, buffy)
+	}
+}
+
+func TestBuildWithFnResults(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	dir := t.TempDir()
+	fSys := filesys.MakeFsOnDisk()
+	for name, content := range map[string]string{
+		"kustomization.yaml": "resources:\n- configmap.yaml\ntransformers:\n- fn.yaml\n",
+		"configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+		"fn.yaml": `apiVersion: example.com/v1
+kind: Linter
+metadata:
+  name: lint
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: ./fn.sh
+`,
+		// the function passes the resources through and warns about them
+		"fn.sh": `#!/bin/sh
+cat
+echo 'results:'
+echo '- message: data is empty'
+echo '  severity: warning'
+`,
+	} {
+		if err := fSys.WriteFile(filepath.Join(dir, name), []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(dir, "fn.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	resultsFile := filepath.Join(dir, "results.yaml")
+	for failOn, expectedErr := range map[string]string{
+		"never":   "",
+		"error":   "",
+		"warning": "1 function results of severity warning or above",
+	} {
+		stderr := new(bytes.Buffer)
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+		cmd.SetErr(stderr)
+		AddFunctionAlphaEnablementFlags(cmd.Flags())
+		cmd.Flags().Set("enable-alpha-plugins", "true")
+		cmd.Flags().Set("enable-exec", "true")
+		cmd.Flags().Set("fail-on-results", failOn)
+		cmd.Flags().Set("results-file", resultsFile)
+		err := cmd.RunE(cmd, []string{dir})
+		if expectedErr == "" && err != nil {
+			t.Fatalf("--fail-on-results %s: unexpected error %v", failOn, err)
+		}
+		if expectedErr != "" && (err == nil || err.Error() != expectedErr) {
+			t.Fatalf("--fail-on-results %s: expected error %q, got %v", failOn, expectedErr, err)
+		}
+		if stderr.String() != "./fn.sh: [warning]: data is empty\n" {
+			t.Fatalf("unexpected stderr %q", stderr)
+		}
+		b, err := fSys.ReadFile(resultsFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "- function: ./fn.sh\n  message: data is empty\n  severity: warning\n"
+		if string(b) != expected {
+			t.Fatalf("expected results file:\n%s\nbut got:\n%s", expected, b)
+		}
+	}
+}
+
+func TestBuildWithErrorFormatJSON(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile("base/kustomization.yaml", []byte("resources:\n- missing.yaml\n"))
+	fSys.WriteFile("overlay/kustomization.yaml", []byte("resources:\n- ../base\n"))
+	stderr := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.SetErr(stderr)
+	cmd.Flags().Set("error-format", "json")
+	err := cmd.RunE(cmd, []string{"overlay"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !cmd.SilenceErrors {
+		t.Fatal("expected the error message to be silenced")
+	}
+	var actual struct {
+		Code          string
+		Message       string
+		Chain         []string
+		Kustomization string
+		Field         string
+		Path          []struct {
+			Kustomization string
+			Field         string
+		}
+	}
+	if err := json.Unmarshal(stderr.Bytes(), &actual); err != nil {
+		t.Fatalf("invalid JSON error %q: %v", stderr, err)
+	}
+	if actual.Code != "ResourceLoadFailed" || actual.Message != err.Error() ||
+		actual.Kustomization != "/base/kustomization.yaml" || actual.Field != "resources" {
+		t.Fatalf("unexpected JSON error %s", stderr)
+	}
+	if len(actual.Chain) < 2 || actual.Chain[0] != err.Error() {
+		t.Fatalf("unexpected error chain %q", actual.Chain)
+	}
+	if len(actual.Path) != 2 ||
+		actual.Path[0].Kustomization != "/overlay/kustomization.yaml" ||
+		actual.Path[1].Kustomization != "/base/kustomization.yaml" {
+		t.Fatalf("unexpected error path %+v", actual.Path)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("error-format", "xml")
+	err = cmd.RunE(cmd, []string{"overlay"})
+	if err == nil || !strings.Contains(err.Error(), "illegal flag value --error-format xml") {
+		t.Fatalf("expected an error about the illegal error format, got %v", err)
+	}
+}
+
+func TestBuildFunctionPullPolicy(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("function-pull-policy", "Sometimes")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(),
+		"illegal flag value --function-pull-policy Sometimes; legal values: [Always IfNotPresent Never]") {
+		t.Fatalf("expected an error about the illegal pull policy, got %v", err)
+	}
+
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("function-pull-policy", "Never")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestHelperFunction is the function the fake container runtime of the
+// tests runs in its containers, appending its pid to the pids annotation
+// of the resources.
+func TestHelperFunction(t *testing.T) {
+	if os.Getenv("BUILD_TEST_FUNCTION") != "1" {
+		t.Skip("only run as the function of the tests")
+	}
+	cmd := command.Build(framework.SimpleProcessor{Filter: kio.FilterFunc(
+		func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+			for _, n := range nodes {
+				pids := n.GetAnnotations()["pids"] + strconv.Itoa(os.Getpid()) + ","
+				if err := n.PipeE(yaml.SetAnnotation("pids", pids)); err != nil {
+					return nil, err
+				}
+			}
+			return nodes, nil
+		})}, command.StandaloneDisabled, false)
+	// the arguments of the function follow --
+	for i, arg := range os.Args {
+		if arg == "--" {
+			cmd.SetArgs(os.Args[i+1:])
+		}
+	}
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// writeTestFiles writes the files to dir, making the scripts executable.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		mode := os.FileMode(0o600)
+		if strings.HasPrefix(content, "#!") {
+			mode = 0o700
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// installFakeRuntimes writes the scripts of the fake runtimes to a directory
+// put first on the PATH.
+func installFakeRuntimes(t *testing.T, runtimes map[string]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake runtimes are shell scripts")
+	}
+	dir := t.TempDir()
+	writeTestFiles(t, dir, runtimes)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// fakeDocker returns a fake docker recording its commands in log, and
+// running TestHelperFunction as the function of its containers.
+func fakeDocker(log string) string {
+	function := "BUILD_TEST_FUNCTION=1 exec \"" + os.Args[0] + "\" -test.run=^TestHelperFunction$ --"
+	return `#!/bin/sh
+echo "$*" >> "` + log + `"
+case "$1" in
+create) echo id-$$ ;;
+start) ` + function + ` --serve ;;
+run) ` + function + ` ;;
+esac
+`
+}
+
+// containerFnFiles are the files of a kustomization transforming a
+// ConfigMap with two container functions of the same image.
+var containerFnFiles = map[string]string{
+	"kustomization.yaml": "resources:\n- configmap.yaml\ntransformers:\n- first.yaml\n- second.yaml\n",
+	"configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+	"first.yaml":         containerFn("first"),
+	"second.yaml":        containerFn("second"),
+}
+
+func containerFn(name string) string {
+	return `apiVersion: example.com/v1
+kind: AddPid
+metadata:
+  name: ` + name + `
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: example.com/add-pid:v1
+`
+}
+
+// runContainerFnBuild builds the kustomization of containerFnFiles in dir
+// with the flags, and returns the pids of the functions which ran.
+func runContainerFnBuild(t *testing.T, dir string, flags map[string]string) []string {
+	t.Helper()
+	fSys := filesys.MakeFsOnDisk()
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("enable-alpha-plugins", "true")
+	cmd.Flags().Set("container-runtime", "docker")
+	for name, value := range flags {
+		cmd.Flags().Set(name, value)
+	}
+	if err := cmd.RunE(cmd, []string{dir}); err != nil {
+		t.Fatal(err)
+	}
+	node, err := yaml.Parse(buffy.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(node.GetAnnotations()["pids"], ","), ",")
+}
+
+// readLines returns the lines of the file, or none if it doesn't exist.
+func readLines(t *testing.T, name string) []string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+func TestBuildMounts(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("mount", "type=bind,src=charts,dst=/charts,readonly=yes")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(),
+		`invalid --mount: invalid mount "type=bind,src=charts,dst=/charts,readonly=yes": option readonly must be true or false`) {
+		t.Fatalf("expected an error about the invalid mount, got %v", err)
+	}
+
+	// the source of the mount is relative to the kustomization root
+	dir := t.TempDir()
+	log := filepath.Join(t.TempDir(), "docker.log")
+	installFakeRuntimes(t, map[string]string{"docker": fakeDocker(log)})
+	writeTestFiles(t, dir, containerFnFiles)
+	runContainerFnBuild(t, dir, map[string]string{"mount": "type=bind,src=charts,dst=/charts,rw=true"})
+	mount := "--mount type=bind,source=" + filepath.Join(dir, "charts") + ",target=/charts "
+	calls := readLines(t, log)
+	if len(calls) != 2 {
+		t.Fatalf("expected two runs of containers, got %q", calls)
+	}
+	for _, call := range calls {
+		if !strings.HasPrefix(call, "run ") || !strings.Contains(call, mount) {
+			t.Fatalf("expected the functions to be run with %q, got %q", mount, call)
+		}
+	}
+}
+
+func TestBuildWasmRuntime(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("wasm-runtime", "wasmer")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(),
+		"illegal flag value --wasm-runtime wasmer; legal values: [wasmtime wazero]") {
+		t.Fatalf("expected an error about the illegal wasm runtime, got %v", err)
+	}
+
+	// the fake runtimes set the runtime of the ConfigMap to their name
+	installFakeRuntimes(t, map[string]string{
+		"wasmtime": "#!/bin/sh\nsed s/RUNTIME/wasmtime/\n",
+		"wazero":   "#!/bin/sh\nsed s/RUNTIME/wazero/\n",
+	})
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"kustomization.yaml": "resources:\n- configmap.yaml\ntransformers:\n- fn.yaml\n",
+		"configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\ndata:\n  runtime: RUNTIME\n",
+		"fn.yaml": `apiVersion: example.com/v1
+kind: SetRuntime
+metadata:
+  name: set-runtime
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: set-runtime.wasm
+`,
+	})
+	// wasmtime is preferred unless --wasm-runtime selects another runtime
+	for wasmRuntime, expected := range map[string]string{"": "wasmtime", "wazero": "wazero"} {
+		buffy := new(bytes.Buffer)
+		cmd := NewCmdBuild(filesys.MakeFsOnDisk(), MakeHelp("foo", "bar"), buffy)
+		AddFunctionAlphaEnablementFlags(cmd.Flags())
+		cmd.Flags().Set("enable-alpha-plugins", "true")
+		cmd.Flags().Set("enable-wasm", "true")
+		cmd.Flags().Set("wasm-runtime", wasmRuntime)
+		if err := cmd.RunE(cmd, []string{dir}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buffy.String(), "runtime: "+expected+"\n") {
+			t.Fatalf("--wasm-runtime %q: expected the function to be run by %s, got:\n%s",
+				wasmRuntime, expected, buffy)
+		}
+	}
+}
+
+func TestBuildReuseContainers(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(t.TempDir(), "docker.log")
+	installFakeRuntimes(t, map[string]string{"docker": fakeDocker(log)})
+	writeTestFiles(t, dir, containerFnFiles)
+
+	// each function runs in its own container
+	pids := runContainerFnBuild(t, dir, nil)
+	if len(pids) != 2 || pids[0] == pids[1] {
+		t.Fatalf("expected the functions to run in two containers, got pids %v", pids)
+	}
+	if calls := readLines(t, log); len(calls) != 2 {
+		t.Fatalf("expected two runs of containers, got %q", calls)
+	}
+	if err := os.Remove(log); err != nil {
+		t.Fatal(err)
+	}
+
+	// the functions are sent to the same container, removed after the build
+	pids = runContainerFnBuild(t, dir, map[string]string{"reuse-containers": "true"})
+	if len(pids) != 2 || pids[0] != pids[1] {
+		t.Fatalf("expected the functions to run in the same container, got pids %v", pids)
+	}
+	var commands []string
+	for _, call := range readLines(t, log) {
+		commands = append(commands, strings.Fields(call)[0])
+	}
+	if strings.Join(commands, " ") != "create start rm" {
+		t.Fatalf("expected the container to be created, started and removed once, got %q", commands)
+	}
+}
+
+func TestBuildFunctionOutputCache(t *testing.T) {
+	dir := t.TempDir()
+	runs := filepath.Join(t.TempDir(), "runs.log")
+	if runtime.GOOS == "windows" {
+		t.Skip("the function is a shell script")
+	}
+	writeTestFiles(t, dir, map[string]string{
+		"kustomization.yaml": "resources:\n- configmap.yaml\ntransformers:\n- fn.yaml\n",
+		"configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+		"fn.yaml": `apiVersion: example.com/v1
+kind: Passthrough
+metadata:
+  name: passthrough
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: ./fn.sh
+`,
+		// the function records its runs
+		"fn.sh": "#!/bin/sh\necho run >> \"" + runs + "\"\ncat\n",
+	})
+	cacheDir := t.TempDir()
+	build := func() string {
+		t.Helper()
+		buffy := new(bytes.Buffer)
+		cmd := NewCmdBuild(filesys.MakeFsOnDisk(), MakeHelp("foo", "bar"), buffy)
+		AddFunctionAlphaEnablementFlags(cmd.Flags())
+		cmd.Flags().Set("enable-alpha-plugins", "true")
+		cmd.Flags().Set("enable-exec", "true")
+		cmd.Flags().Set("function-output-cache-dir", cacheDir)
+		if err := cmd.RunE(cmd, []string{dir}); err != nil {
+			t.Fatal(err)
+		}
+		return buffy.String()
+	}
+
+	// the second build reuses the output of the first one
+	first := build()
+	if second := build(); second != first {
+		t.Fatalf("expected the cached output:\n%s\nbut got:\n%s", first, second)
+	}
+	if n := len(readLines(t, runs)); n != 1 {
+		t.Fatalf("expected the function to run once, got %d runs", n)
+	}
+
+	// a change of the input runs the function again
+	writeTestFiles(t, dir, map[string]string{
+		"configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: other\n",
+	})
+	build()
+	if n := len(readLines(t, runs)); n != 2 {
+		t.Fatalf("expected the function to run again, got %d runs", n)
+	}
+}
+
+func TestBuildFnLogLevel(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("function-log-level", "verbose")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "illegal flag value --function-log-level verbose") {
+		t.Fatalf("expected an illegal flag value error, got %v", err)
+	}
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("function-log-level", "warning")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildFnLimits(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("function-memory", "lots")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), `invalid function limits: invalid memory limit "lots"`) {
+		t.Fatalf("expected an invalid memory limit error, got %v", err)
+	}
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("function-timeout", "0")
+	cmd.Flags().Set("function-cpus", "1.5")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildContainerIsolation(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("container-isolation", "vm")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || err.Error() != "illegal flag value --container-isolation vm; legal values: [process hyperv]" {
+		t.Fatalf("expected an illegal flag value error, got %v", err)
+	}
+	// the isolation only applies to windows containers
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("container-isolation", "process")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildTrustedCatalog(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	for name, content := range map[string]string{
+		"app/kustomization.yaml": "resources:\n- configmap.yaml\ntransformers:\n- set-team.yaml\n",
+		"app/configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n",
+		"app/set-team.yaml":      "apiVersion: example.co/v1\nkind: SetTeam\nmetadata:\n  name: set-team\n",
+		"catalogs/example-co.yaml": `apiVersion: config.kubernetes.io/v1alpha1
+kind: Catalog
+metadata:
+  name: example-co-functions
+spec:
+  krmFunctions:
+  - group: example.co
+    names:
+      kind: SetTeam
+    versions:
+    - name: v1
+      runtime:
+        container:
+          image: docker.example.co/functions/set-team:v1.0.0
+          requireNetwork: true
+`,
+		"catalogs/invalid.yaml": "apiVersion: v1\nkind: ConfigMap\n",
+	} {
+		if err := fSys.WriteFile(name, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for catalog, expectedErr := range map[string]string{
+		// the function of the catalog requires the network, which isn't enabled
+		"catalogs/example-co.yaml": "network required but not enabled",
+		"catalogs/invalid.yaml": "reading --trusted-catalog catalogs/invalid.yaml: invalid catalog: " +
+			"expected apiVersion config.kubernetes.io/v1alpha1 and kind Catalog, got v1 ConfigMap",
+	} {
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+		cmd.Flags().Set("enable-alpha-plugins", "true")
+		cmd.Flags().Set("trusted-catalog", catalog)
+		err := cmd.RunE(cmd, []string{"app"})
+		if err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Fatalf("--trusted-catalog %s: expected an error containing %q, got %v", catalog, expectedErr, err)
+		}
+	}
+}
+
+func TestBuildExecPolicy(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	fSys.WriteFile("policies/exec.yaml", []byte(`apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: ExecPolicy
+allowedExecutables:
+- path: /usr/local/bin/set-team
+  sha256: a428de44a9059f31a59237a5881c2d2cffa93757d99026156e4ea544577ab7f3
+`))
+	fSys.WriteFile("policies/relative.yaml", []byte(`apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: ExecPolicy
+allowedExecutables:
+- path: bin/set-team
+  sha256: a428de44a9059f31a59237a5881c2d2cffa93757d99026156e4ea544577ab7f3
+`))
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("exec-policy", "policies/exec.yaml")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for policy, expectedErr := range map[string]string{
+		"policies/relative.yaml": "reading --exec-policy policies/relative.yaml: invalid exec policy: " +
+			`path "bin/set-team" of an allowed executable isn't absolute`,
+		"policies/missing.yaml": "reading --exec-policy policies/missing.yaml",
+	} {
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+		cmd.Flags().Set("exec-policy", policy)
+		err := cmd.RunE(cmd, []string{})
+		if err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Fatalf("--exec-policy %s: expected an error containing %q, got %v", policy, expectedErr, err)
+		}
+	}
+}
+
+func TestBuildGitCredentials(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	fSys.WriteFile("git/credentials.yaml", []byte(`apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: GitCredentials
+credentials:
+- host: github.com/my-org
+  tokenEnv: GITHUB_TOKEN
+- host: gitlab.example.com
+  sshKeyPath: /keys/deploy
+`))
+	fSys.WriteFile("git/both.yaml", []byte(`apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: GitCredentials
+credentials:
+- host: github.com
+  token: secret
+  tokenEnv: GITHUB_TOKEN
+`))
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+	cmd.Flags().Set("git-credentials", "git/credentials.yaml")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for credentials, expectedErr := range map[string]string{
+		"git/both.yaml": "reading --git-credentials git/both.yaml: invalid git credentials: " +
+			"git credential of github.com may have either a token or a tokenEnv, not both",
+		"git/missing.yaml": "reading --git-credentials git/missing.yaml",
+	} {
+		cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), new(bytes.Buffer))
+		cmd.Flags().Set("git-credentials", credentials)
+		err := cmd.RunE(cmd, []string{})
+		if err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Fatalf("--git-credentials %s: expected an error containing %q, got %v", credentials, expectedErr, err)
+		}
+	}
+}
+
+func TestBuildCompletion(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	loadFileSystem(fSys)
+	fSys.WriteFile("overlays/prod/kustomization.yaml", []byte{})
+	testCases := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"dir": {
+			args:     []string{"ov"},
+			expected: "overlays/\n:6\n",
+		},
+		"load restrictor": {
+			args:     []string{"--load-restrictor", "LoadRestrictionsN"},
+			expected: "LoadRestrictionsNone\n:4\n",
+		},
+		"output format": {
+			args:     []string{"--output-format", "j"},
+			expected: "json\njsonlines\n:4\n",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			root := &cobra.Command{Use: "kustomize"}
+			root.AddCommand(NewCmdBuild(fSys, MakeHelp("kustomize", "build"), out))
+			root.SetOut(out)
+			root.SetArgs(append([]string{cobra.ShellCompRequestCmd, "build"}, tc.args...))
+			if err := root.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if actual := out.String(); !strings.HasPrefix(actual, tc.expected) {
+				t.Errorf("expected completions %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBuildWithOpenAPIFromCluster(t *testing.T) {
+	defer openapi.ResetOpenAPI()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/apiextensions.k8s.io/v1/customresourcedefinitions" ||
+			r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"items": [{"spec": {
+  "group": "example.com",
+  "names": {"kind": "Widget"},
+  "scope": "Namespaced",
+  "versions": [{"name": "v1", "schema": {"openAPIV3Schema": {
+    "type": "object",
+    "properties": {"spec": {"type": "object", "properties": {"ports": {
+      "type": "array",
+      "x-kubernetes-list-type": "map",
+      "x-kubernetes-list-map-keys": ["port"],
+      "items": {"type": "object", "properties": {"port": {"type": "integer"}}}
+    }}}}
+  }}}]
+}}]}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte("token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	kubeconfig := filepath.Join(dir, "config")
+	if err := os.WriteFile(kubeconfig, []byte(`
+current-context: test
+contexts:
+- name: test
+  context: {cluster: test, user: test}
+clusters:
+- name: test
+  cluster: {server: `+server.URL+`}
+users:
+- name: test
+  user: {tokenFile: token}
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "missing")+string(filepath.ListSeparator)+kubeconfig)
+
+	fSys := filesys.MakeFsInMemory()
+	fSys.WriteFile(konfig.DefaultKustomizationFileName(), []byte(`
+resources:
+- widget.yaml
+patches:
+- patch: |-
+    apiVersion: example.com/v1
+    kind: Widget
+    metadata:
+      name: w
+    spec:
+      ports:
+      - port: 443
+`))
+	fSys.WriteFile("widget.yaml", []byte(`
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  ports:
+  - port: 80
+`))
+	buffy := new(bytes.Buffer)
+	cmd := NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("openapi-from-cluster", "true")
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	const expected = `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  ports:
+  - port: 443
+  - port: 80
+`
+	if buffy.String() != expected {
+		t.Fatalf("Expected output:\n%s\n But got output:\n%s", expected, buffy)
+	}
+
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "missing"))
+	cmd = NewCmdBuild(fSys, MakeHelp("foo", "bar"), buffy)
+	cmd.Flags().Set("openapi-from-cluster", "true")
+	err := cmd.RunE(cmd, []string{})
+	if err == nil || err.Error() != "--openapi-from-cluster: no kubeconfig found" {
+		t.Fatalf("Expected a missing kubeconfig error, but got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/util"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/wasm"
+)
+
+// addCompletions completes the DIR arguments with the directories containing
+// a kustomization file, and the flags having a fixed set of legal values.
+func addCompletions(cmd *cobra.Command, fSys filesys.FileSystem) {
+	cmd.ValidArgsFunction = util.CompleteKustomizationDirs(fSys)
+	for name, f := range map[string]util.CompletionFunc{
+		flagComponentsName: util.CompleteKustomizationDirs(fSys),
+		flagLoadRestrictorName: util.CompleteValues(types.LoadRestrictionsRootOnly.String(),
+			types.LoadRestrictionsRootOfRepo.String(), types.LoadRestrictionsNone.String()),
+		flagOutputFormatName:       util.CompleteValues(outputFormatYAML, outputFormatJSON, outputFormatJSONLines),
+		flagOutputGroupByName:      util.CompleteValues(groupByKind, groupByNamespace),
+		flagErrorFormatName:        util.CompleteValues(errorFormatText, errorFormatJSON),
+		flagValidateName:           util.CompleteValues(validateTrue, validateFalse, validateStrict),
+		flagFunctionPullPolicyName: util.CompleteValues(runtimeutil.ImagePullPolicies...),
+		flagWasmRuntimeName:        util.CompleteValues(wasm.Runtimes...),
+		flagFailOnResultsName: util.CompleteValues(string(framework.Error), string(framework.Warning),
+			string(framework.Info), failOnResultsNever),
+		flagReorderOutputName: util.CompleteValues(string(krusty.ReorderOptionLegacy),
+			string(krusty.ReorderOptionNone), string(krusty.ReorderOptionCustom)),
+	} {
+		if err := cmd.RegisterFlagCompletionFunc(name, f); err != nil {
+			log.Fatalf("Error registering the completion of flag '%s': %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const flagAllowAbsolutePathsName = "allow-absolute-paths"
+
+func AddFlagAllowAbsolutePaths(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.allowAbsolutePaths,
+		flagAllowAbsolutePathsName,
+		false,
+		"Allow resources, patches, bases and components to be absolute paths or file:// urls,"+
+			" e.g. of inputs materialized by build systems like Bazel or Nix, which are loaded"+
+			" in place regardless of --"+flagLoadRestrictorName+".")
+}
@@ -0,0 +1,20 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const flagComponentsName = "components"
+
+func AddFlagComponents(set *pflag.FlagSet) {
+	set.StringSliceVar(
+		&theFlags.components,
+		flagComponentsName,
+		nil,
+		"Components to apply on top of the kustomization, after its own components,"+
+			" e.g. to enable optional features without editing it."+
+			" Paths are relative to the current directory, or git repository urls.")
+}
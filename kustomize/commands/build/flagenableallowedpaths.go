@@ -0,0 +1,19 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const flagEnableAllowedPathsName = "enable-allowed-paths"
+
+func AddFlagEnableAllowedPaths(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.enable.allowedPaths,
+		flagEnableAllowedPathsName,
+		false,
+		"Let kustomizations read the files below the directories of their allowedPaths field,"+
+			" outside of their root, regardless of --"+flagLoadRestrictorName+".")
+}
@@ -21,4 +21,14 @@ func AddFlagEnableHelm(set *pflag.FlagSet) {
 		"helm-command",
 		"helm", // default
 		"helm command (path to executable)")
+	set.StringVar(
+		&theFlags.helmKubeVersion,
+		"helm-kube-version",
+		"",
+		"Kubernetes version of the helm charts which don't set a kubeVersion, e.g. 1.27.0")
+	set.StringSliceVar(
+		&theFlags.helmApiVersions,
+		"helm-api-versions",
+		nil,
+		"Kubernetes api versions of the helm charts which don't set apiVersions, e.g. monitoring.coreos.com/v1")
 }
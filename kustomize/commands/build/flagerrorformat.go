@@ -0,0 +1,106 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagErrorFormatName = "error-format"
+
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+
+	// errorCodeBuildFailed is the code of the errors which don't
+	// locate their cause in a kustomization.
+	errorCodeBuildFailed = "BuildFailed"
+)
+
+func AddFlagErrorFormat(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.errorFormat,
+		flagErrorFormatName,
+		errorFormatText,
+		"Format of the error of a failed build. Use '"+errorFormatJSON+"' to write it to stderr"+
+			" as a JSON object with a stable error code, the kustomization and field involved,"+
+			" and the path of kustomizations leading to it.")
+}
+
+func validateFlagErrorFormat() error {
+	switch theFlags.errorFormat {
+	case errorFormatText, errorFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagErrorFormatName, theFlags.errorFormat,
+			[]string{errorFormatText, errorFormatJSON})
+	}
+}
+
+// isFlagErrorFormatJSON returns true if errors are written as JSON.
+func isFlagErrorFormatJSON() bool {
+	return theFlags.errorFormat == errorFormatJSON
+}
+
+// buildErrorJSON is the JSON object written for a failed build.
+type buildErrorJSON struct {
+	// Code is the code of the innermost build error.
+	Code string `json:"code"`
+	// Message is the message of the error.
+	Message string `json:"message"`
+	// Chain is the messages of the errors wrapped by the error.
+	Chain []string `json:"chain"`
+	// Kustomization is the kustomization file of the innermost build error.
+	Kustomization string `json:"kustomization,omitempty"`
+	Field         string `json:"field,omitempty"`
+	Step          string `json:"step,omitempty"`
+	// Path is the kustomizations from the one built to the one of the
+	// innermost build error, and the fields leading from each one to
+	// the next.
+	Path []buildErrorLocation `json:"path,omitempty"`
+}
+
+type buildErrorLocation struct {
+	Kustomization string `json:"kustomization"`
+	Field         string `json:"field,omitempty"`
+}
+
+// writeFlagErrorFormat writes err to stderr as JSON.
+func writeFlagErrorFormat(stderr io.Writer, err error) error {
+	result := buildErrorJSON{
+		Code:    errorCodeBuildFailed,
+		Message: err.Error(),
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if n := len(result.Chain); n == 0 || result.Chain[n-1] != e.Error() {
+			result.Chain = append(result.Chain, e.Error())
+		}
+	}
+	for _, e := range krusty.BuildErrors(err) {
+		location := buildErrorLocation{
+			Kustomization: filepath.Join(e.Root, e.File),
+			Field:         e.Field,
+		}
+		result.Path = append(result.Path, location)
+		result.Code = string(e.Code)
+		result.Kustomization = location.Kustomization
+		result.Field = e.Field
+		result.Step = e.Step
+	}
+	out, errJ := json.MarshalIndent(result, "", "  ")
+	if errJ != nil {
+		return errJ
+	}
+	_, errW := stderr.Write(append(out, '\n'))
+	return errW
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+)
+
+const flagExecPolicyName = "exec-policy"
+
+func AddFlagExecPolicy(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.execPolicy,
+		flagExecPolicyName,
+		"",
+		"A file of an ExecPolicy restricting the executables of exec functions and exec plugins"+
+			" to those it allows by absolute path and sha256 digest. The binaries built for go"+
+			" functions, and the runtimes and modules of wasm functions, are restricted too.")
+}
+
+// readFlagExecPolicy returns the allowed executables of the policy of the
+// exec policy flag, or nil if it isn't set.
+func readFlagExecPolicy(fSys filesys.FileSystem) ([]exec.AllowedExecutable, error) {
+	if theFlags.execPolicy == "" {
+		return nil, nil
+	}
+	content, err := readFlagFile(fSys, flagExecPolicyName, theFlags.execPolicy)
+	if err != nil {
+		return nil, err
+	}
+	p, err := types.ParseExecPolicy(content)
+	if err != nil {
+		return nil, fmt.Errorf("reading --%s %s: %w", flagExecPolicyName, theFlags.execPolicy, err)
+	}
+	return p.AllowedExecutables, nil
+}
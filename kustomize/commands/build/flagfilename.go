@@ -0,0 +1,164 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	flagFilenameName = "filename"
+	flagRootName     = "root"
+
+	// filenameStdin reads the kustomization from stdin.
+	filenameStdin = "-"
+)
+
+func AddFlagFilename(set *pflag.FlagSet) {
+	set.StringVarP(
+		&theFlags.filename,
+		flagFilenameName,
+		"f",
+		"",
+		"Build the kustomization in this file instead of the kustomization file of DIR,"+
+			" or read from stdin if '"+filenameStdin+"'.  Its resources are resolved"+
+			" relative to --"+flagRootName+".")
+	set.StringVar(
+		&theFlags.root,
+		flagRootName,
+		"",
+		"Directory the kustomization of --"+flagFilenameName+" is built in."+
+			" Defaults to the directory of the file, or the current directory for stdin.")
+}
+
+func validateFlagFilename(args []string) error {
+	if theFlags.filename == "" {
+		if theFlags.root != "" {
+			return fmt.Errorf("--%s requires --%s", flagRootName, flagFilenameName)
+		}
+		return nil
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("--%s can't be used with a DIR argument, use --%s instead",
+			flagFilenameName, flagRootName)
+	}
+	if theFlags.filename == filenameStdin && theFlags.watch {
+		return fmt.Errorf("--%s can't watch a kustomization read from stdin", flagWatchName)
+	}
+	root := theFlags.root
+	if root == "" {
+		root = filesys.SelfDir
+		if theFlags.filename != filenameStdin {
+			root = filepath.Dir(theFlags.filename)
+		}
+	}
+	theArgs.kustomizationPaths = []string{root}
+	return nil
+}
+
+// readFlagFilename reads the kustomization from stdin if requested.
+func readFlagFilename(stdin io.Reader) ([]byte, error) {
+	if theFlags.filename != filenameStdin {
+		return nil, nil
+	}
+	content, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, fmt.Errorf("reading the kustomization from stdin: %w", err)
+	}
+	return content, nil
+}
+
+// withFlagFilename returns fSys, in which the kustomization file of the
+// build root is replaced with the kustomization of the filename flag.
+// stdinContent is the kustomization read from stdin, if any.
+func withFlagFilename(fSys filesys.FileSystem, stdinContent []byte) (filesys.FileSystem, error) {
+	if theFlags.filename == "" {
+		return fSys, nil
+	}
+	root, err := filesys.ConfirmDir(fSys, theArgs.kustomizationPaths[0])
+	if err != nil {
+		return nil, err
+	}
+	if theFlags.filename != filenameStdin && !fSys.Exists(theFlags.filename) {
+		return nil, fmt.Errorf("kustomization file %q not found", theFlags.filename)
+	}
+	return &kustomizationFs{
+		FileSystem: fSys,
+		root:       root,
+		path:       theFlags.filename,
+		content:    stdinContent,
+	}, nil
+}
+
+// kustomizationFs is a FileSystem in which the kustomization file of
+// root is read from another file, or is given content.  The other
+// kustomization files of root are hidden.
+type kustomizationFs struct {
+	filesys.FileSystem
+
+	root    filesys.ConfirmedDir
+	path    string
+	content []byte
+}
+
+// kustomizationFileName returns the name of the kustomization file
+// of root at path, or the empty string if path isn't one.
+func (fs *kustomizationFs) kustomizationFileName(path string) string {
+	name := filepath.Base(path)
+	recognized := false
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		recognized = recognized || n == name
+	}
+	if !recognized {
+		return ""
+	}
+	dir, f, err := fs.FileSystem.CleanedAbs(filepath.Dir(path))
+	if err != nil || f != "" || dir != fs.root {
+		return ""
+	}
+	return name
+}
+
+// CleanedAbs implements FileSystem, and confirms the kustomization
+// file of root even if it doesn't exist.
+func (fs *kustomizationFs) CleanedAbs(path string) (filesys.ConfirmedDir, string, error) {
+	if name := fs.kustomizationFileName(path); name != "" {
+		return fs.root, name, nil
+	}
+	return fs.FileSystem.CleanedAbs(path)
+}
+
+// Exists implements FileSystem.
+func (fs *kustomizationFs) Exists(path string) bool {
+	switch fs.kustomizationFileName(path) {
+	case "":
+		return fs.FileSystem.Exists(path)
+	case konfig.DefaultKustomizationFileName():
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadFile implements FileSystem.
+func (fs *kustomizationFs) ReadFile(path string) ([]byte, error) {
+	switch fs.kustomizationFileName(path) {
+	case "":
+		return fs.FileSystem.ReadFile(path)
+	case konfig.DefaultKustomizationFileName():
+		if fs.path == filenameStdin {
+			return fs.content, nil
+		}
+		return fs.FileSystem.ReadFile(fs.path)
+	default:
+		return nil, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+}
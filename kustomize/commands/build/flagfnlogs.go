@@ -0,0 +1,95 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+const (
+	flagFnLogLevelName = "function-log-level"
+
+	fnLogLevelNone = "none"
+)
+
+func AddFlagFnLogLevel(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.fnLogLevel,
+		flagFnLogLevelName,
+		runtimeutil.LogLevelInfo,
+		"Print the lines functions write to their stderr at this level or above to stderr once the"+
+			" build is done, with the function and time of each: "+
+			fmt.Sprint(append(append([]string{}, runtimeutil.LogLevels...), fnLogLevelNone))+
+			". Lines without a level prefix such as 'WARN:' are at level "+runtimeutil.LogLevelInfo+
+			". The lines are recorded in the report of --"+flagMetadataName+" too.")
+}
+
+func validateFlagFnLogLevel() error {
+	if fnLogLevelIndex(theFlags.fnLogLevel) < 0 {
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagFnLogLevelName, theFlags.fnLogLevel,
+			append(append([]string{}, runtimeutil.LogLevels...), fnLogLevelNone))
+	}
+	return nil
+}
+
+// fnLogLevelIndex returns the severity of level, or -1 if it's unknown.
+func fnLogLevelIndex(level string) int {
+	if level == fnLogLevelNone {
+		return len(runtimeutil.LogLevels)
+	}
+	for i, l := range runtimeutil.LogLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// fnLogs collects the lines the functions of a build write to their
+// stderr, which may be written concurrently.
+type fnLogs struct {
+	mu      sync.Mutex
+	records []runtimeutil.LogRecord
+}
+
+// collectFlagFnLogs sets the options of the functions to collect the
+// lines of their stderr at the level of the log level flag or above in
+// the returned fnLogs, rather than interleaving them with the stderr of
+// the build.
+func collectFlagFnLogs(o *types.FnPluginLoadingOptions) *fnLogs {
+	logs := &fnLogs{}
+	minLevel := fnLogLevelIndex(theFlags.fnLogLevel)
+	o.LogFunc = func(record runtimeutil.LogRecord) {
+		if fnLogLevelIndex(record.Level) < minLevel {
+			return
+		}
+		logs.mu.Lock()
+		defer logs.mu.Unlock()
+		logs.records = append(logs.records, record)
+	}
+	return logs
+}
+
+// writeFlagFnLogs prints the logs to stderr, and records them in the
+// report of the metadata flag.
+func writeFlagFnLogs(stderr io.Writer, logs *fnLogs) {
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	for _, r := range logs.records {
+		fmt.Fprintf(stderr, "%s %s %s: %s\n",
+			r.Time.Format(time.RFC3339), r.Level, r.Function, r.Message)
+	}
+	if theReport != nil {
+		theReport.FunctionLogs = append(theReport.FunctionLogs, logs.records...)
+	}
+}
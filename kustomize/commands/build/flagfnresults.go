@@ -0,0 +1,113 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	flagFailOnResultsName = "fail-on-results"
+	flagResultsFileName   = "results-file"
+
+	failOnResultsNever = "never"
+)
+
+// severityLevels orders the severities of the results, so that a build
+// fails on the results of the severity of the flag or above.
+var severityLevels = map[string]int{
+	string(framework.Info):    1,
+	string(framework.Warning): 2,
+	string(framework.Error):   3,
+	failOnResultsNever:        4,
+}
+
+func AddFlagFnResults(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.failOnResults,
+		flagFailOnResultsName,
+		failOnResultsNever,
+		"Fail the build if a function emits a result of this severity or above: "+
+			string(framework.Error)+", "+string(framework.Warning)+", "+string(framework.Info)+
+			" or "+failOnResultsNever+". The results are printed to stderr in any case.")
+	set.StringVar(
+		&theFlags.resultsFile,
+		flagResultsFileName,
+		"",
+		"Write the results emitted by functions to this file, as YAML.")
+}
+
+func validateFlagFnResults() error {
+	if _, ok := severityLevels[theFlags.failOnResults]; !ok {
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagFailOnResultsName, theFlags.failOnResults,
+			[]string{string(framework.Error), string(framework.Warning), string(framework.Info),
+				failOnResultsNever})
+	}
+	return nil
+}
+
+// fnResult is a result emitted by a function, as written to the results file.
+type fnResult struct {
+	Function         string `yaml:"function"`
+	framework.Result `yaml:",inline"`
+}
+
+// fnResults collects the results emitted by the functions of a build.
+type fnResults []fnResult
+
+// collectFlagFnResults sets the options of the functions to collect
+// their results in the returned fnResults.
+func collectFlagFnResults(o *types.FnPluginLoadingOptions) *fnResults {
+	results := &fnResults{}
+	o.ResultsFunc = func(function string, rs framework.Results) {
+		for _, r := range rs {
+			*results = append(*results, fnResult{Function: function, Result: *r})
+		}
+	}
+	return results
+}
+
+// writeFlagFnResults prints the results to stderr and writes them to the
+// file of the results file flag, returning an error if one of them has the
+// severity of the fail on results flag or above.
+func writeFlagFnResults(fSys filesys.FileSystem, stderr io.Writer, results *fnResults) error {
+	failures := 0
+	for _, r := range *results {
+		fmt.Fprintf(stderr, "%s: %s\n", r.Function, r.Result)
+		if severityLevels[string(severity(r.Severity))] >= severityLevels[theFlags.failOnResults] {
+			failures++
+		}
+	}
+	if theFlags.resultsFile != "" {
+		b, err := yaml.Marshal([]fnResult(*results))
+		if err != nil {
+			return err
+		}
+		if err := fSys.WriteFile(theFlags.resultsFile, b); err != nil {
+			return err
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d function results of severity %s or above",
+			failures, theFlags.failOnResults)
+	}
+	return nil
+}
+
+// severity returns s, defaulting to info like the messages of the results.
+func severity(s framework.Severity) framework.Severity {
+	if s == "" {
+		return framework.Info
+	}
+	return s
+}
@@ -0,0 +1,41 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const flagGitCredentialsName = "git-credentials"
+
+func AddFlagGitCredentials(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.gitCredentials,
+		flagGitCredentialsName,
+		"",
+		"A file of GitCredentials authenticating the clones of remote bases by host,"+
+			" with a token, the environment variable of a token, or an ssh key,"+
+			" in addition to the credential helpers of the git configuration.")
+}
+
+// readFlagGitCredentials returns the credentials of the file of the git
+// credentials flag, or nil if it isn't set.
+func readFlagGitCredentials(fSys filesys.FileSystem) ([]types.GitCredential, error) {
+	if theFlags.gitCredentials == "" {
+		return nil, nil
+	}
+	content, err := readFlagFile(fSys, flagGitCredentialsName, theFlags.gitCredentials)
+	if err != nil {
+		return nil, err
+	}
+	c, err := types.ParseGitCredentials(content)
+	if err != nil {
+		return nil, fmt.Errorf("reading --%s %s: %w", flagGitCredentialsName, theFlags.gitCredentials, err)
+	}
+	return c.Credentials, nil
+}
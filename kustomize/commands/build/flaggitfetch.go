@@ -0,0 +1,36 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagGitFetchDepthName       = "git-fetch-depth"
+	flagGitFetchAllBranchesName = "git-fetch-all-branches"
+	flagGitFetchTagsName        = "git-fetch-tags"
+)
+
+func AddFlagGitFetch(set *pflag.FlagSet) {
+	set.IntVar(
+		&theFlags.gitFetch.Depth,
+		flagGitFetchDepthName,
+		0,
+		"The number of commits of the history of the ref of remote git bases fetched,"+
+			" unless set by their depth query parameter. 0 fetches only the commit of the ref,"+
+			" a negative depth the full history.")
+	set.BoolVar(
+		&theFlags.gitFetch.AllBranches,
+		flagGitFetchAllBranchesName,
+		false,
+		"Fetch all the branches of the repositories of remote git bases rather than only"+
+			" their ref, unless set by their singleBranch query parameter.")
+	set.BoolVar(
+		&theFlags.gitFetch.Tags,
+		flagGitFetchTagsName,
+		false,
+		"Fetch all the tags of the repositories of remote git bases, unless set by their"+
+			" tags query parameter.")
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+const (
+	flagProxyName   = "proxy"
+	flagNoProxyName = "no-proxy"
+	flagCAFileName  = "ca-file"
+)
+
+func AddFlagHTTP(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.http.Proxy,
+		flagProxyName,
+		"",
+		"The url of the proxy of the remote fetches, e.g. http://proxy.example.com:3128:"+
+			" the clones of git repositories over https, remote files, archives and"+
+			" OCI artifacts, and the pulls of helm charts. Defaults to the proxy of the environment.")
+	set.StringSliceVar(
+		&theFlags.http.NoProxy,
+		flagNoProxyName,
+		[]string{},
+		"Hosts, including their subdomains, and CIDRs connected to directly rather than through --"+
+			flagProxyName+", like NO_PROXY.")
+	set.StringArrayVar(
+		&theFlags.http.CAFiles,
+		flagCAFileName,
+		[]string{},
+		"A PEM file of certificate authorities trusted by the remote fetches in addition"+
+			" to those of the system, e.g. of a TLS intercepting proxy. May be repeated.")
+}
+
+func validateFlagHTTP() error {
+	if len(theFlags.http.NoProxy) > 0 && theFlags.http.Proxy == "" {
+		return fmt.Errorf("--%s requires --%s", flagNoProxyName, flagProxyName)
+	}
+	if err := theFlags.http.Validate(); err != nil {
+		return fmt.Errorf("invalid --%s or --%s: %w", flagProxyName, flagCAFileName, err)
+	}
+	return nil
+}
+
+// getFlagHTTP returns the http configuration of the flags.
+func getFlagHTTP() types.HTTPConfig {
+	return theFlags.http
+}
@@ -20,12 +20,16 @@ func AddFlagLoadRestrictor(set *pflag.FlagSet) {
 		"if set to '"+types.LoadRestrictionsNone.String()+
 			"', local kustomizations may load files from outside their root. "+
 			"This does, however, break the "+
-			"relocatability of the kustomization.")
+			"relocatability of the kustomization. "+
+			"If set to '"+types.LoadRestrictionsRootOfRepo.String()+
+			"', they may load files from anywhere in their git repository, "+
+			"but not from outside of it.")
 }
 
 func validateFlagLoadRestrictor() error {
 	switch theFlags.loadRestrictor {
 	case types.LoadRestrictionsRootOnly.String(),
+		types.LoadRestrictionsRootOfRepo.String(),
 		types.LoadRestrictionsNone.String(), "":
 		return nil
 	default:
@@ -33,12 +37,15 @@ func validateFlagLoadRestrictor() error {
 			"illegal flag value --%s %s; legal values: %v",
 			flagLoadRestrictorName, theFlags.loadRestrictor,
 			[]string{types.LoadRestrictionsRootOnly.String(),
+				types.LoadRestrictionsRootOfRepo.String(),
 				types.LoadRestrictionsNone.String()})
 	}
 }
 
 func getFlagLoadRestrictorValue() types.LoadRestrictions {
 	switch theFlags.loadRestrictor {
+	case types.LoadRestrictionsRootOfRepo.String():
+		return types.LoadRestrictionsRootOfRepo
 	case types.LoadRestrictionsNone.String(), "none":
 		return types.LoadRestrictionsNone
 	default:
@@ -0,0 +1,186 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/provenance"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+const flagMetadataName = "metadata"
+
+func AddFlagMetadata(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.metadata,
+		flagMetadataName,
+		"",
+		"Write a JSON report of the build to this file: the files read, with their hashes,"+
+			" the remote sources fetched, the plugins run, the warnings, the logs of the functions and the output resources.")
+}
+
+// buildReport is the report written with the metadata flag.
+type buildReport struct {
+	KustomizeVersion string           `json:"kustomizeVersion"`
+	Paths            []string         `json:"paths"`
+	Inputs           []reportInput    `json:"inputs"`
+	Remotes          []string         `json:"remotes"`
+	Plugins          []reportPlugin   `json:"plugins"`
+	Warnings         []string         `json:"warnings"`
+	Resources        []reportResource `json:"resources"`
+	// FunctionLogs are the lines the functions wrote to their stderr.
+	FunctionLogs []runtimeutil.LogRecord `json:"functionLogs"`
+}
+
+type reportInput struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+type reportPlugin struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Root string `json:"root,omitempty"`
+	// Builtin is false for plugins which aren't builtin.
+	Builtin bool `json:"builtin"`
+}
+
+type reportResource struct {
+	// Source is the kustomization path whose output has the resource.
+	Source     string `json:"source"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	SHA256     string `json:"sha256"`
+}
+
+// theReport collects the report of the build, if requested.
+var theReport *buildReport
+
+// startFlagMetadata starts the report of the build of paths, if requested,
+// and returns fSys recording the files read for it.
+func startFlagMetadata(fSys filesys.FileSystem, paths []string) filesys.FileSystem {
+	theReport = nil
+	if theFlags.metadata == "" {
+		return fSys
+	}
+	theReport = &buildReport{
+		KustomizeVersion: provenance.GetProvenance().Semver(),
+		Paths:            paths,
+		Inputs:           []reportInput{},
+		Remotes:          []string{},
+		Plugins:          []reportPlugin{},
+		Warnings:         []string{},
+		Resources:        []reportResource{},
+		FunctionLogs:     []runtimeutil.LogRecord{},
+	}
+	return newRecordingFs(fSys)
+}
+
+// recordFlagMetadata records the output resources of the kustomization
+// path in the report, if requested.
+func recordFlagMetadata(path string, m resmap.ResMap) error {
+	if theReport == nil {
+		return nil
+	}
+	for _, r := range m.Resources() {
+		out, err := r.AsYAML()
+		if err != nil {
+			return err
+		}
+		theReport.Resources = append(theReport.Resources, reportResource{
+			Source:     path,
+			APIVersion: r.GetApiVersion(),
+			Kind:       r.GetKind(),
+			Namespace:  r.GetNamespace(),
+			Name:       r.GetName(),
+			SHA256:     hash(out),
+		})
+	}
+	return nil
+}
+
+// writeFlagMetadata completes the report with the files read through
+// fSys, see startFlagMetadata, and the steps of profile p, and writes it.
+func writeFlagMetadata(fSys filesys.FileSystem, p *krusty.Profile) error {
+	if theReport == nil {
+		return nil
+	}
+	if rFs, ok := fSys.(*recordingFs); ok {
+		fSys = rFs.FileSystem
+		files := rFs.files()
+		paths := make([]string, 0, len(files))
+		for path, content := range files {
+			// missing files are recorded without content
+			if content != nil {
+				paths = append(paths, path)
+			}
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			theReport.Inputs = append(theReport.Inputs, reportInput{Path: path, SHA256: hash(files[path])})
+			theReport.Warnings = append(theReport.Warnings, kustomizationWarnings(path, files[path])...)
+		}
+	}
+	remotes := map[string]bool{}
+	for _, e := range p.Entries() {
+		switch e.Kind {
+		case krusty.ProfileKindRemote:
+			if !remotes[e.Name] {
+				remotes[e.Name] = true
+				theReport.Remotes = append(theReport.Remotes, e.Name)
+			}
+		case krusty.ProfileKindGenerator, krusty.ProfileKindTransformer, krusty.ProfileKindValidator:
+			theReport.Plugins = append(theReport.Plugins, reportPlugin{
+				Kind: string(e.Kind), Name: e.Name, Root: e.Root, Builtin: !e.Plugin})
+		}
+	}
+	out, err := json.MarshalIndent(theReport, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fSys.WriteFile(theFlags.metadata, append(out, '\n'))
+}
+
+// kustomizationWarnings returns the warnings about the deprecated fields
+// of the file at path if it is a kustomization file.
+func kustomizationWarnings(path string, content []byte) []string {
+	found := false
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		found = found || filepath.Base(path) == name
+	}
+	if !found {
+		return nil
+	}
+	var k types.Kustomization
+	if err := k.Unmarshal(content); err != nil {
+		return nil
+	}
+	warnings := k.CheckDeprecatedFields()
+	if warnings == nil {
+		return nil
+	}
+	result := make([]string, 0, len(*warnings))
+	for _, w := range *warnings {
+		result = append(result, path+": "+w)
+	}
+	return result
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/yaml"
+)
+
+const flagOpenAPIFromClusterName = "openapi-from-cluster"
+
+// AddFlagOpenAPIFromCluster adds the --openapi-from-cluster flag.
+func AddFlagOpenAPIFromCluster(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.openAPIFromCluster,
+		flagOpenAPIFromClusterName,
+		false,
+		"Add the schemas of the CustomResourceDefinitions in the current kubernetes cluster "+
+			"specified in the user's kubeconfig to the OpenAPI schema, so that patches "+
+			"and merges of custom resources use their list types and merge keys.")
+}
+
+// addSchemaFromCluster adds the schemas of the CustomResourceDefinitions
+// of the cluster of the current context of the user's kubeconfig.
+func addSchemaFromCluster() error {
+	config, err := restConfigFromKubeconfig()
+	if err != nil {
+		return fmt.Errorf("--%s: %w", flagOpenAPIFromClusterName, err)
+	}
+	if err := openapi.AddSchemaFromCluster(config); err != nil {
+		return fmt.Errorf("--%s: %w", flagOpenAPIFromClusterName, err)
+	}
+	return nil
+}
+
+// kubeconfig contains the fields of a kubeconfig file used to build
+// the openapi.RESTConfig of its current context.
+type kubeconfig struct {
+	CurrentContext string `json:"current-context"`
+	Contexts       []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster string `json:"cluster"`
+			User    string `json:"user"`
+		} `json:"context"`
+	} `json:"contexts"`
+	Clusters []struct {
+		Name    string `json:"name"`
+		Cluster struct {
+			Server                   string `json:"server"`
+			InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+			CertificateAuthority     string `json:"certificate-authority"`
+			CertificateAuthorityData []byte `json:"certificate-authority-data"`
+		} `json:"cluster"`
+	} `json:"clusters"`
+	Users []struct {
+		Name string `json:"name"`
+		User struct {
+			Token                 string                 `json:"token"`
+			TokenFile             string                 `json:"tokenFile"`
+			Username              string                 `json:"username"`
+			Password              string                 `json:"password"`
+			ClientCertificate     string                 `json:"client-certificate"`
+			ClientCertificateData []byte                 `json:"client-certificate-data"`
+			ClientKey             string                 `json:"client-key"`
+			ClientKeyData         []byte                 `json:"client-key-data"`
+			Exec                  map[string]interface{} `json:"exec"`
+			AuthProvider          map[string]interface{} `json:"auth-provider"`
+		} `json:"user"`
+	} `json:"users"`
+}
+
+// kubeconfigPaths returns the kubeconfig files in the order kubectl reads them:
+// the files listed in $KUBECONFIG, or else ~/.kube/config.
+func kubeconfigPaths() []string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return filepath.SplitList(env)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(home, ".kube", "config")}
+}
+
+// restConfigFromKubeconfig returns the openapi.RESTConfig of the current
+// context of the user's kubeconfig. Like kubectl, the first file setting
+// the current context or defining an entry wins. Credentials from exec
+// plugins and auth providers aren't supported.
+func restConfigFromKubeconfig() (*openapi.RESTConfig, error) {
+	var merged kubeconfig
+	dirs := map[string]string{}
+	found := false
+	for _, path := range kubeconfigPaths() {
+		b, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading kubeconfig: %w", err)
+		}
+		found = true
+		var k kubeconfig
+		if err := yaml.Unmarshal(b, &k); err != nil {
+			return nil, fmt.Errorf("parsing kubeconfig %s: %w", path, err)
+		}
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = k.CurrentContext
+		}
+		merged.Contexts = append(merged.Contexts, k.Contexts...)
+		for _, c := range k.Clusters {
+			if _, ok := dirs["cluster/"+c.Name]; !ok {
+				dirs["cluster/"+c.Name] = filepath.Dir(path)
+			}
+			merged.Clusters = append(merged.Clusters, c)
+		}
+		for _, u := range k.Users {
+			if _, ok := dirs["user/"+u.Name]; !ok {
+				dirs["user/"+u.Name] = filepath.Dir(path)
+			}
+			merged.Users = append(merged.Users, u)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no kubeconfig found")
+	}
+	if merged.CurrentContext == "" {
+		return nil, fmt.Errorf("kubeconfig has no current-context")
+	}
+
+	clusterName, userName, ok := "", "", false
+	for _, c := range merged.Contexts {
+		if c.Name == merged.CurrentContext {
+			clusterName, userName, ok = c.Context.Cluster, c.Context.User, true
+			break
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig has no context %q", merged.CurrentContext)
+	}
+
+	config := &openapi.RESTConfig{}
+	ok = false
+	for _, c := range merged.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		dir := dirs["cluster/"+c.Name]
+		config.Host = c.Cluster.Server
+		config.Insecure = c.Cluster.InsecureSkipTLSVerify
+		config.CAData = c.Cluster.CertificateAuthorityData
+		if len(config.CAData) == 0 && c.Cluster.CertificateAuthority != "" {
+			b, err := os.ReadFile(resolvePath(dir, c.Cluster.CertificateAuthority))
+			if err != nil {
+				return nil, fmt.Errorf("reading certificate-authority of cluster %q: %w", c.Name, err)
+			}
+			config.CAData = b
+		}
+		ok = true
+		break
+	}
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig has no cluster %q", clusterName)
+	}
+
+	for _, u := range merged.Users {
+		if u.Name != userName {
+			continue
+		}
+		if u.User.Exec != nil || u.User.AuthProvider != nil {
+			return nil, fmt.Errorf(
+				"user %q authenticates with an exec plugin or auth provider, which isn't supported", u.Name)
+		}
+		dir := dirs["user/"+u.Name]
+		config.BearerToken = u.User.Token
+		if config.BearerToken == "" && u.User.TokenFile != "" {
+			b, err := os.ReadFile(resolvePath(dir, u.User.TokenFile))
+			if err != nil {
+				return nil, fmt.Errorf("reading tokenFile of user %q: %w", u.Name, err)
+			}
+			config.BearerToken = strings.TrimSpace(string(b))
+		}
+		config.Username = u.User.Username
+		config.Password = u.User.Password
+		config.CertData = u.User.ClientCertificateData
+		if len(config.CertData) == 0 && u.User.ClientCertificate != "" {
+			b, err := os.ReadFile(resolvePath(dir, u.User.ClientCertificate))
+			if err != nil {
+				return nil, fmt.Errorf("reading client-certificate of user %q: %w", u.Name, err)
+			}
+			config.CertData = b
+		}
+		config.KeyData = u.User.ClientKeyData
+		if len(config.KeyData) == 0 && u.User.ClientKey != "" {
+			b, err := os.ReadFile(resolvePath(dir, u.User.ClientKey))
+			if err != nil {
+				return nil, fmt.Errorf("reading client-key of user %q: %w", u.Name, err)
+			}
+			config.KeyData = b
+		}
+		break
+	}
+	return config, nil
+}
+
+// resolvePath resolves path relative to the directory of the kubeconfig defining it.
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagOutputFileNameName = "output-filename"
+	flagOutputGroupByName  = "output-group-by"
+
+	groupByKind      = "kind"
+	groupByNamespace = "namespace"
+)
+
+// templatesByGroup are the file name templates used to group the output by
+// kind or by namespace.
+var templatesByGroup = map[string]string{
+	groupByKind:      "{kind}.yaml",
+	groupByNamespace: "{namespace}.yaml",
+}
+
+func AddFlagOutputFileName(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.outputFileName,
+		flagOutputFileNameName,
+		"",
+		"Template of the file names used when the output path is a directory,"+
+			" e.g. '{namespace}/{kind}-{name}.yaml'. Supported placeholders are "+
+			"{group}, {version}, {kind}, {name} and {namespace}, which is '"+
+			clusterScopedNamespace+"' for cluster-scoped resources."+
+			" Resources with the same file name are written to the same file.")
+	set.StringVar(
+		&theFlags.outputGroupBy,
+		flagOutputGroupByName,
+		"",
+		"When the output path is a directory, write the resources of each '"+groupByKind+
+			"' or each '"+groupByNamespace+"' to a single file.")
+}
+
+func validateFlagOutputFileName() error {
+	if theFlags.outputGroupBy != "" {
+		if theFlags.outputFileName != "" {
+			return fmt.Errorf("--%s and --%s are mutually exclusive",
+				flagOutputFileNameName, flagOutputGroupByName)
+		}
+		if _, ok := templatesByGroup[theFlags.outputGroupBy]; !ok {
+			return fmt.Errorf(
+				"illegal flag value --%s %s; legal values: %v",
+				flagOutputGroupByName, theFlags.outputGroupBy,
+				[]string{groupByKind, groupByNamespace})
+		}
+	}
+	if theFlags.outputFileName != "" {
+		return validateFileNameTemplate(theFlags.outputFileName)
+	}
+	return nil
+}
+
+// getFlagOutputFileName returns the file name template selected by the flags,
+// or the empty string to use the default file names.
+func getFlagOutputFileName() string {
+	if theFlags.outputGroupBy != "" {
+		return templatesByGroup[theFlags.outputGroupBy]
+	}
+	return theFlags.outputFileName
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	flagOutputFormatName = "output-format"
+	flagOutputListName   = "output-list"
+
+	outputFormatYAML      = "yaml"
+	outputFormatJSON      = "json"
+	outputFormatJSONLines = "jsonlines"
+)
+
+func AddFlagOutputFormat(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.outputFormat,
+		flagOutputFormatName,
+		outputFormatYAML,
+		"Format of the output. Use '"+outputFormatYAML+"' for a stream of YAML documents,"+
+			" '"+outputFormatJSON+"' for a stream of JSON documents,"+
+			" or '"+outputFormatJSONLines+"' for one JSON document per line.")
+	set.BoolVar(
+		&theFlags.outputList,
+		flagOutputListName,
+		false,
+		"Output the resources as the items of a single 'kind: List' document."+
+			" Not supported by the '"+outputFormatJSONLines+"' format.")
+}
+
+func validateFlagOutputFormat() error {
+	switch theFlags.outputFormat {
+	case outputFormatYAML, outputFormatJSON:
+		return nil
+	case outputFormatJSONLines:
+		if theFlags.outputList {
+			return fmt.Errorf("--%s is not supported by --%s %s",
+				flagOutputListName, flagOutputFormatName, outputFormatJSONLines)
+		}
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagOutputFormatName, theFlags.outputFormat,
+			[]string{outputFormatYAML, outputFormatJSON, outputFormatJSONLines})
+	}
+}
+
+// isDefaultOutputFormat returns true if the resources are output as a
+// stream of YAML documents, the only format supported by directory output.
+func isDefaultOutputFormat() bool {
+	return theFlags.outputFormat == outputFormatYAML && !theFlags.outputList
+}
+
+// formatOutput returns the resources of m in the format selected by the flags.
+func formatOutput(m resmap.ResMap) ([]byte, error) {
+	if theFlags.outputList {
+		return formatList(m)
+	}
+	switch theFlags.outputFormat {
+	case outputFormatJSON, outputFormatJSONLines:
+		var buf bytes.Buffer
+		for _, res := range m.Resources() {
+			b, err := res.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			if theFlags.outputFormat == outputFormatJSON {
+				err = json.Indent(&buf, b, "", "  ")
+			} else {
+				err = json.Compact(&buf, b)
+			}
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString("\n")
+		}
+		return buf.Bytes(), nil
+	default:
+		return m.AsYaml()
+	}
+}
+
+// formatList returns the resources of m as the items of a List.
+func formatList(m resmap.ResMap) ([]byte, error) {
+	items := []interface{}{}
+	for _, res := range m.Resources() {
+		item, err := res.Map()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	list := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	}
+	if theFlags.outputFormat == outputFormatJSON {
+		b, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	}
+	return yaml.Marshal(list)
+}
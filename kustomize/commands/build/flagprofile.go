@@ -0,0 +1,56 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	flagProfileName = "profile"
+
+	// profileText prints the profile as a table to stderr.
+	profileText = "-"
+)
+
+func AddFlagProfile(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.profile,
+		flagProfileName,
+		"",
+		"Profile the build: print the time spent in accumulation, each generator,"+
+			" transformer and validator, and remote fetches to stderr,"+
+			" or write it as JSON to the given file.")
+	set.Lookup(flagProfileName).NoOptDefVal = profileText
+}
+
+// makeFlagProfile returns the profile to record the build in, or nil
+// if the build isn't profiled.  The report of the metadata flag is
+// made from the profile too.
+func makeFlagProfile() *krusty.Profile {
+	if theFlags.profile == "" && theFlags.metadata == "" {
+		return nil
+	}
+	return krusty.NewProfile()
+}
+
+// writeFlagProfile writes p to stderr or the file of the profile flag.
+func writeFlagProfile(fSys filesys.FileSystem, stderr io.Writer, p *krusty.Profile) error {
+	if p == nil || theFlags.profile == "" {
+		return nil
+	}
+	if theFlags.profile == profileText {
+		return p.WriteText(stderr)
+	}
+	var b bytes.Buffer
+	if err := p.WriteJSON(&b); err != nil {
+		return err
+	}
+	return fSys.WriteFile(theFlags.profile, b.Bytes())
+}
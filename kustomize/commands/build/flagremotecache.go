@@ -0,0 +1,41 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	flagRemoteCacheDirName = "remote-cache-dir"
+	flagRemoteCacheTTLName = "remote-cache-ttl"
+
+	defaultRemoteCacheTTL = time.Hour
+)
+
+func AddFlagRemoteCache(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.remoteCacheDir,
+		flagRemoteCacheDirName,
+		"",
+		"Cache the clones of remote git bases in this directory, shared by the builds"+
+			" using it, rather than cloning them in every build. The clones of commit hashes"+
+			" are reused forever, those of branches and tags for --"+flagRemoteCacheTTLName+".")
+	set.DurationVar(
+		&theFlags.remoteCacheTTL,
+		flagRemoteCacheTTLName,
+		defaultRemoteCacheTTL,
+		"How long the cached clones of branches and other refs which may move are reused"+
+			" with --"+flagRemoteCacheDirName+".")
+}
+
+func validateFlagRemoteCache() error {
+	if theFlags.remoteCacheTTL < 0 {
+		return fmt.Errorf("--%s must not be negative", flagRemoteCacheTTLName)
+	}
+	return nil
+}
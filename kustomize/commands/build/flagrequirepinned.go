@@ -0,0 +1,21 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const flagRequirePinnedRemotesName = "require-pinned-remotes"
+
+func AddFlagRequirePinnedRemotes(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.requirePinnedRemotes,
+		flagRequirePinnedRemotesName,
+		false,
+		"Fail on remote bases, files and helm charts which aren't pinned to immutable content:"+
+			" git repositories must be referenced by commit hashes, OCI artifacts by digests,"+
+			" remote files by their sha256, e.g. ?sha256=<digest>,"+
+			" and the charts of helm repositories by exact versions.")
+}
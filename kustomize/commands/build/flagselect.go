@@ -0,0 +1,135 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+const (
+	flagSelectName  = "select"
+	flagExcludeName = "exclude"
+)
+
+// selectorKeys are the fields of resources a selector of the select and
+// exclude flags can match.
+var selectorKeys = map[string]func(r *resource.Resource) string{
+	"group":     func(r *resource.Resource) string { return r.GetGvk().Group },
+	"version":   func(r *resource.Resource) string { return r.GetGvk().Version },
+	"kind":      func(r *resource.Resource) string { return r.GetKind() },
+	"name":      func(r *resource.Resource) string { return r.GetName() },
+	"namespace": func(r *resource.Resource) string { return r.GetNamespace() },
+}
+
+func AddFlagSelect(set *pflag.FlagSet) {
+	set.StringArrayVar(
+		&theFlags.selects,
+		flagSelectName,
+		nil,
+		"Only output the resources matching this selector, e.g. 'kind=Deployment,name=api-*'."+
+			" The keys are group, version, kind, name and namespace, and the values are glob patterns."+
+			" A resource must match all the keys of a selector, and any of the selectors if repeated.")
+	set.StringArrayVar(
+		&theFlags.excludes,
+		flagExcludeName,
+		nil,
+		"Don't output the resources matching this selector, in the format of --"+flagSelectName+".")
+}
+
+// resourceSelector matches the resources whose fields match all its
+// glob patterns, by key.
+type resourceSelector map[string]string
+
+func (s resourceSelector) matches(r *resource.Resource) bool {
+	for key, pattern := range s {
+		// the pattern was validated when parsed
+		if ok, _ := path.Match(pattern, selectorKeys[key](r)); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSelector parses a selector of the form 'key=pattern,...'.
+func parseSelector(flag, s string) (resourceSelector, error) {
+	sel := resourceSelector{}
+	for _, term := range strings.Split(s, ",") {
+		key, pattern, found := strings.Cut(term, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf(
+				"invalid --%s %q; expected comma-separated key=pattern terms", flag, s)
+		}
+		if _, ok := selectorKeys[key]; !ok {
+			return nil, fmt.Errorf(
+				"invalid --%s %q; unknown key %q, legal keys: %v",
+				flag, s, key, []string{"group", "version", "kind", "name", "namespace"})
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --%s %q; bad pattern %q: %w", flag, s, pattern, err)
+		}
+		sel[key] = pattern
+	}
+	return sel, nil
+}
+
+func parseSelectors(flag string, values []string) ([]resourceSelector, error) {
+	var sels []resourceSelector
+	for _, v := range values {
+		sel, err := parseSelector(flag, v)
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	return sels, nil
+}
+
+func validateFlagSelect() error {
+	if _, err := parseSelectors(flagSelectName, theFlags.selects); err != nil {
+		return err
+	}
+	_, err := parseSelectors(flagExcludeName, theFlags.excludes)
+	return err
+}
+
+// filterFlagSelect removes the resources of m which don't match
+// any selector of the select flag, or match one of the exclude flag.
+func filterFlagSelect(m resmap.ResMap) error {
+	selects, err := parseSelectors(flagSelectName, theFlags.selects)
+	if err != nil {
+		return err
+	}
+	excludes, err := parseSelectors(flagExcludeName, theFlags.excludes)
+	if err != nil {
+		return err
+	}
+	if len(selects) == 0 && len(excludes) == 0 {
+		return nil
+	}
+	for _, r := range m.Resources() {
+		if (len(selects) == 0 || matchesAny(selects, r)) && !matchesAny(excludes, r) {
+			continue
+		}
+		if err := m.Remove(r.CurId()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchesAny(sels []resourceSelector, r *resource.Resource) bool {
+	for _, sel := range sels {
+		if sel.matches(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,7 +4,31 @@
 package build
 
 import (
+	"fmt"
+	"io"
+	"strings"
+
 	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/kv"
+	"sigs.k8s.io/kustomize/api/pkg/loader"
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/fngrpc"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/wasm"
+)
+
+const (
+	flagFunctionPullPolicyName = "function-pull-policy"
+	flagWasmRuntimeName        = "wasm-runtime"
+	flagContainerIsolationName = "container-isolation"
+	flagEnvFileName            = "env-file"
+
+	// defaultFnTimeout keeps a hanging function from hanging the build.
+	defaultFnTimeout = "10m"
 )
 
 func AddFunctionBasicsFlags(set *pflag.FlagSet) {
@@ -16,13 +40,119 @@ func AddFunctionBasicsFlags(set *pflag.FlagSet) {
 		"the docker network to run the container in")
 	set.StringArrayVar(
 		&theFlags.fnOptions.Mounts, "mount", []string{},
-		"a list of storage options read from the filesystem")
+		"a list of storage options read from the filesystem, "+
+			"e.g. type=bind,src=charts,dst=/charts,rw=true with src relative to the kustomization root")
 	set.StringArrayVarP(
 		&theFlags.fnOptions.Env, "env", "e", []string{},
 		"a list of environment variables to be used by functions")
+	set.StringVar(
+		&theFlags.envFile, flagEnvFileName, "",
+		"a file of environment variables, as KEY=VALUE lines, to be used by functions and exec plugins; "+
+			"variables given by --env take precedence")
 	set.BoolVar(
 		&theFlags.fnOptions.AsCurrentUser, "as-current-user", false,
 		"use the uid and gid of the command executor to run the function in the container")
+	set.StringVar(
+		&theFlags.fnOptions.ContainerRuntime, "container-runtime", "",
+		"the container runtime to run container functions with: docker, podman or nerdctl "+
+			"(autodetected from the PATH if empty)")
+	set.StringVar(
+		&theFlags.fnOptions.ContainerIsolation, flagContainerIsolationName, "",
+		"the isolation of Windows containers: "+strings.Join(container.Isolations, " or ")+
+			" (the container runtime's default if empty)")
+	set.BoolVar(
+		&theFlags.reuseContainers, "reuse-containers", false,
+		"keep the container functions running, with --serve, to send them their next runs in the build, "+
+			"rather than running a new container each time")
+	set.BoolVar(
+		&theFlags.cacheFnOutputs, "cache-function-outputs", false,
+		"reuse the outputs of the exec functions, and of the container functions whose image is pinned "+
+			"by digest, when they're run again with the same functionConfig and resources; "+
+			"the functions must only depend on their input")
+	set.StringVar(
+		&theFlags.fnOutputCacheDir, "function-output-cache-dir", "",
+		"the directory caching the outputs of the functions, implying --cache-function-outputs "+
+			"(kustomize/function-outputs in the cache directory of the user if empty)")
+	set.StringVar(
+		&theFlags.fnOptions.WasmRuntime, flagWasmRuntimeName, "",
+		"the WebAssembly runtime to run wasm functions with: "+strings.Join(wasm.Runtimes, " or ")+
+			" (autodetected from the PATH if empty)")
+	set.StringVar(
+		&theFlags.fnOptions.ImagePullPolicy, flagFunctionPullPolicyName, "",
+		"when to pull the images of container functions which don't declare an imagePullPolicy: "+
+			strings.Join(runtimeutil.ImagePullPolicies, ", ")+" (the container runtime's default if empty)")
+	set.StringVar(
+		&theFlags.fnOptions.Limits.Timeout, "function-timeout", defaultFnTimeout,
+		"the wall-clock time a run of a container or exec function may take before it's killed, "+
+			"unless the function declares limits.timeout; 0 disables the timeout")
+	set.StringVar(
+		&theFlags.fnOptions.Limits.CPU, "function-cpus", "",
+		"the number of CPUs a container function may use, e.g. 0.5, "+
+			"unless the function declares limits.cpu (unlimited if empty)")
+	set.StringVar(
+		&theFlags.fnOptions.Limits.Memory, "function-memory", "",
+		"the memory a container or exec function may use, e.g. 512m, "+
+			"unless the function declares limits.memory (unlimited if empty); "+
+			"the memory of exec functions is only limited on Linux, and their CPUs aren't limited")
+}
+
+func validateFlagContainerIsolation() error {
+	if theFlags.fnOptions.ContainerIsolation == "" {
+		return nil
+	}
+	for _, i := range container.Isolations {
+		if theFlags.fnOptions.ContainerIsolation == i {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"illegal flag value --%s %s; legal values: %v",
+		flagContainerIsolationName, theFlags.fnOptions.ContainerIsolation, container.Isolations)
+}
+
+func validateFlagFnLimits() error {
+	if err := theFlags.fnOptions.Limits.Validate(); err != nil {
+		return fmt.Errorf("invalid function limits: %w", err)
+	}
+	return nil
+}
+
+func validateFlagMounts() error {
+	for _, m := range theFlags.fnOptions.Mounts {
+		if _, err := runtimeutil.ParseStorageMount(m); err != nil {
+			return fmt.Errorf("invalid --mount: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateFlagFunctionPullPolicy() error {
+	if theFlags.fnOptions.ImagePullPolicy == "" {
+		return nil
+	}
+	for _, p := range runtimeutil.ImagePullPolicies {
+		if theFlags.fnOptions.ImagePullPolicy == p {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"illegal flag value --%s %s; legal values: %v",
+		flagFunctionPullPolicyName, theFlags.fnOptions.ImagePullPolicy,
+		runtimeutil.ImagePullPolicies)
+}
+
+func validateFlagWasmRuntime() error {
+	if theFlags.fnOptions.WasmRuntime == "" {
+		return nil
+	}
+	for _, r := range wasm.Runtimes {
+		if theFlags.fnOptions.WasmRuntime == r {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"illegal flag value --%s %s; legal values: %v",
+		flagWasmRuntimeName, theFlags.fnOptions.WasmRuntime, wasm.Runtimes)
 }
 
 func AddFunctionAlphaEnablementFlags(set *pflag.FlagSet) {
@@ -30,7 +160,80 @@ func AddFunctionAlphaEnablementFlags(set *pflag.FlagSet) {
 		&theFlags.fnOptions.EnableExec, "enable-exec", false,
 		"enable support for exec functions (raw executables); "+
 			"do not use for untrusted configs! (Alpha)")
+	set.BoolVar(
+		&theFlags.fnOptions.SandboxExec, "sandbox-exec", false,
+		"run exec functions in a sandbox with bubblewrap on Linux, with read-only access to the "+
+			"system and the kustomization, and access to the mounts, and to the network with --network. (Alpha)")
 	set.BoolVar(
 		&theFlags.fnOptions.EnableStar, "enable-star", false,
 		"enable support for starlark functions. (Alpha)")
+	set.BoolVar(
+		&theFlags.fnOptions.EnableWasm, "enable-wasm", false,
+		"enable support for WebAssembly functions, run sandboxed by a wasm runtime. (Alpha)")
+	set.BoolVar(
+		&theFlags.fnOptions.EnableGo, "enable-go", false,
+		"enable support for functions published as Go modules, built with the go command; "+
+			"do not use for untrusted configs! (Alpha)")
+}
+
+// readFlagEnvFile returns the variables of the file of --env-file,
+// if any, as KEY=VALUE.
+func readFlagEnvFile(fSys filesys.FileSystem) ([]string, error) {
+	if theFlags.envFile == "" {
+		return nil, nil
+	}
+	path, err := flagFilePath(fSys, flagEnvFileName, theFlags.envFile)
+	if err != nil {
+		return nil, err
+	}
+	ldr := kv.NewLoader(loader.NewFileLoaderAtRoot(fSys),
+		provider.NewDefaultDepProvider().GetFieldValidator())
+	pairs, err := ldr.Load(types.KvPairSources{
+		EnvSources: []string{path},
+	})
+	if err != nil {
+		return nil, err
+	}
+	env := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		env = append(env, p.Key+"="+p.Value)
+	}
+	return env, nil
+}
+
+// startFlagReuseContainers sets a pool of the containers of the functions
+// in o if --reuse-containers is set, returning a function which removes
+// the containers of the pool.
+func startFlagReuseContainers(o *types.FnPluginLoadingOptions, stderr io.Writer) func() {
+	if !theFlags.reuseContainers {
+		return func() {}
+	}
+	o.ContainerPool = container.NewPool()
+	return func() {
+		if err := o.ContainerPool.Close(); err != nil {
+			fmt.Fprintf(stderr, "removing the containers of the functions: %v\n", err)
+		}
+	}
+}
+
+// startExecServers sets a pool of the exec functions run as servers in o,
+// so that each of them is started once per build, returning a function
+// which stops them.
+func startExecServers(o *types.FnPluginLoadingOptions, stderr io.Writer) func() {
+	o.ExecServers = exec.NewServerPool(fngrpc.Dialer{})
+	return func() {
+		if err := o.ExecServers.Close(); err != nil {
+			fmt.Fprintf(stderr, "stopping the exec functions run as servers: %v\n", err)
+		}
+	}
+}
+
+// makeFlagFnOutputCache returns the cache of the outputs of the functions,
+// or nil if neither --cache-function-outputs nor --function-output-cache-dir
+// is set.
+func makeFlagFnOutputCache() (*runtimeutil.OutputCache, error) {
+	if !theFlags.cacheFnOutputs && theFlags.fnOutputCacheDir == "" {
+		return nil, nil
+	}
+	return runtimeutil.NewOutputCache(theFlags.fnOutputCacheDir)
 }
@@ -0,0 +1,49 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/pkg/loader"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const flagTrustedCatalogName = "trusted-catalog"
+
+func AddFlagTrustedCatalogs(set *pflag.FlagSet) {
+	set.StringArrayVar(
+		&theFlags.trustedCatalogs,
+		flagTrustedCatalogName,
+		[]string{},
+		"A file or URL of a catalog of functions resolving the generators, transformers and"+
+			" validators without a function annotation, taking precedence over the catalogs"+
+			" of the kustomizations. May be repeated.")
+}
+
+// readFlagTrustedCatalogs returns the catalogs of the trusted catalog flags.
+func readFlagTrustedCatalogs(fSys filesys.FileSystem) ([]*types.Catalog, error) {
+	ldr := loader.NewFileLoaderAtRoot(fSys)
+	var catalogs []*types.Catalog
+	for _, path := range theFlags.trustedCatalogs {
+		var content []byte
+		var err error
+		if strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://") {
+			if content, err = ldr.Load(path); err != nil {
+				return nil, fmt.Errorf("reading --%s %s: %w", flagTrustedCatalogName, path, err)
+			}
+		} else if content, err = readFlagFile(fSys, flagTrustedCatalogName, path); err != nil {
+			return nil, err
+		}
+		c, err := types.ParseCatalog(content)
+		if err != nil {
+			return nil, fmt.Errorf("reading --%s %s: %w", flagTrustedCatalogName, path, err)
+		}
+		catalogs = append(catalogs, c)
+	}
+	return catalogs, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+const (
+	flagValidateName         = "validate"
+	flagValidationSchemaName = "validation-schema"
+
+	validateTrue   = "true"
+	validateFalse  = "false"
+	validateStrict = "strict"
+)
+
+func AddFlagValidate(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.validate,
+		flagValidateName,
+		validateFalse,
+		"Validate the output against the openapi schema of the resources."+
+			" Values of the wrong type are errors, and unknown fields are warnings."+
+			" Use '"+validateStrict+"' to make unknown fields and resources without a schema errors too.")
+	set.Lookup(flagValidateName).NoOptDefVal = validateTrue
+	set.StringSliceVar(
+		&theFlags.validationSchemas,
+		flagValidationSchemaName,
+		nil,
+		"Path to a file of CustomResourceDefinitions, whose schemas validate"+
+			" the custom resources of the output with --"+flagValidateName+".")
+}
+
+func validateFlagValidate() error {
+	switch theFlags.validate {
+	case validateTrue, validateFalse, validateStrict:
+		return nil
+	default:
+		return fmt.Errorf(
+			"illegal flag value --%s %s; legal values: %v",
+			flagValidateName, theFlags.validate,
+			[]string{validateTrue, validateFalse, validateStrict})
+	}
+}
+
+func getFlagValidate() krusty.ValidationOption {
+	switch theFlags.validate {
+	case validateTrue:
+		return krusty.ValidationOptionEnabled
+	case validateStrict:
+		return krusty.ValidationOptionStrict
+	default:
+		return krusty.ValidationOptionNone
+	}
+}
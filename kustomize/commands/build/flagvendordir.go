@@ -0,0 +1,21 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"github.com/spf13/pflag"
+)
+
+const flagVendorDirName = "vendor-dir"
+
+func AddFlagVendorDir(set *pflag.FlagSet) {
+	set.StringVar(
+		&theFlags.vendorDir,
+		flagVendorDirName,
+		"",
+		"Load remote git repositories and files from their copies in this directory,"+
+			" laid out like the localized-files directory of 'kustomize localize',"+
+			" instead of fetching them. It's an error for one to be missing,"+
+			" so that builds work without network access.")
+}
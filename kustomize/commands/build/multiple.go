@@ -0,0 +1,155 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// expandKustomizationPaths returns the kustomization paths of the arguments,
+// replacing the glob patterns with the matching directories containing a
+// kustomization file.
+func expandKustomizationPaths(fSys filesys.FileSystem, args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") || strings.Contains(arg, "://") {
+			paths = append(paths, arg)
+			continue
+		}
+		dirs, err := globKustomizationDirs(fSys, arg)
+		if err != nil {
+			return nil, err
+		}
+		if len(dirs) == 0 {
+			return nil, fmt.Errorf("no kustomization directories match %q", arg)
+		}
+		sort.Strings(dirs)
+		paths = append(paths, dirs...)
+	}
+	return paths, nil
+}
+
+// globKustomizationDirs returns the directories matching pattern which
+// contain a kustomization file.  The directories are relative if pattern is.
+func globKustomizationDirs(fSys filesys.FileSystem, pattern string) ([]string, error) {
+	// file systems differ in how they match relative patterns,
+	// so they are made absolute first
+	wd, _, err := fSys.CleanedAbs(filesys.SelfDir)
+	if err != nil {
+		return nil, err
+	}
+	absPattern := pattern
+	if !filepath.IsAbs(pattern) {
+		absPattern = filepath.Join(wd.String(), pattern)
+	}
+	var dirs []string
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		matches, err := fSys.Glob(filepath.Join(absPattern, name))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			dir := filepath.Dir(match)
+			if !filepath.IsAbs(pattern) {
+				if dir, err = filepath.Rel(wd.String(), dir); err != nil {
+					return nil, err
+				}
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
+
+// buildMultiple builds each of the kustomization paths.  If the output path
+// is a directory, the output of each path is written to a subdirectory of
+// the output path, see outputDirName.  Otherwise the outputs are written to
+// the output path or to writer one after the other, separated by a
+// '# Source: <path>' comment in YAML output.
+func buildMultiple(
+	fSys filesys.FileSystem, k *krusty.Kustomizer, paths []string, writer io.Writer) error {
+	toDir := theFlags.outputPath != "" && fSys.IsDir(theFlags.outputPath)
+	if !toDir && getFlagOutputFileName() != "" {
+		return fmt.Errorf("--%s and --%s require --output to be a directory",
+			flagOutputFileNameName, flagOutputGroupByName)
+	}
+	dirs := map[string]string{}
+	var buf bytes.Buffer
+	for i, path := range paths {
+		m, err := k.Run(fSys, path)
+		if err != nil {
+			return fmt.Errorf("building %s: %w", path, err)
+		}
+		if err := filterFlagSelect(m); err != nil {
+			return err
+		}
+		if err := recordFlagMetadata(path, m); err != nil {
+			return err
+		}
+		if toDir {
+			dir := filepath.Join(theFlags.outputPath, outputDirName(path))
+			if other, found := dirs[dir]; found {
+				return fmt.Errorf(
+					"the outputs of %s and %s would both be written to %s", other, path, dir)
+			}
+			dirs[dir] = path
+			if err := fSys.MkdirAll(dir); err != nil {
+				return err
+			}
+			if err := writeOutput(fSys, nil, dir, m); err != nil {
+				return fmt.Errorf("writing %s: %w", path, err)
+			}
+			continue
+		}
+		out, err := formatOutput(m)
+		if err != nil {
+			return err
+		}
+		if theFlags.outputFormat == outputFormatYAML {
+			if i > 0 {
+				buf.WriteString("---\n")
+			}
+			fmt.Fprintf(&buf, "# Source: %s\n", path)
+		}
+		buf.Write(out)
+	}
+	if toDir {
+		return nil
+	}
+	if theFlags.outputPath != "" {
+		// Ignore writer; write to o.outputPath directly.
+		return fSys.WriteFile(theFlags.outputPath, buf.Bytes())
+	}
+	_, err := writer.Write(buf.Bytes())
+	return err
+}
+
+var unsafeDirNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// outputDirName returns the name of the subdirectory of the output
+// directory for the kustomization path.  It mirrors the path, without
+// the elements that would escape the output directory.
+func outputDirName(path string) string {
+	var elems []string
+	for _, elem := range strings.Split(filepath.ToSlash(path), "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			continue
+		}
+		elems = append(elems, unsafeDirNameChars.ReplaceAllString(elem, "_"))
+	}
+	if len(elems) == 0 {
+		return "_root"
+	}
+	return filepath.Join(elems...)
+}
@@ -9,9 +9,15 @@ import (
 	"github.com/spf13/pflag"
 	flag "github.com/spf13/pflag"
 	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
-const flagReorderOutputName = "reorder"
+const (
+	flagReorderOutputName = "reorder"
+	flagReorderFileName   = "reorder-file"
+)
 
 func AddFlagReorderOutput(set *pflag.FlagSet) {
 	set.StringVar(
@@ -19,19 +25,76 @@ func AddFlagReorderOutput(set *pflag.FlagSet) {
 		string(krusty.ReorderOptionLegacy),
 		"Reorder the resources just before output. Use '"+string(krusty.ReorderOptionLegacy)+"' to"+
 			" apply a legacy reordering (Namespaces first, Webhooks last, etc)."+
-			" Use '"+string(krusty.ReorderOptionNone)+"' to suppress a final reordering.")
+			" Use '"+string(krusty.ReorderOptionNone)+"' to suppress a final reordering."+
+			" Use '"+string(krusty.ReorderOptionCustom)+"' to apply the order of --"+flagReorderFileName+".")
+	set.StringVar(
+		&theFlags.reorderFile, flagReorderFileName, "",
+		"File listing the GVKs of the resources in output order, e.g.\n"+
+			"- kind: Namespace\n"+
+			"- group: apiextensions.k8s.io\n"+
+			"  kind: CustomResourceDefinition\n"+
+			"- kind: '"+krusty.CustomOrderWildcard+"'\n"+
+			"- group: admissionregistration.k8s.io\n"+
+			"Empty fields match any value, and the entry of kind '"+krusty.CustomOrderWildcard+"'"+
+			" matches the resources no other entry matches.")
 }
 
 func validateFlagReorderOutput() error {
 	switch theFlags.reorderOutput {
 	case string(krusty.ReorderOptionNone), string(krusty.ReorderOptionLegacy):
+		if theFlags.reorderFile != "" {
+			return fmt.Errorf("--%s requires --%s %s",
+				flagReorderFileName, flagReorderOutputName, krusty.ReorderOptionCustom)
+		}
+		return nil
+	case string(krusty.ReorderOptionCustom):
+		if theFlags.reorderFile == "" {
+			return fmt.Errorf("--%s %s requires --%s",
+				flagReorderOutputName, krusty.ReorderOptionCustom, flagReorderFileName)
+		}
 		return nil
 	default:
 		return fmt.Errorf(
 			"illegal flag value --%s %s; legal values: %v",
 			flagReorderOutputName, theFlags.reorderOutput,
-			[]string{string(krusty.ReorderOptionLegacy), string(krusty.ReorderOptionNone)})
+			[]string{string(krusty.ReorderOptionLegacy), string(krusty.ReorderOptionNone),
+				string(krusty.ReorderOptionCustom)})
+	}
+}
+
+// readFlagReorderFile returns the order of the file of --reorder-file,
+// if any.
+func readFlagReorderFile(fSys filesys.FileSystem) ([]resid.Gvk, error) {
+	if theFlags.reorderFile == "" {
+		return nil, nil
+	}
+	content, err := fSys.ReadFile(theFlags.reorderFile)
+	if err != nil {
+		return nil, err
+	}
+	var order []resid.Gvk
+	if err := yaml.Unmarshal(content, &order); err != nil {
+		return nil, fmt.Errorf("invalid --%s %s, expected a list of GVKs: %w",
+			flagReorderFileName, theFlags.reorderFile, err)
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("--%s %s is empty", flagReorderFileName, theFlags.reorderFile)
+	}
+	wildcards := 0
+	for _, gvk := range order {
+		if gvk.Kind == krusty.CustomOrderWildcard {
+			wildcards++
+			if gvk.Group != "" || gvk.Version != "" {
+				return nil, fmt.Errorf("invalid --%s %s: the entry of kind '%s' can't have a group or version",
+					flagReorderFileName, theFlags.reorderFile, krusty.CustomOrderWildcard)
+			}
+		}
+	}
+	if wildcards > 1 {
+		return nil, fmt.Errorf("invalid --%s %s: several entries of kind '%s'",
+			flagReorderFileName, theFlags.reorderFile, krusty.CustomOrderWildcard)
 	}
+	return order, nil
 }
 
 func getFlagReorderOutput(flags *flag.FlagSet) krusty.ReorderOption {
@@ -44,6 +107,8 @@ func getFlagReorderOutput(flags *flag.FlagSet) krusty.ReorderOption {
 		return krusty.ReorderOptionNone
 	case string(krusty.ReorderOptionLegacy):
 		return krusty.ReorderOptionLegacy
+	case string(krusty.ReorderOptionCustom):
+		return krusty.ReorderOptionCustom
 	default:
 		return krusty.ReorderOptionUnspecified
 	}
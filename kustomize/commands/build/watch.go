@@ -0,0 +1,148 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	flagWatchName         = "watch"
+	flagWatchIntervalName = "watch-interval"
+)
+
+func AddFlagWatch(set *pflag.FlagSet) {
+	set.BoolVar(
+		&theFlags.watch,
+		flagWatchName,
+		false,
+		"Build again whenever one of the local files read by the build changes,"+
+			" including the files of its bases and components.")
+	set.DurationVar(
+		&theFlags.watchInterval,
+		flagWatchIntervalName,
+		time.Second,
+		"Interval between checks for changed files in --"+flagWatchName+" mode.")
+}
+
+func validateFlagWatch() error {
+	if theFlags.watch && theFlags.watchInterval <= 0 {
+		return fmt.Errorf("--%s must be positive", flagWatchIntervalName)
+	}
+	return nil
+}
+
+// watch calls build, then calls it again each time one of the files it read
+// from fSys changes, until ctx is done.  Remote bases are not watched.  Build errors are written to errOut,
+// and don't stop watching the files read until the error.
+func watch(ctx context.Context, fSys filesys.FileSystem, interval time.Duration,
+	errOut io.Writer, build func(fSys filesys.FileSystem) error) {
+	for {
+		recorder := newRecordingFs(fSys)
+		if err := build(recorder); err != nil {
+			fmt.Fprintf(errOut, "Error: %v\n", err)
+		}
+		files := recorder.files()
+		fmt.Fprintf(errOut, "Watching %d files for changes\n", len(files))
+		if !waitForChange(ctx, fSys, interval, files) {
+			return
+		}
+	}
+}
+
+// waitForChange returns true when the content of one of files has changed,
+// or false when ctx is done.
+func waitForChange(ctx context.Context, fSys filesys.FileSystem,
+	interval time.Duration, files map[string][]byte) bool {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+		for _, path := range sortedPaths(files) {
+			content, err := fSys.ReadFile(path)
+			if err != nil {
+				// removed files are changed files
+				content = nil
+			}
+			if !bytes.Equal(content, files[path]) || (content == nil) != (files[path] == nil) {
+				return true
+			}
+		}
+	}
+}
+
+func sortedPaths(files map[string][]byte) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// recordingFs is a FileSystem recording the content of the files read
+// through it.
+type recordingFs struct {
+	filesys.FileSystem
+
+	mu   sync.Mutex
+	read map[string][]byte
+}
+
+func newRecordingFs(fSys filesys.FileSystem) *recordingFs {
+	return &recordingFs{FileSystem: fSys, read: map[string][]byte{}}
+}
+
+// ReadFile implements FileSystem, and records the content of the file.
+func (fs *recordingFs) ReadFile(path string) ([]byte, error) {
+	content, err := fs.FileSystem.ReadFile(path)
+	fs.record(path, content, err)
+	return content, err
+}
+
+// Open implements FileSystem, and records the content of the file.
+func (fs *recordingFs) Open(path string) (filesys.File, error) {
+	f, err := fs.FileSystem.Open(path)
+	if err == nil {
+		content, readErr := fs.FileSystem.ReadFile(path)
+		fs.record(path, content, readErr)
+	}
+	return f, err
+}
+
+func (fs *recordingFs) record(path string, content []byte, err error) {
+	if err != nil {
+		// a missing file is watched as well, it may be created
+		content = nil
+	} else if content == nil {
+		content = []byte{}
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.read[path] = content
+}
+
+// files returns the content of the files read, by path.
+func (fs *recordingFs) files() map[string][]byte {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	files := make(map[string][]byte, len(fs.read))
+	for path, content := range fs.read {
+		files[path] = content
+	}
+	return files
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package build
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestWatch(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	if err := fSys.WriteFile("base/configmap.yaml", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var builds []string
+	errOut := new(bytes.Buffer)
+	watch(ctx, fSys, time.Millisecond, errOut, func(fSys filesys.FileSystem) error {
+		content, err := fSys.ReadFile("base/configmap.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// a missing file is watched, so that creating it triggers a build
+		_, _ = fSys.ReadFile("base/patch.yaml")
+		builds = append(builds, string(content))
+		switch len(builds) {
+		case 1:
+			// changing a file read triggers a build
+			return fSys.WriteFile("base/configmap.yaml", []byte("b"))
+		case 2:
+			// so does creating a file which was missing, even if the build failed
+			if err := fSys.WriteFile("base/patch.yaml", []byte("c")); err != nil {
+				t.Fatal(err)
+			}
+			return fmt.Errorf("build failed")
+		case 3:
+			// writing a file which isn't read doesn't
+			if err := fSys.WriteFile("unrelated.yaml", []byte("d")); err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				cancel()
+			}()
+		}
+		return nil
+	})
+	if fmt.Sprint(builds) != "[a b b]" {
+		t.Fatalf("unexpected builds: %v", builds)
+	}
+	const expected = `Watching 2 files for changes
+Error: build failed
+Watching 2 files for changes
+Watching 2 files for changes
+`
+	if errOut.String() != expected {
+		t.Fatalf("Expected:\n%s\nBut got:\n%s\n", expected, errOut.String())
+	}
+}
+
+func TestValidateFlagWatch(t *testing.T) {
+	theFlags.watch = true
+	theFlags.watchInterval = 0
+	defer func() { theFlags.watch = false }()
+	if err := validateFlagWatch(); err == nil || err.Error() != "--watch-interval must be positive" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -4,7 +4,10 @@
 package build
 
 import (
+	"bytes"
+	"fmt"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"sigs.k8s.io/kustomize/api/resmap"
@@ -61,3 +64,100 @@ func fileName(res *resource.Resource) string {
 	return strings.ToLower(res.GetGvk().StringWoEmptyField()) +
 		"_" + strings.ToLower(res.GetName()) + ".yaml"
 }
+
+// clusterScopedNamespace is the value of the {namespace} placeholder
+// of cluster-scoped resources.  Namespace names can't contain '_',
+// so it doesn't collide with a namespace.
+const clusterScopedNamespace = "_cluster"
+
+var fileNamePlaceholder = regexp.MustCompile(`{[^{}]*}`)
+
+// validateFileNameTemplate returns an error if the file name template
+// contains unknown placeholders, or would write outside of the output directory.
+func validateFileNameTemplate(template string) error {
+	for _, p := range fileNamePlaceholder.FindAllString(template, -1) {
+		if _, ok := fileNameFields(nil)[p]; !ok {
+			return fmt.Errorf(
+				"unknown placeholder %s in file name template %q", p, template)
+		}
+	}
+	if filepath.IsAbs(template) || filepath.IsAbs(filepath.FromSlash(template)) {
+		return fmt.Errorf("file name template %q must be a relative path", template)
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(template), "/") {
+		if elem == ".." {
+			return fmt.Errorf(
+				"file name template %q must not refer to a parent directory", template)
+		}
+	}
+	return nil
+}
+
+// fileNameFields returns the values of the file name template placeholders
+// for res.  If res is nil, the values are empty.
+func fileNameFields(res *resource.Resource) map[string]string {
+	fields := map[string]string{
+		"{group}":     "",
+		"{version}":   "",
+		"{kind}":      "",
+		"{name}":      "",
+		"{namespace}": "",
+	}
+	if res == nil {
+		return fields
+	}
+	id := res.CurId()
+	fields["{group}"] = id.Group
+	fields["{version}"] = id.Version
+	fields["{kind}"] = id.Kind
+	fields["{name}"] = id.Name
+	fields["{namespace}"] = id.EffectiveNamespace()
+	if id.IsClusterScoped() {
+		fields["{namespace}"] = clusterScopedNamespace
+	}
+	for k, v := range fields {
+		// placeholder values must not create directories
+		fields[k] = strings.ReplaceAll(strings.ToLower(v), "/", "_")
+	}
+	return fields
+}
+
+// templatedFileName expands the placeholders of template for res.
+func templatedFileName(template string, res *resource.Resource) string {
+	fields := fileNameFields(res)
+	return fileNamePlaceholder.ReplaceAllStringFunc(template, func(p string) string {
+		return fields[p]
+	})
+}
+
+// WriteTemplatedFiles writes the resources of m to the files in dirPath named
+// by the file name template, see validateFileNameTemplate.  Resources with the
+// same file name are written to the same file, in the order of m.
+func (w Writer) WriteTemplatedFiles(dirPath, template string, m resmap.ResMap) error {
+	if err := validateFileNameTemplate(template); err != nil {
+		return err
+	}
+	var fNames []string
+	contents := map[string][][]byte{}
+	for _, res := range m.Resources() {
+		fName := filepath.Join(dirPath, filepath.FromSlash(templatedFileName(template, res)))
+		yml, err := res.AsYAML()
+		if err != nil {
+			return err
+		}
+		if _, found := contents[fName]; !found {
+			fNames = append(fNames, fName)
+		}
+		contents[fName] = append(contents[fName], yml)
+	}
+	for _, fName := range fNames {
+		if err := w.fSys.MkdirAll(filepath.Dir(fName)); err != nil {
+			return err
+		}
+		content := bytes.Join(contents[fName], []byte("---\n"))
+		if err := w.fSys.WriteFile(fName, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
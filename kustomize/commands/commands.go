@@ -16,9 +16,19 @@ import (
 	"sigs.k8s.io/kustomize/cmd/config/configcobra"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/build"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/create"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/diff"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/edit"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/export"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/format"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/graph"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/images"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/userconfig"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/localize"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/migrate"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/openapi"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/test"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/update"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/verify"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/version"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
@@ -42,7 +52,13 @@ func NewDefaultCommand() *cobra.Command {
 		Long: `
 Manages declarative configuration of Kubernetes.
 See https://sigs.k8s.io/kustomize
+
+Default values of flags may be set in the user configuration file
+$XDG_CONFIG_HOME/kustomize/config.yaml, or the file in $KUSTOMIZE_CONFIG.
 `,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return userconfig.LoadAndApply(fSys, userconfig.DefaultPath(), cmd)
+		},
 	}
 
 	pvd := provider.NewDefaultDepProvider()
@@ -55,6 +71,15 @@ See https://sigs.k8s.io/kustomize
 		version.NewCmdVersion(stdOut),
 		openapi.NewCmdOpenAPI(stdOut),
 		localize.NewCmdLocalize(fSys),
+		diff.NewCmdDiff(fSys, stdOut),
+		graph.NewCmdGraph(fSys, stdOut),
+		format.NewCmdFormat(fSys, stdOut),
+		images.NewCmdImages(fSys, stdOut),
+		migrate.NewCmdMigrate(fSys, stdOut),
+		export.NewCmdExport(fSys, stdOut),
+		test.NewCmdTest(fSys, stdOut),
+		update.NewCmdUpdate(fSys, stdOut),
+		verify.NewCmdVerify(fSys, stdOut),
 	)
 	configcobra.AddCommands(c, konfig.ProgramName)
 
@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/kustomize/api/konfig"
 	ldrhelper "sigs.k8s.io/kustomize/api/pkg/loader"
 	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/util"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -27,6 +29,8 @@ type createFlags struct {
 	suffix          string
 	detectResources bool
 	detectRecursive bool
+	detectDepth     int
+	splitCrds       bool
 	path            string
 }
 
@@ -45,6 +49,10 @@ func NewCmdCreate(fSys filesys.FileSystem, rf *resource.Factory) *cobra.Command
 	# Create a new kustomization detecting resources in the current directory.
 	kustomize create --autodetect
 
+	# Create a new kustomization detecting resources in the current directory and
+	# its subdirectories up to 2 levels deep, adding CRDs to the crds field.
+	kustomize create --autodetect --recursive --depth 2 --split-crds
+
 	# Create a new kustomization with multiple resources and fields set.
 	kustomize create --resources deployment.yaml,service.yaml,../base --namespace staging --nameprefix acme-
 `,
@@ -92,6 +100,16 @@ func NewCmdCreate(fSys filesys.FileSystem, rf *resource.Factory) *cobra.Command
 		"recursive",
 		false,
 		"Enable recursive directory searching for resource auto-detection.")
+	c.Flags().IntVar(
+		&opts.detectDepth,
+		"depth",
+		0,
+		"Maximum depth of subdirectories searched by --recursive, 0 means no limit.")
+	c.Flags().BoolVar(
+		&opts.splitCrds,
+		"split-crds",
+		false,
+		"Add auto-detected files of CustomResourceDefinitions to the crds field instead of resources.")
 	return c
 }
 
@@ -107,12 +125,21 @@ func runCreate(opts createFlags, fSys filesys.FileSystem, rf *resource.Factory)
 	if _, err = kustfile.NewKustomizationFile(fSys); err == nil {
 		return fmt.Errorf("kustomization file already exists")
 	}
+	var detected detectedFiles
 	if opts.detectResources {
-		detected, err := detectResources(fSys, rf, opts.path, opts.detectRecursive)
+		depth := opts.detectDepth
+		if !opts.detectRecursive {
+			depth = -1
+		}
+		detected, err = detectResources(fSys, rf, opts.path, depth)
 		if err != nil {
 			return err
 		}
-		for _, resource := range detected {
+		if !opts.splitCrds {
+			detected.resources = append(detected.resources, detected.crds...)
+			detected.crds = nil
+		}
+		for _, resource := range detected.resources {
 			if kustfile.StringInSlice(resource, resources) {
 				continue
 			}
@@ -133,6 +160,8 @@ func runCreate(opts createFlags, fSys filesys.FileSystem, rf *resource.Factory)
 		return err
 	}
 	m.Resources = resources
+	m.Crds = detected.crds
+	m.ConfigMapGenerator = envGenerators(detected.envs)
 	m.Namespace = opts.namespace
 	m.NamePrefix = opts.prefix
 	m.NameSuffix = opts.suffix
@@ -149,8 +178,27 @@ func runCreate(opts createFlags, fSys filesys.FileSystem, rf *resource.Factory)
 	return mf.Write(m)
 }
 
-func detectResources(fSys filesys.FileSystem, rf *resource.Factory, base string, recursive bool) ([]string, error) {
-	var paths []string
+// detectedFiles are the files found by detectResources.
+type detectedFiles struct {
+	// resources are the files of resources and the directories of kustomizations.
+	resources []string
+	// crds are the files containing only CustomResourceDefinitions.
+	crds []string
+	// envs are the .env and .properties files.
+	envs []string
+}
+
+// envFileExtensions are the extensions of files of key=value pairs.
+var envFileExtensions = map[string]bool{".env": true, ".properties": true}
+
+// detectResources searches base for files to add to a kustomization, descending
+// into subdirectories up to depth levels; -1 means not at all, and 0 means no limit.
+//
+// Files that aren't resources, like helm templates and kustomize configuration, are
+// skipped, as are hidden directories and helm charts. Directories containing a
+// kustomization are added as a whole.
+func detectResources(fSys filesys.FileSystem, rf *resource.Factory, base string, depth int) (detectedFiles, error) {
+	var detected detectedFiles
 	err := fSys.Walk(base, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -159,28 +207,107 @@ func detectResources(fSys filesys.FileSystem, rf *resource.Factory, base string,
 			return nil
 		}
 		if info.IsDir() {
-			if !recursive {
+			if depth < 0 || (depth > 0 && dirDepth(base, path) > depth) ||
+				strings.HasPrefix(info.Name(), ".") || fSys.Exists(filepath.Join(path, "Chart.yaml")) {
 				return filepath.SkipDir
 			}
 			// If a sub-directory contains an existing kustomization file add the
 			// directory as a resource and do not decend into it.
 			for _, kfilename := range konfig.RecognizedKustomizationFileNames() {
 				if fSys.Exists(filepath.Join(path, kfilename)) {
-					paths = append(paths, path)
+					detected.resources = append(detected.resources, path)
 					return filepath.SkipDir
 				}
 			}
 			return nil
 		}
+		if envFileExtensions[filepath.Ext(path)] || info.Name() == ".env" {
+			detected.envs = append(detected.envs, path)
+			return nil
+		}
 		fContents, err := fSys.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		if _, err := rf.SliceFromBytes(fContents); err != nil {
+		if helmTemplate.Match(fContents) {
+			return nil
+		}
+		resources, err := rf.SliceFromBytes(fContents)
+		if err != nil || len(resources) == 0 {
 			return nil
 		}
-		paths = append(paths, path)
+		crds := 0
+		for _, res := range resources {
+			if isKustomizeConfig(res) || res.GetAnnotations()[konfig.IgnoredByKustomizeAnnotation] != "" {
+				return nil
+			}
+			if res.GetKind() == "CustomResourceDefinition" {
+				crds++
+			}
+		}
+		if crds == len(resources) {
+			detected.crds = append(detected.crds, path)
+		} else {
+			detected.resources = append(detected.resources, path)
+		}
 		return nil
 	})
-	return paths, err
+	return detected, err
 }
+
+// isKustomizeConfig returns true if res is a kustomization or component.
+func isKustomizeConfig(res *resource.Resource) bool {
+	switch res.GetApiVersion() {
+	case types.KustomizationVersion, types.ComponentVersion:
+		return true
+	}
+	return false
+}
+
+// dirDepth returns the number of directories from base to the subdirectory path.
+func dirDepth(base, path string) int {
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return 0
+	}
+	return len(strings.Split(rel, string(filepath.Separator)))
+}
+
+// envGenerators returns a ConfigMap generator for each env file, named after the file.
+func envGenerators(envs []string) []types.ConfigMapArgs {
+	var generators []types.ConfigMapArgs
+	names := make(map[string]bool)
+	for _, env := range envs {
+		name := generatorName(env)
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%s-%d", generatorName(env), i)
+		}
+		names[name] = true
+		generators = append(generators, types.ConfigMapArgs{GeneratorArgs: types.GeneratorArgs{
+			Name:          name,
+			KvPairSources: types.KvPairSources{EnvSources: []string{env}},
+		}})
+	}
+	return generators
+}
+
+// generatorName returns the name of the generator of the env file path:
+// its base name without extension, or the name of its directory for .env.
+func generatorName(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if name == "" {
+		name = filepath.Base(filepath.Dir(path))
+	}
+	name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-.")
+	if name == "" {
+		return "env"
+	}
+	return name
+}
+
+// helmTemplate matches the actions of helm templates, but not the
+// templates in the values of resources, like alerting rules.
+var helmTemplate = regexp.MustCompile(`\{\{-?\s*(\.Values|\.Release|\.Chart|\.Capabilities|include\s|template\s|define\s)`)
+
+// invalidNameChars are the characters not allowed in the names of ConfigMaps.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
@@ -203,3 +203,102 @@ func TestCreateWithDetectRecursive(t *testing.T) {
 		t.Fatalf("expected %+v but got %+v", expected, m.Resources)
 	}
 }
+
+func writeDetectRepoContent(fSys filesys.FileSystem) {
+	writeDetectContent(fSys)
+	fSys.WriteFile("/crd.yaml", []byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com`))
+	fSys.WriteFile("/app.env", []byte("FOO=bar\n"))
+	fSys.WriteFile("/sub/Settings.properties", []byte("a=b\n"))
+	fSys.WriteFile("/sub/.env", []byte("B=c\n"))
+	fSys.WriteFile("/component.yaml", []byte(`
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+resources:
+- test.yaml`))
+	fSys.WriteFile("/local.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: local
+  annotations:
+    config.kubernetes.io/local-config: "true"`))
+	fSys.WriteFile("/rules.yaml", []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: rules
+data:
+  summary: "{{ $labels.instance }} is down"`))
+	fSys.WriteFile("/templates/deployment.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "chart.fullname" . }}`))
+	fSys.WriteFile("/chart/Chart.yaml", []byte(`
+name: chart
+version: 1.0.0`))
+	fSys.WriteFile("/chart/values.yaml", []byte(`
+kind: Service
+metadata:
+  name: test4`))
+	fSys.WriteFile("/.github/service.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: test5`))
+	fSys.WriteFile("/sub/deeper/test.yaml", []byte(`
+apiVersion: v1
+kind: Service
+metadata:
+  name: test6`))
+}
+
+func TestCreateWithDetectRepo(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeDetectRepoContent(fSys)
+	opts := createFlags{path: "/", detectResources: true, detectRecursive: true, detectDepth: 1, splitCrds: true}
+	err := runCreate(opts, fSys, factory)
+	if err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	m := readKustomizationFS(t, fSys)
+	assert.Equal(t, []string{"/overlay", "/rules.yaml", "/sub/test.yaml", "/test.yaml"}, m.Resources)
+	assert.Equal(t, []string{"/crd.yaml"}, m.Crds)
+	assert.Equal(t, []types.ConfigMapArgs{
+		{GeneratorArgs: types.GeneratorArgs{Name: "app",
+			KvPairSources: types.KvPairSources{EnvSources: []string{"/app.env"}}}},
+		{GeneratorArgs: types.GeneratorArgs{Name: "sub",
+			KvPairSources: types.KvPairSources{EnvSources: []string{"/sub/.env"}}}},
+		{GeneratorArgs: types.GeneratorArgs{Name: "settings",
+			KvPairSources: types.KvPairSources{EnvSources: []string{"/sub/Settings.properties"}}}},
+	}, m.ConfigMapGenerator)
+}
+
+func TestCreateWithDetectCrdsAsResources(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeDetectRepoContent(fSys)
+	opts := createFlags{path: "/", detectResources: true}
+	err := runCreate(opts, fSys, factory)
+	if err != nil {
+		t.Fatalf("unexpected cmd error: %v", err)
+	}
+	m := readKustomizationFS(t, fSys)
+	assert.Equal(t, []string{"/rules.yaml", "/test.yaml", "/crd.yaml"}, m.Resources)
+	assert.Empty(t, m.Crds)
+	assert.Len(t, m.ConfigMapGenerator, 1)
+}
+
+func TestGeneratorName(t *testing.T) {
+	for path, expected := range map[string]string{
+		"app.env":                  "app",
+		"config/My_App.properties": "my-app",
+		"config/.env":              "config",
+		".env":                     "env",
+	} {
+		assert.Equal(t, expected, generatorName(path), path)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const contextLines = 3
+
+type flags struct {
+	againstGitRef string
+}
+
+// NewCmdDiff returns a new diff command.
+func NewCmdDiff(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f flags
+	cmd := &cobra.Command{
+		Use:   "diff DIR_A [DIR_B]",
+		Short: "[Alpha] Shows the differences between the resources of two kustomizations",
+		Long: `[Alpha] Builds two kustomizations and shows the differences between
+their resources.
+
+Resources are matched by group, version, kind, namespace and name, and the
+differences of each changed resource are shown as a unified diff of its YAML,
+with sorted fields, so that only changes of the values of the resources are
+shown.  Added and removed resources are shown entirely.
+
+With --against-git-ref, the kustomization DIR_A is compared to the same
+kustomization at a git ref of the repository containing it.
+`,
+		Example: `
+# Show the differences between two overlays
+kustomize diff overlays/staging overlays/production
+
+# Show the changes of an overlay since the main branch
+kustomize diff overlays/production --against-git-ref main
+`,
+		SilenceUsage: true,
+		Args:         cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dirA, dirB := "", ""
+			switch {
+			case f.againstGitRef != "" && len(args) == 2:
+				return errors.Errorf("specify a single directory with --against-git-ref")
+			case f.againstGitRef != "":
+				tmpDir, err := os.MkdirTemp("", "kustomize-diff-")
+				if err != nil {
+					return errors.Wrap(err)
+				}
+				defer os.RemoveAll(tmpDir)
+				if dirA, err = checkoutGitRef(args[0], f.againstGitRef, tmpDir); err != nil {
+					return err
+				}
+				dirB = args[0]
+			case len(args) == 2:
+				dirA, dirB = args[0], args[1]
+			default:
+				return errors.Errorf("specify two directories, or a directory and --against-git-ref")
+			}
+			return Diff(fSys, w, dirA, dirB)
+		},
+	}
+	// no shorthand to avoid conflation with other flags
+	cmd.Flags().StringVar(&f.againstGitRef,
+		"against-git-ref",
+		"",
+		`Git ref to compare the kustomization directory against.`)
+	return cmd
+}
+
+// Diff builds the kustomizations in dirA and dirB, and writes the
+// differences between their resources to w.
+func Diff(fSys filesys.FileSystem, w io.Writer, dirA, dirB string) error {
+	resourcesA, err := build(fSys, dirA)
+	if err != nil {
+		return err
+	}
+	resourcesB, err := build(fSys, dirB)
+	if err != nil {
+		return err
+	}
+	ids := map[string]bool{}
+	for id := range resourcesA {
+		ids[id] = true
+	}
+	for id := range resourcesB {
+		ids[id] = true
+	}
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	var out bytes.Buffer
+	for _, id := range sorted {
+		a, b := resourcesA[id], resourcesB[id]
+		if a == b {
+			continue
+		}
+		fromFile, toFile := "a/"+id, "b/"+id
+		if _, found := resourcesA[id]; !found {
+			fromFile = "/dev/null"
+		}
+		if _, found := resourcesB[id]; !found {
+			toFile = "/dev/null"
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(a),
+			B:        splitLines(b),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  contextLines,
+		})
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		out.WriteString(diff)
+	}
+	_, err = w.Write(out.Bytes())
+	return errors.Wrap(err)
+}
+
+// build builds the kustomization in dir, and returns the YAML of its
+// resources by id.
+func build(fSys filesys.FileSystem, dir string) (map[string]string, error) {
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, dir)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "building %s", dir)
+	}
+	return resourcesByID(m)
+}
+
+func resourcesByID(m resmap.ResMap) (map[string]string, error) {
+	resources := map[string]string{}
+	for _, res := range m.Resources() {
+		// the fields of the YAML are sorted, so that the order
+		// of the fields in the sources doesn't matter
+		yml, err := res.AsYAML()
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		id := res.CurId()
+		resources[fmt.Sprintf("%s/%s/%s", id.Gvk.StringWoEmptyField(), id.EffectiveNamespace(), id.Name)] = string(yml)
+	}
+	return resources, nil
+}
+
+// splitLines splits s into lines, keeping their line endings.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,192 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/diff"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const base = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  ports:
+  - port: 80
+`
+
+func writeOverlays(t *testing.T, fSys filesys.FileSystem) {
+	t.Helper()
+	require.NoError(t, fSys.WriteFile("base/kustomization.yaml", []byte("resources:\n- resources.yaml\n")))
+	require.NoError(t, fSys.WriteFile("base/resources.yaml", []byte(base)))
+	require.NoError(t, fSys.WriteFile("staging/kustomization.yaml", []byte(`namespace: app
+resources:
+- ../base
+`)))
+	require.NoError(t, fSys.WriteFile("production/kustomization.yaml", []byte(`namespace: app
+resources:
+- ../base
+- configmap.yaml
+replicas:
+- name: app
+  count: 3
+patches:
+- patch: |-
+    $patch: delete
+    apiVersion: v1
+    kind: Service
+    metadata:
+      name: app
+`)))
+	// the order of the fields doesn't matter
+	require.NoError(t, fSys.WriteFile("production/configmap.yaml", []byte(`kind: ConfigMap
+metadata:
+  name: config
+apiVersion: v1
+`)))
+}
+
+const expectedDiff = `--- a/apps_v1_Deployment/app/app
++++ b/apps_v1_Deployment/app/app
+@@ -4,7 +4,7 @@
+   name: app
+   namespace: app
+ spec:
+-  replicas: 1
++  replicas: 3
+   template:
+     spec:
+       containers:
+--- /dev/null
++++ b/v1_ConfigMap/app/config
+@@ -0,0 +1,5 @@
++apiVersion: v1
++kind: ConfigMap
++metadata:
++  name: config
++  namespace: app
+--- a/v1_Service/app/app
++++ /dev/null
+@@ -1,8 +0,0 @@
+-apiVersion: v1
+-kind: Service
+-metadata:
+-  name: app
+-  namespace: app
+-spec:
+-  ports:
+-  - port: 80
+`
+
+func TestDiff(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeOverlays(t, fSys)
+	out := new(bytes.Buffer)
+	cmd := diff.NewCmdDiff(fSys, out)
+	cmd.SetArgs([]string{"staging", "production"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, expectedDiff, out.String())
+
+	out.Reset()
+	cmd = diff.NewCmdDiff(fSys, out)
+	cmd.SetArgs([]string{"staging", "staging"})
+	require.NoError(t, cmd.Execute())
+	require.Empty(t, out.String())
+}
+
+func TestDiff_invalidArgs(t *testing.T) {
+	for name, args := range map[string][]string{
+		"oneDir":         {"staging"},
+		"gitRefWithDirs": {"staging", "production", "--against-git-ref", "main"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cmd := diff.NewCmdDiff(filesys.MakeFsInMemory(), new(bytes.Buffer))
+			cmd.SetArgs(args)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			require.Error(t, cmd.Execute())
+		})
+	}
+}
+
+func TestDiff_againstGitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	fSys := filesys.MakeFsOnDisk()
+	require.NoError(t, fSys.MkdirAll(filepath.Join(repo, "base")))
+	require.NoError(t, fSys.MkdirAll(filepath.Join(repo, "staging")))
+	require.NoError(t, fSys.MkdirAll(filepath.Join(repo, "production")))
+	writeOverlays(t, filesys.FileSystemOrOnDisk{FileSystem: &prefixFs{FileSystem: fSys, prefix: repo}})
+	run("init", "-q")
+	run("add", ".")
+	run("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+	// the working tree is compared to the ref, including uncommitted changes
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "staging", "kustomization.yaml"),
+		[]byte("namespace: app\nresources:\n- ../base\nreplicas:\n- name: app\n  count: 3\n"), 0600))
+
+	out := new(bytes.Buffer)
+	cmd := diff.NewCmdDiff(fSys, out)
+	cmd.SetArgs([]string{filepath.Join(repo, "staging"), "--against-git-ref", "HEAD"})
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, `--- a/apps_v1_Deployment/app/app
++++ b/apps_v1_Deployment/app/app
+@@ -4,7 +4,7 @@
+   name: app
+   namespace: app
+ spec:
+-  replicas: 1
++  replicas: 3
+   template:
+     spec:
+       containers:
+`, out.String())
+
+	// refs are never taken as options of git
+	for _, ref := range []string{"--output=" + filepath.Join(repo, "out.tar"), "-v"} {
+		cmd = diff.NewCmdDiff(fSys, new(bytes.Buffer))
+		cmd.SetArgs([]string{filepath.Join(repo, "staging"), "--against-git-ref=" + ref})
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		require.Error(t, cmd.Execute())
+	}
+	require.NoFileExists(t, filepath.Join(repo, "out.tar"))
+}
+
+// prefixFs writes the files to a directory of the file system.
+type prefixFs struct {
+	filesys.FileSystem
+	prefix string
+}
+
+func (fs *prefixFs) WriteFile(path string, data []byte) error {
+	return fs.FileSystem.WriteFile(filepath.Join(fs.prefix, path), data)
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+// checkoutGitRef extracts the files of the git repository containing dir at
+// ref to tmpDir, and returns the path of dir in tmpDir.  The whole repository
+// is extracted, as the kustomization in dir may refer to files outside of it.
+// Neither the working tree nor the index of the repository are modified.
+func checkoutGitRef(dir, ref, tmpDir string) (string, error) {
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	// resolve ref to its tree first, so that a ref starting with a dash
+	// can't be taken as an option of git archive
+	tree, err := git(dir, "rev-parse", "--verify", "--end-of-options", ref+"^{tree}")
+	if err != nil {
+		return "", err
+	}
+	tree = strings.TrimSpace(tree)
+	// git archive only archives the current directory of subdirectories
+	archive, err := exec.Command("git", "-C", strings.TrimSpace(root),
+		"archive", "--format=tar", tree).Output()
+	if err != nil {
+		return "", gitError(err, "archive", tree)
+	}
+	err = kio.ArchiveReader{Reader: bytes.NewReader(archive), Format: kio.TarArchive}.
+		Extract(filesys.MakeFsOnDisk(), tmpDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(tmpDir, filepath.FromSlash(strings.TrimSpace(prefix))), nil
+}
+
+// git runs a git command in dir and returns its output.
+func git(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return "", gitError(err, args...)
+	}
+	return string(out), nil
+}
+
+func gitError(err error, args ...string) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return errors.Errorf("git %s: %s", strings.Join(args, " "),
+			strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return errors.WrapPrefixf(err, "git %s", strings.Join(args, " "))
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package add
+
+import (
+	"errors"
+	"log"
+	"reflect"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+type addReplacementOptions struct {
+	Path    string
+	Source  types.SourceSelector
+	Target  types.TargetSelector
+	Options struct {
+		Source types.FieldOptions
+		Target types.FieldOptions
+	}
+}
+
+// newCmdAddReplacement adds a replacement to the kustomization file.
+func newCmdAddReplacement(fSys filesys.FileSystem) *cobra.Command {
+	var o addReplacementOptions
+	o.Target.Select = &types.Selector{}
+
+	cmd := &cobra.Command{
+		Use:   "replacement",
+		Short: "Add an item to replacements field",
+		Long: `This command will add an item to replacements field in the kustomization file.
+The item is either the path to a file containing replacements, or a replacement
+copying the field of a source resource to the fields of the target resources.
+`,
+		Example: `
+		add replacement --path {filepath}
+		add replacement --source-kind ConfigMap --source-name config --source-fieldpath data.host \
+		    --target-kind Deployment --target-fieldpath spec.template.spec.containers.0.env.0.value`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate()
+			if err != nil {
+				return err
+			}
+			return o.RunAddReplacement(fSys)
+		},
+	}
+	cmd.Flags().StringVar(&o.Path, "path", "",
+		"Path to a file containing replacements. Cannot be used with the source and target flags.")
+
+	cmd.Flags().StringVar(&o.Source.Group, "source-group", "", "API group of the source resource")
+	cmd.Flags().StringVar(&o.Source.Version, "source-version", "", "API version of the source resource")
+	cmd.Flags().StringVar(&o.Source.Kind, "source-kind", "", "Kind of the source resource")
+	cmd.Flags().StringVar(&o.Source.Name, "source-name", "", "Name of the source resource")
+	cmd.Flags().StringVar(&o.Source.Namespace, "source-namespace", "", "Namespace of the source resource")
+	cmd.Flags().StringVar(&o.Source.FieldPath, "source-fieldpath", "",
+		"Path to the field of the source resource to copy. Defaults to "+types.DefaultReplacementFieldPath)
+	cmd.Flags().StringVar(&o.Options.Source.Delimiter, "source-delimiter", "",
+		"Delimiter to split the source value with")
+	cmd.Flags().IntVar(&o.Options.Source.Index, "source-index", 0,
+		"Index of the part of the split source value to copy")
+
+	cmd.Flags().StringVar(&o.Target.Select.Group, "target-group", "", "API group of the target resources")
+	cmd.Flags().StringVar(&o.Target.Select.Version, "target-version", "", "API version of the target resources")
+	cmd.Flags().StringVar(&o.Target.Select.Kind, "target-kind", "", "Kind of the target resources")
+	cmd.Flags().StringVar(&o.Target.Select.Name, "target-name", "", "Name of the target resources")
+	cmd.Flags().StringVar(&o.Target.Select.Namespace, "target-namespace", "", "Namespace of the target resources")
+	cmd.Flags().StringVar(&o.Target.Select.AnnotationSelector, "target-annotation-selector", "",
+		"annotationSelector of the target resources")
+	cmd.Flags().StringVar(&o.Target.Select.LabelSelector, "target-label-selector", "",
+		"labelSelector of the target resources")
+	cmd.Flags().StringSliceVar(&o.Target.FieldPaths, "target-fieldpath", nil,
+		"Path to a field of the target resources to write the value to. May be repeated.")
+	cmd.Flags().StringVar(&o.Options.Target.Delimiter, "target-delimiter", "",
+		"Delimiter to split the target value with")
+	cmd.Flags().IntVar(&o.Options.Target.Index, "target-index", 0,
+		"Index of the part of the split target value to replace")
+	cmd.Flags().BoolVar(&o.Options.Target.Create, "create", false,
+		"Create the target fields if they are missing")
+
+	return cmd
+}
+
+// Validate validates addReplacement command.
+func (o *addReplacementOptions) Validate() error {
+	inline := o.Source != (types.SourceSelector{}) || o.Options.Source != (types.FieldOptions{}) ||
+		*o.Target.Select != (types.Selector{}) || len(o.Target.FieldPaths) > 0 ||
+		o.Options.Target != (types.FieldOptions{})
+	if o.Path != "" {
+		if inline {
+			return errors.New("path can't be set at the same time as the source and target flags")
+		}
+		return nil
+	}
+	if o.Source.Kind == "" && o.Source.Name == "" {
+		return errors.New("must provide either path, or the source kind or name")
+	}
+	if *o.Target.Select == (types.Selector{}) {
+		return errors.New("must provide at least one of the target flags selecting resources")
+	}
+	if len(o.Target.FieldPaths) == 0 {
+		return errors.New("must provide at least one target fieldpath")
+	}
+	for _, fp := range o.Target.FieldPaths {
+		if fp == "" {
+			return errors.New("target fieldpaths can't be empty")
+		}
+	}
+	return nil
+}
+
+// replacement returns the replacement to add.
+func (o *addReplacementOptions) replacement() types.ReplacementField {
+	if o.Path != "" {
+		return types.ReplacementField{Path: o.Path}
+	}
+	source := o.Source
+	if o.Options.Source != (types.FieldOptions{}) {
+		options := o.Options.Source
+		source.Options = &options
+	}
+	target := o.Target
+	if o.Options.Target != (types.FieldOptions{}) {
+		options := o.Options.Target
+		target.Options = &options
+	}
+	return types.ReplacementField{Replacement: types.Replacement{
+		Source:  &source,
+		Targets: []*types.TargetSelector{&target},
+	}}
+}
+
+// RunAddReplacement runs addReplacement command (do real work).
+func (o *addReplacementOptions) RunAddReplacement(fSys filesys.FileSystem) error {
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return err
+	}
+
+	m, err := mf.Read()
+	if err != nil {
+		return err
+	}
+
+	r := o.replacement()
+	for _, existing := range m.Replacements {
+		if reflect.DeepEqual(existing, r) {
+			log.Printf("replacement already in kustomization file")
+			return nil
+		}
+	}
+	m.Replacements = append(m.Replacements, r)
+
+	return mf.Write(m)
+}
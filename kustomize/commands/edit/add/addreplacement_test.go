@@ -0,0 +1,110 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package add
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testutils_test "sigs.k8s.io/kustomize/kustomize/v5/commands/internal/testutils"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestAddReplacement(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+replacements:
+- path: replacements.yaml
+`))
+
+	cmd := newCmdAddReplacement(fSys)
+	args := []string{
+		"--source-kind", "ConfigMap",
+		"--source-name", "config",
+		"--source-fieldpath", "data.host",
+		"--target-kind", "Deployment",
+		"--target-label-selector", "app=web",
+		"--target-fieldpath", "spec.template.spec.containers.0.env.0.value",
+		"--target-fieldpath", "metadata.annotations.host",
+		"--target-delimiter", ":",
+		"--create",
+	}
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute())
+	// adding the same replacement again is a no-op
+	cmd = newCmdAddReplacement(fSys)
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute())
+
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+replacements:
+- path: replacements.yaml
+- source:
+    fieldPath: data.host
+    kind: ConfigMap
+    name: config
+  targets:
+  - fieldPaths:
+    - spec.template.spec.containers.0.env.0.value
+    - metadata.annotations.host
+    options:
+      create: true
+      delimiter: ':'
+    select:
+      kind: Deployment
+      labelSelector: app=web
+`, string(content))
+}
+
+func TestAddReplacementWithPath(t *testing.T) {
+	fSys := filesys.MakeEmptyDirInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+
+	cmd := newCmdAddReplacement(fSys)
+	cmd.SetArgs([]string{"--path", "replacements.yaml"})
+	require.NoError(t, cmd.Execute())
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "replacements:\n- path: replacement.yaml\n- path: replacements.yaml\n")
+}
+
+func TestAddReplacementValidation(t *testing.T) {
+	testCases := map[string]struct {
+		args []string
+		err  string
+	}{
+		"pathAndInline": {
+			args: []string{"--path", "r.yaml", "--source-kind", "ConfigMap"},
+			err:  "path can't be set at the same time as the source and target flags",
+		},
+		"noSource": {
+			args: []string{"--target-kind", "Deployment", "--target-fieldpath", "spec.replicas"},
+			err:  "must provide either path, or the source kind or name",
+		},
+		"noTarget": {
+			args: []string{"--source-kind", "ConfigMap", "--target-fieldpath", "spec.replicas"},
+			err:  "must provide at least one of the target flags selecting resources",
+		},
+		"noTargetFieldPath": {
+			args: []string{"--source-kind", "ConfigMap", "--target-kind", "Deployment"},
+			err:  "must provide at least one target fieldpath",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fSys := filesys.MakeEmptyDirInMemory()
+			testutils_test.WriteTestKustomization(fSys)
+			cmd := newCmdAddReplacement(fSys)
+			cmd.SetArgs(tc.args)
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			assert.EqualError(t, cmd.Execute(), tc.err)
+		})
+	}
+}
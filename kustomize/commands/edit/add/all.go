@@ -45,14 +45,22 @@ func NewCmdAdd(
 	# Adds one or more commonAnnotations to the kustomization
 	kustomize edit add annotation {annotationKey1:annotationValue1},{annotationKey2:annotationValue2}
 
+	# Adds a replacement copying a field of a ConfigMap to the Deployments
+	kustomize edit add replacement --source-kind ConfigMap --source-name config --source-fieldpath data.host \
+	    --target-kind Deployment --target-fieldpath spec.template.spec.containers.0.env.0.value
+
 	# Adds a transformer configuration to the kustomization
 	kustomize edit add transformer <filepath>
+
+	# Adds a generator configuration to the kustomization
+	kustomize edit add generator <filepath>
 `,
 		Args: cobra.MinimumNArgs(1),
 	}
 	c.AddCommand(
 		newCmdAddResource(fSys),
 		newCmdAddPatch(fSys),
+		newCmdAddReplacement(fSys),
 		newCmdAddComponent(fSys),
 		newCmdAddSecret(fSys, ldr, rf),
 		newCmdAddConfigMap(fSys, ldr, rf),
@@ -5,6 +5,7 @@ package fix
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"path"
 	"strconv"
@@ -18,32 +19,58 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
-func ConvertVarsToReplacements(fSys filesys.FileSystem, k *types.Kustomization) error {
+// untranslatableError is returned for usages of a var that can't be
+// expressed as a replacement target.
+type untranslatableError struct {
+	msg string
+}
+
+func (e *untranslatableError) Error() string {
+	return e.msg
+}
+
+// ConvertVarsToReplacements converts the vars of k to replacements, and replaces
+// their $(VAR) references in the resources and patches with placeholders.
+// Vars that can't be converted are kept in k, and returned with the reason.
+func ConvertVarsToReplacements(fSys filesys.FileSystem, k *types.Kustomization) ([]string, error) {
 	if k.Vars == nil {
-		return nil
+		return nil, nil
 	}
 
 	k.Resources = append(k.Resources, k.Bases...)
-	k.Replacements = []types.ReplacementField{}
 
 	files, err := filesTouchedByKustomize(k, "", fSys)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	var kept []types.Var
+	var unconverted []string
 	for _, v := range k.Vars {
 		repl := &types.Replacement{}
-		if err := addTargets(repl, v.Name, files, fSys); err != nil {
-			return err
+		err := addTargets(repl, v.Name, files, fSys)
+		var untranslatable *untranslatableError
+		switch {
+		case errors.As(err, &untranslatable):
+			kept = append(kept, v)
+			unconverted = append(unconverted, fmt.Sprintf("%s: %s", v.Name, err.Error()))
+			continue
+		case err != nil:
+			return nil, err
+		case len(repl.Targets) == 0:
+			kept = append(kept, v)
+			unconverted = append(unconverted, fmt.Sprintf(
+				"%s: no references found in resources or patches", v.Name))
+			continue
 		}
 		copySourceFromVars(repl, v)
 		if err := setPlaceholderValue(v.Name, files, fSys); err != nil {
-			return err
+			return nil, err
 		}
 		k.Replacements = append(k.Replacements, types.ReplacementField{Replacement: *repl})
 	}
-	k.Vars = nil
-	return nil
+	k.Vars = kept
+	return unconverted, nil
 }
 
 var patchTarget = make(map[string]types.Patch)
@@ -115,7 +142,7 @@ func addTargets(repl *types.Replacement, varName string, files []string, fSys fi
 		for _, n := range nodes {
 			fieldPaths, options, err := findVarName(n, varName, []string{})
 			if err != nil {
-				return fmt.Errorf("error with %s: %s", file, err.Error())
+				return fmt.Errorf("error with %s: %w", file, err)
 			}
 			targets, err := constructTargets(file, n, fieldPaths, options)
 			if err != nil {
@@ -202,6 +229,10 @@ func constructFieldOptions(value string, varString string) ([]*types.FieldOption
 	if value == varString {
 		return []*types.FieldOptions{{}}, nil
 	}
+	if strings.Count(value, varString) > 1 {
+		return nil, &untranslatableError{
+			msg: fmt.Sprintf("cannot convert all vars to replacements; %s is used more than once in a value", varString)}
+	}
 
 	var delimiter string
 	var index int
@@ -220,7 +251,8 @@ func constructFieldOptions(value string, varString string) ([]*types.FieldOption
 		pre := string(value[i-1])
 		post := string(value[i+len(varString)])
 		if pre != post {
-			return nil, fmt.Errorf("cannot convert all vars to replacements; %s is not delimited", varString)
+			return nil, &untranslatableError{
+				msg: fmt.Sprintf("cannot convert all vars to replacements; %s is not delimited", varString)}
 		}
 		delimiter = pre
 		index = indexOf(varString, strings.Split(value, delimiter))
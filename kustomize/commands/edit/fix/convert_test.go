@@ -4,6 +4,7 @@
 package fix
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
@@ -338,6 +339,16 @@ vars:
     kind: Secret
     name: my-secret
     apiVersion: v1
+- name: SOME_CONFIG_NAME
+  objref:
+    kind: ConfigMap
+    name: my-config
+    apiVersion: v1
+- name: UNUSED
+  objref:
+    kind: ConfigMap
+    name: my-config
+    apiVersion: v1
 `)
 	pod := []byte(`
 apiVersion: v1
@@ -351,16 +362,105 @@ spec:
     env:
     - name: SECRET_TOKEN
       value: var$(SOME_SECRET_NAME)/path
+    - name: CONFIG
+      value: $(SOME_CONFIG_NAME)
 `)
 
 	fSys := filesys.MakeFsInMemory()
 	testutils_test.WriteTestKustomizationWith(fSys, kustomization)
 	fSys.WriteFile("pod.yaml", pod)
-	cmd := NewCmdFix(fSys, os.Stdout)
+	var out bytes.Buffer
+	cmd := NewCmdFix(fSys, &out)
+	assert.NoError(t, cmd.Flags().Set("vars", "true"))
+	assert.NoError(t, cmd.RunE(cmd, nil))
+	assert.Contains(t, out.String(), `
+The following vars could not be converted to replacements and were kept:
+  SOME_SECRET_NAME: error with pod.yaml: cannot convert all vars to replacements; $(SOME_SECRET_NAME) is not delimited
+  UNUSED: no references found in resources or patches
+`)
+
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	assert.NoError(t, err)
+	assert.Equal(t, `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+
+resources:
+- pod.yaml
+
+vars:
+- fieldref: {}
+  name: SOME_SECRET_NAME
+  objref:
+    apiVersion: v1
+    kind: Secret
+    name: my-secret
+- fieldref: {}
+  name: UNUSED
+  objref:
+    apiVersion: v1
+    kind: ConfigMap
+    name: my-config
+replacements:
+- source:
+    kind: ConfigMap
+    name: my-config
+    version: v1
+  targets:
+  - fieldPaths:
+    - spec.containers.0.env.1.value
+    select:
+      kind: Pod
+      name: my-pod
+      version: v1
+`, string(content))
+
+	content, err = fSys.ReadFile("pod.yaml")
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "value: var$(SOME_SECRET_NAME)/path")
+	assert.Contains(t, string(content), "value: SOME_CONFIG_NAME_PLACEHOLDER")
+}
+
+func TestFixVarsUsedTwiceInValue(t *testing.T) {
+	kustomization := []byte(`
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+
+resources:
+- pod.yaml
+
+vars:
+- name: SOME_SECRET_NAME
+  objref:
+    kind: Secret
+    name: my-secret
+    apiVersion: v1
+`)
+	pod := []byte(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: my-pod
+spec:
+  containers:
+  - image: myimage
+    name: hello
+    args:
+    - $(SOME_SECRET_NAME)-$(SOME_SECRET_NAME)
+`)
+
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, kustomization)
+	fSys.WriteFile("pod.yaml", pod)
+	var out bytes.Buffer
+	cmd := NewCmdFix(fSys, &out)
 	assert.NoError(t, cmd.Flags().Set("vars", "true"))
-	err := cmd.RunE(cmd, nil)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "error with pod.yaml: cannot convert all vars to replacements; $(SOME_SECRET_NAME) is not delimited")
+	assert.NoError(t, cmd.RunE(cmd, nil))
+	assert.Contains(t, out.String(),
+		"SOME_SECRET_NAME: error with pod.yaml: cannot convert all vars to replacements; $(SOME_SECRET_NAME) is used more than once in a value")
+	content, err := fSys.ReadFile("pod.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, string(pod), string(content))
 }
 
 func TestFixVarsWithPatchBasic(t *testing.T) {
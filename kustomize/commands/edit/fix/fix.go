@@ -60,7 +60,7 @@ func RunFix(fSys filesys.FileSystem, w io.Writer) error {
 	}
 
 	if flags.vars {
-		err = ConvertVarsToReplacements(fSys, m)
+		unconverted, err := ConvertVarsToReplacements(fSys, m)
 		if err != nil {
 			return err
 		}
@@ -70,6 +70,13 @@ Fixed fields:
   patchesStrategicMerge -> patches
   commonLabels -> labels
   vars -> replacements`)
+		if len(unconverted) > 0 {
+			fmt.Fprintln(w, `
+The following vars could not be converted to replacements and were kept:`)
+			for _, msg := range unconverted {
+				fmt.Fprintf(w, "  %s\n", msg)
+			}
+		}
 	} else {
 		fmt.Fprintln(w, `
 Fixed fields:
@@ -39,6 +39,9 @@ func NewCmdRemove(
 
 	# Removes one or more transformers from the kustomization file
 	kustomize edit remove transformer <filepath>
+
+	# Removes one or more generators from the kustomization file
+	kustomize edit remove generator <filepath>
 `,
 		Args: cobra.MinimumNArgs(1),
 	}
@@ -50,6 +53,7 @@ func NewCmdRemove(
 		newCmdRemoveAnnotation(fSys, v.MakeAnnotationNameValidator()),
 		newCmdRemovePatch(fSys),
 		newCmdRemoveTransformer(fSys),
+		newCmdRemoveGenerator(fSys),
 		newCmdRemoveBuildMetadata(fSys),
 	)
 	return c
@@ -0,0 +1,84 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package remove
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+type removeGeneratorOptions struct {
+	generatorFilePaths []string
+}
+
+// newCmdRemoveGenerator removes the names of files containing a generator
+// configuration from the kustomization file.
+func newCmdRemoveGenerator(fSys filesys.FileSystem) *cobra.Command {
+	var o removeGeneratorOptions
+
+	cmd := &cobra.Command{
+		Use: "generator",
+		Short: "Removes one or more generators from " +
+			konfig.DefaultKustomizationFileName(),
+		Example: `
+		remove generator my-generator.yml
+		remove generator generator1.yml generator2.yml generator3.yml
+		remove generator generators/*.yml
+		`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := o.Validate(args)
+			if err != nil {
+				return err
+			}
+			return o.RunRemoveGenerator(fSys)
+		},
+	}
+	return cmd
+}
+
+// Validate validates removeGenerator command.
+func (o *removeGeneratorOptions) Validate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("must specify a generator file")
+	}
+	o.generatorFilePaths = args
+	return nil
+}
+
+// RunRemoveGenerator runs Generator command (do real work).
+func (o *removeGeneratorOptions) RunRemoveGenerator(fSys filesys.FileSystem) error {
+	mf, err := kustfile.NewKustomizationFile(fSys)
+	if err != nil {
+		return err
+	}
+
+	m, err := mf.Read()
+	if err != nil {
+		return err
+	}
+
+	generators, err := globPatterns(m.Generators, o.generatorFilePaths)
+	if err != nil {
+		return err
+	}
+
+	if len(generators) == 0 {
+		return nil
+	}
+
+	newGenerators := make([]string, 0, len(m.Generators))
+	for _, generator := range m.Generators {
+		if kustfile.StringInSlice(generator, generators) {
+			continue
+		}
+		newGenerators = append(newGenerators, generator)
+	}
+
+	m.Generators = newGenerators
+	return mf.Write(m)
+}
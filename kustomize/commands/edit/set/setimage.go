@@ -4,7 +4,11 @@
 package set
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"regexp"
 	"sort"
 	"strings"
@@ -18,6 +22,7 @@ import (
 
 type setImageOptions struct {
 	imageMap map[string]types.Image
+	fromFile string
 }
 
 var pattern = regexp.MustCompile(`^(.*):([a-zA-Z0-9._-]*|\*)$`)
@@ -78,18 +83,72 @@ and overwrite the previous ones if the image name exists.
 
 The image tag can only contain alphanumeric, '.', '_' and '-'. Passing * (asterisk) either as the new name, 
 the new tag, or the digest will preserve the appropriate values from the kustomization file.
+
+The command
+  set image --from-file images.txt
+sets all the images of the file at once. The file contains an image per line,
+in the formats of the arguments, e.g. written by a CI pipeline:
+
+# comments and empty lines are ignored
+postgres=eu.gcr.io/my-project/postgres:latest
+my-app@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+
+or a JSON array of images, either in the formats of the arguments or as objects:
+
+[{"name": "postgres", "newName": "eu.gcr.io/my-project/postgres", "newTag": "latest"}]
+
+Use - to read the images from stdin. Images passed as arguments take precedence
+over the images of the file.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
 			if err != nil {
 				return err
 			}
+			if o.fromFile != "" {
+				if err = o.readImagesFile(fSys, cmd.InOrStdin()); err != nil {
+					return err
+				}
+			}
 			return o.RunSetImage(fSys)
 		},
+		ValidArgsFunction: completeImageNames(fSys),
 	}
+	cmd.Flags().StringVar(&o.fromFile, "from-file", "",
+		"Path to a file of images to set, one per line or a JSON array. Use - for stdin.")
 	return cmd
 }
 
+// completeImageNames completes the names of the images of the kustomization
+// not set by the previous arguments, without a trailing space so that the
+// new name, tag or digest can follow.
+func completeImageNames(fSys filesys.FileSystem) func(
+	*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		mf, err := kustfile.NewKustomizationFile(fSys)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		m, err := mf.Read()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		set := make(map[string]bool)
+		for _, arg := range args {
+			if img, err := parse(arg); err == nil {
+				set[img.Name] = true
+			}
+		}
+		var names []string
+		for _, img := range m.Images {
+			if !set[img.Name] && strings.HasPrefix(img.Name, toComplete) {
+				names = append(names, img.Name)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
 type overwrite struct {
 	name   string
 	digest string
@@ -98,7 +157,7 @@ type overwrite struct {
 
 // Validate validates setImage command.
 func (o *setImageOptions) Validate(args []string) error {
-	if len(args) == 0 {
+	if len(args) == 0 && o.fromFile == "" {
 		return errImageNoArgs
 	}
 
@@ -114,6 +173,74 @@ func (o *setImageOptions) Validate(args []string) error {
 	return nil
 }
 
+// readImagesFile adds the images of the file fromFile to the images to set,
+// unless they were passed as arguments.
+func (o *setImageOptions) readImagesFile(fSys filesys.FileSystem, stdin io.Reader) error {
+	var content []byte
+	var err error
+	if o.fromFile == "-" {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = fSys.ReadFile(o.fromFile)
+	}
+	if err != nil {
+		return err
+	}
+	images, err := parseImagesFile(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", o.fromFile, err)
+	}
+	for _, img := range images {
+		if _, found := o.imageMap[img.Name]; !found {
+			o.imageMap[img.Name] = img
+		}
+	}
+	return nil
+}
+
+// parseImagesFile parses the images of a file, which is either a JSON
+// array of images, or contains an image per line.
+func parseImagesFile(content []byte) ([]types.Image, error) {
+	trimmed := bytes.TrimSpace(content)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var entries []json.RawMessage
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, err
+		}
+		var images []types.Image
+		for i, entry := range entries {
+			var img types.Image
+			var arg string
+			if err := json.Unmarshal(entry, &arg); err == nil {
+				img, err = parse(arg)
+				if err != nil {
+					return nil, fmt.Errorf("item %d: %w", i, err)
+				}
+			} else if err := json.Unmarshal(entry, &img); err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			if img.Name == "" {
+				return nil, fmt.Errorf("item %d: %w", i, errImageInvalidArgs)
+			}
+			images = append(images, img)
+		}
+		return images, nil
+	}
+	var images []types.Image
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		img, err := parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
 // RunSetImage runs setImage command.
 func (o *setImageOptions) RunSetImage(fSys filesys.FileSystem) error {
 	mf, err := kustfile.NewKustomizationFile(fSys)
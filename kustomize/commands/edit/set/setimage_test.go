@@ -5,6 +5,7 @@ package set
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -443,3 +444,135 @@ func TestSetImage(t *testing.T) {
 		})
 	}
 }
+
+func TestSetImageFromFile(t *testing.T) {
+	testCases := map[string]struct {
+		file       string
+		args       []string
+		fileOutput []string
+		err        string
+	}{
+		"lines": {
+			file: `# written by CI
+image1=my-image1:my-tag
+
+image2@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+`,
+			fileOutput: []string{
+				"images:",
+				"- name: image1",
+				"  newName: my-image1",
+				"  newTag: my-tag",
+				"- digest: sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3",
+				"  name: image2",
+			},
+		},
+		"json": {
+			file: `["image1=my-image1:my-tag", {"name": "image2", "newTag": "v2"}]`,
+			fileOutput: []string{
+				"images:",
+				"- name: image1",
+				"  newName: my-image1",
+				"  newTag: my-tag",
+				"- name: image2",
+				"  newTag: v2",
+			},
+		},
+		"args take precedence": {
+			file: "image1=my-image1:my-tag\nimage2:v2\n",
+			args: []string{"image1:v1"},
+			fileOutput: []string{
+				"images:",
+				"- name: image1",
+				"  newTag: v1",
+				"- name: image2",
+				"  newTag: v2",
+			},
+		},
+		"invalid line": {
+			file: "image1:v1\nimage2=\n",
+			err:  "images.txt: line 2: invalid format of image, use one of the following options:",
+		},
+		"json without name": {
+			file: `[{"newTag": "v2"}]`,
+			err:  "images.txt: item 0: invalid format of image",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			fSys := filesys.MakeFsInMemory()
+			testutils_test.WriteTestKustomization(fSys)
+			if err := fSys.WriteFile("images.txt", []byte(tc.file)); err != nil {
+				t.Fatal(err)
+			}
+			cmd := newCmdSetImage(fSys)
+			if err := cmd.Flags().Set("from-file", "images.txt"); err != nil {
+				t.Fatal(err)
+			}
+
+			err := cmd.RunE(cmd, tc.args)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing %q, got %v", tc.err, err)
+				}
+				content, _ := testutils_test.ReadTestKustomization(fSys)
+				if strings.Contains(string(content), "images:") {
+					t.Errorf("kustomization file was modified:\n%s", content)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			content, err := testutils_test.ReadTestKustomization(fSys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedStr := strings.Join(tc.fileOutput, "\n")
+			if !strings.Contains(string(content), expectedStr) {
+				t.Errorf("unexpected images in kustomization file. \nActual:\n%s\nExpected:\n%s", content, expectedStr)
+			}
+		})
+	}
+}
+
+func TestSetImageFromStdin(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+	cmd := newCmdSetImage(fSys)
+	cmd.SetIn(strings.NewReader("image1:v1\n"))
+	if err := cmd.Flags().Set("from-file", "-"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "- name: image1\n  newTag: v1") {
+		t.Errorf("unexpected images in kustomization file:\n%s", content)
+	}
+}
+
+func TestSetImageCompletion(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomizationWith(fSys, []byte(`
+images:
+- name: nginx
+  newTag: v1
+- name: node
+- name: postgres
+`))
+	cmd := newCmdSetImage(fSys)
+
+	names, _ := cmd.ValidArgsFunction(cmd, nil, "n")
+	if expected := []string{"nginx", "node"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+	names, _ = cmd.ValidArgsFunction(cmd, []string{"nginx=nginx:v2"}, "")
+	if expected := []string{"node", "postgres"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
@@ -5,6 +5,7 @@ package set
 
 import (
 	"errors"
+	"fmt"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,10 +14,17 @@ import (
 	"sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 type setReplicasOptions struct {
 	replicasMap map[string]types.Replica
+	kind        string
+	group       string
+	version     string
+	fieldPath   string
+	config      string
 }
 
 // errors
@@ -24,9 +32,15 @@ type setReplicasOptions struct {
 var (
 	errReplicasNoArgs      = errors.New("no replicas specified")
 	errReplicasInvalidArgs = errors.New(`invalid format of replica, use the following format: <name>=<count>`)
+	errReplicasNoKind      = errors.New("--group, --version and --fieldpath require --kind")
 )
 
-const replicasSeparator = "="
+const (
+	replicasSeparator = "="
+
+	defaultReplicasFieldPath = "spec/replicas"
+	defaultReplicasConfig    = "kustomizeconfig.yaml"
+)
 
 // newCmdSetReplicas sets the new replica count for a resource in the kustomization.
 func newCmdSetReplicas(fSys filesys.FileSystem) *cobra.Command {
@@ -48,15 +62,45 @@ replicas:
 
 to the kustomization file if it doesn't exist,
 and overwrite the previous ones if the replicas name exists.
+
+The replicas of Deployments, ReplicationControllers, ReplicaSets and
+StatefulSets are set by default.  For other kinds, e.g. custom resources,
+--kind adds the field of the replicas to the replicas field specs of a
+transformer configuration, which is added to the configurations of the
+kustomization:
+  set replicas my-db=3 --kind Database --group example.com --fieldpath spec/instances
+will add
+
+replicas:
+- group: example.com
+  kind: Database
+  path: spec/instances
+  create: true
+
+to kustomizeconfig.yaml, or the file of --config.
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := o.Validate(args)
 			if err != nil {
 				return err
 			}
+			if o.kind == "" && (o.group != "" || o.version != "" ||
+				cmd.Flags().Changed("fieldpath")) {
+				return errReplicasNoKind
+			}
 			return o.RunSetReplicas(fSys)
 		},
 	}
+	cmd.Flags().StringVar(&o.kind, "kind", "",
+		"Kind of the resources, if their replicas aren't set by default.")
+	cmd.Flags().StringVar(&o.group, "group", "",
+		"API group of the resources of --kind.")
+	cmd.Flags().StringVar(&o.version, "version", "",
+		"API version of the resources of --kind.")
+	cmd.Flags().StringVar(&o.fieldPath, "fieldpath", defaultReplicasFieldPath,
+		"Path of the replicas field of the resources of --kind, e.g. spec/size.")
+	cmd.Flags().StringVar(&o.config, "config", defaultReplicasConfig,
+		"Transformer configuration file the field spec of --kind is added to.")
 	return cmd
 }
 
@@ -108,9 +152,71 @@ func (o *setReplicasOptions) RunSetReplicas(fSys filesys.FileSystem) error {
 	})
 
 	m.Replicas = replicas
+	if o.kind != "" {
+		err = addReplicasFieldSpec(fSys, o.config, types.FieldSpec{
+			Gvk:                resid.Gvk{Group: o.group, Version: o.version, Kind: o.kind},
+			Path:               o.fieldPath,
+			CreateIfNotPresent: true,
+		})
+		if err != nil {
+			return err
+		}
+		if !kustfile.StringInSlice(o.config, m.Configurations) {
+			m.Configurations = append(m.Configurations, o.config)
+		}
+	}
 	return mf.Write(m)
 }
 
+// addReplicasFieldSpec adds fs to the replicas field specs of the
+// transformer configuration file at path, unless a field spec with the
+// same kind and path is there already.  The file is created if needed.
+func addReplicasFieldSpec(fSys filesys.FileSystem, path string, fs types.FieldSpec) error {
+	config := yaml.NewMapRNode(nil)
+	if fSys.Exists(path) {
+		content, err := fSys.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if config, err = yaml.Parse(string(content)); err != nil {
+			return fmt.Errorf("invalid transformer configuration %s: %w", path, err)
+		}
+	}
+	specs, err := config.Pipe(yaml.LookupCreate(yaml.SequenceNode, "replicas"))
+	if err != nil {
+		return err
+	}
+	elements, err := specs.Elements()
+	if err != nil {
+		return err
+	}
+	for _, e := range elements {
+		var existing types.FieldSpec
+		if err := e.YNode().Decode(&existing); err != nil {
+			return fmt.Errorf("invalid transformer configuration %s: %w", path, err)
+		}
+		if existing.Gvk.Equals(fs.Gvk) && existing.Path == fs.Path {
+			return nil
+		}
+	}
+	content, err := yaml.Marshal(fs)
+	if err != nil {
+		return err
+	}
+	spec, err := yaml.Parse(string(content))
+	if err != nil {
+		return err
+	}
+	if err := specs.PipeE(yaml.Append(spec.YNode())); err != nil {
+		return err
+	}
+	out, err := config.String()
+	if err != nil {
+		return err
+	}
+	return fSys.WriteFile(path, []byte(out))
+}
+
 func parseReplicasArg(arg string) (types.Replica, error) {
 	// matches a name and a replica count
 	// <name>=<count>
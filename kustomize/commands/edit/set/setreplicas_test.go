@@ -143,3 +143,71 @@ func TestSetReplicas(t *testing.T) {
 		})
 	}
 }
+
+func TestSetReplicasKind(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+	if err := fSys.WriteFile("kustomizeconfig.yaml", []byte(`# custom resources
+replicas:
+- path: spec/size
+  kind: Cache
+`)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		cmd := newCmdSetReplicas(fSys)
+		for name, value := range map[string]string{
+			"kind":      "Database",
+			"group":     "example.com",
+			"fieldpath": "spec/instances",
+		} {
+			if err := cmd.Flags().Set(name, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := cmd.RunE(cmd, []string{"db=3"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	content, err := testutils_test.ReadTestKustomization(fSys)
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	for _, expected := range []string{
+		"replicas:\n- count: 3\n  name: db\n",
+		"configurations:\n- kustomizeconfig.yaml\n",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("unexpected kustomization file.\nActual:\n%s\nExpected:\n%s", content, expected)
+		}
+	}
+	config, err := fSys.ReadFile("kustomizeconfig.yaml")
+	if err != nil {
+		t.Fatalf("unexpected read error: %v", err)
+	}
+	expected := `# custom resources
+replicas:
+- path: spec/size
+  kind: Cache
+- group: example.com
+  kind: Database
+  path: spec/instances
+  create: true
+`
+	if string(config) != expected {
+		t.Errorf("unexpected transformer configuration.\nActual:\n%s\nExpected:\n%s", config, expected)
+	}
+}
+
+func TestSetReplicasKindRequired(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	testutils_test.WriteTestKustomization(fSys)
+	cmd := newCmdSetReplicas(fSys)
+	if err := cmd.Flags().Set("fieldpath", "spec/size"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.RunE(cmd, []string{"db=3"}); err != errReplicasNoKind {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
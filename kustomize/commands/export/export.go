@@ -0,0 +1,200 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package export exports live resources of a cluster as a kustomization.
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/format"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// defaultKinds are the resource types exported by default, those usually
+// managed by users rather than by controllers.
+var defaultKinds = []string{
+	"deployments",
+	"statefulsets",
+	"daemonsets",
+	"cronjobs",
+	"services",
+	"ingresses",
+	"configmaps",
+	"secrets",
+	"serviceaccounts",
+	"roles",
+	"rolebindings",
+	"persistentvolumeclaims",
+	"horizontalpodautoscalers",
+	"poddisruptionbudgets",
+	"networkpolicies",
+}
+
+type flags struct {
+	namespace string
+	kinds     []string
+	selector  string
+	context   string
+	output    string
+}
+
+// runKubectl runs kubectl with args, and returns its output.
+var runKubectl = func(args ...string) ([]byte, error) {
+	command := exec.Command("kubectl", args...)
+	var stdout, stderr bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		return nil, fmt.Errorf("running kubectl %s: %w\n%s",
+			strings.Join(args, " "), err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// NewCmdExport returns a new export command.
+func NewCmdExport(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f flags
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "[Alpha] Exports the live resources of a namespace as a kustomization",
+		Long: `[Alpha] Reads the live resources of a namespace of the cluster of the
+user's kubeconfig with kubectl, and writes them as a buildable base: a file
+per resource, and a kustomization setting the namespace.
+
+The fields populated by the server, e.g. status, uid, resourceVersion
+and managedFields, are removed.  Resources owned by other resources,
+e.g. ReplicaSets of Deployments, and those created by the cluster, e.g.
+the default ServiceAccount, aren't exported.
+`,
+		Example: `
+# Export the resources of the namespace shop to bases/shop
+kustomize export --namespace shop -o bases/shop
+
+# Export the deployments and services labelled app=api
+kustomize export -n shop --kinds deployments,services -l app=api -o api
+`,
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubectlArgs := []string{"get", strings.Join(f.kinds, ","), "-o", "yaml"}
+			if f.namespace != "" {
+				kubectlArgs = append(kubectlArgs, "--namespace", f.namespace)
+			}
+			if f.selector != "" {
+				kubectlArgs = append(kubectlArgs, "--selector", f.selector)
+			}
+			if f.context != "" {
+				kubectlArgs = append(kubectlArgs, "--context", f.context)
+			}
+			list, err := runKubectl(kubectlArgs...)
+			if err != nil {
+				return err
+			}
+			n, err := Export(fSys, f.output, list)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "exported %d resources to %s\n", n, f.output)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&f.namespace, "namespace", "n", "",
+		"Namespace of the resources.  Defaults to the namespace of the kubeconfig context.")
+	cmd.Flags().StringSliceVar(&f.kinds, "kinds", defaultKinds,
+		"Resource types to export, as understood by kubectl get.")
+	cmd.Flags().StringVarP(&f.selector, "selector", "l", "",
+		"Label selector of the resources to export.")
+	cmd.Flags().StringVar(&f.context, "context", "",
+		"Kubeconfig context of the cluster.")
+	cmd.Flags().StringVarP(&f.output, "output", "o", filesys.SelfDir,
+		"Directory the kustomization is written to.")
+	return cmd
+}
+
+// Export writes the resources of list, a List of live resources, to files
+// in dir with a kustomization using them, and returns the number of
+// resources exported.  See PruneServerFields.
+func Export(fSys filesys.FileSystem, dir string, list []byte) (int, error) {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if path := filepath.Join(dir, name); fSys.Exists(path) {
+			return 0, fmt.Errorf("%s already exists", path)
+		}
+	}
+	node, err := yaml.Parse(string(list))
+	if err != nil {
+		return 0, err
+	}
+	items, err := node.Pipe(yaml.Lookup("items"))
+	if err != nil {
+		return 0, err
+	}
+	var resources []*yaml.RNode
+	if items != nil {
+		if resources, err = items.Elements(); err != nil {
+			return 0, err
+		}
+	}
+	if err := fSys.MkdirAll(dir); err != nil {
+		return 0, err
+	}
+	k := &types.Kustomization{TypeMeta: types.TypeMeta{
+		APIVersion: types.KustomizationVersion,
+		Kind:       types.KustomizationKind,
+	}}
+	fileNames := map[string]bool{}
+	for _, rn := range resources {
+		if isServerManaged(rn) {
+			continue
+		}
+		ns := rn.GetNamespace()
+		if k.Namespace == "" {
+			k.Namespace = ns
+		} else if ns != "" && ns != k.Namespace {
+			return 0, fmt.Errorf("the resources are in several namespaces, %s and %s",
+				k.Namespace, ns)
+		}
+		if err := PruneServerFields(rn); err != nil {
+			return 0, err
+		}
+		name := resourceFileName(rn, fileNames)
+		out, err := rn.String()
+		if err != nil {
+			return 0, err
+		}
+		if err := fSys.WriteFile(filepath.Join(dir, name), []byte(out)); err != nil {
+			return 0, err
+		}
+		k.Resources = append(k.Resources, name)
+	}
+	content, err := yaml.Marshal(k)
+	if err != nil {
+		return 0, err
+	}
+	if content, err = format.Format(content, format.Options{}); err != nil {
+		return 0, err
+	}
+	return len(k.Resources), fSys.WriteFile(
+		filepath.Join(dir, konfig.DefaultKustomizationFileName()), content)
+}
+
+// resourceFileName returns the name of the file of rn, e.g.
+// deployment-app.yaml, which isn't used yet.
+func resourceFileName(rn *yaml.RNode, used map[string]bool) string {
+	prefix := strings.ToLower(rn.GetKind()) + "-" + rn.GetName()
+	name := prefix + ".yaml"
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s-%d.yaml", prefix, i)
+	}
+	used[name] = true
+	return name
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const liveList = `apiVersion: v1
+kind: List
+metadata:
+  resourceVersion: ""
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    annotations:
+      deployment.kubernetes.io/revision: "3"
+      kubectl.kubernetes.io/last-applied-configuration: |
+        {"apiVersion":"apps/v1","kind":"Deployment"}
+      team: shop
+    creationTimestamp: "2023-01-01T00:00:00Z"
+    generation: 3
+    managedFields:
+    - manager: kubectl
+    name: api
+    namespace: shop
+    resourceVersion: "1234"
+    uid: 9b4c0a1e-0000-0000-0000-000000000000
+  spec:
+    replicas: 2
+    selector:
+      matchLabels:
+        app: api
+    template:
+      metadata:
+        creationTimestamp: null
+        labels:
+          app: api
+      spec:
+        containers:
+        - image: api:1.0
+          name: api
+  status:
+    replicas: 2
+- apiVersion: apps/v1
+  kind: ReplicaSet
+  metadata:
+    name: api-5d8f
+    namespace: shop
+    ownerReferences:
+    - apiVersion: apps/v1
+      controller: true
+      kind: Deployment
+      name: api
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: api
+    namespace: shop
+  spec:
+    clusterIP: 10.0.0.12
+    clusterIPs:
+    - 10.0.0.12
+    ports:
+    - port: 80
+    selector:
+      app: api
+- apiVersion: v1
+  kind: ServiceAccount
+  metadata:
+    name: default
+    namespace: shop
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: kube-root-ca.crt
+    namespace: shop
+- apiVersion: v1
+  kind: Secret
+  metadata:
+    name: default-token-x1
+    namespace: shop
+  type: kubernetes.io/service-account-token
+`
+
+func TestExport(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	n, err := Export(fSys, "shop", []byte(liveList))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	for path, expected := range map[string]string{
+		"shop/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment-api.yaml
+- service-api.yaml
+namespace: shop
+`,
+		"shop/deployment-api.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  annotations:
+    team: shop
+  name: api
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: api
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      containers:
+      - image: api:1.0
+        name: api
+`,
+		"shop/service-api.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: api
+spec:
+  ports:
+  - port: 80
+  selector:
+    app: api
+`,
+	} {
+		actual, err := fSys.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(actual), path)
+	}
+
+	// the export builds
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, "shop")
+	require.NoError(t, err)
+	assert.Equal(t, 2, m.Size())
+
+	_, err = Export(fSys, "shop", []byte(liveList))
+	require.EqualError(t, err, "shop/kustomization.yaml already exists")
+}
+
+func TestExport_severalNamespaces(t *testing.T) {
+	_, err := Export(filesys.MakeFsInMemory(), "out", []byte(`kind: List
+items:
+- kind: ConfigMap
+  metadata:
+    name: a
+    namespace: shop
+- kind: ConfigMap
+  metadata:
+    name: a
+    namespace: billing
+`))
+	require.EqualError(t, err, "the resources are in several namespaces, shop and billing")
+}
+
+func TestCmdExport(t *testing.T) {
+	var kubectlArgs []string
+	defer func(run func(args ...string) ([]byte, error)) { runKubectl = run }(runKubectl)
+	runKubectl = func(args ...string) ([]byte, error) {
+		kubectlArgs = args
+		return []byte(liveList), nil
+	}
+
+	var out bytes.Buffer
+	cmd := NewCmdExport(filesys.MakeFsInMemory(), &out)
+	cmd.SetArgs([]string{"-n", "shop", "--kinds", "deployments,services", "-l", "app=api", "-o", "api"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, []string{"get", "deployments,services", "-o", "yaml",
+		"--namespace", "shop", "--selector", "app=api"}, kubectlArgs)
+	assert.Equal(t, "exported 2 resources to api\n", out.String())
+}
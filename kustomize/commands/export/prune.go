@@ -0,0 +1,114 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// serverFields are the paths of the fields populated by the server.
+var serverFields = [][]string{
+	{"status"},
+	{"metadata", "uid"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "deletionGracePeriodSeconds"},
+	{"metadata", "managedFields"},
+	{"metadata", "selfLink"},
+	{"metadata", "namespace"},
+	{"spec", "template", "metadata", "creationTimestamp"},
+	{"spec", "jobTemplate", "metadata", "creationTimestamp"},
+	{"spec", "jobTemplate", "spec", "template", "metadata", "creationTimestamp"},
+}
+
+// serverFieldsByKind are the paths of the fields populated by the server
+// in resources of a kind.
+var serverFieldsByKind = map[string][][]string{
+	"Service": {
+		{"spec", "clusterIP"},
+		{"spec", "clusterIPs"},
+	},
+	"PersistentVolumeClaim": {
+		{"spec", "volumeName"},
+	},
+}
+
+// serverAnnotations are the annotations added by the server or by
+// clients, and the prefixes of such annotations, ending with '/'.
+var serverAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+	"pv.kubernetes.io/",
+	"volume.kubernetes.io/",
+	"volume.beta.kubernetes.io/",
+	"control-plane.alpha.kubernetes.io/leader",
+}
+
+// PruneServerFields removes the fields of the live resource rn which are
+// populated by the server, such as its status, uid and managed fields,
+// and its namespace.
+func PruneServerFields(rn *yaml.RNode) error {
+	fields := append(append([][]string{}, serverFields...), serverFieldsByKind[rn.GetKind()]...)
+	for _, path := range fields {
+		parent, err := rn.Pipe(yaml.Lookup(path[:len(path)-1]...))
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			continue
+		}
+		if _, err := parent.Pipe(yaml.Clear(path[len(path)-1])); err != nil {
+			return err
+		}
+	}
+	annotations, err := rn.Pipe(yaml.Lookup(yaml.MetadataField, yaml.AnnotationsField))
+	if err != nil || annotations == nil {
+		return err
+	}
+	for key := range rn.GetAnnotations() {
+		if !isServerAnnotation(key) {
+			continue
+		}
+		if _, err := annotations.Pipe(yaml.Clear(key)); err != nil {
+			return err
+		}
+	}
+	if len(annotations.Content()) == 0 {
+		return rn.SetAnnotations(nil)
+	}
+	return nil
+}
+
+func isServerAnnotation(key string) bool {
+	for _, a := range serverAnnotations {
+		if key == a || (strings.HasSuffix(a, "/") && strings.HasPrefix(key, a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isServerManaged returns whether the live resource rn is created and
+// managed by the cluster or a controller, rather than by users.
+func isServerManaged(rn *yaml.RNode) bool {
+	owners, _ := rn.Pipe(yaml.Lookup("metadata", "ownerReferences"))
+	if owners != nil && len(owners.Content()) > 0 {
+		return true
+	}
+	name := rn.GetName()
+	switch rn.GetKind() {
+	case "ConfigMap":
+		return name == "kube-root-ca.crt"
+	case "ServiceAccount":
+		return name == "default"
+	case "Secret":
+		secretType, _ := rn.Pipe(yaml.Lookup("type"))
+		return yaml.GetValue(secretType) == "kubernetes.io/service-account-token"
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package format formats kustomization files.
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Options are the options of Format.
+type Options struct {
+	// Sort sorts the entries of the resources and images fields.  The
+	// order of resources may change the order of the build output, so
+	// it isn't sorted by default.
+	Sort bool
+}
+
+type flags struct {
+	check bool
+	sort  bool
+}
+
+// NewCmdFormat returns a new fmt command.
+func NewCmdFormat(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f flags
+	cmd := &cobra.Command{
+		Use:   "fmt [PATH]...",
+		Short: "Formats kustomization files",
+		Long: `Formats the kustomization files at the given paths, or in the given
+directories and their subdirectories, in place.  The current directory is
+formatted if no path is given.
+
+Formatting orders the fields of kustomizations like 'kustomize edit' does,
+writes lists in block style with a consistent indentation, and removes the
+quotes of values that don't need them.  Comments are kept.  Kustomization
+files in JSON stay JSON.
+`,
+		Example: `
+# Format the kustomizations of a repository
+kustomize fmt .
+
+# Fail, listing the kustomizations that aren't formatted, e.g. in CI
+kustomize fmt --check .
+
+# Also sort the resources and images of a kustomization
+kustomize fmt --sort overlays/production/kustomization.yaml
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{filesys.SelfDir}
+			}
+			return run(fSys, w, args, f)
+		},
+	}
+	cmd.Flags().BoolVar(&f.check, "check", false,
+		"List the kustomization files that aren't formatted, and fail if there are any, "+
+			"instead of formatting them.")
+	cmd.Flags().BoolVar(&f.sort, "sort", false,
+		"Sort the entries of the resources and images fields.  This can change the order "+
+			"of the build output.")
+	return cmd
+}
+
+func run(fSys filesys.FileSystem, w io.Writer, args []string, f flags) error {
+	var unformatted []string
+	for _, arg := range args {
+		paths, err := kustfile.KustomizationFiles(fSys, arg)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			content, err := fSys.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			formatted, err := Format(content, Options{Sort: f.sort})
+			if err != nil {
+				return fmt.Errorf("formatting %s: %w", path, err)
+			}
+			if bytes.Equal(content, formatted) {
+				continue
+			}
+			if f.check {
+				unformatted = append(unformatted, path)
+				fmt.Fprintln(w, path)
+				continue
+			}
+			if err := fSys.WriteFile(path, formatted); err != nil {
+				return err
+			}
+		}
+	}
+	if len(unformatted) > 0 {
+		return fmt.Errorf("%d kustomization files aren't formatted", len(unformatted))
+	}
+	return nil
+}
+
+// Format returns the formatted content of a kustomization file.
+func Format(content []byte, opts Options) ([]byte, error) {
+	if len(bytes.TrimSpace(content)) == 0 {
+		return content, nil
+	}
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(content)).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 ||
+		doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("a kustomization must be a mapping")
+	}
+	k := doc.Content[0]
+	orderFields(k)
+	if opts.Sort {
+		sortEntries(k)
+	}
+	if isJSON(content) {
+		var b bytes.Buffer
+		writeJSON(&b, k)
+		var out bytes.Buffer
+		if err := json.Indent(&out, b.Bytes(), "", "  "); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	}
+	normalizeStyle(&doc)
+	var out bytes.Buffer
+	e := yaml.NewEncoder(&out)
+	if err := e.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := e.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// orderFields orders the fields of the kustomization k like kustomize
+// edit writes them.  Unknown fields come last, in their original order.
+func orderFields(k *yaml.Node) {
+	order := map[string]int{}
+	for i, n := range kustfile.FieldNames() {
+		order[n] = i
+	}
+	rank := func(key string) int {
+		if i, ok := order[key]; ok {
+			return i
+		}
+		return len(order)
+	}
+	fields := make([][2]*yaml.Node, 0, len(k.Content)/2)
+	for i := 0; i+1 < len(k.Content); i += 2 {
+		fields = append(fields, [2]*yaml.Node{k.Content[i], k.Content[i+1]})
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		return rank(fields[i][0].Value) < rank(fields[j][0].Value)
+	})
+	k.Content = k.Content[:0]
+	for _, f := range fields {
+		k.Content = append(k.Content, f[0], f[1])
+	}
+}
+
+// sortEntries sorts the resources of the kustomization k by path, and
+// its images by name.
+func sortEntries(k *yaml.Node) {
+	for i := 0; i+1 < len(k.Content); i += 2 {
+		list := k.Content[i+1]
+		if list.Kind != yaml.SequenceNode {
+			continue
+		}
+		var key func(n *yaml.Node) string
+		switch k.Content[i].Value {
+		case "resources":
+			key = func(n *yaml.Node) string { return n.Value }
+		case "images":
+			key = func(n *yaml.Node) string { return kustfile.FieldValue(n, "name") }
+		default:
+			continue
+		}
+		sort.SliceStable(list.Content, func(a, b int) bool {
+			return key(list.Content[a]) < key(list.Content[b])
+		})
+	}
+}
+
+// normalizeStyle writes the lists and mappings of n in block style,
+// and its single line scalars without quotes, which the encoder adds
+// back where they are needed.
+func normalizeStyle(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.SequenceNode, yaml.MappingNode:
+		n.Style &^= yaml.FlowStyle
+	case yaml.ScalarNode:
+		if n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 &&
+			!strings.Contains(n.Value, "\n") {
+			n.Style &^= yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle
+		}
+	}
+	for _, c := range n.Content {
+		normalizeStyle(c)
+	}
+}
+
+// isJSON returns whether content is a JSON object.
+func isJSON(content []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte("{"))
+}
+
+// writeJSON writes n as compact JSON, keeping the order of fields.
+func writeJSON(b *bytes.Buffer, n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		b.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSONString(b, n.Content[i].Value)
+			b.WriteByte(':')
+			writeJSON(b, n.Content[i+1])
+		}
+		b.WriteByte('}')
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSON(b, c)
+		}
+		b.WriteByte(']')
+	case yaml.AliasNode:
+		writeJSON(b, n.Alias)
+	default:
+		switch n.ShortTag() {
+		case yaml.NodeTagInt, yaml.NodeTagFloat, yaml.NodeTagBool, yaml.NodeTagNull:
+			b.WriteString(n.Value)
+		default:
+			writeJSONString(b, n.Value)
+		}
+	}
+}
+
+func writeJSONString(b *bytes.Buffer, s string) {
+	// marshalling a string can't fail
+	out, _ := json.Marshal(s)
+	b.Write(out)
+}
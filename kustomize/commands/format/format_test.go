@@ -0,0 +1,167 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package format_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "sigs.k8s.io/kustomize/kustomize/v5/commands/format"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const unformatted = `# the production overlay
+images:
+  - name: 'nginx'
+    newTag: "1.25"
+  - name: busybox
+    newTag: "1.36"
+resources: [ service.yaml, ../../base ]
+namePrefix: "prod-"
+patches:
+- patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 3
+  target:
+    kind: Deployment
+# keep the labels of the base
+x-unknown: true
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+`
+
+func TestFormat(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts     Options
+		input    string
+		expected string
+	}{
+		"yaml": {
+			input: unformatted,
+			expected: `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- service.yaml
+- ../../base
+namePrefix: prod-
+patches:
+- patch: |-
+    - op: replace
+      path: /spec/replicas
+      value: 3
+  target:
+    kind: Deployment
+# the production overlay
+images:
+- name: nginx
+  newTag: "1.25"
+- name: busybox
+  newTag: "1.36"
+# keep the labels of the base
+x-unknown: true
+`,
+		},
+		"sort": {
+			opts: Options{Sort: true},
+			input: `resources:
+- service.yaml
+# the base
+- ../../base
+images:
+- name: nginx
+- name: busybox
+`,
+			expected: `resources:
+# the base
+- ../../base
+- service.yaml
+images:
+- name: busybox
+- name: nginx
+`,
+		},
+		"json": {
+			input: `{"resources": ["../../base"], "apiVersion": "kustomize.config.k8s.io/v1beta1",
+"replicas": [{"name": "app", "count": 3}], "x-enabled": true}`,
+			expected: `{
+  "apiVersion": "kustomize.config.k8s.io/v1beta1",
+  "resources": [
+    "../../base"
+  ],
+  "replicas": [
+    {
+      "name": "app",
+      "count": 3
+    }
+  ],
+  "x-enabled": true
+}
+`,
+		},
+		"empty": {
+			input:    "\n",
+			expected: "\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Format([]byte(tc.input), tc.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(actual))
+
+			// formatting is idempotent
+			again, err := Format(actual, tc.opts)
+			require.NoError(t, err)
+			assert.Equal(t, string(actual), string(again))
+		})
+	}
+}
+
+func TestFormat_notMapping(t *testing.T) {
+	_, err := Format([]byte("- resources\n"), Options{})
+	require.EqualError(t, err, "a kustomization must be a mapping")
+}
+
+func TestCmdFormat(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	const formatted = "resources:\n- deployment.yaml\n"
+	for path, content := range map[string]string{
+		"base/kustomization.yaml":            formatted,
+		"overlays/prod/kustomization.yaml":   unformatted,
+		"overlays/dev/Kustomization":         "resources: [../../base]\n",
+		"overlays/dev/deployment.yaml":       "kind: [Deployment]\n",
+		"overlays/.cache/kustomization.yaml": "resources: [a]\n",
+	} {
+		require.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+
+	var out bytes.Buffer
+	cmd := NewCmdFormat(fSys, &out)
+	cmd.SetArgs([]string{"--check", "base", "overlays"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	require.EqualError(t, cmd.Execute(), "2 kustomization files aren't formatted")
+	assert.Equal(t, "overlays/dev/Kustomization\noverlays/prod/kustomization.yaml\n", out.String())
+
+	out.Reset()
+	cmd = NewCmdFormat(fSys, &out)
+	cmd.SetArgs([]string{"overlays"})
+	require.NoError(t, cmd.Execute())
+	assert.Empty(t, out.String())
+	content, err := fSys.ReadFile("overlays/dev/Kustomization")
+	require.NoError(t, err)
+	assert.Equal(t, "resources:\n- ../../base\n", string(content))
+	content, err = fSys.ReadFile("overlays/dev/deployment.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "kind: [Deployment]\n", string(content))
+	content, err = fSys.ReadFile("overlays/.cache/kustomization.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "resources: [a]\n", string(content))
+
+	cmd = NewCmdFormat(fSys, &out)
+	cmd.SetArgs([]string{"--check", "base", "overlays"})
+	require.NoError(t, cmd.Execute())
+}
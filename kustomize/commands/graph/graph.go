@@ -0,0 +1,332 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	outputDOT  = "dot"
+	outputJSON = "json"
+)
+
+// The kinds of nodes.
+const (
+	NodeKustomization = "kustomization"
+	NodeComponent     = "component"
+	NodeRemote        = "remote"
+	NodeChart         = "chart"
+	NodePatch         = "patch"
+	NodeMissing       = "missing"
+)
+
+// The kinds of edges, named after the kustomization fields they come from.
+const (
+	EdgeResource    = "resources"
+	EdgeComponent   = "components"
+	EdgePatch       = "patches"
+	EdgeChart       = "helmCharts"
+	EdgeGenerator   = "generators"
+	EdgeTransformer = "transformers"
+	EdgeValidator   = "validators"
+)
+
+// Node is a kustomization, or a remote, chart or patch file it refers to.
+type Node struct {
+	// ID is the path of the node relative to the working directory,
+	// or the url of a remote.
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+}
+
+// Edge is a reference of a kustomization to another node.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Graph is the graph of kustomizations and what they refer to.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+type flags struct {
+	output string
+}
+
+// NewCmdGraph returns a new graph command.
+func NewCmdGraph(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f flags
+	cmd := &cobra.Command{
+		Use:   "graph [DIR]...",
+		Short: "[Alpha] Shows the graph of kustomizations and what they refer to",
+		Long: `[Alpha] Shows the graph of the kustomizations in the given directories,
+the bases and components they refer to, transitively, and the remote
+resources, helm charts and patch files they use.
+
+The kustomization files are read without building them.  Remote bases
+are shown, but not fetched.  The graph is written in the DOT language
+of graphviz, or as JSON with --output json.
+`,
+		Example: `
+# Render the graph of an overlay with graphviz
+kustomize graph overlays/production | dot -Tsvg > graph.svg
+
+# List the kustomizations using a base
+kustomize graph overlays/* -o json | jq -r '.edges[] | select(.to == "base") | .from'
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{filesys.SelfDir}
+			}
+			if f.output != outputDOT && f.output != outputJSON {
+				return errors.Errorf("unsupported output %q, must be %q or %q",
+					f.output, outputDOT, outputJSON)
+			}
+			g, err := MakeGraph(fSys, args...)
+			if err != nil {
+				return err
+			}
+			if f.output == outputJSON {
+				return g.WriteJSON(w)
+			}
+			return g.WriteDOT(w)
+		},
+	}
+	cmd.Flags().StringVarP(&f.output,
+		"output",
+		"o",
+		outputDOT,
+		`Format of the graph, "dot" or "json".`)
+	return cmd
+}
+
+// MakeGraph returns the graph of the kustomizations in dirs, and of the
+// kustomizations they refer to.  The nodes and edges are sorted.
+func MakeGraph(fSys filesys.FileSystem, dirs ...string) (*Graph, error) {
+	b := &builder{fSys: fSys, nodes: map[string]string{}, edges: map[Edge]bool{}}
+	for _, dir := range dirs {
+		if err := b.addKustomization(filepath.Clean(dir), false); err != nil {
+			return nil, err
+		}
+	}
+	return b.graph(), nil
+}
+
+type builder struct {
+	fSys  filesys.FileSystem
+	nodes map[string]string
+	edges map[Edge]bool
+}
+
+func (b *builder) graph() *Graph {
+	g := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	for id, kind := range b.nodes {
+		g.Nodes = append(g.Nodes, Node{ID: id, Kind: kind})
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	for e := range b.edges {
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, c := g.Edges[i], g.Edges[j]
+		if a.From != c.From {
+			return a.From < c.From
+		}
+		if a.To != c.To {
+			return a.To < c.To
+		}
+		return a.Kind < c.Kind
+	})
+	return g
+}
+
+func (b *builder) addEdge(from, to, kind string) {
+	b.edges[Edge{From: from, To: to, Kind: kind}] = true
+}
+
+// addKustomization adds the kustomization in dir, and recursively
+// the kustomizations it refers to.
+func (b *builder) addKustomization(dir string, component bool) error {
+	if _, found := b.nodes[dir]; found {
+		return nil
+	}
+	k, err := readKustomization(b.fSys, dir)
+	if err != nil {
+		return err
+	}
+	if component || k.Kind == types.ComponentKind {
+		b.nodes[dir] = NodeComponent
+	} else {
+		b.nodes[dir] = NodeKustomization
+	}
+	for _, r := range k.Resources {
+		if err := b.addReference(dir, r, EdgeResource, false); err != nil {
+			return err
+		}
+	}
+	for _, c := range k.Components {
+		if err := b.addReference(dir, c, EdgeComponent, true); err != nil {
+			return err
+		}
+	}
+	for field, paths := range map[string][]string{
+		EdgeGenerator:   k.Generators,
+		EdgeTransformer: k.Transformers,
+		EdgeValidator:   k.Validators,
+	} {
+		for _, p := range paths {
+			// inline plugin configs and config files aren't nodes
+			if b.fSys.IsDir(filepath.Join(dir, p)) || isRemote(p) {
+				if err := b.addReference(dir, p, field, false); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, p := range append(k.Patches, k.PatchesJson6902...) {
+		b.addPatch(dir, p.Path)
+	}
+	for _, p := range k.PatchesStrategicMerge {
+		// inline patches span several lines
+		if !strings.Contains(string(p), "\n") {
+			b.addPatch(dir, string(p))
+		}
+	}
+	b.addCharts(dir, k)
+	return nil
+}
+
+// addReference adds the node of the resource or component path of the
+// kustomization in dir.  Resource files aren't nodes.
+func (b *builder) addReference(dir, path, kind string, component bool) error {
+	id := filepath.Join(dir, path)
+	switch {
+	case b.fSys.IsDir(id):
+		if err := b.addKustomization(id, component); err != nil {
+			return err
+		}
+	case b.fSys.Exists(id):
+		return nil
+	case isRemote(path):
+		id = path
+		b.nodes[id] = NodeRemote
+	default:
+		b.nodes[id] = NodeMissing
+	}
+	b.addEdge(dir, id, kind)
+	return nil
+}
+
+// addPatch adds the patch file at path of the kustomization in dir.
+func (b *builder) addPatch(dir, path string) {
+	if path == "" {
+		return
+	}
+	id := filepath.Join(dir, path)
+	b.nodes[id] = NodePatch
+	b.addEdge(dir, id, EdgePatch)
+}
+
+// addCharts adds the helm charts of the kustomization in dir.  Charts found
+// in the chart home are identified by their directory, the others by their
+// repository, name and version.
+func (b *builder) addCharts(dir string, k *types.Kustomization) {
+	chartHome := "charts"
+	if k.HelmGlobals != nil && k.HelmGlobals.ChartHome != "" {
+		chartHome = k.HelmGlobals.ChartHome
+	}
+	for _, c := range k.HelmCharts {
+		id := filepath.Join(dir, chartHome, c.Name)
+		if !b.fSys.IsDir(id) && c.Repo != "" {
+			id = strings.TrimSuffix(c.Repo, "/") + "/" + c.Name
+			if c.Version != "" {
+				id += "@" + c.Version
+			}
+		}
+		b.nodes[id] = NodeChart
+		b.addEdge(dir, id, EdgeChart)
+	}
+}
+
+// isRemote returns whether path looks like the url of a remote file
+// or repository.
+func isRemote(path string) bool {
+	if strings.Contains(path, "://") || strings.HasPrefix(path, "git@") {
+		return true
+	}
+	parts := strings.SplitN(path, "/", 2)
+	return len(parts) == 2 && strings.Contains(parts[0], ".") && parts[0] != "." && parts[0] != ".."
+}
+
+// readKustomization reads the kustomization file in dir.
+func readKustomization(fSys filesys.FileSystem, dir string) (*types.Kustomization, error) {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		path := filepath.Join(dir, name)
+		if !fSys.Exists(path) {
+			continue
+		}
+		content, err := fSys.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		var k types.Kustomization
+		if err := k.Unmarshal(content); err != nil {
+			return nil, errors.WrapPrefixf(err, "reading %s", path)
+		}
+		k.FixKustomization()
+		return &k, nil
+	}
+	return nil, errors.Errorf("no kustomization file found in %s", dir)
+}
+
+// WriteJSON writes the graph as JSON.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	b, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	_, err = w.Write(append(b, '\n'))
+	return errors.Wrap(err)
+}
+
+// nodeAttributes are the DOT attributes of the kinds of nodes.
+var nodeAttributes = map[string]string{
+	NodeKustomization: "shape=box",
+	NodeComponent:     "shape=box, style=dashed",
+	NodeRemote:        "shape=box, style=rounded",
+	NodeChart:         "shape=component",
+	NodePatch:         "shape=note",
+	NodeMissing:       "shape=box, color=red",
+}
+
+// WriteDOT writes the graph in the DOT language.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph kustomize {\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&sb, "  %q [%s];\n", n.ID, nodeAttributes[n.Kind])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "  %q -> %q [label=%q];\n", e.From, e.To, e.Kind)
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return errors.Wrap(err)
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package graph_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "sigs.k8s.io/kustomize/kustomize/v5/commands/graph"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func writeRepo(t *testing.T, fSys filesys.FileSystem) {
+	t.Helper()
+	for path, content := range map[string]string{
+		"base/kustomization.yaml": `
+resources:
+- deployment.yaml
+- github.com/example/config//monitoring?ref=v1.0.0
+helmCharts:
+- name: redis
+  repo: https://charts.example.com
+  version: 1.2.3
+- name: local
+`,
+		"base/deployment.yaml":         "",
+		"base/charts/local/Chart.yaml": "",
+		"components/tls/kustomization.yaml": `
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+patches:
+- path: ingress.yaml
+`,
+		"overlays/prod/kustomization.yaml": `
+resources:
+- ../../base
+- ../missing
+components:
+- ../../components/tls
+patches:
+- path: replicas.yaml
+- patch: |-
+    - op: remove
+      path: /spec
+  target:
+    kind: Deployment
+patchesStrategicMerge:
+- memory.yaml
+`,
+		"overlays/staging/kustomization.yaml": `
+resources:
+- ../../base
+`,
+	} {
+		require.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+}
+
+func TestMakeGraph(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeRepo(t, fSys)
+	g, err := MakeGraph(fSys, "overlays/prod", "overlays/staging")
+	require.NoError(t, err)
+	assert.Equal(t, []Node{
+		{ID: "base", Kind: NodeKustomization},
+		{ID: "base/charts/local", Kind: NodeChart},
+		{ID: "components/tls", Kind: NodeComponent},
+		{ID: "components/tls/ingress.yaml", Kind: NodePatch},
+		{ID: "github.com/example/config//monitoring?ref=v1.0.0", Kind: NodeRemote},
+		{ID: "https://charts.example.com/redis@1.2.3", Kind: NodeChart},
+		{ID: "overlays/missing", Kind: NodeMissing},
+		{ID: "overlays/prod", Kind: NodeKustomization},
+		{ID: "overlays/prod/memory.yaml", Kind: NodePatch},
+		{ID: "overlays/prod/replicas.yaml", Kind: NodePatch},
+		{ID: "overlays/staging", Kind: NodeKustomization},
+	}, g.Nodes)
+	assert.Equal(t, []Edge{
+		{From: "base", To: "base/charts/local", Kind: EdgeChart},
+		{From: "base", To: "github.com/example/config//monitoring?ref=v1.0.0", Kind: EdgeResource},
+		{From: "base", To: "https://charts.example.com/redis@1.2.3", Kind: EdgeChart},
+		{From: "components/tls", To: "components/tls/ingress.yaml", Kind: EdgePatch},
+		{From: "overlays/prod", To: "base", Kind: EdgeResource},
+		{From: "overlays/prod", To: "components/tls", Kind: EdgeComponent},
+		{From: "overlays/prod", To: "overlays/missing", Kind: EdgeResource},
+		{From: "overlays/prod", To: "overlays/prod/memory.yaml", Kind: EdgePatch},
+		{From: "overlays/prod", To: "overlays/prod/replicas.yaml", Kind: EdgePatch},
+		{From: "overlays/staging", To: "base", Kind: EdgeResource},
+	}, g.Edges)
+}
+
+func TestMakeGraph_noKustomization(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.MkdirAll("empty"))
+	_, err := MakeGraph(fSys, "empty")
+	require.EqualError(t, err, "no kustomization file found in empty")
+}
+
+func TestGraphCommand(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	writeRepo(t, fSys)
+
+	var out bytes.Buffer
+	cmd := NewCmdGraph(fSys, &out)
+	cmd.SetArgs([]string{"overlays/staging"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, `digraph kustomize {
+  "base" [shape=box];
+  "base/charts/local" [shape=component];
+  "github.com/example/config//monitoring?ref=v1.0.0" [shape=box, style=rounded];
+  "https://charts.example.com/redis@1.2.3" [shape=component];
+  "overlays/staging" [shape=box];
+  "base" -> "base/charts/local" [label="helmCharts"];
+  "base" -> "github.com/example/config//monitoring?ref=v1.0.0" [label="resources"];
+  "base" -> "https://charts.example.com/redis@1.2.3" [label="helmCharts"];
+  "overlays/staging" -> "base" [label="resources"];
+}
+`, out.String())
+
+	out.Reset()
+	cmd = NewCmdGraph(fSys, &out)
+	cmd.SetArgs([]string{"base", "-o", "json"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, `{
+  "nodes": [
+    {
+      "id": "base",
+      "kind": "kustomization"
+    },
+    {
+      "id": "base/charts/local",
+      "kind": "chart"
+    },
+    {
+      "id": "github.com/example/config//monitoring?ref=v1.0.0",
+      "kind": "remote"
+    },
+    {
+      "id": "https://charts.example.com/redis@1.2.3",
+      "kind": "chart"
+    }
+  ],
+  "edges": [
+    {
+      "from": "base",
+      "to": "base/charts/local",
+      "kind": "helmCharts"
+    },
+    {
+      "from": "base",
+      "to": "github.com/example/config//monitoring?ref=v1.0.0",
+      "kind": "resources"
+    },
+    {
+      "from": "base",
+      "to": "https://charts.example.com/redis@1.2.3",
+      "kind": "helmCharts"
+    }
+  ]
+}
+`, out.String())
+
+	cmd = NewCmdGraph(fSys, &out)
+	cmd.SetArgs([]string{"base", "-o", "svg"})
+	cmd.SetErr(&bytes.Buffer{})
+	require.EqualError(t, cmd.Execute(), `unsupported output "svg", must be "dot" or "json"`)
+}
@@ -0,0 +1,244 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package images
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// containerFields are the fields holding lists of containers, in any
+// resource, e.g. in pod templates of custom resources.
+var containerFields = map[string]bool{
+	"containers":          true,
+	"initContainers":      true,
+	"ephemeralContainers": true,
+}
+
+// ImageRef is a reference to a container image in a resource.
+type ImageRef struct {
+	Image      string `json:"image"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Container  string `json:"container,omitempty"`
+	// Path is the path of the image field in the resource.
+	Path string `json:"path"`
+}
+
+// resource returns the kind, namespace and name of the resource of r.
+func (r ImageRef) resource() string {
+	if r.Namespace == "" {
+		return r.Kind + "/" + r.Name
+	}
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+type flags struct {
+	output string
+	unique bool
+}
+
+// NewCmdImages returns a new images command.
+func NewCmdImages(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f flags
+	cmd := &cobra.Command{
+		Use:   "images [DIR]",
+		Short: "[Alpha] Lists the container images used by the resources of a kustomization",
+		Long: `[Alpha] Builds a kustomization and lists the container images of its
+resources, with the resource and container using each of them.
+
+Images are found in the containers, initContainers and ephemeralContainers
+fields of any resource, including the pod templates of custom resources.
+`,
+		Example: `
+# List the images of an overlay and the resources using them
+kustomize images overlays/production
+
+# List the distinct images, one per line, e.g. to mirror them
+kustomize images overlays/production --unique
+
+# List the images as JSON
+kustomize images overlays/production -o json
+`,
+		SilenceUsage: true,
+		Args:         cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := filesys.SelfDir
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			if f.output != outputText && f.output != outputJSON {
+				return errors.Errorf("unsupported output %q, must be %q or %q",
+					f.output, outputText, outputJSON)
+			}
+			m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, dir)
+			if err != nil {
+				return err
+			}
+			refs, err := FindImages(m)
+			if err != nil {
+				return err
+			}
+			return write(w, refs, f)
+		},
+	}
+	cmd.Flags().StringVarP(&f.output,
+		"output",
+		"o",
+		outputText,
+		`Format of the list, "text" or "json".`)
+	cmd.Flags().BoolVar(&f.unique,
+		"unique",
+		false,
+		"List each distinct image once, without the resources using it.")
+	return cmd
+}
+
+// FindImages returns the references to images in the resources of m,
+// sorted by image, then by resource and path.
+func FindImages(m resmap.ResMap) ([]ImageRef, error) {
+	var refs []ImageRef
+	for _, res := range m.Resources() {
+		if res.GetKind() == "CustomResourceDefinition" {
+			continue
+		}
+		ref := ImageRef{
+			APIVersion: res.GetApiVersion(),
+			Kind:       res.GetKind(),
+			Namespace:  res.GetNamespace(),
+			Name:       res.GetName(),
+		}
+		if err := VisitImages(&res.RNode, func(path, container string, image *yaml.RNode) {
+			r := ref
+			r.Path, r.Container, r.Image = path, container, image.YNode().Value
+			refs = append(refs, r)
+		}); err != nil {
+			return nil, errors.WrapPrefixf(err, "finding the images of %s", ref.resource())
+		}
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		if a.Image != b.Image {
+			return a.Image < b.Image
+		}
+		if a.resource() != b.resource() {
+			return a.resource() < b.resource()
+		}
+		return a.Path < b.Path
+	})
+	return refs, nil
+}
+
+// VisitImages calls found with the path, the container name and the
+// image field of the containers in node, e.g. to rewrite the images.
+// Containers are found in any resource, e.g. in the pod templates of
+// custom resources.
+func VisitImages(node *yaml.RNode, found func(path, container string, image *yaml.RNode)) error {
+	return walk(node, "", false, found)
+}
+
+// walk calls found for the images of the containers in node, which is
+// at path.  inContainers is true if node is a list of containers.
+func walk(node *yaml.RNode, path string, inContainers bool,
+	found func(path, container string, image *yaml.RNode)) error {
+	switch node.YNode().Kind {
+	case yaml.MappingNode:
+		return node.VisitFields(func(n *yaml.MapNode) error {
+			key := n.Key.YNode().Value
+			return walk(n.Value, joinPath(path, key), containerFields[key], found)
+		})
+	case yaml.SequenceNode:
+		elements, err := node.Elements()
+		if err != nil {
+			return err
+		}
+		for i, e := range elements {
+			elementPath := fmt.Sprintf("%s[%d]", path, i)
+			if inContainers && e.YNode().Kind == yaml.MappingNode {
+				if image := e.Field("image"); image != nil && scalarField(e, "image") != "" {
+					found(elementPath+".image", scalarField(e, "name"), image.Value)
+				}
+			}
+			if err := walk(e, elementPath, false, found); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scalarField returns the value of the scalar field of node, or the empty
+// string if there is none.
+func scalarField(node *yaml.RNode, field string) string {
+	f := node.Field(field)
+	if f == nil || f.Value.YNode().Kind != yaml.ScalarNode {
+		return ""
+	}
+	return f.Value.YNode().Value
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func write(w io.Writer, refs []ImageRef, f flags) error {
+	if f.unique {
+		images := []string{}
+		for _, r := range refs {
+			if len(images) == 0 || images[len(images)-1] != r.Image {
+				images = append(images, r.Image)
+			}
+		}
+		if f.output == outputJSON {
+			return writeJSON(w, images)
+		}
+		if len(images) == 0 {
+			return nil
+		}
+		_, err := io.WriteString(w, strings.Join(images, "\n")+"\n")
+		return errors.Wrap(err)
+	}
+	if f.output == outputJSON {
+		if refs == nil {
+			refs = []ImageRef{}
+		}
+		return writeJSON(w, refs)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "IMAGE\tRESOURCE\tCONTAINER")
+	for _, r := range refs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Image, r.resource(), r.Container)
+	}
+	return errors.Wrap(tw.Flush())
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	_, err = w.Write(append(b, '\n'))
+	return errors.Wrap(err)
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package images_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "sigs.k8s.io/kustomize/kustomize/v5/commands/images"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func writeKustomization(t *testing.T, fSys filesys.FileSystem) {
+	t.Helper()
+	for path, content := range map[string]string{
+		"kustomization.yaml": `
+namespace: apps
+resources:
+- resources.yaml
+images:
+- name: nginx
+  newTag: "1.25"
+`,
+		"resources.yaml": `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: nginx
+        image: nginx
+      - name: sidecar
+        image: envoyproxy/envoy:v1.27.0
+---
+apiVersion: example.com/v1
+kind: Worker
+metadata:
+  name: queue
+spec:
+  podTemplate:
+    spec:
+      containers:
+      - name: worker
+        image: busybox
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  image: not-an-image
+`,
+	} {
+		require.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+}
+
+func runImages(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	writeKustomization(t, fSys)
+	var out bytes.Buffer
+	cmd := NewCmdImages(fSys, &out)
+	cmd.SetArgs(args)
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestImages(t *testing.T) {
+	out, err := runImages(t)
+	require.NoError(t, err)
+	assert.Equal(t, `IMAGE                     RESOURCE             CONTAINER
+busybox                   Deployment/apps/web  init
+busybox                   Worker/apps/queue    worker
+envoyproxy/envoy:v1.27.0  Deployment/apps/web  sidecar
+nginx:1.25                Deployment/apps/web  nginx
+`, out)
+}
+
+func TestImages_unique(t *testing.T) {
+	out, err := runImages(t, "--unique")
+	require.NoError(t, err)
+	assert.Equal(t, "busybox\nenvoyproxy/envoy:v1.27.0\nnginx:1.25\n", out)
+
+	out, err = runImages(t, "--unique", "-o", "json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `["busybox", "envoyproxy/envoy:v1.27.0", "nginx:1.25"]`, out)
+}
+
+func TestImages_json(t *testing.T) {
+	out, err := runImages(t, "-o", "json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+  {"image": "busybox", "apiVersion": "apps/v1", "kind": "Deployment", "namespace": "apps",
+   "name": "web", "container": "init", "path": "spec.template.spec.initContainers[0].image"},
+  {"image": "busybox", "apiVersion": "example.com/v1", "kind": "Worker", "namespace": "apps",
+   "name": "queue", "container": "worker", "path": "spec.podTemplate.spec.containers[0].image"},
+  {"image": "envoyproxy/envoy:v1.27.0", "apiVersion": "apps/v1", "kind": "Deployment", "namespace": "apps",
+   "name": "web", "container": "sidecar", "path": "spec.template.spec.containers[1].image"},
+  {"image": "nginx:1.25", "apiVersion": "apps/v1", "kind": "Deployment", "namespace": "apps",
+   "name": "web", "container": "nginx", "path": "spec.template.spec.containers[0].image"}
+]`, out)
+}
+
+func TestImages_invalidOutput(t *testing.T) {
+	_, err := runImages(t, "-o", "yaml")
+	require.EqualError(t, err, `unsupported output "yaml", must be "text" or "json"`)
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kustfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// KustomizationFiles returns path if it is a file, or the kustomization
+// files in the directory path and its subdirectories, skipping hidden
+// directories.
+func KustomizationFiles(fSys filesys.FileSystem, path string) ([]string, error) {
+	if !fSys.IsDir(path) {
+		if !fSys.Exists(path) {
+			return nil, fmt.Errorf("%s doesn't exist", path)
+		}
+		return []string{path}, nil
+	}
+	names := map[string]bool{}
+	for _, n := range konfig.RecognizedKustomizationFileNames() {
+		names[n] = true
+	}
+	var paths []string
+	// some file systems walk absolute paths, the first one being path
+	root := ""
+	err := fSys.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if root == "" {
+			root = p
+			return nil
+		}
+		if info.IsDir() {
+			if strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if names[info.Name()] {
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, filepath.Join(path, rel))
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// FieldValue returns the value of the scalar field of the mapping n, or ""
+// if it's unset.
+func FieldValue(n *yaml.Node, field string) string {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == field {
+			return n.Content[i+1].Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kustfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestKustomizationFiles(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	for _, path := range []string{
+		"/app/kustomization.yaml",
+		"/app/base/Kustomization",
+		"/app/base/deployment.yaml",
+		"/app/overlays/prod/kustomization.yml",
+		"/app/.git/kustomization.yaml",
+	} {
+		require.NoError(t, fSys.WriteFile(path, []byte("resources: []\n")))
+	}
+	paths, err := KustomizationFiles(fSys, "/app")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"/app/kustomization.yaml",
+		"/app/base/Kustomization",
+		"/app/overlays/prod/kustomization.yml",
+	}, paths)
+
+	paths, err = KustomizationFiles(fSys, "/app/base/deployment.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/app/base/deployment.yaml"}, paths)
+
+	_, err = KustomizationFiles(fSys, "/missing")
+	assert.EqualError(t, err, "/missing doesn't exist")
+}
+
+func TestFieldValue(t *testing.T) {
+	n := yaml.MustParse("name: nginx\nversion: 1.2.0\n").YNode()
+	assert.Equal(t, "nginx", FieldValue(n, "name"))
+	assert.Equal(t, "1.2.0", FieldValue(n, "version"))
+	assert.Equal(t, "", FieldValue(n, "repo"))
+}
@@ -11,6 +11,7 @@ import (
 	"log"
 	"reflect"
 	"regexp"
+	"strings"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -66,7 +67,9 @@ func determineFieldOrder() []string {
 		"Generators",
 		"Transformers",
 		"Components",
+		"AllowedPaths",
 		"OpenAPI",
+		"OpenAPIMerge",
 		"BuildMetadata",
 	}
 
@@ -93,6 +96,19 @@ func determineFieldOrder() []string {
 	return result
 }
 
+// FieldNames returns the names of the Kustomization fields, as written
+// in kustomization files, in the preferred order for serialization.
+func FieldNames() []string {
+	t := reflect.TypeOf(types.Kustomization{})
+	names := make([]string, 0, len(fieldMarshallingOrder))
+	for _, n := range fieldMarshallingOrder {
+		// the fields are known to exist, see determineFieldOrder
+		f, _ := t.FieldByName(n)
+		names = append(names, strings.Split(f.Tag.Get("json"), ",")[0])
+	}
+	return names
+}
+
 // commentedField records the comment associated with a kustomization field
 // field has to be a recognized kustomization field
 // comment can be empty
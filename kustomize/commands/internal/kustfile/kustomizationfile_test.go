@@ -47,7 +47,9 @@ func TestFieldOrder(t *testing.T) {
 		"Generators",
 		"Transformers",
 		"Components",
+		"AllowedPaths",
 		"OpenAPI",
+		"OpenAPIMerge",
 		"BuildMetadata",
 	}
 	actual := determineFieldOrder()
@@ -61,6 +63,14 @@ func TestFieldOrder(t *testing.T) {
 	}
 }
 
+func TestFieldNames(t *testing.T) {
+	names := FieldNames()
+	require.Equal(t, len(fieldMarshallingOrder), len(names))
+	require.Equal(t, []string{"apiVersion", "kind", "metadata", "resources"}, names[:4])
+	require.Equal(t, "patchesJson6902", names[13])
+	require.Equal(t, "openapi", names[len(names)-3])
+}
+
 func TestWriteAndRead(t *testing.T) {
 	kustomization := &types.Kustomization{
 		NamePrefix: "prefix",
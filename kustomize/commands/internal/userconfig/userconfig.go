@@ -0,0 +1,210 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package userconfig reads the user configuration file of kustomize,
+// which holds the defaults of the flags of commands.
+package userconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConfigFileName is the name of the user configuration file
+	// below XDG_CONFIG_HOME/kustomize.
+	ConfigFileName = "config.yaml"
+
+	// ConfigPathEnv is the name of the environment variable overriding the
+	// path of the user configuration file.  If set to the empty string,
+	// no configuration file is read.
+	ConfigPathEnv = "KUSTOMIZE_CONFIG"
+
+	// tempDirEnv is the environment variable of the directory of the
+	// temporary files of kustomize.
+	tempDirEnv = "TMPDIR"
+)
+
+// Config is the user configuration of kustomize, e.g.
+//
+//	pluginHome: ~/kustomize/plugins
+//	tempDir: ~/.cache/kustomize
+//	http:
+//	  proxy: http://proxy.example.com:3128
+//	  noProxy: [internal.example.com, 10.0.0.0/8]
+//	  caFiles: [~/certs/proxy-ca.pem]
+//	flags:
+//	  build:
+//	    enable-helm: true
+//	    helm-command: /opt/helm/bin/helm
+//	    load-restrictor: LoadRestrictionsNone
+//	  edit fix:
+//	    vars: true
+type Config struct {
+	// PluginHome is the directory of the kustomize plugins,
+	// unless set by $KUSTOMIZE_PLUGIN_HOME.
+	PluginHome string `json:"pluginHome,omitempty" yaml:"pluginHome,omitempty"`
+
+	// TempDir is the directory of the clones of remote bases, of the
+	// homes of helm and of other temporary files, unless set by $TMPDIR.
+	TempDir string `json:"tempDir,omitempty" yaml:"tempDir,omitempty"`
+
+	// HTTP is the proxy and the extra certificate authorities of the
+	// remote fetches of the commands which fetch remote content, unless
+	// set by their flags.
+	HTTP types.HTTPConfig `json:"http,omitempty" yaml:"http,omitempty"`
+
+	// Flags are the default values of the flags of commands, by flag name
+	// and command, e.g. "build" or "edit fix".  Flags given on the command
+	// line take precedence.  The values of flags that can be repeated may
+	// be lists.
+	Flags map[string]map[string]interface{} `json:"flags,omitempty" yaml:"flags,omitempty"`
+}
+
+// DefaultPath returns the path of the user configuration file, or the
+// empty string if it is disabled.
+func DefaultPath() string {
+	if path, set := os.LookupEnv(ConfigPathEnv); set {
+		return path
+	}
+	root := os.Getenv(konfig.XdgConfigHomeEnv)
+	if root == "" {
+		root = filepath.Join(konfig.HomeDir(), konfig.XdgConfigHomeEnvDefault)
+	}
+	return filepath.Join(root, konfig.ProgramName, ConfigFileName)
+}
+
+// Load reads the user configuration file at path.  A missing file is an
+// empty configuration.
+func Load(fSys filesys.FileSystem, path string) (*Config, error) {
+	c := &Config{}
+	if path == "" || !fSys.Exists(path) {
+		return c, nil
+	}
+	content, err := fSys.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(content, c); err != nil {
+		return nil, fmt.Errorf("invalid user configuration %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// LoadAndApply reads the user configuration file at path and applies it to
+// cmd.  An invalid file doesn't fail cmd, which may not even use it, e.g.
+// "kustomize version", but is ignored with a warning on the standard error
+// of cmd.  The flags of cmd with invalid defaults in a valid file fail cmd,
+// and only cmd.
+func LoadAndApply(fSys filesys.FileSystem, path string, cmd *cobra.Command) error {
+	c, err := Load(fSys, path)
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: ignoring %v\n", err)
+		return nil
+	}
+	return c.Apply(cmd)
+}
+
+// Apply sets the flags of cmd that weren't given on the command line to
+// their defaults in c, the proxy and CA file flags to the http
+// configuration of c, and the environment variables of the directories
+// of c that aren't set.
+func (c *Config) Apply(cmd *cobra.Command) error {
+	if err := setEnvDefault(konfig.KustomizePluginHomeEnv, c.PluginHome); err != nil {
+		return err
+	}
+	if err := setEnvDefault(tempDirEnv, c.TempDir); err != nil {
+		return err
+	}
+	name := commandName(cmd)
+	flags := c.Flags[name]
+	names := make([]string, 0, len(flags))
+	for n := range flags {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		f := cmd.Flags().Lookup(n)
+		if f == nil {
+			return fmt.Errorf("user configuration: unknown flag --%s of command %q", n, name)
+		}
+		if f.Changed {
+			continue
+		}
+		values, isList := flags[n].([]interface{})
+		if !isList {
+			values = []interface{}{flags[n]}
+		}
+		for _, v := range values {
+			if err := cmd.Flags().Set(n, fmt.Sprint(v)); err != nil {
+				return fmt.Errorf("user configuration: flag --%s of command %q: %w", n, name, err)
+			}
+		}
+	}
+	return c.applyHTTP(cmd)
+}
+
+// applyHTTP sets the proxy and CA file flags of cmd, if it has them, that
+// weren't given on the command line or by the flags of c, to the http
+// configuration of c.
+func (c *Config) applyHTTP(cmd *cobra.Command) error {
+	caFiles := make([]string, 0, len(c.HTTP.CAFiles))
+	for _, f := range c.HTTP.CAFiles {
+		caFiles = append(caFiles, expandHome(f))
+	}
+	values := map[string][]string{
+		"proxy":    {c.HTTP.Proxy},
+		"no-proxy": c.HTTP.NoProxy,
+		"ca-file":  caFiles,
+	}
+	for _, n := range []string{"proxy", "no-proxy", "ca-file"} {
+		f := cmd.Flags().Lookup(n)
+		if f == nil || f.Changed {
+			continue
+		}
+		for _, v := range values[n] {
+			if v == "" {
+				continue
+			}
+			if err := cmd.Flags().Set(n, v); err != nil {
+				return fmt.Errorf("user configuration: http of command %q: %w", commandName(cmd), err)
+			}
+		}
+	}
+	return nil
+}
+
+// commandName returns the path of cmd without the name of the program.
+func commandName(cmd *cobra.Command) string {
+	path := strings.Fields(cmd.CommandPath())
+	if len(path) > 0 {
+		path = path[1:]
+	}
+	return strings.Join(path, " ")
+}
+
+// setEnvDefault sets the environment variable name to dir, with a
+// leading ~ expanded, unless it is already set.
+func setEnvDefault(name, dir string) error {
+	if dir == "" || os.Getenv(name) != "" {
+		return nil
+	}
+	return os.Setenv(name, expandHome(dir))
+}
+
+// expandHome returns path with a leading ~ expanded to the home directory.
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(konfig.HomeDir(), path[1:])
+	}
+	return path
+}
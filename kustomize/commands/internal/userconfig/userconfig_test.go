@@ -0,0 +1,183 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package userconfig_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/konfig"
+	. "sigs.k8s.io/kustomize/kustomize/v5/commands/internal/userconfig"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+type testFlags struct {
+	enableHelm  bool
+	helmCommand string
+	mounts      []string
+}
+
+// makeCommands returns the commands "kustomize build" and "kustomize edit fix",
+// with their flags bound to f.
+func makeCommands(f *testFlags) (build, fix *cobra.Command) {
+	root := &cobra.Command{Use: "kustomize"}
+	build = &cobra.Command{Use: "build"}
+	build.Flags().BoolVar(&f.enableHelm, "enable-helm", false, "")
+	build.Flags().StringVar(&f.helmCommand, "helm-command", "helm", "")
+	build.Flags().StringArrayVar(&f.mounts, "mount", nil, "")
+	edit := &cobra.Command{Use: "edit"}
+	fix = &cobra.Command{Use: "fix"}
+	fix.Flags().Bool("vars", false, "")
+	edit.AddCommand(fix)
+	root.AddCommand(build, edit)
+	return build, fix
+}
+
+func TestApply(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("config.yaml", []byte(`
+flags:
+  build:
+    enable-helm: true
+    helm-command: /opt/helm/bin/helm
+    mount:
+    - type=bind,src=/a,dst=/a
+    - type=bind,src=/b,dst=/b
+  edit fix:
+    vars: true
+`)))
+	c, err := Load(fSys, "config.yaml")
+	require.NoError(t, err)
+
+	var f testFlags
+	build, fix := makeCommands(&f)
+	require.NoError(t, build.Flags().Parse([]string{"--helm-command", "helm3"}))
+	require.NoError(t, c.Apply(build))
+	assert.True(t, f.enableHelm)
+	// flags of the command line take precedence
+	assert.Equal(t, "helm3", f.helmCommand)
+	assert.Equal(t, []string{"type=bind,src=/a,dst=/a", "type=bind,src=/b,dst=/b"}, f.mounts)
+
+	require.NoError(t, c.Apply(fix))
+	assert.Equal(t, "true", fix.Flags().Lookup("vars").Value.String())
+}
+
+func TestApply_errors(t *testing.T) {
+	for expectedErr, config := range map[string]string{
+		`user configuration: unknown flag --enable-helms of command "build"`: `
+flags:
+  build:
+    enable-helms: true
+`,
+		`user configuration: flag --enable-helm of command "build": invalid argument "yes please" for "--enable-helm" flag: strconv.ParseBool: parsing "yes please": invalid syntax`: `
+flags:
+  build:
+    enable-helm: yes please
+`,
+	} {
+		fSys := filesys.MakeFsInMemory()
+		require.NoError(t, fSys.WriteFile("config.yaml", []byte(config)))
+		c, err := Load(fSys, "config.yaml")
+		require.NoError(t, err)
+		build, _ := makeCommands(&testFlags{})
+		assert.EqualError(t, c.Apply(build), expectedErr)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	c, err := Load(fSys, "missing.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, &Config{}, c)
+
+	require.NoError(t, fSys.WriteFile("config.yaml", []byte("pluginDir: /plugins\n")))
+	_, err = Load(fSys, "config.yaml")
+	require.ErrorContains(t, err, `invalid user configuration config.yaml: error unmarshaling JSON: while decoding JSON: json: unknown field "pluginDir"`)
+}
+
+func TestLoadAndApply(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("config.yaml", []byte("flags:\n  build:\n    enable-helm: true\n")))
+	var f testFlags
+	build, _ := makeCommands(&f)
+	require.NoError(t, LoadAndApply(fSys, "config.yaml", build))
+	assert.True(t, f.enableHelm)
+
+	// an invalid file is ignored with a warning
+	require.NoError(t, fSys.WriteFile("config.yaml", []byte("flags: [build\n")))
+	f = testFlags{}
+	build, _ = makeCommands(&f)
+	stderr := new(bytes.Buffer)
+	build.SetErr(stderr)
+	require.NoError(t, LoadAndApply(fSys, "config.yaml", build))
+	assert.False(t, f.enableHelm)
+	assert.Contains(t, stderr.String(), "Warning: ignoring invalid user configuration config.yaml")
+}
+
+func TestApply_directories(t *testing.T) {
+	t.Setenv(konfig.KustomizePluginHomeEnv, "")
+	t.Setenv("TMPDIR", "/tmp/set")
+	t.Setenv("HOME", "/home/user")
+	c := &Config{PluginHome: "~/plugins", TempDir: "/tmp/config"}
+	build, _ := makeCommands(&testFlags{})
+	require.NoError(t, c.Apply(build))
+	assert.Equal(t, filepath.Join("/home/user", "plugins"), os.Getenv(konfig.KustomizePluginHomeEnv))
+	// the environment takes precedence
+	assert.Equal(t, "/tmp/set", os.Getenv("TMPDIR"))
+}
+
+func TestApply_http(t *testing.T) {
+	t.Setenv("HOME", "/home/user")
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("config.yaml", []byte(`
+http:
+  proxy: http://proxy.example.com:3128
+  noProxy: [internal.example.com, 10.0.0.0/8]
+  caFiles: [~/certs/proxy-ca.pem]
+flags:
+  build:
+    no-proxy: [example.com]
+`)))
+	c, err := Load(fSys, "config.yaml")
+	require.NoError(t, err)
+
+	var proxy string
+	var noProxy, caFiles []string
+	build, fix := makeCommands(&testFlags{})
+	build.Flags().StringVar(&proxy, "proxy", "", "")
+	build.Flags().StringSliceVar(&noProxy, "no-proxy", nil, "")
+	build.Flags().StringArrayVar(&caFiles, "ca-file", nil, "")
+	require.NoError(t, build.Flags().Parse([]string{"--ca-file", "/certs/ca.pem"}))
+	require.NoError(t, c.Apply(build))
+	assert.Equal(t, "http://proxy.example.com:3128", proxy)
+	// the flags of the command take precedence
+	assert.Equal(t, []string{"example.com"}, noProxy)
+	assert.Equal(t, []string{"/certs/ca.pem"}, caFiles)
+
+	// the commands without the flags are left alone
+	require.NoError(t, c.Apply(fix))
+
+	proxy, caFiles = "", nil
+	build, _ = makeCommands(&testFlags{})
+	build.Flags().StringVar(&proxy, "proxy", "", "")
+	build.Flags().StringSliceVar(&noProxy, "no-proxy", nil, "")
+	build.Flags().StringArrayVar(&caFiles, "ca-file", nil, "")
+	require.NoError(t, c.Apply(build))
+	assert.Equal(t, []string{filepath.Join("/home/user", "certs", "proxy-ca.pem")}, caFiles)
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv(konfig.XdgConfigHomeEnv, "/config")
+	t.Setenv(ConfigPathEnv, "")
+	require.NoError(t, os.Unsetenv(ConfigPathEnv))
+	assert.Equal(t, filepath.Join("/config", "kustomize", "config.yaml"), DefaultPath())
+
+	t.Setenv(ConfigPathEnv, "/etc/kustomize.yaml")
+	assert.Equal(t, "/etc/kustomize.yaml", DefaultPath())
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// CompletionFunc completes the arguments or the value of a flag of a command.
+type CompletionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// CompleteValues returns a CompletionFunc completing one of values.
+func CompleteValues(values ...string) CompletionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return withPrefix(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// CompleteKustomizationDirs returns a CompletionFunc completing the directories
+// containing a kustomization file.  Directories which don't, but whose
+// subdirectories do, are completed with a trailing separator, so that the
+// completion can continue into them.
+func CompleteKustomizationDirs(fSys filesys.FileSystem) CompletionFunc {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return kustomizationDirs(fSys, toComplete)
+	}
+}
+
+func kustomizationDirs(fSys filesys.FileSystem, toComplete string) ([]string, cobra.ShellCompDirective) {
+	dir, _ := filepath.Split(toComplete)
+	parent := dir
+	if parent == "" {
+		parent = filesys.SelfDir
+	}
+	entries, err := fSys.ReadDir(parent)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	sort.Strings(entries)
+	directive := cobra.ShellCompDirectiveNoFileComp
+	var result []string
+	if toComplete == "" && HasKustomizationFile(fSys, parent) {
+		result = append(result, filesys.SelfDir)
+	}
+	for _, entry := range entries {
+		path := dir + entry
+		if strings.HasPrefix(entry, ".") || !strings.HasPrefix(path, toComplete) ||
+			!fSys.IsDir(filepath.Join(parent, entry)) {
+			continue
+		}
+		switch {
+		case HasKustomizationFile(fSys, filepath.Join(parent, entry)):
+			result = append(result, path)
+		case hasKustomizationDir(fSys, filepath.Join(parent, entry)):
+			result = append(result, path+string(filepath.Separator))
+			directive |= cobra.ShellCompDirectiveNoSpace
+		}
+	}
+	return result, directive
+}
+
+// HasKustomizationFile returns true if dir contains a kustomization file.
+func HasKustomizationFile(fSys filesys.FileSystem, dir string) bool {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		if fSys.Exists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKustomizationDir returns true if a subdirectory of dir contains a
+// kustomization file.
+func hasKustomizationDir(fSys filesys.FileSystem, dir string) bool {
+	found := false
+	_ = fSys.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || found {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		found = path != dir && HasKustomizationFile(fSys, path)
+		return nil
+	})
+	return found
+}
+
+// withPrefix returns the values starting with prefix.
+func withPrefix(values []string, prefix string) []string {
+	var result []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package util
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestCompleteValues(t *testing.T) {
+	values, directive := CompleteValues("json", "jsonlines", "yaml")(nil, nil, "js")
+	assert.Equal(t, []string{"json", "jsonlines"}, values)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
+
+func TestCompleteKustomizationDirs(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	for _, path := range []string{
+		"base/kustomization.yaml",
+		"overlays/dev/kustomization.yml",
+		"overlays/prod/Kustomization",
+		"overlays/prod/patch.yaml",
+		"docs/README.md",
+		".git/kustomization.yaml",
+	} {
+		require.NoError(t, fSys.WriteFile(path, []byte{}))
+	}
+	complete := CompleteKustomizationDirs(fSys)
+
+	testCases := map[string]struct {
+		toComplete string
+		expected   []string
+		directive  cobra.ShellCompDirective
+	}{
+		"top level": {
+			toComplete: "",
+			expected:   []string{"base", "overlays/"},
+			directive:  cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace,
+		},
+		"prefix": {
+			toComplete: "b",
+			expected:   []string{"base"},
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+		"subdirectory": {
+			toComplete: "overlays/",
+			expected:   []string{"overlays/dev", "overlays/prod"},
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+		"subdirectory prefix": {
+			toComplete: "overlays/p",
+			expected:   []string{"overlays/prod"},
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+		"no match": {
+			toComplete: "docs/",
+			expected:   nil,
+			directive:  cobra.ShellCompDirectiveNoFileComp,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, directive := complete(nil, nil, tc.toComplete)
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.directive, directive)
+		})
+	}
+
+	require.NoError(t, fSys.WriteFile("kustomization.yaml", []byte{}))
+	actual, _ := complete(nil, nil, "")
+	assert.Equal(t, []string{".", "base", "overlays/"}, actual)
+}
@@ -20,7 +20,10 @@ type arguments struct {
 }
 
 type flags struct {
-	scope string
+	scope       string
+	enableHelm  bool
+	helmCommand string
+	verify      bool
 }
 
 // NewCmdLocalize returns a new localize command.
@@ -40,8 +43,18 @@ working directory.
 
 For details, see: https://kubectl.docs.kubernetes.io/references/kustomize/cmd/
 
+Destination contains the lockfile localize-lock.yaml, recording the url,
+resolved commit and content digest of each remote source. With --verify, the
+only argument is a localized directory, default the current working
+directory, which is verified as by kustomize verify: its content must match
+its lockfile, and it must have no unrecorded or remote content.
+
+With --enable-helm, the remote charts of helmCharts entries are pulled into
+the chart home of the localized copy, and their repo is removed, so that the
+copy builds offline.
+
 Disclaimer:
-This command does not yet localize helm or KRM plugin fields. This command also
+This command does not yet localize KRM plugin fields. This command also
 alphabetizes kustomization fields in the localized copy.
 `,
 		Example: `
@@ -51,14 +64,29 @@ kustomize localize
 # Localize some local directory, with scope and default destination
 kustomize localize /home/path/scope/target --scope /home/path/scope
 
+# Localize the current working directory, pulling remote helm charts
+kustomize localize --enable-helm
+
 # Localize remote at set destination relative to working directory
 kustomize localize https://github.com/kubernetes-sigs/kustomize//api/krusty/testdata/localize/simple?ref=v4.5.7 path/non-existing-dir
+
+# Localize remote at default destination localized-simple-v4.5.7, then verify
+# the content of the localized copy against its lockfile
+kustomize localize https://github.com/kubernetes-sigs/kustomize//api/krusty/testdata/localize/simple?ref=v4.5.7
+kustomize localize --verify localized-simple-v4.5.7
 `,
 		SilenceUsage: true,
 		Args:         cobra.MaximumNArgs(numArgs),
 		RunE: func(cmd *cobra.Command, rawArgs []string) error {
+			if f.verify {
+				return runVerify(fs, rawArgs)
+			}
 			args := matchArgs(rawArgs)
-			dst, err := lclzr.Run(fs, args.target, f.scope, args.dest)
+			var opts lclzr.Options
+			if f.enableHelm {
+				opts.HelmCommand = f.helmCommand
+			}
+			dst, err := lclzr.RunWithOptions(fs, args.target, f.scope, args.dest, opts)
 			if err != nil {
 				return errors.Wrap(err)
 			}
@@ -74,9 +102,37 @@ kustomize localize https://github.com/kubernetes-sigs/kustomize//api/krusty/test
 Cannot specify for remote targets, as scope is by default the containing repo.
 If not specified for local target, scope defaults to target.
 `)
+	cmd.Flags().BoolVar(&f.enableHelm,
+		"enable-helm",
+		false,
+		"Pull the remote charts of helmCharts into the chart home of the localized copy.")
+	cmd.Flags().StringVar(&f.helmCommand,
+		"helm-command",
+		"helm", // default
+		"helm command (path to executable)")
+	cmd.Flags().BoolVar(&f.verify,
+		"verify",
+		false,
+		"Verify the content of a localized directory against its lockfile, instead of localizing.")
 	return cmd
 }
 
+// runVerify verifies the localized directory in rawArgs, if any, against its lockfile.
+func runVerify(fs filesys.FileSystem, rawArgs []string) error {
+	if len(rawArgs) > 1 {
+		return errors.Errorf("--verify accepts at most 1 argument, the localized directory")
+	}
+	dir := filesys.SelfDir
+	if len(rawArgs) == 1 {
+		dir = rawArgs[0]
+	}
+	if err := lclzr.Verify(fs, dir); err != nil {
+		return errors.Wrap(err)
+	}
+	log.Printf("SUCCESS: %s matches %s\n", dir, lclzr.LockFileName)
+	return nil
+}
+
 // matchArgs matches user-entered userArgs, which cannot exceed max length, with
 // arguments.
 func matchArgs(rawArgs []string) arguments {
@@ -12,8 +12,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	lclzr "sigs.k8s.io/kustomize/api/krusty/localizer"
 	loctest "sigs.k8s.io/kustomize/api/testutils/localizertest"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/localize"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 const deployment = `apiVersion: apps/v1
@@ -39,6 +41,9 @@ spec:
         - containerPort: 80
 `
 
+// emptyLock is the lockfile of a localized directory without remote content.
+const emptyLock = "sources: []\n"
+
 func TestScopeFlag(t *testing.T) {
 	kustomizations := map[string]string{
 		filepath.Join("target", "kustomization.yaml"): fmt.Sprintf(`resources:
@@ -63,6 +68,7 @@ func TestScopeFlag(t *testing.T) {
 	require.NoError(t, err)
 
 	loctest.SetupDir(t, expected, testDir.Join("dst"), kustomizations)
+	loctest.SetupDir(t, expected, testDir.Join("dst"), map[string]string{lclzr.LockFileName: emptyLock})
 	loctest.CheckFs(t, testDir.String(), expected, actual)
 }
 
@@ -97,6 +103,7 @@ func TestOptionalArgs(t *testing.T) {
 			loctest.SetupDir(t, expected, target, kust)
 			dst := filepath.Join(target, "localized-target")
 			loctest.SetupDir(t, expected, dst, kust)
+			loctest.SetupDir(t, expected, dst, map[string]string{lclzr.LockFileName: emptyLock})
 			loctest.CheckFs(t, testDir.String(), expected, actual)
 
 			successMsg := fmt.Sprintf(`SUCCESS: localized "." to directory %s
@@ -126,6 +133,7 @@ func TestOutput(t *testing.T) {
 	require.NoError(t, err)
 
 	loctest.SetupDir(t, expected, target.Join("dst"), kustomization)
+	loctest.SetupDir(t, expected, target.Join("dst"), map[string]string{lclzr.LockFileName: emptyLock})
 	loctest.CheckFs(t, target.String(), expected, actual)
 
 	successMsg := fmt.Sprintf(`SUCCESS: localized "%s" to directory %s
@@ -156,3 +164,35 @@ func TestTooManyArgs(t *testing.T) {
 	})
 	require.EqualError(t, err, "accepts at most 2 arg(s), received 3")
 }
+
+func TestVerify(t *testing.T) {
+	const remoteFile = "localized-files/example.com/remote.yaml"
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile(filepath.Join("/dst", remoteFile), []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: remote
+`)))
+	require.NoError(t, fSys.WriteFile(filepath.Join("/dst", lclzr.LockFileName), []byte(`sources:
+- digest: sha256:e2e0c5571ba6eba77d70a3ef37e8c5ba1bc628c8f89763a15ff0f0cb08fc5a90
+  path: localized-files/example.com/remote.yaml
+  url: https://example.com/remote.yaml
+`)))
+
+	cmd := localize.NewCmdLocalize(fSys)
+	require.NoError(t, cmd.Flags().Set("verify", "true"))
+	require.NoError(t, cmd.RunE(cmd, []string{"/dst"}))
+
+	require.NoError(t, fSys.WriteFile(filepath.Join("/dst", remoteFile), []byte("tampered")))
+	err := cmd.RunE(cmd, []string{"/dst"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `localized directory /dst does not verify:
+  localized-files/example.com/remote.yaml (https://example.com/remote.yaml): expected digest sha256:e2e0c5571ba6eba77d70a3ef37e8c5ba1bc628c8f89763a15ff0f0cb08fc5a90, got sha256:`)
+
+	require.NoError(t, fSys.RemoveAll(filepath.Join("/dst", lclzr.LockFileName)))
+	require.EqualError(t, cmd.RunE(cmd, []string{"/dst"}),
+		"localized directory /dst has no localize-lock.yaml: it must be created by kustomize localize")
+
+	require.EqualError(t, cmd.RunE(cmd, []string{"/dst", "/other"}),
+		"--verify accepts at most 1 argument, the localized directory")
+}
@@ -0,0 +1,396 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/format"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/images"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type helmFlags struct {
+	values      []string
+	repo        string
+	version     string
+	releaseName string
+	namespace   string
+	includeCRDs bool
+	helmCommand string
+	output      string
+	overlay     string
+}
+
+// NewCmdMigrateHelm returns a new command scaffolding kustomizations
+// from a helm chart.
+func NewCmdMigrateHelm(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f helmFlags
+	cmd := &cobra.Command{
+		Use:   "helm CHART",
+		Short: "[Alpha] Scaffolds a base and an overlay from a helm chart",
+		Long: `[Alpha] Inflates a helm chart once with the given values, and writes
+its resources as a kustomize base, with an overlay using the base.
+
+CHART is the directory of a local chart, or the name of a chart of the
+repository given by --repo.  In the base:
+
+  - ConfigMaps are replaced by configMapGenerator entries, whose data
+    are files in a directory named after the ConfigMap.
+  - The tags and digests of the images are moved to images entries.
+  - The namespaces of the resources are removed.  The overlay sets the
+    namespace of the resources instead.
+
+The result is a starting point: review it, as helm hooks, tests and
+values computed by templates become plain resources.  The helm command
+must be installed.
+`,
+		Example: `
+# Scaffold a base and a production overlay in the current directory
+kustomize migrate helm ./charts/app --values values-prod.yaml --overlay production
+
+# Scaffold from a chart of a repository
+kustomize migrate helm redis --repo https://charts.bitnami.com/bitnami --version 17.0.0 -o redis
+`,
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := inflateChart(args[0], f)
+			if err != nil {
+				return err
+			}
+			err = Scaffold(fSys, f.output, m, ScaffoldOptions{
+				Overlay:   f.overlay,
+				Namespace: f.namespace,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "wrote %s and %s\n", filepath.Join(f.output, baseDir),
+				filepath.Join(f.output, overlaysDir, f.overlay))
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVarP(&f.values, "values", "f", nil,
+		"Values file of the chart.  Later files take precedence.")
+	cmd.Flags().StringVar(&f.repo, "repo", "",
+		"Repository of the chart, if it isn't a local directory.")
+	cmd.Flags().StringVar(&f.version, "version", "",
+		"Version of the chart in the repository.")
+	cmd.Flags().StringVar(&f.releaseName, "release-name", "",
+		"Name of the release the chart is inflated as.")
+	cmd.Flags().StringVarP(&f.namespace, "namespace", "n", "",
+		"Namespace the chart is inflated in, and set by the overlay.")
+	cmd.Flags().BoolVar(&f.includeCRDs, "include-crds", false,
+		"Include the CustomResourceDefinitions of the chart.")
+	cmd.Flags().StringVar(&f.helmCommand, "helm-command", "helm",
+		"Helm command (path to executable).")
+	cmd.Flags().StringVarP(&f.output, "output", "o", filesys.SelfDir,
+		"Directory the base and overlays directories are written to.")
+	cmd.Flags().StringVar(&f.overlay, "overlay", "default",
+		"Name of the overlay.")
+	return cmd
+}
+
+// inflateChart inflates the chart with a kustomization using the helm
+// chart inflation generator.
+func inflateChart(chart string, f helmFlags) (resmap.ResMap, error) {
+	dir, err := os.MkdirTemp("", "kustomize-migrate-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	c := types.HelmChart{
+		Name:        chart,
+		Repo:        f.repo,
+		Version:     f.version,
+		ReleaseName: f.releaseName,
+		Namespace:   f.namespace,
+		IncludeCRDs: f.includeCRDs,
+	}
+	chartHome := filepath.Join(dir, types.HelmDefaultHome)
+	if f.repo == "" {
+		abs, err := filepath.Abs(chart)
+		if err != nil {
+			return nil, err
+		}
+		chartHome, c.Name = filepath.Dir(abs), filepath.Base(abs)
+	}
+	for i, v := range f.values {
+		abs, err := filepath.Abs(v)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			c.ValuesFile = abs
+		} else {
+			c.AdditionalValuesFiles = append(c.AdditionalValuesFiles, abs)
+		}
+	}
+	k := types.Kustomization{
+		HelmGlobals: &types.HelmGlobals{ChartHome: chartHome},
+		HelmCharts:  []types.HelmChart{c},
+	}
+	content, err := yaml.Marshal(k)
+	if err != nil {
+		return nil, err
+	}
+	fSys := filesys.MakeFsOnDisk()
+	if err := fSys.WriteFile(
+		filepath.Join(dir, konfig.DefaultKustomizationFileName()), content); err != nil {
+		return nil, err
+	}
+	opts := krusty.MakeDefaultOptions()
+	opts.LoadRestrictions = types.LoadRestrictionsNone
+	opts.PluginConfig.HelmConfig.Enabled = true
+	opts.PluginConfig.HelmConfig.Command = f.helmCommand
+	m, err := krusty.MakeKustomizer(opts).Run(fSys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("inflating chart %s: %w", chart, err)
+	}
+	return m, nil
+}
+
+const (
+	baseDir     = "base"
+	overlaysDir = "overlays"
+)
+
+// ScaffoldOptions are the options of Scaffold.
+type ScaffoldOptions struct {
+	// Overlay is the name of the overlay.
+	Overlay string
+
+	// Namespace is the namespace set by the overlay.  If empty, it is
+	// the namespace shared by the resources, if any.
+	Namespace string
+}
+
+// Scaffold writes the resources of m as a base in dir/base, and an
+// overlay of the base in dir/overlays/<overlay>.
+func Scaffold(fSys filesys.FileSystem, dir string, m resmap.ResMap, opts ScaffoldOptions) error {
+	base := filepath.Join(dir, baseDir)
+	overlay := filepath.Join(dir, overlaysDir, opts.Overlay)
+	for _, d := range []string{base, overlay} {
+		if fSys.Exists(d) {
+			return fmt.Errorf("%s already exists", d)
+		}
+	}
+	if err := fSys.MkdirAll(base); err != nil {
+		return err
+	}
+
+	namespace, err := stripNamespaces(m)
+	if err != nil {
+		return err
+	}
+	if opts.Namespace != "" {
+		namespace = opts.Namespace
+	}
+	k := &types.Kustomization{TypeMeta: types.TypeMeta{
+		APIVersion: types.KustomizationVersion,
+		Kind:       types.KustomizationKind,
+	}}
+	if k.Images, err = extractImages(m); err != nil {
+		return err
+	}
+	fileNames := map[string]bool{}
+	for _, res := range m.Resources() {
+		if isConfigLike(res) {
+			args, err := writeConfigMapFiles(fSys, base, res)
+			if err != nil {
+				return err
+			}
+			k.ConfigMapGenerator = append(k.ConfigMapGenerator, args)
+			continue
+		}
+		name := resourceFileName(res, fileNames)
+		out, err := res.AsYAML()
+		if err != nil {
+			return err
+		}
+		if err := fSys.WriteFile(filepath.Join(base, name), out); err != nil {
+			return err
+		}
+		k.Resources = append(k.Resources, name)
+	}
+	if err := writeKustomization(fSys, base, k); err != nil {
+		return err
+	}
+
+	if err := fSys.MkdirAll(overlay); err != nil {
+		return err
+	}
+	return writeKustomization(fSys, overlay, &types.Kustomization{
+		TypeMeta: k.TypeMeta,
+		Resources: []string{
+			filepath.ToSlash(filepath.Join("..", "..", baseDir)),
+		},
+		Namespace: namespace,
+	})
+}
+
+// stripNamespaces removes the namespaces of the resources of m, and
+// returns the namespace they share, if any.
+func stripNamespaces(m resmap.ResMap) (string, error) {
+	namespaces := map[string]bool{}
+	for _, res := range m.Resources() {
+		ns := res.GetNamespace()
+		if ns == "" {
+			continue
+		}
+		namespaces[ns] = true
+		if err := res.SetNamespace(""); err != nil {
+			return "", err
+		}
+	}
+	if len(namespaces) != 1 {
+		return "", nil
+	}
+	for ns := range namespaces {
+		return ns, nil
+	}
+	return "", nil
+}
+
+// extractImages removes the tags and digests of the images of the
+// resources of m, and returns the images entries setting them back.
+// Images used with several tags or digests are left as they are.
+func extractImages(m resmap.ResMap) ([]types.Image, error) {
+	versions := map[string]map[string]bool{}
+	for _, res := range m.Resources() {
+		if err := images.VisitImages(&res.RNode, func(_, _ string, image *yaml.RNode) {
+			name, tag, digest := splitImage(image.YNode().Value)
+			if versions[name] == nil {
+				versions[name] = map[string]bool{}
+			}
+			versions[name][tag+"@"+digest] = true
+		}); err != nil {
+			return nil, err
+		}
+	}
+	var result []types.Image
+	for name, vs := range versions {
+		if len(vs) != 1 || vs["@"] {
+			continue
+		}
+		for v := range vs {
+			tag, digest, _ := strings.Cut(v, "@")
+			result = append(result, types.Image{Name: name, NewTag: tag, Digest: digest})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	extracted := map[string]bool{}
+	for _, i := range result {
+		extracted[i.Name] = true
+	}
+	for _, res := range m.Resources() {
+		if err := images.VisitImages(&res.RNode, func(_, _ string, image *yaml.RNode) {
+			if name, _, _ := splitImage(image.YNode().Value); extracted[name] {
+				image.YNode().Value = name
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// splitImage splits an image reference into its name, tag and digest.
+func splitImage(image string) (name, tag, digest string) {
+	name = image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	return name, tag, digest
+}
+
+// isConfigLike returns whether res is a ConfigMap replaced by a
+// configMapGenerator entry.
+func isConfigLike(res *resource.Resource) bool {
+	return res.GetApiVersion() == "v1" && res.GetKind() == "ConfigMap" &&
+		len(res.GetBinaryDataMap()) == 0
+}
+
+// writeConfigMapFiles writes the data of the ConfigMap res to files in
+// a directory named after it, and returns the generator arguments of
+// the ConfigMap.  The generated ConfigMap keeps its name, without a
+// hash suffix, as templates of the chart may refer to it by name.
+func writeConfigMapFiles(
+	fSys filesys.FileSystem, base string, res *resource.Resource) (types.ConfigMapArgs, error) {
+	args := types.ConfigMapArgs{}
+	args.Name = res.GetName()
+	args.Options = &types.GeneratorOptions{
+		Labels:                res.GetLabels(),
+		Annotations:           res.GetAnnotations(),
+		DisableNameSuffixHash: true,
+	}
+	if len(args.Options.Labels) == 0 {
+		args.Options.Labels = nil
+	}
+	if len(args.Options.Annotations) == 0 {
+		args.Options.Annotations = nil
+	}
+	data := res.GetDataMap()
+	if len(data) == 0 {
+		return args, nil
+	}
+	dir := filepath.Join(base, args.Name)
+	if err := fSys.MkdirAll(dir); err != nil {
+		return args, err
+	}
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fSys.WriteFile(filepath.Join(dir, key), []byte(data[key])); err != nil {
+			return args, err
+		}
+		args.FileSources = append(args.FileSources, filepath.ToSlash(filepath.Join(args.Name, key)))
+	}
+	return args, nil
+}
+
+// resourceFileName returns the name of the file of res, e.g.
+// deployment-app.yaml, which isn't used yet.
+func resourceFileName(res *resource.Resource, used map[string]bool) string {
+	prefix := strings.ToLower(res.GetKind()) + "-" + res.GetName()
+	name := prefix + ".yaml"
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s-%d.yaml", prefix, i)
+	}
+	used[name] = true
+	return name
+}
+
+// writeKustomization writes the formatted kustomization k in dir.
+func writeKustomization(fSys filesys.FileSystem, dir string, k *types.Kustomization) error {
+	content, err := yaml.Marshal(k)
+	if err != nil {
+		return err
+	}
+	content, err = format.Format(content, format.Options{})
+	if err != nil {
+		return err
+	}
+	return fSys.WriteFile(filepath.Join(dir, konfig.DefaultKustomizationFileName()), content)
+}
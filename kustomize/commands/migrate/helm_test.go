@@ -0,0 +1,128 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	. "sigs.k8s.io/kustomize/kustomize/v5/commands/migrate"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// inflated are resources as inflated by helm.
+const inflated = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  namespace: apps
+  labels:
+    app: app
+data:
+  LOG_LEVEL: info
+  app.properties: |
+    port=8080
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: apps
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: registry.example.com:5000/app/migrate:1.2.0
+      containers:
+      - name: app
+        image: registry.example.com:5000/app/server@sha256:abc
+        envFrom:
+        - configMapRef:
+            name: app-config
+      - name: proxy
+        image: envoyproxy/envoy:v1.27.0
+      - name: debug
+        image: busybox
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: app
+  namespace: apps
+spec:
+  template:
+    spec:
+      containers:
+      - name: proxy
+        image: envoyproxy/envoy:v1.28.0
+`
+
+func TestScaffold(t *testing.T) {
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("chart/kustomization.yaml", []byte("resources:\n- inflated.yaml\n")))
+	require.NoError(t, fSys.WriteFile("chart/inflated.yaml", []byte(inflated)))
+	// generated ConfigMaps come after the resources, unless sorted
+	opts := krusty.MakeDefaultOptions()
+	opts.Reorder = krusty.ReorderOptionLegacy
+	k := krusty.MakeKustomizer(opts)
+	m, err := k.Run(fSys, "chart")
+	require.NoError(t, err)
+	expected, err := m.AsYaml()
+	require.NoError(t, err)
+
+	require.NoError(t, Scaffold(fSys, "out", m, ScaffoldOptions{Overlay: "prod"}))
+
+	for path, content := range map[string]string{
+		"out/base/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment-app.yaml
+- job-app.yaml
+configMapGenerator:
+- name: app-config
+  files:
+  - app-config/LOG_LEVEL
+  - app-config/app.properties
+  options:
+    labels:
+      app: app
+    disableNameSuffixHash: true
+images:
+- name: registry.example.com:5000/app/migrate
+  newTag: 1.2.0
+- name: registry.example.com:5000/app/server
+  digest: sha256:abc
+`,
+		"out/overlays/prod/kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- ../../base
+namespace: apps
+`,
+		"out/base/app-config/app.properties": "port=8080\n",
+	} {
+		actual, err := fSys.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(actual), path)
+	}
+	deployment, err := fSys.ReadFile("out/base/deployment-app.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(deployment), "image: registry.example.com:5000/app/migrate\n")
+	assert.Contains(t, string(deployment), "image: envoyproxy/envoy:v1.27.0\n")
+	assert.NotContains(t, string(deployment), "namespace")
+
+	// the overlay builds the inflated resources
+	m, err = k.Run(fSys, "out/overlays/prod")
+	require.NoError(t, err)
+	actual, err := m.AsYaml()
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+
+	assert.EqualError(t, Scaffold(fSys, "out", m, ScaffoldOptions{Overlay: "prod"}),
+		"out/base already exists")
+}
@@ -0,0 +1,28 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package migrate contains the commands migrating configuration
+// from other tools to kustomizations.
+package migrate
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// NewCmdMigrate returns a new migrate command.
+func NewCmdMigrate(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "migrate",
+		Short: "[Alpha] Migrates configuration from other tools to kustomizations",
+		Example: `
+	# Scaffold a base and an overlay from a local helm chart
+	kustomize migrate helm ./charts/app --values values-prod.yaml
+`,
+		Args: cobra.MinimumNArgs(1),
+	}
+	c.AddCommand(NewCmdMigrateHelm(fSys, w))
+	return c
+}
@@ -0,0 +1,367 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package test runs the tests of kustomizations.
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/utils"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// TestFileName is the name of the test file of a kustomization.
+	TestFileName = "kustomize_test.yaml"
+
+	// recursiveSuffix makes a directory argument match the kustomizations
+	// of its subdirectories too.
+	recursiveSuffix = "/..."
+
+	contextLines = 3
+)
+
+// Test is the content of a test file.  The kustomization in the directory
+// of the test file is built, and its output compared with the
+// expectations of the test.
+type Test struct {
+	// Golden is the path of a file, relative to the test file, which
+	// must be equal to the output of the kustomization.
+	Golden string `json:"golden,omitempty" yaml:"golden,omitempty"`
+
+	// Assertions about the resources of the output.
+	Assertions []Assertion `json:"assertions,omitempty" yaml:"assertions,omitempty"`
+}
+
+// Assertion is an assertion about the resources of the output which
+// match a selector, e.g.
+//
+//	select:
+//	  kind: Deployment
+//	  name: api
+//	path: spec.replicas
+//	value: 3
+type Assertion struct {
+	// Select selects the resources of the assertion.
+	Select types.Selector `json:"select" yaml:"select"`
+
+	// Count, if set, is the number of resources selected.  Otherwise, at
+	// least one resource must be selected.
+	Count *int `json:"count,omitempty" yaml:"count,omitempty"`
+
+	// Path, if set, is the path of a field of the selected resources,
+	// in the syntax of the field paths of replacements, e.g.
+	// spec.template.spec.containers.[name=api].image.  The field must
+	// exist.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Value, if set, is the value of the field at Path.
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+type flags struct {
+	update bool
+}
+
+// NewCmdTest returns a new test command.
+func NewCmdTest(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f flags
+	cmd := &cobra.Command{
+		Use:   "test [DIR]...",
+		Short: "[Alpha] Tests the outputs of kustomizations",
+		Long: `[Alpha] Builds the kustomizations with a ` + TestFileName + ` test
+file in the given directories, and checks their outputs against the
+expectations of the tests.  A directory ending with /... matches its
+subdirectories too.  The default is ./...
+
+A test file compares the output with a golden file, and makes assertions
+about the resources of the output:
+
+  golden: expected.yaml
+  assertions:
+  - select:
+      kind: Deployment
+      name: api
+    path: spec.replicas
+    value: 3
+  - select:
+      kind: ConfigMap
+      name: debug
+    count: 0
+
+With --update, the golden files are written with the outputs instead.
+`,
+		Example: `
+# Run the tests of all the kustomizations of the repository
+kustomize test ./...
+
+# Update the golden files of the overlays
+kustomize test overlays/... --update
+`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{filesys.SelfDir + recursiveSuffix}
+			}
+			return run(fSys, w, args, f)
+		},
+	}
+	cmd.Flags().BoolVar(&f.update, "update", false,
+		"Write the outputs of the kustomizations to their golden files "+
+			"instead of comparing them.")
+	return cmd
+}
+
+func run(fSys filesys.FileSystem, w io.Writer, args []string, f flags) error {
+	var dirs []string
+	for _, arg := range args {
+		d, err := testDirs(fSys, arg)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, d...)
+	}
+	if len(dirs) == 0 {
+		return errors.Errorf("no %s found", TestFileName)
+	}
+	failed := 0
+	for _, dir := range dirs {
+		failures, err := RunTest(fSys, dir, f.update)
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+		if len(failures) == 0 {
+			fmt.Fprintf(w, "ok    %s\n", dir)
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "FAIL  %s\n", dir)
+		for _, failure := range failures {
+			fmt.Fprintf(w, "    %s\n", strings.ReplaceAll(
+				strings.TrimRight(failure, "\n"), "\n", "\n    "))
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d tests failed", failed, len(dirs))
+	}
+	return nil
+}
+
+// testDirs returns the directories with a test file matching arg, which
+// is a directory, or a directory followed by /... to match its
+// subdirectories too.
+func testDirs(fSys filesys.FileSystem, arg string) ([]string, error) {
+	dir := strings.TrimSuffix(arg, recursiveSuffix)
+	if dir == "" {
+		dir = filesys.SelfDir
+	}
+	if !fSys.IsDir(dir) {
+		return nil, errors.Errorf("%s isn't a directory", dir)
+	}
+	if !strings.HasSuffix(arg, recursiveSuffix) {
+		if !fSys.Exists(filepath.Join(dir, TestFileName)) {
+			return nil, errors.Errorf("no %s in %s", TestFileName, dir)
+		}
+		return []string{dir}, nil
+	}
+	var dirs []string
+	// some file systems walk absolute paths, the first one being dir
+	root := ""
+	err := fSys.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if root == "" {
+			root = p
+		}
+		if info.IsDir() && p != root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || info.Name() != TestFileName {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, filepath.Join(dir, rel))
+		return nil
+	})
+	sort.Strings(dirs)
+	return dirs, errors.Wrap(err)
+}
+
+// RunTest runs the test of the kustomization in dir, and returns the
+// failures of the test.  If update is true, the golden file of the test
+// is written with the output of the kustomization instead of compared.
+func RunTest(fSys filesys.FileSystem, dir string, update bool) ([]string, error) {
+	content, err := fSys.ReadFile(filepath.Join(dir, TestFileName))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	var t Test
+	if err := yaml.Unmarshal(content, &t); err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid %s", TestFileName)
+	}
+	m, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, dir)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "building %s", dir)
+	}
+	var failures []string
+	if t.Golden != "" {
+		failure, err := checkGolden(fSys, filepath.Join(dir, t.Golden), m, update)
+		if err != nil {
+			return nil, err
+		}
+		if failure != "" {
+			failures = append(failures, failure)
+		}
+	}
+	for i, a := range t.Assertions {
+		failure, err := a.check(m)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "assertion %d", i+1)
+		}
+		if failure != "" {
+			failures = append(failures, fmt.Sprintf("assertion %d: %s", i+1, failure))
+		}
+	}
+	return failures, nil
+}
+
+// checkGolden compares the output m with the golden file at path, or
+// writes the output to it if update is true.
+func checkGolden(fSys filesys.FileSystem, path string, m resmap.ResMap, update bool) (string, error) {
+	actual, err := m.AsYaml()
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	if update {
+		return "", errors.Wrap(fSys.WriteFile(path, actual))
+	}
+	expected, err := fSys.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	if bytes.Equal(expected, actual) {
+		return "", nil
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(string(actual)),
+		FromFile: path,
+		ToFile:   "output",
+		Context:  contextLines,
+	})
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	return "the output differs from the golden file:\n" + diff, nil
+}
+
+// check returns the failure of the assertion on the output m, if any.
+func (a Assertion) check(m resmap.ResMap) (string, error) {
+	selected, err := m.Select(a.Select)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	if a.Count != nil {
+		if len(selected) != *a.Count {
+			return fmt.Sprintf("%s selects %d resources, expected %d",
+				describe(a.Select), len(selected), *a.Count), nil
+		}
+	} else if len(selected) == 0 {
+		return fmt.Sprintf("%s selects no resources", describe(a.Select)), nil
+	}
+	if a.Path == "" {
+		return "", nil
+	}
+	for _, r := range selected {
+		field, err := r.Pipe(yaml.Lookup(utils.SmarterPathSplitter(a.Path, ".")...))
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		id := r.CurId().String()
+		if field == nil {
+			return fmt.Sprintf("%s has no field %s", id, a.Path), nil
+		}
+		if a.Value == nil {
+			continue
+		}
+		equal, actual, err := equalValues(field, a.Value)
+		if err != nil {
+			return "", err
+		}
+		if !equal {
+			expected, _ := json.Marshal(a.Value)
+			return fmt.Sprintf("%s has %s %s, expected %s", id, a.Path, actual, expected), nil
+		}
+	}
+	return "", nil
+}
+
+// describe returns the non-empty terms of the selector s, e.g.
+// kind=Deployment,name=api.
+func describe(s types.Selector) string {
+	var terms []string
+	for _, t := range [][2]string{
+		{"group", s.Group},
+		{"version", s.Version},
+		{"kind", s.Kind},
+		{"name", s.Name},
+		{"namespace", s.Namespace},
+		{"labelSelector", s.LabelSelector},
+		{"annotationSelector", s.AnnotationSelector},
+	} {
+		if t[1] != "" {
+			terms = append(terms, t[0]+"="+t[1])
+		}
+	}
+	if len(terms) == 0 {
+		return "the empty selector"
+	}
+	return strings.Join(terms, ",")
+}
+
+// equalValues returns whether the field has the expected value, and
+// the field as JSON.
+func equalValues(field *yaml.RNode, expected interface{}) (bool, string, error) {
+	var actual interface{}
+	if err := field.YNode().Decode(&actual); err != nil {
+		return false, "", errors.Wrap(err)
+	}
+	// compare the values decoded from JSON, so that e.g. numbers
+	// have the same type
+	actualJSON, err := json.Marshal(actual)
+	if err != nil {
+		return false, "", errors.Wrap(err)
+	}
+	expectedJSON, err := json.Marshal(expected)
+	if err != nil {
+		return false, "", errors.Wrap(err)
+	}
+	var a, e interface{}
+	if err := json.Unmarshal(actualJSON, &a); err != nil {
+		return false, "", errors.Wrap(err)
+	}
+	if err := json.Unmarshal(expectedJSON, &e); err != nil {
+		return false, "", errors.Wrap(err)
+	}
+	return reflect.DeepEqual(a, e), string(actualJSON), nil
+}
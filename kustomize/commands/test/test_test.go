@@ -0,0 +1,176 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package test_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "sigs.k8s.io/kustomize/kustomize/v5/commands/test"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: api
+        image: api:1.0
+`
+
+const prodOutput = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: prod
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: api:1.0
+        name: api
+`
+
+func writeFiles(t *testing.T, fSys filesys.FileSystem, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		require.NoError(t, fSys.WriteFile(path, []byte(content)))
+	}
+}
+
+func makeTree(t *testing.T) filesys.FileSystem {
+	t.Helper()
+	fSys := filesys.MakeFsInMemory()
+	writeFiles(t, fSys, map[string]string{
+		"base/kustomization.yaml": "resources:\n- deployment.yaml\n",
+		"base/deployment.yaml":    deployment,
+		"overlays/prod/kustomization.yaml": `resources:
+- ../../base
+namespace: prod
+replicas:
+- name: api
+  count: 3
+`,
+		"overlays/prod/kustomize_test.yaml": `golden: expected.yaml
+assertions:
+- select:
+    kind: Deployment
+    name: api
+  path: spec.replicas
+  value: 3
+- select:
+    kind: Deployment
+  path: spec.template.spec.containers.[name=api].image
+  value: api:1.0
+- select:
+    kind: ConfigMap
+  count: 0
+`,
+		"overlays/prod/expected.yaml": prodOutput,
+	})
+	return fSys
+}
+
+func TestRunTest(t *testing.T) {
+	fSys := makeTree(t)
+	failures, err := RunTest(fSys, "overlays/prod", false)
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+}
+
+func TestRunTest_failures(t *testing.T) {
+	fSys := makeTree(t)
+	writeFiles(t, fSys, map[string]string{
+		"overlays/prod/expected.yaml": deployment,
+		"overlays/prod/kustomize_test.yaml": `golden: expected.yaml
+assertions:
+- select:
+    kind: Deployment
+  path: spec.replicas
+  value: 2
+- select:
+    kind: Deployment
+  path: spec.paused
+- select:
+    kind: Service
+- select:
+    kind: Deployment
+  count: 2
+`,
+	})
+	failures, err := RunTest(fSys, "overlays/prod", false)
+	require.NoError(t, err)
+	require.Len(t, failures, 5)
+	assert.Contains(t, failures[0], "the output differs from the golden file:\n"+
+		"--- overlays/prod/expected.yaml\n+++ output\n")
+	assert.Contains(t, failures[0], "\n+  namespace: prod\n")
+	assert.Equal(t, []string{
+		"assertion 1: Deployment.v1.apps/api.prod has spec.replicas 3, expected 2",
+		"assertion 2: Deployment.v1.apps/api.prod has no field spec.paused",
+		"assertion 3: kind=Service selects no resources",
+		"assertion 4: kind=Deployment selects 1 resources, expected 2",
+	}, failures[1:])
+}
+
+func TestRunTest_update(t *testing.T) {
+	fSys := makeTree(t)
+	require.NoError(t, fSys.WriteFile("overlays/prod/expected.yaml", []byte(deployment)))
+	failures, err := RunTest(fSys, "overlays/prod", true)
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+	content, err := fSys.ReadFile("overlays/prod/expected.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, prodOutput, string(content))
+}
+
+func TestRunTest_invalid(t *testing.T) {
+	fSys := makeTree(t)
+	require.NoError(t, fSys.WriteFile("overlays/prod/kustomize_test.yaml", []byte("golden: [a]\n")))
+	_, err := RunTest(fSys, "overlays/prod", false)
+	require.ErrorContains(t, err, "invalid kustomize_test.yaml")
+}
+
+func TestCmdTest(t *testing.T) {
+	fSys := makeTree(t)
+	writeFiles(t, fSys, map[string]string{
+		"overlays/dev/kustomization.yaml": "resources:\n- ../../base\n",
+		"overlays/dev/kustomize_test.yaml": `assertions:
+- select:
+    name: api
+  path: spec.replicas
+  value: 2
+`,
+	})
+
+	var out bytes.Buffer
+	cmd := NewCmdTest(fSys, &out)
+	cmd.SetArgs([]string{"overlays/..."})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	require.EqualError(t, cmd.Execute(), "1 of 2 tests failed")
+	assert.Equal(t, `FAIL  overlays/dev
+    assertion 1: Deployment.v1.apps/api.[noNs] has spec.replicas 1, expected 2
+ok    overlays/prod
+`, out.String())
+
+	out.Reset()
+	cmd = NewCmdTest(fSys, &out)
+	cmd.SetArgs([]string{"overlays/prod"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "ok    overlays/prod\n", out.String())
+
+	cmd = NewCmdTest(fSys, &out)
+	cmd.SetArgs([]string{"base"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	require.EqualError(t, cmd.Execute(), "no kustomize_test.yaml in base")
+}
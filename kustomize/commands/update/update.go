@@ -0,0 +1,542 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package update updates the versions of the remote bases, the helm
+// charts and the function images of kustomizations.
+package update
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/kustomize/api/pkg/loader"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/internal/kustfile"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// The largest updates allowed by the --allow flag.
+const (
+	LevelMajor = "major"
+	LevelMinor = "minor"
+	LevelPatch = "patch"
+)
+
+const ociScheme = "oci://"
+
+// Update is the update of a version in a kustomization file, or in the
+// config file of a function.
+type Update struct {
+	// File is the path of the kustomization file, or of the config file of
+	// the function.
+	File string
+	// Field is the field of the kustomization, e.g. resources, helmCharts
+	// or transformers.
+	Field string
+	// Name is the url of the remote base, the name of the chart, or the
+	// image of the function without its tag.
+	Name string
+	Old  string
+	New  string
+}
+
+func (u Update) String() string {
+	return fmt.Sprintf("%s: %s %s: %s -> %s", u.File, u.Field, u.Name, u.Old, u.New)
+}
+
+type flags struct {
+	dryRun bool
+	allow  string
+}
+
+// NewCmdUpdate returns a new update command.
+func NewCmdUpdate(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	var f flags
+	cmd := &cobra.Command{
+		Use:   "update [DIR]",
+		Short: "[Alpha] Updates the refs of remote bases, the versions of helm charts and the tags of function images",
+		Long: `[Alpha] Updates the refs of the remote bases and components, the
+versions of the helm charts, and the tags of the images of the container
+functions, of the kustomizations in DIR and its subdirectories to the
+newest versions available upstream.  The default DIR is the current
+directory.
+
+Only refs and versions which are semantic versions, e.g. v1.2.3 or
+1.2.3, are updated, to the newest version of the same form which isn't a
+prerelease.  Branches, commits and version ranges are left as they are,
+and so are the images pinned by digest and those whose tag is a version
+constraint, e.g. ^2, which are resolved by each build.  The tags of
+remote bases are listed with git; the versions of charts are read from
+the index of their repository; the tags of images are listed from their
+registry.  The functions are updated in the kustomizations' generators,
+transformers and validators, inline or in their files.
+`,
+		Example: `
+# Update the kustomizations of the repository to the newest minor versions
+kustomize update
+
+# Show the updates of the overlays, including major versions, without writing them
+kustomize update overlays --allow major --dry-run
+`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := filesys.SelfDir
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			switch f.allow {
+			case LevelMajor, LevelMinor, LevelPatch:
+			default:
+				return errors.Errorf("illegal flag value --allow %s; legal values: %v",
+					f.allow, []string{LevelMajor, LevelMinor, LevelPatch})
+			}
+			updates, err := Run(fSys, dir, f.allow, f.dryRun)
+			for _, u := range updates {
+				fmt.Fprintln(w, u)
+			}
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&f.dryRun, "dry-run", false,
+		"Print the updates without writing them.")
+	cmd.Flags().StringVar(&f.allow, "allow", LevelMinor,
+		"The largest update allowed: '"+LevelMajor+"', '"+LevelMinor+
+			"' to keep the major version, or '"+LevelPatch+"' to keep the minor version.")
+	return cmd
+}
+
+// Run updates the kustomizations in dir and its subdirectories, and
+// returns the updates.  The kustomization files are left unchanged if
+// dryRun is true.
+func Run(fSys filesys.FileSystem, dir string, allow string, dryRun bool) ([]Update, error) {
+	if !fSys.IsDir(dir) {
+		return nil, errors.Errorf("%s isn't a directory", dir)
+	}
+	files, err := kustfile.KustomizationFiles(fSys, dir)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	u := &updater{allow: allow, tags: map[string][]string{}, charts: map[string]map[string][]string{},
+		images: container.NewImageResolver(), functionFiles: map[string]bool{}}
+	var result []Update
+	for _, file := range files {
+		content, err := fSys.ReadFile(file)
+		if err != nil {
+			return result, errors.Wrap(err)
+		}
+		updated, updates, err := u.updateKustomization(file, content)
+		if err != nil {
+			return result, errors.WrapPrefixf(err, "updating %s", file)
+		}
+		result = append(result, updates...)
+		if len(updates) == 0 || dryRun {
+			continue
+		}
+		if err := fSys.WriteFile(file, updated); err != nil {
+			return result, errors.Wrap(err)
+		}
+	}
+	for _, file := range files {
+		updates, err := u.updateFunctionFiles(fSys, file, dryRun)
+		result = append(result, updates...)
+		if err != nil {
+			return result, errors.WrapPrefixf(err, "updating the functions of %s", file)
+		}
+	}
+	return result, nil
+}
+
+type updater struct {
+	allow string
+	// tags are the tags of the git repositories.
+	tags map[string][]string
+	// charts are the versions of the charts of the helm repositories.
+	charts map[string]map[string][]string
+	// images lists the tags of the images of the functions.
+	images *container.ImageResolver
+	// functionFiles are the config files of functions already updated.
+	functionFiles map[string]bool
+}
+
+// functionFields are the fields of kustomizations listing the configs of
+// functions, inline or in files.
+var functionFields = []string{"generators", "transformers", "validators"}
+
+// updateKustomization returns the content of the kustomization file
+// with its versions updated, and the updates.
+func (u *updater) updateKustomization(file string, content []byte) ([]byte, []Update, error) {
+	node, err := yaml.Parse(string(content))
+	if err != nil {
+		return nil, nil, err
+	}
+	var updates []Update
+	for _, field := range []string{"resources", "components", "bases"} {
+		seq, err := node.Pipe(yaml.Lookup(field))
+		if err != nil {
+			return nil, nil, err
+		}
+		if seq == nil {
+			continue
+		}
+		for _, item := range seq.YNode().Content {
+			update, err := u.updateRemote(item.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			if update == nil {
+				continue
+			}
+			update.File, update.Field = file, field
+			item.Value = replaceRef(item.Value, update.Old, update.New)
+			updates = append(updates, *update)
+		}
+	}
+	for _, field := range functionFields {
+		seq, err := node.Pipe(yaml.Lookup(field))
+		if err != nil {
+			return nil, nil, err
+		}
+		if seq == nil {
+			continue
+		}
+		for _, item := range seq.YNode().Content {
+			if !isInlineConfig(item.Value) {
+				continue
+			}
+			updated, fnUpdates, err := u.updateFunctions(item.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, update := range fnUpdates {
+				update.File, update.Field = file, field
+				updates = append(updates, update)
+			}
+			item.Value = updated
+		}
+	}
+	charts, err := node.Pipe(yaml.Lookup("helmCharts"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if charts != nil {
+		for _, chart := range charts.Content() {
+			update, err := u.updateChart(yaml.NewRNode(chart))
+			if err != nil {
+				return nil, nil, err
+			}
+			if update != nil {
+				update.File, update.Field = file, "helmCharts"
+				updates = append(updates, *update)
+			}
+		}
+	}
+	if len(updates) == 0 {
+		return content, nil, nil
+	}
+	out, err := node.String()
+	if err != nil {
+		return nil, nil, err
+	}
+	return []byte(out), updates, nil
+}
+
+// updateRemote returns the update of the ref of the remote base at
+// url, or nil if there's none.
+func (u *updater) updateRemote(url string) (*Update, error) {
+	repo, ref, err := loader.RepoURL(url)
+	if err != nil || ref == "" {
+		// not a remote base with a ref
+		return nil, nil //nolint:nilerr
+	}
+	if _, ok := parseVersion(ref); !ok {
+		return nil, nil
+	}
+	tags, found := u.tags[repo]
+	if !found {
+		tags, err = listTags(repo)
+		if err != nil {
+			return nil, err
+		}
+		u.tags[repo] = tags
+	}
+	newest := newestVersion(ref, tags, u.allow)
+	if newest == ref {
+		return nil, nil
+	}
+	return &Update{Name: url, Old: ref, New: newest}, nil
+}
+
+// updateChart updates the version of the helm chart, and returns the
+// update, or nil if there's none.
+func (u *updater) updateChart(chart *yaml.RNode) (*Update, error) {
+	name, repo := kustfile.FieldValue(chart.YNode(), "name"), kustfile.FieldValue(chart.YNode(), "repo")
+	current := kustfile.FieldValue(chart.YNode(), "version")
+	if name == "" || repo == "" || current == "" || strings.HasPrefix(repo, ociScheme) {
+		// local charts and the charts of oci registries can't be listed
+		return nil, nil
+	}
+	if _, ok := parseVersion(current); !ok {
+		// e.g. a version range
+		return nil, nil
+	}
+	charts, found := u.charts[repo]
+	if !found {
+		var err error
+		charts, err = readChartIndex(repo)
+		if err != nil {
+			return nil, err
+		}
+		u.charts[repo] = charts
+	}
+	newest := newestVersion(current, charts[name], u.allow)
+	if newest == current {
+		return nil, nil
+	}
+	chart.Field("version").Value.YNode().Value = newest
+	return &Update{Name: name, Old: current, New: newest}, nil
+}
+
+// updateFunctionFiles updates the images of the functions in the config
+// files listed by the kustomization file, and returns the updates.  The
+// files are left unchanged if dryRun is true.
+func (u *updater) updateFunctionFiles(fSys filesys.FileSystem, file string, dryRun bool) ([]Update, error) {
+	content, err := fSys.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	node, err := yaml.Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	var result []Update
+	for _, field := range functionFields {
+		seq, err := node.Pipe(yaml.Lookup(field))
+		if err != nil {
+			return result, err
+		}
+		if seq == nil {
+			continue
+		}
+		for _, item := range seq.YNode().Content {
+			if isInlineConfig(item.Value) || strings.Contains(item.Value, "://") {
+				continue
+			}
+			path := filepath.Join(filepath.Dir(file), item.Value)
+			// directories are kustomizations of functions, updated by themselves
+			if u.functionFiles[path] || !fSys.Exists(path) || fSys.IsDir(path) {
+				continue
+			}
+			u.functionFiles[path] = true
+			config, err := fSys.ReadFile(path)
+			if err != nil {
+				return result, errors.Wrap(err)
+			}
+			updated, updates, err := u.updateFunctions(string(config))
+			if err != nil {
+				return result, errors.WrapPrefixf(err, "updating %s", path)
+			}
+			for _, update := range updates {
+				update.File, update.Field = path, field
+				result = append(result, update)
+			}
+			if len(updates) == 0 || dryRun {
+				continue
+			}
+			if err := fSys.WriteFile(path, []byte(updated)); err != nil {
+				return result, errors.Wrap(err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// isInlineConfig returns true if the entry of a function field is an
+// inline config, rather than a path or url.
+func isInlineConfig(entry string) bool {
+	return strings.Contains(entry, "\n")
+}
+
+// updateFunctions returns the configs with the tags of the images of
+// their container functions updated, and the updates.  The configs are
+// updated textually, to keep their formatting.
+func (u *updater) updateFunctions(configs string) (string, []Update, error) {
+	nodes, err := kio.FromBytes([]byte(configs))
+	if err != nil {
+		return "", nil, err
+	}
+	var updates []Update
+	updated := map[string]bool{}
+	for _, node := range nodes {
+		spec, err := runtimeutil.GetFunctionSpec(node)
+		if err != nil {
+			return "", nil, err
+		}
+		if spec == nil || spec.Container.Image == "" || strings.Contains(spec.Container.Image, "@") ||
+			updated[spec.Container.Image] {
+			// images pinned by digest are left as they are
+			continue
+		}
+		image := spec.Container.Image
+		updated[image] = true
+		name, tag := image, ""
+		if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+			name, tag = image[:i], image[i+1:]
+		}
+		if _, ok := parseVersion(tag); !ok {
+			// e.g. latest or a version constraint
+			continue
+		}
+		tags, err := u.images.Tags(image)
+		if err != nil {
+			return "", nil, err
+		}
+		newest := newestVersion(tag, tags, u.allow)
+		if newest == tag {
+			continue
+		}
+		configs = regexp.MustCompile(regexp.QuoteMeta(image)+`(["'\s]|$)`).
+			ReplaceAllString(configs, name+":"+newest+"${1}")
+		updates = append(updates, Update{Name: name, Old: tag, New: newest})
+	}
+	return configs, updates, nil
+}
+
+// replaceRef replaces the ref or version query parameter old of the
+// remote url with new.
+func replaceRef(url, old, new string) string {
+	base, query, _ := strings.Cut(url, "?")
+	params := strings.Split(query, "&")
+	for i, p := range params {
+		for _, key := range []string{"ref=", "version="} {
+			if p == key+old {
+				params[i] = key + new
+			}
+		}
+	}
+	return base + "?" + strings.Join(params, "&")
+}
+
+// listTags returns the tags of the git repository.
+func listTags(repo string) ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "ls-remote", "--tags", "--refs", repo)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "listing the tags of %s: %s",
+			repo, strings.TrimSpace(stderr.String()))
+	}
+	var tags []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if _, ref, found := strings.Cut(line, "\trefs/tags/"); found {
+			tags = append(tags, ref)
+		}
+	}
+	return tags, nil
+}
+
+// readChartIndex returns the versions of the charts of the helm repository.
+func readChartIndex(repo string) (map[string][]string, error) {
+	url := strings.TrimSuffix(repo, "/") + "/index.yaml"
+	resp, err := http.Get(url) //nolint:gosec,noctx
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "reading the index of %s", repo)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("reading %s: %s", url, resp.Status)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	var index struct {
+		Entries map[string][]struct {
+			Version string `json:"version"`
+		} `json:"entries"`
+	}
+	if err := k8syaml.Unmarshal(content, &index); err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid index %s", url)
+	}
+	result := map[string][]string{}
+	for name, entries := range index.Entries {
+		for _, e := range entries {
+			result[name] = append(result[name], e.Version)
+		}
+	}
+	return result, nil
+}
+
+// version is a semantic version, with the prefix of its tag,
+// e.g. v or api/v.
+type version struct {
+	prefix              string
+	major, minor, patch int
+	prerelease          bool
+}
+
+var versionRegexp = regexp.MustCompile(
+	`^(.*/)?(v?)(\d+)\.(\d+)\.(\d+)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// parseVersion parses a semantic version.
+func parseVersion(s string) (version, bool) {
+	m := versionRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return version{}, false
+	}
+	v := version{prefix: m[1] + m[2], prerelease: m[6] != ""}
+	var err error
+	for i, n := range []*int{&v.major, &v.minor, &v.patch} {
+		if *n, err = strconv.Atoi(m[i+3]); err != nil {
+			return version{}, false
+		}
+	}
+	return v, true
+}
+
+func (v version) lessThan(o version) bool {
+	if v.major != o.major {
+		return v.major < o.major
+	}
+	if v.minor != o.minor {
+		return v.minor < o.minor
+	}
+	return v.patch < o.patch
+}
+
+// newestVersion returns the newest of the versions, with the same prefix
+// as current and within the allowed update, or current if there's none
+// newer.  Prereleases are ignored.
+func newestVersion(current string, versions []string, allow string) string {
+	cur, ok := parseVersion(current)
+	if !ok {
+		return current
+	}
+	newest, newestV := current, cur
+	for _, s := range versions {
+		v, ok := parseVersion(s)
+		if !ok || v.prerelease || v.prefix != cur.prefix || !newestV.lessThan(v) {
+			continue
+		}
+		if (allow != LevelMajor && v.major != cur.major) ||
+			(allow == LevelPatch && v.minor != cur.minor) {
+			continue
+		}
+		newest, newestV = s, v
+	}
+	return newest
+}
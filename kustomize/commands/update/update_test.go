@@ -0,0 +1,237 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package update_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	. "sigs.k8s.io/kustomize/kustomize/v5/commands/update"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const chartIndex = `apiVersion: v1
+entries:
+  minecraft:
+  - version: 4.0.0
+  - version: 3.2.0
+  - version: 3.1.5
+  - version: 3.1.3
+  - version: 3.3.0-rc.1
+  other:
+  - version: 9.0.0
+`
+
+// makeRepo returns the url of a git repository with the tags.
+func makeRepo(t *testing.T, tags ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	for _, tag := range tags {
+		out, err := exec.Command("git", "-C", dir, "tag", tag).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	return "file://" + dir
+}
+
+func makeChartRepo(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, chartIndex)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestRun(t *testing.T) {
+	repo := makeRepo(t, "v1.0.0", "v1.2.0", "v1.2.1", "v2.0.0", "v1.3.0-beta.1", "api/v1.5.0")
+	charts := makeChartRepo(t)
+	dir := t.TempDir()
+	fSys := filesys.MakeFsOnDisk()
+	kustomization := fmt.Sprintf(`resources:
+- deployment.yaml
+- %[1]s//base?ref=v1.0.0
+- %[1]s//branch?ref=main
+components:
+- %[1]s//component?ref=v1.2.0&timeout=90s
+helmCharts:
+- name: minecraft
+  repo: %[2]s
+  version: 3.1.3
+- name: other
+  repo: %[2]s
+  version: ~9.0
+`, repo, charts)
+	for path, content := range map[string]string{
+		"overlay/kustomization.yaml":         kustomization,
+		"overlay/.hidden/kustomization.yaml": kustomization,
+		"monorepo/kustomization.yaml":        fmt.Sprintf("resources:\n- %s//api?ref=api/v1.0.0\n", repo),
+	} {
+		require.NoError(t, fSys.MkdirAll(filepath.Dir(filepath.Join(dir, path))))
+		require.NoError(t, fSys.WriteFile(filepath.Join(dir, path), []byte(content)))
+	}
+
+	file := filepath.Join(dir, "overlay/kustomization.yaml")
+	updates, err := Run(fSys, dir, LevelMinor, true)
+	require.NoError(t, err)
+	assert.Equal(t, []Update{
+		{File: filepath.Join(dir, "monorepo/kustomization.yaml"), Field: "resources",
+			Name: repo + "//api?ref=api/v1.0.0", Old: "api/v1.0.0", New: "api/v1.5.0"},
+		{File: file, Field: "resources", Name: repo + "//base?ref=v1.0.0", Old: "v1.0.0", New: "v1.2.1"},
+		{File: file, Field: "components", Name: repo + "//component?ref=v1.2.0&timeout=90s", Old: "v1.2.0", New: "v1.2.1"},
+		{File: file, Field: "helmCharts", Name: "minecraft", Old: "3.1.3", New: "3.2.0"},
+	}, updates)
+	content, err := fSys.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, kustomization, string(content))
+
+	updates, err = Run(fSys, filepath.Join(dir, "overlay"), LevelPatch, false)
+	require.NoError(t, err)
+	assert.Len(t, updates, 2)
+	content, err = fSys.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`resources:
+- deployment.yaml
+- %[1]s//base?ref=v1.0.0
+- %[1]s//branch?ref=main
+components:
+- %[1]s//component?ref=v1.2.1&timeout=90s
+helmCharts:
+- name: minecraft
+  repo: %[2]s
+  version: 3.1.5
+- name: other
+  repo: %[2]s
+  version: ~9.0
+`, repo, charts), string(content))
+
+	updates, err = Run(fSys, filepath.Join(dir, "overlay"), LevelMajor, false)
+	require.NoError(t, err)
+	assert.Equal(t, []Update{
+		{File: file, Field: "resources", Name: repo + "//base?ref=v1.0.0", Old: "v1.0.0", New: "v2.0.0"},
+		{File: file, Field: "components", Name: repo + "//component?ref=v1.2.1&timeout=90s", Old: "v1.2.1", New: "v2.0.0"},
+		{File: file, Field: "helmCharts", Name: "minecraft", Old: "3.1.5", New: "4.0.0"},
+	}, updates)
+}
+
+// makeRegistry returns the host of a registry with the tags of the
+// repository fns/set-team.
+func makeRegistry(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/fns/set-team/tags/list" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"name": "fns/set-team", "tags": ["v1.0.0", "v1.1.0", "v1.1.2", "v2.0.0", "latest"]}`)
+	}))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestRunFunctions(t *testing.T) {
+	registry := makeRegistry(t)
+	dir := t.TempDir()
+	fSys := filesys.MakeFsOnDisk()
+	setTeam := fmt.Sprintf(`apiVersion: example.com/v1
+kind: SetTeam
+metadata:
+  name: set-team
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: %s/fns/set-team:v1.0.0
+team: platform
+`, registry)
+	kustomization := fmt.Sprintf(`transformers:
+- set-team.yaml
+- |-
+  apiVersion: example.com/v1
+  kind: SetTeam
+  metadata:
+    name: inline
+    annotations:
+      config.kubernetes.io/function: |
+        container:
+          image: %[1]s/fns/set-team:v1.1.0
+validators:
+- set-team.yaml
+- |-
+  apiVersion: example.com/v1
+  kind: SetTeam
+  metadata:
+    name: constrained
+    annotations:
+      config.kubernetes.io/function: |
+        container:
+          image: %[1]s/fns/set-team:^1
+`, registry)
+	for path, content := range map[string]string{
+		"kustomization.yaml": kustomization,
+		"set-team.yaml":      setTeam,
+	} {
+		require.NoError(t, fSys.WriteFile(filepath.Join(dir, path), []byte(content)))
+	}
+
+	updates, err := Run(fSys, dir, LevelMinor, false)
+	require.NoError(t, err)
+	assert.Equal(t, []Update{
+		{File: filepath.Join(dir, "kustomization.yaml"), Field: "transformers",
+			Name: registry + "/fns/set-team", Old: "v1.1.0", New: "v1.1.2"},
+		{File: filepath.Join(dir, "set-team.yaml"), Field: "transformers",
+			Name: registry + "/fns/set-team", Old: "v1.0.0", New: "v1.1.2"},
+	}, updates)
+	content, err := fSys.ReadFile(filepath.Join(dir, "set-team.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Replace(setTeam, "v1.0.0", "v1.1.2", 1), string(content))
+	content, err = fSys.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "image: "+registry+"/fns/set-team:v1.1.2\n")
+	assert.Contains(t, string(content), "image: "+registry+"/fns/set-team:^1\n")
+}
+
+func TestCmdUpdate(t *testing.T) {
+	charts := makeChartRepo(t)
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, fSys.WriteFile("kustomization.yaml", []byte(fmt.Sprintf(`helmCharts:
+- name: minecraft
+  repo: %s
+  version: 3.1.3
+`, charts))))
+
+	var out bytes.Buffer
+	cmd := NewCmdUpdate(fSys, &out)
+	cmd.SetArgs([]string{"--dry-run"})
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "kustomization.yaml: helmCharts minecraft: 3.1.3 -> 3.2.0\n", out.String())
+
+	cmd = NewCmdUpdate(fSys, &out)
+	cmd.SetArgs([]string{"--allow", "any"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	require.EqualError(t, cmd.Execute(),
+		"illegal flag value --allow any; legal values: [major minor patch]")
+}
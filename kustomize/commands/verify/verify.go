@@ -0,0 +1,54 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package verify verifies localized kustomizations against their lockfile.
+package verify
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	lclzr "sigs.k8s.io/kustomize/api/krusty/localizer"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// NewCmdVerify returns a new verify command.
+func NewCmdVerify(fSys filesys.FileSystem, w io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify [DIR]",
+		Short: "[Alpha] Verifies a localized kustomization against its lockfile",
+		Long: `[Alpha] Verifies the directory created by kustomize localize, by default
+the current directory, for use as a supply-chain gate in CI.  The directory
+must have a ` + lclzr.LockFileName + ` lockfile, the remote content and
+the chart homes of the charts localized to the directory must match its
+digests, the remote content localized must be recorded in the lockfile, and
+the kustomizations of the directory must have no remote references, e.g. to
+remote bases, files or charts.
+
+This is the same verification as kustomize localize --verify.
+`,
+		Example: `
+# Localize a kustomization, then verify it in CI
+kustomize localize overlays/prod vendored/prod --enable-helm
+kustomize verify vendored/prod
+`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := filesys.SelfDir
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			if !fSys.IsDir(dir) {
+				return errors.Errorf("%s isn't a directory", dir)
+			}
+			if err := lclzr.Verify(fSys, dir); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "ok    %s\n", dir)
+			return nil
+		},
+	}
+}
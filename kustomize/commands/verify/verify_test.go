@@ -0,0 +1,48 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package verify_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	lclzr "sigs.k8s.io/kustomize/api/krusty/localizer"
+	"sigs.k8s.io/kustomize/kustomize/v5/commands/verify"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+func TestVerify(t *testing.T) {
+	const remoteFile = "localized-files/example.com/remote.yaml"
+	fSys := filesys.MakeFsInMemory()
+	for path, content := range map[string]string{
+		remoteFile:           "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: remote\n",
+		"kustomization.yaml": "resources:\n- " + remoteFile + "\n",
+		lclzr.LockFileName: `sources:
+- digest: sha256:e2e0c5571ba6eba77d70a3ef37e8c5ba1bc628c8f89763a15ff0f0cb08fc5a90
+  path: localized-files/example.com/remote.yaml
+  url: https://example.com/remote.yaml
+`,
+	} {
+		require.NoError(t, fSys.WriteFile(filepath.Join("/dst", path), []byte(content)))
+	}
+
+	var out bytes.Buffer
+	cmd := verify.NewCmdVerify(fSys, &out)
+	require.NoError(t, cmd.RunE(cmd, []string{"/dst"}))
+	require.Equal(t, "ok    /dst\n", out.String())
+
+	require.NoError(t, fSys.WriteFile("/dst/kustomization.yaml", []byte(
+		"resources:\n- "+remoteFile+"\n- https://example.com/other.yaml\n")))
+	require.NoError(t, fSys.WriteFile(filepath.Join("/dst", remoteFile), []byte("tampered")))
+	err := cmd.RunE(cmd, []string{"/dst"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `localized directory /dst does not verify:
+  localized-files/example.com/remote.yaml (https://example.com/remote.yaml): expected digest sha256:e2e0c5571ba6eba77d70a3ef37e8c5ba1bc628c8f89763a15ff0f0cb08fc5a90, got sha256:`)
+	require.Contains(t, err.Error(), `
+  kustomization.yaml: remote reference resources "https://example.com/other.yaml"`)
+
+	require.EqualError(t, cmd.RunE(cmd, []string{"/missing"}), "/missing isn't a directory")
+}
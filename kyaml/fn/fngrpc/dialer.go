@@ -0,0 +1,62 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fngrpc
+
+import (
+	"context"
+	"path/filepath"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/local"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/fngrpc/fnpb"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+)
+
+// Dialer connects the runners of exec functions to the functions served by
+// ListenAndServe, e.g. as the dialer of an exec.ServerPool.
+type Dialer struct{}
+
+var _ exec.ServerDialer = Dialer{}
+
+// Dial connects to the function listening on the unix socket at path.
+func (Dialer) Dial(path string) (exec.ServerConn, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	cc, err := grpc.Dial("unix://"+path, grpc.WithTransportCredentials(local.NewCredentials()))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return &conn{cc: cc, client: fnpb.NewResourceListProcessorClient(cc)}, nil
+}
+
+// conn is the connection to a function served by ListenAndServe.
+type conn struct {
+	cc     *grpc.ClientConn
+	client fnpb.ResourceListProcessorClient
+}
+
+// Process sends the ResourceList in to the function, and returns the
+// resulting ResourceList.  If the function fails to process it, Process
+// returns the ResourceList with the results of the failure too, and an
+// *exec.FunctionError.  Other errors, e.g. of the connection or of the
+// deadline of ctx, are gRPC status errors.
+func (c *conn) Process(ctx context.Context, in []byte) ([]byte, error) {
+	resp, err := c.client.Process(ctx, &fnpb.ProcessRequest{ResourceList: in},
+		grpc.MaxCallRecvMsgSize(framework.MaxFrameSize), grpc.MaxCallSendMsgSize(framework.MaxFrameSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetError() != "" {
+		return resp.GetResourceList(), &exec.FunctionError{Message: resp.GetError()}
+	}
+	return resp.GetResourceList(), nil
+}
+
+func (c *conn) Close() error {
+	return c.cc.Close()
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fngrpc_test
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/fngrpc"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework/command"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// TestHelperServer is the function the tests run as a server, labelling
+// the resources with its pid.  It fails on a resource named bad, and hangs
+// on one named hang.
+func TestHelperServer(t *testing.T) {
+	if os.Getenv("FNGRPC_TEST_SERVER") != "1" {
+		t.Skip("only run as the function of the tests")
+	}
+	cmd := fngrpc.Build(framework.SimpleProcessor{Filter: kio.FilterFunc(
+		func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+			for _, n := range nodes {
+				switch n.GetName() {
+				case "bad":
+					return nil, errors.Errorf("bad resource")
+				case "hang":
+					select {}
+				}
+				if err := n.PipeE(yaml.SetLabel("pid", strconv.Itoa(os.Getpid()))); err != nil {
+					return nil, err
+				}
+			}
+			return nodes, nil
+		})}, command.StandaloneDisabled, false)
+	// the arguments of the function follow --
+	for i, arg := range os.Args {
+		if arg == "--" {
+			cmd.SetArgs(os.Args[i+1:])
+		}
+	}
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestDialer_ServerPool(t *testing.T) {
+	servers := exec.NewServerPool(fngrpc.Dialer{})
+	defer servers.Close()
+	instance := &exec.Filter{
+		Path:       os.Args[0],
+		Args:       []string{"-test.run=^TestHelperServer$", "--"},
+		Env:        []string{"FNGRPC_TEST_SERVER=1"},
+		WorkingDir: t.TempDir(),
+		Protocol:   runtimeutil.ExecProtocolServer,
+		Servers:    servers,
+	}
+	run := func(name string) (string, error) {
+		t.Helper()
+		output, err := instance.Filter([]*yaml.RNode{
+			yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n"),
+		})
+		if err != nil {
+			return "", err
+		}
+		require.Len(t, output, 1)
+		return output[0].GetLabels()["pid"], nil
+	}
+
+	// the ResourceLists are sent to the same server
+	first, err := run("config")
+	require.NoError(t, err)
+	assert.NotEmpty(t, first)
+	pid, err := run("config")
+	require.NoError(t, err)
+	assert.Equal(t, first, pid)
+
+	// the failures of the function fail the run, but keep the server
+	_, err = run("bad")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "run as a server: processing filter: bad resource")
+	pid, err = run("config")
+	require.NoError(t, err)
+	assert.Equal(t, first, pid)
+
+	// a server which times out is replaced
+	defer func(stop time.Duration) { exec.ServerStopTimeout = stop }(exec.ServerStopTimeout)
+	exec.ServerStopTimeout = time.Second
+	instance.Timeout = time.Second
+	_, err = run("hang")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DeadlineExceeded")
+	pid, err = run("config")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, pid)
+}
@@ -0,0 +1,8 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fnpb contains the ResourceListProcessor gRPC service of the KRM
+// functions run as servers, generated from processor.proto.
+package fnpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative processor.proto
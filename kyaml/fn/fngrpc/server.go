@@ -0,0 +1,111 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fngrpc serves KRM functions as the ResourceListProcessor gRPC
+// service of the fnpb package on a unix socket, for the exec functions run
+// with the "server" protocol, and connects the runners of the functions to
+// them.  It's a module of its own, so that kyaml doesn't depend on gRPC.
+package fngrpc
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/local"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/fngrpc/fnpb"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework/command"
+)
+
+// Build returns the cobra.Command of command.Build, with a --listen flag
+// running the function with ListenAndServe on the unix socket at its path.
+func Build(p framework.ResourceListProcessor, mode command.CLIMode, noPrintError bool) *cobra.Command {
+	cmd := command.Build(p, mode, noPrintError)
+	var listen string
+	cmd.Flags().StringVar(&listen, "listen", "",
+		"process the ResourceLists of the gRPC calls to the unix socket at this path "+
+			"until STDIN is closed")
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if listen != "" {
+			return ListenAndServe(p, listen, cmd.InOrStdin(), cmd.OutOrStdout())
+		}
+		return run(cmd, args)
+	}
+	return cmd
+}
+
+// ListenAndServe runs p as a long-running server like framework.Serve, but
+// as the gRPC service fnpb.ResourceListProcessor on the unix socket at path
+// rather than on STDIN and STDOUT, so that the ResourceLists aren't
+// interleaved with the output of the function, and the failures of the
+// function are told apart from those of the calls.
+//
+// ListenAndServe advertises it listens to w as a line with
+// framework.AddressPrefix and path, and processes the ResourceLists of all
+// the calls one at a time.  It returns nil once r, e.g. STDIN, is closed by
+// the runner of the function, and the calls in progress have completed.
+func ListenAndServe(p framework.ResourceListProcessor, path string, r io.Reader, w io.Writer) error {
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if _, err := fmt.Fprintf(w, "%s%s\n", framework.AddressPrefix, path); err != nil {
+		l.Close()
+		return errors.Wrap(err)
+	}
+	s := grpc.NewServer(grpc.Creds(local.NewCredentials()),
+		grpc.MaxRecvMsgSize(framework.MaxFrameSize), grpc.MaxSendMsgSize(framework.MaxFrameSize))
+	fnpb.RegisterResourceListProcessorServer(s, NewServer(p))
+	go func() {
+		_, _ = io.Copy(io.Discard, r)
+		s.GracefulStop()
+	}()
+	if err := s.Serve(l); err != nil && !goerrors.Is(err, grpc.ErrServerStopped) {
+		return errors.Wrap(err)
+	}
+	return nil
+}
+
+// NewServer returns the implementation of fnpb.ResourceListProcessor
+// processing the ResourceLists of the calls with p, one at a time, e.g. to
+// serve it alongside other services.
+func NewServer(p framework.ResourceListProcessor) fnpb.ResourceListProcessorServer {
+	return &processorServer{p: p}
+}
+
+// processorServer implements fnpb.ResourceListProcessor.
+type processorServer struct {
+	fnpb.UnimplementedResourceListProcessorServer
+
+	// mu serializes the ResourceLists of the calls
+	mu sync.Mutex
+	p  framework.ResourceListProcessor
+}
+
+func (s *processorServer) Process(ctx context.Context, req *fnpb.ProcessRequest) (*fnpb.ProcessResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		// the call was cancelled or timed out waiting for the others
+		return nil, status.FromContextError(err).Err()
+	}
+	out, err := framework.ProcessResourceList(s.p, req.GetResourceList())
+	if out == nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &fnpb.ProcessResponse{ResourceList: out}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp, nil
+}
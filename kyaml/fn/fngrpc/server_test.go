@@ -0,0 +1,85 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package fngrpc_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/fngrpc"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestListenAndServe(t *testing.T) {
+	p := framework.SimpleProcessor{Filter: kio.FilterFunc(func(in []*yaml.RNode) ([]*yaml.RNode, error) {
+		for _, item := range in {
+			if item.GetName() == "bad" {
+				return nil, errors.Errorf("bad resource")
+			}
+			if err := item.PipeE(yaml.SetLabel("served", "true")); err != nil {
+				return nil, err
+			}
+		}
+		return in, nil
+	})}
+	socket := filepath.Join(t.TempDir(), "fn.sock")
+	stdin, closeStdin := io.Pipe()
+	stdout, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- fngrpc.ListenAndServe(p, socket, stdin, w) }()
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, framework.AddressPrefix+socket+"\n", line)
+	conn, err := fngrpc.Dialer{}.Dial(socket)
+	require.NoError(t, err)
+	defer conn.Close()
+	process := func(name string) ([]byte, error) {
+		return conn.Process(context.Background(), []byte(`
+apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: `+name+`
+`))
+	}
+
+	for _, name := range []string{"a", "b"} {
+		out, err := process(name)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "name: "+name+"\n    labels:\n      served: 'true'\n")
+	}
+
+	// the failures of the function come with its results
+	out, err := process("bad")
+	var fnErr *exec.FunctionError
+	require.ErrorAs(t, err, &fnErr)
+	assert.Equal(t, "processing filter: bad resource", fnErr.Message)
+	assert.Contains(t, string(out), `results:
+- message: 'processing filter: bad resource'
+  severity: error
+`)
+
+	// unlike those of the request
+	out, err = conn.Process(context.Background(), []byte("items: {"))
+	assert.Nil(t, out)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "failed to read ResourceList input")
+
+	closeStdin.Close()
+	assert.NoError(t, <-done)
+}
@@ -45,6 +45,10 @@ const (
 // The output will be of the same type as the input (e.g. ResourceList).
 // Example usage: `cat resource_list.yaml | go run main.go`
 //
+// The cobra.Command has a boolean `--serve` flag to run the function as a long-running
+// server, which processes a stream of length-prefixed ResourceLists read from STDIN and
+// writes the results to STDOUT.  See framework.Serve for details.
+//
 // By default, any error returned by the ResourceListProcessor will be printed to STDERR.
 // Set noPrintError to true to suppress this.
 func Build(p framework.ResourceListProcessor, mode CLIMode, noPrintError bool) *cobra.Command {
@@ -52,11 +56,17 @@ func Build(p framework.ResourceListProcessor, mode CLIMode, noPrintError bool) *
 
 	var printStack bool
 	cmd.Flags().BoolVar(&printStack, "stack", false, "print the stack trace on failure")
+	var serve bool
+	cmd.Flags().BoolVar(&serve, "serve", false,
+		"process a stream of length-prefixed ResourceLists from STDIN until it is closed")
 	cmd.Args = cobra.MinimumNArgs(0)
 	cmd.SilenceErrors = true
 	cmd.SilenceUsage = true
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if serve {
+			return framework.Serve(p, cmd.InOrStdin(), cmd.OutOrStdout())
+		}
 		var readers []io.Reader
 		rw := &kio.ByteReadWriter{
 			Writer:                cmd.OutOrStdout(),
@@ -122,7 +122,13 @@ func Execute(p ResourceListProcessor, rlSource *kio.ByteReadWriter) error {
 	if rlSource.Writer == nil {
 		rlSource.Writer = os.Stdout
 	}
+	return execute(p, rlSource, false)
+}
 
+// execute reads a ResourceList from rlSource, passes it to p, and writes the result
+// to rlSource.  If resultsOnError is true, an error returned by p is added to the
+// ResourceList results instead of being returned.
+func execute(p ResourceListProcessor, rlSource *kio.ByteReadWriter, resultsOnError bool) error {
 	// Read the input
 	rl := ResourceList{}
 	var err error
@@ -138,6 +144,10 @@ func Execute(p ResourceListProcessor, rlSource *kio.ByteReadWriter) error {
 	}
 
 	retErr := p.Process(&rl)
+	if retErr != nil && resultsOnError {
+		rl.Results = appendErrorResults(rl.Results, retErr)
+		retErr = nil
+	}
 
 	// If either the internal annotations for path, index, and id OR the legacy
 	// annotations for path, index, and id are changed, we have to update the other.
@@ -166,6 +176,28 @@ func Execute(p ResourceListProcessor, rlSource *kio.ByteReadWriter) error {
 	return retErr
 }
 
+// appendErrorResults appends err to results as error Results, skipping the
+// Results already contained in results.
+func appendErrorResults(results Results, err error) Results {
+	var r Results
+	if !goerrors.As(err, &r) {
+		return append(results, &Result{Message: err.Error(), Severity: Error})
+	}
+	for _, item := range r {
+		found := false
+		for _, existing := range results {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			results = append(results, item)
+		}
+	}
+	return results
+}
+
 // Filter executes the given kio.Filter and replaces the ResourceList's items with the result.
 // This can be used to help implement ResourceListProcessors. See SimpleProcessor for example.
 //
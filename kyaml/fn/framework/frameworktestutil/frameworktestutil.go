@@ -2,6 +2,18 @@
 // SPDX-License-Identifier: Apache-2.0
 
 // Package frameworktestutil contains utilities for testing functions written using the framework.
+//
+// The expected output of the tests, i.e. the golden files of GoldenRunner and
+// the expected testdata files of the checkers, is rewritten from the actual
+// output when the environment variable KUSTOMIZE_UPDATE_GOLDEN (UpdateGoldenEnv)
+// is true:
+//
+//	KUSTOMIZE_UPDATE_GOLDEN=true go test ./...
+//
+// It's an environment variable rather than an -update test flag, since a
+// flag registered by this package on the global flag set of every test
+// binary importing it would panic with "flag redefined" in the tests
+// which already define their own -update flag.
 package frameworktestutil
 
 import (
@@ -49,7 +61,8 @@ type CommandResultsChecker struct {
 	ExpectedErrorFilename string
 
 	// UpdateExpectedFromActual if set to true will write the actual results to the
-	// expected testdata files.  This is useful for updating test data.
+	// expected testdata files.  This is useful for updating test data.  Running
+	// the tests with UpdateGoldenEnv set to true has the same effect.
 	UpdateExpectedFromActual bool
 
 	// OutputAssertionFunc allows you to swap out the logic used to compare the expected output
@@ -154,7 +167,8 @@ type ProcessorResultsChecker struct {
 	ExpectedErrorFilename string
 
 	// UpdateExpectedFromActual if set to true will write the actual results to the
-	// expected testdata files.  This is useful for updating test data.
+	// expected testdata files.  This is useful for updating test data.  Running
+	// the tests with UpdateGoldenEnv set to true has the same effect.
 	UpdateExpectedFromActual bool
 
 	// InputFilename is the name of the file containing the ResourceList input.
@@ -331,7 +345,7 @@ func (rc *checkerCore) recordTestCase(s string) {
 }
 
 func (rc *checkerCore) shouldUpdateFixtures() bool {
-	return rc.updateExpectedFromActual
+	return rc.updateExpectedFromActual || updateGoldenFromEnv()
 }
 
 func (rc *checkerCore) updateFixtures(t *testing.T, actualOutput string, actualError string) {
@@ -49,6 +49,23 @@ func TestCommandResultsChecker_UpdateExpectedFromActual(t *testing.T) {
 	require.Contains(t, checker.TestCasesRun(), filepath.Join(dir, "important_subdir"))
 }
 
+func TestProcessorResultsChecker_updateGoldenEnv(t *testing.T) {
+	t.Setenv(UpdateGoldenEnv, "true")
+	dir := filepath.FromSlash("testdata/update_expectations/processor")
+	checker := ProcessorResultsChecker{
+		TestDataDirectory: dir,
+		Processor:         testProcessor,
+	}
+	// This should result in the test being skipped, like with UpdateExpectedFromActual.
+	checker.Assert(t)
+	require.Contains(t, checker.TestCasesRun(), filepath.Join(dir, "important_subdir"))
+
+	t.Setenv(UpdateGoldenEnv, "")
+	// This time should inherently pass
+	checker.Assert(t)
+	require.Contains(t, checker.TestCasesRun(), filepath.Join(dir, "important_subdir"))
+}
+
 func testCommand() *cobra.Command {
 	return command.Build(testProcessor(), command.StandaloneEnabled, false)
 }
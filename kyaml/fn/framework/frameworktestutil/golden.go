@@ -0,0 +1,180 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package frameworktestutil
+
+import (
+	"bytes"
+	goerrors "errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	DefaultGoldenDirectory       = "testdata/golden"
+	DefaultGoldenOutputFilename  = "output.yaml"
+	DefaultGoldenResultsFilename = "results.yaml"
+)
+
+// UpdateGoldenEnv is the environment variable which, when set to true, makes
+// GoldenRunner write the golden files of its test cases, and the checkers
+// write their expected testdata files, e.g. with
+// `KUSTOMIZE_UPDATE_GOLDEN=true go test ./...`.
+const UpdateGoldenEnv = "KUSTOMIZE_UPDATE_GOLDEN"
+
+// GoldenTestCase is a test case run by GoldenRunner.
+type GoldenTestCase struct {
+	// Name is the name of the test case.  It is also the name of the directory of its
+	// golden files in the GoldenDirectory of the runner.  Required.
+	Name string
+
+	// Package is the directory of the input package, which is read with a
+	// kio.LocalPackageReader.  Required.
+	Package string
+
+	// FunctionConfig is the path to the file containing the function config.
+	// Optional.
+	FunctionConfig string
+}
+
+// GoldenRunner runs a function against the input packages of a table of test cases,
+// and compares the output items and Results of the function to golden files.
+//
+// The golden files of a test case are in the directory named after the test case in
+// GoldenDirectory.  They are written from the actual output instead of being compared
+// when UpdateGoldenFiles is true, or when the tests are run with UpdateGoldenEnv set
+// to true.
+type GoldenRunner struct {
+	// Processor returns the function to run.  Required.
+	Processor func() framework.ResourceListProcessor
+
+	// GoldenDirectory is the directory containing the golden files of the test cases.
+	// Defaults to "testdata/golden".
+	GoldenDirectory string
+
+	// OutputFilename is the name of the golden file with the expected items.
+	// Defaults to "output.yaml".
+	OutputFilename string
+
+	// ResultsFilename is the name of the golden file with the expected Results.
+	// Test cases producing no Results have no results file.
+	// Defaults to "results.yaml".
+	ResultsFilename string
+
+	// UpdateGoldenFiles if set to true will write the actual output to the golden files.
+	UpdateGoldenFiles bool
+
+	// OutputAssertionFunc allows you to swap out the logic used to compare the golden
+	// files to the actual output and Results.
+	// By default, it performs a string comparison after normalizing whitespace.
+	OutputAssertionFunc AssertionFunc
+}
+
+// Run runs each test case as a subtest of t.
+func (gr GoldenRunner) Run(t *testing.T, cases []GoldenTestCase) {
+	t.Helper()
+	if gr.GoldenDirectory == "" {
+		gr.GoldenDirectory = DefaultGoldenDirectory
+	}
+	if gr.OutputFilename == "" {
+		gr.OutputFilename = DefaultGoldenOutputFilename
+	}
+	if gr.ResultsFilename == "" {
+		gr.ResultsFilename = DefaultGoldenResultsFilename
+	}
+	if gr.OutputAssertionFunc == nil {
+		gr.OutputAssertionFunc = RequireStrippedStringsEqual
+	}
+	require.NotZero(t, len(cases), "No test cases provided")
+	for i := range cases {
+		tc := cases[i]
+		t.Run(tc.Name, func(t *testing.T) {
+			gr.runTestCase(t, tc)
+		})
+	}
+}
+
+func (gr GoldenRunner) runTestCase(t *testing.T, tc GoldenTestCase) {
+	t.Helper()
+	require.NotEmpty(t, tc.Name, "test case is missing a Name")
+	require.NotEmpty(t, tc.Package, "test case %s is missing a Package", tc.Name)
+	output, results := gr.process(t, tc)
+
+	dir := filepath.Join(gr.GoldenDirectory, tc.Name)
+	outputFile := filepath.Join(dir, gr.OutputFilename)
+	resultsFile := filepath.Join(dir, gr.ResultsFilename)
+	if gr.UpdateGoldenFiles || updateGoldenFromEnv() {
+		require.NoError(t, os.MkdirAll(dir, 0700))
+		require.NoError(t, os.WriteFile(outputFile, []byte(output), 0600))
+		if results == "" {
+			err := os.Remove(resultsFile)
+			if !os.IsNotExist(err) {
+				require.NoError(t, err)
+			}
+		} else {
+			require.NoError(t, os.WriteFile(resultsFile, []byte(results), 0600))
+		}
+		t.Skip("Updated golden files for test case")
+	}
+
+	expectedOutput, err := os.ReadFile(outputFile)
+	require.NoError(t, err, "missing golden file, run the tests with "+UpdateGoldenEnv+"=true to create it")
+	gr.OutputAssertionFunc(t, string(expectedOutput), output)
+
+	expectedResults, err := os.ReadFile(resultsFile)
+	if os.IsNotExist(err) {
+		require.Emptyf(t, results, "test expected no results, but got:\n%s", results)
+		return
+	}
+	require.NoError(t, err)
+	gr.OutputAssertionFunc(t, string(expectedResults), results)
+}
+
+// process runs the function against the package of tc, and returns the
+// serialized output items and Results.  An error returned by the function is
+// added to the Results, as it would be reported to the user.
+func (gr GoldenRunner) process(t *testing.T, tc GoldenTestCase) (string, string) {
+	t.Helper()
+	rl := &framework.ResourceList{}
+	var err error
+	rl.Items, err = (&kio.LocalPackageReader{PackagePath: tc.Package}).Read()
+	require.NoError(t, err)
+	if tc.FunctionConfig != "" {
+		b, err := os.ReadFile(tc.FunctionConfig)
+		require.NoError(t, err)
+		rl.FunctionConfig, err = yaml.Parse(string(b))
+		require.NoError(t, err)
+	}
+
+	if err := gr.Processor().Process(rl); err != nil {
+		var results framework.Results
+		if goerrors.As(err, &results) {
+			rl.Results = append(rl.Results, results...)
+		} else {
+			rl.Results = append(rl.Results, &framework.Result{
+				Message: err.Error(), Severity: framework.Error})
+		}
+	}
+
+	var output bytes.Buffer
+	require.NoError(t, kio.ByteWriter{Writer: &output}.Write(rl.Items))
+	if len(rl.Results) == 0 {
+		return output.String(), ""
+	}
+	results, err := yaml.Marshal(rl.Results)
+	require.NoError(t, err)
+	return output.String(), string(results)
+}
+
+// updateGoldenFromEnv returns true if UpdateGoldenEnv is set to true.
+func updateGoldenFromEnv() bool {
+	update, _ := strconv.ParseBool(os.Getenv(UpdateGoldenEnv))
+	return update
+}
@@ -0,0 +1,78 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package frameworktestutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var goldenTestCases = []GoldenTestCase{
+	{
+		Name:           "app",
+		Package:        filepath.FromSlash("testdata/golden_packages/app"),
+		FunctionConfig: filepath.FromSlash("testdata/golden_packages/config.yaml"),
+	},
+	{
+		Name:    "invalid",
+		Package: filepath.FromSlash("testdata/golden_packages/invalid"),
+	},
+}
+
+func TestGoldenRunner(t *testing.T) {
+	GoldenRunner{Processor: goldenProcessor}.Run(t, goldenTestCases)
+}
+
+func TestGoldenRunner_UpdateGoldenFiles(t *testing.T) {
+	dir := t.TempDir()
+	runner := GoldenRunner{
+		Processor:         goldenProcessor,
+		GoldenDirectory:   dir,
+		UpdateGoldenFiles: true,
+	}
+	// This should result in the test cases being skipped after writing the golden files
+	runner.Run(t, goldenTestCases)
+	assert.FileExists(t, filepath.Join(dir, "app", DefaultGoldenOutputFilename))
+	assert.NoFileExists(t, filepath.Join(dir, "app", DefaultGoldenResultsFilename))
+	assert.FileExists(t, filepath.Join(dir, "invalid", DefaultGoldenOutputFilename))
+	b, err := os.ReadFile(filepath.Join(dir, "invalid", DefaultGoldenResultsFilename))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "configmap invalid is invalid")
+
+	runner.UpdateGoldenFiles = false
+	// This time should inherently pass
+	runner.Run(t, goldenTestCases)
+}
+
+func goldenProcessor() framework.ResourceListProcessor {
+	return framework.ResourceListProcessorFunc(func(rl *framework.ResourceList) error {
+		value := "none"
+		if rl.FunctionConfig != nil {
+			value = rl.FunctionConfig.GetDataMap()["value"]
+		}
+		var results framework.Results
+		for _, node := range rl.Items {
+			if node.GetKind() == "ConfigMap" && node.GetName() == "invalid" {
+				results = append(results, &framework.Result{
+					Message:  "configmap invalid is invalid",
+					Severity: framework.Error,
+				})
+				continue
+			}
+			if err := node.PipeE(yaml.SetAnnotation("golden", value)); err != nil {
+				return err
+			}
+		}
+		if len(results) > 0 {
+			return results
+		}
+		return nil
+	})
+}
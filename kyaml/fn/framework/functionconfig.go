@@ -0,0 +1,333 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	validationErrors "k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// LoadTypedFunctionConfig reads a configuration resource from YAML into the provided data
+// structure like LoadFunctionConfig, and additionally:
+//
+//   - validates src against the schema of api, which is the schema provided by api if it
+//     implements ValidationSchemaProvider, or otherwise the schema generated by SchemaFromType
+//   - applies the defaults declared by the schema to src before loading it
+//   - returns the validation failures as Results with the path of each invalid field, so
+//     that they are reported to the user as the results of the function
+//
+// Defaulter and Validator are invoked as by LoadFunctionConfig.
+func LoadTypedFunctionConfig(src *yaml.RNode, api interface{}) error {
+	if api == nil {
+		return nil
+	}
+	var schema *spec.Schema
+	var err error
+	if s, ok := api.(ValidationSchemaProvider); ok {
+		schema, err = s.Schema()
+		if err != nil {
+			return errors.WrapPrefixf(err, "loading provided schema")
+		}
+	} else if schema, err = SchemaFromType(api); err != nil {
+		return errors.WrapPrefixf(err, "generating schema")
+	}
+
+	if src == nil {
+		src = yaml.NewMapRNode(nil)
+	} else {
+		src = src.Copy()
+	}
+	if err := ApplySchemaDefaults(schema, src); err != nil {
+		return err
+	}
+	results := validationResults(validate.AgainstSchema(schema, src, strfmt.Default))
+	if len(results) > 0 {
+		// don't unmarshal invalid config, the unmarshal errors would be less helpful
+		return results
+	}
+
+	// using sigs.k8s.io/yaml here lets the custom types embed core types
+	// that only have json tags, notably types from k8s.io/apimachinery/pkg/apis/meta/v1
+	if err := k8syaml.Unmarshal([]byte(src.MustString()), api); err != nil {
+		return errors.Wrap(err)
+	}
+
+	if d, ok := api.(Defaulter); ok {
+		if err := d.Default(); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	if v, ok := api.(Validator); ok {
+		results = validationResults(v.Validate())
+	}
+	if len(results) > 0 {
+		return results
+	}
+	return nil
+}
+
+// validationResults converts validation errors to error Results.
+func validationResults(err error) Results {
+	if err == nil {
+		return nil
+	}
+	var results Results
+	if goerrors.As(err, &results) {
+		return results
+	}
+	var composite *validationErrors.CompositeError
+	if goerrors.As(err, &composite) {
+		for _, e := range composite.Errors {
+			results = append(results, validationResults(e)...)
+		}
+		return results
+	}
+	result := &Result{Message: err.Error(), Severity: Error}
+	var validation *validationErrors.Validation
+	if goerrors.As(err, &validation) && validation.Name != "" && validation.Name != "." {
+		result.Field = &Field{Path: strings.TrimPrefix(validation.Name, ".")}
+	}
+	return Results{result}
+}
+
+// ApplySchemaDefaults sets the fields of node missing a value to the default declared by
+// their schema.  Defaults are only applied to the fields of objects which exist in node.
+func ApplySchemaDefaults(schema *spec.Schema, node *yaml.RNode) error {
+	if schema == nil || yaml.IsMissingOrNull(node) {
+		return nil
+	}
+	switch node.YNode().Kind {
+	case yaml.MappingNode:
+		for _, name := range sortedPropertyNames(schema) {
+			prop := schema.Properties[name]
+			field := node.Field(name)
+			if field.IsNilOrEmpty() {
+				if prop.Default == nil {
+					continue
+				}
+				value, err := defaultValue(prop.Default)
+				if err != nil {
+					return errors.WrapPrefixf(err, "default of %s", name)
+				}
+				if err := node.PipeE(yaml.SetField(name, value)); err != nil {
+					return errors.Wrap(err)
+				}
+				continue
+			}
+			if err := ApplySchemaDefaults(&prop, field.Value); err != nil {
+				return err
+			}
+		}
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+			return node.VisitFields(func(field *yaml.MapNode) error {
+				if _, found := schema.Properties[field.Key.YNode().Value]; found {
+					return nil
+				}
+				return ApplySchemaDefaults(schema.AdditionalProperties.Schema, field.Value)
+			})
+		}
+	case yaml.SequenceNode:
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return nil
+		}
+		elements, err := node.Elements()
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		for i := range elements {
+			if err := ApplySchemaDefaults(schema.Items.Schema, elements[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sortedPropertyNames returns the names of the properties of schema in a stable order.
+func sortedPropertyNames(schema *spec.Schema) []string {
+	var names []string
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// defaultValue returns the node of a schema default value.
+func defaultValue(v interface{}) (*yaml.RNode, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	// json is yaml, so the value is parsed as a scalar or flow style node
+	return yaml.Parse(string(b))
+}
+
+// SchemaFromType generates an openapi schema for the Go type of api, so that
+// function configs can be validated without writing a schema.
+//
+// The schema is generated from the json tags of the fields of the type, or from the
+// yaml tags of fields without a json tag.  Fields are not required, as the tags don't
+// say whether they are.  The default of a field may be set by a `default` tag, e.g.
+//
+//	Replicas int `json:"replicas,omitempty" default:"1"`
+func SchemaFromType(api interface{}) (*spec.Schema, error) {
+	t := reflect.TypeOf(api)
+	if t == nil {
+		return nil, errors.Errorf("cannot generate the schema of nil")
+	}
+	return schemaForType(t, map[reflect.Type]bool{})
+}
+
+// schemaForType generates the schema of t.  seen contains the struct types being
+// generated, to stop at recursive types.
+func schemaForType(t reflect.Type, seen map[reflect.Type]bool) (*spec.Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	s := &spec.Schema{}
+	switch t.Kind() {
+	case reflect.Bool:
+		s.Type = spec.StringOrArray{"boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s.Type = spec.StringOrArray{"integer"}
+	case reflect.Float32, reflect.Float64:
+		s.Type = spec.StringOrArray{"number"}
+	case reflect.String:
+		s.Type = spec.StringOrArray{"string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is marshalled as a base64 string
+			s.Type = spec.StringOrArray{"string"}
+			break
+		}
+		items, err := schemaForType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		s.Type = spec.StringOrArray{"array"}
+		s.Items = &spec.SchemaOrArray{Schema: items}
+	case reflect.Map:
+		values, err := schemaForType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		s.Type = spec.StringOrArray{"object"}
+		s.AdditionalProperties = &spec.SchemaOrBool{Allows: true, Schema: values}
+	case reflect.Struct:
+		if seen[t] || implementsMarshaler(t) {
+			// recursive type, or a type with its own encoding -- allow any value
+			break
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		s.Type = spec.StringOrArray{"object"}
+		s.Properties = map[string]spec.Schema{}
+		if err := addStructProperties(s, t, seen); err != nil {
+			return nil, err
+		}
+	}
+	// interfaces and other kinds accept any value
+	return s, nil
+}
+
+// addStructProperties adds the schemas of the fields of the struct type t to s.
+func addStructProperties(s *spec.Schema, t reflect.Type, seen map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, inline, skip := fieldName(f)
+		if skip {
+			continue
+		}
+		if inline {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := addStructProperties(s, ft, seen); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		fs, err := schemaForType(f.Type, seen)
+		if err != nil {
+			return errors.WrapPrefixf(err, "field %s", f.Name)
+		}
+		if d, found := f.Tag.Lookup("default"); found {
+			if fs.Default, err = parseDefault(d, fs); err != nil {
+				return errors.WrapPrefixf(err, "default of field %s", f.Name)
+			}
+		}
+		s.Properties[name] = *fs
+	}
+	return nil
+}
+
+// fieldName returns the name of the field f in the encoded struct, whether the
+// fields of f are inlined in the struct, and whether f is skipped.
+func fieldName(f reflect.StructField) (string, bool, bool) {
+	tag, found := f.Tag.Lookup("json")
+	if !found {
+		tag, found = f.Tag.Lookup("yaml")
+	}
+	parts := strings.Split(tag, ",")
+	if parts[0] == "-" && len(parts) == 1 {
+		return "", false, true
+	}
+	for _, p := range parts[1:] {
+		if p == "inline" {
+			return "", true, false
+		}
+	}
+	if parts[0] != "" {
+		return parts[0], false, false
+	}
+	if f.Anonymous && !found {
+		// embedded structs without tags are inlined
+		return "", true, false
+	}
+	if !f.IsExported() {
+		return "", false, true
+	}
+	return f.Name, false, false
+}
+
+// parseDefault parses the default tag value d of a field with schema s.
+func parseDefault(d string, s *spec.Schema) (interface{}, error) {
+	switch {
+	case s.Type.Contains("string"):
+		return d, nil
+	case s.Type.Contains("integer"):
+		return strconv.ParseInt(d, 10, 64)
+	case s.Type.Contains("number"):
+		return strconv.ParseFloat(d, 64)
+	case s.Type.Contains("boolean"):
+		return strconv.ParseBool(d)
+	default:
+		var v interface{}
+		err := json.Unmarshal([]byte(d), &v)
+		return v, errors.Wrap(err)
+	}
+}
+
+// implementsMarshaler returns true if t has its own json encoding, e.g. metav1.Time.
+func implementsMarshaler(t reflect.Type) bool {
+	marshaler := reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	return t.Implements(marshaler) || reflect.PtrTo(t).Implements(marshaler)
+}
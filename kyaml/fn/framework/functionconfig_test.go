@@ -0,0 +1,111 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+type typedConfig struct {
+	yaml.ResourceMeta `json:",inline"`
+	Spec              typedConfigSpec `json:"spec"`
+}
+
+type typedConfigSpec struct {
+	Replicas int               `json:"replicas,omitempty" default:"1"`
+	Image    string            `json:"image,omitempty" default:"nginx"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Ports    []typedPort       `json:"ports,omitempty"`
+}
+
+type typedPort struct {
+	Name string `json:"name"`
+	Port int    `json:"port,omitempty" default:"80"`
+}
+
+func TestSchemaFromType(t *testing.T) {
+	s, err := SchemaFromType(&typedConfig{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"apiVersion", "kind", "metadata", "spec"}, sortedPropertyNames(s))
+	spec := s.Properties["spec"]
+	assert.Equal(t, "integer", spec.Properties["replicas"].Type[0])
+	assert.Equal(t, int64(1), spec.Properties["replicas"].Default)
+	assert.Equal(t, "nginx", spec.Properties["image"].Default)
+	assert.Equal(t, "string", spec.Properties["labels"].AdditionalProperties.Schema.Type[0])
+	ports := spec.Properties["ports"]
+	assert.Equal(t, "array", ports.Type[0])
+	assert.Equal(t, int64(80), ports.Items.Schema.Properties["port"].Default)
+}
+
+func TestLoadTypedFunctionConfig(t *testing.T) {
+	src := yaml.MustParse(`
+apiVersion: example.io/v1
+kind: Typed
+metadata:
+  name: t
+spec:
+  image: redis
+  ports:
+  - name: a
+  - name: b
+    port: 8080
+`)
+	var c typedConfig
+	require.NoError(t, LoadTypedFunctionConfig(src, &c))
+	assert.Equal(t, typedConfigSpec{
+		Replicas: 1,
+		Image:    "redis",
+		Ports:    []typedPort{{Name: "a", Port: 80}, {Name: "b", Port: 8080}},
+	}, c.Spec)
+	// src is not modified
+	assert.Nil(t, src.Field("spec").Value.Field("replicas"))
+}
+
+func TestLoadTypedFunctionConfig_fieldErrors(t *testing.T) {
+	src := yaml.MustParse(`
+apiVersion: example.io/v1
+kind: Typed
+spec:
+  replicas: many
+  ports:
+  - name: a
+    port: eighty
+`)
+	var c typedConfig
+	err := LoadTypedFunctionConfig(src, &c)
+	require.Error(t, err)
+	results, ok := err.(Results)
+	require.True(t, ok, err.Error())
+	var paths []string
+	for _, r := range results {
+		assert.Equal(t, Error, r.Severity)
+		require.NotNil(t, r.Field)
+		paths = append(paths, r.Field.Path)
+	}
+	assert.ElementsMatch(t, []string{"spec.replicas", "spec.ports[0].port"}, paths)
+}
+
+type validatedConfig struct {
+	Value string `json:"value,omitempty"`
+}
+
+func (c *validatedConfig) Validate() error {
+	if c.Value == "" {
+		return Results{{Message: "value is required", Severity: Error,
+			Field: &Field{Path: "value"}}}
+	}
+	return nil
+}
+
+func TestLoadTypedFunctionConfig_validator(t *testing.T) {
+	var c validatedConfig
+	err := LoadTypedFunctionConfig(yaml.MustParse(`{}`), &c)
+	assert.Equal(t, Results{{Message: "value is required", Severity: Error,
+		Field: &Field{Path: "value"}}}, err)
+	assert.NoError(t, LoadTypedFunctionConfig(yaml.MustParse(`value: a`), &c))
+}
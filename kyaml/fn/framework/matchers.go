@@ -5,6 +5,7 @@ package framework
 
 import (
 	"bytes"
+	"path"
 	"strings"
 	"text/template"
 
@@ -148,6 +149,45 @@ func GVKMatcher(names ...string) ResourceTemplateMatcher {
 	}
 }
 
+// NamePatternMatcher matches resources whose metadata.name matches one of the provided glob
+// patterns, using the syntax of path.Match.
+// e.g. `NamePatternMatcher("foo-*", "bar")` matches if `metadata.name` starts with "foo-" or is "bar".
+//
+// NamePatternMatcher supports templating.
+func NamePatternMatcher(patterns ...string) ResourceTemplateMatcher {
+	return &TemplatedMetaSliceMatcher{
+		Templates: patterns,
+		MetaMatcher: func(patterns sets.String, meta yaml.ResourceMeta) bool {
+			return matchesAnyPattern(patterns, meta.Name)
+		},
+	}
+}
+
+// NamespacePatternMatcher matches resources whose metadata.namespace matches one of the provided
+// glob patterns, using the syntax of path.Match.
+// e.g. `NamespacePatternMatcher("team-*")` matches if `metadata.namespace` starts with "team-".
+//
+// NamespacePatternMatcher supports templating.
+func NamespacePatternMatcher(patterns ...string) ResourceTemplateMatcher {
+	return &TemplatedMetaSliceMatcher{
+		Templates: patterns,
+		MetaMatcher: func(patterns sets.String, meta yaml.ResourceMeta) bool {
+			return matchesAnyPattern(patterns, meta.Namespace)
+		},
+	}
+}
+
+// matchesAnyPattern returns true if value matches one of the glob patterns.
+// Malformed patterns don't match any value.
+func matchesAnyPattern(patterns sets.String, value string) bool {
+	for p := range patterns {
+		if ok, _ := path.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // TemplatedMetaSliceMatcher is a utility type for constructing matchers that compare resource
 // metadata to a slice of (possibly templated) strings.
 type TemplatedMetaSliceMatcher struct {
@@ -303,6 +343,90 @@ func (m *TemplatedMetaMapMatcher) Filter(items []*yaml.RNode) ([]*yaml.RNode, er
 
 var _ ResourceTemplateMatcher = &TemplatedMetaMapMatcher{}
 
+// LabelSelectorMatcher matches resources whose labels match the provided Kubernetes label selector.
+// e.g. `LabelSelectorMatcher("app in (foo, bar),!canary")` matches resources labelled app=foo or
+// app=bar, and not labelled canary.
+//
+// LabelSelectorMatcher supports templating.
+func LabelSelectorMatcher(selector string) ResourceTemplateMatcher {
+	return &TemplatedSelectorMatcher{
+		Template: selector,
+		SelectorMatcher: func(node *yaml.RNode, selector string) (bool, error) {
+			return node.MatchesLabelSelector(selector)
+		},
+	}
+}
+
+// AnnotationSelectorMatcher matches resources whose annotations match the provided Kubernetes
+// label selector.
+// e.g. `AnnotationSelectorMatcher("config.kubernetes.io/local-config!=true")` matches resources
+// which are not annotated as local config.
+//
+// AnnotationSelectorMatcher supports templating.
+func AnnotationSelectorMatcher(selector string) ResourceTemplateMatcher {
+	return &TemplatedSelectorMatcher{
+		Template: selector,
+		SelectorMatcher: func(node *yaml.RNode, selector string) (bool, error) {
+			return node.MatchesAnnotationSelector(selector)
+		},
+	}
+}
+
+// TemplatedSelectorMatcher is a utility type for constructing matchers that match resources to a
+// (possibly templated) selector string.
+type TemplatedSelectorMatcher struct {
+	// Template is the possibly templated selector.
+	Template string
+	// selector is the final (possibly rendered) selector.
+	selector string
+	// TemplateData is the data to use in template rendering.
+	// Rendering will not take place if it is nil when InitTemplates is called.
+	TemplateData interface{}
+	// SelectorMatcher is a function that returns true if the given resource matches the
+	// selector, or an error if the selector is invalid.
+	SelectorMatcher func(node *yaml.RNode, selector string) (bool, error)
+}
+
+// Match returns true if the resource matches the selector.
+func (m *TemplatedSelectorMatcher) Match(node *yaml.RNode) bool {
+	ok, err := m.SelectorMatcher(node, m.selector)
+	return err == nil && ok
+}
+
+// DefaultTemplateData sets TemplateData to the provided default values if it has not already
+// been set.
+func (m *TemplatedSelectorMatcher) DefaultTemplateData(data interface{}) {
+	if m.TemplateData == nil {
+		m.TemplateData = data
+	}
+}
+
+// InitTemplates renders the selector template and validates the selector before the
+// selector is applied. It should be called exactly once per filter operation, before
+// beginning match comparisons.
+func (m *TemplatedSelectorMatcher) InitTemplates() error {
+	m.selector = m.Template
+	if m.TemplateData != nil {
+		var err error
+		if m.selector, err = templatize(m.Template, m.TemplateData); err != nil {
+			return errors.WrapPrefixf(err, "unable to render template %s", m.Template)
+		}
+	}
+	if _, err := m.SelectorMatcher(yaml.NewMapRNode(nil), m.selector); err != nil {
+		return errors.WrapPrefixf(err, "invalid selector %q", m.selector)
+	}
+	return nil
+}
+
+// Filter applies the matcher to a list of items, returning only those that match.
+func (m *TemplatedSelectorMatcher) Filter(items []*yaml.RNode) ([]*yaml.RNode, error) {
+	// AndSelector or OrSelector doesn't really matter here since there is only one matcher (m).
+	s := AndSelector{Matchers: []ResourceMatcher{m}, TemplateData: m.TemplateData}
+	return s.Filter(items)
+}
+
+var _ ResourceTemplateMatcher = &TemplatedSelectorMatcher{}
+
 func templatizeSlice(values []string, data interface{}) ([]string, error) {
 	if data == nil {
 		return values, nil
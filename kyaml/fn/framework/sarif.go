@@ -0,0 +1,255 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// sarifSchema is the json schema of the SARIF logs written by SARIFWriter
+	sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// sarifVersion is the SARIF version of the logs written by SARIFWriter
+	sarifVersion = "2.1.0"
+
+	// SARIFRuleIDTag is the Result tag containing the id of the rule which
+	// produced the result.  It is written as the SARIF ruleId of the result.
+	SARIFRuleIDTag = "ruleId"
+)
+
+// SARIFWriter writes Results as a SARIF log, the format consumed by GitHub code
+// scanning and many other CI annotators.
+//
+// The File of each Result is written as the location of the result.  If Items
+// contains the resource the result refers to, the line and column of its Field
+// are written as the region of the location.  The line numbers are those of the
+// parsed nodes, so they are only correct if the Items were read from their files,
+// e.g. by a kio.LocalPackageReader.
+type SARIFWriter struct {
+	// Writer is where the SARIF log is written.
+	Writer io.Writer
+
+	// ToolName is the name of the function reported as the tool which produced the results.
+	ToolName string
+
+	// ToolVersion is the version of the function.
+	ToolVersion string
+
+	// InformationURI is a URI with documentation of the function.
+	InformationURI string
+
+	// Items are the resources the results refer to.  Results are matched to an item
+	// by their File, or if they have no File by their ResourceRef.
+	Items []*yaml.RNode
+}
+
+// Write writes results as a SARIF log.
+func (w SARIFWriter) Write(results Results) error {
+	if w.Writer == nil {
+		return errors.Errorf("must specify a Writer for the SARIF log")
+	}
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           w.ToolName,
+			Version:        w.ToolVersion,
+			InformationURI: w.InformationURI,
+		}},
+		Results: []sarifResult{},
+	}
+	rules := map[string]bool{}
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		sr := sarifResult{
+			RuleID:  r.Tags[SARIFRuleIDTag],
+			Level:   sarifLevel(r.Severity),
+			Message: sarifMessage{Text: r.Message},
+		}
+		if sr.RuleID != "" && !rules[sr.RuleID] {
+			rules[sr.RuleID] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: sr.RuleID})
+		}
+		if loc, ok := w.location(r); ok {
+			sr.Locations = []sarifLocation{loc}
+		}
+		run.Results = append(run.Results, sr)
+	}
+	if run.Tool.Driver.Name == "" {
+		run.Tool.Driver.Name = "krm-function"
+	}
+
+	b, err := json.MarshalIndent(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if _, err := w.Writer.Write(append(b, '\n')); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
+
+// location returns the SARIF location of the result, or false if it has none.
+func (w SARIFWriter) location(r *Result) (sarifLocation, bool) {
+	var loc sarifLocation
+	item := w.item(r)
+	path := ""
+	if r.File != nil {
+		path = r.File.Path
+	} else if item != nil {
+		path, _, _ = kioutil.GetFileAnnotations(item)
+	}
+	if path != "" {
+		loc.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: strings.TrimPrefix(path, "./")},
+		}
+		if region := fieldRegion(item, r.Field); region != nil {
+			loc.PhysicalLocation.Region = region
+		}
+	}
+
+	var name []string
+	if r.ResourceRef != nil {
+		for _, s := range []string{r.ResourceRef.APIVersion, r.ResourceRef.Kind,
+			r.ResourceRef.Namespace, r.ResourceRef.Name} {
+			if s != "" {
+				name = append(name, s)
+			}
+		}
+	}
+	if r.Field != nil && r.Field.Path != "" {
+		name = append(name, r.Field.Path)
+	}
+	if len(name) > 0 {
+		loc.LogicalLocations = []sarifLogicalLocation{{
+			FullyQualifiedName: strings.Join(name, "/"),
+			Kind:               "resource",
+		}}
+	}
+	return loc, loc.PhysicalLocation != nil || len(loc.LogicalLocations) > 0
+}
+
+// item returns the item the result refers to, or nil if it can't be found.
+func (w SARIFWriter) item(r *Result) *yaml.RNode {
+	for _, item := range w.Items {
+		if r.File != nil {
+			path, index, _ := kioutil.GetFileAnnotations(item)
+			if path == r.File.Path && (index == "" || index == strconv.Itoa(r.File.Index)) {
+				return item
+			}
+			continue
+		}
+		if ref := r.ResourceRef; ref != nil &&
+			item.GetApiVersion() == ref.APIVersion && item.GetKind() == ref.Kind &&
+			item.GetName() == ref.Name && item.GetNamespace() == ref.Namespace {
+			return item
+		}
+	}
+	return nil
+}
+
+// fieldRegion returns the region of field in item, or nil if it can't be found.
+func fieldRegion(item *yaml.RNode, field *Field) *sarifRegion {
+	if item == nil {
+		return nil
+	}
+	yn := item.YNode()
+	if field != nil && field.Path != "" {
+		nodes, err := item.Query(field.Path)
+		if err != nil || len(nodes) == 0 {
+			return nil
+		}
+		yn = nodes[0].YNode()
+	}
+	if yn.Line == 0 {
+		return nil
+	}
+	return &sarifRegion{StartLine: yn.Line, StartColumn: yn.Column}
+}
+
+// sarifLevel returns the SARIF level of a severity
+func sarifLevel(s Severity) string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifLog is the subset of the SARIF 2.1.0 log format written by SARIFWriter.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind,omitempty"`
+}
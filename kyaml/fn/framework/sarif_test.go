@@ -0,0 +1,144 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestSARIFWriter(t *testing.T) {
+	items, err := (&kio.ByteReader{Reader: strings.NewReader(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/path: deploy.yaml
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:latest
+`)}).Read()
+	require.NoError(t, err)
+
+	results := framework.Results{
+		{
+			Message:  "image tags must not be latest",
+			Severity: framework.Error,
+			ResourceRef: &yaml.ResourceIdentifier{
+				TypeMeta: yaml.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				NameMeta: yaml.NameMeta{Name: "app"},
+			},
+			Field: &framework.Field{Path: "spec.template.spec.containers[0].image"},
+			Tags:  map[string]string{framework.SARIFRuleIDTag: "no-latest"},
+		},
+		{
+			Message:  "replicas should be at least 2",
+			Severity: framework.Warning,
+			File:     &framework.File{Path: "deploy.yaml"},
+			Field:    &framework.Field{Path: "spec.replicas"},
+		},
+		{
+			Message: "checked 1 resource",
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, framework.SARIFWriter{
+		Writer:      &out,
+		ToolName:    "validator",
+		ToolVersion: "v1.0.0",
+		Items:       items,
+	}.Write(results))
+
+	assert.Equal(t, `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "validator",
+          "version": "v1.0.0",
+          "rules": [
+            {
+              "id": "no-latest"
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "no-latest",
+          "level": "error",
+          "message": {
+            "text": "image tags must not be latest"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "deploy.yaml"
+                },
+                "region": {
+                  "startLine": 13,
+                  "startColumn": 16
+                }
+              },
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "apps/v1/Deployment/app/spec.template.spec.containers[0].image",
+                  "kind": "resource"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "level": "warning",
+          "message": {
+            "text": "replicas should be at least 2"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "deploy.yaml"
+                },
+                "region": {
+                  "startLine": 8,
+                  "startColumn": 13
+                }
+              },
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "spec.replicas",
+                  "kind": "resource"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "level": "note",
+          "message": {
+            "text": "checked 1 resource"
+          }
+        }
+      ]
+    }
+  ]
+}
+`, out.String())
+}
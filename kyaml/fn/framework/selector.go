@@ -5,6 +5,7 @@ package framework
 
 import (
 	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -35,6 +36,23 @@ type Selector struct {
 	// e.g. Annotations: {"foo": "bar", "baz": "buz"] matches if BOTH "foo" and "baz" annotations match.
 	Annotations map[string]string `json:"annotations" yaml:"annotations"`
 
+	// NamePatterns is a list of glob patterns of metadata.names to match.  If empty match all names.
+	// e.g. NamePatterns: ["foo-*"] matches if `metadata.name` starts with "foo-".
+	NamePatterns []string `json:"namePatterns,omitempty" yaml:"namePatterns,omitempty"`
+
+	// NamespacePatterns is a list of glob patterns of metadata.namespaces to match.  If empty match
+	// all namespaces.
+	// e.g. NamespacePatterns: ["team-*"] matches if `metadata.namespace` starts with "team-".
+	NamespacePatterns []string `json:"namespacePatterns,omitempty" yaml:"namespacePatterns,omitempty"`
+
+	// LabelSelector is a Kubernetes label selector the labels must match.
+	// e.g. LabelSelector: "app in (foo, bar),!canary"
+	LabelSelector string `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
+
+	// AnnotationSelector is a Kubernetes label selector the annotations must match.
+	// e.g. AnnotationSelector: "config.kubernetes.io/local-config!=true"
+	AnnotationSelector string `json:"annotationSelector,omitempty" yaml:"annotationSelector,omitempty"`
+
 	// ResourceMatcher is an arbitrary function used to match resources.
 	// Selector matches if the function returns true.
 	ResourceMatcher func(*yaml.RNode) bool
@@ -50,7 +68,12 @@ type Selector struct {
 // Filter implements kio.Filter, returning only those items from the list that the selector
 // matches.
 func (s *Selector) Filter(items []*yaml.RNode) ([]*yaml.RNode, error) {
-	andSel := AndSelector{TemplateData: s.TemplateData, FailOnEmptyMatch: s.FailOnEmptyMatch}
+	return s.andSelector().Filter(items)
+}
+
+// andSelector returns an AndSelector with the matchers of the Selector fields.
+func (s *Selector) andSelector() *AndSelector {
+	andSel := &AndSelector{TemplateData: s.TemplateData, FailOnEmptyMatch: s.FailOnEmptyMatch}
 	if s.Names != nil {
 		andSel.Matchers = append(andSel.Matchers, NameMatcher(s.Names...))
 	}
@@ -69,10 +92,127 @@ func (s *Selector) Filter(items []*yaml.RNode) ([]*yaml.RNode, error) {
 	if s.Annotations != nil {
 		andSel.Matchers = append(andSel.Matchers, AnnotationMatcher(s.Annotations))
 	}
+	if s.NamePatterns != nil {
+		andSel.Matchers = append(andSel.Matchers, NamePatternMatcher(s.NamePatterns...))
+	}
+	if s.NamespacePatterns != nil {
+		andSel.Matchers = append(andSel.Matchers, NamespacePatternMatcher(s.NamespacePatterns...))
+	}
+	if s.LabelSelector != "" {
+		andSel.Matchers = append(andSel.Matchers, LabelSelectorMatcher(s.LabelSelector))
+	}
+	if s.AnnotationSelector != "" {
+		andSel.Matchers = append(andSel.Matchers, AnnotationSelectorMatcher(s.AnnotationSelector))
+	}
 	if s.ResourceMatcher != nil {
 		andSel.Matchers = append(andSel.Matchers, ResourceMatcherFunc(s.ResourceMatcher))
 	}
-	return andSel.Filter(items)
+	return andSel
+}
+
+// ItemSelection selects the items a function applies to, following the include/exclude
+// convention for functionConfig.  Functions embed it in their functionConfig type:
+//
+//	type Config struct {
+//		framework.ItemSelection `json:",inline" yaml:",inline"`
+//		...
+//	}
+//
+// so that users select the items in the functionConfig:
+//
+//	include:
+//	- kinds: [Deployment, StatefulSet]
+//	exclude:
+//	- namePatterns: ["*-canary"]
+//
+// An item is selected if it matches at least one of the Include selectors, or Include is empty,
+// and it matches none of the Exclude selectors.
+type ItemSelection struct {
+	// Include is the list of selectors of the items to select.  If empty select all items.
+	Include []Selector `json:"include,omitempty" yaml:"include,omitempty"`
+
+	// Exclude is the list of selectors of the items not to select.
+	Exclude []Selector `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+
+	// TemplateData if present will cause the selector values to be parsed as templates
+	// and rendered using TemplateData before they are used.
+	TemplateData interface{} `json:"-" yaml:"-"`
+}
+
+// Filter implements kio.Filter, returning only those items from the list that are selected.
+func (s *ItemSelection) Filter(items []*yaml.RNode) ([]*yaml.RNode, error) {
+	selected, err := s.selected(items)
+	if err != nil {
+		return nil, err
+	}
+	var result []*yaml.RNode
+	for i := range items {
+		if selected[i] {
+			result = append(result, items[i])
+		}
+	}
+	return result, nil
+}
+
+// Apply runs the filter on the selected items and returns all the items, with the selected
+// items replaced by the items returned by the filter.  Items which are not selected keep
+// their position, and the items returned by the filter take the positions of the selected
+// items, with any additional items appended.
+func (s *ItemSelection) Apply(items []*yaml.RNode, filter kio.Filter) ([]*yaml.RNode, error) {
+	selected, err := s.selected(items)
+	if err != nil {
+		return nil, err
+	}
+	var in []*yaml.RNode
+	for i := range items {
+		if selected[i] {
+			in = append(in, items[i])
+		}
+	}
+	out, err := filter.Filter(in)
+	if err != nil {
+		return nil, err
+	}
+	var result []*yaml.RNode
+	for i := range items {
+		if !selected[i] {
+			result = append(result, items[i])
+		} else if len(out) > 0 {
+			result = append(result, out[0])
+			out = out[1:]
+		}
+	}
+	return append(result, out...), nil
+}
+
+// selected returns whether each of the items is selected.
+func (s *ItemSelection) selected(items []*yaml.RNode) ([]bool, error) {
+	include, err := s.matcher(s.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := s.matcher(s.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]bool, len(items))
+	for i := range items {
+		selected[i] = (len(s.Include) == 0 || include.Match(items[i])) &&
+			(len(s.Exclude) == 0 || !exclude.Match(items[i]))
+	}
+	return selected, nil
+}
+
+// matcher returns an OrSelector matching the items matched by any of the selectors.
+func (s *ItemSelection) matcher(selectors []Selector) (*OrSelector, error) {
+	orSel := &OrSelector{TemplateData: s.TemplateData}
+	for i := range selectors {
+		orSel.Matchers = append(orSel.Matchers, selectors[i].andSelector())
+	}
+	if err := orSel.InitTemplates(); err != nil {
+		return nil, err
+	}
+	return orSel, nil
 }
 
 // MatchAll is a shorthand for building an AndSelector from a list of ResourceMatchers.
@@ -94,6 +94,46 @@ func TestSelector(t *testing.T) {
 			ValueFoo: "foo-l",
 			ValueBar: "bar-l",
 		},
+
+		// Test the name patterns template
+		{
+			Name: "namePatterns",
+			Fn: func(s *framework.Selector) {
+				s.NamePatterns = []string{"{{ .Value }}*"}
+			},
+			ValueFoo: "f",
+			ValueBar: "b",
+		},
+
+		// Test the namespace patterns template
+		{
+			Name: "namespacePatterns",
+			Fn: func(s *framework.Selector) {
+				s.NamespacePatterns = []string{"{{ .Value }}-*"}
+			},
+			ValueFoo: "foo",
+			ValueBar: "bar",
+		},
+
+		// Test the label selector template
+		{
+			Name: "labelSelector",
+			Fn: func(s *framework.Selector) {
+				s.LabelSelector = "key in ({{ .Value }}, other)"
+			},
+			ValueFoo: "foo-l",
+			ValueBar: "bar-l",
+		},
+
+		// Test the annotation selector template
+		{
+			Name: "annotationSelector",
+			Fn: func(s *framework.Selector) {
+				s.AnnotationSelector = "key={{ .Value }}"
+			},
+			ValueFoo: "foo-a",
+			ValueBar: "bar-a",
+		},
 	}
 
 	// input is the input resources that are selected
@@ -195,6 +235,76 @@ metadata:
 	}
 }
 
+func TestSelector_invalidLabelSelector(t *testing.T) {
+	s := &framework.Selector{LabelSelector: "key in (a"}
+	_, err := s.Filter([]*yaml.RNode{yaml.MustParse(`kind: Foo`)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid selector "key in (a"`)
+}
+
+func TestItemSelection(t *testing.T) {
+	input := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app-canary
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  labels:
+    mutate: "true"
+`
+	var config struct {
+		framework.ItemSelection `json:",inline" yaml:",inline"`
+	}
+	require.NoError(t, framework.LoadFunctionConfig(yaml.MustParse(`
+include:
+- kinds: [Deployment]
+- labelSelector: mutate=true
+exclude:
+- namePatterns: ["*-canary"]
+`), &config))
+
+	items, err := kio.FromBytes([]byte(input))
+	require.NoError(t, err)
+	selected, err := config.Filter(items)
+	require.NoError(t, err)
+	var names []string
+	for _, item := range selected {
+		names = append(names, item.GetKind()+"/"+item.GetName())
+	}
+	assert.Equal(t, []string{"Deployment/app", "ConfigMap/app"}, names)
+
+	items, err = config.Apply(items, kio.FilterFunc(func(items []*yaml.RNode) ([]*yaml.RNode, error) {
+		for _, item := range items {
+			if err := item.PipeE(yaml.SetAnnotation("mutated", "true")); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}))
+	require.NoError(t, err)
+	var mutated []string
+	for _, item := range items {
+		mutated = append(mutated, item.GetKind()+"/"+item.GetName()+"="+item.GetAnnotations()["mutated"])
+	}
+	assert.Equal(t, []string{
+		"Deployment/app=true", "Deployment/app-canary=", "Service/app=", "ConfigMap/app=true",
+	}, mutated)
+}
+
 func TestAndOrSelector_Composition(t *testing.T) {
 	// This selector should pick the "prime-target" deployment by name
 	// as well as any resources with the given labels or annotations regardless of kind
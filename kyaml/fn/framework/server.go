@@ -0,0 +1,140 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework
+
+import (
+	"bytes"
+	"encoding/binary"
+	goerrors "errors"
+	"io"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// MaxFrameSize is the largest ResourceList accepted by Serve.
+const MaxFrameSize = 512 << 20
+
+// Serve runs p as a long-running server processing a stream of ResourceLists,
+// so that orchestrators invoking the same function many times don't pay the
+// cost of starting it each time.
+//
+// Serve reads ResourceLists from r and writes the resulting ResourceList for each
+// of them to w, in order.  Each ResourceList is framed as described by ReadFrame
+// and WriteFrame, and may be yaml or json.  Errors returned by p are written as
+// error Results of the response rather than stopping the server.  Errors reading
+// or writing the stream stop the server and are returned.
+//
+// Serve returns nil once r is exhausted.
+func Serve(p ResourceListProcessor, r io.Reader, w io.Writer) error {
+	for {
+		in, err := ReadFrame(r)
+		if goerrors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		out, err := ProcessResourceList(p, in)
+		if out == nil {
+			// the request couldn't be read, respond with the error
+			var b bytes.Buffer
+			if err := writeErrorResourceList(&b, err); err != nil {
+				return err
+			}
+			out = b.Bytes()
+		}
+		if err := WriteFrame(w, out); err != nil {
+			return err
+		}
+	}
+}
+
+// AddressPrefix starts the line a function run as a long-running server
+// writes to STDOUT once it listens for ResourceLists, followed by the address
+// it listens on, e.g.
+//
+//	krm-function-address: /tmp/kustomize-fn-server-1234/fn.sock
+//
+// The fngrpc module serves the functions this way.
+const AddressPrefix = "krm-function-address: "
+
+// ProcessResourceList processes the ResourceList in, yaml or json, with p
+// like Execute, and returns the resulting ResourceList.  If p fails,
+// ProcessResourceList returns the resulting ResourceList with the error of
+// p as its results, and the error of p.  If the ResourceList can't be read
+// or written, it returns no ResourceList and the error.
+func ProcessResourceList(p ResourceListProcessor, in []byte) ([]byte, error) {
+	var out bytes.Buffer
+	rw := &kio.ByteReadWriter{
+		Reader:                bytes.NewReader(in),
+		Writer:                &out,
+		KeepReaderAnnotations: true,
+	}
+	// the error of p is added to the results too
+	var processErr error
+	processor := ResourceListProcessorFunc(func(rl *ResourceList) error {
+		processErr = p.Process(rl)
+		return processErr
+	})
+	if err := execute(processor, rw, true); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), processErr
+}
+
+// writeErrorResourceList writes an empty ResourceList with err as its result.
+func writeErrorResourceList(w io.Writer, resultErr error) error {
+	b, err := yaml.Marshal(Results{{Message: resultErr.Error(), Severity: Error}})
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	results, err := yaml.Parse(string(b))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	return kio.ByteWriter{
+		Writer:             w,
+		WrappingKind:       kio.ResourceListKind,
+		WrappingAPIVersion: kio.ResourceListAPIVersion,
+		Results:            results,
+	}.Write(nil)
+}
+
+// ReadFrame reads a single frame written by WriteFrame from r.
+// A frame is the length of its payload as a 4 byte big-endian unsigned
+// integer, followed by the payload.
+// ReadFrame returns io.EOF if r is exhausted before the start of the frame.
+func ReadFrame(r io.Reader) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		if goerrors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, errors.WrapPrefixf(err, "reading frame size")
+	}
+	if size > MaxFrameSize {
+		return nil, errors.Errorf("frame size %d exceeds the maximum of %d", size, MaxFrameSize)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errors.WrapPrefixf(err, "reading frame")
+	}
+	return b, nil
+}
+
+// WriteFrame writes b to w as a single frame.
+func WriteFrame(w io.Writer, b []byte) error {
+	if len(b) > MaxFrameSize {
+		return errors.Errorf("frame size %d exceeds the maximum of %d", len(b), MaxFrameSize)
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(len(b))); err != nil {
+		return errors.WrapPrefixf(err, "writing frame size")
+	}
+	if _, err := w.Write(b); err != nil {
+		return errors.WrapPrefixf(err, "writing frame")
+	}
+	return nil
+}
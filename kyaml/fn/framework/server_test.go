@@ -0,0 +1,114 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package framework_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestServe(t *testing.T) {
+	calls := 0
+	p := framework.SimpleProcessor{Filter: kio.FilterFunc(func(in []*yaml.RNode) ([]*yaml.RNode, error) {
+		calls++
+		for _, item := range in {
+			if item.GetName() == "bad" {
+				return nil, errors.Errorf("bad resource")
+			}
+			if err := item.PipeE(yaml.SetLabel("served", "true")); err != nil {
+				return nil, err
+			}
+		}
+		return in, nil
+	})}
+
+	var in bytes.Buffer
+	for _, rl := range []string{`
+apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+`, `{"apiVersion": "config.kubernetes.io/v1", "kind": "ResourceList",
+"items": [{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "bad"}}]}`, `
+apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: b
+`} {
+		require.NoError(t, framework.WriteFrame(&in, []byte(rl)))
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, framework.Serve(p, &in, &out))
+	assert.Equal(t, 3, calls)
+
+	var responses []string
+	for {
+		b, err := framework.ReadFrame(&out)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		responses = append(responses, string(b))
+	}
+	require.Len(t, responses, 3)
+	assert.Equal(t, `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+    labels:
+      served: 'true'
+`, responses[0])
+	assert.Contains(t, responses[1], `results:
+- message: 'processing filter: bad resource'
+  severity: error
+`)
+	assert.Contains(t, responses[2], "name: b\n    labels:\n      served: 'true'\n")
+}
+
+func TestServe_truncated(t *testing.T) {
+	var in bytes.Buffer
+	require.NoError(t, framework.WriteFrame(&in, []byte("kind: ResourceList\nitems: []\n")))
+	in.Truncate(in.Len() - 3)
+	err := framework.Serve(framework.SimpleProcessor{}, &in, io.Discard)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading frame")
+}
+
+func TestProcessResourceList(t *testing.T) {
+	p := framework.SimpleProcessor{Filter: kio.FilterFunc(func(in []*yaml.RNode) ([]*yaml.RNode, error) {
+		return nil, errors.Errorf("bad resource")
+	})}
+
+	// the error of the processor comes with the results
+	out, err := framework.ProcessResourceList(p, []byte("kind: ResourceList\nitems: []\n"))
+	assert.EqualError(t, err, "processing filter: bad resource")
+	assert.Contains(t, string(out), `results:
+- message: 'processing filter: bad resource'
+  severity: error
+`)
+
+	// unlike those of the ResourceList
+	out, err = framework.ProcessResourceList(p, []byte("items: {"))
+	assert.Nil(t, out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read ResourceList input")
+}
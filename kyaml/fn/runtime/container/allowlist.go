@@ -0,0 +1,288 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// hostAllowlist is a list of host patterns: a host name or IP address, or
+// *. followed by a domain to match its subdomains, optionally followed by
+// :port to match only that port.
+type hostAllowlist []string
+
+// validate returns an error if a pattern of l is malformed.
+func (l hostAllowlist) validate() error {
+	for _, pattern := range l {
+		host, port := splitHostPort(pattern)
+		host = strings.TrimPrefix(host, "*.")
+		_, err := strconv.ParseUint(port, 10, 16)
+		if host == "" || strings.ContainsAny(host, "/*@ ") || (port != "" && err != nil) ||
+			(strings.Contains(host, ":") && net.ParseIP(host) == nil) {
+			return errors.Errorf("invalid allowed host %q", pattern)
+		}
+	}
+	return nil
+}
+
+// allows returns true if hostport, as host:port, matches a pattern of l.
+func (l hostAllowlist) allows(hostport string) bool {
+	host, port := splitHostPort(hostport)
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range l {
+		h, p := splitHostPort(pattern)
+		if p != "" && p != port {
+			continue
+		}
+		h = strings.ToLower(h)
+		if domain := strings.TrimPrefix(h, "*"); domain != h {
+			if strings.HasSuffix(host, domain) && len(host) > len(domain) {
+				return true
+			}
+		} else if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// splitHostPort splits hostport into its host and port, which is empty if
+// hostport has none.
+func splitHostPort(hostport string) (host, port string) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return strings.Trim(hostport, "[]"), ""
+	}
+	return host, port
+}
+
+// allowlistProxy is an HTTP proxy forwarding only the requests to the hosts
+// of its allowlist, tunneling CONNECT requests for HTTPS.
+type allowlistProxy struct {
+	allowed   hostAllowlist
+	transport http.RoundTripper
+	server    *http.Server
+	listener  net.Listener
+	wg        sync.WaitGroup
+
+	mu      sync.Mutex
+	tunnels map[net.Conn]bool
+}
+
+// startAllowlistProxy starts a proxy listening at the address addr, which
+// forwards only the requests to the hosts of allowed.
+func startAllowlistProxy(addr string, allowed hostAllowlist) (*allowlistProxy, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "starting the proxy of the allowed hosts")
+	}
+	p := &allowlistProxy{
+		allowed:  allowed,
+		listener: l,
+		tunnels:  map[net.Conn]bool{},
+		// the proxy doesn't honor the proxy environment of the runner
+		transport: &http.Transport{Proxy: nil, DialContext: (&net.Dialer{Timeout: 30 * time.Second}).DialContext},
+	}
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: 30 * time.Second}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		_ = p.server.Serve(l)
+	}()
+	return p, nil
+}
+
+// URL returns the URL of the proxy, for the HTTP_PROXY environment variables.
+func (p *allowlistProxy) URL() string {
+	return "http://" + p.listener.Addr().String()
+}
+
+// Close stops the proxy, closing its connections.
+func (p *allowlistProxy) Close() error {
+	err := p.server.Close()
+	// the server doesn't track the hijacked connections of the tunnels
+	p.mu.Lock()
+	for conn := range p.tunnels {
+		conn.Close()
+	}
+	p.mu.Unlock()
+	p.wg.Wait()
+	return err
+}
+
+func (p *allowlistProxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	hostport := req.Host
+	if req.Method != http.MethodConnect {
+		if req.URL.Host == "" {
+			http.Error(w, "not a proxy request", http.StatusBadRequest)
+			return
+		}
+		hostport = req.URL.Host
+		if _, port := splitHostPort(hostport); port == "" {
+			hostport = net.JoinHostPort(req.URL.Hostname(), "80")
+		}
+	}
+	if !p.allowed.allows(hostport) {
+		http.Error(w, fmt.Sprintf("host %s is not in the allowed hosts of the function", hostport),
+			http.StatusForbidden)
+		return
+	}
+	if req.Method == http.MethodConnect {
+		p.tunnel(w, hostport)
+		return
+	}
+	p.forward(w, req)
+}
+
+// forward forwards the plain HTTP request req.
+func (p *allowlistProxy) forward(w http.ResponseWriter, req *http.Request) {
+	out := req.Clone(req.Context())
+	out.RequestURI = ""
+	out.Header.Del("Proxy-Connection")
+	out.Header.Del("Proxy-Authorization")
+	resp, err := p.transport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// tunnel connects the client to hostport, copying the data both ways.
+func (p *allowlistProxy) tunnel(w http.ResponseWriter, hostport string) {
+	upstream, err := net.DialTimeout("tcp", hostport, 30*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "tunneling not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buf, err := hj.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	if _, err := client.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		upstream.Close()
+		client.Close()
+		return
+	}
+	p.mu.Lock()
+	p.tunnels[client], p.tunnels[upstream] = true, true
+	p.mu.Unlock()
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() {
+			p.mu.Lock()
+			delete(p.tunnels, client)
+			delete(p.tunnels, upstream)
+			p.mu.Unlock()
+		}()
+		defer upstream.Close()
+		defer client.Close()
+		done := make(chan struct{})
+		go func() {
+			// data buffered by the server before the hijack comes first
+			_, _ = io.Copy(upstream, io.MultiReader(buf.Reader, client))
+			close(done)
+		}()
+		_, _ = io.Copy(client, upstream)
+		client.Close()
+		<-done
+	}()
+}
+
+// allowlistNetwork is an internal network of the container runtime, from
+// which functions reach the allowed hosts through an allowlistProxy on the
+// gateway of the network.
+type allowlistNetwork struct {
+	runtime string
+	name    string
+	proxy   *allowlistProxy
+}
+
+// runtimeOutput runs the container runtime with args, returning its
+// output. It is replaced by tests.
+var runtimeOutput = func(runtime string, args ...string) (string, error) {
+	out, err := exec.Command(runtime, args...).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok { //nolint:errorlint
+			return "", errors.Errorf("%s %s: %v: %s", runtime, strings.Join(args, " "),
+				err, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return "", errors.WrapPrefixf(err, "%s %s", runtime, strings.Join(args, " "))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// startAllowlistNetwork creates an internal network with runtime, without
+// access to the outside, and starts a proxy to the allowed hosts on its
+// gateway.
+func startAllowlistNetwork(runtime string, allowed hostAllowlist) (*allowlistNetwork, error) {
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	n := &allowlistNetwork{runtime: runtime, name: "kustomize-fn-" + hex.EncodeToString(suffix)}
+	if _, err := runtimeOutput(runtime, "network", "create", "--internal", n.name); err != nil {
+		return nil, err
+	}
+	format := "{{range .IPAM.Config}}{{.Gateway}}{{end}}"
+	if runtime == RuntimePodman {
+		format = "{{range .Subnets}}{{.Gateway}}{{end}}"
+	}
+	gateway, err := runtimeOutput(runtime, "network", "inspect", "--format", format, n.name)
+	if err == nil && net.ParseIP(gateway) == nil {
+		err = errors.Errorf("network %s has no gateway to run the proxy of the allowed hosts on", n.name)
+	}
+	if err == nil {
+		n.proxy, err = startAllowlistProxy(net.JoinHostPort(gateway, "0"), allowed)
+	}
+	if err != nil {
+		_ = n.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+// Env returns the environment variables directing the HTTP clients of the
+// function to the proxy.
+func (n *allowlistNetwork) Env() []string {
+	var env []string
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		env = append(env, name+"="+n.proxy.URL())
+	}
+	return append(env, "NO_PROXY=", "no_proxy=")
+}
+
+// Close stops the proxy and removes the network.
+func (n *allowlistNetwork) Close() error {
+	if n.proxy != nil {
+		_ = n.proxy.Close()
+	}
+	_, err := runtimeOutput(n.runtime, "network", "rm", n.name)
+	return err
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+func TestHostAllowlist(t *testing.T) {
+	l := hostAllowlist{"api.example.com", "*.googleapis.com", "registry.example.com:443", "10.0.0.1"}
+	require.NoError(t, l.validate())
+	for hostport, expected := range map[string]bool{
+		"api.example.com:443":          true,
+		"API.example.com:80":           true,
+		"other.example.com:443":        false,
+		"storage.googleapis.com:443":   true,
+		"a.b.googleapis.com:443":       true,
+		"googleapis.com:443":           false,
+		"evilgoogleapis.com:443":       false,
+		"registry.example.com:443":     true,
+		"registry.example.com:80":      false,
+		"10.0.0.1:8080":                true,
+		"api.example.com.evil.com:443": false,
+	} {
+		assert.Equal(t, expected, l.allows(hostport), hostport)
+	}
+
+	for _, pattern := range []string{"*", "", "https://api.example.com", "*.*.example.com", "api.example.com:443:1"} {
+		assert.Error(t, hostAllowlist{pattern}.validate(), pattern)
+	}
+}
+
+func TestAllowlistProxy(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("plain"))
+	}))
+	defer plain.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("secure"))
+	}))
+	defer secure.Close()
+	plainURL, _ := url.Parse(plain.URL)
+	secureURL, _ := url.Parse(secure.URL)
+
+	get := func(p *allowlistProxy, target string) (int, string) {
+		t.Helper()
+		proxyURL, _ := url.Parse(p.URL())
+		client := &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		}}
+		resp, err := client.Get(target)
+		if err != nil {
+			// the CONNECT response of a denied tunnel is an error
+			return http.StatusForbidden, err.Error()
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	p, err := startAllowlistProxy("127.0.0.1:0", hostAllowlist{plainURL.Host, secureURL.Host})
+	require.NoError(t, err)
+	status, body := get(p, plain.URL)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "plain", body)
+	status, body = get(p, secure.URL)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "secure", body)
+	require.NoError(t, p.Close())
+
+	p, err = startAllowlistProxy("127.0.0.1:0", hostAllowlist{"api.example.com"})
+	require.NoError(t, err)
+	defer p.Close()
+	status, body = get(p, plain.URL)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "is not in the allowed hosts of the function")
+	status, body = get(p, secure.URL)
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Contains(t, body, "Forbidden")
+}
+
+func TestFilter_allowedHosts(t *testing.T) {
+	setLookPath(t, RuntimeDocker)
+	var calls []string
+	oldRuntimeOutput := runtimeOutput
+	runtimeOutput = func(runtime string, args ...string) (string, error) {
+		calls = append(calls, runtime+" "+strings.Join(args, " "))
+		if args[1] == "inspect" {
+			return "127.0.0.1", nil
+		}
+		return "", nil
+	}
+	t.Cleanup(func() { runtimeOutput = oldRuntimeOutput })
+
+	instance := NewContainer(runtimeutil.ContainerSpec{
+		Image:        "example.com:version",
+		Network:      true,
+		AllowedHosts: []string{"api.example.com"},
+	}, "nobody")
+	require.NoError(t, instance.setupExec())
+	// the function has no network until the network of the allowed hosts is set up
+	assert.Equal(t, []string{"--network", "none"}, instance.Exec.Args[9:11])
+
+	n, err := startAllowlistNetwork(instance.Runtime, hostAllowlist(instance.AllowedHosts))
+	require.NoError(t, err)
+	_, args := instance.getCommand(n)
+	assert.Equal(t, []string{"--network", n.name}, args[9:11])
+	assert.Contains(t, strings.Join(args, " "), "-e HTTPS_PROXY="+n.proxy.URL())
+	assert.Equal(t, "example.com:version", args[len(args)-1])
+	require.NoError(t, n.Close())
+
+	assert.Equal(t, []string{
+		"docker network create --internal " + n.name,
+		"docker network inspect --format {{range .IPAM.Config}}{{.Gateway}}{{end}} " + n.name,
+		"docker network rm " + n.name,
+	}, calls)
+
+	instance = NewContainer(runtimeutil.ContainerSpec{
+		Image:        "example.com:version",
+		AllowedHosts: []string{"api.example.com"},
+	}, "nobody")
+	assert.EqualError(t, instance.setupExec(), "allowedHosts of function example.com:version requires network")
+}
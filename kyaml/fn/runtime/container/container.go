@@ -5,8 +5,13 @@ package container
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	runtimeexec "sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
@@ -128,6 +133,57 @@ type Filter struct {
 	Exec runtimeexec.Filter
 
 	UIDGID string
+
+	// Runtime is the container runtime CLI used to run the container, one of
+	// Runtimes. If empty, the first runtime of Runtimes found on the PATH is used.
+	Runtime string
+
+	// Pool, if set, keeps the function running in its container to send it
+	// the next runs of the function, rather than running a new container.
+	// The function must support ServeArg.
+	Pool *Pool
+
+	// ImageResolver resolves the version constraint of the image, if its
+	// tag is one.  A resolver shared by the Filters is used if nil.
+	ImageResolver *ImageResolver
+
+	// OS is the OS of the container, OSLinux or OSWindows.  If empty, it's
+	// detected with DetectOS.  The mounts of Windows containers are
+	// translated to the C: drive, e.g. /charts to C:\charts, and they're
+	// run as ContainerUser rather than nobody.
+	OS string
+
+	// Isolation is the isolation of Windows containers, one of Isolations,
+	// ignored for Linux containers.  If empty, the default isolation of the
+	// runtime is used.
+	Isolation string
+}
+
+const (
+	RuntimeDocker  = "docker"
+	RuntimePodman  = "podman"
+	RuntimeNerdctl = "nerdctl"
+)
+
+// Runtimes are the supported container runtimes, in the order of preference
+// of autodetection.
+var Runtimes = []string{RuntimeDocker, RuntimePodman, RuntimeNerdctl}
+
+// lookPath and geteuid are replaced by tests.
+var (
+	lookPath = exec.LookPath
+	geteuid  = os.Geteuid
+)
+
+// DetectRuntime returns the first runtime of Runtimes found on the PATH,
+// or docker if none is found.
+func DetectRuntime() string {
+	for _, runtime := range Runtimes {
+		if _, err := lookPath(runtime); err == nil {
+			return runtime
+		}
+	}
+	return RuntimeDocker
 }
 
 func (c Filter) String() string {
@@ -140,10 +196,36 @@ func (c Filter) GetExit() error {
 	return c.Exec.GetExit()
 }
 
+func (c Filter) GetResults() *yaml.RNode {
+	return c.Exec.GetResults()
+}
+
 func (c *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	if err := c.setupExec(); err != nil {
 		return nil, err
 	}
+	if c.Exec.Cache != nil {
+		// functions whose image isn't pinned aren't cached
+		if key := c.cacheKey(); key != "" {
+			c.Exec.CacheKey = key
+		} else {
+			c.Exec.Cache = nil
+		}
+	}
+	if len(c.AllowedHosts) == 0 && c.Pool != nil && c.Runtime != RuntimeNerdctl {
+		c.Exec.FunctionFilter.Run = c.runInPool
+		return c.Exec.FunctionFilter.Filter(nodes)
+	}
+	if len(c.AllowedHosts) == 0 {
+		return c.Exec.Filter(nodes)
+	}
+	// the network and the proxy of the allowed hosts only live for the run
+	n, err := startAllowlistNetwork(c.Runtime, c.AllowedHosts)
+	if err != nil {
+		return nil, err
+	}
+	defer n.Close()
+	c.Exec.Path, c.Exec.Args = c.getCommand(n)
 	return c.Exec.Filter(nodes)
 }
 
@@ -161,43 +243,265 @@ func (c *Filter) setupExec() error {
 		c.Exec.WorkingDir = wd
 	}
 
-	path, args := c.getCommand()
+	if c.Runtime == "" {
+		c.Runtime = DetectRuntime()
+	}
+	if !isSupportedRuntime(c.Runtime) {
+		return errors.Errorf("unsupported container runtime %q, must be one of %v", c.Runtime, Runtimes)
+	}
+	if c.OS == "" {
+		osType, err := DetectOS(c.Runtime)
+		if err != nil {
+			return err
+		}
+		c.OS = osType
+	}
+	if err := c.validatePlatform(); err != nil {
+		return err
+	}
+	if _, ok := pullFlags[c.ImagePullPolicy]; !ok {
+		return errors.Errorf("unsupported image pull policy %q, must be one of %v",
+			c.ImagePullPolicy, runtimeutil.ImagePullPolicies)
+	}
+	resolver := c.ImageResolver
+	if resolver == nil {
+		resolver = defaultImageResolver
+	}
+	image, err := resolver.Resolve(c.Image)
+	if err != nil {
+		return err
+	}
+	c.Image = image
+	if err := validateImageDigest(c.Image); err != nil {
+		return err
+	}
+	if err := c.Limits.Validate(); err != nil {
+		return errors.WrapPrefixf(err, "function %s", c.Image)
+	}
+	// the runtime limits the CPUs and memory of the container, and the
+	// client of the runtime is killed once the timeout is reached
+	timeout, _ := c.Limits.TimeoutDuration()
+	c.Exec.Timeout = timeout
+	if len(c.AllowedHosts) > 0 {
+		if !c.ContainerSpec.Network {
+			return errors.Errorf("allowedHosts of function %s requires network", c.Image)
+		}
+		if err := hostAllowlist(c.AllowedHosts).validate(); err != nil {
+			return err
+		}
+	}
+
+	path, args := c.getCommand(nil)
 	c.Exec.Path = path
 	c.Exec.Args = args
 	return nil
 }
 
-// getArgs returns the command + args to run to spawn the container
-func (c *Filter) getCommand() (string, []string) {
-	network := runtimeutil.NetworkNameNone
-	if c.ContainerSpec.Network {
-		network = runtimeutil.NetworkNameHost
+// pullFlags are the values of the --pull flag of the runtimes for the
+// image pull policies.
+var pullFlags = map[string]string{
+	"":                           "",
+	runtimeutil.PullAlways:       "always",
+	runtimeutil.PullIfNotPresent: "missing",
+	runtimeutil.PullNever:        "never",
+}
+
+// digestPattern matches the digests of images pinned with name@digest.
+var digestPattern = regexp.MustCompile(`^(sha256:[a-f0-9]{64}|sha512:[a-f0-9]{128})$`)
+
+// validateImageDigest returns an error if image is pinned to a malformed
+// digest, rather than letting the runtime fail with a less helpful message.
+func validateImageDigest(image string) error {
+	if _, digest, ok := strings.Cut(image, "@"); ok && !digestPattern.MatchString(digest) {
+		return errors.Errorf("invalid digest %q of image %q, must be sha256: or sha512: followed by the hex-encoded digest",
+			digest, image)
 	}
-	// run the container using docker.  this is simpler than using the docker
-	// libraries, and ensures things like auth work the same as if the container
-	// was run from the cli.
-	args := []string{"run",
-		"--rm",                                              // delete the container afterward
-		"-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR", // attach stdin, stdout, stderr
-		"--network", string(network),
+	return nil
+}
 
-		// added security options
-		"--user", c.UIDGID,
-		"--security-opt=no-new-privileges", // don't allow the user to escalate privileges
-		// note: don't make fs readonly because things like heredoc rely on writing tmp files
+func isSupportedRuntime(runtime string) bool {
+	for _, r := range Runtimes {
+		if r == runtime {
+			return true
+		}
 	}
+	return false
+}
 
+// cacheKey identifies the function in the output cache, by the digest of
+// its image, its mounts, its network and its environment.  It's empty if
+// the image isn't pinned by its digest, since the image of a tag can change.
+func (c *Filter) cacheKey() string {
+	if _, _, ok := strings.Cut(c.Image, "@"); !ok {
+		return ""
+	}
+	key := []string{"container", c.Image, c.UIDGID,
+		strconv.FormatBool(c.ContainerSpec.Network), strings.Join(c.AllowedHosts, ",")}
 	for _, storageMount := range c.StorageMounts {
-		// convert declarative relative paths to absolute (otherwise docker will throw an error)
 		if !filepath.IsAbs(storageMount.Src) {
 			storageMount.Src = filepath.Join(c.Exec.WorkingDir, storageMount.Src)
 		}
+		key = append(key, storageMount.String())
+	}
+	for _, e := range c.Env {
+		// variables without a value are exported from the host
+		if !strings.Contains(e, "=") {
+			e += "=" + os.Getenv(e)
+		}
+		key = append(key, e)
+	}
+	return strings.Join(key, "\x00")
+}
+
+// getArgs returns the command + args to run to spawn the container,
+// attached to the network n of the allowed hosts if not nil
+func (c *Filter) getCommand(n *allowlistNetwork) (string, []string) {
+	// run the container using the runtime cli.  this is simpler than using the
+	// runtime libraries, and ensures things like auth work the same as if the
+	// container was run from the cli.
+	args := []string{"run",
+		"--rm", // delete the container afterward
+		"-i",   // attach stdin
+	}
+	return c.Runtime, append(args, c.getContainerArgs(n)...)
+}
+
+// getCreateArgs returns the args to create the container of a Pool, which
+// the runs of the function are sent to.  Its root filesystem is read-only,
+// so that the runs don't share files other than those of /tmp.
+func (c *Filter) getCreateArgs() []string {
+	args := []string{"-i"}
+	if c.OS != OSWindows {
+		// Windows containers don't support read-only root filesystems
+		args = append(args, "--read-only", "--tmpfs", "/tmp")
+	}
+	return append(args, c.getContainerArgs(nil)...)
+}
+
+// runInPool sends the ResourceList of reader to the function running in the
+// container of the Pool, and writes the resulting ResourceList to writer.
+// The run fails if the results of the function contain an error, like the
+// exit code of a function run in its own container.
+func (c *Filter) runInPool(reader io.Reader, writer io.Writer) error {
+	in, err := io.ReadAll(reader)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	var stderr io.Writer = os.Stderr
+	if c.Exec.Log != nil {
+		stderr = runtimeutil.NewLogWriter(c.Exec.Log)
+	}
+	pc, release, err := c.Pool.acquire(c.Runtime, c.getCreateArgs(), stderr)
+	if err != nil {
+		return errors.WrapPrefixf(err, "function %s", c.Image)
+	}
+	defer release()
+	out, err := pc.process(in, c.Exec.Timeout)
+	if err != nil {
+		// the function may be stuck, or have exited
+		_ = pc.remove()
+		return errors.WrapPrefixf(err, "function %s", c.Image)
+	}
+	if _, err := writer.Write(out); err != nil {
+		return errors.Wrap(err)
+	}
+	return resultsError(out)
+}
+
+// resultsError returns an error with the messages of the error results of
+// the ResourceList b, if any.
+func resultsError(b []byte) error {
+	rl, err := yaml.Parse(string(b))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	results, err := rl.Pipe(yaml.Lookup("results"))
+	if err != nil || results == nil {
+		return err
+	}
+	elements, err := results.Elements()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	var messages []string
+	for _, result := range elements {
+		if severity, _ := result.GetString("severity"); severity == "error" {
+			message, _ := result.GetString("message")
+			messages = append(messages, message)
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return errors.Errorf("function failed: %s", strings.Join(messages, "; "))
+}
+
+// getContainerArgs returns the options and image of the container, for
+// both running and creating it.
+func (c *Filter) getContainerArgs(n *allowlistNetwork) []string {
+	network := runtimeutil.NetworkNameNone
+	switch {
+	case n != nil:
+		network = runtimeutil.ContainerNetworkName(n.name)
+	case c.ContainerSpec.Network && len(c.AllowedHosts) == 0:
+		network = runtimeutil.NetworkNameHost
+	}
+	var args []string
+	if c.Runtime != RuntimeNerdctl {
+		// nerdctl attaches stdout and stderr of containers run in the foreground
+		args = append(args, "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR")
+	}
+	args = append(args, "--network", string(network))
+	if c.OS == OSWindows {
+		// Windows images have no nobody user, and Windows containers don't
+		// support security options
+		user := c.UIDGID
+		if user == "nobody" {
+			user = windowsUser
+		}
+		args = append(args, "--user", user)
+		if c.Isolation != "" {
+			args = append(args, "--isolation", c.Isolation)
+		}
+	} else {
+		args = append(args,
+			// added security options
+			"--user", c.UIDGID,
+			"--security-opt=no-new-privileges", // don't allow the user to escalate privileges
+			// note: don't make fs readonly because things like heredoc rely on writing tmp files
+		)
+	}
+	if pull := pullFlags[c.ImagePullPolicy]; pull != "" {
+		args = append(args, "--pull", pull)
+	}
+	if c.Limits.CPU != "" {
+		args = append(args, "--cpus", c.Limits.CPU)
+	}
+	if c.Limits.Memory != "" {
+		args = append(args, "--memory", c.Limits.Memory)
+	}
+	if c.Runtime == RuntimePodman && geteuid() != 0 {
+		// rootless podman: map the user to itself, so that the container can
+		// access the files of mounts owned by the user
+		args = append(args, "--userns=keep-id")
+	}
+
+	for _, storageMount := range c.StorageMounts {
+		// convert declarative relative paths to absolute (otherwise the runtime will throw an error)
+		if !filepath.IsAbs(storageMount.Src) {
+			storageMount.Src = filepath.Join(c.Exec.WorkingDir, filepath.FromSlash(storageMount.Src))
+		}
+		storageMount.DstPath = c.containerPath(storageMount.DstPath)
 		args = append(args, "--mount", storageMount.String())
 	}
 
 	args = append(args, runtimeutil.NewContainerEnvFromStringSlice(c.Env).GetDockerFlags()...)
-	a := append(args, c.Image) //nolint:gocritic
-	return "docker", a
+	if n != nil {
+		for _, e := range n.Env() {
+			args = append(args, "-e", e)
+		}
+	}
+	return append(args, c.Image)
 }
 
 // NewContainer returns a new container filter
@@ -7,8 +7,11 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,6 +21,7 @@ import (
 )
 
 func TestFilter_setupExec(t *testing.T) {
+	setLookPath(t, RuntimeDocker)
 	var tests = []struct {
 		name           string
 		functionConfig string
@@ -148,6 +152,180 @@ metadata:
 	}
 }
 
+func TestFilter_setupExecRuntimes(t *testing.T) {
+	var tests = []struct {
+		name         string
+		runtime      string
+		installed    []string
+		euid         int
+		expectedPath string
+		expectedArgs []string
+		expectedErr  string
+	}{
+		{
+			name:         "autodetect docker",
+			installed:    []string{RuntimeNerdctl, RuntimePodman, RuntimeDocker},
+			expectedPath: "docker",
+			expectedArgs: []string{"run", "--rm", "-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "nobody", "--security-opt=no-new-privileges"},
+		},
+		{
+			name:         "autodetect podman",
+			installed:    []string{RuntimeNerdctl, RuntimePodman},
+			expectedPath: "podman",
+			expectedArgs: []string{"run", "--rm", "-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "nobody", "--security-opt=no-new-privileges"},
+		},
+		{
+			name:         "autodetect nothing installed",
+			expectedPath: "docker",
+			expectedArgs: []string{"run", "--rm", "-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "nobody", "--security-opt=no-new-privileges"},
+		},
+		{
+			name:         "rootless podman",
+			runtime:      RuntimePodman,
+			euid:         1000,
+			expectedPath: "podman",
+			expectedArgs: []string{"run", "--rm", "-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "nobody", "--security-opt=no-new-privileges",
+				"--userns=keep-id"},
+		},
+		{
+			name:         "nerdctl",
+			runtime:      RuntimeNerdctl,
+			installed:    []string{RuntimeDocker},
+			expectedPath: "nerdctl",
+			expectedArgs: []string{"run", "--rm", "-i",
+				"--network", "none", "--user", "nobody", "--security-opt=no-new-privileges"},
+		},
+		{
+			name:        "unsupported",
+			runtime:     "rkt",
+			expectedErr: `unsupported container runtime "rkt"`,
+		},
+	}
+
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			setLookPath(t, tt.installed...)
+			oldGeteuid := geteuid
+			geteuid = func() int { return tt.euid }
+			t.Cleanup(func() { geteuid = oldGeteuid })
+
+			instance := NewContainer(runtimeutil.ContainerSpec{Image: "example.com:version"}, "nobody")
+			instance.Runtime = tt.runtime
+			err := instance.setupExec()
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPath, instance.Exec.Path)
+			expectedArgs := append(tt.expectedArgs,
+				runtimeutil.NewContainerEnvFromStringSlice(instance.Env).GetDockerFlags()...)
+			expectedArgs = append(expectedArgs, instance.Image)
+			assert.Equal(t, expectedArgs, instance.Exec.Args)
+		})
+	}
+}
+
+func TestFilter_setupExecPullPolicy(t *testing.T) {
+	setLookPath(t, RuntimeDocker)
+	digest := "sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3"
+	var tests = []struct {
+		name         string
+		spec         runtimeutil.ContainerSpec
+		expectedPull []string
+		expectedErr  string
+	}{
+		{
+			name: "runtime default",
+			spec: runtimeutil.ContainerSpec{Image: "example.com:version"},
+		},
+		{
+			name:         "always",
+			spec:         runtimeutil.ContainerSpec{Image: "example.com:version", ImagePullPolicy: runtimeutil.PullAlways},
+			expectedPull: []string{"--pull", "always"},
+		},
+		{
+			name:         "pinned if not present",
+			spec:         runtimeutil.ContainerSpec{Image: "example.com@" + digest, ImagePullPolicy: runtimeutil.PullIfNotPresent},
+			expectedPull: []string{"--pull", "missing"},
+		},
+		{
+			name:         "pinned tag never",
+			spec:         runtimeutil.ContainerSpec{Image: "example.com:version@" + digest, ImagePullPolicy: runtimeutil.PullNever},
+			expectedPull: []string{"--pull", "never"},
+		},
+		{
+			name:        "unsupported policy",
+			spec:        runtimeutil.ContainerSpec{Image: "example.com:version", ImagePullPolicy: "Sometimes"},
+			expectedErr: `unsupported image pull policy "Sometimes"`,
+		},
+		{
+			name:        "invalid digest",
+			spec:        runtimeutil.ContainerSpec{Image: "example.com@sha256:24a0c4"},
+			expectedErr: `invalid digest "sha256:24a0c4" of image "example.com@sha256:24a0c4"`,
+		},
+	}
+
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			instance := NewContainer(tt.spec, "nobody")
+			err := instance.setupExec()
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			expectedArgs := append([]string{"run", "--rm", "-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+				"--network", "none", "--user", "nobody", "--security-opt=no-new-privileges"},
+				tt.expectedPull...)
+			expectedArgs = append(expectedArgs,
+				runtimeutil.NewContainerEnvFromStringSlice(instance.Env).GetDockerFlags()...)
+			expectedArgs = append(expectedArgs, tt.spec.Image)
+			assert.Equal(t, expectedArgs, instance.Exec.Args)
+		})
+	}
+}
+
+func TestFilter_setupExecLimits(t *testing.T) {
+	setLookPath(t, RuntimeDocker)
+	instance := NewContainer(runtimeutil.ContainerSpec{Image: "example.com:version",
+		Limits: runtimeutil.ResourceLimits{Timeout: "30s", CPU: "0.5", Memory: "512m"}}, "nobody")
+	require.NoError(t, instance.setupExec())
+	assert.Equal(t, []string{"run", "--rm", "-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+		"--network", "none", "--user", "nobody", "--security-opt=no-new-privileges",
+		"--cpus", "0.5", "--memory", "512m", "-e", "LOG_TO_STDERR=true", "-e", "STRUCTURED_RESULTS=true",
+		"example.com:version"}, instance.Exec.Args)
+	assert.Equal(t, 30*time.Second, instance.Exec.Timeout)
+
+	instance = NewContainer(runtimeutil.ContainerSpec{Image: "example.com:version",
+		Limits: runtimeutil.ResourceLimits{CPU: "none"}}, "nobody")
+	assert.EqualError(t, instance.setupExec(),
+		`function example.com:version: invalid cpu limit "none", must be a positive number of CPUs`)
+}
+
+// setLookPath makes only the given runtimes be found on the PATH.
+func setLookPath(t *testing.T, installed ...string) {
+	t.Helper()
+	oldLookPath := lookPath
+	lookPath = func(file string) (string, error) {
+		for _, runtime := range installed {
+			if file == runtime {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	t.Cleanup(func() { lookPath = oldLookPath })
+}
+
 func TestFilter_Filter(t *testing.T) {
 	cfg, err := yaml.Parse(`apiVersion: apps/v1
 kind: Deployment
@@ -254,3 +432,21 @@ func getAbsFilePath(args ...string) string {
 	path, _ := filepath.Abs(filepath.Join(args...))
 	return path
 }
+
+func TestFilter_cacheKey(t *testing.T) {
+	t.Setenv("TEAM", "platform")
+	c := NewContainer(runtimeutil.ContainerSpec{
+		Image: "example.com/set-team@sha256:" + strings.Repeat("0", 64),
+		Env:   []string{"TEAM", "REGION=eu"},
+	}, "nobody")
+	key := c.cacheKey()
+	assert.Contains(t, key, "TEAM=platform")
+	assert.Contains(t, key, "REGION=eu")
+
+	t.Setenv("TEAM", "data")
+	assert.NotEqual(t, key, c.cacheKey())
+
+	// tags can be moved to other images
+	c.Image = "example.com/set-team:v1"
+	assert.Empty(t, c.cacheKey())
+}
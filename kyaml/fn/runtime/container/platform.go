@@ -0,0 +1,107 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+// The OSes of the containers.
+const (
+	OSLinux   = "linux"
+	OSWindows = "windows"
+)
+
+// Isolations are the isolations of Windows containers.
+var Isolations = []string{"process", "hyperv"}
+
+// windowsUser is the unprivileged user of the Windows images, used in
+// place of nobody.
+const windowsUser = "ContainerUser"
+
+// windowsDrive is the drive of the mounts in Windows containers.
+const windowsDrive = "C:"
+
+// goos and runtimeOSType are replaced by tests.
+var (
+	goos          = runtime.GOOS
+	runtimeOSType = func(rt string) (string, error) {
+		var stderr bytes.Buffer
+		cmd := exec.Command(rt, "info", "--format", "{{.OSType}}")
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", errors.WrapPrefixf(err, "detecting the OS of the containers of %s: %s",
+				rt, strings.TrimSpace(stderr.String()))
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+)
+
+// osTypes caches the OS of the containers of each runtime.
+var osTypes sync.Map
+
+// DetectOS returns the OS of the containers run by the runtime.  Only the
+// runtimes of Windows hosts, which run either Linux or Windows containers,
+// are asked; the containers of other hosts are Linux containers.
+func DetectOS(rt string) (string, error) {
+	if goos != OSWindows {
+		return OSLinux, nil
+	}
+	if osType, ok := osTypes.Load(rt); ok {
+		return osType.(string), nil
+	}
+	osType, err := runtimeOSType(rt)
+	if err != nil {
+		return "", err
+	}
+	if osType != OSWindows {
+		osType = OSLinux
+	}
+	osTypes.Store(rt, osType)
+	return osType, nil
+}
+
+// validatePlatform returns an error if the function can't run in a
+// container of c.OS.
+func (c *Filter) validatePlatform() error {
+	switch c.OS {
+	case OSLinux:
+		return nil
+	case OSWindows:
+	default:
+		return errors.Errorf("unsupported OS %q of the container of function %s, must be %s or %s",
+			c.OS, c.Image, OSLinux, OSWindows)
+	}
+	if c.Isolation != "" && c.Isolation != Isolations[0] && c.Isolation != Isolations[1] {
+		return errors.Errorf("unsupported isolation %q of function %s, must be one of %v",
+			c.Isolation, c.Image, Isolations)
+	}
+	if len(c.AllowedHosts) > 0 {
+		return errors.Errorf("allowedHosts of function %s aren't supported by windows containers", c.Image)
+	}
+	for _, storageMount := range c.StorageMounts {
+		if storageMount.MountType == runtimeutil.MountTypeTmpfs {
+			return errors.Errorf("tmpfs mount to %q of function %s isn't supported by windows containers",
+				storageMount.DstPath, c.Image)
+		}
+	}
+	return nil
+}
+
+// containerPath returns the path of a mount in the container, translating
+// the absolute paths, e.g. /charts, to C:\charts in Windows containers.
+func (c *Filter) containerPath(dst string) string {
+	if c.OS != OSWindows || !strings.HasPrefix(dst, "/") {
+		return dst
+	}
+	return windowsDrive + strings.ReplaceAll(dst, "/", `\`)
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+// setPlatform makes the host run hostOS, and its runtimes run containers
+// of osType.
+func setPlatform(t *testing.T, hostOS, osType string) {
+	t.Helper()
+	oldGOOS, oldRuntimeOSType := goos, runtimeOSType
+	goos = hostOS
+	runtimeOSType = func(rt string) (string, error) {
+		if osType == "" {
+			return "", errors.Errorf("%s isn't running", rt)
+		}
+		return osType, nil
+	}
+	t.Cleanup(func() {
+		goos, runtimeOSType = oldGOOS, oldRuntimeOSType
+		for _, rt := range Runtimes {
+			osTypes.Delete(rt)
+		}
+	})
+}
+
+func TestDetectOS(t *testing.T) {
+	setPlatform(t, OSLinux, OSWindows)
+	osType, err := DetectOS(RuntimeDocker)
+	require.NoError(t, err)
+	assert.Equal(t, OSLinux, osType)
+
+	setPlatform(t, OSWindows, OSWindows)
+	osType, err = DetectOS(RuntimeDocker)
+	require.NoError(t, err)
+	assert.Equal(t, OSWindows, osType)
+
+	setPlatform(t, OSWindows, "")
+	_, err = DetectOS(RuntimePodman)
+	assert.EqualError(t, err, "podman isn't running")
+}
+
+func TestFilter_setupExecWindows(t *testing.T) {
+	setLookPath(t, RuntimeDocker)
+	setPlatform(t, OSWindows, OSWindows)
+	instance := NewContainer(runtimeutil.ContainerSpec{
+		Image: "example.com:version",
+		StorageMounts: []runtimeutil.StorageMount{
+			{MountType: runtimeutil.MountTypeBind, Src: "charts/app", DstPath: "/charts/app", ReadWriteMode: true},
+		},
+	}, "nobody")
+	instance.Exec.WorkingDir = "/kustomization"
+	instance.Isolation = "hyperv"
+	require.NoError(t, instance.setupExec())
+	assert.Equal(t, OSWindows, instance.OS)
+	assert.Equal(t, []string{"run", "--rm", "-i", "-a", "STDIN", "-a", "STDOUT", "-a", "STDERR",
+		"--network", "none", "--user", "ContainerUser", "--isolation", "hyperv",
+		"--mount", `type=bind,source=/kustomization/charts/app,target=C:\charts\app`,
+		"-e", "LOG_TO_STDERR=true", "-e", "STRUCTURED_RESULTS=true", "example.com:version"}, instance.Exec.Args)
+
+	for spec, expectedErr := range map[*runtimeutil.ContainerSpec]string{
+		{Image: "example.com:version", StorageMounts: []runtimeutil.StorageMount{
+			{MountType: runtimeutil.MountTypeTmpfs, DstPath: "/tmp"}}}: `tmpfs mount to "/tmp" of function example.com:version ` +
+			"isn't supported by windows containers",
+		{Image: "example.com:version", Network: true, AllowedHosts: []string{"example.com"}}: "allowedHosts of function " +
+			"example.com:version aren't supported by windows containers",
+	} {
+		instance := NewContainer(*spec, "nobody")
+		assert.EqualError(t, instance.setupExec(), expectedErr)
+	}
+
+	instance = NewContainer(runtimeutil.ContainerSpec{Image: "example.com:version"}, "nobody")
+	instance.Isolation = "vm"
+	assert.EqualError(t, instance.setupExec(),
+		`unsupported isolation "vm" of function example.com:version, must be one of [process hyperv]`)
+}
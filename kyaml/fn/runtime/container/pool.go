@@ -0,0 +1,206 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+)
+
+// ServeArg is the argument of the functions run in the containers of a
+// Pool, which must process a stream of ResourceLists framed as described by
+// framework.Serve until their stdin is closed, like the functions built
+// with the command package do with --serve.
+const ServeArg = "--serve"
+
+// PoolStopTimeout is how long the function of a container of a Pool has to
+// stop once its stdin is closed, before the container is removed anyway.
+var PoolStopTimeout = 10 * time.Second
+
+// Pool keeps the functions running in their containers, to send them the
+// ResourceLists of their next runs rather than starting a container for
+// each run.  A container is reused by the runs of the same image with the
+// same options, e.g. those of the generators and transformers of a build
+// configuring the same function, whose configuration is in the input of
+// each run.
+//
+// The functions run with ServeArg.  The root filesystem of their containers
+// is read-only, so that a run can't leave files behind for the next ones,
+// except in the mounts of the function and in /tmp, a tmpfs.  A container
+// whose run fails other than with the results of the function, e.g. because
+// it times out or exits, is removed, and the next run starts a new one.
+//
+// The containers of the functions with allowed hosts, and those run with
+// nerdctl, aren't reused.
+type Pool struct {
+	mu         sync.Mutex
+	containers map[string]*pooledContainer
+}
+
+// pooledContainer is a container of a Pool, locked while a function runs in
+// it.  Its id is empty until it's created, and once it's removed.
+type pooledContainer struct {
+	mu      sync.Mutex
+	runtime string
+	id      string
+
+	// cmd is the client of the runtime attached to the container, which
+	// sends the ResourceLists written to stdin to the function, and reads
+	// the resulting ones from stdout
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	// exited is closed once the client has exited
+	exited chan struct{}
+}
+
+// NewPool returns an empty Pool.  The caller closes it to remove its containers.
+func NewPool() *Pool {
+	return &Pool{containers: map[string]*pooledContainer{}}
+}
+
+// acquire returns the container created by runtime with createArgs and
+// ServeArg, starting it if the pool has none running yet, with its log
+// written to stderr, and a function to release the container after the run.
+func (p *Pool) acquire(runtime string, createArgs []string, stderr io.Writer) (*pooledContainer, func(), error) {
+	key := runtime + "\x00" + strings.Join(createArgs, "\x00")
+	p.mu.Lock()
+	pc, found := p.containers[key]
+	if !found {
+		pc = &pooledContainer{runtime: runtime}
+		p.containers[key] = pc
+	}
+	p.mu.Unlock()
+
+	// a container runs one function at a time
+	pc.mu.Lock()
+	if pc.id == "" {
+		if err := pc.start(createArgs, stderr); err != nil {
+			pc.mu.Unlock()
+			return nil, nil, err
+		}
+	}
+	return pc, pc.mu.Unlock, nil
+}
+
+// start creates the container and attaches to it.
+func (pc *pooledContainer) start(createArgs []string, stderr io.Writer) error {
+	id, err := runtimeOutput(pc.runtime, append(append([]string{"create"}, createArgs...), ServeArg)...)
+	if err != nil {
+		return err
+	}
+	pc.id = id
+	pc.cmd = exec.Command(pc.runtime, "start", "-a", "-i", id) //nolint:gosec
+	pc.cmd.Stderr = stderr
+	if pc.stdin, err = pc.cmd.StdinPipe(); err == nil {
+		pc.stdout, err = pc.cmd.StdoutPipe()
+	}
+	if err == nil {
+		err = pc.cmd.Start()
+	}
+	if err != nil {
+		pc.cmd = nil
+		_ = pc.remove()
+		return errors.WrapPrefixf(err, "starting container %s", id)
+	}
+	pc.exited = make(chan struct{})
+	go func(cmd *exec.Cmd, exited chan struct{}) {
+		_ = cmd.Wait()
+		close(exited)
+	}(pc.cmd, pc.exited)
+	return nil
+}
+
+// process sends the ResourceList in to the function of the container, and
+// returns the resulting ResourceList, failing if it takes longer than
+// timeout, if positive.  The caller removes the container if it fails.
+func (pc *pooledContainer) process(in []byte, timeout time.Duration) ([]byte, error) {
+	type result struct {
+		out []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		if err := framework.WriteFrame(pc.stdin, in); err != nil {
+			done <- result{err: err}
+			return
+		}
+		out, err := framework.ReadFrame(pc.stdout)
+		done <- result{out: out, err: err}
+	}()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, errors.WrapPrefixf(r.err, "container %s", pc.id)
+		}
+		return r.out, nil
+	case <-expired:
+		return nil, errors.Errorf("container %s timed out after %s", pc.id, timeout)
+	}
+}
+
+// stop closes the stdin of the function for it to exit, and removes the
+// container, once the function has exited or after PoolStopTimeout.
+func (pc *pooledContainer) stop() error {
+	if pc.cmd != nil {
+		pc.stdin.Close()
+		select {
+		case <-pc.exited:
+		case <-time.After(PoolStopTimeout):
+		}
+	}
+	return pc.remove()
+}
+
+// remove force-removes the container, which kills its function, and
+// detaches from it.
+func (pc *pooledContainer) remove() error {
+	if pc.id == "" {
+		return nil
+	}
+	_, err := runtimeOutput(pc.runtime, "rm", "-f", pc.id)
+	if pc.cmd != nil {
+		// the client may outlive the container if it can't be removed
+		_ = pc.cmd.Process.Kill()
+		<-pc.exited
+	}
+	pc.id, pc.cmd, pc.stdin, pc.stdout, pc.exited = "", nil, nil, nil, nil
+	return err
+}
+
+// Close stops the functions and removes the containers of the pool,
+// returning the first error.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keys := make([]string, 0, len(p.containers))
+	for key := range p.containers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var firstErr error
+	for _, key := range keys {
+		pc := p.containers[key]
+		pc.mu.Lock()
+		if err := pc.stop(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		pc.mu.Unlock()
+		delete(p.containers, key)
+	}
+	return firstErr
+}
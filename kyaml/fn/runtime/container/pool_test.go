@@ -0,0 +1,125 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework/command"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// TestHelperFunction is the function the fake runtime of the tests runs in
+// the containers it starts, labelling the resources with its pid.  It fails
+// on a resource named bad, and hangs on one named hang.
+func TestHelperFunction(t *testing.T) {
+	if os.Getenv("CONTAINER_TEST_FUNCTION") != "1" {
+		t.Skip("only run as the function of the tests")
+	}
+	cmd := command.Build(framework.SimpleProcessor{Filter: kio.FilterFunc(
+		func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+			for _, n := range nodes {
+				switch n.GetName() {
+				case "bad":
+					return nil, errors.Errorf("bad resource")
+				case "hang":
+					select {}
+				}
+				if err := n.PipeE(yaml.SetLabel("pid", strconv.Itoa(os.Getpid()))); err != nil {
+					return nil, err
+				}
+			}
+			return nodes, nil
+		})}, command.StandaloneDisabled, false)
+	// the arguments of the function follow --
+	for i, arg := range os.Args {
+		if arg == "--" {
+			cmd.SetArgs(os.Args[i+1:])
+		}
+	}
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestFilter_Pool(t *testing.T) {
+	setLookPath(t, RuntimeDocker)
+	// the fake docker runs TestHelperFunction in the containers it starts
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, RuntimeDocker), []byte(
+		"#!/bin/sh\n[ \"$1\" = start ] && CONTAINER_TEST_FUNCTION=1 exec \""+os.Args[0]+
+			"\" -test.run=^TestHelperFunction$ -- --serve\nexit 1\n"), 0o700)) //nolint:gosec
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var mu sync.Mutex
+	var calls []string
+	oldRuntimeOutput := runtimeOutput
+	runtimeOutput = func(runtime string, args ...string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, runtime+" "+strings.Join(args, " "))
+		return "id-" + strconv.Itoa(len(calls)), nil
+	}
+	t.Cleanup(func() { runtimeOutput = oldRuntimeOutput })
+
+	pool := NewPool()
+	run := func(name string) (string, error) {
+		t.Helper()
+		spec := runtimeutil.ContainerSpec{Image: "example.com:version"}
+		spec.Limits.Timeout = "2s"
+		instance := NewContainer(spec, "nobody")
+		instance.OS = OSLinux
+		instance.Pool = pool
+		instance.Exec.WorkingDir = getWorkingDir(t)
+		output, err := instance.Filter([]*yaml.RNode{yaml.MustParse(
+			"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n")})
+		if err != nil {
+			return "", err
+		}
+		require.Len(t, output, 1)
+		return output[0].GetLabels()["pid"], nil
+	}
+
+	// the runs are sent to the same function
+	first, err := run("config")
+	require.NoError(t, err)
+	assert.NotEmpty(t, first)
+	pid, err := run("config")
+	require.NoError(t, err)
+	assert.Equal(t, first, pid)
+
+	// the failures of the function fail the run, but keep the container
+	_, err = run("bad")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad resource")
+	pid, err = run("config")
+	require.NoError(t, err)
+	assert.Equal(t, first, pid)
+
+	// a container whose function times out is removed, and replaced
+	_, err = run("hang")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "container id-1 timed out after 2s")
+	second, err := run("config")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	require.NoError(t, pool.Close())
+	create := "docker create -i --read-only --tmpfs /tmp -a STDIN -a STDOUT -a STDERR --network none " +
+		"--user nobody --security-opt=no-new-privileges -e LOG_TO_STDERR=true -e STRUCTURED_RESULTS=true " +
+		"example.com:version --serve"
+	assert.Equal(t, []string{create, "docker rm -f id-1", create, "docker rm -f id-3"}, calls)
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/internal/oci"
+)
+
+const (
+	dockerHubRegistry = "registry-1.docker.io"
+	dockerHubLibrary  = "library/"
+)
+
+// constraintPattern matches the version constraints used as the tags of
+// images, e.g. ^2, ^1.4, ~1.4.2 or ^v2.
+var constraintPattern = regexp.MustCompile(`^([\^~])v?(\d+)(?:\.(\d+))?(?:\.(\d+))?$`)
+
+// tagPattern matches the tags which are semantic versions without a
+// prerelease, e.g. v1.2.3 or 1.2.3.
+var tagPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)$`)
+
+// ImageResolver resolves the images of functions whose tag is a version
+// constraint, e.g. example.com/fn:^2, to the newest tag of their
+// repository matching the constraint, e.g. example.com/fn:v2.3.1.  The
+// tags of the repositories are listed once per resolver.
+//
+// A constraint ^X.Y.Z matches the versions from X.Y.Z up to the next
+// major version (the next minor version for 0.Y.Z), and ~X.Y.Z those up to
+// the next minor version.  The omitted minor and patch versions are 0.
+// Only the tags which are semantic versions, without a prerelease, match.
+type ImageResolver struct {
+	client oci.Client
+
+	mu   sync.Mutex
+	tags map[string][]string
+}
+
+// NewImageResolver returns an ImageResolver reading the credentials of the
+// registries from the docker config file.
+func NewImageResolver() *ImageResolver {
+	return &ImageResolver{tags: map[string][]string{}}
+}
+
+// defaultImageResolver resolves the images of the Filters without an
+// ImageResolver.
+var defaultImageResolver = NewImageResolver()
+
+// HasVersionConstraint returns true if the tag of image is a version
+// constraint.
+func HasVersionConstraint(image string) bool {
+	_, tag := splitTag(image)
+	return constraintPattern.MatchString(tag)
+}
+
+// Resolve returns image with its version constraint resolved, or image if
+// its tag isn't a version constraint.
+func (r *ImageResolver) Resolve(image string) (string, error) {
+	name, tag := splitTag(image)
+	m := constraintPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return image, nil
+	}
+	if strings.Contains(image, "@") {
+		return "", errors.Errorf("image %s can't have both a version constraint and a digest", image)
+	}
+	var lower [3]int
+	for i := range lower {
+		if m[i+2] != "" {
+			lower[i], _ = strconv.Atoi(m[i+2])
+		}
+	}
+	// the first version which doesn't match
+	upper := [3]int{lower[0] + 1, 0, 0}
+	if m[3] != "" && (m[1] == "~" || lower[0] == 0) {
+		upper = [3]int{lower[0], lower[1] + 1, 0}
+	}
+	tags, err := r.Tags(name)
+	if err != nil {
+		return "", err
+	}
+	newest, newestV := "", [3]int{-1, 0, 0}
+	for _, t := range tags {
+		tm := tagPattern.FindStringSubmatch(t)
+		if tm == nil {
+			continue
+		}
+		var v [3]int
+		for i := range v {
+			v[i], _ = strconv.Atoi(tm[i+1])
+		}
+		if !lessVersion(v, lower) && lessVersion(v, upper) && lessVersion(newestV, v) {
+			newest, newestV = t, v
+		}
+	}
+	if newest == "" {
+		return "", errors.Errorf("no tag of image %s matches the version constraint %s", name, tag)
+	}
+	return name + ":" + newest, nil
+}
+
+// Tags lists the tags of the repository of image, whose tag and digest
+// are ignored.
+func (r *ImageResolver) Tags(image string) ([]string, error) {
+	name, _ := splitTag(image)
+	ref, err := imageReference(name)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tags, ok := r.tags[name]; ok {
+		return tags, nil
+	}
+	tags, err := r.client.Tags(ref)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "resolving image %s", name)
+	}
+	r.tags[name] = tags
+	return tags, nil
+}
+
+// splitTag returns the name of image without its digest, and its tag.
+func splitTag(image string) (string, string) {
+	name, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// imageReference returns the reference of the repository of the image
+// name, in Docker Hub if its first component isn't a registry.
+func imageReference(name string) (oci.Reference, error) {
+	registry, repository, found := strings.Cut(name, "/")
+	if !found || !strings.ContainsAny(registry, ".:") && registry != "localhost" {
+		registry, repository = dockerHubRegistry, name
+	}
+	if registry == "docker.io" || registry == "index.docker.io" {
+		registry = dockerHubRegistry
+	}
+	if registry == dockerHubRegistry && !strings.Contains(repository, "/") {
+		repository = dockerHubLibrary + repository
+	}
+	if repository == "" || repository != strings.ToLower(repository) {
+		return oci.Reference{}, errors.Errorf("image %s has an invalid repository", name)
+	}
+	return oci.Reference{Registry: registry, Repository: repository}, nil
+}
+
+func lessVersion(a, b [3]int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
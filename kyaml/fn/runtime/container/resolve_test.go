@@ -0,0 +1,73 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/internal/oci"
+	"sigs.k8s.io/kustomize/kyaml/internal/oci/ocitest"
+)
+
+func TestImageResolver_Resolve(t *testing.T) {
+	registry := ocitest.NewRegistry(t, true)
+	registry.Tag("fns/set-team", "v0.3.0", "v0.3.4", "v0.4.0", "v1.2.0", "v1.4.1", "v1.5.0-rc.1",
+		"v1.4.3", "v2.0.0", "latest")
+	image := registry.Host + "/fns/set-team"
+	r := NewImageResolver()
+	for constraint, expected := range map[string]string{
+		"^1":     "v1.4.3",
+		"^v1.4":  "v1.4.3",
+		"~1.2":   "v1.2.0",
+		"~1":     "v1.4.3",
+		"^0.3":   "v0.3.4",
+		"^0":     "v0.4.0",
+		"^2.0.0": "v2.0.0",
+	} {
+		actual, err := r.Resolve(image + ":" + constraint)
+		if assert.NoError(t, err, constraint) {
+			assert.Equal(t, image+":"+expected, actual, constraint)
+		}
+	}
+
+	for _, unconstrained := range []string{image, image + ":v1.2.0", image + "@sha256:abc"} {
+		actual, err := r.Resolve(unconstrained)
+		require.NoError(t, err)
+		assert.Equal(t, unconstrained, actual)
+	}
+
+	_, err := r.Resolve(image + ":^3")
+	assert.EqualError(t, err, "no tag of image "+image+" matches the version constraint ^3")
+	_, err = r.Resolve(image + ":^1@sha256:abc")
+	assert.EqualError(t, err, "image "+image+":^1@sha256:abc can't have both a version constraint and a digest")
+}
+
+func TestImageReference(t *testing.T) {
+	for name, expected := range map[string]oci.Reference{
+		"set-team":                    {Registry: dockerHubRegistry, Repository: "library/set-team"},
+		"fns/set-team":                {Registry: dockerHubRegistry, Repository: "fns/set-team"},
+		"docker.io/set-team":          {Registry: dockerHubRegistry, Repository: "library/set-team"},
+		"gcr.io/fns/set-team":         {Registry: "gcr.io", Repository: "fns/set-team"},
+		"localhost/set-team":          {Registry: "localhost", Repository: "set-team"},
+		"localhost:5000/fns/set-team": {Registry: "localhost:5000", Repository: "fns/set-team"},
+	} {
+		actual, err := imageReference(name)
+		if assert.NoError(t, err, name) {
+			assert.Equal(t, expected, actual, name)
+		}
+	}
+}
+
+func TestFilter_setupExecConstraint(t *testing.T) {
+	setLookPath(t, RuntimeDocker)
+	registry := ocitest.NewRegistry(t, false)
+	registry.Tag("fns/set-team", "v1.0.0", "v1.1.0")
+	instance := NewContainer(runtimeutil.ContainerSpec{Image: registry.Host + "/fns/set-team:^1"}, "nobody")
+	require.NoError(t, instance.setupExec())
+	assert.Equal(t, registry.Host+"/fns/set-team:v1.1.0", instance.Image)
+	assert.Equal(t, registry.Host+"/fns/set-team:v1.1.0", instance.Exec.Args[len(instance.Exec.Args)-1])
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// AllowedExecutable is an executable of an allowlist, matched by its
+// absolute path and the sha256 digest of its content.
+type AllowedExecutable struct {
+	// Path is the absolute path of the executable.
+	Path string `json:"path" yaml:"path"`
+
+	// Sha256 is the hex-encoded sha256 digest of the executable.
+	Sha256 string `json:"sha256" yaml:"sha256"`
+}
+
+// CheckAllowed returns an error if the executable at the absolute path
+// doesn't match an executable of allowlist by path and digest.  A nil
+// allowlist allows any executable, while an empty one allows none.
+func CheckAllowed(path string, allowlist []AllowedExecutable) error {
+	if allowlist == nil {
+		return nil
+	}
+	path = filepath.Clean(path)
+	var digest string
+	for _, allowed := range allowlist {
+		if filepath.Clean(allowed.Path) != path {
+			continue
+		}
+		if digest == "" {
+			var err error
+			if digest, err = sha256File(path); err != nil {
+				return err
+			}
+		}
+		if strings.EqualFold(allowed.Sha256, digest) {
+			return nil
+		}
+		return errors.Errorf("executable %s doesn't have the sha256 digest allowed for it: got %s",
+			path, digest)
+	}
+	return errors.Errorf("executable %s isn't in the allowlist of executables", path)
+}
+
+// sha256File returns the hex-encoded sha256 digest of the file at path.
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrap(err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// absPath returns the absolute path of the executable of c, which is looked
+// up on the PATH like by exec.Command if it's only a name.
+func (c *Filter) absPath() (string, error) {
+	if !strings.ContainsRune(c.Path, filepath.Separator) && !strings.Contains(c.Path, "/") {
+		p, err := exec.LookPath(c.Path)
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		return filepath.Abs(p)
+	}
+	if filepath.IsAbs(c.Path) {
+		return c.Path, nil
+	}
+	return filepath.Join(c.WorkingDir, c.Path), nil
+}
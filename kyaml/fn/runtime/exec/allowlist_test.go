@@ -0,0 +1,63 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestCheckAllowed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "set-team")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\ncat\n"), 0o700)) //nolint:gosec
+	sum := sha256.Sum256([]byte("#!/bin/sh\ncat\n"))
+	digest := hex.EncodeToString(sum[:])
+
+	assert.NoError(t, exec.CheckAllowed(path, nil))
+	assert.NoError(t, exec.CheckAllowed(path, []exec.AllowedExecutable{
+		{Path: "/usr/bin/other", Sha256: digest},
+		{Path: path, Sha256: strings.ToUpper(digest)},
+	}))
+	assert.EqualError(t, exec.CheckAllowed(path, []exec.AllowedExecutable{}),
+		"executable "+path+" isn't in the allowlist of executables")
+	assert.EqualError(t, exec.CheckAllowed(path, []exec.AllowedExecutable{
+		{Path: path, Sha256: strings.Repeat("0", 64)},
+	}), "executable "+path+" doesn't have the sha256 digest allowed for it: got "+digest)
+}
+
+func TestFilter_Allowlist(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "echo.sh"), []byte("#!/bin/sh\ncat\n"), 0o700)) //nolint:gosec
+	sum := sha256.Sum256([]byte("#!/bin/sh\ncat\n"))
+	input := []*yaml.RNode{yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n")}
+
+	instance := exec.Filter{
+		Path:       "./echo.sh",
+		WorkingDir: dir,
+		Allowlist:  []exec.AllowedExecutable{{Path: filepath.Join(dir, "echo.sh"), Sha256: hex.EncodeToString(sum[:])}},
+	}
+	output, err := instance.Filter(input)
+	require.NoError(t, err)
+	assert.Len(t, output, 1)
+
+	// executables on the PATH are matched by the path they're found at
+	instance = exec.Filter{
+		Path:       "sh",
+		Args:       []string{"echo.sh"},
+		WorkingDir: dir,
+		Allowlist:  instance.Allowlist,
+	}
+	_, err = instance.Filter(input)
+	assert.ErrorContains(t, err, "/sh isn't in the allowlist of executables")
+}
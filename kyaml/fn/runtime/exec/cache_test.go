@@ -0,0 +1,65 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestFilter_Cache(t *testing.T) {
+	dir := t.TempDir()
+	// the function records its runs, and outputs its input
+	script := "#!/bin/sh\necho run >> runs.txt\ncat\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fn.sh"), []byte(script), 0o700)) //nolint:gosec
+	cache := &runtimeutil.OutputCache{Dir: filepath.Join(dir, "cache")}
+	runs := func() int {
+		b, err := os.ReadFile(filepath.Join(dir, "runs.txt"))
+		require.NoError(t, err)
+		return len(b) / len("run\n")
+	}
+	filter := func(config string) []*yaml.RNode {
+		t.Helper()
+		instance := exec.Filter{Path: "./fn.sh", WorkingDir: dir}
+		instance.Cache = cache
+		instance.FunctionConfig = yaml.MustParse(config)
+		output, err := instance.Filter([]*yaml.RNode{
+			yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n"),
+		})
+		require.NoError(t, err)
+		return output
+	}
+
+	first := filter("apiVersion: v1\nkind: ConfigMap\ndata:\n  team: platform\n")
+	assert.Equal(t, 1, runs())
+	second := filter("apiVersion: v1\nkind: ConfigMap\ndata:\n  team: platform\n")
+	assert.Equal(t, 1, runs())
+	assert.Equal(t, first[0].MustString(), second[0].MustString())
+
+	// another functionConfig is another input
+	filter("apiVersion: v1\nkind: ConfigMap\ndata:\n  team: data\n")
+	assert.Equal(t, 2, runs())
+
+	// another executable is another function
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fn.sh"), []byte(script+"\n"), 0o700)) //nolint:gosec
+	filter("apiVersion: v1\nkind: ConfigMap\ndata:\n  team: platform\n")
+	assert.Equal(t, 3, runs())
+
+	// failed runs aren't cached
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fail.sh"), []byte("#!/bin/sh\necho run >> runs.txt\nexit 1\n"), 0o700)) //nolint:gosec
+	for i := 0; i < 2; i++ {
+		instance := exec.Filter{Path: "./fail.sh", WorkingDir: dir}
+		instance.Cache = cache
+		_, err := instance.Filter(nil)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 5, runs())
+}
@@ -8,6 +8,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
@@ -25,30 +28,172 @@ type Filter struct {
 	// should run in
 	WorkingDir string
 
+	// Env are environment variables, as KEY=VALUE, set for the
+	// executable in addition to those of the current process
+	Env []string
+
+	// IsolateEnv, if true, sets only Env and the PATH of the current
+	// process for the executable, which inherits no other variables
+	IsolateEnv bool
+
+	// Allowlist, if not nil, restricts the executables run to its
+	// executables, matched by path and digest
+	Allowlist []AllowedExecutable
+
+	// Sandbox, if not nil, runs the executable in the sandbox
+	Sandbox *Sandbox
+
+	// Protocol is how the executable receives the ResourceLists, one of
+	// runtimeutil.ExecProtocolStdio (the default) and ExecProtocolServer.
+	Protocol string
+
+	// Servers keeps the executables run with ExecProtocolServer running for
+	// their next ResourceLists, and connects to them.  It's required by
+	// ExecProtocolServer.
+	Servers *ServerPool
+
+	// Timeout, if positive, kills the executable if a run takes longer
+	Timeout time.Duration
+
+	// MemoryLimit, if positive, limits the address space of the executable
+	// to this many bytes, set before it starts, and of the bubblewrap
+	// running it in the Sandbox.  It's only supported on Linux, and requires
+	// sh on the PATH.
+	MemoryLimit int64
+
 	runtimeutil.FunctionFilter
 }
 
 func (c *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	c.FunctionFilter.Run = c.Run
+	if c.Cache != nil && c.CacheKey == "" {
+		key, err := c.cacheKey()
+		if err != nil {
+			return nil, err
+		}
+		c.CacheKey = key
+	}
 	return c.FunctionFilter.Filter(nodes)
 }
 
+// cacheKey identifies the function in the output cache, by the digest of
+// the executable, its arguments, its environment and its working directory.
+// The environment inherited from the host isn't part of the key.
+func (c *Filter) cacheKey() (string, error) {
+	path, err := c.absPath()
+	if err != nil {
+		return "", err
+	}
+	digest, err := sha256File(path)
+	if err != nil {
+		return "", err
+	}
+	key := []string{"exec", digest, c.WorkingDir, strconv.FormatBool(c.IsolateEnv)}
+	key = append(key, c.Args...)
+	key = append(key, "--")
+	return strings.Join(append(key, c.Env...), "\x00"), nil
+}
+
 func (c *Filter) Run(reader io.Reader, writer io.Writer) error {
-	cmd := exec.Command(c.Path, c.Args...) //nolint:gosec
+	if c.Protocol == runtimeutil.ExecProtocolServer {
+		return c.runOnServer(reader, writer)
+	}
+	if c.Protocol != "" && c.Protocol != runtimeutil.ExecProtocolStdio {
+		return errors.Errorf("unsupported protocol %q of exec function %s, must be %s or %s",
+			c.Protocol, c.Path, runtimeutil.ExecProtocolStdio, runtimeutil.ExecProtocolServer)
+	}
+	cmd, cleanup, err := c.command(c.Args)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 	cmd.Stdin = reader
 	cmd.Stdout = writer
+	defer flushLog(cmd)
+	return c.run(cmd)
+}
+
+// run runs cmd within the limits of c.
+func (c *Filter) run(cmd *exec.Cmd) error {
+	if c.Timeout > 0 {
+		// don't wait for the children of a killed executable holding its
+		// stdout or stderr
+		cmd.WaitDelay = time.Second
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	if c.Timeout <= 0 {
+		return cmd.Wait()
+	}
+	timer := time.AfterFunc(c.Timeout, func() { _ = cmd.Process.Kill() })
+	err := cmd.Wait()
+	if !timer.Stop() {
+		return errors.Errorf("exec function %s timed out after %s", c.Path, c.Timeout)
+	}
+	return err
+}
+
+// command returns the command running the executable with args, and a
+// function releasing its resources once it has started.
+func (c *Filter) command(args []string) (*exec.Cmd, func(), error) {
+	cmd := exec.Command(c.Path, args...) //nolint:gosec
 	cmd.Stderr = os.Stderr
+	if c.Log != nil {
+		cmd.Stderr = runtimeutil.NewLogWriter(c.Log)
+	}
+	switch {
+	case c.IsolateEnv:
+		cmd.Env = []string{}
+		if path, ok := os.LookupEnv("PATH"); ok {
+			cmd.Env = append(cmd.Env, "PATH="+path)
+		}
+		cmd.Env = append(cmd.Env, c.Env...)
+	case len(c.Env) > 0:
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
 	if c.WorkingDir == "" {
-		return errors.Errorf("no working directory set for exec function")
+		return nil, nil, errors.Errorf("no working directory set for exec function")
 	}
 	if !filepath.IsAbs(c.WorkingDir) {
-		return errors.Errorf(
+		return nil, nil, errors.Errorf(
 			"relative working directory %s not allowed", c.WorkingDir)
 	}
 	if c.WorkingDir == "/" {
-		return errors.Errorf(
+		return nil, nil, errors.Errorf(
 			"root working directory '/' not allowed")
 	}
+	cleanup := func() {}
+	if c.Allowlist != nil || c.Sandbox != nil {
+		path, err := c.absPath()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := CheckAllowed(path, c.Allowlist); err != nil {
+			return nil, nil, err
+		}
+		if c.Sandbox != nil {
+			filter, err := c.Sandbox.wrap(cmd, path, args, c.WorkingDir)
+			if err != nil {
+				return nil, nil, err
+			}
+			cleanup = func() { filter.Close() }
+		}
+	}
+	if c.MemoryLimit > 0 {
+		if err := limitMemory(cmd, c.MemoryLimit); err != nil {
+			cleanup()
+			return nil, nil, errors.WrapPrefixf(err, "limiting the memory of exec function %s", c.Path)
+		}
+	}
 	cmd.Dir = c.WorkingDir
-	return cmd.Run()
+	return cmd, cleanup, nil
+}
+
+// flushLog logs the last partial line the command wrote to its stderr,
+// once it has exited.
+func flushLog(cmd *exec.Cmd) {
+	if w, ok := cmd.Stderr.(*runtimeutil.LogWriter); ok {
+		w.Flush()
+	}
 }
@@ -5,12 +5,15 @@ package exec_test
 
 import (
 	"os"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -62,6 +65,60 @@ metadata:
 				WorkingDir: wd,
 			},
 		},
+		{
+			name: "exec_env",
+			input: []string{
+				`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-foo`,
+			},
+			expectedOutput: []string{
+				`apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: deployment-foo
+  annotations:
+    internal.config.kubernetes.io/path: 'statefulset_deployment-foo.yaml'
+    config.kubernetes.io/path: 'statefulset_deployment-foo.yaml'
+`,
+			},
+			expectedError: "",
+			instance: exec.Filter{
+				Path:       "sh",
+				Args:       []string{"-c", "sed s/Deployment/$KIND/g"},
+				WorkingDir: wd,
+				Env:        []string{"KIND=StatefulSet"},
+			},
+		},
+		{
+			name: "exec_isolated_env",
+			input: []string{
+				`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-foo`,
+			},
+			expectedOutput: []string{
+				`apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: deployment-foo
+  annotations:
+    internal.config.kubernetes.io/path: 'statefulset_deployment-foo.yaml'
+    config.kubernetes.io/path: 'statefulset_deployment-foo.yaml'
+`,
+			},
+			expectedError: "",
+			instance: exec.Filter{
+				Path: "sh",
+				// HOME isn't inherited
+				Args:       []string{"-c", "sed s/Deployment/$KIND$HOME/g"},
+				WorkingDir: wd,
+				Env:        []string{"KIND=StatefulSet"},
+				IsolateEnv: true,
+			},
+		},
 	}
 
 	for i := range tests {
@@ -117,3 +174,55 @@ metadata:
 		})
 	}
 }
+
+func TestFilter_Log(t *testing.T) {
+	var records []runtimeutil.LogRecord
+	f := exec.Filter{
+		Path:       "sh",
+		Args:       []string{"-c", "echo 'WARN: careful' >&2; printf done >&2; cat"},
+		WorkingDir: t.TempDir(),
+	}
+	f.Log = func(r runtimeutil.LogRecord) { records = append(records, r) }
+	_, err := f.Filter([]*yaml.RNode{yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n")})
+	require.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, runtimeutil.LogLevelWarning, records[0].Level)
+		assert.Equal(t, "careful", records[0].Message)
+		assert.Equal(t, "done", records[1].Message)
+	}
+}
+
+func TestFilter_limits(t *testing.T) {
+	input := []*yaml.RNode{yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n")}
+	f := exec.Filter{Path: "sleep", Args: []string{"10"}, WorkingDir: t.TempDir(), Timeout: 100 * time.Millisecond}
+	_, err := f.Filter(input)
+	assert.EqualError(t, err, "exec function sleep timed out after 100ms")
+
+	if runtime.GOOS == "linux" {
+		f = exec.Filter{Path: "cat", WorkingDir: t.TempDir(), Timeout: time.Minute, MemoryLimit: 1 << 30}
+		_, err = f.Filter(input)
+		assert.NoError(t, err)
+	}
+}
+
+func TestFilter_memoryLimit(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("memory limits are only supported on linux")
+	}
+	input := []*yaml.RNode{yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n")}
+	// the function holds 64MB in a variable before copying its input
+	f := exec.Filter{
+		Path:        "sh",
+		Args:        []string{"-c", `x=$(head -c 64000000 /dev/zero | tr '\0' a) && cat`},
+		WorkingDir:  t.TempDir(),
+		MemoryLimit: 1 << 30,
+	}
+	output, err := f.Filter(input)
+	require.NoError(t, err)
+	assert.Len(t, output, 1)
+
+	// the limit applies from the start of the function
+	f.MemoryLimit = 32 << 20
+	_, err = f.Filter(input)
+	assert.Error(t, err)
+}
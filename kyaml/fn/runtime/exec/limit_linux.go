@@ -0,0 +1,38 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"os/exec"
+	"strconv"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// MemoryLimitSupported is whether the Filter can limit the memory of the
+// executable with MemoryLimit.
+const MemoryLimitSupported = true
+
+// limitMemory changes cmd to limit its address space to bytes before its
+// executable starts, by running it with sh setting the limit with ulimit,
+// which the executable inherits.  The limit of a sandboxed cmd applies to
+// bubblewrap and the sandboxed executable alike.
+func limitMemory(cmd *exec.Cmd, bytes int64) error {
+	if cmd.Err != nil {
+		return cmd.Err
+	}
+	sh, err := lookPath("sh")
+	if err != nil {
+		return errors.Errorf("memory limits of exec functions require sh on the PATH")
+	}
+	kib := bytes / 1024
+	if kib < 1 {
+		kib = 1
+	}
+	// the limit is $0, the command "$@"
+	args := []string{sh, "-c", `ulimit -v "$0" && exec "$@"`, strconv.FormatInt(kib, 10), cmd.Path}
+	cmd.Args = append(args, cmd.Args[1:]...)
+	cmd.Path = sh
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitMemory_sandbox(t *testing.T) {
+	if _, ok := seccompArchs[runtime.GOARCH]; !ok {
+		t.Skipf("sandboxes aren't supported on %s", runtime.GOARCH)
+	}
+	setSandboxHost(t, "linux", true)
+	cmd := exec.Command("/opt/fns/set-team", "--team", "platform")
+	filter, err := (&Sandbox{}).wrap(cmd, "/opt/fns/set-team", []string{"--team", "platform"}, "/kustomization")
+	require.NoError(t, err)
+	defer filter.Close()
+	bwrapArgs := cmd.Args[1:]
+
+	// bubblewrap is run with the limit, which the sandboxed executable inherits
+	require.NoError(t, limitMemory(cmd, 64<<20))
+	assert.Equal(t, "/usr/bin/sh", cmd.Path)
+	assert.Equal(t, append([]string{"/usr/bin/sh", "-c", `ulimit -v "$0" && exec "$@"`, "65536", "/usr/bin/bwrap"},
+		bwrapArgs...), cmd.Args)
+}
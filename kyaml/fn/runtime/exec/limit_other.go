@@ -0,0 +1,24 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux
+// +build !linux
+
+package exec
+
+import (
+	"os/exec"
+	"runtime"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// MemoryLimitSupported is whether the Filter can limit the memory of the
+// executable with MemoryLimit.
+const MemoryLimitSupported = false
+
+// limitMemory fails, since the memory of processes is only limited on
+// Linux.
+func limitMemory(*exec.Cmd, int64) error {
+	return errors.Errorf("memory limits of exec functions aren't supported on %s", runtime.GOOS)
+}
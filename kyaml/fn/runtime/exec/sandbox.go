@@ -0,0 +1,244 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+// Sandbox runs the executable of an exec function unprivileged, with
+// bubblewrap on Linux: in new user, pid, ipc, uts and network namespaces,
+// without capabilities, with a seccomp filter denying the syscalls which
+// administer the system or inspect other processes, and with a read-only
+// view of the system directories and the working directory.  The
+// executable can only write to stdout, stderr, a private /tmp and the
+// mounts which aren't read-only.
+type Sandbox struct {
+	// Mounts are the storage the executable can access in addition to the
+	// system directories and the working directory.  Only bind mounts are
+	// supported, and relative sources are relative to the working directory.
+	Mounts []runtimeutil.StorageMount
+
+	// Network, if true, lets the executable access the network of the host.
+	Network bool
+}
+
+// SandboxRuntime is the command running the sandboxes.
+const SandboxRuntime = "bwrap"
+
+// sandboxSystemDirs are mounted read-only in the sandbox if they exist,
+// for the executable to find its interpreter and libraries.
+var sandboxSystemDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc"}
+
+// lookPath and goos are replaced by tests.
+var (
+	lookPath = exec.LookPath
+	goos     = runtime.GOOS
+)
+
+// wrap changes cmd to run the executable at the absolute path with args
+// in the sandbox.  It returns the file of the seccomp filter, which the
+// caller closes once cmd has run.
+func (s *Sandbox) wrap(cmd *exec.Cmd, path string, args []string, workingDir string) (*os.File, error) {
+	if goos != "linux" {
+		return nil, errors.Errorf("sandboxed exec functions are only supported on linux")
+	}
+	filter, err := seccompFilter(runtime.GOARCH)
+	if err != nil {
+		return nil, err
+	}
+	bwrap, err := lookPath(SandboxRuntime)
+	if err != nil {
+		return nil, errors.Errorf(
+			"sandboxed exec functions require %s (bubblewrap) on the PATH", SandboxRuntime)
+	}
+	options, err := s.args(path, workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// bubblewrap reads the filter from an inherited file descriptor, the
+	// first one of ExtraFiles
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	_, err = w.Write(filter)
+	w.Close()
+	if err != nil {
+		r.Close()
+		return nil, errors.Wrap(err)
+	}
+	cmd.ExtraFiles = append([]*os.File{r}, cmd.ExtraFiles...)
+	cmd.Path, cmd.Err = bwrap, nil
+	cmd.Args = append(append([]string{SandboxRuntime, "--seccomp", "3"}, options...),
+		append([]string{"--", path}, args...)...)
+	return r, nil
+}
+
+// args returns the options of bubblewrap running the executable at path
+// in the sandbox, without the seccomp filter.
+func (s *Sandbox) args(path, workingDir string) ([]string, error) {
+	args := []string{
+		"--unshare-all",
+		"--die-with-parent",
+		"--new-session",
+		"--cap-drop", "ALL",
+	}
+	if s.Network {
+		args = append(args, "--share-net")
+	}
+	for _, dir := range sandboxSystemDirs {
+		args = append(args, "--ro-bind-try", dir, dir)
+	}
+	args = append(args,
+		"--proc", "/proc",
+		"--dev", "/dev",
+		"--tmpfs", "/tmp",
+		"--ro-bind", workingDir, workingDir,
+		// the executable may be outside of the directories of the sandbox
+		"--ro-bind", path, path,
+	)
+	for _, m := range s.Mounts {
+		if m.MountType != "bind" {
+			return nil, errors.Errorf("mount %s isn't supported by sandboxed exec functions, "+
+				"which only support bind mounts", m.String())
+		}
+		src := m.Src
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(workingDir, src)
+		}
+		bind := "--ro-bind"
+		if m.ReadWriteMode {
+			bind = "--bind"
+		}
+		args = append(args, bind, src, m.DstPath)
+	}
+	return append(args, "--chdir", workingDir), nil
+}
+
+// Classic BPF instructions and seccomp return values of the seccomp filter.
+const (
+	bpfLoadAbs  = 0x20 // BPF_LD | BPF_W | BPF_ABS
+	bpfJumpEq   = 0x15 // BPF_JMP | BPF_JEQ | BPF_K
+	bpfJumpGe   = 0x35 // BPF_JMP | BPF_JGE | BPF_K
+	bpfReturn   = 0x06 // BPF_RET | BPF_K
+	seccompKill = 0x80000000
+	seccompDeny = 0x00050000 | 1 // SECCOMP_RET_ERRNO with EPERM
+	seccompPass = 0x7fff0000
+
+	// offsets of the fields of struct seccomp_data
+	seccompDataNr   = 0
+	seccompDataArch = 4
+
+	// x32SyscallBit marks the syscalls of the x32 ABI on amd64
+	x32SyscallBit = 0x40000000
+)
+
+// seccompArchs are the audit architectures of the supported GOARCHs, and
+// the numbers of the syscalls the filter denies: those administering the
+// system, loading code into the kernel, changing the namespaces and mounts,
+// and inspecting other processes.
+var seccompArchs = map[string]struct {
+	audit    uint32
+	syscalls []uint32
+}{
+	"amd64": {audit: 0xc000003e, syscalls: []uint32{
+		101, // ptrace
+		155, // pivot_root
+		161, // chroot
+		163, // acct
+		165, // mount
+		166, // umount2
+		167, // swapon
+		168, // swapoff
+		169, // reboot
+		175, // init_module
+		176, // delete_module
+		179, // quotactl
+		246, // kexec_load
+		248, // add_key
+		249, // request_key
+		250, // keyctl
+		272, // unshare
+		298, // perf_event_open
+		304, // open_by_handle_at
+		308, // setns
+		310, // process_vm_readv
+		311, // process_vm_writev
+		313, // finit_module
+		320, // kexec_file_load
+		321, // bpf
+		323, // userfaultfd
+	}},
+	"arm64": {audit: 0xc00000b7, syscalls: []uint32{
+		39,  // umount2
+		40,  // mount
+		41,  // pivot_root
+		51,  // chroot
+		60,  // quotactl
+		89,  // acct
+		97,  // unshare
+		104, // kexec_load
+		105, // init_module
+		106, // delete_module
+		117, // ptrace
+		142, // reboot
+		217, // add_key
+		218, // request_key
+		219, // keyctl
+		224, // swapon
+		225, // swapoff
+		241, // perf_event_open
+		265, // open_by_handle_at
+		268, // setns
+		270, // process_vm_readv
+		271, // process_vm_writev
+		273, // finit_module
+		280, // bpf
+		282, // userfaultfd
+		294, // kexec_file_load
+	}},
+}
+
+// seccompFilter returns the seccomp filter of the sandbox for arch, as the
+// classic BPF program bubblewrap reads, in the byte order of arch.
+func seccompFilter(arch string) ([]byte, error) {
+	a, ok := seccompArchs[arch]
+	if !ok {
+		return nil, errors.Errorf("sandboxed exec functions aren't supported on %s", arch)
+	}
+	var b bytes.Buffer
+	ins := func(code uint16, jt, jf uint8, k uint32) {
+		// both supported architectures are little-endian
+		_ = binary.Write(&b, binary.LittleEndian, struct {
+			Code   uint16
+			Jt, Jf uint8
+			K      uint32
+		}{code, jt, jf, k})
+	}
+	// kill the syscalls of other architectures, whose numbers differ
+	ins(bpfLoadAbs, 0, 0, seccompDataArch)
+	ins(bpfJumpEq, 1, 0, a.audit)
+	ins(bpfReturn, 0, 0, seccompKill)
+	ins(bpfLoadAbs, 0, 0, seccompDataNr)
+	if arch == "amd64" {
+		ins(bpfJumpGe, 0, 1, x32SyscallBit)
+		ins(bpfReturn, 0, 0, seccompDeny)
+	}
+	for _, nr := range a.syscalls {
+		ins(bpfJumpEq, 0, 1, nr)
+		ins(bpfReturn, 0, 0, seccompDeny)
+	}
+	ins(bpfReturn, 0, 0, seccompPass)
+	return b.Bytes(), nil
+}
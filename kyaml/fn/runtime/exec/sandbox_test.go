@@ -0,0 +1,100 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"encoding/binary"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+func setSandboxHost(t *testing.T, hostOS string, installed bool) {
+	t.Helper()
+	oldLookPath, oldGoos := lookPath, goos
+	lookPath = func(file string) (string, error) {
+		if installed {
+			return "/usr/bin/" + file, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	goos = hostOS
+	t.Cleanup(func() { lookPath, goos = oldLookPath, oldGoos })
+}
+
+func TestSandbox_wrap(t *testing.T) {
+	if _, ok := seccompArchs[runtime.GOARCH]; !ok {
+		t.Skipf("sandboxes aren't supported on %s", runtime.GOARCH)
+	}
+	setSandboxHost(t, "linux", true)
+	s := &Sandbox{Mounts: []runtimeutil.StorageMount{
+		{MountType: "bind", Src: "charts", DstPath: "/charts"},
+		{MountType: "bind", Src: "/var/cache/fn", DstPath: "/cache", ReadWriteMode: true},
+	}}
+	cmd := exec.Command("/opt/fns/set-team", "--team", "platform")
+	filter, err := s.wrap(cmd, "/opt/fns/set-team", []string{"--team", "platform"}, "/kustomization")
+	require.NoError(t, err)
+	defer filter.Close()
+	assert.Equal(t, "/usr/bin/bwrap", cmd.Path)
+	assert.Equal(t, []*os.File{filter}, cmd.ExtraFiles)
+	assert.Equal(t, []string{"bwrap", "--seccomp", "3",
+		"--unshare-all", "--die-with-parent", "--new-session", "--cap-drop", "ALL",
+		"--ro-bind-try", "/usr", "/usr",
+		"--ro-bind-try", "/bin", "/bin",
+		"--ro-bind-try", "/sbin", "/sbin",
+		"--ro-bind-try", "/lib", "/lib",
+		"--ro-bind-try", "/lib32", "/lib32",
+		"--ro-bind-try", "/lib64", "/lib64",
+		"--ro-bind-try", "/etc", "/etc",
+		"--proc", "/proc",
+		"--dev", "/dev",
+		"--tmpfs", "/tmp",
+		"--ro-bind", "/kustomization", "/kustomization",
+		"--ro-bind", "/opt/fns/set-team", "/opt/fns/set-team",
+		"--ro-bind", "/kustomization/charts", "/charts",
+		"--bind", "/var/cache/fn", "/cache",
+		"--chdir", "/kustomization",
+		"--", "/opt/fns/set-team", "--team", "platform",
+	}, cmd.Args)
+
+	s = &Sandbox{Network: true}
+	args, err := s.args("/opt/fns/set-team", "/kustomization")
+	require.NoError(t, err)
+	assert.Contains(t, args, "--share-net")
+
+	s = &Sandbox{Mounts: []runtimeutil.StorageMount{{MountType: "volume", Src: "cache", DstPath: "/cache"}}}
+	_, err = s.wrap(exec.Command("/opt/fns/set-team"), "/opt/fns/set-team", nil, "/kustomization")
+	assert.EqualError(t, err, "mount type=volume,source=cache,target=/cache,readonly "+
+		"isn't supported by sandboxed exec functions, which only support bind mounts")
+
+	setSandboxHost(t, "linux", false)
+	_, err = (&Sandbox{}).wrap(exec.Command("/opt/fns/set-team"), "/opt/fns/set-team", nil, "/kustomization")
+	assert.EqualError(t, err, "sandboxed exec functions require bwrap (bubblewrap) on the PATH")
+
+	setSandboxHost(t, "darwin", true)
+	_, err = (&Sandbox{}).wrap(exec.Command("/opt/fns/set-team"), "/opt/fns/set-team", nil, "/kustomization")
+	assert.EqualError(t, err, "sandboxed exec functions are only supported on linux")
+}
+
+func TestSeccompFilter(t *testing.T) {
+	for arch, a := range seccompArchs {
+		filter, err := seccompFilter(arch)
+		require.NoError(t, err)
+		n := 4 + 2*len(a.syscalls) + 1
+		if arch == "amd64" {
+			n += 2
+		}
+		require.Len(t, filter, 8*n, arch)
+		// the filter checks the architecture first, and allows the other syscalls last
+		assert.Equal(t, a.audit, binary.LittleEndian.Uint32(filter[12:16]), arch)
+		assert.Equal(t, uint32(seccompPass), binary.LittleEndian.Uint32(filter[len(filter)-4:]), arch)
+	}
+	_, err := seccompFilter("s390x")
+	assert.EqualError(t, err, "sandboxed exec functions aren't supported on s390x")
+}
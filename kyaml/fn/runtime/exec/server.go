@@ -0,0 +1,310 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec
+
+import (
+	"bufio"
+	"context"
+	goerrors "errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+// ServerStartTimeout is how long an exec function run as a server has to
+// advertise its address.
+var ServerStartTimeout = 30 * time.Second
+
+// ServerStopTimeout is how long an exec function run as a server has to stop
+// once its stdin is closed, before it's killed.
+var ServerStopTimeout = 10 * time.Second
+
+// DefaultServerTimeout is how long an exec function run as a server may take
+// to process a ResourceList if the Timeout of the function isn't set.
+var DefaultServerTimeout = 10 * time.Minute
+
+// ServerConn is the connection to an exec function run as a server.
+type ServerConn interface {
+	// Process sends the ResourceList in to the function, and returns the
+	// resulting ResourceList.  If the function fails to process it, Process
+	// returns the resulting ResourceList with the results of the failure,
+	// and a *FunctionError.
+	Process(ctx context.Context, in []byte) ([]byte, error)
+
+	// Close closes the connection.
+	Close() error
+}
+
+// ServerDialer connects to the exec functions run as servers, e.g. with
+// the gRPC service of the fngrpc module.
+type ServerDialer interface {
+	// Dial connects to the function listening on the unix socket at path.
+	Dial(path string) (ServerConn, error)
+}
+
+// FunctionError is the error of an exec function run as a server which
+// failed to process a ResourceList.
+type FunctionError struct {
+	Message string
+}
+
+func (e *FunctionError) Error() string {
+	return e.Message
+}
+
+// ServerPool keeps the exec functions run as servers running, to send their
+// next ResourceLists to them, e.g. across the runs of the functions in a
+// build.
+type ServerPool struct {
+	dialer  ServerDialer
+	mu      sync.Mutex
+	servers map[string]*pooledServer
+}
+
+// pooledServer is a server of a ServerPool, started by the first get of
+// its key.
+type pooledServer struct {
+	// started is closed once s or err is set
+	started chan struct{}
+	s       *server
+	err     error
+}
+
+// NewServerPool returns an empty ServerPool connecting to its servers
+// with dialer.
+func NewServerPool(dialer ServerDialer) *ServerPool {
+	return &ServerPool{dialer: dialer, servers: map[string]*pooledServer{}}
+}
+
+// Close stops the servers of the pool, returning the first error.
+func (p *ServerPool) Close() error {
+	p.mu.Lock()
+	servers := p.servers
+	p.servers = map[string]*pooledServer{}
+	p.mu.Unlock()
+	keys := make([]string, 0, len(servers))
+	for k := range servers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var firstErr error
+	for _, k := range keys {
+		ps := servers[k]
+		<-ps.started
+		if ps.s == nil {
+			continue
+		}
+		if err := ps.s.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// get returns the server of the executable of c, starting it if it isn't
+// running yet.  The lock of the pool isn't held while the server starts, so
+// that the servers of other executables can be used meanwhile.
+func (p *ServerPool) get(c *Filter) (*pooledServer, string, error) {
+	key := strings.Join(append(append([]string{c.Path, c.WorkingDir}, c.Args...), c.Env...), "\x00")
+	p.mu.Lock()
+	ps, ok := p.servers[key]
+	if !ok {
+		ps = &pooledServer{started: make(chan struct{})}
+		p.servers[key] = ps
+	}
+	p.mu.Unlock()
+	if ok {
+		<-ps.started
+		return ps, key, ps.err
+	}
+	ps.s, ps.err = c.startServer(p.dialer)
+	close(ps.started)
+	if ps.err != nil {
+		// the next get starts it again
+		p.evict(key, ps)
+	}
+	return ps, key, ps.err
+}
+
+// evict removes the server ps of key from the pool, and stops it.
+func (p *ServerPool) evict(key string, ps *pooledServer) {
+	p.mu.Lock()
+	if p.servers[key] == ps {
+		delete(p.servers, key)
+	}
+	p.mu.Unlock()
+	if ps.s != nil {
+		_ = ps.s.close()
+	}
+}
+
+// server is an executable run with --listen, and the connection to it.
+type server struct {
+	cmd   *exec.Cmd
+	stdin io.Closer
+	conn  ServerConn
+	// dir is the directory of the socket
+	dir string
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// runOnServer sends the ResourceList of reader to the executable run as a
+// server of c.Servers, and writes the resulting ResourceList to writer.
+func (c *Filter) runOnServer(reader io.Reader, writer io.Writer) error {
+	if c.Servers == nil {
+		return errors.Errorf("exec function %s run as a server requires a server pool", c.Path)
+	}
+	in, err := io.ReadAll(reader)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	ps, key, err := c.Servers.get(c)
+	if err != nil {
+		return err
+	}
+	out, err := ps.s.process(in, c.Timeout)
+	var fnErr *FunctionError
+	if err != nil && !goerrors.As(err, &fnErr) {
+		// the server may be broken, or stuck
+		c.Servers.evict(key, ps)
+		return errors.WrapPrefixf(err, "exec function %s run as a server", c.Path)
+	}
+	// with the results of a failed function too
+	if _, writeErr := writer.Write(out); writeErr != nil {
+		return errors.Wrap(writeErr)
+	}
+	if err != nil {
+		return errors.WrapPrefixf(err, "exec function %s run as a server", c.Path)
+	}
+	return nil
+}
+
+// serverSocket is the name of the unix socket of a server, in a directory
+// of its own.
+const serverSocket = "fn.sock"
+
+// startServer runs the executable with --listen and the path of a unix
+// socket, and connects to it with dialer once the executable advertises it
+// listens.  A sandboxed executable can write to the directory of the socket.
+func (c *Filter) startServer(dialer ServerDialer) (*server, error) {
+	dir, err := os.MkdirTemp("", "kustomize-fn-server-")
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	socket := filepath.Join(dir, serverSocket)
+	f := *c
+	if c.Sandbox != nil {
+		sandbox := *c.Sandbox
+		sandbox.Mounts = append(append([]runtimeutil.StorageMount{}, sandbox.Mounts...),
+			runtimeutil.StorageMount{MountType: "bind", Src: dir, DstPath: dir, ReadWriteMode: true})
+		f.Sandbox = &sandbox
+	}
+	cmd, cleanup, err := f.command(append(append([]string{}, c.Args...), "--listen", socket))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	defer cleanup()
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, errors.Wrap(err)
+	}
+	// the pipe of stdout is read after Wait, which would close the pipe of
+	// cmd.StdoutPipe
+	stdout, w, err := os.Pipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, errors.Wrap(err)
+	}
+	cmd.Stdout = w
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		stdout.Close()
+		os.RemoveAll(dir)
+		return nil, errors.Wrap(err)
+	}
+	s := &server{cmd: cmd, stdin: stdin, dir: dir}
+
+	address := make(chan string, 1)
+	go func() {
+		r := bufio.NewReader(stdout)
+		line, _ := r.ReadString('\n')
+		address <- line
+		// the function may log to stdout once it has advertised its address
+		_, _ = io.Copy(cmd.Stderr, r)
+		stdout.Close()
+	}()
+	var line string
+	select {
+	case line = <-address:
+	case <-time.After(ServerStartTimeout):
+	}
+	if strings.TrimSpace(line) != framework.AddressPrefix+socket {
+		_ = cmd.Process.Kill()
+		s.close()
+		return nil, errors.Errorf("exec function %s run as a server didn't advertise it listens "+
+			"with a line starting with %q", c.Path, framework.AddressPrefix)
+	}
+	if s.conn, err = dialer.Dial(socket); err != nil {
+		s.close()
+		return nil, errors.WrapPrefixf(err, "connecting to exec function %s", c.Path)
+	}
+	return s, nil
+}
+
+// process returns the ResourceList resulting from the ResourceList in,
+// failing if it takes longer than timeout, or DefaultServerTimeout if
+// timeout isn't positive.
+func (s *server) process(in []byte, timeout time.Duration) ([]byte, error) {
+	if timeout <= 0 {
+		timeout = DefaultServerTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return s.conn.Process(ctx, in)
+}
+
+// close stops the server by closing its stdin, killing it if it doesn't
+// stop by itself within ServerStopTimeout, and removes its socket.  Only
+// the first call stops it.
+func (s *server) close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.stop()
+		os.RemoveAll(s.dir)
+	})
+	return s.closeErr
+}
+
+func (s *server) stop() error {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.stdin.Close()
+	done := make(chan error, 1)
+	go func() { done <- s.cmd.Wait() }()
+	select {
+	case err := <-done:
+		flushLog(s.cmd)
+		if err != nil {
+			return errors.WrapPrefixf(err, "exec function %s run as a server", s.cmd.Path)
+		}
+		return nil
+	case <-time.After(ServerStopTimeout):
+		_ = s.cmd.Process.Kill()
+		return errors.Errorf("exec function %s run as a server didn't stop", s.cmd.Path)
+	}
+}
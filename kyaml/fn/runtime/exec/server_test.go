@@ -0,0 +1,241 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package exec_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// TestHelperServer is the function the tests run as a server, labelling
+// the resources with its pid.  It fails on a resource named bad, exits on
+// one named exit, and hangs on one named hang.  It serves the ResourceLists
+// of the connections to the socket of --listen framed like framework.Serve,
+// rather than with gRPC.
+func TestHelperServer(t *testing.T) {
+	if os.Getenv("EXEC_TEST_SERVER") != "1" {
+		t.Skip("only run as the function of the tests")
+	}
+	p := framework.SimpleProcessor{Filter: kio.FilterFunc(
+		func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+			for _, n := range nodes {
+				switch n.GetName() {
+				case "bad":
+					return nil, errors.Errorf("bad resource")
+				case "exit":
+					os.Exit(3)
+				case "hang":
+					select {}
+				}
+				if err := n.PipeE(yaml.SetLabel("pid", strconv.Itoa(os.Getpid()))); err != nil {
+					return nil, err
+				}
+			}
+			return nodes, nil
+		})}
+	// the arguments of the function follow --
+	var args []string
+	for i, arg := range os.Args {
+		if arg == "--" {
+			args = os.Args[i+1:]
+		}
+	}
+	if len(args) != 2 || args[0] != "--listen" {
+		os.Exit(2)
+	}
+	l, err := net.Listen("unix", args[1])
+	if err != nil {
+		os.Exit(1)
+	}
+	fmt.Printf("%s%s\n", framework.AddressPrefix, args[1])
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() { _ = framework.Serve(p, conn, conn) }()
+		}
+	}()
+	_, _ = io.Copy(io.Discard, os.Stdin)
+	os.Exit(0)
+}
+
+// frameDialer connects to TestHelperServer.
+type frameDialer struct{}
+
+func (frameDialer) Dial(path string) (exec.ServerConn, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return frameConn{conn}, nil
+}
+
+type frameConn struct {
+	net.Conn
+}
+
+func (c frameConn) Process(ctx context.Context, in []byte) ([]byte, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := c.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	if err := framework.WriteFrame(c, in); err != nil {
+		return nil, err
+	}
+	out, err := framework.ReadFrame(c)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(string(out), "severity: error") {
+		return out, &exec.FunctionError{Message: "bad resource"}
+	}
+	return out, nil
+}
+
+func TestFilter_ProtocolServer(t *testing.T) {
+	servers := exec.NewServerPool(frameDialer{})
+	var pids []string
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		instance := exec.Filter{
+			Path:       os.Args[0],
+			Args:       []string{"-test.run=^TestHelperServer$", "--"},
+			Env:        []string{"EXEC_TEST_SERVER=1"},
+			WorkingDir: dir,
+			Protocol:   runtimeutil.ExecProtocolServer,
+			Servers:    servers,
+		}
+		output, err := instance.Filter([]*yaml.RNode{
+			yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n"),
+		})
+		require.NoError(t, err)
+		require.Len(t, output, 1)
+		pids = append(pids, output[0].GetLabels()["pid"])
+	}
+	assert.NotEmpty(t, pids[0])
+	assert.Equal(t, pids[0], pids[1])
+	assert.NoError(t, servers.Close())
+}
+
+// serverFilter returns the filter running TestHelperServer as a server of
+// servers.
+func serverFilter(t *testing.T, servers *exec.ServerPool) *exec.Filter {
+	t.Helper()
+	return &exec.Filter{
+		Path:       os.Args[0],
+		Args:       []string{"-test.run=^TestHelperServer$", "--"},
+		Env:        []string{"EXEC_TEST_SERVER=1"},
+		WorkingDir: t.TempDir(),
+		Protocol:   runtimeutil.ExecProtocolServer,
+		Servers:    servers,
+	}
+}
+
+// configMap returns a ConfigMap named name.
+func configMap(name string) *yaml.RNode {
+	return yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n")
+}
+
+func TestFilter_ProtocolServerErrors(t *testing.T) {
+	servers := exec.NewServerPool(frameDialer{})
+	defer servers.Close()
+	instance := serverFilter(t, servers)
+	pid := func() string {
+		t.Helper()
+		output, err := instance.Filter([]*yaml.RNode{configMap("config")})
+		require.NoError(t, err)
+		require.Len(t, output, 1)
+		return output[0].GetLabels()["pid"]
+	}
+	first := pid()
+
+	// the failures of the function fail the run, but keep the server
+	_, err := instance.Filter([]*yaml.RNode{configMap("bad")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "run as a server: bad resource")
+	assert.Equal(t, first, pid())
+
+	// a server whose connection fails is replaced
+	_, err = instance.Filter([]*yaml.RNode{configMap("exit")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "run as a server")
+	second := pid()
+	assert.NotEqual(t, first, second)
+
+	// like one that hangs, which the default timeout stops
+	defer func(timeout, stop time.Duration) {
+		exec.DefaultServerTimeout, exec.ServerStopTimeout = timeout, stop
+	}(exec.DefaultServerTimeout, exec.ServerStopTimeout)
+	exec.DefaultServerTimeout, exec.ServerStopTimeout = time.Second, time.Second
+	_, err = instance.Filter([]*yaml.RNode{configMap("hang")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "i/o timeout")
+	assert.NotEqual(t, second, pid())
+}
+
+func TestServerPool_startsConcurrently(t *testing.T) {
+	servers := exec.NewServerPool(frameDialer{})
+	defer servers.Close()
+	defer func(timeout time.Duration) { exec.ServerStartTimeout = timeout }(exec.ServerStartTimeout)
+	exec.ServerStartTimeout = 10 * time.Second
+
+	// a function slow to start doesn't hold up the others
+	slow := make(chan error, 1)
+	go func() {
+		_, err := (&exec.Filter{
+			Path:       "sh",
+			Args:       []string{"-c", "sleep 2", "--"},
+			WorkingDir: t.TempDir(),
+			Protocol:   runtimeutil.ExecProtocolServer,
+			Servers:    servers,
+		}).Filter(nil)
+		slow <- err
+	}()
+	_, err := serverFilter(t, servers).Filter([]*yaml.RNode{configMap("config")})
+	require.NoError(t, err)
+	select {
+	case err := <-slow:
+		t.Fatalf("the slow function finished first: %v", err)
+	default:
+	}
+	assert.Error(t, <-slow)
+}
+
+func TestFilter_ProtocolServerNotAdvertised(t *testing.T) {
+	instance := exec.Filter{
+		Path:       "true",
+		WorkingDir: t.TempDir(),
+		Protocol:   runtimeutil.ExecProtocolServer,
+	}
+	_, err := instance.Filter(nil)
+	assert.EqualError(t, err, `exec function true run as a server requires a server pool`)
+
+	instance.Servers = exec.NewServerPool(frameDialer{})
+	_, err = instance.Filter(nil)
+	assert.EqualError(t, err, `exec function true run as a server didn't advertise it listens `+
+		`with a line starting with "krm-function-address: "`)
+
+	instance.Protocol = "grpc"
+	_, err = instance.Filter(nil)
+	assert.EqualError(t, err, `unsupported protocol "grpc" of exec function true, must be stdio or server`)
+}
@@ -0,0 +1,12 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gomodule contains the implementation of functions published as
+// Go modules.
+//
+// A Go function is the path of its main package pinned to a version, as
+// for go run module@version.  The function is built with go install the
+// first time it runs, and its binary is cached by the hash of the module
+// and the platform, so that the next runs don't depend on the go command.
+// Like exec functions, Go functions run unsandboxed on the host.
+package gomodule
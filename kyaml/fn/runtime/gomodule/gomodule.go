@@ -0,0 +1,179 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package gomodule
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	runtimeexec "sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Filter filters resources through a function published as a Go module,
+// which is built once and run from the cache afterward.
+type Filter struct {
+	runtimeutil.GoSpec `json:",inline" yaml:",inline"`
+
+	Exec runtimeexec.Filter
+
+	// CacheDir is the directory caching the built functions.  If empty,
+	// kustomize/go-functions in the cache directory of the user is used.
+	CacheDir string
+}
+
+// versionPattern matches the versions of modules, including pseudo-versions,
+// rather than queries like latest which would make the cache stale.
+var versionPattern = regexp.MustCompile(`^v[0-9]+\.[0-9]+\.[0-9]+([-+][0-9A-Za-z.+-]+)?$`)
+
+// lookPath and goInstall are replaced by tests.
+var (
+	lookPath = exec.LookPath
+
+	// goInstall builds the main package of module with the go command
+	// goCmd, writing the binary to the directory gobin.
+	goInstall = func(goCmd, gobin, module string) error {
+		cmd := exec.Command(goCmd, "install", module)
+		// the module is resolved like by go run module@version, regardless
+		// of the module of the working directory
+		cmd.Dir = gobin
+		cmd.Env = append(os.Environ(), "GOBIN="+gobin, "GOFLAGS=-mod=mod")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return errors.Errorf("go install %s: %v: %s", module, err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+)
+
+func (f Filter) String() string {
+	if f.Exec.DeferFailure {
+		return fmt.Sprintf("%s deferFailure: %v", f.Module, f.Exec.DeferFailure)
+	}
+	return f.Module
+}
+
+func (f Filter) GetExit() error {
+	return f.Exec.GetExit()
+}
+
+func (f Filter) GetResults() *yaml.RNode {
+	return f.Exec.GetResults()
+}
+
+func (f *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	if err := f.setupExec(); err != nil {
+		return nil, err
+	}
+	return f.Exec.Filter(nodes)
+}
+
+func (f *Filter) setupExec() error {
+	// don't init 2x
+	if f.Exec.Path != "" {
+		return nil
+	}
+
+	if err := validateModule(f.Module); err != nil {
+		return err
+	}
+	if f.Exec.WorkingDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		f.Exec.WorkingDir = wd
+	}
+	path, err := f.binary()
+	if err != nil {
+		return err
+	}
+	f.Exec.Path = path
+	return nil
+}
+
+// validateModule returns an error if module isn't a package path pinned
+// to a version.
+func validateModule(module string) error {
+	pkg, version, ok := strings.Cut(module, "@")
+	if pkg == "" || !ok || !versionPattern.MatchString(version) {
+		return errors.Errorf(
+			"go function module %q must be the path of a package pinned to a version, "+
+				"e.g. example.com/functions/set-labels@v1.2.0", module)
+	}
+	return nil
+}
+
+// binary returns the path of the binary of the function, building it into
+// the cache if it isn't there yet.  The binaries are cached by the hash of
+// the module and the platform.
+func (f *Filter) binary() (string, error) {
+	cacheDir := f.CacheDir
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", errors.WrapPrefixf(err, "caching go functions")
+		}
+		cacheDir = filepath.Join(userCacheDir, "kustomize", "go-functions")
+	}
+	sum := sha256.Sum256([]byte(f.Module + "\n" + runtime.GOOS + "/" + runtime.GOARCH))
+	dir := filepath.Join(cacheDir, hex.EncodeToString(sum[:]))
+	if path, err := binaryIn(dir); err == nil {
+		return path, nil
+	}
+
+	goCmd, err := lookPath("go")
+	if err != nil {
+		return "", errors.Errorf("go function %s requires the go command on the PATH", f.Module)
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return "", errors.Wrap(err)
+	}
+	// build into a directory of its own, renamed into place once complete,
+	// so that concurrent builds never run a partial binary
+	buildDir, err := os.MkdirTemp(cacheDir, "build-")
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	defer os.RemoveAll(buildDir)
+	if err := goInstall(goCmd, buildDir, f.Module); err != nil {
+		return "", err
+	}
+	if _, err := binaryIn(buildDir); err != nil {
+		return "", errors.WrapPrefixf(err, "building go function %s", f.Module)
+	}
+	if err := os.Rename(buildDir, dir); err != nil {
+		// a concurrent build may have cached the binary first
+		if path, errB := binaryIn(dir); errB == nil {
+			return path, nil
+		}
+		return "", errors.Wrap(err)
+	}
+	return binaryIn(dir)
+}
+
+// binaryIn returns the path of the binary in dir, which holds only it.
+func binaryIn(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	if len(entries) != 1 || entries[0].IsDir() {
+		return "", errors.Errorf("expected a single binary in %s", dir)
+	}
+	return filepath.Join(dir, entries[0].Name()), nil
+}
+
+// NewGoModule returns a new go module filter
+func NewGoModule(spec runtimeutil.GoSpec) Filter {
+	return Filter{GoSpec: spec}
+}
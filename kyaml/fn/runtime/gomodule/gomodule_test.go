@@ -0,0 +1,107 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package gomodule
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// setGoInstall makes go install write a script echoing its input, and
+// returns the modules it was called for.
+func setGoInstall(t *testing.T) *[]string {
+	t.Helper()
+	var installed []string
+	oldLookPath, oldGoInstall := lookPath, goInstall
+	lookPath = func(file string) (string, error) {
+		return "/usr/bin/" + file, nil
+	}
+	goInstall = func(goCmd, gobin, module string) error {
+		installed = append(installed, module)
+		return os.WriteFile(filepath.Join(gobin, "set-labels"), []byte("#!/bin/sh\ncat\n"), 0o700) //nolint:gosec
+	}
+	t.Cleanup(func() { lookPath, goInstall = oldLookPath, oldGoInstall })
+	return &installed
+}
+
+func TestFilter_setupExec(t *testing.T) {
+	installed := setGoInstall(t)
+	cacheDir := t.TempDir()
+
+	instance := NewGoModule(runtimeutil.GoSpec{Module: "example.com/functions/set-labels@v1.2.0"})
+	instance.CacheDir = cacheDir
+	require.NoError(t, instance.setupExec())
+	assert.Equal(t, cacheDir, filepath.Dir(filepath.Dir(instance.Exec.Path)))
+	assert.Equal(t, "set-labels", filepath.Base(instance.Exec.Path))
+	cached := instance.Exec.Path
+
+	// the binary is built once
+	instance = NewGoModule(runtimeutil.GoSpec{Module: "example.com/functions/set-labels@v1.2.0"})
+	instance.CacheDir = cacheDir
+	require.NoError(t, instance.setupExec())
+	assert.Equal(t, cached, instance.Exec.Path)
+	instance = NewGoModule(runtimeutil.GoSpec{Module: "example.com/functions/set-labels@v1.3.0"})
+	instance.CacheDir = cacheDir
+	require.NoError(t, instance.setupExec())
+	assert.NotEqual(t, cached, instance.Exec.Path)
+	assert.Equal(t, []string{
+		"example.com/functions/set-labels@v1.2.0",
+		"example.com/functions/set-labels@v1.3.0",
+	}, *installed)
+	entries, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestFilter_setupExecErrors(t *testing.T) {
+	setGoInstall(t)
+	for _, module := range []string{
+		"example.com/functions/set-labels",
+		"example.com/functions/set-labels@latest",
+		"example.com/functions/set-labels@",
+		"@v1.2.0",
+	} {
+		instance := NewGoModule(runtimeutil.GoSpec{Module: module})
+		instance.CacheDir = t.TempDir()
+		assert.EqualError(t, instance.setupExec(), "go function module \""+module+
+			"\" must be the path of a package pinned to a version, e.g. example.com/functions/set-labels@v1.2.0")
+	}
+
+	for _, module := range []string{
+		"example.com/functions/set-labels@v0.0.0-20230301120000-abcdef123456",
+		"example.com/functions/set-labels/v2@v2.0.0-rc.1",
+	} {
+		assert.NoError(t, validateModule(module), module)
+	}
+
+	lookPath = func(file string) (string, error) { return "", exec.ErrNotFound }
+	instance := NewGoModule(runtimeutil.GoSpec{Module: "example.com/functions/set-labels@v1.2.0"})
+	instance.CacheDir = t.TempDir()
+	assert.EqualError(t, instance.setupExec(),
+		"go function example.com/functions/set-labels@v1.2.0 requires the go command on the PATH")
+}
+
+func TestFilter_Filter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake function is a shell script")
+	}
+	setGoInstall(t)
+	instance := NewGoModule(runtimeutil.GoSpec{Module: "example.com/functions/set-labels@v1.2.0"})
+	instance.CacheDir = t.TempDir()
+	instance.Exec.FunctionConfig = yaml.MustParse("apiVersion: example.com/v1\nkind: SetLabels\n")
+	input := []*yaml.RNode{yaml.MustParse("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n")}
+	output, err := instance.Filter(input)
+	require.NoError(t, err)
+	require.Len(t, output, 1)
+	assert.Equal(t, "config", output[0].GetName())
+	assert.Equal(t, "example.com/functions/set-labels@v1.2.0", instance.String())
+}
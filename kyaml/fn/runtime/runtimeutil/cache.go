@@ -0,0 +1,85 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runtimeutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// OutputCache caches the outputs of functions, keyed by the function and
+// its input ResourceList, i.e. its functionConfig and items, so that the
+// functions aren't run again for the same input.  It assumes the functions
+// are deterministic: the output of a function reading files or the
+// network which have changed since it was cached is still reused.
+type OutputCache struct {
+	// Dir is the directory of the cached outputs.
+	Dir string
+}
+
+// NewOutputCache returns an OutputCache in dir.  If dir is empty,
+// kustomize/function-outputs in the cache directory of the user is used.
+func NewOutputCache(dir string) (*OutputCache, error) {
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "locating the function output cache")
+		}
+		dir = filepath.Join(userCacheDir, "kustomize", "function-outputs")
+	}
+	return &OutputCache{Dir: dir}, nil
+}
+
+// path returns the file caching the output of the function identified by
+// key for input.
+func (c *OutputCache) path(key string, input []byte) string {
+	h := sha256.New()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write(input)
+	return filepath.Join(c.Dir, hex.EncodeToString(h.Sum(nil)))
+}
+
+// run writes the output of run for input to output, from the cache if run
+// has already succeeded with input.  The outputs of failed runs aren't
+// cached.
+func (c *OutputCache) run(key string, input []byte, output io.Writer,
+	run func(reader io.Reader, writer io.Writer) error) error {
+	path := c.path(key, input)
+	if cached, err := os.ReadFile(path); err == nil {
+		_, err = output.Write(cached)
+		return errors.Wrap(err)
+	}
+	var out bytes.Buffer
+	if err := run(bytes.NewReader(input), io.MultiWriter(output, &out)); err != nil {
+		return err
+	}
+	// the output is written to a temporary file first, so that concurrent
+	// builds never read a partial output; failing to cache it only
+	// loses the optimization
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return nil
+	}
+	f, err := os.CreateTemp(c.Dir, ".output-*")
+	if err != nil {
+		return nil
+	}
+	_, err = f.Write(out.Bytes())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return nil
+}
@@ -5,9 +5,13 @@ package runtimeutil
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"path"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 	k8syaml "sigs.k8s.io/yaml"
@@ -137,10 +141,56 @@ type FunctionSpec struct {
 
 	// ExecSpec is the spec for running a function as an executable
 	Exec ExecSpec `json:"exec,omitempty" yaml:"exec,omitempty"`
+
+	// Wasm is the spec for running a function as a WebAssembly module
+	Wasm WasmSpec `json:"wasm,omitempty" yaml:"wasm,omitempty"`
+
+	// Go is the spec for running a function published as a Go module
+	Go GoSpec `json:"go,omitempty" yaml:"go,omitempty"`
 }
 
 type ExecSpec struct {
 	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Args are the arguments to the executable
+	Args []string `json:"args,omitempty" yaml:"args,omitempty"`
+
+	// Env is the allowlist of the environment variables of the executable,
+	// as KEY=VALUE, or as KEY to take the value given to the runner, e.g. with
+	// --env or --env-file.  If set, the executable inherits only these
+	// variables and PATH, rather than all those of the runner.
+	Env []string `json:"env,omitempty" yaml:"env,omitempty"`
+
+	// Protocol is how the executable receives the ResourceLists: "stdio"
+	// (the default) runs it for each ResourceList, on its stdin and stdout;
+	// "server" runs it once with --listen and the path of a unix socket, as
+	// a gRPC server listening on the socket, and sends the ResourceLists to
+	// it.  See the fngrpc module.
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+
+	// Limits limit the resources of each run of the executable.  Its CPUs
+	// can't be limited.
+	Limits ResourceLimits `json:"limits,omitempty" yaml:"limits,omitempty"`
+}
+
+// The protocols of exec functions.
+const (
+	ExecProtocolStdio  = "stdio"
+	ExecProtocolServer = "server"
+)
+
+// WasmSpec defines how to run a function as a WebAssembly module
+type WasmSpec struct {
+	// Path specifies a path to a .wasm module built for WASI, which reads
+	// the ResourceList from stdin and writes it to stdout
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// GoSpec defines how to run a function published as a Go module
+type GoSpec struct {
+	// Module is the path of the main package of the function, pinned to a
+	// version, e.g. example.com/functions/set-labels@v1.2.0
+	Module string `json:"module,omitempty" yaml:"module,omitempty"`
 }
 
 // ContainerSpec defines a spec for running a function as a container
@@ -151,13 +201,115 @@ type ContainerSpec struct {
 	// Network defines network specific configuration
 	Network bool `json:"network,omitempty" yaml:"network,omitempty"`
 
+	// AllowedHosts restricts the network access of a function declaring
+	// Network to these hosts, e.g. api.example.com, *.example.com or
+	// api.example.com:443.  The function then reaches them through an HTTP
+	// proxy, and can't reach any other host.
+	AllowedHosts []string `json:"allowedHosts,omitempty" yaml:"allowedHosts,omitempty"`
+
 	// Mounts are the storage or directories to mount into the container
 	StorageMounts []StorageMount `json:"mounts,omitempty" yaml:"mounts,omitempty"`
 
 	// Env is a slice of env string that will be exposed to container
 	Env []string `json:"envs,omitempty" yaml:"envs,omitempty"`
+
+	// ImagePullPolicy is when to pull the image, one of ImagePullPolicies.
+	// If empty, the default policy of the container runtime is used.
+	ImagePullPolicy string `json:"imagePullPolicy,omitempty" yaml:"imagePullPolicy,omitempty"`
+
+	// Limits limit the resources of each run of the container.
+	Limits ResourceLimits `json:"limits,omitempty" yaml:"limits,omitempty"`
+}
+
+// ResourceLimits limit the resources of a run of a function, so that a
+// misbehaving function can't hang the build or exhaust the host.  The
+// empty fields don't limit anything.
+type ResourceLimits struct {
+	// Timeout is the wall-clock time a run may take, as a duration such as
+	// 30s or 5m, after which the function is killed.  0 disables the
+	// default timeout.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// CPU is the number of CPUs the function may use, e.g. 0.5 or 2.
+	CPU string `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+
+	// Memory is the memory the function may use, in bytes or with one of
+	// the suffixes k, m and g, e.g. 512m.
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+}
+
+// WithDefaults returns l with its empty fields set from defaults.
+func (l ResourceLimits) WithDefaults(defaults ResourceLimits) ResourceLimits {
+	if l.Timeout == "" {
+		l.Timeout = defaults.Timeout
+	}
+	if l.CPU == "" {
+		l.CPU = defaults.CPU
+	}
+	if l.Memory == "" {
+		l.Memory = defaults.Memory
+	}
+	return l
+}
+
+// Validate returns an error if one of the limits can't be parsed.
+func (l ResourceLimits) Validate() error {
+	if _, err := l.TimeoutDuration(); err != nil {
+		return err
+	}
+	if l.CPU != "" {
+		if cpu, err := strconv.ParseFloat(l.CPU, 64); err != nil || cpu <= 0 {
+			return fmt.Errorf("invalid cpu limit %q, must be a positive number of CPUs", l.CPU)
+		}
+	}
+	_, err := l.MemoryBytes()
+	return err
+}
+
+// TimeoutDuration returns the timeout, or 0 if there is none.
+func (l ResourceLimits) TimeoutDuration() (time.Duration, error) {
+	if l.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(l.Timeout)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q, must be a duration such as 30s or 5m", l.Timeout)
+	}
+	return d, nil
+}
+
+// memoryUnits are the multipliers of the suffixes of the memory limits.
+var memoryUnits = map[byte]int64{'b': 1, 'k': 1 << 10, 'm': 1 << 20, 'g': 1 << 30}
+
+// MemoryBytes returns the memory limit in bytes, or 0 if there is none.
+func (l ResourceLimits) MemoryBytes() (int64, error) {
+	if l.Memory == "" {
+		return 0, nil
+	}
+	digits, unit := l.Memory, int64(1)
+	if u, ok := memoryUnits[strings.ToLower(l.Memory[len(l.Memory)-1:])[0]]; ok {
+		digits, unit = l.Memory[:len(l.Memory)-1], u
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n <= 0 || n > math.MaxInt64/unit {
+		return 0, fmt.Errorf("invalid memory limit %q, must be a positive number of bytes "+
+			"with an optional suffix k, m or g", l.Memory)
+	}
+	return n * unit, nil
 }
 
+const (
+	// PullAlways pulls the image before each run of the function.
+	PullAlways = "Always"
+	// PullIfNotPresent pulls the image only if it isn't present locally.
+	PullIfNotPresent = "IfNotPresent"
+	// PullNever never pulls the image, which must be present locally.
+	PullNever = "Never"
+)
+
+// ImagePullPolicies are the supported image pull policies.
+var ImagePullPolicies = []string{PullAlways, PullIfNotPresent, PullNever}
+
 // StarlarkSpec defines how to run a function as a starlark program
 type StarlarkSpec struct {
 	Name string `json:"name,omitempty" yaml:"name,omitempty"`
@@ -171,16 +323,18 @@ type StarlarkSpec struct {
 
 // StorageMount represents a container's mounted storage option(s)
 type StorageMount struct {
-	// Type of mount e.g. bind mount, local volume, etc.
+	// Type of mount, one of MountTypes: bind, volume or tmpfs.
 	MountType string `json:"type,omitempty" yaml:"type,omitempty"`
 
 	// Source for the storage to be mounted.
 	// For named volumes, this is the name of the volume.
 	// For anonymous volumes, this field is omitted (empty string).
-	// For bind mounts, this is the path to the file or directory on the host.
+	// For bind mounts, this is the path to the file or directory on the host,
+	// relative to the kustomization root in kustomize builds.
+	// For tmpfs mounts, this field is omitted.
 	Src string `json:"src,omitempty" yaml:"src,omitempty"`
 
-	// The path where the file or directory is mounted in the container.
+	// The absolute path where the file or directory is mounted in the container.
 	DstPath string `json:"dst,omitempty" yaml:"dst,omitempty"`
 
 	// Mount in ReadWrite mode if it's explicitly configured
@@ -188,6 +342,48 @@ type StorageMount struct {
 	ReadWriteMode bool `json:"rw,omitempty" yaml:"rw,omitempty"`
 }
 
+// The supported types of mounts.
+const (
+	// MountTypeBind mounts a file or directory of the host, relative to
+	// the directory of the function config, e.g. the kustomization root.
+	MountTypeBind = "bind"
+	// MountTypeVolume mounts a volume of the container runtime.
+	MountTypeVolume = "volume"
+	// MountTypeTmpfs mounts an empty temporary file system.
+	MountTypeTmpfs = "tmpfs"
+)
+
+// MountTypes are the supported types of mounts.
+var MountTypes = []string{MountTypeBind, MountTypeVolume, MountTypeTmpfs}
+
+// Validate returns an error if the mount isn't of a supported type, lacks
+// the fields of its type, or has fields which can't be passed safely to
+// the container runtime.
+func (s *StorageMount) Validate() error {
+	switch s.MountType {
+	case MountTypeBind:
+		if s.Src == "" {
+			return fmt.Errorf("bind mount to %q has no src", s.DstPath)
+		}
+	case MountTypeVolume:
+	case MountTypeTmpfs:
+		if s.Src != "" {
+			return fmt.Errorf("tmpfs mount to %q can't have a src", s.DstPath)
+		}
+	default:
+		return fmt.Errorf("mount to %q has the unsupported type %q, must be one of %s",
+			s.DstPath, s.MountType, strings.Join(MountTypes, ", "))
+	}
+	if !path.IsAbs(s.DstPath) {
+		return fmt.Errorf("mount dst %q must be an absolute path in the container", s.DstPath)
+	}
+	// the fields are passed to the runtime as a comma separated list
+	if strings.Contains(s.Src, ",") || strings.Contains(s.DstPath, ",") {
+		return fmt.Errorf("mount %s can't have a comma in its src or dst", s.String())
+	}
+	return nil
+}
+
 func (s *StorageMount) String() string {
 	mode := ""
 	if !s.ReadWriteMode {
@@ -254,6 +450,47 @@ func getFunctionSpecFromAnnotation(n *yaml.RNode, meta yaml.ResourceMeta) (*Func
 	return &fs, nil
 }
 
+// ParseStorageMount parses a mount like the --mount flag of docker run,
+// e.g. type=bind,src=charts,dst=/charts,rw=true, and validates it.  Unlike
+// StringToStorageMount, it fails on the options it doesn't support.
+func ParseStorageMount(s string) (StorageMount, error) {
+	var sm StorageMount
+	readonly := false
+	for _, option := range strings.Split(s, ",") {
+		key, value, hasValue := strings.Cut(option, "=")
+		switch key {
+		case "type":
+			sm.MountType = value
+		case "src", "source":
+			sm.Src = value
+		case "dst", "target", "destination":
+			sm.DstPath = value
+		case "rw", "readonly", "ro":
+			b := true
+			if hasValue {
+				var err error
+				if b, err = strconv.ParseBool(value); err != nil {
+					return StorageMount{}, fmt.Errorf("invalid mount %q: option %s must be true or false", s, key)
+				}
+			}
+			if key == "rw" {
+				sm.ReadWriteMode = b
+			} else {
+				readonly = b
+			}
+		default:
+			return StorageMount{}, fmt.Errorf("invalid mount %q: unsupported option %q", s, key)
+		}
+	}
+	if readonly && sm.ReadWriteMode {
+		return StorageMount{}, fmt.Errorf("invalid mount %q: it can't be both readonly and rw", s)
+	}
+	if err := sm.Validate(); err != nil {
+		return StorageMount{}, fmt.Errorf("invalid mount %q: %w", s, err)
+	}
+	return sm, nil
+}
+
 func StringToStorageMount(s string) StorageMount {
 	m := make(map[string]string)
 	options := strings.Split(s, ",")
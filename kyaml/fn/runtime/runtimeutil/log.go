@@ -0,0 +1,123 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runtimeutil
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The levels of the LogRecords, from the least to the most severe.
+const (
+	LogLevelDebug   = "debug"
+	LogLevelInfo    = "info"
+	LogLevelWarning = "warning"
+	LogLevelError   = "error"
+)
+
+// LogLevels are the levels of the LogRecords, ordered by severity.
+var LogLevels = []string{LogLevelDebug, LogLevelInfo, LogLevelWarning, LogLevelError}
+
+// logLevelPrefixes are the prefixes of the lines of a function's stderr
+// declaring their level, as written by common logging libraries.
+var logLevelPrefixes = map[string]string{
+	"debug":   LogLevelDebug,
+	"trace":   LogLevelDebug,
+	"info":    LogLevelInfo,
+	"warn":    LogLevelWarning,
+	"warning": LogLevelWarning,
+	"error":   LogLevelError,
+	"fatal":   LogLevelError,
+}
+
+// LogRecord is a line a function wrote to its stderr.
+type LogRecord struct {
+	// Function identifies the function, e.g. by its image or path.
+	Function string `json:"function,omitempty" yaml:"function,omitempty"`
+
+	// Time is when the function wrote the line.
+	Time time.Time `json:"time" yaml:"time"`
+
+	// Level is one of LogLevels, parsed from a prefix of the line such as
+	// "WARN:" or "[error]", and LogLevelInfo if the line has none.
+	Level string `json:"level" yaml:"level"`
+
+	// Message is the line without its level prefix.
+	Message string `json:"message" yaml:"message"`
+}
+
+// ParseLogLine returns the LogRecord of a line a function wrote to its
+// stderr, without its Function and Time.
+func ParseLogLine(line string) LogRecord {
+	line = strings.TrimRight(line, "\r\n")
+	trimmed := strings.TrimSpace(line)
+	var prefix, rest string
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		if i := strings.Index(trimmed, "]"); i > 0 {
+			prefix, rest = trimmed[1:i], trimmed[i+1:]
+		}
+	default:
+		if i := strings.Index(trimmed, ":"); i > 0 {
+			prefix, rest = trimmed[:i], trimmed[i+1:]
+		}
+	}
+	if level, ok := logLevelPrefixes[strings.ToLower(prefix)]; ok {
+		return LogRecord{Level: level, Message: strings.TrimSpace(rest)}
+	}
+	return LogRecord{Level: LogLevelInfo, Message: line}
+}
+
+// LogWriter is the stderr of a function, calling Log with a LogRecord for
+// each line written to it.
+type LogWriter struct {
+	// Log is called with the LogRecord of each line.
+	Log func(LogRecord)
+
+	mu      sync.Mutex
+	partial []byte
+}
+
+// NewLogWriter returns a LogWriter calling log.
+func NewLogWriter(log func(LogRecord)) *LogWriter {
+	return &LogWriter{Log: log}
+}
+
+// Write logs the complete lines of p, keeping the last partial line until
+// it's completed or flushed.
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs the partial line, e.g. once the function has exited.
+func (w *LogWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		w.log(string(w.partial))
+		w.partial = nil
+	}
+}
+
+func (w *LogWriter) log(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	record := ParseLogLine(line)
+	record.Time = time.Now()
+	w.Log(record)
+}
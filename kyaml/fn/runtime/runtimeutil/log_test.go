@@ -0,0 +1,40 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runtimeutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogLine(t *testing.T) {
+	for line, expected := range map[string]LogRecord{
+		"WARN: deprecated field":      {Level: LogLevelWarning, Message: "deprecated field"},
+		"[error] no team":             {Level: LogLevelError, Message: "no team"},
+		"debug:reading config\n":      {Level: LogLevelDebug, Message: "reading config"},
+		"setting the team":            {Level: LogLevelInfo, Message: "setting the team"},
+		"team: platform":              {Level: LogLevelInfo, Message: "team: platform"},
+		"  indented, without a level": {Level: LogLevelInfo, Message: "  indented, without a level"},
+	} {
+		assert.Equal(t, expected, ParseLogLine(line), line)
+	}
+}
+
+func TestLogWriter(t *testing.T) {
+	var records []LogRecord
+	w := NewLogWriter(func(r LogRecord) { records = append(records, r) })
+	_, err := w.Write([]byte("INFO: start"))
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+	_, err = w.Write([]byte("ing\n\nWARN: careful\nunterminated"))
+	assert.NoError(t, err)
+	w.Flush()
+	if assert.Len(t, records, 3) {
+		assert.Equal(t, "starting", records[0].Message)
+		assert.Equal(t, LogLevelWarning, records[1].Level)
+		assert.Equal(t, "unterminated", records[2].Message)
+		assert.False(t, records[2].Time.IsZero())
+	}
+}
@@ -42,6 +42,20 @@ type FunctionFilter struct {
 	// The Run error will be available through GetExit().
 	DeferFailure bool
 
+	// Cache, if set, caches the outputs of Run, keyed by CacheKey and the
+	// input ResourceList.  Run isn't run again for an input it has already
+	// succeeded with.
+	Cache *OutputCache
+
+	// CacheKey identifies the function in the Cache, e.g. by the digests of
+	// its image or executable and its arguments.  The outputs of Run aren't
+	// cached if it's empty.
+	CacheKey string
+
+	// Log, if set, is called with the lines the function writes to its
+	// stderr, which aren't written to the stderr of the current process.
+	Log func(LogRecord)
+
 	// results saves the results emitted from Run
 	Results *yaml.RNode
 
@@ -56,6 +70,11 @@ func (c FunctionFilter) GetExit() error {
 	return c.exit
 }
 
+// GetResults returns the results emitted from Run, or nil
+func (c FunctionFilter) GetResults() *yaml.RNode {
+	return c.Results
+}
+
 // functionsDirectoryName is keyword directory name for functions scoped 1 directory higher
 const functionsDirectoryName = "functions"
 
@@ -173,7 +192,11 @@ func (c *FunctionFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	r := &kio.ByteReader{Reader: out}
 
 	// don't exit immediately if the function fails -- write out the validation
-	c.exit = c.Run(in, out)
+	if c.Cache != nil && c.CacheKey != "" {
+		c.exit = c.Cache.run(c.CacheKey, in.Bytes(), out, c.Run)
+	} else {
+		c.exit = c.Run(in, out)
+	}
 
 	output, err := r.Read()
 	if err != nil {
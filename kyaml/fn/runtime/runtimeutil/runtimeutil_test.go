@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -1241,6 +1242,25 @@ container:
 `,
 		},
 
+		{
+			name: "image pinned to a digest with pull policy",
+			resource: `
+apiVersion: v1beta1
+kind: Example
+metadata:
+  annotations:
+    config.kubernetes.io/function: |-
+      container:
+        image: foo@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+        imagePullPolicy: IfNotPresent
+`,
+			expectedFn: `
+container:
+  image: foo@sha256:24a0c4b4a4c0eb97a1aabb8e29f18e917d05abfe1b7a7c07857230879ce7d3d3
+  imagePullPolicy: IfNotPresent
+`,
+		},
+
 		{
 			name: "path with uncorrect position",
 			resource: `
@@ -1458,6 +1478,76 @@ func Test_StringToStorageMount(t *testing.T) {
 	}
 }
 
+func TestParseStorageMount(t *testing.T) {
+	tests := []struct {
+		in          string
+		expectedOut string
+		expectedErr string
+	}{
+		{
+			in:          "type=bind,src=charts,dst=/charts",
+			expectedOut: "type=bind,source=charts,target=/charts,readonly",
+		},
+		{
+			in:          "type=bind,source=charts,destination=/charts,rw",
+			expectedOut: "type=bind,source=charts,target=/charts",
+		},
+		{
+			in:          "type=tmpfs,dst=/tmp,readonly=true",
+			expectedOut: "type=tmpfs,source=,target=/tmp,readonly",
+		},
+		{
+			in:          "type=volume,src=cache,dst=/cache,rw=true",
+			expectedOut: "type=volume,source=cache,target=/cache",
+		},
+		{
+			in:          "type=bind,src=charts,dst=/charts,rwe=true",
+			expectedErr: `invalid mount "type=bind,src=charts,dst=/charts,rwe=true": unsupported option "rwe"`,
+		},
+		{
+			in:          "type=bind,src=charts,dst=/charts,rw=invalid",
+			expectedErr: `invalid mount "type=bind,src=charts,dst=/charts,rw=invalid": option rw must be true or false`,
+		},
+		{
+			in:          "type=bind,src=charts,dst=/charts,rw,ro",
+			expectedErr: `invalid mount "type=bind,src=charts,dst=/charts,rw,ro": it can't be both readonly and rw`,
+		},
+		{
+			in:          "type=tmpfs,src=/tmp/test/,dst=/tmp",
+			expectedErr: `invalid mount "type=tmpfs,src=/tmp/test/,dst=/tmp": tmpfs mount to "/tmp" can't have a src`,
+		},
+		{
+			in:          "type=bind,dst=/charts",
+			expectedErr: `invalid mount "type=bind,dst=/charts": bind mount to "/charts" has no src`,
+		},
+		{
+			in:          "type=bind,src=charts,dst=charts",
+			expectedErr: `invalid mount "type=bind,src=charts,dst=charts": mount dst "charts" must be an absolute path in the container`,
+		},
+		{
+			in: "src=charts,dst=/charts",
+			expectedErr: `invalid mount "src=charts,dst=/charts": mount to "/charts" has the unsupported type "", ` +
+				`must be one of bind, volume, tmpfs`,
+		},
+	}
+
+	for _, tc := range tests {
+		s, err := ParseStorageMount(tc.in)
+		if tc.expectedErr != "" {
+			assert.EqualError(t, err, tc.expectedErr)
+			continue
+		}
+		if assert.NoError(t, err, tc.in) {
+			assert.Equal(t, tc.expectedOut, s.String())
+		}
+	}
+
+	// commas would split the options passed to the runtime
+	s := StorageMount{MountType: MountTypeBind, Src: "a,type=volume", DstPath: "/a"}
+	assert.EqualError(t, s.Validate(),
+		"mount type=bind,source=a,type=volume,target=/a,readonly can't have a comma in its src or dst")
+}
+
 func TestContainerEnvGetDockerFlags(t *testing.T) {
 	tests := []struct {
 		input  *ContainerEnv
@@ -1549,3 +1639,37 @@ metadata:
 		assert.Equal(t, tc.expected, *NewContainerEnvFromStringSlice(fn.Container.Env))
 	}
 }
+
+func TestResourceLimits(t *testing.T) {
+	limits := ResourceLimits{Memory: "1g"}.WithDefaults(ResourceLimits{Timeout: "10m", Memory: "512m"})
+	assert.Equal(t, ResourceLimits{Timeout: "10m", Memory: "1g"}, limits)
+	timeout, err := limits.TimeoutDuration()
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, timeout)
+	memory, err := limits.MemoryBytes()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1<<30), memory)
+
+	// 0 disables the default timeout
+	timeout, err = ResourceLimits{Timeout: "0"}.WithDefaults(limits).TimeoutDuration()
+	assert.NoError(t, err)
+	assert.Zero(t, timeout)
+
+	for limits, expectedErr := range map[ResourceLimits]string{
+		{Timeout: "10"}:    `invalid timeout "10", must be a duration such as 30s or 5m`,
+		{Timeout: "-1s"}:   `invalid timeout "-1s", must be a duration such as 30s or 5m`,
+		{CPU: "0"}:         `invalid cpu limit "0", must be a positive number of CPUs`,
+		{Memory: "512mb"}:  `invalid memory limit "512mb", must be a positive number of bytes with an optional suffix k, m or g`,
+		{Memory: "m"}:      `invalid memory limit "m", must be a positive number of bytes with an optional suffix k, m or g`,
+		{Memory: "1024"}:   "",
+		{CPU: "1.5"}:       "",
+		{Timeout: "1h30m"}: "",
+	} {
+		err := limits.Validate()
+		if expectedErr == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, expectedErr)
+		}
+	}
+}
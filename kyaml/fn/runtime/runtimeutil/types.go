@@ -3,6 +3,14 @@
 
 package runtimeutil
 
+import "sigs.k8s.io/kustomize/kyaml/yaml"
+
 type DeferFailureFunction interface {
 	GetExit() error
 }
+
+// ResultsFunction is a function keeping the results field of the
+// ResourceList it emitted.
+type ResultsFunction interface {
+	GetResults() *yaml.RNode
+}
@@ -31,6 +31,10 @@
 // The items in the resourceList respect the io spec specified by:
 // https://github.com/kubernetes-sigs/kustomize/blob/master/cmd/config/docs/api-conventions/config-io.md
 //
+// Programs read from a path may load helper modules with load statements, e.g.
+// load("lib/helpers.star", "annotate"). The modules are relative to the directory
+// of the loading module, are run once and may load further modules.
+//
 // The starlark language spec can be found here:
 // https://github.com/google/starlark-go/blob/master/doc/spec.md
 package starlark
@@ -0,0 +1,90 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package starlark
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// modulePathLocal is the thread local holding the path of the module
+// executed by the thread, which its load statements are relative to.
+const modulePathLocal = "kustomize.module.path"
+
+// moduleLoader loads the modules of the load statements of a program,
+// executing each module once.
+type moduleLoader struct {
+	read        func(path string) ([]byte, error)
+	predeclared starlark.StringDict
+
+	// modules maps the path of a module to its globals, which are nil
+	// while the module is being loaded
+	modules map[string]*starlark.StringDict
+}
+
+// newModuleLoader returns a loader of the modules of sf, predeclaring
+// predeclared in them like in the program.
+func (sf *Filter) newModuleLoader(predeclared starlark.StringDict) *moduleLoader {
+	read := sf.ReadModule
+	if read == nil {
+		read = os.ReadFile
+	}
+	l := &moduleLoader{
+		read:        read,
+		predeclared: predeclared,
+		modules:     map[string]*starlark.StringDict{},
+	}
+	if sf.Path != "" {
+		// the program is loading until it's done
+		l.modules[filepath.Clean(sf.Path)] = nil
+	}
+	return l
+}
+
+// newThread returns a thread executing the module at modulePath, which
+// is empty for a program which isn't read from a file.
+func (l *moduleLoader) newThread(name, modulePath string) *starlark.Thread {
+	thread := &starlark.Thread{Name: name, Load: l.load}
+	thread.SetLocal(modulePathLocal, modulePath)
+	return thread
+}
+
+// load is the Load function of the threads, loading module relative to
+// the directory of the module executed by thread.  Starlark prefixes its
+// errors with the module.
+func (l *moduleLoader) load(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	loading, _ := thread.Local(modulePathLocal).(string)
+	if loading == "" {
+		return nil, errors.Errorf("only programs read from a path can load modules")
+	}
+	if module == "" || filepath.IsAbs(module) || path.IsAbs(module) {
+		return nil, errors.Errorf("modules must be relative to the loading module")
+	}
+	p := filepath.Join(filepath.Dir(loading), filepath.FromSlash(module))
+
+	globals, found := l.modules[p]
+	if found && globals == nil {
+		return nil, errors.Errorf("cycle in the load statements")
+	}
+	if found {
+		return *globals, nil
+	}
+	l.modules[p] = nil
+	b, err := l.read(p)
+	if err != nil {
+		delete(l.modules, p)
+		return nil, err
+	}
+	g, err := starlark.ExecFile(l.newThread(thread.Name, p), p, b, l.predeclared)
+	if err != nil {
+		delete(l.modules, p)
+		return nil, err
+	}
+	l.modules[p] = &g
+	return g, nil
+}
@@ -31,6 +31,11 @@ type Filter struct {
 	// Path is the path to a starlark program to read and run
 	Path string
 
+	// ReadModule reads the modules the program loads with load statements,
+	// which are relative to the directory of the loading module and only
+	// supported by programs read from Path.  Defaults to os.ReadFile.
+	ReadModule func(path string) ([]byte, error)
+
 	runtimeutil.FunctionFilter
 }
 
@@ -95,14 +100,14 @@ func (sf *Filter) Run(reader io.Reader, writer io.Writer) error {
 		return errors.Wrap(err)
 	}
 
-	// run the starlark as program as transformation function
-	thread := &starlark.Thread{Name: sf.Name}
-
 	ctx := &Context{resourceList: value}
 	pd, err := ctx.predeclared()
 	if err != nil {
 		return errors.Wrap(err)
 	}
+
+	// run the starlark as program as transformation function
+	thread := sf.newModuleLoader(pd).newThread(sf.Name, sf.Path)
 	_, err = starlark.ExecFile(thread, sf.Name, sf.Program, pd)
 	if err != nil {
 		return errors.Wrap(err)
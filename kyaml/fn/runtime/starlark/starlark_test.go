@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -535,3 +536,70 @@ spec:
 		})
 	}
 }
+
+func TestFilter_Filter_load(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.star": `
+load("lib/annotations.star", "annotate")
+load("lib/annotations.star", "VALUE")
+annotate(ctx.resource_list["items"], VALUE)
+`,
+		"lib/annotations.star": `
+load("values.star", _value = "VALUE")
+VALUE = _value
+def annotate(items, value):
+  for resource in items:
+    resource["metadata"]["annotations"]["foo"] = value
+`,
+		"lib/values.star": `VALUE = "bar"`,
+		"cycle.star":      `load("lib/cycle.star", "x")`,
+		"lib/cycle.star":  `load("../cycle.star", "x")`,
+		"absolute.star":   `load("/lib/values.star", "VALUE")`,
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	input := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`
+	run := func(f *Filter) (string, error) {
+		o := &bytes.Buffer{}
+		err := kio.Pipeline{
+			Inputs:  []kio.Reader{&kio.ByteReader{Reader: bytes.NewBufferString(input)}},
+			Filters: []kio.Filter{f},
+			Outputs: []kio.Writer{&kio.ByteWriter{Writer: o}},
+		}.Execute()
+		return o.String(), err
+	}
+
+	var read []string
+	out, err := run(&Filter{Name: "main", Path: filepath.Join(dir, "main.star"),
+		ReadModule: func(path string) ([]byte, error) {
+			read = append(read, path)
+			return os.ReadFile(path)
+		}})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, out, "foo: bar")
+	// each module is read once
+	assert.Equal(t, []string{
+		filepath.Join(dir, "lib", "annotations.star"),
+		filepath.Join(dir, "lib", "values.star"),
+	}, read)
+
+	_, err = run(&Filter{Name: "cycle", Path: filepath.Join(dir, "cycle.star")})
+	assert.ErrorContains(t, err, "cannot load ../cycle.star: cycle in the load statements")
+	_, err = run(&Filter{Name: "absolute", Path: filepath.Join(dir, "absolute.star")})
+	assert.ErrorContains(t, err, "cannot load /lib/values.star: modules must be relative to the loading module")
+	_, err = run(&Filter{Name: "inline", Program: files["main.star"]})
+	assert.ErrorContains(t, err, "only programs read from a path can load modules")
+	_, err = run(&Filter{Name: "missing", Path: filepath.Join(dir, "lib", "cycle.star"),
+		ReadModule: func(string) ([]byte, error) { return nil, fmt.Errorf("not allowed") }})
+	assert.ErrorContains(t, err, "cannot load ../cycle.star: not allowed")
+}
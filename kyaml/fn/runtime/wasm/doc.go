@@ -0,0 +1,12 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wasm contains the WebAssembly function implementation.
+//
+// WebAssembly functions are .wasm modules built for WASI, which read the
+// ResourceList from stdin and write the result to stdout like the other
+// functions.  They are run with a WebAssembly runtime CLI, wasmtime or
+// wazero, which is all they depend on.  The modules are sandboxed: they
+// have no access to the filesystem or the network, and only see the
+// environment variables set for them.
+package wasm
@@ -0,0 +1,159 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	runtimeexec "sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// Filter filters resources through a WebAssembly module.
+type Filter struct {
+	runtimeutil.WasmSpec `json:",inline" yaml:",inline"`
+
+	Exec runtimeexec.Filter
+
+	// Runtime is the WebAssembly runtime CLI used to run the module, one of
+	// Runtimes. If empty, the first runtime of Runtimes found on the PATH is used.
+	Runtime string
+
+	// Env are environment variables set for the module, as KEY=VALUE, or
+	// as KEY to export the variable of the current process.
+	Env []string
+}
+
+const (
+	RuntimeWasmtime = "wasmtime"
+	RuntimeWazero   = "wazero"
+)
+
+// Runtimes are the supported WebAssembly runtimes, in the order of
+// preference of autodetection.
+var Runtimes = []string{RuntimeWasmtime, RuntimeWazero}
+
+// envFlags are the flags of the runtimes setting an environment variable.
+var envFlags = map[string]string{
+	RuntimeWasmtime: "--env",
+	RuntimeWazero:   "-env",
+}
+
+// lookPath is replaced by tests.
+var lookPath = exec.LookPath
+
+// DetectRuntime returns the first runtime of Runtimes found on the PATH,
+// or the empty string if none is found.
+func DetectRuntime() string {
+	for _, runtime := range Runtimes {
+		if _, err := lookPath(runtime); err == nil {
+			return runtime
+		}
+	}
+	return ""
+}
+
+func (f Filter) String() string {
+	if f.Exec.DeferFailure {
+		return fmt.Sprintf("%s deferFailure: %v", f.Path, f.Exec.DeferFailure)
+	}
+	return f.Path
+}
+
+func (f Filter) GetExit() error {
+	return f.Exec.GetExit()
+}
+
+func (f Filter) GetResults() *yaml.RNode {
+	return f.Exec.GetResults()
+}
+
+func (f *Filter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	if err := f.setupExec(); err != nil {
+		return nil, err
+	}
+	return f.Exec.Filter(nodes)
+}
+
+func (f *Filter) setupExec() error {
+	// don't init 2x
+	if f.Exec.Path != "" {
+		return nil
+	}
+
+	if f.Path == "" {
+		return errors.Errorf("no path set for wasm function")
+	}
+	if f.Exec.WorkingDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		f.Exec.WorkingDir = wd
+	}
+	if f.Runtime == "" {
+		if f.Runtime = DetectRuntime(); f.Runtime == "" {
+			return errors.Errorf(
+				"no wasm runtime found to run %s: install one of %v on the PATH", f.Path, Runtimes)
+		}
+	}
+	if _, ok := envFlags[f.Runtime]; !ok {
+		return errors.Errorf("unsupported wasm runtime %q, must be one of %v", f.Runtime, Runtimes)
+	}
+	// the allowlist of the executables restricts the modules too, while
+	// the exec filter checks the runtime
+	if f.Exec.Allowlist != nil {
+		path := f.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(f.Exec.WorkingDir, path)
+		}
+		if err := runtimeexec.CheckAllowed(path, f.Exec.Allowlist); err != nil {
+			return err
+		}
+	}
+
+	f.Exec.Path, f.Exec.Args = f.getCommand()
+	return nil
+}
+
+// getCommand returns the command running the module with the runtime.
+// The runtime preopens no directories and grants no network access, so
+// the module only reads stdin and writes stdout and stderr.
+func (f *Filter) getCommand() (string, []string) {
+	args := []string{"run"}
+	for _, e := range f.env() {
+		args = append(args, envFlags[f.Runtime], e)
+	}
+	// relative paths are relative to the working directory, which the
+	// runtime runs in
+	return f.Runtime, append(args, f.Path)
+}
+
+// env returns the environment variables of the module as KEY=VALUE,
+// defaulting the variables of the function runtimes like for containers.
+func (f *Filter) env() []string {
+	ce := runtimeutil.NewContainerEnvFromStringSlice(f.Env)
+	var env []string
+	for k, v := range ce.EnvVars {
+		env = append(env, k+"="+v)
+	}
+	for _, k := range ce.VarsToExport {
+		if v, ok := os.LookupEnv(k); ok {
+			env = append(env, k+"="+v)
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+// NewWasm returns a new wasm filter
+func NewWasm(spec runtimeutil.WasmSpec) Filter {
+	return Filter{WasmSpec: spec}
+}
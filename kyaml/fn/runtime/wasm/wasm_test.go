@@ -0,0 +1,127 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	runtimeexec "sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func setLookPath(t *testing.T, installed ...string) {
+	t.Helper()
+	oldLookPath := lookPath
+	lookPath = func(file string) (string, error) {
+		for _, runtime := range installed {
+			if file == runtime {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	t.Cleanup(func() { lookPath = oldLookPath })
+}
+
+func TestDetectRuntime(t *testing.T) {
+	setLookPath(t)
+	assert.Equal(t, "", DetectRuntime())
+	setLookPath(t, RuntimeWazero)
+	assert.Equal(t, RuntimeWazero, DetectRuntime())
+	setLookPath(t, RuntimeWazero, RuntimeWasmtime)
+	assert.Equal(t, RuntimeWasmtime, DetectRuntime())
+}
+
+func TestFilter_setupExec(t *testing.T) {
+	setLookPath(t, RuntimeWazero)
+	t.Setenv("KUSTOMIZE_WASM_TEST", "exported")
+
+	instance := NewWasm(runtimeutil.WasmSpec{Path: "fn/validate.wasm"})
+	instance.Env = []string{"FOO=bar", "KUSTOMIZE_WASM_TEST", "KUSTOMIZE_WASM_UNSET"}
+	instance.Exec.WorkingDir = "/kustomization"
+	require.NoError(t, instance.setupExec())
+	assert.Equal(t, "wazero", instance.Exec.Path)
+	assert.Equal(t, []string{
+		"run",
+		"-env", "FOO=bar",
+		"-env", "KUSTOMIZE_WASM_TEST=exported",
+		"-env", "LOG_TO_STDERR=true",
+		"-env", "STRUCTURED_RESULTS=true",
+		"fn/validate.wasm",
+	}, instance.Exec.Args)
+
+	instance = NewWasm(runtimeutil.WasmSpec{Path: "validate.wasm"})
+	instance.Runtime = RuntimeWasmtime
+	require.NoError(t, instance.setupExec())
+	assert.Equal(t, []string{
+		"run", "--env", "LOG_TO_STDERR=true", "--env", "STRUCTURED_RESULTS=true", "validate.wasm",
+	}, instance.Exec.Args)
+
+	instance = NewWasm(runtimeutil.WasmSpec{Path: "validate.wasm"})
+	instance.Runtime = "wasmer"
+	assert.EqualError(t, instance.setupExec(),
+		`unsupported wasm runtime "wasmer", must be one of [wasmtime wazero]`)
+
+	instance = NewWasm(runtimeutil.WasmSpec{})
+	assert.EqualError(t, instance.setupExec(), "no path set for wasm function")
+
+	setLookPath(t)
+	instance = NewWasm(runtimeutil.WasmSpec{Path: "validate.wasm"})
+	assert.EqualError(t, instance.setupExec(),
+		"no wasm runtime found to run validate.wasm: install one of [wasmtime wazero] on the PATH")
+}
+
+func TestFilter_setupExecAllowlist(t *testing.T) {
+	setLookPath(t, RuntimeWazero)
+	dir := t.TempDir()
+	module := []byte("\x00asm")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "validate.wasm"), module, 0o600))
+	sum := sha256.Sum256(module)
+
+	instance := NewWasm(runtimeutil.WasmSpec{Path: "validate.wasm"})
+	instance.Exec.WorkingDir = dir
+	instance.Exec.Allowlist = []runtimeexec.AllowedExecutable{
+		{Path: filepath.Join(dir, "validate.wasm"), Sha256: hex.EncodeToString(sum[:])}}
+	require.NoError(t, instance.setupExec())
+
+	instance = NewWasm(runtimeutil.WasmSpec{Path: "other.wasm"})
+	instance.Exec.WorkingDir = dir
+	instance.Exec.Allowlist = []runtimeexec.AllowedExecutable{}
+	assert.EqualError(t, instance.setupExec(),
+		"executable "+filepath.Join(dir, "other.wasm")+" isn't in the allowlist of executables")
+}
+
+func TestFilter_Filter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the runtime is a shell script")
+	}
+	// a runtime passing the ResourceList through, annotating it
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, RuntimeWasmtime), []byte(`#!/bin/sh
+sed "s/name: cm/name: cm-$(basename "$6")/"
+`), 0700)) //nolint:gosec
+	t.Setenv("PATH", dir+string(filepath.ListSeparator)+os.Getenv("PATH"))
+
+	instance := NewWasm(runtimeutil.WasmSpec{Path: "fn.wasm"})
+	instance.Runtime = RuntimeWasmtime
+	instance.Exec.WorkingDir = dir
+	out, err := instance.Filter([]*yaml.RNode{yaml.MustParse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`)})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, "cm-fn.wasm", out[0].GetName())
+	assert.Equal(t, "fn.wasm", instance.String())
+}
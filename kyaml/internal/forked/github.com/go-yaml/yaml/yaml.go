@@ -288,6 +288,12 @@ func (e *Encoder) DefaultSeqIndent() {
 	e.encoder.emitter.compact_sequence_indent = false
 }
 
+// SetWidth changes the preferred width of the output lines, after which long
+// strings are wrapped.  A negative width disables wrapping.
+func (e *Encoder) SetWidth(width int) {
+	yaml_emitter_set_width(&e.encoder.emitter, width)
+}
+
 // Close closes the encoder by writing any remaining data.
 // It does not write a stream terminating string "...".
 func (e *Encoder) Close() (err error) {
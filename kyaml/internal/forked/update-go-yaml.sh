@@ -25,6 +25,9 @@ export GOYAML_REF="goyaml-$GOYAML_SHA"
 # The PRs we need to cherry-pick onto the above commit
 declare -r GO_YAML_PRS=(753)
 
+# The patches of our own, not proposed upstream, applied on top of the PRs
+declare -r GO_YAML_PATCHES_DIR="kyaml/internal/forked/patches"
+
 REPO_ROOT=$(git rev-parse --show-toplevel)
 declare -r REPO_ROOT
 declare -r REBASEMAGIC="${REPO_ROOT}/.git/rebase-apply"
@@ -99,6 +102,11 @@ for pr in "${GO_YAML_PRS[@]}" ; do
   cherry-pick https://github.com/go-yaml/yaml "$pr"
 done
 
+explain "Applying our own patches from ${GO_YAML_PATCHES_DIR}"
+for patch in "${REPO_ROOT}/${GO_YAML_PATCHES_DIR}"/*.patch ; do
+  git am -3 "$patch"
+done
+
 explain "Converting module to be internal."
 find kyaml/internal/forked/github.com/go-yaml/yaml -name "*.go" -type f | xargs sed -i '' s+"gopkg.in/yaml.v3"+"sigs.k8s.io/kustomize/kyaml/internal/forked/github.com/go-yaml/yaml"+g
 rm kyaml/internal/forked/github.com/go-yaml/yaml/go.mod
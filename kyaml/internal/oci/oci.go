@@ -0,0 +1,600 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package oci pushes and pulls single layer artifacts to and from OCI
+// registries, using the OCI distribution API.
+package oci
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+const (
+	// Scheme is the scheme of OCI references.
+	Scheme = "oci://"
+
+	// ManifestMediaType is the media type of the manifests of artifacts.
+	ManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+	// emptyMediaType is the media type of the empty config of artifacts.
+	emptyMediaType = "application/vnd.oci.empty.v1+json"
+
+	defaultTag = "latest"
+
+	// TrustedRealmHostsEnv is the environment variable of the hosts, comma
+	// separated, of the authentication realms which may be sent the
+	// credentials of registries on other hosts, unless set by the
+	// TrustedRealmHosts of the Client.
+	TrustedRealmHostsEnv = "KUSTOMIZE_OCI_TRUSTED_REALM_HOSTS"
+
+	// credentialHelperPrefix is the prefix of the docker credential helpers.
+	credentialHelperPrefix = "docker-credential-"
+)
+
+// emptyConfig is the content of the empty config of artifacts.
+var emptyConfig = []byte("{}")
+
+// Reference is a parsed reference to an artifact, e.g.
+// oci://registry.example.com/team/app:v1.
+type Reference struct {
+	// Registry is the host, and optionally the port, of the registry.
+	Registry string
+	// Repository is the repository of the artifact in the registry.
+	Repository string
+	// Tag is the tag of the artifact, empty if Digest is set.
+	Tag string
+	// Digest is the digest of the artifact's manifest, if referenced by digest.
+	Digest string
+}
+
+// ParseReference parses an oci:// reference, defaulting its tag to latest.
+func ParseReference(ref string) (Reference, error) {
+	if !strings.HasPrefix(ref, Scheme) {
+		return Reference{}, errors.Errorf("OCI reference %q must start with %s", ref, Scheme)
+	}
+	s := strings.TrimPrefix(ref, Scheme)
+	var r Reference
+	i := strings.Index(s, "/")
+	if i <= 0 {
+		return Reference{}, errors.Errorf("OCI reference %q has no repository", ref)
+	}
+	r.Registry, s = s[:i], s[i+1:]
+	if i := strings.Index(s, "@"); i >= 0 {
+		r.Digest, s = s[i+1:], s[:i]
+		if !strings.HasPrefix(r.Digest, "sha256:") {
+			return Reference{}, errors.Errorf("OCI reference %q has an unsupported digest", ref)
+		}
+	} else if i := strings.LastIndex(s, ":"); i > strings.LastIndex(s, "/") {
+		r.Tag, s = s[i+1:], s[:i]
+	}
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = defaultTag
+	}
+	r.Repository = s
+	if r.Repository == "" || r.Repository != strings.ToLower(r.Repository) {
+		return Reference{}, errors.Errorf("OCI reference %q has an invalid repository", ref)
+	}
+	return r, nil
+}
+
+// String returns the reference in its oci:// form.
+func (r Reference) String() string {
+	if r.Digest != "" {
+		return Scheme + r.Registry + "/" + r.Repository + "@" + r.Digest
+	}
+	return Scheme + r.Registry + "/" + r.Repository + ":" + r.Tag
+}
+
+// ref returns the tag or digest of the reference.
+func (r Reference) ref() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// Descriptor describes a blob of an artifact.
+type Descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// Manifest is the OCI image manifest of an artifact.
+type Manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	ArtifactType  string       `json:"artifactType,omitempty"`
+	Config        Descriptor   `json:"config"`
+	Layers        []Descriptor `json:"layers"`
+}
+
+// Client pushes and pulls artifacts.  Credentials are read from the
+// docker config file, or its credential helpers, and registries on the
+// loopback interface are accessed over plain http.  The credentials of a
+// registry are only sent to the registry itself, and to the https
+// authentication realms on the same host, or on a trusted host.
+type Client struct {
+	// HTTPClient is the client used to access registries.  Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+
+	// TrustedRealmHosts are the hosts of the authentication realms which
+	// may be sent the credentials of registries on other hosts, e.g.
+	// auth.docker.io.  Defaults to those of TrustedRealmHostsEnv.
+	TrustedRealmHosts []string
+
+	// mu guards tokens.
+	mu sync.Mutex
+
+	// tokens are the bearer tokens obtained for each registry and scope,
+	// keyed by tokenKey.
+	tokens map[string]string
+}
+
+// tokenKey returns the key of the token of scope on registry: registries
+// may have repositories with the same path, whose tokens differ.
+func tokenKey(registry, scope string) string {
+	return registry + " " + scope
+}
+
+// Push pushes content as the single layer of an artifact of artifactType,
+// and returns the digest of its manifest.
+func (c *Client) Push(ref Reference, artifactType, layerMediaType string, content []byte) (string, error) {
+	layer := describe(layerMediaType, content)
+	config := describe(emptyMediaType, emptyConfig)
+	for _, blob := range []struct {
+		desc    Descriptor
+		content []byte
+	}{{config, emptyConfig}, {layer, content}} {
+		if err := c.pushBlob(ref, blob.desc, blob.content); err != nil {
+			return "", err
+		}
+	}
+	manifest, err := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     ManifestMediaType,
+		ArtifactType:  artifactType,
+		Config:        config,
+		Layers:        []Descriptor{layer},
+	})
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	resp, err := c.do(ref, http.MethodPut, c.url(ref, "manifests/"+ref.ref()),
+		map[string]string{"Content-Type": ManifestMediaType}, manifest)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", responseError(resp, "pushing manifest of %s", ref)
+	}
+	return describe(ManifestMediaType, manifest).Digest, nil
+}
+
+// Pull pulls the layer of layerMediaType of an artifact.
+func (c *Client) Pull(ref Reference, layerMediaType string) ([]byte, error) {
+	b, err := c.get(ref, "manifests/"+ref.ref(), ManifestMediaType)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Digest != "" && describe(ManifestMediaType, b).Digest != ref.Digest {
+		return nil, errors.Errorf("manifest of %s doesn't match its digest", ref)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, errors.WrapPrefixf(err, "parsing manifest of %s", ref)
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != layerMediaType {
+			continue
+		}
+		content, err := c.get(ref, "blobs/"+layer.Digest, "")
+		if err != nil {
+			return nil, err
+		}
+		if describe(layer.MediaType, content).Digest != layer.Digest {
+			return nil, errors.Errorf("layer %s of %s doesn't match its digest", layer.Digest, ref)
+		}
+		return content, nil
+	}
+	return nil, errors.Errorf("%s has no layer of media type %s", ref, layerMediaType)
+}
+
+// Tags lists the tags of the repository of ref.
+func (c *Client) Tags(ref Reference) ([]string, error) {
+	var tags []string
+	u := c.url(ref, "tags/list")
+	for u != "" {
+		resp, err := c.do(ref, http.MethodGet, u, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var list struct {
+			Tags []string `json:"tags"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			return nil, responseError(resp, "listing the tags of %s", ref.Registry+"/"+ref.Repository)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "listing the tags of %s", ref.Registry+"/"+ref.Repository)
+		}
+		tags = append(tags, list.Tags...)
+		// the registry may paginate the tags, linking to the next page
+		u = ""
+		if next := nextLink(resp.Header.Get("Link")); next != "" {
+			if nextURL, err := resp.Request.URL.Parse(next); err == nil {
+				u = nextURL.String()
+			}
+		}
+	}
+	return tags, nil
+}
+
+// nextLink returns the url of the next page of a Link header, e.g.
+// </v2/app/tags/list?n=100&last=v1>; rel="next".
+func nextLink(link string) string {
+	for _, l := range strings.Split(link, ",") {
+		target, params, _ := strings.Cut(strings.TrimSpace(l), ";")
+		if strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>")
+		}
+	}
+	return ""
+}
+
+func (c *Client) pushBlob(ref Reference, desc Descriptor, content []byte) error {
+	resp, err := c.do(ref, http.MethodHead, c.url(ref, "blobs/"+desc.Digest), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = c.do(ref, http.MethodPost, c.url(ref, "blobs/uploads/"), nil, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		defer resp.Body.Close()
+		return responseError(resp, "pushing blob %s to %s", desc.Digest, ref)
+	}
+	resp.Body.Close()
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return errors.WrapPrefixf(err, "pushing blob %s to %s", desc.Digest, ref)
+	}
+	q := location.Query()
+	q.Set("digest", desc.Digest)
+	location.RawQuery = q.Encode()
+
+	resp, err = c.do(ref, http.MethodPut, location.String(),
+		map[string]string{"Content-Type": "application/octet-stream"}, content)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return responseError(resp, "pushing blob %s to %s", desc.Digest, ref)
+	}
+	return nil
+}
+
+func (c *Client) get(ref Reference, path, accept string) ([]byte, error) {
+	var header map[string]string
+	if accept != "" {
+		header = map[string]string{"Accept": accept}
+	}
+	resp, err := c.do(ref, http.MethodGet, c.url(ref, path), header, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "pulling %s", ref)
+	}
+	b, err := io.ReadAll(resp.Body)
+	return b, errors.Wrap(err)
+}
+
+// url returns the url of path in the repository of ref.
+func (c *Client) url(ref Reference, path string) string {
+	scheme := "https"
+	if isLoopback(ref.Registry) {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s", scheme, ref.Registry, ref.Repository, path)
+}
+
+// do sends a request, authenticating it if the registry challenges it.
+func (c *Client) do(ref Reference, method, u string, header map[string]string, body []byte) (*http.Response, error) {
+	scope := fmt.Sprintf("repository:%s:pull", ref.Repository)
+	if method != http.MethodGet && method != http.MethodHead {
+		scope += ",push"
+	}
+	c.mu.Lock()
+	token := c.tokens[tokenKey(ref.Registry, scope)]
+	c.mu.Unlock()
+	resp, err := c.send(method, u, header, body, token)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	auth, err := c.authorize(ref.Registry, scope, challenge)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(method, u, header, body, auth)
+}
+
+func (c *Client) send(method, u string, header map[string]string, body []byte, auth string) (*http.Response, error) {
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	return resp, errors.Wrap(err)
+}
+
+// authorize answers the challenge of a registry, returning the value of
+// the Authorization header.
+func (c *Client) authorize(registry, scope, challenge string) (string, error) {
+	basic, err := credentials(registry)
+	if err != nil {
+		return "", err
+	}
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if basic == "" {
+			return "", errors.Errorf("no credentials for %s", registry)
+		}
+		return "Basic " + basic, nil
+	case "bearer":
+	default:
+		return "", errors.Errorf("unsupported authentication challenge %q of %s", challenge, registry)
+	}
+
+	u, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" || u.Host == "" {
+		return "", errors.Errorf("invalid authentication realm %q of %s", params["realm"], registry)
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && isLoopback(registry)) {
+		return "", errors.Errorf("authentication realm %q of %s must use https", params["realm"], registry)
+	}
+	q := u.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	q.Set("scope", scope)
+	u.RawQuery = q.Encode()
+	var auth string
+	withheld := basic != "" && !c.isTrustedRealm(registry, u.Hostname())
+	if basic != "" && !withheld {
+		auth = "Basic " + basic
+	}
+	resp, err := c.send(http.MethodGet, u.String(), nil, nil, auth)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		if withheld {
+			return "", errors.WrapPrefixf(responseError(resp, "authenticating to %s", registry),
+				"the credentials of %s weren't sent to the realm on %s, which can be trusted with %s",
+				registry, u.Hostname(), TrustedRealmHostsEnv)
+		}
+		return "", responseError(resp, "authenticating to %s", registry)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", errors.WrapPrefixf(err, "authenticating to %s", registry)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	auth = "Bearer " + token.Token
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		c.tokens = map[string]string{}
+	}
+	c.tokens[tokenKey(registry, scope)] = auth
+	return auth, nil
+}
+
+// isTrustedRealm returns true if the credentials of registry may be sent
+// to the authentication realm on realmHost: if it's the host of the
+// registry, or one of the trusted realm hosts.
+func (c *Client) isTrustedRealm(registry, realmHost string) bool {
+	host, _, err := net.SplitHostPort(registry)
+	if err != nil {
+		host = registry
+	}
+	if strings.EqualFold(host, realmHost) {
+		return true
+	}
+	trusted := c.TrustedRealmHosts
+	if trusted == nil {
+		trusted = strings.Split(os.Getenv(TrustedRealmHostsEnv), ",")
+	}
+	for _, h := range trusted {
+		if h = strings.TrimSpace(h); h != "" && strings.EqualFold(h, realmHost) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g.
+// Bearer realm="https://auth.example.com/token",service="registry".
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return strings.ToLower(scheme), params
+}
+
+// dockerConfig is the part of the docker config file holding the
+// credentials of registries.
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// credentials returns the base64 encoded credentials of the registry in
+// the docker config file, or given by its credential helper, or the empty
+// string if there are none.
+func credentials(registry string) (string, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil //nolint:nilerr // no home, no credentials
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return "", nil //nolint:nilerr // no config file, no credentials
+	}
+	var config dockerConfig
+	if json.Unmarshal(b, &config) != nil {
+		return "", nil
+	}
+	helper := config.CredHelpers[registry]
+	if helper == "" {
+		helper = config.CredsStore
+	}
+	if helper != "" {
+		basic, err := helperCredentials(helper, registry)
+		if basic != "" || err != nil {
+			return basic, err
+		}
+	}
+	for _, key := range []string{registry, "https://" + registry, "http://" + registry} {
+		if auth, ok := config.Auths[key]; ok {
+			if auth.Auth != "" {
+				return auth.Auth, nil
+			}
+			if auth.Username != "" {
+				return base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password)), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// helperCredentials returns the base64 encoded credentials of the registry
+// given by the docker credential helper, or the empty string if it has
+// none.
+func helperCredentials(helper, registry string) (string, error) {
+	program := credentialHelperPrefix + helper
+	path, err := exec.LookPath(program)
+	if err != nil {
+		return "", errors.Errorf(
+			"the docker credential helper %s of %s isn't installed: %v", program, registry, err)
+	}
+	cmd := exec.Command(path, "get")
+	cmd.Stdin = strings.NewReader(registry)
+	out, err := cmd.Output()
+	if err != nil {
+		// helpers exit with an error when they have no credentials
+		if strings.Contains(string(out), "credentials not found") {
+			return "", nil
+		}
+		return "", errors.Errorf("the docker credential helper %s of %s failed: %v: %s",
+			program, registry, err, strings.TrimSpace(string(out)))
+	}
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return "", errors.WrapPrefixf(err, "reading the credentials of %s given by %s", registry, program)
+	}
+	if creds.Username == "<token>" {
+		return "", errors.Errorf("the identity token of %s given by %s isn't supported", registry, program)
+	}
+	if creds.Secret == "" {
+		return "", nil
+	}
+	return base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Secret)), nil
+}
+
+// isLoopback returns true if the registry is on the loopback interface.
+func isLoopback(registry string) bool {
+	host, _, err := net.SplitHostPort(registry)
+	if err != nil {
+		host = registry
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func describe(mediaType string, content []byte) Descriptor {
+	sum := sha256.Sum256(content)
+	return Descriptor{
+		MediaType: mediaType,
+		Digest:    "sha256:" + hex.EncodeToString(sum[:]),
+		Size:      int64(len(content)),
+	}
+}
+
+func responseError(resp *http.Response, msg string, args ...interface{}) error {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return errors.Errorf("%s: %s: %s", fmt.Sprintf(msg, args...), resp.Status, strings.TrimSpace(string(b)))
+}
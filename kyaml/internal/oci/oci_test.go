@@ -0,0 +1,238 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package oci_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/internal/oci"
+	"sigs.k8s.io/kustomize/kyaml/internal/oci/ocitest"
+)
+
+func TestParseReference(t *testing.T) {
+	for ref, expected := range map[string]oci.Reference{
+		"oci://registry.example.com/app": {
+			Registry: "registry.example.com", Repository: "app", Tag: "latest"},
+		"oci://localhost:5000/team/app:v1.2": {
+			Registry: "localhost:5000", Repository: "team/app", Tag: "v1.2"},
+		"oci://registry.example.com/app@sha256:abc": {
+			Registry: "registry.example.com", Repository: "app", Digest: "sha256:abc"},
+	} {
+		actual, err := oci.ParseReference(ref)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+
+	for ref, msg := range map[string]string{
+		"registry.example.com/app":            `OCI reference "registry.example.com/app" must start with oci://`,
+		"oci://registry.example.com":          `OCI reference "oci://registry.example.com" has no repository`,
+		"oci://registry.example.com/App":      `OCI reference "oci://registry.example.com/App" has an invalid repository`,
+		"oci://registry.example.com/app@md5:": `OCI reference "oci://registry.example.com/app@md5:" has an unsupported digest`,
+	} {
+		_, err := oci.ParseReference(ref)
+		assert.EqualError(t, err, msg)
+	}
+}
+
+func TestClient(t *testing.T) {
+	for _, auth := range []bool{false, true} {
+		registry := ocitest.NewRegistry(t, auth)
+		ref, err := oci.ParseReference("oci://" + registry.Host + "/team/app:v1")
+		require.NoError(t, err)
+
+		c := &oci.Client{}
+		digest, err := c.Push(ref, "application/vnd.test", "application/vnd.test.layer", []byte("content"))
+		require.NoError(t, err)
+		assert.Contains(t, string(registry.Manifest("team/app", "v1")),
+			`"artifactType":"application/vnd.test"`)
+
+		content, err := c.Pull(ref, "application/vnd.test.layer")
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(content))
+
+		ref.Tag, ref.Digest = "", digest
+		content, err = (&oci.Client{}).Pull(ref, "application/vnd.test.layer")
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(content))
+
+		_, err = c.Pull(ref, "application/vnd.other")
+		assert.EqualError(t, err, ref.String()+" has no layer of media type application/vnd.other")
+
+		ref.Digest, ref.Tag = "", "missing"
+		_, err = c.Pull(ref, "application/vnd.test.layer")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pulling "+ref.String()+": 404 Not Found")
+
+		registry.Tag("team/app", "v1.1.0", "v2.0.0")
+		tags, err := c.Tags(ref)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"v1", "v1.1.0", "v2.0.0"}, tags)
+	}
+}
+
+// authServers starts a registry challenging the requests without the token
+// of its realm, a server on the host localhost rather than the loopback
+// address of the registry, which records the Authorization header of the
+// token requests in auths.
+func authServers(t *testing.T, auths *[]string) oci.Reference {
+	t.Helper()
+	realm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		*auths = append(*auths, req.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"token": "realm-token"}`)
+	}))
+	t.Cleanup(realm.Close)
+	realmURL := strings.Replace(realm.URL, "127.0.0.1", "localhost", 1)
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer realm-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token"`, realmURL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"tags": ["v1"]}`)
+	}))
+	t.Cleanup(registry.Close)
+	ref, err := oci.ParseReference("oci://" + strings.TrimPrefix(registry.URL, "http://") + "/team/app:v1")
+	require.NoError(t, err)
+	return ref
+}
+
+// writeDockerConfig writes the docker config file of the test.
+func writeDockerConfig(t *testing.T, config string) {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o600))
+	t.Setenv("DOCKER_CONFIG", dir)
+}
+
+func TestClient_realmOnOtherHost(t *testing.T) {
+	var auths []string
+	ref := authServers(t, &auths)
+	basic := base64.StdEncoding.EncodeToString([]byte("user:password"))
+	writeDockerConfig(t, fmt.Sprintf(`{"auths": {%q: {"auth": %q}}}`, ref.Registry, basic))
+	t.Setenv(oci.TrustedRealmHostsEnv, "")
+
+	// the credentials aren't sent to a realm on another host
+	_, err := (&oci.Client{}).Tags(ref)
+	require.NoError(t, err)
+	assert.Equal(t, []string{""}, auths)
+
+	// unless it's trusted
+	_, err = (&oci.Client{TrustedRealmHosts: []string{"localhost"}}).Tags(ref)
+	require.NoError(t, err)
+	t.Setenv(oci.TrustedRealmHostsEnv, "auth.example.com, localhost")
+	_, err = (&oci.Client{}).Tags(ref)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "Basic " + basic, "Basic " + basic}, auths)
+}
+
+func TestClient_tokensOfRegistries(t *testing.T) {
+	// two registries with the same repository, whose realms issue different tokens
+	var refs []oci.Reference
+	var auths [2][]string
+	for i := range auths {
+		i := i
+		token := fmt.Sprintf("token-%d", i)
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/token" {
+				fmt.Fprintf(w, `{"token": %q}`, token)
+				return
+			}
+			auths[i] = append(auths[i], req.Header.Get("Authorization"))
+			if req.Header.Get("Authorization") != "Bearer "+token {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"tags": ["v1"]}`)
+		}))
+		t.Cleanup(server.Close)
+		ref, err := oci.ParseReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/team/app:v1")
+		require.NoError(t, err)
+		refs = append(refs, ref)
+	}
+
+	c := &oci.Client{}
+	for i := 0; i < 2; i++ {
+		for _, ref := range refs {
+			_, err := c.Tags(ref)
+			require.NoError(t, err)
+		}
+	}
+	// each registry is only sent its own token, which is reused
+	assert.Equal(t, []string{"", "Bearer token-0", "Bearer token-0"}, auths[0])
+	assert.Equal(t, []string{"", "Bearer token-1", "Bearer token-1"}, auths[1])
+}
+
+// roundTripFunc is an http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClient_insecureRealm(t *testing.T) {
+	// a registry which isn't on the loopback interface, with a plain http realm
+	var realmRequested bool
+	c := &oci.Client{HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "auth.example.com" {
+			realmRequested = true
+		}
+		rec := httptest.NewRecorder()
+		rec.Header().Set("WWW-Authenticate", `Bearer realm="http://auth.example.com/token"`)
+		rec.WriteHeader(http.StatusUnauthorized)
+		return rec.Result(), nil
+	})}}
+	ref, err := oci.ParseReference("oci://registry.example.com/team/app:v1")
+	require.NoError(t, err)
+	_, err = c.Tags(ref)
+	assert.EqualError(t, err,
+		`authentication realm "http://auth.example.com/token" of registry.example.com must use https`)
+	assert.False(t, realmRequested)
+}
+
+func TestClient_credentialHelpers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the credential helper is a shell script")
+	}
+	var auths []string
+	ref := authServers(t, &auths)
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "docker-credential-test"), []byte(`#!/bin/sh
+read registry
+if [ "$registry" = "`+ref.Registry+`" ]; then
+  echo '{"ServerURL": "'$registry'", "Username": "user", "Secret": "secret"}'
+else
+  echo "credentials not found in native keychain"
+  exit 1
+fi
+`), 0o700)) //nolint:gosec
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv(oci.TrustedRealmHostsEnv, "localhost")
+
+	writeDockerConfig(t, `{"credsStore": "test"}`)
+	_, err := (&oci.Client{}).Tags(ref)
+	require.NoError(t, err)
+	writeDockerConfig(t, fmt.Sprintf(`{"credsStore": "missing", "credHelpers": {%q: "test"}}`, ref.Registry))
+	_, err = (&oci.Client{}).Tags(ref)
+	require.NoError(t, err)
+	basic := base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	assert.Equal(t, []string{"Basic " + basic, "Basic " + basic}, auths)
+
+	writeDockerConfig(t, `{"credsStore": "missing"}`)
+	_, err = (&oci.Client{}).Tags(ref)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the docker credential helper docker-credential-missing of "+
+		ref.Registry+" isn't installed")
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package ocitest provides an in-memory OCI registry for tests.
+package ocitest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Token is the bearer token required by registries with authentication.
+const Token = "ocitest-token"
+
+var (
+	blobPath     = regexp.MustCompile(`^/v2/(.+)/blobs/(sha256:[0-9a-f]{64})$`)
+	uploadPath   = regexp.MustCompile(`^/v2/(.+)/blobs/uploads/(\d*)$`)
+	manifestPath = regexp.MustCompile(`^/v2/(.+)/manifests/([^/]+)$`)
+	tagsPath     = regexp.MustCompile(`^/v2/(.+)/tags/list$`)
+)
+
+// Registry is an in-memory OCI registry.
+type Registry struct {
+	// Host is the host and port of the registry.
+	Host string
+
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	uploads   int
+}
+
+// NewRegistry starts a registry, closed when the test ends.  If auth is
+// true, the registry challenges requests without the bearer Token.
+func NewRegistry(t *testing.T, auth bool) *Registry {
+	t.Helper()
+	r := &Registry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token" {
+			fmt.Fprintf(w, `{"token": %q}`, Token)
+			return
+		}
+		if auth && req.Header.Get("Authorization") != "Bearer "+Token {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+				`Bearer realm="%s/token",service="ocitest"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.serve(w, req)
+	}))
+	t.Cleanup(server.Close)
+	r.Host = strings.TrimPrefix(server.URL, "http://")
+	return r
+}
+
+// Manifest returns the manifest of repository:ref, or nil.
+func (r *Registry) Manifest(repository, ref string) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.manifests[repository+":"+ref]
+}
+
+// Tag tags an empty manifest of repository with each of the tags.
+func (r *Registry) Tag(repository string, tags ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, tag := range tags {
+		r.manifests[repository+":"+tag] = []byte("{}")
+	}
+}
+
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path := req.URL.Path
+	switch {
+	case uploadPath.MatchString(path):
+		m := uploadPath.FindStringSubmatch(path)
+		if req.Method == http.MethodPost {
+			r.uploads++
+			w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%d?state=x", m[1], r.uploads))
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		b, _ := io.ReadAll(req.Body)
+		if req.URL.Query().Get("state") != "x" || digest(b) != req.URL.Query().Get("digest") {
+			http.Error(w, "digest invalid", http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest(b)] = b
+		w.WriteHeader(http.StatusCreated)
+	case blobPath.MatchString(path):
+		b, ok := r.blobs[blobPath.FindStringSubmatch(path)[2]]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		_, _ = w.Write(b)
+	case tagsPath.MatchString(path):
+		repository := tagsPath.FindStringSubmatch(path)[1]
+		tags := []string{}
+		for key := range r.manifests {
+			if tag := strings.TrimPrefix(key, repository+":"); tag != key && !strings.HasPrefix(tag, "sha256:") {
+				tags = append(tags, tag)
+			}
+		}
+		sort.Strings(tags)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": repository, "tags": tags})
+	case manifestPath.MatchString(path):
+		m := manifestPath.FindStringSubmatch(path)
+		key := m[1] + ":" + m[2]
+		if req.Method == http.MethodPut {
+			b, _ := io.ReadAll(req.Body)
+			r.manifests[key] = b
+			r.manifests[m[1]+":"+digest(b)] = b
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		b, ok := r.manifests[key]
+		if !ok {
+			http.Error(w, `{"errors":[{"code":"MANIFEST_UNKNOWN"}]}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(b)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
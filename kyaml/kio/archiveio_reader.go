@@ -0,0 +1,281 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ArchiveFormat identifies the container format of an archive read by
+// ArchiveReader or written by ArchiveWriter.
+type ArchiveFormat string
+
+const (
+	// TarArchive is an uncompressed tar archive.
+	TarArchive ArchiveFormat = "tar"
+
+	// TarGzipArchive is a gzip compressed tar archive.
+	TarGzipArchive ArchiveFormat = "tgz"
+
+	// ZipArchive is a zip archive.
+	ZipArchive ArchiveFormat = "zip"
+)
+
+// ArchiveReader reads ResourceNodes from the files contained in a tar or zip archive.
+// Each Resource is annotated with the path of the archive entry it was read from, so
+// it can be written back to an archive or a local package with the same layout.
+type ArchiveReader struct {
+	// Reader is where the archive is read from.
+	Reader io.Reader
+
+	// Format is the format of the archive. Defaults to TarArchive if empty.
+	Format ArchiveFormat
+
+	// MatchFilesGlob configures Read to only read Resources from entries matching any of the
+	// provided patterns.
+	// Defaults to ["*.yaml", "*.yml"] if empty.  To match all files specify ["*"].
+	MatchFilesGlob []string
+
+	// OmitReaderAnnotations will cause the reader to skip annotating Resources with the entry
+	// path and index.
+	OmitReaderAnnotations bool
+
+	// SetAnnotations are annotations to set on the Resources as they are read.
+	SetAnnotations map[string]string
+
+	// PreserveSeqIndent if true adds kioutil.SeqIndentAnnotation to each resource
+	PreserveSeqIndent bool
+
+	// WrapBareSeqNode wraps the bare sequence node document with map node,
+	// kyaml uses reader annotations to track resources, it is not possible to
+	// add them to bare sequence nodes, this option enables wrapping such bare
+	// sequence nodes into map node with key yaml.BareSeqNodeWrappingKey
+	// note that this wrapping is different and not related to ResourceList wrapping
+	WrapBareSeqNode bool
+}
+
+var _ Reader = ArchiveReader{}
+
+// Read reads the Resources from the archive.
+func (r ArchiveReader) Read() ([]*yaml.RNode, error) {
+	if r.Reader == nil {
+		return nil, errors.Errorf("must specify archive reader")
+	}
+	if len(r.MatchFilesGlob) == 0 {
+		r.MatchFilesGlob = DefaultMatch
+	}
+	switch r.Format {
+	case "", TarArchive:
+		return r.readTar(r.Reader)
+	case TarGzipArchive:
+		gz, err := gzip.NewReader(r.Reader)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		defer gz.Close()
+		return r.readTar(gz)
+	case ZipArchive:
+		return r.readZip()
+	default:
+		return nil, errors.Errorf("unsupported archive format %q", r.Format)
+	}
+}
+
+func (r ArchiveReader) readTar(in io.Reader) ([]*yaml.RNode, error) {
+	var operand ResourceNodeSlice
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return operand, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		nodes, err := r.readEntry(hdr.Name, tr)
+		if err != nil {
+			return nil, err
+		}
+		operand = append(operand, nodes...)
+	}
+}
+
+func (r ArchiveReader) readZip() ([]*yaml.RNode, error) {
+	// zip requires random access to read the central directory
+	b, err := io.ReadAll(r.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	var operand ResourceNodeSlice
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, f.Name)
+		}
+		nodes, err := r.readEntry(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		operand = append(operand, nodes...)
+	}
+	return operand, nil
+}
+
+// readEntry reads the ResourceNodes from a single archive entry
+func (r ArchiveReader) readEntry(name string, in io.Reader) ([]*yaml.RNode, error) {
+	relPath, err := cleanArchivePath(name)
+	if err != nil {
+		return nil, err
+	}
+	if match, err := r.matchFile(relPath); err != nil || !match {
+		return nil, err
+	}
+
+	annotations := map[string]string{}
+	for k, v := range r.SetAnnotations {
+		annotations[k] = v
+	}
+	if !r.OmitReaderAnnotations {
+		annotations[kioutil.PathAnnotation] = relPath
+		annotations[kioutil.LegacyPathAnnotation] = relPath
+	}
+	rr := &ByteReader{
+		DisableUnwrapping:     true,
+		Reader:                in,
+		OmitReaderAnnotations: r.OmitReaderAnnotations,
+		SetAnnotations:        annotations,
+		PreserveSeqIndent:     r.PreserveSeqIndent,
+		WrapBareSeqNode:       r.WrapBareSeqNode,
+	}
+	nodes, err := rr.Read()
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, relPath)
+	}
+	return nodes, nil
+}
+
+// Extract writes all the files of the archive, not only those holding
+// Resources, to dir, e.g. to build the kustomization in it.
+func (r ArchiveReader) Extract(fSys filesys.FileSystem, dir string) error {
+	if r.Reader == nil {
+		return errors.Errorf("must specify archive reader")
+	}
+	write := func(name string, in io.Reader) error {
+		relPath, err := cleanArchivePath(name)
+		if err != nil {
+			return err
+		}
+		b, err := io.ReadAll(in)
+		if err != nil {
+			return errors.WrapPrefixf(err, relPath)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(relPath))
+		if err := fSys.MkdirAll(filepath.Dir(path)); err != nil {
+			return errors.Wrap(err)
+		}
+		return errors.Wrap(fSys.WriteFile(path, b))
+	}
+	switch r.Format {
+	case "", TarArchive:
+		return extractTar(r.Reader, write)
+	case TarGzipArchive:
+		gz, err := gzip.NewReader(r.Reader)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		defer gz.Close()
+		return extractTar(gz, write)
+	case ZipArchive:
+		b, err := io.ReadAll(r.Reader)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return errors.WrapPrefixf(err, f.Name)
+			}
+			err = write(f.Name, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return errors.Errorf("unsupported archive format %q", r.Format)
+	}
+}
+
+// extractTar calls write with the regular files of the tar archive.
+func extractTar(in io.Reader, write func(name string, in io.Reader) error) error {
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := write(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// matchFile returns true if the entry is in scope of MatchFilesGlob
+func (r ArchiveReader) matchFile(relPath string) (bool, error) {
+	for _, g := range r.MatchFilesGlob {
+		if match, err := filepath.Match(g, path.Base(relPath)); err != nil {
+			return false, errors.Wrap(err)
+		} else if match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// cleanArchivePath returns the slash separated relative path of an archive entry,
+// or an error if the entry would resolve outside of the archive root.
+func cleanArchivePath(name string) (string, error) {
+	p := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+		return "", errors.Errorf("archive entry must be a relative path within the archive: %s", name)
+	}
+	return p, nil
+}
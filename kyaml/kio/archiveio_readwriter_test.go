@@ -0,0 +1,172 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestArchiveReadWriter_roundTrip(t *testing.T) {
+	for _, format := range []kio.ArchiveFormat{kio.TarArchive, kio.TarGzipArchive, kio.ZipArchive} {
+		t.Run(string(format), func(t *testing.T) {
+			input, err := kio.ParseAll(`kind: Deployment
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/path: apps/app.yaml
+`, `kind: Service
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/path: apps/app.yaml
+    config.kubernetes.io/index: '1'
+`, `kind: ConfigMap
+metadata:
+  name: settings
+`)
+			require.NoError(t, err)
+
+			var archive bytes.Buffer
+			require.NoError(t, kio.ArchiveWriter{Writer: &archive, Format: format}.Write(input))
+
+			nodes, err := kio.ArchiveReader{Reader: &archive, Format: format}.Read()
+			require.NoError(t, err)
+			require.Len(t, nodes, 3)
+
+			var got []string
+			for _, n := range nodes {
+				path, index, err := kioutil.GetFileAnnotations(n)
+				require.NoError(t, err)
+				got = append(got, n.GetKind()+" "+path+" "+index)
+			}
+			assert.Equal(t, []string{
+				"Deployment apps/app.yaml 0",
+				"Service apps/app.yaml 1",
+				"ConfigMap configmap_settings.yaml 0",
+			}, got)
+		})
+	}
+}
+
+func TestArchiveReader_Read(t *testing.T) {
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	for _, f := range []struct{ name, content string }{
+		{"a/b.yaml", "kind: Foo\n---\nkind: Bar\n"},
+		{"a/README.md", "# not a resource\n"},
+		{"c.json", `{"kind": "Baz"}`},
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg, Name: f.name, Mode: 0644, Size: int64(len(f.content)),
+		}))
+		_, err := tw.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	nodes, err := kio.ArchiveReader{
+		Reader:         bytes.NewReader(archive.Bytes()),
+		MatchFilesGlob: kio.MatchAll,
+	}.Read()
+	require.NoError(t, err)
+	out, err := kio.StringAll(nodes)
+	require.NoError(t, err)
+	assert.Equal(t, `kind: Foo
+metadata:
+  annotations:
+    config.kubernetes.io/path: 'a/b.yaml'
+    internal.config.kubernetes.io/path: 'a/b.yaml'
+---
+kind: Bar
+metadata:
+  annotations:
+    config.kubernetes.io/path: 'a/b.yaml'
+    internal.config.kubernetes.io/path: 'a/b.yaml'
+---
+{"kind": "Baz", metadata: {annotations: {config.kubernetes.io/path: 'c.json', internal.config.kubernetes.io/path: 'c.json'}}}
+`, out)
+
+	nodes, err = kio.ArchiveReader{Reader: bytes.NewReader(archive.Bytes())}.Read()
+	require.NoError(t, err)
+	assert.Len(t, nodes, 2)
+}
+
+func TestArchiveReader_Read_unsafePath(t *testing.T) {
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg, Name: "../evil.yaml", Mode: 0644, Size: 0,
+	}))
+	require.NoError(t, tw.Close())
+
+	_, err := kio.ArchiveReader{Reader: &archive}.Read()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "archive entry must be a relative path")
+}
+
+func TestArchiveReader_Extract(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for _, f := range []struct{ name, content string }{
+		{"base/kustomization.yaml", "resources: [deployment.yaml]\n"},
+		{"base/deployment.yaml", "kind: Deployment\n"},
+		{"base/app.properties", "level=debug\n"},
+	} {
+		w, err := zw.Create(f.name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(f.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, kio.ArchiveReader{Reader: &archive, Format: kio.ZipArchive}.Extract(fSys, "/out"))
+	content, err := fSys.ReadFile("/out/base/app.properties")
+	require.NoError(t, err)
+	assert.Equal(t, "level=debug\n", string(content))
+	assert.True(t, fSys.Exists("/out/base/kustomization.yaml"))
+	assert.True(t, fSys.Exists("/out/base/deployment.yaml"))
+
+	var unsafe bytes.Buffer
+	tw := tar.NewWriter(&unsafe)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg, Name: "../evil.yaml", Mode: 0644, Size: 0,
+	}))
+	require.NoError(t, tw.Close())
+	err = kio.ArchiveReader{Reader: &unsafe}.Extract(fSys, "/out")
+	assert.EqualError(t, err, "archive entry must be a relative path within the archive: ../evil.yaml")
+}
+
+func TestArchiveWriter_Write_keepReaderAnnotations(t *testing.T) {
+	node := yaml.MustParse(`kind: Foo
+metadata:
+  name: foo
+`)
+	var archive bytes.Buffer
+	require.NoError(t, kio.ArchiveWriter{
+		Writer:                &archive,
+		KeepReaderAnnotations: true,
+	}.Write([]*yaml.RNode{node}))
+	// the input node must not be mutated by defaulting the annotations
+	assert.Empty(t, node.GetAnnotations())
+
+	tr := tar.NewReader(&archive)
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "foo_foo.yaml", hdr.Name)
+	var content bytes.Buffer
+	_, err = content.ReadFrom(tr)
+	require.NoError(t, err)
+	assert.Contains(t, content.String(), "internal.config.kubernetes.io/path: 'foo_foo.yaml'")
+}
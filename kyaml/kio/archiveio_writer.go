@@ -0,0 +1,154 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sort"
+	"time"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// archiveModTime is the modification time set on all archive entries so that
+// writing the same Resources always produces the same archive.
+var archiveModTime = time.Unix(0, 0).UTC()
+
+// ArchiveWriter writes ResourceNodes to a tar or zip archive. Resources are grouped
+// into archive entries by their kioutil.PathAnnotation, defaulting the annotation
+// for Resources that are missing it.
+type ArchiveWriter struct {
+	// Writer is where the archive is written.
+	Writer io.Writer
+
+	// Format is the format of the archive. Defaults to TarArchive if empty.
+	Format ArchiveFormat
+
+	// KeepReaderAnnotations if set will retain the annotations set by ArchiveReader
+	KeepReaderAnnotations bool
+
+	// ClearAnnotations will clear annotations before writing the resources
+	ClearAnnotations []string
+}
+
+var _ Writer = ArchiveWriter{}
+
+// Write writes the Resources to the archive.
+func (w ArchiveWriter) Write(inputNodes []*yaml.RNode) error {
+	if w.Writer == nil {
+		return errors.Errorf("must specify archive writer")
+	}
+	// Copy the nodes so defaulting annotations doesn't mutate the caller's nodes.
+	nodes := copyRNodes(inputNodes)
+	if err := kioutil.DefaultPathAndIndexAnnotation("", nodes); err != nil {
+		return err
+	}
+	entries, err := w.render(nodes)
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for p := range entries {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	switch w.Format {
+	case "", TarArchive:
+		return w.writeTar(w.Writer, paths, entries)
+	case TarGzipArchive:
+		gz := gzip.NewWriter(w.Writer)
+		if err := w.writeTar(gz, paths, entries); err != nil {
+			return err
+		}
+		return errors.Wrap(gz.Close())
+	case ZipArchive:
+		return w.writeZip(paths, entries)
+	default:
+		return errors.Errorf("unsupported archive format %q", w.Format)
+	}
+}
+
+// render encodes the Resources belonging to each archive entry
+func (w ArchiveWriter) render(nodes []*yaml.RNode) (map[string][]byte, error) {
+	byPath := map[string][]*yaml.RNode{}
+	for i := range nodes {
+		p, _, err := kioutil.GetFileAnnotations(nodes[i])
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		relPath, err := cleanArchivePath(p)
+		if err != nil {
+			return nil, err
+		}
+		byPath[relPath] = append(byPath[relPath], nodes[i])
+	}
+
+	clearAnnotations := append([]string{}, w.ClearAnnotations...)
+	if !w.KeepReaderAnnotations {
+		clearAnnotations = append(clearAnnotations, kioutil.PathAnnotation, kioutil.LegacyPathAnnotation)
+	}
+	entries := map[string][]byte{}
+	for p := range byPath {
+		if err := kioutil.SortNodes(byPath[p]); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		buf := &bytes.Buffer{}
+		bw := ByteWriter{
+			Writer:                buf,
+			KeepReaderAnnotations: w.KeepReaderAnnotations,
+			ClearAnnotations:      clearAnnotations,
+		}
+		if err := bw.Write(byPath[p]); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		entries[p] = buf.Bytes()
+	}
+	return entries, nil
+}
+
+func (w ArchiveWriter) writeTar(out io.Writer, paths []string, entries map[string][]byte) error {
+	tw := tar.NewWriter(out)
+	for _, p := range paths {
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     p,
+			Mode:     0644,
+			Size:     int64(len(entries[p])),
+			ModTime:  archiveModTime,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return errors.Wrap(err)
+		}
+		if _, err := tw.Write(entries[p]); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return errors.Wrap(tw.Close())
+}
+
+func (w ArchiveWriter) writeZip(paths []string, entries map[string][]byte) error {
+	zw := zip.NewWriter(w.Writer)
+	for _, p := range paths {
+		hdr := &zip.FileHeader{
+			Name:     p,
+			Method:   zip.Deflate,
+			Modified: archiveModTime,
+		}
+		f, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if _, err := f.Write(entries[p]); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return errors.Wrap(zw.Close())
+}
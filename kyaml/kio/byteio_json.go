@@ -0,0 +1,114 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// JSONComment is a comment of a Resource written as JSON by a ByteWriter.
+// The comments of the Resources are written to the ByteWriter CommentWriter
+// as a JSON array of JSONComments, in the order they appear in the Resources.
+type JSONComment struct {
+	// Document is the index of the JSON document containing the comment.
+	Document int `json:"document"`
+
+	// Path is the path of the commented field from the root of the document.
+	// Sequence elements are identified by their index.
+	Path []string `json:"path"`
+
+	// HeadComment is the comment on the lines preceding the field.
+	HeadComment string `json:"headComment,omitempty"`
+
+	// LineComment is the comment at the end of the line of the field.
+	LineComment string `json:"lineComment,omitempty"`
+
+	// FootComment is the comment on the lines following the field.
+	FootComment string `json:"footComment,omitempty"`
+}
+
+// writeJSON writes the nodes as JSON documents.
+func (w ByteWriter) writeJSON(nodes []*yaml.RNode) error {
+	var docs []*yaml.RNode
+	if w.WrappingKind != "" {
+		docs = append(docs, yaml.NewRNode(w.wrap(nodes)))
+	} else {
+		for i := range nodes {
+			docs = append(docs, yaml.NewRNode(upWrapBareSequenceNode(nodes[i].Document())))
+		}
+	}
+
+	encoder := json.NewEncoder(w.Writer)
+	encoder.SetIndent("", "  ")
+	comments := []JSONComment{}
+	for i := range docs {
+		if err := encoder.Encode(docs[i]); err != nil {
+			return errors.Wrap(err)
+		}
+		if w.WrappingKind == "" {
+			w.reportWritten(i + 1)
+		} else {
+			w.reportWritten(len(nodes))
+		}
+		comments = appendJSONComments(comments, i, nil, docs[i].YNode())
+	}
+
+	if w.CommentWriter == nil {
+		return nil
+	}
+	b, err := json.MarshalIndent(comments, "", "  ")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	_, err = w.CommentWriter.Write(append(b, '\n'))
+	return errors.Wrap(err)
+}
+
+// appendJSONComments appends the comments of n, the field at path, and of the
+// fields it contains to comments.
+func appendJSONComments(comments []JSONComment, doc int, path []string, n *yaml.Node, keys ...*yaml.Node) []JSONComment {
+	c := JSONComment{Document: doc, Path: append([]string{}, path...)}
+	for _, cn := range append(keys, n) {
+		c.HeadComment = joinComments(c.HeadComment, cn.HeadComment)
+		c.LineComment = joinComments(c.LineComment, cn.LineComment)
+		c.FootComment = joinComments(c.FootComment, cn.FootComment)
+	}
+	if c.HeadComment != "" || c.LineComment != "" || c.FootComment != "" {
+		comments = append(comments, c)
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for i := range n.Content {
+			comments = appendJSONComments(comments, doc, path, n.Content[i])
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			comments = appendJSONComments(comments, doc,
+				append(path, n.Content[i].Value), n.Content[i+1], n.Content[i])
+		}
+	case yaml.SequenceNode:
+		for i := range n.Content {
+			comments = appendJSONComments(comments, doc,
+				append(path, strconv.Itoa(i)), n.Content[i])
+		}
+	}
+	return comments
+}
+
+// joinComments joins two comments of the same kind on a field.
+func joinComments(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + "\n" + b
+	}
+}
@@ -5,6 +5,7 @@ package kio
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"regexp"
@@ -40,6 +41,10 @@ type ByteReadWriter struct {
 	// PreserveSeqIndent if true adds kioutil.SeqIndentAnnotation to each resource
 	PreserveSeqIndent bool
 
+	// PreserveAnchors if true inflates YAML aliases when reading and restores
+	// them when writing. See ByteReader.PreserveAnchors.
+	PreserveAnchors bool
+
 	// Style is a style that is set on the Resource Node Document.
 	Style yaml.Style
 
@@ -57,6 +62,10 @@ type ByteReadWriter struct {
 	NoWrap             bool
 	WrappingAPIVersion string
 	WrappingKind       string
+
+	// Progress if set is called with the progress events of the ByteReader
+	// and ByteWriter.
+	Progress ProgressFunc
 }
 
 func (rw *ByteReadWriter) Read() ([]*yaml.RNode, error) {
@@ -64,7 +73,9 @@ func (rw *ByteReadWriter) Read() ([]*yaml.RNode, error) {
 		Reader:                rw.Reader,
 		OmitReaderAnnotations: rw.OmitReaderAnnotations,
 		PreserveSeqIndent:     rw.PreserveSeqIndent,
+		PreserveAnchors:       rw.PreserveAnchors,
 		WrapBareSeqNode:       rw.WrapBareSeqNode,
+		Progress:              rw.Progress,
 	}
 	val, err := b.Read()
 	rw.Results = b.Results
@@ -86,6 +97,7 @@ func (rw *ByteReadWriter) Write(nodes []*yaml.RNode) error {
 		Style:                 rw.Style,
 		FunctionConfig:        rw.FunctionConfig,
 		Results:               rw.Results,
+		Progress:              rw.Progress,
 	}
 	if !rw.NoWrap {
 		w.WrappingAPIVersion = rw.WrappingAPIVersion
@@ -160,6 +172,21 @@ type ByteReader struct {
 	// AnchorsAweigh set to true attempts to replace all YAML anchor aliases
 	// with their definitions (anchor values) immediately after the read.
 	AnchorsAweigh bool
+
+	// PreserveAnchors set to true replaces all YAML anchor aliases with their
+	// definitions like AnchorsAweigh, but records the anchors, aliases and merge
+	// keys in the kioutil.AnchorsAnnotation so that ByteWriter can restore them
+	// when the Resources are written.
+	PreserveAnchors bool
+
+	// Progress if set is called with a ProgressResourceRead event for each
+	// Resource read.
+	Progress ProgressFunc
+
+	// ScalarCompatibility configures how plain scalars which YAML 1.1 and YAML 1.2
+	// parsers interpret differently, e.g. `mode: 0644`, are read.
+	// Defaults to yaml.ScalarCompatibilityDefault.
+	ScalarCompatibility yaml.ScalarCompatibility
 }
 
 var _ Reader = &ByteReader{}
@@ -199,6 +226,9 @@ func (r *ByteReader) Read() ([]*yaml.RNode, error) {
 	if r.PreserveSeqIndent && r.OmitReaderAnnotations {
 		return nil, errors.Errorf(`"PreserveSeqIndent" option adds a reader annotation, please set "OmitReaderAnnotations" to false`)
 	}
+	if r.PreserveAnchors && r.OmitReaderAnnotations {
+		return nil, errors.Errorf(`"PreserveAnchors" option adds a reader annotation, please set "OmitReaderAnnotations" to false`)
+	}
 
 	output := ResourceNodeSlice{}
 
@@ -266,6 +296,7 @@ func (r *ByteReader) Read() ([]*yaml.RNode, error) {
 				for i := range items.Value.Content() {
 					// add items
 					output = append(output, yaml.NewRNode(items.Value.Content()[i]))
+					r.reportRead(len(output), input.Len())
 				}
 			}
 			continue
@@ -273,10 +304,16 @@ func (r *ByteReader) Read() ([]*yaml.RNode, error) {
 
 		// add the node to the list
 		output = append(output, node)
+		r.reportRead(len(output), input.Len())
 
 		// increment the index annotation value
 		index++
 	}
+	for _, n := range output {
+		if err := yaml.ResolveAmbiguousScalars(n, r.ScalarCompatibility); err != nil {
+			return nil, err
+		}
+	}
 	if r.AnchorsAweigh {
 		for _, n := range output {
 			if err = n.DeAnchor(); err != nil {
@@ -287,6 +324,12 @@ func (r *ByteReader) Read() ([]*yaml.RNode, error) {
 	return output, nil
 }
 
+// reportRead reports the number of Resources read so far from an input of size bytes.
+func (r *ByteReader) reportRead(resources, size int) {
+	r.Progress.report(ProgressEvent{
+		Type: ProgressResourceRead, Resources: resources, Bytes: int64(size)})
+}
+
 func (r *ByteReader) decode(originalYAML string, index int, decoder *yaml.Decoder) (*yaml.RNode, error) {
 	node := &yaml.Node{}
 	err := decoder.Decode(node)
@@ -315,6 +358,15 @@ func (r *ByteReader) decode(originalYAML string, index int, decoder *yaml.Decode
 		n = wrappedNode
 	}
 
+	var anchors yaml.AnchorRecords
+	if r.PreserveAnchors {
+		// inflate the aliases before any annotations are set, so that the annotations
+		// don't end up in copies of anchored metadata
+		if anchors, err = n.DeAnchorWithRecords(); err != nil {
+			return nil, errors.Wrap(err)
+		}
+	}
+
 	if r.SetAnnotations == nil {
 		r.SetAnnotations = map[string]string{}
 	}
@@ -345,5 +397,14 @@ func (r *ByteReader) decode(originalYAML string, index int, decoder *yaml.Decode
 			return nil, errors.Wrap(err)
 		}
 	}
+	if !anchors.IsEmpty() {
+		b, err := json.Marshal(anchors)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if err = n.PipeE(yaml.SetAnnotation(kioutil.AnchorsAnnotation, string(b))); err != nil {
+			return nil, errors.Wrap(err)
+		}
+	}
 	return n, nil
 }
@@ -238,6 +238,29 @@ i: j
 			instance: ByteReader{OmitReaderAnnotations: true},
 		},
 
+		//
+		//
+		//
+		{
+			name: "scalar_compatibility",
+			input: `mode: 0644
+enabled: on
+---
+flag: y
+`,
+			expectedItems: []string{
+				`mode: 420
+enabled: true
+`,
+				`flag: true
+`,
+			},
+			instance: ByteReader{
+				OmitReaderAnnotations: true,
+				ScalarCompatibility:   yaml.ScalarCompatibilityYAML1_1,
+			},
+		},
+
 		//
 		//
 		//
@@ -866,3 +866,135 @@ results:
 		})
 	}
 }
+
+func TestByteReadWriter_PreserveAnchors(t *testing.T) {
+	const input = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels: &labels
+    app: web
+spec:
+  selector:
+    matchLabels: *labels
+  template:
+    metadata:
+      labels: *labels
+    spec:
+      containers:
+      - &container
+        name: web
+        image: nginx
+        env: &env
+        - name: A
+          value: a
+      - <<: *container
+        name: sidecar
+        env: *env
+`
+	testCases := []struct {
+		name     string
+		filter   kio.Filter
+		expected string
+	}{
+		{
+			name:     "unmodified",
+			filter:   kio.FilterFunc(func(nodes []*yaml.RNode) ([]*yaml.RNode, error) { return nodes, nil }),
+			expected: input,
+		},
+		{
+			name: "modified_alias_is_left_inflated",
+			filter: kio.FilterAll(yaml.FilterFunc(func(rn *yaml.RNode) (*yaml.RNode, error) {
+				return rn.Pipe(yaml.Lookup("spec", "template", "metadata", "labels"), yaml.SetField("tier", yaml.NewStringRNode("frontend")))
+			})),
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels: &labels
+    app: web
+spec:
+  selector:
+    matchLabels: *labels
+  template:
+    metadata:
+      labels:
+        app: web
+        tier: frontend
+    spec:
+      containers:
+      - &container
+        name: web
+        image: nginx
+        env: &env
+        - name: A
+          value: a
+      - <<: *container
+        name: sidecar
+        env: *env
+`,
+		},
+		{
+			name: "modified_merged_field_is_kept",
+			filter: kio.FilterAll(yaml.FilterFunc(func(rn *yaml.RNode) (*yaml.RNode, error) {
+				return rn.Pipe(yaml.Lookup("spec", "template", "spec", "containers", "[name=sidecar]"), yaml.SetField("image", yaml.NewStringRNode("busybox")))
+			})),
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels: &labels
+    app: web
+spec:
+  selector:
+    matchLabels: *labels
+  template:
+    metadata:
+      labels: *labels
+    spec:
+      containers:
+      - &container
+        name: web
+        image: nginx
+        env: &env
+        - name: A
+          value: a
+      - <<: *container
+        name: sidecar
+        env: *env
+        image: busybox
+`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			rw := &kio.ByteReadWriter{
+				Reader:          bytes.NewBufferString(input),
+				Writer:          &out,
+				PreserveAnchors: true,
+			}
+			nodes, err := rw.Read()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			// filters see the inflated resources
+			image, err := nodes[0].Pipe(yaml.Lookup("spec", "template", "spec", "containers", "[name=sidecar]", "image"))
+			if !assert.NoError(t, err) || !assert.NotNil(t, image) {
+				t.FailNow()
+			}
+			assert.Equal(t, "nginx", image.YNode().Value)
+
+			nodes, err = tc.filter.Filter(nodes)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			if !assert.NoError(t, rw.Write(nodes)) {
+				t.FailNow()
+			}
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"io"
 	"path/filepath"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
@@ -47,6 +48,41 @@ type ByteWriter struct {
 
 	// Sort if set, will cause ByteWriter to sort the nodes before writing them.
 	Sort bool
+
+	// Indent is the number of spaces of each indentation level.
+	// Defaults to yaml.DefaultIndent.
+	Indent int
+
+	// SeqIndent is the indentation style of the sequences of Resources without a
+	// kioutil.SeqIndentAnnotation.  Defaults to yaml.CompactSequenceStyle.
+	SeqIndent yaml.SequenceIndentStyle
+
+	// StringQuoting is the quoting style of string values.
+	// Defaults to StringQuotingPreserve.
+	StringQuoting StringQuoting
+
+	// Width if set is the preferred width of the output lines, after which long
+	// strings are wrapped.  Strings are not wrapped by default.
+	Width int
+
+	// JSON if set, will cause ByteWriter to encode the Resources as JSON rather than
+	// YAML.  If WrappingKind is set the wrapping Resource is written as a single JSON
+	// document, otherwise each Resource is written as a separate JSON document.
+	// JSON has no comments, so comments are dropped unless CommentWriter is set.
+	JSON bool
+
+	// CommentWriter if set when writing JSON, is where the comments of the Resources
+	// are written, so that they are not lost.  See JSONComment for the format.
+	CommentWriter io.Writer
+
+	// Progress if set is called with a ProgressResourceWritten event after each
+	// Resource is written, or after all of them are written when they are wrapped.
+	Progress ProgressFunc
+
+	// ScalarCompatibility configures how plain scalars which YAML 1.1 and YAML 1.2
+	// parsers interpret differently, e.g. `mode: 0644`, are written.
+	// Defaults to yaml.ScalarCompatibilityDefault.
+	ScalarCompatibility yaml.ScalarCompatibility
 }
 
 var _ Writer = ByteWriter{}
@@ -60,6 +96,10 @@ func (w ByteWriter) Write(inputNodes []*yaml.RNode) error {
 		}
 	}
 
+	if w.Progress != nil {
+		w.Writer = &countingWriter{Writer: w.Writer}
+	}
+
 	// Even though we use the this value further down we must check this before removing annotations
 	jsonEncodeSingleBareNode := w.shouldJSONEncodeSingleBareNode(nodes)
 
@@ -69,6 +109,13 @@ func (w ByteWriter) Write(inputNodes []*yaml.RNode) error {
 		seqIndentsForNodes = append(seqIndentsForNodes, nodes[i].GetAnnotations()[kioutil.SeqIndentAnnotation])
 	}
 
+	// store anchors annotation value for each node in order to restore the anchors
+	// once the annotations have been cleared
+	var anchorsForNodes []string
+	for i := range nodes {
+		anchorsForNodes = append(anchorsForNodes, nodes[i].GetAnnotations()[kioutil.AnchorsAnnotation])
+	}
+
 	for i := range nodes {
 		// clean resources by removing annotations set by the Reader
 		if !w.KeepReaderAnnotations {
@@ -85,6 +132,11 @@ func (w ByteWriter) Write(inputNodes []*yaml.RNode) error {
 			if err != nil {
 				return errors.Wrap(err)
 			}
+
+			_, err = nodes[i].Pipe(yaml.ClearAnnotation(kioutil.AnchorsAnnotation))
+			if err != nil {
+				return errors.Wrap(err)
+			}
 		}
 		for _, a := range w.ClearAnnotations {
 			_, err := nodes[i].Pipe(yaml.ClearAnnotation(a))
@@ -97,34 +149,88 @@ func (w ByteWriter) Write(inputNodes []*yaml.RNode) error {
 			return err
 		}
 
+		if !w.KeepReaderAnnotations && anchorsForNodes[i] != "" {
+			var anchors yaml.AnchorRecords
+			if err := json.Unmarshal([]byte(anchorsForNodes[i]), &anchors); err != nil {
+				return errors.WrapPrefixf(err, "invalid %s annotation", kioutil.AnchorsAnnotation)
+			}
+			nodes[i].ReAnchor(anchors)
+		}
+
+		if err := yaml.ResolveAmbiguousScalars(nodes[i], w.ScalarCompatibility); err != nil {
+			return err
+		}
 		if w.Style != 0 {
 			nodes[i].YNode().Style = w.Style
 		}
+		setStringQuoting(nodes[i].YNode(), w.StringQuoting)
 	}
 
 	if jsonEncodeSingleBareNode {
 		encoder := json.NewEncoder(w.Writer)
 		encoder.SetIndent("", "  ")
-		return errors.Wrap(encoder.Encode(nodes[0]))
+		if err := encoder.Encode(nodes[0]); err != nil {
+			return errors.Wrap(err)
+		}
+		w.reportWritten(1)
+		return nil
+	}
+
+	if w.JSON {
+		return w.writeJSON(nodes)
 	}
 
 	encoder := yaml.NewEncoder(w.Writer)
 	defer encoder.Close()
+	if w.Indent > 0 {
+		encoder.SetIndent(w.Indent)
+	}
+	if w.Width > 0 {
+		encoder.SetWidth(w.Width)
+	}
+	w.setSeqIndent(encoder, "")
 	// don't wrap the elements
 	if w.WrappingKind == "" {
 		for i := range nodes {
-			if seqIndentsForNodes[i] == string(yaml.WideSequenceStyle) {
-				encoder.DefaultSeqIndent()
-			} else {
-				encoder.CompactSeqIndent()
-			}
+			w.setSeqIndent(encoder, seqIndentsForNodes[i])
 			if err := encoder.Encode(upWrapBareSequenceNode(nodes[i].Document())); err != nil {
 				return errors.Wrap(err)
 			}
+			w.reportWritten(i + 1)
 		}
 		return nil
 	}
-	// wrap the elements in a list
+	if err := encoder.Encode(w.wrap(nodes)); err != nil {
+		return err
+	}
+	w.reportWritten(len(nodes))
+	return nil
+}
+
+// reportWritten reports the number of Resources written so far.
+func (w ByteWriter) reportWritten(resources int) {
+	if counter, ok := w.Writer.(*countingWriter); ok {
+		w.Progress.report(ProgressEvent{
+			Type: ProgressResourceWritten, Resources: resources, Bytes: counter.n})
+	}
+}
+
+// setSeqIndent sets the sequence indentation style of the encoder to the style of
+// the seqindent annotation, or if it is empty to the SeqIndent of the writer.
+func (w ByteWriter) setSeqIndent(encoder *yaml.Encoder, annotation string) {
+	style := yaml.SequenceIndentStyle(annotation)
+	if style == "" {
+		style = w.SeqIndent
+	}
+	if style == yaml.WideSequenceStyle {
+		encoder.DefaultSeqIndent()
+	} else {
+		encoder.CompactSeqIndent()
+	}
+}
+
+// wrap returns a document wrapping the nodes in the items of a WrappingKind.
+func (w ByteWriter) wrap(nodes []*yaml.RNode) *yaml.Node {
 	items := &yaml.Node{Kind: yaml.SequenceNode}
 	list := &yaml.Node{
 		Kind:  yaml.MappingNode,
@@ -152,7 +258,7 @@ func (w ByteWriter) Write(inputNodes []*yaml.RNode) error {
 	for i := range nodes {
 		items.Content = append(items.Content, nodes[i].YNode())
 	}
-	return encoder.Encode(doc)
+	return doc
 }
 
 func copyRNodes(in []*yaml.RNode) []*yaml.RNode {
@@ -187,6 +293,59 @@ func (w ByteWriter) shouldJSONEncodeSingleBareNode(nodes []*yaml.RNode) bool {
 	return false
 }
 
+// StringQuoting is the quoting style of string values written by a ByteWriter.
+type StringQuoting string
+
+const (
+	// StringQuotingPreserve keeps the quoting of string values as it was read.
+	StringQuotingPreserve StringQuoting = ""
+
+	// StringQuotingMinimal only quotes string values which would not be read back
+	// as strings if they were not quoted, including YAML 1.1 non-string values
+	// such as "on" or "yes".
+	StringQuotingMinimal StringQuoting = "minimal"
+
+	// StringQuotingDouble double quotes all single line string values.
+	StringQuotingDouble StringQuoting = "double"
+
+	// StringQuotingSingle single quotes all single line string values.
+	StringQuotingSingle StringQuoting = "single"
+)
+
+// setStringQuoting recursively sets the style of the string values of n to the
+// quoting style q.  Mapping keys and multi-line strings are not modified.
+func setStringQuoting(n *yaml.Node, q StringQuoting) {
+	if q == StringQuotingPreserve {
+		return
+	}
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for i := range n.Content {
+			setStringQuoting(n.Content[i], q)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			setStringQuoting(n.Content[i], q)
+		}
+	case yaml.ScalarNode:
+		if n.ShortTag() != yaml.NodeTagString || strings.Contains(n.Value, "\n") ||
+			n.Style&(yaml.LiteralStyle|yaml.FoldedStyle|yaml.TaggedStyle) != 0 {
+			return
+		}
+		switch q {
+		case StringQuotingMinimal:
+			n.Style = 0
+			if yaml.IsYaml1_1NonString(n) {
+				n.Style = yaml.DoubleQuotedStyle
+			}
+		case StringQuotingDouble:
+			n.Style = yaml.DoubleQuotedStyle
+		case StringQuotingSingle:
+			n.Style = yaml.SingleQuotedStyle
+		}
+	}
+}
+
 // upWrapBareSequenceNode unwraps the bare sequence nodes wrapped by yaml.BareSeqNodeWrappingKey
 func upWrapBareSequenceNode(node *yaml.Node) *yaml.Node {
 	rNode := yaml.NewRNode(node)
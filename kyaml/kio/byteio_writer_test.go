@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	. "sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
@@ -509,6 +510,184 @@ items:
 {"a": "b", "metadata": {"annotations": {"internal.config.kubernetes.io/path": "test-1.json"}}}
 ---
 {"c": "d", "metadata": {"annotations": {"internal.config.kubernetes.io/path": "test-2.json"}}}
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name:     "indent_and_seq_indent",
+			instance: ByteWriter{Indent: 4, SeqIndent: yaml.WideSequenceStyle},
+			items: []string{
+				`a:
+  b:
+  - c
+  - d
+`,
+				`e:
+- f
+metadata:
+  annotations:
+    internal.config.kubernetes.io/seqindent: "compact"
+`,
+			},
+			expectedOutput: `a:
+    b:
+        - c
+        - d
+---
+e:
+  - f
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name:     "string_quoting_minimal",
+			instance: ByteWriter{StringQuoting: StringQuotingMinimal},
+			items: []string{
+				`a: "b"
+c: '1'
+d: "on"
+e: 'f: g'
+"h": 'i'
+j: 2
+`,
+			},
+			expectedOutput: `a: b
+c: "1"
+d: "on"
+e: "f: g"
+"h": i
+j: 2
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name:     "string_quoting_double",
+			instance: ByteWriter{StringQuoting: StringQuotingDouble},
+			items: []string{
+				`a: b
+c: [d, 'e']
+f: |
+  g
+h: true
+`,
+			},
+			expectedOutput: `a: "b"
+c: ["d", "e"]
+f: |
+  g
+h: true
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name:     "scalar_compatibility_yaml1.1",
+			instance: ByteWriter{ScalarCompatibility: yaml.ScalarCompatibilityYAML1_1},
+			items: []string{
+				`mode: 0644
+enabled: on
+name: "on"
+`,
+			},
+			expectedOutput: `mode: 420
+enabled: true
+name: "on"
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name:     "scalar_compatibility_string",
+			instance: ByteWriter{ScalarCompatibility: yaml.ScalarCompatibilityString},
+			items: []string{
+				`mode: 0644
+enabled: on
+replicas: 1
+`,
+			},
+			expectedOutput: `mode: "0644"
+enabled: "on"
+replicas: 1
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name:     "width",
+			instance: ByteWriter{Width: 40},
+			items: []string{
+				`a: a long string that will be wrapped because it is longer than the width`,
+			},
+			expectedOutput: `a: a long string that will be wrapped because
+  it is longer than the width
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name:     "json_multiple_items",
+			instance: ByteWriter{JSON: true},
+			items: []string{
+				`c: d # second`,
+				`a: [b, c] #first`,
+			},
+			expectedOutput: `
+{
+  "c": "d"
+}
+{
+  "a": [
+    "b",
+    "c"
+  ]
+}
+`,
+		},
+
+		//
+		// Test Case
+		//
+		{
+			name: "json_wrap_resource_list",
+			instance: ByteWriter{
+				JSON:               true,
+				WrappingKind:       ResourceListKind,
+				WrappingAPIVersion: ResourceListAPIVersion,
+			},
+			items: []string{
+				`a: b #first`,
+			},
+			functionConfig: `e: f`,
+			expectedOutput: `
+{
+  "apiVersion": "config.kubernetes.io/v1",
+  "functionConfig": {
+    "e": "f"
+  },
+  "items": [
+    {
+      "a": "b"
+    }
+  ],
+  "kind": "ResourceList"
+}
 `,
 		},
 	}
@@ -551,3 +730,48 @@ items:
 		})
 	}
 }
+
+func TestByteWriter_JSONComments(t *testing.T) {
+	out := &bytes.Buffer{}
+	comments := &bytes.Buffer{}
+	w := ByteWriter{Writer: out, JSON: true, CommentWriter: comments}
+	err := w.Write([]*yaml.RNode{
+		yaml.MustParse(`a: b`),
+		yaml.MustParse(`# head
+c: d # line
+e:
+# element
+- f
+`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "a": "b"
+}
+{
+  "c": "d",
+  "e": [
+    "f"
+  ]
+}
+`, out.String())
+	assert.Equal(t, `[
+  {
+    "document": 1,
+    "path": [
+      "c"
+    ],
+    "headComment": "# head",
+    "lineComment": "# line"
+  },
+  {
+    "document": 1,
+    "path": [
+      "e",
+      "0"
+    ],
+    "headComment": "# element"
+  }
+]
+`, comments.String())
+}
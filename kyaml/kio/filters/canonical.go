@@ -0,0 +1,142 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters
+
+import (
+	"sort"
+
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// canonicalRootFieldOrder are the fields which come first in a Resource
+var canonicalRootFieldOrder = []string{
+	yaml.APIVersionField, yaml.KindField, yaml.MetadataField,
+}
+
+// canonicalMetadataFieldOrder are the fields which come first in the Resource metadata
+var canonicalMetadataFieldOrder = []string{
+	yaml.NameField, "generateName", yaml.NamespaceField, yaml.LabelsField, yaml.AnnotationsField,
+}
+
+// CanonicalFormatFilter formats Resources into a canonical form, so that formatting
+// the same Resources always produces the same output regardless of how they were
+// written:
+//
+//   - apiVersion, kind and metadata are the first fields of the Resource, and
+//     name, namespace, labels and annotations the first fields of the metadata
+//   - other fields are ordered as they are declared in the Resource's openapi schema
+//   - fields which are not in the schema follow the schema fields in the order used
+//     by FormatFilter
+//   - sequences are indented in the compact style
+//
+// Unlike FormatFilter, CanonicalFormatFilter never reorders list elements.
+// Resources with the FmtAnnotation set to FmtStrategyNone are not formatted.
+type CanonicalFormatFilter struct {
+	// Schema if set is used as the schema of all Resources, rather than the
+	// schema of their type.
+	Schema *openapi.ResourceSchema `yaml:"-"`
+}
+
+var _ kio.Filter = CanonicalFormatFilter{}
+
+func (f CanonicalFormatFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	for i := range nodes {
+		fmtStrategy, err := getFormattingStrategy(nodes[i])
+		if err != nil {
+			return nil, err
+		}
+		if fmtStrategy == FmtStrategyNone {
+			continue
+		}
+		s := f.Schema
+		if s == nil {
+			meta, _ := nodes[i].GetMeta()
+			s = openapi.SchemaForResourceType(meta.TypeMeta)
+		}
+		canonicalOrder(nodes[i].YNode(), s, canonicalRootFieldOrder)
+		if nodes[i].GetAnnotations()[kioutil.SeqIndentAnnotation] != "" {
+			if err := nodes[i].PipeE(yaml.SetAnnotation(
+				kioutil.SeqIndentAnnotation, string(yaml.CompactSequenceStyle))); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// canonicalOrder recursively orders the fields of n.  The fields in first come first.
+func canonicalOrder(n *yaml.Node, s *openapi.ResourceSchema, first []string) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for i := range n.Content {
+			canonicalOrder(n.Content[i], s, first)
+		}
+	case yaml.SequenceNode:
+		var es *openapi.ResourceSchema
+		if s != nil {
+			es = s.Elements()
+		}
+		for i := range n.Content {
+			canonicalOrder(n.Content[i], es, nil)
+		}
+	case yaml.MappingNode:
+		rank := map[string]int{}
+		for _, name := range first {
+			rank[name] = len(rank)
+		}
+		for _, name := range s.PropertyNames() {
+			if _, found := rank[name]; !found {
+				rank[name] = len(rank)
+			}
+		}
+		sort.Stable(canonicalMapContents{Node: n, rank: rank})
+
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			name := n.Content[i].Value
+			var fs *openapi.ResourceSchema
+			if s != nil {
+				fs = s.Field(name)
+			}
+			var fieldFirst []string
+			if len(first) > 0 && name == yaml.MetadataField {
+				fieldFirst = canonicalMetadataFieldOrder
+			}
+			canonicalOrder(n.Content[i+1], fs, fieldFirst)
+		}
+	}
+}
+
+// canonicalMapContents sorts the fields of a MappingNode by their rank, followed
+// by the fields without a rank in the order used by sortedMapContents.
+type canonicalMapContents struct {
+	*yaml.Node
+	rank map[string]int
+}
+
+func (s canonicalMapContents) Len() int {
+	return len(s.Content) / 2
+}
+
+func (s canonicalMapContents) Swap(i, j int) {
+	sortedMapContents(*s.Node).Swap(i, j)
+}
+
+func (s canonicalMapContents) Less(i, j int) bool {
+	iName, jName := s.Content[i*2].Value, s.Content[j*2].Value
+	iRank, foundI := s.rank[iName]
+	jRank, foundJ := s.rank[jName]
+	switch {
+	case foundI && foundJ:
+		return iRank < jRank
+	case foundI:
+		return true
+	case foundJ:
+		return false
+	default:
+		return sortedMapContents(*s.Node).Less(i, j)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package filters_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	. "sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestCanonicalFormatFilter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schema   string
+		input    string
+		expected string
+	}{
+		{
+			name: "builtin schema",
+			input: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+        args:
+        - b
+        - a
+  replicas: 1
+  selector:
+    matchLabels:
+      app: nginx
+status: {}
+metadata:
+  annotations:
+    a: b
+  namespace: default
+  name: nginx
+kind: Deployment
+apiVersion: apps/v1
+`,
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: default
+  annotations:
+    a: b
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: nginx
+  template:
+    spec:
+      containers:
+      - args:
+        - b
+        - a
+        image: nginx
+        name: nginx
+status: {}
+`,
+		},
+		{
+			name: "schema declaration order",
+			schema: `{
+  "definitions": {
+    "io.example.v1.Widget": {
+      "properties": {
+        "spec": {
+          "properties": {
+            "size": {"type": "integer"},
+            "color": {"type": "string"},
+            "items": {
+              "type": "array",
+              "items": {
+                "properties": {
+                  "value": {"type": "string"},
+                  "key": {"type": "string"}
+                }
+              }
+            }
+          }
+        }
+      },
+      "x-kubernetes-group-version-kind": [
+        {"group": "example.io", "kind": "Widget", "version": "v1"}
+      ]
+    }
+  }
+}`,
+			input: `
+kind: Widget
+apiVersion: example.io/v1
+metadata:
+  name: w
+spec:
+  unknown: true
+  items:
+  - key: b
+    value: "2"
+  - key: a
+    value: "1"
+  color: blue
+  size: 3
+`,
+			expected: `apiVersion: example.io/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  size: 3
+  color: blue
+  items:
+  - value: "2"
+    key: b
+  - value: "1"
+    key: a
+  unknown: true
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var f CanonicalFormatFilter
+			if tc.schema != "" {
+				openapi.ResetOpenAPI()
+				t.Cleanup(openapi.ResetOpenAPI)
+				require.NoError(t, openapi.AddSchema([]byte(tc.schema)))
+				f.Schema = openapi.SchemaForResourceType(
+					yaml.TypeMeta{APIVersion: "example.io/v1", Kind: "Widget"})
+				require.NotNil(t, f.Schema)
+			}
+
+			var out bytes.Buffer
+			err := kio.Pipeline{
+				Inputs:  []kio.Reader{&kio.ByteReader{Reader: bytes.NewBufferString(tc.input)}},
+				Filters: []kio.Filter{f},
+				Outputs: []kio.Writer{kio.ByteWriter{Writer: &out}},
+			}.Execute()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+}
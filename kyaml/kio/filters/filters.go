@@ -16,11 +16,12 @@ import (
 // Filters are the list of known filters for unmarshalling a filter into a concrete
 // implementation.
 var Filters = map[string]func() kio.Filter{
-	"FileSetter":    func() kio.Filter { return &FileSetter{} },
-	"FormatFilter":  func() kio.Filter { return &FormatFilter{} },
-	"GrepFilter":    func() kio.Filter { return GrepFilter{} },
-	"MatchModifier": func() kio.Filter { return &MatchModifyFilter{} },
-	"Modifier":      func() kio.Filter { return &Modifier{} },
+	"CanonicalFormatFilter": func() kio.Filter { return &CanonicalFormatFilter{} },
+	"FileSetter":            func() kio.Filter { return &FileSetter{} },
+	"FormatFilter":          func() kio.Filter { return &FormatFilter{} },
+	"GrepFilter":            func() kio.Filter { return GrepFilter{} },
+	"MatchModifier":         func() kio.Filter { return &MatchModifyFilter{} },
+	"Modifier":              func() kio.Filter { return &Modifier{} },
 }
 
 // filter wraps a kio.filter so that it can be unmarshalled from yaml.
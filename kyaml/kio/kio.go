@@ -8,6 +8,7 @@ package kio
 import (
 	"fmt"
 	"strconv"
+	"sync"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
@@ -71,6 +72,34 @@ func (fn FilterFunc) Filter(o []*yaml.RNode) ([]*yaml.RNode, error) {
 	return fn(o)
 }
 
+// ItemIndependentFilter is a Filter whose result for each Resource depends only on that
+// Resource, so that filtering the Resources one at a time and concatenating the results
+// gives the same result as filtering all the Resources at once.  A Pipeline with
+// Concurrency may run ItemIndependentFilters on several Resources concurrently, so
+// they must be safe for concurrent use.
+type ItemIndependentFilter interface {
+	Filter
+
+	// ItemIndependent returns true if the Filter is item-independent.
+	ItemIndependent() bool
+}
+
+// ItemIndependent marks the filter as an ItemIndependentFilter.  The filter must
+// meet the requirements of ItemIndependentFilter.
+func ItemIndependent(filter Filter) ItemIndependentFilter {
+	return itemIndependentFilter{filter: filter}
+}
+
+type itemIndependentFilter struct {
+	filter Filter
+}
+
+func (f itemIndependentFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	return f.filter.Filter(nodes)
+}
+
+func (itemIndependentFilter) ItemIndependent() bool { return true }
+
 // Pipeline reads Resource Configuration from a set of Inputs, applies some
 // transformation filters, and writes the results to a set of Outputs.
 //
@@ -96,6 +125,12 @@ type Pipeline struct {
 	// filter in the list. This is useful when subsequent functions in the
 	// pipeline may generate new resources.
 	ContinueOnEmptyResult bool `yaml:"continueOnEmptyResult,omitempty"`
+
+	// Concurrency if greater than 1 is the number of goroutines running
+	// ItemIndependentFilters.  Each Resource is filtered separately, and the results
+	// are concatenated in the order of the Resources.  Other filters always run on
+	// all the Resources at once.
+	Concurrency int `yaml:"concurrency,omitempty"`
 }
 
 // Execute executes each step in the sequence, returning immediately after encountering
@@ -134,7 +169,7 @@ func (p Pipeline) ExecuteWithCallback(callback PipelineExecuteCallbackFunc) erro
 		if callback != nil {
 			callback(op)
 		}
-		result, err = op.Filter(result)
+		result, err = p.filter(op, result)
 		// TODO (issue 2872): This len(result) == 0 should be removed and empty result list should be
 		// handled by outputs. However currently some writer like LocalPackageReadWriter
 		// will clear the output directory and which will cause unpredictable results
@@ -159,6 +194,43 @@ func (p Pipeline) ExecuteWithCallback(callback PipelineExecuteCallbackFunc) erro
 	return nil
 }
 
+// filter applies the filter to the nodes, concurrently if the filter is item-independent.
+func (p Pipeline) filter(op Filter, nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	f, ok := op.(ItemIndependentFilter)
+	if !ok || !f.ItemIndependent() || p.Concurrency <= 1 || len(nodes) <= 1 {
+		return op.Filter(nodes)
+	}
+
+	results := make([][]*yaml.RNode, len(nodes))
+	errs := make([]error, len(nodes))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < p.Concurrency && w < len(nodes); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], errs[i] = op.Filter([]*yaml.RNode{nodes[i]})
+			}
+		}()
+	}
+	for i := range nodes {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var result []*yaml.RNode
+	for i := range results {
+		// return the error of the first Resource, as a serial filter would
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		result = append(result, results[i]...)
+	}
+	return result, nil
+}
+
 // FilterAll runs the yaml.Filter against all inputs
 func FilterAll(filter yaml.Filter) Filter {
 	return FilterFunc(func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
@@ -5,9 +5,12 @@ package kio_test
 
 import (
 	"bytes"
+	"fmt"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -30,6 +33,71 @@ func TestPipe(t *testing.T) {
 	}
 }
 
+func TestPipelineConcurrency(t *testing.T) {
+	var inputs []string
+	for i := 0; i < 50; i++ {
+		inputs = append(inputs, fmt.Sprintf("kind: Foo\nmetadata:\n  name: foo-%d\n", i))
+	}
+	nodes, err := ParseAll(inputs...)
+	assert.NoError(t, err)
+
+	var concurrent, maxConcurrent int32
+	var out []*yaml.RNode
+	err = Pipeline{
+		Inputs: []Reader{ResourceNodeSlice(nodes)},
+		Filters: []Filter{ItemIndependent(FilterFunc(func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+			n := atomic.AddInt32(&concurrent, 1)
+			defer atomic.AddInt32(&concurrent, -1)
+			for {
+				m := atomic.LoadInt32(&maxConcurrent)
+				if n <= m || atomic.CompareAndSwapInt32(&maxConcurrent, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			// drop the Resources with names ending in 5 and duplicate the others
+			if strings.HasSuffix(nodes[0].GetName(), "5") {
+				return nil, nil
+			}
+			return []*yaml.RNode{nodes[0], nodes[0].Copy()}, nil
+		}))},
+		Outputs:     []Writer{WriterFunc(func(nodes []*yaml.RNode) error { out = nodes; return nil })},
+		Concurrency: 4,
+	}.Execute()
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, maxConcurrent, int32(4))
+	assert.Greater(t, maxConcurrent, int32(1))
+
+	var names []string
+	for i := 0; i < 50; i++ {
+		if i%10 != 5 {
+			names = append(names, fmt.Sprintf("foo-%d", i), fmt.Sprintf("foo-%d", i))
+		}
+	}
+	var actual []string
+	for i := range out {
+		actual = append(actual, out[i].GetName())
+	}
+	assert.Equal(t, names, actual)
+}
+
+func TestPipelineConcurrency_error(t *testing.T) {
+	nodes, err := ParseAll("kind: Foo\nmetadata:\n  name: a\n", "kind: Foo\nmetadata:\n  name: b\n",
+		"kind: Foo\nmetadata:\n  name: c\n")
+	assert.NoError(t, err)
+	err = Pipeline{
+		Inputs: []Reader{ResourceNodeSlice(nodes)},
+		Filters: []Filter{ItemIndependent(FilterFunc(func(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+			if nodes[0].GetName() != "a" {
+				return nil, fmt.Errorf("failed %s", nodes[0].GetName())
+			}
+			return nodes, nil
+		}))},
+		Concurrency: 2,
+	}.Execute()
+	assert.EqualError(t, err, "failed b")
+}
+
 type mockCallback struct {
 	mock.Mock
 }
@@ -30,6 +30,10 @@ const (
 	// SeqIndentAnnotation records the sequence nodes indentation of the input resource
 	SeqIndentAnnotation AnnotationKey = internalPrefix + "seqindent"
 
+	// AnchorsAnnotation records the YAML anchors and aliases of the input resource
+	// which were inflated when it was read
+	AnchorsAnnotation AnnotationKey = internalPrefix + "anchors"
+
 	// IdAnnotation records the id of the resource to map inputs to outputs
 	IdAnnotation AnnotationKey = internalPrefix + "id"
 
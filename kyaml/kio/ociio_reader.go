@@ -0,0 +1,106 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/internal/oci"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// OCIArtifactType is the artifact type of the packages published by OCIWriter.
+	OCIArtifactType = "application/vnd.kustomize.package.v1"
+
+	// OCILayerMediaType is the media type of the layer of the packages published
+	// by OCIWriter, a gzip compressed tar archive of the package files.
+	OCILayerMediaType = "application/vnd.kustomize.package.layer.v1.tar+gzip"
+)
+
+// IsOCIReference returns true if ref is a reference to an OCI artifact,
+// e.g. oci://registry.example.com/team/app:v1.
+func IsOCIReference(ref string) bool {
+	return strings.HasPrefix(ref, oci.Scheme)
+}
+
+// IsPinnedOCIReference returns true if ref is a reference to an OCI artifact
+// by the digest of its manifest, e.g.
+// oci://registry.example.com/team/app@sha256:..., rather than by a tag.
+func IsPinnedOCIReference(ref string) bool {
+	r, err := oci.ParseReference(ref)
+	return err == nil && r.Digest != ""
+}
+
+// OCIReader reads ResourceNodes from a package published as an OCI artifact by
+// OCIWriter.  Each Resource is annotated with the path of the file it was read
+// from in the package.
+//
+// Credentials of the registry are read from the docker config file, or its
+// credential helpers, and are only sent to the authentication realms on the
+// host of the registry or in $KUSTOMIZE_OCI_TRUSTED_REALM_HOSTS.  Registries
+// on the loopback interface, e.g. localhost:5000, are accessed over plain http.
+type OCIReader struct {
+	// Reference is the reference of the artifact, e.g.
+	// oci://registry.example.com/team/app:v1.  The tag defaults to latest.
+	Reference string
+
+	// Client is the client used to access the registry.  Defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	// OmitReaderAnnotations will cause the reader to skip annotating Resources with
+	// the file path and index.
+	OmitReaderAnnotations bool
+
+	// SetAnnotations are annotations to set on the Resources as they are read.
+	SetAnnotations map[string]string
+
+	// PreserveSeqIndent if true adds kioutil.SeqIndentAnnotation to each resource
+	PreserveSeqIndent bool
+}
+
+var _ Reader = OCIReader{}
+
+// Read pulls the artifact and reads the Resources from it.
+func (r OCIReader) Read() ([]*yaml.RNode, error) {
+	content, err := r.pull()
+	if err != nil {
+		return nil, err
+	}
+	return ArchiveReader{
+		Reader:                bytes.NewReader(content),
+		Format:                TarGzipArchive,
+		MatchFilesGlob:        MatchAll,
+		OmitReaderAnnotations: r.OmitReaderAnnotations,
+		SetAnnotations:        r.SetAnnotations,
+		PreserveSeqIndent:     r.PreserveSeqIndent,
+	}.Read()
+}
+
+// Extract pulls the artifact and writes all the files of the package, not
+// only those holding Resources, to dir, e.g. to build the kustomization
+// in it.
+func (r OCIReader) Extract(fSys filesys.FileSystem, dir string) error {
+	content, err := r.pull()
+	if err != nil {
+		return err
+	}
+	err = ArchiveReader{Reader: bytes.NewReader(content), Format: TarGzipArchive}.Extract(fSys, dir)
+	return errors.WrapPrefixf(err, "extracting %s", r.Reference)
+}
+
+// pull pulls the layer of the artifact holding the package.
+func (r OCIReader) pull() ([]byte, error) {
+	ref, err := oci.ParseReference(r.Reference)
+	if err != nil {
+		return nil, err
+	}
+	c := &oci.Client{HTTPClient: r.Client}
+	return c.Pull(ref, OCILayerMediaType)
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/internal/oci"
+	"sigs.k8s.io/kustomize/kyaml/internal/oci/ocitest"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+func TestOCIReadWriter_roundTrip(t *testing.T) {
+	registry := ocitest.NewRegistry(t, true)
+	ref := "oci://" + registry.Host + "/team/app:v1"
+	input, err := kio.ParseAll(`kind: Deployment
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/path: apps/app.yaml
+`, `kind: Service
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/path: apps/app.yaml
+    config.kubernetes.io/index: '1'
+`, `kind: ConfigMap
+metadata:
+  name: settings
+`)
+	require.NoError(t, err)
+
+	var digest string
+	require.NoError(t, kio.OCIWriter{Reference: ref, Digest: &digest}.Write(input))
+	assert.Contains(t, string(registry.Manifest("team/app", "v1")), `"artifactType":"`+kio.OCIArtifactType+`"`)
+
+	for _, ref := range []string{ref, "oci://" + registry.Host + "/team/app@" + digest} {
+		nodes, err := kio.OCIReader{Reference: ref}.Read()
+		require.NoError(t, err)
+		var got []string
+		for _, n := range nodes {
+			path, index, err := kioutil.GetFileAnnotations(n)
+			require.NoError(t, err)
+			got = append(got, n.GetKind()+" "+path+" "+index)
+		}
+		assert.Equal(t, []string{
+			"Deployment apps/app.yaml 0",
+			"Service apps/app.yaml 1",
+			"ConfigMap configmap_settings.yaml 0",
+		}, got)
+	}
+
+	_, err = kio.OCIReader{Reference: "registry.example.com/app"}.Read()
+	assert.EqualError(t, err, `OCI reference "registry.example.com/app" must start with oci://`)
+	assert.True(t, kio.IsOCIReference(ref))
+	assert.False(t, kio.IsOCIReference("apps/"))
+	assert.False(t, kio.IsPinnedOCIReference(ref))
+	assert.True(t, kio.IsPinnedOCIReference("oci://registry.example.com/app@sha256:"+strings.Repeat("0", 64)))
+}
+
+func TestOCIReader_Extract(t *testing.T) {
+	registry := ocitest.NewRegistry(t, false)
+	push := func(repository string, files map[string]string) {
+		t.Helper()
+		var archive bytes.Buffer
+		gz := gzip.NewWriter(&archive)
+		tw := tar.NewWriter(gz)
+		for name, content := range files {
+			require.NoError(t, tw.WriteHeader(&tar.Header{
+				Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}))
+			_, err := tw.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+		ref, err := oci.ParseReference("oci://" + registry.Host + "/" + repository)
+		require.NoError(t, err)
+		_, err = (&oci.Client{}).Push(ref, kio.OCIArtifactType, kio.OCILayerMediaType, archive.Bytes())
+		require.NoError(t, err)
+	}
+
+	push("platform/base", map[string]string{
+		"kustomization.yaml":       "configMapGenerator:\n- name: app\n  envs: [app.env]\n",
+		"app.env":                  "LEVEL=debug\n",
+		"components/kustomization": "kind: Component\n",
+	})
+	fSys := filesys.MakeFsInMemory()
+	require.NoError(t, kio.OCIReader{Reference: "oci://" + registry.Host + "/platform/base"}.Extract(fSys, "/base"))
+	for path, content := range map[string]string{
+		"/base/kustomization.yaml":       "configMapGenerator:\n- name: app\n  envs: [app.env]\n",
+		"/base/app.env":                  "LEVEL=debug\n",
+		"/base/components/kustomization": "kind: Component\n",
+	} {
+		b, err := fSys.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(b))
+	}
+
+	push("platform/evil", map[string]string{"../kustomization.yaml": "resources: []\n"})
+	err := kio.OCIReader{Reference: "oci://" + registry.Host + "/platform/evil"}.Extract(fSys, "/evil")
+	assert.EqualError(t, err, "extracting oci://"+registry.Host+"/platform/evil: "+
+		"archive entry must be a relative path within the archive: ../kustomization.yaml")
+	assert.False(t, fSys.Exists("/kustomization.yaml"))
+}
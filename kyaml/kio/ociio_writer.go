@@ -0,0 +1,65 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import (
+	"bytes"
+	"net/http"
+
+	"sigs.k8s.io/kustomize/kyaml/internal/oci"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// OCIWriter publishes ResourceNodes as a package to an OCI registry.  The
+// package is a single layer artifact, its layer a gzip compressed tar archive
+// of the Resources grouped into files by their kioutil.PathAnnotation.
+//
+// Credentials of the registry are read from the docker config file, or its
+// credential helpers, and are only sent to the authentication realms on the
+// host of the registry or in $KUSTOMIZE_OCI_TRUSTED_REALM_HOSTS.  Registries
+// on the loopback interface, e.g. localhost:5000, are accessed over plain http.
+type OCIWriter struct {
+	// Reference is the reference to publish the artifact to, e.g.
+	// oci://registry.example.com/team/app:v1.  The tag defaults to latest.
+	Reference string
+
+	// Client is the client used to access the registry.  Defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	// ClearAnnotations will clear annotations before writing the resources
+	ClearAnnotations []string
+
+	// Digest is set to the digest of the manifest of the published artifact,
+	// if not nil.
+	Digest *string
+}
+
+var _ Writer = OCIWriter{}
+
+// Write publishes the Resources.
+func (w OCIWriter) Write(nodes []*yaml.RNode) error {
+	ref, err := oci.ParseReference(w.Reference)
+	if err != nil {
+		return err
+	}
+	var archive bytes.Buffer
+	err = ArchiveWriter{
+		Writer:           &archive,
+		Format:           TarGzipArchive,
+		ClearAnnotations: w.ClearAnnotations,
+	}.Write(nodes)
+	if err != nil {
+		return err
+	}
+	c := &oci.Client{HTTPClient: w.Client}
+	digest, err := c.Push(ref, OCIArtifactType, OCILayerMediaType, archive.Bytes())
+	if err != nil {
+		return err
+	}
+	if w.Digest != nil {
+		*w.Digest = digest
+	}
+	return nil
+}
@@ -6,7 +6,9 @@ package kio
 import (
 	"fmt"
 	"os"
+	pathpkg "path"
 	"path/filepath"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -89,6 +91,10 @@ type LocalPackageReadWriter struct {
 	// sequence nodes into map node with key yaml.BareSeqNodeWrappingKey
 	// note that this wrapping is different and not related to ResourceList wrapping
 	WrapBareSeqNode bool
+
+	// Progress if set is called with the progress events of the LocalPackageReader
+	// and LocalPackageWriter.
+	Progress ProgressFunc
 }
 
 func (r *LocalPackageReadWriter) Read() ([]*yaml.RNode, error) {
@@ -103,6 +109,7 @@ func (r *LocalPackageReadWriter) Read() ([]*yaml.RNode, error) {
 		PreserveSeqIndent:   r.PreserveSeqIndent,
 		FileSystem:          r.FileSystem,
 		WrapBareSeqNode:     r.WrapBareSeqNode,
+		Progress:            r.Progress,
 	}.Read()
 	if err != nil {
 		return nil, errors.Wrap(err)
@@ -131,6 +138,7 @@ func (r *LocalPackageReadWriter) Write(nodes []*yaml.RNode) error {
 		ClearAnnotations:      clear,
 		KeepReaderAnnotations: r.KeepReaderAnnotations,
 		FileSystem:            r.FileSystem,
+		Progress:              r.Progress,
 	}.Write(nodes)
 	if err != nil {
 		return errors.Wrap(err)
@@ -161,6 +169,25 @@ func (r *LocalPackageReadWriter) getFiles(nodes []*yaml.RNode) (sets.String, err
 // relPath is an OS specific relative path
 type LocalPackageSkipFileFunc func(relPath string) bool
 
+// SymlinkPolicy is how a LocalPackageReader handles symbolic links.
+type SymlinkPolicy string
+
+const (
+	// SymlinkPolicyDefault handles symbolic links as files, so symbolic links to
+	// directories are not walked.
+	SymlinkPolicyDefault SymlinkPolicy = ""
+
+	// SymlinkPolicyFollow reads symbolic links to files as files, and symbolic links to
+	// directories as directories.
+	SymlinkPolicyFollow SymlinkPolicy = "follow"
+
+	// SymlinkPolicyIgnore skips all symbolic links.
+	SymlinkPolicyIgnore SymlinkPolicy = "ignore"
+
+	// SymlinkPolicyError fails the read if the package contains a symbolic link.
+	SymlinkPolicyError SymlinkPolicy = "error"
+)
+
 // LocalPackageReader reads ResourceNodes from a local package.
 type LocalPackageReader struct {
 	Kind string `yaml:"kind,omitempty"`
@@ -177,6 +204,24 @@ type LocalPackageReader struct {
 	// Defaults to ["*.yaml", "*.yml"] if empty.  To match all files specify ["*"].
 	MatchFilesGlob []string `yaml:"matchFilesGlob,omitempty"`
 
+	// IncludeGlobs configures Read to only read Resources from files matching any of
+	// the provided patterns, in addition to MatchFilesGlob.  Patterns containing a slash
+	// are matched against the slash separated path of the file relative to the package,
+	// e.g. "base/*.yaml", other patterns are matched against the name of the file.
+	IncludeGlobs []string `yaml:"includeGlobs,omitempty"`
+
+	// ExcludeGlobs configures Read to skip the files and directories matching any of
+	// the provided patterns.  Patterns are matched as for IncludeGlobs.
+	ExcludeGlobs []string `yaml:"excludeGlobs,omitempty"`
+
+	// MaxDepth if set configures Read to only read Resources from files at most MaxDepth
+	// directories deep, where 1 is the files of the package directory.
+	MaxDepth int `yaml:"maxDepth,omitempty"`
+
+	// SymlinkPolicy configures how Read handles symbolic links.
+	// Defaults to SymlinkPolicyDefault.
+	SymlinkPolicy SymlinkPolicy `yaml:"symlinkPolicy,omitempty"`
+
 	// IncludeSubpackages will configure Read to read Resources from subpackages.
 	// Subpackages are identified by presence of PackageFileName.
 	IncludeSubpackages bool `yaml:"includeSubpackages,omitempty"`
@@ -208,6 +253,15 @@ type LocalPackageReader struct {
 	// sequence nodes into map node with key yaml.BareSeqNodeWrappingKey
 	// note that this wrapping is different and not related to ResourceList wrapping
 	WrapBareSeqNode bool
+
+	// Progress if set is called with a ProgressFileDiscovered event for each file
+	// to read, and then with a ProgressFileRead event after reading each file.
+	Progress ProgressFunc
+
+	// ScalarCompatibility configures how plain scalars which YAML 1.1 and YAML 1.2
+	// parsers interpret differently, e.g. `mode: 0644`, are read.
+	// Defaults to yaml.ScalarCompatibilityDefault.
+	ScalarCompatibility yaml.ScalarCompatibility
 }
 
 var _ Reader = LocalPackageReader{}
@@ -228,7 +282,13 @@ func (r LocalPackageReader) Read() ([]*yaml.RNode, error) {
 		r.MatchFilesGlob = DefaultMatch
 	}
 
-	var operand ResourceNodeSlice
+	// discover the files before reading them, so that the progress of
+	// reading them can be reported
+	type packageFile struct {
+		path, relPath string
+		info          os.FileInfo
+	}
+	var files []packageFile
 	var pathRelativeTo string
 	var err error
 	ignoreFilesMatcher := &ignoreFilesMatcher{
@@ -239,7 +299,7 @@ func (r LocalPackageReader) Read() ([]*yaml.RNode, error) {
 		return nil, errors.Wrap(err)
 	}
 	r.PackagePath = filepath.Join(string(dir), file)
-	err = r.FileSystem.Walk(r.PackagePath, func(
+	err = r.walk(r.PackagePath, map[string]bool{}, func(
 		path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return errors.Wrap(err)
@@ -259,17 +319,17 @@ func (r LocalPackageReader) Read() ([]*yaml.RNode, error) {
 			pathRelativeTo = filepath.Dir(r.PackagePath)
 		}
 
-		// check if we should skip the directory or file
-		if info.IsDir() {
-			return r.shouldSkipDir(path, ignoreFilesMatcher)
-		}
-
 		// get the relative path to file within the package so we can write the files back out
 		// to another location.
 		relPath, err := filepath.Rel(pathRelativeTo, path)
 		if err != nil {
 			return errors.WrapPrefixf(err, pathRelativeTo)
 		}
+
+		// check if we should skip the directory or file
+		if info.IsDir() {
+			return r.shouldSkipDir(path, relPath, ignoreFilesMatcher)
+		}
 		if match, err := r.shouldSkipFile(path, relPath, ignoreFilesMatcher); err != nil {
 			return err
 		} else if match {
@@ -277,15 +337,27 @@ func (r LocalPackageReader) Read() ([]*yaml.RNode, error) {
 			return nil
 		}
 
-		r.initReaderAnnotations(relPath, info)
-		nodes, err := r.readFile(path, info)
+		files = append(files, packageFile{path: path, relPath: relPath, info: info})
+		r.Progress.report(ProgressEvent{
+			Type: ProgressFileDiscovered, Path: filepath.ToSlash(relPath), Bytes: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var operand ResourceNodeSlice
+	for _, f := range files {
+		r.initReaderAnnotations(f.relPath, f.info)
+		nodes, err := r.readFile(f.path, f.info)
 		if err != nil {
-			return errors.WrapPrefixf(err, path)
+			return nil, errors.WrapPrefixf(err, f.path)
 		}
 		operand = append(operand, nodes...)
-		return nil
-	})
-	return operand, err
+		r.Progress.report(ProgressEvent{Type: ProgressFileRead, Path: filepath.ToSlash(f.relPath),
+			Resources: len(nodes), Bytes: f.info.Size()})
+	}
+	return operand, nil
 }
 
 // readFile reads the ResourceNodes from a file
@@ -303,6 +375,7 @@ func (r *LocalPackageReader) readFile(path string, _ os.FileInfo) ([]*yaml.RNode
 		SetAnnotations:        r.SetAnnotations,
 		PreserveSeqIndent:     r.PreserveSeqIndent,
 		WrapBareSeqNode:       r.WrapBareSeqNode,
+		ScalarCompatibility:   r.ScalarCompatibility,
 	}
 	return rr.Read()
 }
@@ -318,6 +391,18 @@ func (r *LocalPackageReader) shouldSkipFile(path, relPath string, matcher *ignor
 		return true, nil
 	}
 
+	if r.MaxDepth > 0 && pathDepth(relPath) > r.MaxDepth {
+		return true, nil
+	}
+	if match, err := matchesAnyGlob(r.ExcludeGlobs, relPath); err != nil || match {
+		return true, err
+	}
+	if len(r.IncludeGlobs) > 0 {
+		if match, err := matchesAnyGlob(r.IncludeGlobs, relPath); err != nil || !match {
+			return true, err
+		}
+	}
+
 	// check if the files are in scope
 	for _, g := range r.MatchFilesGlob {
 		if match, err := filepath.Match(g, filepath.Base(path)); err != nil {
@@ -341,11 +426,21 @@ func (r *LocalPackageReader) initReaderAnnotations(path string, _ os.FileInfo) {
 }
 
 // shouldSkipDir returns a filepath.SkipDir if the directory should be skipped
-func (r *LocalPackageReader) shouldSkipDir(path string, matcher *ignoreFilesMatcher) error {
+func (r *LocalPackageReader) shouldSkipDir(path, relPath string, matcher *ignoreFilesMatcher) error {
 	if matcher.matchDir(path) {
 		return filepath.SkipDir
 	}
 
+	// files in the directory would be deeper than MaxDepth
+	if r.MaxDepth > 0 && pathDepth(relPath) >= r.MaxDepth {
+		return filepath.SkipDir
+	}
+	if match, err := matchesAnyGlob(r.ExcludeGlobs, relPath); err != nil {
+		return err
+	} else if match {
+		return filepath.SkipDir
+	}
+
 	if r.PackageFileName == "" {
 		return nil
 	}
@@ -358,3 +453,82 @@ func (r *LocalPackageReader) shouldSkipDir(path string, matcher *ignoreFilesMatc
 	}
 	return matcher.readIgnoreFile(path)
 }
+
+// walk walks the file tree rooted at root, calling walkFn for each file or directory
+// and applying the SymlinkPolicy to symbolic links.
+// followed are the directories currently being walked through symbolic links.
+func (r *LocalPackageReader) walk(root string, followed map[string]bool, walkFn filepath.WalkFunc) error {
+	return r.FileSystem.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return walkFn(path, info, err)
+		}
+		switch r.SymlinkPolicy {
+		case SymlinkPolicyDefault:
+			return walkFn(path, info, err)
+		case SymlinkPolicyIgnore:
+			return nil
+		case SymlinkPolicyError:
+			return errors.Errorf("symbolic link %s is not allowed", path)
+		case SymlinkPolicyFollow:
+			return r.followSymlink(path, followed, walkFn)
+		default:
+			return errors.Errorf("unknown symlink policy %q", r.SymlinkPolicy)
+		}
+	})
+}
+
+// followSymlink walks the target of the symbolic link at path as if it were at path.
+func (r *LocalPackageReader) followSymlink(path string, followed map[string]bool, walkFn filepath.WalkFunc) error {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return errors.WrapPrefixf(err, "following symbolic link %s", path)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return errors.WrapPrefixf(err, "following symbolic link %s", path)
+	}
+	if !info.IsDir() {
+		return walkFn(path, info, nil)
+	}
+	if followed[target] {
+		return errors.Errorf("symbolic link %s creates a cycle", path)
+	}
+	followed[target] = true
+	defer delete(followed, target)
+	return r.walk(target, followed, func(p string, info os.FileInfo, err error) error {
+		rel, relErr := filepath.Rel(target, p)
+		if relErr != nil {
+			return errors.Wrap(relErr)
+		}
+		return walkFn(filepath.Join(path, rel), info, err)
+	})
+}
+
+// matchesAnyGlob returns true if relPath matches any of the patterns.  Patterns
+// containing a slash are matched against relPath, other patterns are matched
+// against the last element of relPath.
+func matchesAnyGlob(patterns []string, relPath string) (bool, error) {
+	relPath = filepath.ToSlash(relPath)
+	for _, p := range patterns {
+		name := relPath
+		if !strings.Contains(p, "/") {
+			name = pathpkg.Base(relPath)
+		}
+		match, err := pathpkg.Match(p, name)
+		if err != nil {
+			return false, errors.Wrap(err)
+		}
+		if match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// pathDepth returns the number of elements of relPath.
+func pathDepth(relPath string) int {
+	if relPath == "." || relPath == "" {
+		return 0
+	}
+	return len(strings.Split(filepath.ToSlash(relPath), "/"))
+}
@@ -4,6 +4,7 @@
 package kio_test
 
 import (
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 	. "sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 )
 
 var readFileA = []byte(`---
@@ -674,3 +676,122 @@ metadata:
 		}
 	})
 }
+
+func TestLocalPackageReader_Read_globsAndDepth(t *testing.T) {
+	testCases := []struct {
+		name     string
+		reader   LocalPackageReader
+		expected []string
+	}{
+		{
+			name:     "include",
+			reader:   LocalPackageReader{IncludeGlobs: []string{"a/b/a_*.yaml", "c_test.yaml"}},
+			expected: []string{"a/b/a_test.yaml", "a/b/a_test.yaml", "c_test.yaml"},
+		},
+		{
+			name:     "exclude file",
+			reader:   LocalPackageReader{ExcludeGlobs: []string{"a_test.yaml"}},
+			expected: []string{"a/b/b_test.yaml", "c_test.yaml"},
+		},
+		{
+			name:     "exclude directory",
+			reader:   LocalPackageReader{ExcludeGlobs: []string{"a/b"}},
+			expected: []string{"c_test.yaml"},
+		},
+		{
+			name:     "max depth",
+			reader:   LocalPackageReader{MaxDepth: 1},
+			expected: []string{"c_test.yaml"},
+		},
+		{
+			name:     "max depth includes files at depth",
+			reader:   LocalPackageReader{MaxDepth: 3},
+			expected: []string{"a/b/a_test.yaml", "a/b/a_test.yaml", "a/b/b_test.yaml", "c_test.yaml"},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			testOnDiskAndOnMem(t, []mockFile{
+				{path: "a/b"},
+				{path: "a/b/a_test.yaml", content: readFileA},
+				{path: "a/b/b_test.yaml", content: readFileB},
+				{path: "c_test.yaml", content: readFileC},
+			}, func(t *testing.T, path string, mockFS filesys.FileSystem) {
+				t.Helper()
+				rfr := tc.reader
+				rfr.PackagePath = path
+				rfr.FileSystem = filesys.FileSystemOrOnDisk{FileSystem: mockFS}
+				nodes, err := rfr.Read()
+				require.NoError(t, err)
+				var paths []string
+				for _, n := range nodes {
+					p, _, err := kioutil.GetFileAnnotations(n)
+					require.NoError(t, err)
+					paths = append(paths, filepath.ToSlash(p))
+				}
+				require.Equal(t, tc.expected, paths)
+			})
+		})
+	}
+}
+
+func TestLocalPackageReader_Read_symlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require privileges on windows")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg"), 0700))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "other"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "a.yaml"), readFileA, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other", "b.yaml"), readFileB, 0600))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "other"), filepath.Join(dir, "pkg", "linked")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "other", "b.yaml"), filepath.Join(dir, "pkg", "c.yaml")))
+
+	testCases := []struct {
+		policy   SymlinkPolicy
+		expected []string
+		err      string
+	}{
+		{policy: SymlinkPolicyDefault, expected: []string{"a.yaml", "a.yaml", "c.yaml"}},
+		{policy: SymlinkPolicyIgnore, expected: []string{"a.yaml", "a.yaml"}},
+		{policy: SymlinkPolicyFollow, expected: []string{"a.yaml", "a.yaml", "c.yaml", "linked/b.yaml"}},
+		{policy: SymlinkPolicyError, err: "symbolic link " + filepath.Join(dir, "pkg", "c.yaml") + " is not allowed"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(string(tc.policy), func(t *testing.T) {
+			nodes, err := LocalPackageReader{
+				PackagePath:   filepath.Join(dir, "pkg"),
+				SymlinkPolicy: tc.policy,
+			}.Read()
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			var paths []string
+			for _, n := range nodes {
+				p, _, err := kioutil.GetFileAnnotations(n)
+				require.NoError(t, err)
+				paths = append(paths, filepath.ToSlash(p))
+			}
+			require.Equal(t, tc.expected, paths)
+		})
+	}
+}
+
+func TestLocalPackageReader_Read_symlinkCycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require privileges on windows")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg", "a"), 0700))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "pkg"), filepath.Join(dir, "pkg", "a", "loop")))
+	_, err := LocalPackageReader{
+		PackagePath:   filepath.Join(dir, "pkg"),
+		SymlinkPolicy: SymlinkPolicyFollow,
+	}.Read()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "creates a cycle")
+}
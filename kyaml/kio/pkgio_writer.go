@@ -31,6 +31,10 @@ type LocalPackageWriter struct {
 
 	// FileSystem can be used to mock the disk file system.
 	FileSystem filesys.FileSystemOrOnDisk
+
+	// Progress if set is called with a ProgressFileWritten event after writing
+	// each file.
+	Progress ProgressFunc
 }
 
 var _ Writer = LocalPackageWriter{}
@@ -104,6 +108,8 @@ func (r LocalPackageWriter) Write(nodes []*yaml.RNode) error {
 		if err := r.FileSystem.WriteFile(outputPath, buf.Bytes()); err != nil {
 			return errors.Wrap(err)
 		}
+		r.Progress.report(ProgressEvent{Type: ProgressFileWritten, Path: filepath.ToSlash(path),
+			Resources: len(outputFiles[path]), Bytes: int64(buf.Len())})
 	}
 
 	return nil
@@ -0,0 +1,70 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio
+
+import "io"
+
+// ProgressEventType is the type of a ProgressEvent.
+type ProgressEventType string
+
+const (
+	// ProgressFileDiscovered is reported by LocalPackageReader for each file it
+	// will read, before reading any file.
+	ProgressFileDiscovered ProgressEventType = "FileDiscovered"
+
+	// ProgressFileRead is reported by LocalPackageReader after reading a file.
+	ProgressFileRead ProgressEventType = "FileRead"
+
+	// ProgressResourceRead is reported by ByteReader for each Resource it reads.
+	ProgressResourceRead ProgressEventType = "ResourceRead"
+
+	// ProgressResourceWritten is reported by ByteWriter after writing Resources.
+	ProgressResourceWritten ProgressEventType = "ResourceWritten"
+
+	// ProgressFileWritten is reported by LocalPackageWriter after writing a file.
+	ProgressFileWritten ProgressEventType = "FileWritten"
+)
+
+// ProgressEvent reports the progress of reading or writing Resources.
+type ProgressEvent struct {
+	// Type is the type of the event.
+	Type ProgressEventType
+
+	// Path is the slash separated path of the file relative to the package, for
+	// the events of LocalPackageReader and LocalPackageWriter.
+	Path string
+
+	// Resources is the number of Resources read from or written to the file for
+	// file events, or the number of Resources read or written so far for the
+	// events of ByteReader and ByteWriter.
+	Resources int
+
+	// Bytes is the size of the file for file events, the size of the input for
+	// ByteReader events, or the number of bytes written so far for ByteWriter events.
+	Bytes int64
+}
+
+// ProgressFunc is called with the progress events of readers and writers, so that
+// programs reading or writing large packages can report their progress.
+// It is called on the goroutine reading or writing the Resources.
+type ProgressFunc func(ProgressEvent)
+
+// report calls f with the event if f is set.
+func (f ProgressFunc) report(e ProgressEvent) {
+	if f != nil {
+		f(e)
+	}
+}
+
+// countingWriter is a writer counting the bytes written to it.
+type countingWriter struct {
+	io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.n += int64(n)
+	return n, err
+}
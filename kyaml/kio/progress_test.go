@@ -0,0 +1,75 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package kio_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	. "sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+func TestLocalPackageReadWriter_Progress(t *testing.T) {
+	fs := filesys.MakeFsInMemory()
+	require.NoError(t, fs.MkdirAll("/pkg/b"))
+	require.NoError(t, fs.WriteFile("/pkg/a.yaml", readFileA))
+	require.NoError(t, fs.WriteFile("/pkg/b/b.yaml", readFileB))
+
+	var events []ProgressEvent
+	rw := &LocalPackageReadWriter{
+		PackagePath: "/pkg",
+		FileSystem:  filesys.FileSystemOrOnDisk{FileSystem: fs},
+		Progress:    func(e ProgressEvent) { events = append(events, e) },
+	}
+	nodes, err := rw.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []ProgressEvent{
+		{Type: ProgressFileDiscovered, Path: "a.yaml", Bytes: int64(len(readFileA))},
+		{Type: ProgressFileDiscovered, Path: "b/b.yaml", Bytes: int64(len(readFileB))},
+		{Type: ProgressFileRead, Path: "a.yaml", Resources: 2, Bytes: int64(len(readFileA))},
+		{Type: ProgressFileRead, Path: "b/b.yaml", Resources: 1, Bytes: int64(len(readFileB))},
+	}, events)
+
+	events = nil
+	require.NoError(t, rw.Write(nodes))
+	sort.Slice(events, func(i, j int) bool { return events[i].Path < events[j].Path })
+	require.Len(t, events, 2)
+	for i, path := range []string{"a.yaml", "b/b.yaml"} {
+		assert.Equal(t, ProgressFileWritten, events[i].Type)
+		assert.Equal(t, path, events[i].Path)
+		b, err := fs.ReadFile("/pkg/" + path)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(b)), events[i].Bytes)
+	}
+	assert.Equal(t, 2, events[0].Resources)
+	assert.Equal(t, 1, events[1].Resources)
+}
+
+func TestByteReadWriter_Progress(t *testing.T) {
+	input := "a: b\n---\nc: d\n"
+	var out bytes.Buffer
+	var events []ProgressEvent
+	rw := &ByteReadWriter{
+		Reader:   bytes.NewBufferString(input),
+		Writer:   &out,
+		Progress: func(e ProgressEvent) { events = append(events, e) },
+	}
+	nodes, err := rw.Read()
+	require.NoError(t, err)
+	assert.Equal(t, []ProgressEvent{
+		{Type: ProgressResourceRead, Resources: 1, Bytes: int64(len(input))},
+		{Type: ProgressResourceRead, Resources: 2, Bytes: int64(len(input))},
+	}, events)
+
+	events = nil
+	require.NoError(t, rw.Write(nodes))
+	assert.Equal(t, []ProgressEvent{
+		{Type: ProgressResourceWritten, Resources: 1, Bytes: int64(len("a: b\n"))},
+		{Type: ProgressResourceWritten, Resources: 2, Bytes: int64(out.Len())},
+	}, events)
+}
@@ -30,6 +30,9 @@ const (
 
 var GraphStructures = []string{string(TreeStructureGraph), string(TreeStructurePackage)}
 
+// originAnnotation records where kustomize build got a Resource from.
+const originAnnotation = "config.kubernetes.io/origin"
+
 // TreeWriter prints the package structured as a tree.
 // TODO(pwittrock): test this package better.  it is lower-risk since it is only
 //   used for printing rather than updating or editing.
@@ -39,6 +42,10 @@ type TreeWriter struct {
 	Fields          []TreeWriterField
 	Structure       TreeStructure
 	OpenAPIFileName string
+
+	// Origin prints the file, base or generator that produced each Resource,
+	// as recorded in the config.kubernetes.io/origin annotation by kustomize build.
+	Origin bool
 }
 
 // TreeWriterField configures a Resource field to be included in the tree
@@ -268,6 +275,42 @@ func ownerToString(node *yaml.RNode) (string, error) {
 	return fmt.Sprintf("%s %s/%s", kind, namespace, name), nil
 }
 
+// originToString describes the origin annotation value, e.g.
+// "from base/deployment.yaml" or "generated by ConfigMapGenerator app in kustomization.yaml".
+func originToString(annotation string) string {
+	if annotation == "" {
+		return ""
+	}
+	origin, err := yaml.Parse(annotation)
+	if err != nil {
+		return ""
+	}
+	get := func(path ...string) string {
+		value, err := origin.Pipe(yaml.Lookup(path...))
+		if err != nil || value == nil {
+			return ""
+		}
+		return value.YNode().Value
+	}
+	location := get("path")
+	if configuredIn := get("configuredIn"); configuredIn != "" {
+		location = configuredIn
+	}
+	if repo := get("repo"); repo != "" {
+		location = repo + "//" + location
+		if ref := get("ref"); ref != "" {
+			location += "?ref=" + ref
+		}
+	}
+	if kind := get("configuredBy", "kind"); kind != "" {
+		return fmt.Sprintf("generated by %s %s in %s", kind, get("configuredBy", "name"), location)
+	}
+	if location == "" {
+		return ""
+	}
+	return "from " + location
+}
+
 // index indexes the Resources by their package
 func (p TreeWriter) index(nodes []*yaml.RNode) map[string][]*yaml.RNode {
 	// index the ResourceNodes by package
@@ -347,6 +390,11 @@ func (p TreeWriter) doResource(leaf *yaml.RNode, metaString string, branch treep
 	if len(meta.Namespace) > 0 {
 		value = fmt.Sprintf("%s %s/%s", meta.Kind, meta.Namespace, meta.Name)
 	}
+	if p.Origin {
+		if origin := originToString(meta.Annotations[originAnnotation]); origin != "" {
+			value = fmt.Sprintf("%s  (%s)", value, origin)
+		}
+	}
 
 	fields, err := p.getFields(leaf)
 	if err != nil {
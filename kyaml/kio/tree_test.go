@@ -577,3 +577,56 @@ metadata:
 	assert.Error(t, err)
 	assert.Equal(t, "owner 'Application myapp-staging/nginx' not found in input, but found as an owner of input objects", err.Error())
 }
+
+func TestPrinter_Write_Origin(t *testing.T) {
+	in := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/origin: |
+      path: base/deployment.yaml
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+  annotations:
+    config.kubernetes.io/origin: |
+      configuredIn: overlay/kustomization.yaml
+      configuredBy:
+        apiVersion: builtin
+        kind: ConfigMapGenerator
+        name: app-config
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/origin: |
+      path: examples/service.yaml
+      repo: https://github.com/example/repo
+      ref: v1
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: app
+`
+	out := &bytes.Buffer{}
+	err := Pipeline{
+		Inputs:  []Reader{&ByteReader{Reader: bytes.NewBufferString(in)}},
+		Outputs: []Writer{TreeWriter{Writer: out, Structure: TreeStructureGraph, Origin: true}},
+	}.Execute()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, `.
+├── [Resource]  Deployment app  (from base/deployment.yaml)
+├── [Resource]  Secret app
+├── [Resource]  Service app  (from https://github.com/example/repo//examples/service.yaml?ref=v1)
+└── [Resource]  ConfigMap app-config  (generated by ConfigMapGenerator app-config in overlay/kustomization.yaml)
+`, out.String())
+}
@@ -0,0 +1,267 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// customResourceDefinitionsPath is the apiserver path listing the CustomResourceDefinitions
+const customResourceDefinitionsPath = "/apis/apiextensions.k8s.io/v1/customresourcedefinitions"
+
+// RESTConfig contains the information needed to read from a kubernetes apiserver.
+// It mirrors the subset of the client-go rest.Config used by AddSchemaFromCluster,
+// so that kyaml doesn't need to depend on client-go.
+type RESTConfig struct {
+	// Host is the URL of the apiserver, e.g. https://127.0.0.1:6443
+	Host string
+
+	// BearerToken authenticates requests with a bearer token
+	BearerToken string
+
+	// Username and Password authenticate requests with basic auth
+	Username string
+	Password string
+
+	// Insecure skips verifying the certificate of the apiserver
+	Insecure bool
+
+	// CAData contains the PEM encoded certificate authorities of the apiserver
+	CAData []byte
+
+	// CertData and KeyData contain the PEM encoded client certificate and key
+	CertData []byte
+	KeyData  []byte
+
+	// Transport if set is used to make the requests instead of a transport
+	// built from the TLS options above.
+	Transport http.RoundTripper
+}
+
+// AddSchemaFromCluster reads the structural schemas of the CustomResourceDefinitions
+// served by the cluster and adds them to the global schema, so that merges of
+// custom resources honor their list types and merge keys.
+func AddSchemaFromCluster(config *RESTConfig) error {
+	if config == nil || config.Host == "" {
+		return errors.Errorf("must specify the host of the cluster")
+	}
+	client, err := config.httpClient()
+	if err != nil {
+		return err
+	}
+
+	var crds []customResourceDefinition
+	continueToken := ""
+	for {
+		u := strings.TrimSuffix(config.Host, "/") + customResourceDefinitionsPath + "?limit=500"
+		if continueToken != "" {
+			u += "&continue=" + url.QueryEscape(continueToken)
+		}
+		list, err := config.get(client, u)
+		if err != nil {
+			return err
+		}
+		crds = append(crds, list.Items...)
+		continueToken = list.Metadata.Continue
+		if continueToken == "" {
+			break
+		}
+	}
+	return addCustomResourceDefinitions(crds)
+}
+
+// AddCRDSchemas parses b, which may be a single CustomResourceDefinition or a
+// list of CustomResourceDefinitions in json or yaml, e.g. the output of
+// `kubectl get --raw /apis/apiextensions.k8s.io/v1/customresourcedefinitions`,
+// and adds their structural schemas to the global schema.
+func AddCRDSchemas(b []byte) error {
+	j, err := k8syaml.YAMLToJSON(b)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	var list customResourceDefinitionList
+	if err := json.Unmarshal(j, &list); err != nil {
+		return errors.WrapPrefixf(err, "parsing CustomResourceDefinitions")
+	}
+	if len(list.Items) == 0 {
+		var crd customResourceDefinition
+		if err := json.Unmarshal(j, &crd); err != nil {
+			return errors.WrapPrefixf(err, "parsing CustomResourceDefinition")
+		}
+		list.Items = append(list.Items, crd)
+	}
+	return addCustomResourceDefinitions(list.Items)
+}
+
+// customResourceDefinitionList contains the fields of a
+// CustomResourceDefinitionList used to build the schemas.
+type customResourceDefinitionList struct {
+	Items    []customResourceDefinition `json:"items"`
+	Metadata struct {
+		Continue string `json:"continue"`
+	} `json:"metadata"`
+}
+
+// customResourceDefinition contains the fields of a CustomResourceDefinition
+// used to build the schemas.
+type customResourceDefinition struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		Scope    string `json:"scope"`
+		Versions []struct {
+			Name   string `json:"name"`
+			Schema struct {
+				OpenAPIV3Schema map[string]interface{} `json:"openAPIV3Schema"`
+			} `json:"schema"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+// addCustomResourceDefinitions adds the schema and the scope of each version of the crds
+// to the global schema.  If the schema of a version can't be converted, nothing is added
+// and the error names the crd and the version.
+func addCustomResourceDefinitions(crds []customResourceDefinition) error {
+	definitions := spec.Definitions{}
+	namespaceability := make(map[yaml.TypeMeta]bool)
+	for _, crd := range crds {
+		for _, v := range crd.Spec.Versions {
+			typeMeta := yaml.TypeMeta{
+				APIVersion: crd.Spec.Group + "/" + v.Name,
+				Kind:       crd.Spec.Names.Kind,
+			}
+			namespaceability[typeMeta] = crd.Spec.Scope != "Cluster"
+			if v.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			d, err := crdVersionSchema(crd, v.Name, v.Schema.OpenAPIV3Schema)
+			if err != nil {
+				return errors.WrapPrefixf(err, "converting the schema of CustomResourceDefinition %s version %s",
+					crd.Metadata.Name, v.Name)
+			}
+			definitions[crdDefinitionName(crd.Spec.Group, v.Name, crd.Spec.Names.Kind)] = d
+		}
+	}
+
+	schemaLock.Lock()
+	defer schemaLock.Unlock()
+	if globalSchema.namespaceabilityByResourceType == nil {
+		globalSchema.namespaceabilityByResourceType = make(map[yaml.TypeMeta]bool)
+	}
+	for typeMeta, namespaced := range namespaceability {
+		globalSchema.namespaceabilityByResourceType[typeMeta] = namespaced
+	}
+	AddDefinitions(definitions)
+	return nil
+}
+
+// crdVersionSchema converts the openAPIV3Schema of a version of crd to a
+// definition carrying its group, version and kind.
+func crdVersionSchema(crd customResourceDefinition, version string, openAPIV3Schema map[string]interface{}) (spec.Schema, error) {
+	var d spec.Schema
+	s, ok := convertOpenAPIV3Schema(openAPIV3Schema).(map[string]interface{})
+	if !ok {
+		return d, errors.Errorf("openAPIV3Schema is not an object")
+	}
+	s[kubernetesGVKExtensionKey] = []interface{}{map[string]interface{}{
+		groupKey:   crd.Spec.Group,
+		versionKey: version,
+		kindKey:    crd.Spec.Names.Kind,
+	}}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return d, errors.Wrap(err)
+	}
+	if err := d.UnmarshalJSON(b); err != nil {
+		return d, errors.Wrap(err)
+	}
+	return d, nil
+}
+
+// crdDefinitionName returns the name the apiserver uses for the definition of
+// a custom resource, e.g. com.example.v1.Widget for example.com/v1 Widget.
+func crdDefinitionName(group, version, kind string) string {
+	parts := strings.Split(group, ".")
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(append(parts, version, kind), ".")
+}
+
+// httpClient returns a client authenticating with the certificates of the config.
+func (c *RESTConfig) httpClient() (*http.Client, error) {
+	if c.Transport != nil {
+		return &http.Client{Transport: c.Transport}, nil
+	}
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: c.Insecure, //nolint:gosec
+		MinVersion:         tls.VersionTLS12,
+	}
+	if len(c.CAData) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(c.CAData) {
+			return nil, errors.Errorf("no certificates found in CAData")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(c.CertData) > 0 || len(c.KeyData) > 0 {
+		cert, err := tls.X509KeyPair(c.CertData, c.KeyData)
+		if err != nil {
+			return nil, errors.WrapPrefixf(err, "loading client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
+
+// get reads a CustomResourceDefinitionList from u.
+func (c *RESTConfig) get(client *http.Client, u string) (*customResourceDefinitionList, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	case c.Username != "" || c.Password != "":
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "fetching CustomResourceDefinitions")
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "fetching CustomResourceDefinitions")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching CustomResourceDefinitions: %s: %s",
+			resp.Status, strings.TrimSpace(string(b)))
+	}
+	list := &customResourceDefinitionList{}
+	if err := json.Unmarshal(b, list); err != nil {
+		return nil, errors.WrapPrefixf(err, "parsing CustomResourceDefinitions")
+	}
+	return list, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const crdList = `{
+  "kind": "CustomResourceDefinitionList",
+  "apiVersion": "apiextensions.k8s.io/v1",
+  "metadata": {},
+  "items": [{
+    "metadata": {"name": "widgets.example.com"},
+    "spec": {
+      "group": "example.com",
+      "names": {"kind": "Widget", "plural": "widgets"},
+      "scope": "Cluster",
+      "versions": [{
+        "name": "v1",
+        "schema": {"openAPIV3Schema": {
+          "type": "object",
+          "properties": {
+            "spec": {
+              "type": "object",
+              "properties": {
+                "ports": {
+                  "type": "array",
+                  "x-kubernetes-list-type": "map",
+                  "x-kubernetes-list-map-keys": ["port", "protocol"],
+                  "items": {
+                    "type": "object",
+                    "properties": {"port": {"type": "integer"}, "protocol": {"type": "string"}}
+                  }
+                }
+              }
+            }
+          }
+        }}
+      }]
+    }
+  }]
+}`
+
+func TestAddSchemaFromCluster(t *testing.T) {
+	ResetOpenAPI()
+	SuppressBuiltInSchemaUse()
+	defer ResetOpenAPI()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != customResourceDefinitionsPath || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(crdList))
+	}))
+	defer server.Close()
+
+	err := AddSchemaFromCluster(&RESTConfig{Host: server.URL})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403 Forbidden")
+
+	require.NoError(t, AddSchemaFromCluster(&RESTConfig{Host: server.URL, BearerToken: "token"}))
+
+	typeMeta := yaml.TypeMeta{APIVersion: "example.com/v1", Kind: "Widget"}
+	s := SchemaForResourceType(typeMeta)
+	require.NotNil(t, s)
+	ps, keys := s.Lookup("spec", "ports").PatchStrategyAndKeyList()
+	assert.Equal(t, "merge", ps)
+	assert.Equal(t, []string{"port", "protocol"}, keys)
+	assert.NotNil(t, Schema().Definitions["com.example.v1.Widget"])
+
+	assert.True(t, IsCertainlyClusterScoped(typeMeta))
+}
+
+func TestAddCRDSchemas(t *testing.T) {
+	ResetOpenAPI()
+	SuppressBuiltInSchemaUse()
+	defer ResetOpenAPI()
+
+	require.NoError(t, AddCRDSchemas([]byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Gadget
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              replicas:
+                type: integer
+`)))
+	typeMeta := yaml.TypeMeta{APIVersion: "example.com/v1alpha1", Kind: "Gadget"}
+	s := SchemaForResourceType(typeMeta)
+	require.NotNil(t, s)
+	assert.NotNil(t, s.Lookup("spec", "replicas"))
+	isNamespaced, found := IsNamespaceScoped(typeMeta)
+	assert.True(t, found)
+	assert.True(t, isNamespaced)
+}
+
+func TestAddCRDSchemasInvalid(t *testing.T) {
+	ResetOpenAPI()
+	SuppressBuiltInSchemaUse()
+	defer ResetOpenAPI()
+
+	err := AddCRDSchemas([]byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Gadget
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+  - name: v2
+    schema:
+      openAPIV3Schema:
+        type: 5
+`))
+	require.ErrorContains(t, err,
+		"converting the schema of CustomResourceDefinition gadgets.example.com version v2")
+	// none of the versions is added
+	assert.Nil(t, SchemaForResourceType(yaml.TypeMeta{APIVersion: "example.com/v1", Kind: "Gadget"}))
+}
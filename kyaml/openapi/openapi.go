@@ -42,6 +42,10 @@ var (
 
 	// customSchemaFile stores the custom OpenApi schema if it is provided
 	customSchema []byte //nolint:gochecknoglobals
+
+	// schemaOverlays stores the decoded schemas merged over the builtin or
+	// custom schema if they are provided
+	schemaOverlays []spec.Swagger //nolint:gochecknoglobals
 )
 
 // schemaParseStatus is used in cases when a schema should be parsed, but the
@@ -311,6 +315,7 @@ func ResetOpenAPI() {
 
 	globalSchema = openapiData{}
 	customSchema = nil
+	schemaOverlays = nil
 	kubernetesOpenAPIVersion = ""
 }
 
@@ -421,7 +426,7 @@ func isInitSchemaNeededForNamespaceScopeCheck() bool {
 	if globalSchema.schemaInit {
 		return false // globalSchema already is initialized.
 	}
-	if customSchema != nil {
+	if customSchema != nil || len(schemaOverlays) > 0 {
 		return true // initSchema is needed.
 	}
 	if kubernetesOpenAPIVersion == "" || kubernetesOpenAPIVersion == kubernetesOpenAPIDefaultVersion {
@@ -538,8 +543,8 @@ func (rs *ResourceSchema) Field(field string) *ResourceSchema {
 func (rs *ResourceSchema) PatchStrategyAndKeyList() (string, []string) {
 	ps, found := rs.Schema.Extensions[kubernetesPatchStrategyExtensionKey]
 	if !found {
-		// empty patch strategy
-		return "", []string{}
+		// no patch strategy -- the list type may still make the list associative
+		return rs.listTypeStrategyAndKeys()
 	}
 	mkList, found := rs.Schema.Extensions[kubernetesMergeKeyMapList]
 	if found {
@@ -562,8 +567,12 @@ func (rs *ResourceSchema) PatchStrategyAndKeyList() (string, []string) {
 func (rs *ResourceSchema) PatchStrategyAndKey() (string, string) {
 	ps, found := rs.Schema.Extensions[kubernetesPatchStrategyExtensionKey]
 	if !found {
-		// empty patch strategy
-		return "", ""
+		// no patch strategy -- the list type may still make the list associative
+		ps, keys := rs.listTypeStrategyAndKeys()
+		if len(keys) == 0 {
+			return ps, ""
+		}
+		return ps, keys[0]
 	}
 
 	mk, found := rs.Schema.Extensions[kubernetesMergeKeyExtensionKey]
@@ -574,6 +583,31 @@ func (rs *ResourceSchema) PatchStrategyAndKey() (string, string) {
 	return ps.(string), mk.(string)
 }
 
+// listTypeStrategyAndKeys returns the patch strategy and merge keys implied by the
+// list type extension of schemas which don't declare a patch strategy, such as the
+// schemas of CustomResourceDefinitions.  Lists of type map are merged by their
+// list map keys, which may be more than one, and lists of type set by their values.
+func (rs *ResourceSchema) listTypeStrategyAndKeys() (string, []string) {
+	switch rs.Schema.Extensions[kubernetesListTypeExtensionKey] {
+	case "map":
+		mkList, ok := rs.Schema.Extensions[kubernetesMergeKeyMapList].([]interface{})
+		if !ok || len(mkList) == 0 {
+			return "", []string{}
+		}
+		keys := make([]string, 0, len(mkList))
+		for _, v := range mkList {
+			if k, ok := v.(string); ok {
+				keys = append(keys, k)
+			}
+		}
+		return "merge", keys
+	case "set":
+		return "merge", []string{}
+	default:
+		return "", []string{}
+	}
+}
+
 const (
 	// kubernetesOpenAPIDefaultVersion is the latest version number of the statically compiled in
 	// OpenAPI schema for kubernetes built-in types
@@ -609,11 +643,30 @@ const (
 
 // SetSchema sets the kubernetes OpenAPI schema version to use
 func SetSchema(openAPIField map[string]string, schema []byte, reset bool) error {
+	return SetSchemaWithOverlays(openAPIField, schema, nil, reset)
+}
+
+// SetSchemaWithOverlays sets the schema like SetSchema, and merges the
+// overlays, e.g. the schemas of CRDs, over it.  The definitions of the
+// overlays replace those of the schema with the same name, and those of
+// the earlier overlays; the other definitions of the schema are kept.
+// The overlays are decoded right away, so an invalid overlay is returned
+// as an error naming its index.
+func SetSchemaWithOverlays(openAPIField map[string]string, schema []byte, overlays [][]byte, reset bool) error {
+	decodedOverlays := make([]spec.Swagger, 0, len(overlays))
+	for i, overlay := range overlays {
+		swagger, err := decode(overlay, JsonOrYaml)
+		if err != nil {
+			return errors.WrapPrefixf(err, "invalid schema overlay %d", i)
+		}
+		decodedOverlays = append(decodedOverlays, swagger)
+	}
+
 	schemaLock.Lock()
 	defer schemaLock.Unlock()
 
 	// this should only be set once
-	schemaIsSet := (kubernetesOpenAPIVersion != "") || customSchema != nil
+	schemaIsSet := (kubernetesOpenAPIVersion != "") || customSchema != nil || len(schemaOverlays) > 0
 	if schemaIsSet && !reset {
 		return nil
 	}
@@ -626,6 +679,7 @@ func SetSchema(openAPIField map[string]string, schema []byte, reset bool) error
 			return fmt.Errorf("builtin version and custom schema provided, cannot use both")
 		}
 		customSchema = schema
+		schemaOverlays = decodedOverlays
 		kubernetesOpenAPIVersion = "custom"
 		// if the schema is changed, initSchema should parse the new schema
 		globalSchema.schemaInit = false
@@ -633,15 +687,18 @@ func SetSchema(openAPIField map[string]string, schema []byte, reset bool) error
 	}
 
 	// use builtin version
+	if version != "" {
+		if _, ok := kubernetesapi.OpenAPIMustAsset[version]; !ok {
+			return fmt.Errorf("the specified OpenAPI version is not built in")
+		}
+	}
 	kubernetesOpenAPIVersion = version
-	if kubernetesOpenAPIVersion == "" {
+	if kubernetesOpenAPIVersion == "" && len(overlays) == 0 {
 		return nil
 	}
-	if _, ok := kubernetesapi.OpenAPIMustAsset[kubernetesOpenAPIVersion]; !ok {
-		return fmt.Errorf("the specified OpenAPI version is not built in")
-	}
 
 	customSchema = nil
+	schemaOverlays = decodedOverlays
 	// if the schema is changed, initSchema should parse the new schema
 	globalSchema.schemaInit = false
 	return nil
@@ -692,6 +749,11 @@ func initSchema() {
 		globalSchema.defaultBuiltInSchemaParseStatus = schemaParsed
 	}
 
+	// the overlays are merged over the schema
+	for i := range schemaOverlays {
+		indexSwagger(&schemaOverlays[i])
+	}
+
 	if err := parse(kustomizationapi.MustAsset(kustomizationAPIAssetName), JsonOrYaml); err != nil {
 		// this should never happen
 		panic(err)
@@ -718,18 +780,28 @@ func parseBuiltinSchema(version string) {
 
 // parse parses and indexes a single json or proto schema
 func parse(b []byte, format format) error {
+	swagger, err := decode(b, format)
+	if err != nil {
+		return err
+	}
+	indexSwagger(&swagger)
+	return nil
+}
+
+// decode decodes a single json or proto schema without indexing it
+func decode(b []byte, format format) (spec.Swagger, error) {
 	var swagger spec.Swagger
 	switch {
 	case format == Proto:
 		doc := &openapi_v2.Document{}
 		// We parse protobuf and get an openapi_v2.Document here.
 		if err := proto.Unmarshal(b, doc); err != nil {
-			return fmt.Errorf("openapi proto unmarshalling failed: %w", err)
+			return swagger, fmt.Errorf("openapi proto unmarshalling failed: %w", err)
 		}
 		// convert the openapi_v2.Document back to Swagger
 		_, err := swagger.FromGnostic(doc)
 		if err != nil {
-			return errors.Wrap(err)
+			return swagger, errors.Wrap(err)
 		}
 
 	case format == JsonOrYaml:
@@ -737,17 +809,35 @@ func parse(b []byte, format format) error {
 			var err error
 			b, err = k8syaml.YAMLToJSON(b)
 			if err != nil {
-				return errors.Wrap(err)
+				return swagger, errors.Wrap(err)
+			}
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(b, &doc); err == nil {
+			if err := addPropertyOrder(b, doc); err != nil {
+				return swagger, err
+			}
+			if isOpenAPIV3(doc) {
+				b, err = convertOpenAPIV3(doc)
+			} else {
+				b, err = json.Marshal(doc)
+			}
+			if err != nil {
+				return swagger, errors.Wrap(err)
 			}
 		}
 		if err := swagger.UnmarshalJSON(b); err != nil {
-			return errors.Wrap(err)
+			return swagger, errors.Wrap(err)
 		}
 	}
 
+	return swagger, nil
+}
+
+// indexSwagger indexes the definitions and namespaceability of a decoded schema
+func indexSwagger(swagger *spec.Swagger) {
 	AddDefinitions(swagger.Definitions)
 	findNamespaceability(swagger.Paths)
-	return nil
 }
 
 // findNamespaceability looks at the api paths for the resource to determine
@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
@@ -47,6 +48,47 @@ var additionalSchema = []byte(`
 }
 `)
 
+func TestSetSchemaWithOverlays(t *testing.T) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+
+	overlay := []byte(`
+definitions:
+  io.k8s.config.setters.replicas:
+    type: integer
+  io.example.v1.Custom:
+    type: object
+    x-kubernetes-group-version-kind:
+    - group: example.io
+      kind: Custom
+      version: v1
+`)
+	require.NoError(t, SetSchemaWithOverlays(map[string]string{}, nil, [][]byte{additionalSchema, overlay}, true))
+
+	// the builtin schema is kept
+	assert.NotNil(t, SchemaForResourceType(yaml.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}))
+	assert.NotNil(t, SchemaForResourceType(yaml.TypeMeta{APIVersion: "example.io/v1", Kind: "Custom"}))
+	// the definitions of the later overlays replace those of the earlier ones
+	s, err := GetSchema(`{"$ref": "#/definitions/io.k8s.config.setters.replicas"}`, Schema())
+	require.NoError(t, err)
+	assert.Empty(t, s.Schema.Extensions)
+
+	// a schema set without reset doesn't replace the overlays
+	require.NoError(t, SetSchema(map[string]string{"version": "v1.21.2"}, nil, false))
+	assert.NotNil(t, SchemaForResourceType(yaml.TypeMeta{APIVersion: "example.io/v1", Kind: "Custom"}))
+}
+
+func TestSetSchemaWithOverlaysInvalid(t *testing.T) {
+	ResetOpenAPI()
+	defer ResetOpenAPI()
+
+	err := SetSchemaWithOverlays(map[string]string{}, nil, [][]byte{additionalSchema, []byte(`definitions: [1, 2]`)}, true)
+	require.ErrorContains(t, err, "invalid schema overlay 1")
+	// the invalid overlay is not set, and the builtin schema is used
+	assert.Equal(t, kubernetesOpenAPIDefaultVersion, GetSchemaVersion())
+	assert.NotNil(t, SchemaForResourceType(yaml.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}))
+}
+
 func TestSchemaForResourceType(t *testing.T) {
 	// reset package vars
 	globalSchema = openapiData{}
@@ -393,3 +435,27 @@ func TestCanSetAndResetSchemaConcurrently(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+func TestPatchStrategyAndKeyList_listType(t *testing.T) {
+	s := &ResourceSchema{Schema: &spec.Schema{}}
+	s.Schema.Extensions = spec.Extensions{
+		kubernetesListTypeExtensionKey: "map",
+		kubernetesMergeKeyMapList:      []interface{}{"port", "protocol"},
+	}
+	ps, keys := s.PatchStrategyAndKeyList()
+	assert.Equal(t, "merge", ps)
+	assert.Equal(t, []string{"port", "protocol"}, keys)
+	ps, key := s.PatchStrategyAndKey()
+	assert.Equal(t, "merge", ps)
+	assert.Equal(t, "port", key)
+
+	s.Schema.Extensions = spec.Extensions{kubernetesListTypeExtensionKey: "set"}
+	ps, keys = s.PatchStrategyAndKeyList()
+	assert.Equal(t, "merge", ps)
+	assert.Empty(t, keys)
+
+	s.Schema.Extensions = spec.Extensions{kubernetesListTypeExtensionKey: "atomic"}
+	ps, keys = s.PatchStrategyAndKeyList()
+	assert.Equal(t, "", ps)
+	assert.Empty(t, keys)
+}
@@ -0,0 +1,146 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+const (
+	// openAPIV3SchemaRefPrefix is the prefix of references to schemas in an OpenAPI v3 document
+	openAPIV3SchemaRefPrefix = "#/components/schemas/"
+
+	// swaggerSchemaRefPrefix is the prefix of references to schemas in a swagger 2.0 document
+	swaggerSchemaRefPrefix = "#/definitions/"
+
+	// kubernetesListTypeExtensionKey is the key to lookup the kubernetes list type extension
+	// -- the extension is one of "atomic", "set" or "map"
+	kubernetesListTypeExtensionKey = "x-kubernetes-list-type"
+)
+
+// isOpenAPIV3 returns true if the json document is an OpenAPI v3 document,
+// e.g. as served by the /openapi/v3 endpoints of the apiserver.
+func isOpenAPIV3(doc map[string]interface{}) bool {
+	v, ok := doc["openapi"].(string)
+	return ok && strings.HasPrefix(v, "3.")
+}
+
+// convertOpenAPIV3 converts an OpenAPI v3 json document to a swagger 2.0 json
+// document containing the parts of the document used by kyaml:
+//
+//   - components.schemas become definitions, and references to them are rewritten
+//   - the x-kubernetes-list-type and x-kubernetes-list-map-keys extensions are
+//     translated to the patch strategy and merge key extensions
+//   - the group-version-kind extensions of the path GET operations are kept so
+//     that the namespace scope of each type can be determined
+func convertOpenAPIV3(doc map[string]interface{}) ([]byte, error) {
+	swagger := map[string]interface{}{"swagger": "2.0"}
+
+	definitions := map[string]interface{}{}
+	if components, ok := doc["components"].(map[string]interface{}); ok {
+		if schemas, ok := components["schemas"].(map[string]interface{}); ok {
+			for name, s := range schemas {
+				definitions[name] = convertOpenAPIV3Schema(s)
+			}
+		}
+	}
+	swagger["definitions"] = definitions
+
+	paths := map[string]interface{}{}
+	if p, ok := doc["paths"].(map[string]interface{}); ok {
+		for path, item := range p {
+			item, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			get, ok := item["get"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			gvk, found := get[kubernetesGVKExtensionKey]
+			if !found {
+				continue
+			}
+			paths[path] = map[string]interface{}{
+				"get": map[string]interface{}{kubernetesGVKExtensionKey: gvk},
+			}
+		}
+	}
+	swagger["paths"] = paths
+
+	b, err := json.Marshal(swagger)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return b, nil
+}
+
+// convertOpenAPIV3Schema recursively converts an OpenAPI v3 schema to a swagger 2.0 schema.
+func convertOpenAPIV3Schema(in interface{}) interface{} {
+	switch s := in.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(s))
+		for k, v := range s {
+			if ref, ok := v.(string); ok && k == "$ref" {
+				out[k] = strings.Replace(ref, openAPIV3SchemaRefPrefix, swaggerSchemaRefPrefix, 1)
+				continue
+			}
+			out[k] = convertOpenAPIV3Schema(v)
+		}
+		inlineSingleAllOf(out)
+		convertListType(out)
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(s))
+		for i := range s {
+			out[i] = convertOpenAPIV3Schema(s[i])
+		}
+		return out
+	default:
+		return in
+	}
+}
+
+// inlineSingleAllOf replaces an allOf containing a single reference with the
+// reference.  The v3 documents published by kubernetes wrap references this
+// way so that the referring field can have its own description and default.
+func inlineSingleAllOf(s map[string]interface{}) {
+	allOf, ok := s["allOf"].([]interface{})
+	if !ok || len(allOf) != 1 {
+		return
+	}
+	ref, ok := allOf[0].(map[string]interface{})
+	if !ok || len(ref) != 1 || ref["$ref"] == nil {
+		return
+	}
+	if _, found := s["$ref"]; found {
+		return
+	}
+	delete(s, "allOf")
+	s["$ref"] = ref["$ref"]
+}
+
+// convertListType sets the patch strategy and merge key extensions for lists
+// which only declare their list type, as is the case for CustomResourceDefinitions.
+func convertListType(s map[string]interface{}) {
+	if _, found := s[kubernetesPatchStrategyExtensionKey]; found {
+		return
+	}
+	switch s[kubernetesListTypeExtensionKey] {
+	case "set":
+		s[kubernetesPatchStrategyExtensionKey] = "merge"
+	case "map":
+		keys, ok := s[kubernetesMergeKeyMapList].([]interface{})
+		if !ok || len(keys) == 0 {
+			return
+		}
+		s[kubernetesPatchStrategyExtensionKey] = "merge"
+		if _, found := s[kubernetesMergeKeyExtensionKey]; !found {
+			s[kubernetesMergeKeyExtensionKey] = keys[0]
+		}
+	}
+}
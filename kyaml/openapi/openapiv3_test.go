@@ -0,0 +1,132 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var openAPIV3Schema = []byte(`
+openapi: 3.0.0
+info:
+  title: Kubernetes CRD Swagger
+  version: v0.1.0
+paths:
+  /apis/example.com/v1/namespaces/{namespace}/widgets/{name}:
+    get:
+      operationId: readWidget
+      parameters:
+      - name: name
+        in: path
+        required: true
+        schema:
+          type: string
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/com.example.v1.Widget'
+      x-kubernetes-action: get
+      x-kubernetes-group-version-kind:
+        group: example.com
+        kind: Widget
+        version: v1
+components:
+  schemas:
+    com.example.v1.Widget:
+      type: object
+      properties:
+        apiVersion:
+          type: string
+        kind:
+          type: string
+        spec:
+          description: the widget spec
+          allOf:
+          - $ref: '#/components/schemas/com.example.v1.WidgetSpec'
+      x-kubernetes-group-version-kind:
+      - group: example.com
+        kind: Widget
+        version: v1
+    com.example.v1.WidgetSpec:
+      type: object
+      properties:
+        ports:
+          type: array
+          items:
+            $ref: '#/components/schemas/com.example.v1.Port'
+          x-kubernetes-list-type: map
+          x-kubernetes-list-map-keys:
+          - port
+          - protocol
+        parts:
+          type: array
+          items:
+            type: object
+            properties:
+              name:
+                type: string
+          x-kubernetes-list-type: map
+          x-kubernetes-list-map-keys:
+          - name
+        tags:
+          type: array
+          items:
+            type: string
+          x-kubernetes-list-type: set
+        args:
+          type: array
+          items:
+            type: string
+          x-kubernetes-list-type: atomic
+    com.example.v1.Port:
+      type: object
+      properties:
+        port:
+          type: integer
+        protocol:
+          type: string
+`)
+
+func TestAddSchema_openAPIV3(t *testing.T) {
+	ResetOpenAPI()
+	SuppressBuiltInSchemaUse()
+	defer ResetOpenAPI()
+	require.NoError(t, AddSchema(openAPIV3Schema))
+
+	typeMeta := yaml.TypeMeta{APIVersion: "example.com/v1", Kind: "Widget"}
+	s := SchemaForResourceType(typeMeta)
+	require.NotNil(t, s)
+
+	spec := s.Field("spec")
+	require.NotNil(t, spec)
+	assert.NotNil(t, spec.Field("ports"))
+
+	ps, keys := spec.Field("ports").PatchStrategyAndKeyList()
+	assert.Equal(t, "merge", ps)
+	assert.Equal(t, []string{"port", "protocol"}, keys)
+	port := s.Lookup("spec", "ports", Elements, "port")
+	require.NotNil(t, port)
+	assert.Equal(t, "integer", port.Schema.Type[0])
+
+	ps, key := spec.Field("parts").PatchStrategyAndKey()
+	assert.Equal(t, "merge", ps)
+	assert.Equal(t, "name", key)
+
+	ps, keys = spec.Field("tags").PatchStrategyAndKeyList()
+	assert.Equal(t, "merge", ps)
+	assert.Empty(t, keys)
+
+	ps, _ = spec.Field("args").PatchStrategyAndKeyList()
+	assert.Empty(t, ps)
+
+	isNamespaceable, isFound := IsNamespaceScoped(typeMeta)
+	assert.True(t, isFound)
+	assert.True(t, isNamespaceable)
+}
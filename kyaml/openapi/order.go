@@ -0,0 +1,107 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+)
+
+// kustomizePropertyOrderExtensionKey is the key of the extension recording the
+// order in which the properties of a schema are declared in the schema document.
+// -- the extension is an array of strings
+const kustomizePropertyOrderExtensionKey = "x-kustomize-property-order"
+
+// PropertyNames returns the names of the properties of the schema in the order they
+// are declared in the schema document.  The names are sorted if the order is unknown,
+// which matches the order of the schemas published by kubernetes.
+func (rs *ResourceSchema) PropertyNames() []string {
+	if rs == nil || rs.Schema == nil {
+		return nil
+	}
+	var names []string
+	if order, ok := rs.Schema.Extensions[kustomizePropertyOrderExtensionKey].([]interface{}); ok {
+		seen := map[string]bool{}
+		for _, v := range order {
+			name, ok := v.(string)
+			if _, found := rs.Schema.Properties[name]; ok && found && !seen[name] {
+				names = append(names, name)
+				seen[name] = true
+			}
+		}
+		if len(names) == len(rs.Schema.Properties) {
+			return names
+		}
+	}
+	names = nil
+	for name := range rs.Schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// addPropertyOrder records the order in which the properties of each schema in the
+// json document b are declared as an extension of the schema in doc, the decoded b.
+func addPropertyOrder(b []byte, doc map[string]interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	_, err := recordPropertyOrder(d, doc, false)
+	return err
+}
+
+// recordPropertyOrder reads the next json value from d, which was decoded to value,
+// recording the property order of the schemas in value.  If value is an object the
+// keys of the object are returned in the order they were read.
+// isProperties is true if value is the properties of a schema, rather than a schema.
+func recordPropertyOrder(d *json.Decoder, value interface{}, isProperties bool) ([]interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	delim, ok := t.(json.Delim)
+	if !ok {
+		return nil, nil
+	}
+	var keys []interface{}
+	switch delim {
+	case '{':
+		m, _ := value.(map[string]interface{})
+		for d.More() {
+			t, err := d.Token()
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			key, _ := t.(string)
+			keys = append(keys, key)
+			child := m[key]
+			isChildProperties := key == "properties" && !isProperties
+			childKeys, err := recordPropertyOrder(d, child, isChildProperties)
+			if err != nil {
+				return nil, err
+			}
+			if _, isMap := child.(map[string]interface{}); isMap && isChildProperties {
+				m[kustomizePropertyOrderExtensionKey] = childKeys
+			}
+		}
+	case '[':
+		s, _ := value.([]interface{})
+		for i := 0; d.More(); i++ {
+			var child interface{}
+			if i < len(s) {
+				child = s[i]
+			}
+			if _, err := recordPropertyOrder(d, child, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// consume the closing delimiter
+	if _, err := d.Token(); err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return keys, nil
+}
@@ -4,7 +4,11 @@
 package resid
 
 import (
+	"fmt"
+	"path"
+	"regexp"
 	"strings"
+	"sync"
 
 	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -218,28 +222,107 @@ func (x Gvk) IsLessThan(o Gvk) bool {
 // but rejected by
 //       <Group: "apps",       Version: "",        Kind: "Deployment">
 //
+// The Group, Version and Kind of the selector may be glob patterns with the
+// GlobPrefix, to select several groups, versions or kinds, e.g.
+//       <Group: "glob:*.example.com", Version: "glob:v1*", Kind: "Widget">
+//
 func (x Gvk) IsSelected(selector *Gvk) bool {
 	if selector == nil {
 		return true
 	}
 	if len(selector.Group) > 0 {
-		if x.Group != selector.Group {
+		if !matchesPattern(selector.Group, x.Group) {
 			return false
 		}
 	}
 	if len(selector.Version) > 0 {
-		if x.Version != selector.Version {
+		if !matchesPattern(selector.Version, x.Version) {
 			return false
 		}
 	}
 	if len(selector.Kind) > 0 {
-		if x.Kind != selector.Kind {
+		if !matchesPattern(selector.Kind, x.Kind) {
 			return false
 		}
 	}
 	return true
 }
 
+// GlobPrefix marks the group, version or kind of a selector as a glob
+// pattern, e.g. "glob:*.example.com", rather than a literal value or, in
+// the targets of patches, a regex.
+const GlobPrefix = "glob:"
+
+// compiledGlobs caches the glob patterns compiled by matchesPattern.
+var compiledGlobs sync.Map //nolint:gochecknoglobals
+
+// matchesPattern returns true if the value equals the pattern, or matches
+// it if it's a glob pattern.  Malformed glob patterns match nothing.
+func matchesPattern(pattern, value string) bool {
+	if !strings.HasPrefix(pattern, GlobPrefix) {
+		return pattern == value
+	}
+	if r, ok := compiledGlobs.Load(pattern); ok {
+		return r != nil && r.(*regexp.Regexp).MatchString(value)
+	}
+	r, err := CompileGlob(pattern)
+	if err != nil {
+		compiledGlobs.Store(pattern, nil)
+		return false
+	}
+	compiledGlobs.Store(pattern, r)
+	return r.MatchString(value)
+}
+
+// CompileGlob compiles a glob pattern, prefixed with GlobPrefix, into a
+// regex matching the whole value.  The pattern after the prefix has the
+// syntax of path.Match, e.g. "*.example.com" or "v1*", and its wildcards
+// don't match a slash.
+func CompileGlob(pattern string) (*regexp.Regexp, error) {
+	if !strings.HasPrefix(pattern, GlobPrefix) {
+		return nil, fmt.Errorf("glob pattern %q must start with %q", pattern, GlobPrefix)
+	}
+	glob := strings.TrimPrefix(pattern, GlobPrefix)
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+	return regexp.Compile("^" + globToRegex(glob) + "$")
+}
+
+// globToRegex translates a glob pattern, valid for path.Match, to a regex.
+func globToRegex(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '\\':
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			// the class ends at the first unescaped ]
+			end := i + 1
+			for pattern[end] != ']' {
+				if pattern[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			class := pattern[i+1 : end]
+			if strings.HasPrefix(class, "^") {
+				class = "^/" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
 // AsTypeMeta returns a yaml.TypeMeta from x's information.
 func (x Gvk) AsTypeMeta() yaml.TypeMeta {
 	return yaml.TypeMeta{
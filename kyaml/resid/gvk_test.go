@@ -271,6 +271,60 @@ func TestSelectByGVK(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			description: "group wildcard matches",
+			in:          NewGvk("widgets.example.com", "v1", "Widget"),
+			filter:      &Gvk{Group: "glob:*.example.com", Kind: "Widget"},
+			expected:    true,
+		},
+		{
+			description: "group wildcard doesn't match",
+			in:          NewGvk("widgets.example.org", "v1", "Widget"),
+			filter:      &Gvk{Group: "glob:*.example.com", Kind: "Widget"},
+			expected:    false,
+		},
+		{
+			description: "version wildcard matches",
+			in:          NewGvk("example.com", "v1beta2", "Widget"),
+			filter:      &Gvk{Group: "example.com", Version: "glob:v1*"},
+			expected:    true,
+		},
+		{
+			description: "version wildcard doesn't match",
+			in:          NewGvk("example.com", "v2", "Widget"),
+			filter:      &Gvk{Group: "example.com", Version: "glob:v1*"},
+			expected:    false,
+		},
+		{
+			description: "pattern without the glob prefix is literal",
+			in:          NewGvk("example.com", "v1beta1", "Widget"),
+			filter:      &Gvk{Version: "v1*"},
+			expected:    false,
+		},
+		{
+			description: "regex is literal",
+			in:          NewGvk("apps", "v1", "Deployment"),
+			filter:      &Gvk{Group: "a.*", Kind: "Deploy.*"},
+			expected:    false,
+		},
+		{
+			description: "group is literal",
+			in:          NewGvk("appsXopenshift.io", "v1", "Route"),
+			filter:      &Gvk{Group: "apps.openshift.io"},
+			expected:    false,
+		},
+		{
+			description: "kind wildcard matches",
+			in:          NewGvk("example.com", "v1", "WidgetSet"),
+			filter:      &Gvk{Kind: "glob:Widget*"},
+			expected:    true,
+		},
+		{
+			description: "group wildcard matches core group",
+			in:          NewGvk("", "v1", "ConfigMap"),
+			filter:      &Gvk{Group: "glob:*", Kind: "ConfigMap"},
+			expected:    true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -279,6 +333,37 @@ func TestSelectByGVK(t *testing.T) {
 	}
 }
 
+func TestCompileGlob(t *testing.T) {
+	testCases := map[string]struct {
+		matches    []string
+		mismatches []string
+	}{
+		"glob:v1*":           {[]string{"v1", "v1beta1"}, []string{"v2", "x/v1"}},
+		"glob:v1?":           {[]string{"v10"}, []string{"v1", "v1beta1"}},
+		"glob:v[12]":         {[]string{"v1", "v2"}, []string{"v3"}},
+		"glob:v[^1]":         {[]string{"v2"}, []string{"v1"}},
+		"glob:*[^1]":         {[]string{"v2"}, []string{"v1", "v/"}},
+		"glob:*.example.com": {[]string{"a.example.com"}, []string{"a.exampleXcom"}},
+		"glob:apps.k8s.io":   {[]string{"apps.k8s.io"}, []string{"appsXk8s.io"}},
+	}
+	for pattern, tc := range testCases {
+		r, err := CompileGlob(pattern)
+		if !assert.NoError(t, err, pattern) {
+			continue
+		}
+		for _, v := range tc.matches {
+			assert.True(t, r.MatchString(v), "%s should match %s", pattern, v)
+		}
+		for _, v := range tc.mismatches {
+			assert.False(t, r.MatchString(v), "%s shouldn't match %s", pattern, v)
+		}
+	}
+	_, err := CompileGlob("glob:v[1")
+	assert.Error(t, err)
+	_, err = CompileGlob("v1*")
+	assert.Error(t, err)
+}
+
 func TestIsClusterScoped(t *testing.T) {
 	testCases := []struct {
 		name            string
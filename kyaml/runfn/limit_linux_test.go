@@ -0,0 +1,24 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package runfn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+func TestRunFns_execMemoryLimit(t *testing.T) {
+	r := RunFns{EnableExec: true, Limits: runtimeutil.ResourceLimits{Memory: "1g"}}
+	f, err := r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{Path: "fn"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1<<30), f.(*exec.Filter).MemoryLimit)
+
+	f, err = r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{
+		Path: "fn", Limits: runtimeutil.ResourceLimits{Memory: "256m"}}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(256<<20), f.(*exec.Filter).MemoryLimit)
+}
@@ -0,0 +1,27 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !linux
+// +build !linux
+
+package runfn
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+)
+
+func TestRunFns_execMemoryLimit(t *testing.T) {
+	r := RunFns{EnableExec: true, Limits: runtimeutil.ResourceLimits{Memory: "1g"}}
+	f, err := r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{Path: "fn"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Zero(t, f.(*exec.Filter).MemoryLimit)
+
+	_, err = r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{
+		Path: "fn", Limits: runtimeutil.ResourceLimits{Memory: "256m"}}}, nil, nil)
+	assert.EqualError(t, err, "function fn: memory limits of exec functions aren't supported on "+runtime.GOOS)
+}
@@ -10,16 +10,22 @@ import (
 	"os/user"
 	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/gomodule"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/wasm"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -57,6 +63,14 @@ type RunFns struct {
 	// Output can be set to write the result to Output rather than back to the directory
 	Output io.Writer
 
+	// Source can be set to read the Resources from Source rather than from Input
+	// or a directory, e.g. from a kio.OCIReader
+	Source kio.Reader
+
+	// Sink can be set to write the result to Sink rather than to Output or back
+	// to the directory, e.g. to a kio.OCIWriter
+	Sink kio.Writer
+
 	// NoFunctionsFromInput if set to true will not read any functions from the input,
 	// and only use explicit sources
 	NoFunctionsFromInput *bool
@@ -64,9 +78,39 @@ type RunFns struct {
 	// EnableStarlark will enable functions run as starlark scripts
 	EnableStarlark bool
 
+	// StarlarkReadModule can be set to read the modules loaded by starlark
+	// functions with ReadModule, e.g. to apply load restrictions, rather
+	// than from the directory, which they are not allowed to leave
+	StarlarkReadModule func(path string) ([]byte, error)
+
 	// EnableExec will enable exec functions
 	EnableExec bool
 
+	// ExecAllowlist, if not nil, restricts the executables of exec functions
+	// to its executables, matched by path and digest.  It also restricts the
+	// built binaries of Go module functions, and both the runtimes and the
+	// modules of wasm functions.
+	ExecAllowlist []exec.AllowedExecutable
+
+	// ExecServers keeps the exec functions run as servers running for their
+	// next runs.  It's required by those functions.  The caller closes it.
+	ExecServers *exec.ServerPool
+
+	// SandboxExec will run exec functions in a sandbox, with access to the
+	// StorageMounts, and to the network only if Network is set
+	SandboxExec bool
+
+	// EnableWasm will enable functions run as WebAssembly modules
+	EnableWasm bool
+
+	// WasmRuntime is the WebAssembly runtime used to run wasm functions,
+	// one of wasm.Runtimes.  Autodetected if empty.
+	WasmRuntime string
+
+	// EnableGo will enable functions published as Go modules, which are
+	// built with the go command and run on the host like exec functions
+	EnableGo bool
+
 	// DisableContainers will disable functions run as containers
 	DisableContainers bool
 
@@ -79,6 +123,15 @@ type RunFns struct {
 	// LogWriter can be set to write the logs to LogWriter rather than stderr if LogSteps is enabled.
 	LogWriter io.Writer
 
+	// ResultsFunc, if set, is called with the results a function emitted in
+	// the results field of its ResourceList, even if the function failed.
+	ResultsFunc func(function string, results framework.Results)
+
+	// LogFunc, if set, is called with the lines the functions run as
+	// processes write to their stderr, as records identifying the function,
+	// rather than writing them to stderr.
+	LogFunc func(record runtimeutil.LogRecord)
+
 	// resultsCount is used to generate the results filename for each container
 	resultsCount uint32
 
@@ -91,7 +144,35 @@ type RunFns struct {
 	// the uid and gid that run the command
 	AsCurrentUser bool
 
-	// Env contains environment variables that will be exported to container
+	// ContainerRuntime is the container runtime used to run container
+	// functions, one of container.Runtimes.  Autodetected if empty.
+	ContainerRuntime string
+
+	// ContainerIsolation is the isolation of Windows containers, one of
+	// container.Isolations.  The runtime's default if empty.
+	ContainerIsolation string
+
+	// ContainerPool, if set, keeps the container functions running to send
+	// them their next runs.  The caller closes it.
+	ContainerPool *container.Pool
+
+	// OutputCache, if set, caches the outputs of the container functions
+	// whose image is pinned by digest, and of the exec and Go functions,
+	// so they aren't run again for the same functionConfig and items
+	OutputCache *runtimeutil.OutputCache
+
+	// ImagePullPolicy is the image pull policy of the container functions
+	// which don't declare one, one of runtimeutil.ImagePullPolicies.
+	ImagePullPolicy string
+
+	// Limits are the default resource limits of the container and exec
+	// functions which don't declare them.  The CPU limit only applies to
+	// the container functions, and the memory limit only applies to the exec
+	// functions where exec.MemoryLimitSupported.
+	Limits runtimeutil.ResourceLimits
+
+	// Env contains environment variables that will be exported to container.
+	// Those with a value are set for exec functions too.
 	Env []string
 
 	// ContinueOnEmptyResult configures what happens when the underlying pipeline
@@ -136,9 +217,12 @@ func (r RunFns) getNodesAndFilters() (
 		outputPkg = &kio.LocalPackageReadWriter{PackagePath: r.Path, MatchFilesGlob: kio.MatchAll}
 	}
 
-	if r.Input == nil {
+	switch {
+	case r.Source != nil:
+		p.Inputs = []kio.Reader{r.Source}
+	case r.Input == nil:
 		p.Inputs = []kio.Reader{outputPkg}
-	} else {
+	default:
 		p.Inputs = []kio.Reader{&kio.ByteReader{Reader: r.Input}}
 	}
 	if err := p.Execute(); err != nil {
@@ -184,7 +268,9 @@ func (r RunFns) runFunctions(
 	input kio.Reader, output kio.Writer, fltrs []kio.Filter) error {
 	// use the previously read Resources as input
 	var outputs []kio.Writer
-	if r.Output == nil {
+	if r.Sink != nil {
+		outputs = append(outputs, r.Sink)
+	} else if r.Output == nil {
 		// write back to the package
 		outputs = append(outputs, output)
 	} else {
@@ -202,24 +288,15 @@ func (r RunFns) runFunctions(
 	}
 	if r.LogSteps {
 		err = pipeline.ExecuteWithCallback(func(op kio.Filter) {
-			var identifier string
-
-			switch filter := op.(type) {
-			case *container.Filter:
-				identifier = filter.Image
-			case *exec.Filter:
-				identifier = filter.Path
-			case *starlark.Filter:
-				identifier = filter.String()
-			default:
-				identifier = "unknown-type function"
-			}
-
-			_, _ = fmt.Fprintf(r.LogWriter, "Running %s\n", identifier)
+			_, _ = fmt.Fprintf(r.LogWriter, "Running %s\n", functionIdentifier(op))
 		})
 	} else {
 		err = pipeline.Execute()
 	}
+	// the results of a failed function explain its failure
+	if errR := r.reportResults(fltrs); errR != nil && err == nil {
+		err = errR
+	}
 	if err != nil {
 		return err
 	}
@@ -241,6 +318,111 @@ func (r RunFns) runFunctions(
 	return nil
 }
 
+// functionIdentifier returns the image, path or name identifying the
+// function of the filter op.
+func functionIdentifier(op kio.Filter) string {
+	switch filter := op.(type) {
+	case *container.Filter:
+		return filter.Image
+	case *exec.Filter:
+		return filter.Path
+	case *wasm.Filter:
+		return filter.Path
+	case *gomodule.Filter:
+		return filter.Module
+	case *starlark.Filter:
+		return filter.String()
+	default:
+		return "unknown-type function"
+	}
+}
+
+// execLimits returns the timeout and memory limit of an exec function
+// declaring limits, or those of r.Limits.  The memory limit of r.Limits
+// only applies where exec.MemoryLimitSupported, so that the exec functions
+// still run elsewhere, while a function declaring limits.memory fails
+// there.  The CPU limit is ignored: an exec function runs as a plain
+// process on the host, whose share of the CPUs can't be limited portably
+// without a container.
+func (r RunFns) execLimits(limits runtimeutil.ResourceLimits) (time.Duration, int64, error) {
+	if limits.Memory != "" && !exec.MemoryLimitSupported {
+		return 0, 0, errors.Errorf("memory limits of exec functions aren't supported on %s", runtime.GOOS)
+	}
+	defaults := runtimeutil.ResourceLimits{Timeout: r.Limits.Timeout}
+	if exec.MemoryLimitSupported {
+		defaults.Memory = r.Limits.Memory
+	}
+	limits = limits.WithDefaults(defaults)
+	limits.CPU = ""
+	if err := limits.Validate(); err != nil {
+		return 0, 0, err
+	}
+	timeout, _ := limits.TimeoutDuration()
+	memory, _ := limits.MemoryBytes()
+	return timeout, memory, nil
+}
+
+// setLog sets the function run by f as a process to log its stderr with
+// r.LogFunc.
+func (r RunFns) setLog(f kio.Filter) {
+	if r.LogFunc == nil {
+		return
+	}
+	var ff *runtimeutil.FunctionFilter
+	switch filter := f.(type) {
+	case *container.Filter:
+		ff = &filter.Exec.FunctionFilter
+	case *exec.Filter:
+		ff = &filter.FunctionFilter
+	case *wasm.Filter:
+		ff = &filter.Exec.FunctionFilter
+	case *gomodule.Filter:
+		ff = &filter.Exec.FunctionFilter
+	default:
+		return
+	}
+	function := functionIdentifier(f)
+	ff.Log = func(record runtimeutil.LogRecord) {
+		record.Function = function
+		r.LogFunc(record)
+	}
+}
+
+// readStarlarkModule reads the starlark module at path with
+// r.StarlarkReadModule, or from r.Path if it's not set.
+func (r RunFns) readStarlarkModule(p string) ([]byte, error) {
+	if r.StarlarkReadModule != nil {
+		return r.StarlarkReadModule(p)
+	}
+	rel, err := filepath.Rel(r.Path, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, errors.Errorf("module %s not allowed outside of %s", p, r.Path)
+	}
+	return os.ReadFile(p)
+}
+
+// reportResults calls r.ResultsFunc with the results of each of the fltrs
+// which emitted some.
+func (r RunFns) reportResults(fltrs []kio.Filter) error {
+	if r.ResultsFunc == nil {
+		return nil
+	}
+	for _, f := range fltrs {
+		rf, ok := f.(runtimeutil.ResultsFunction)
+		if !ok || rf.GetResults() == nil {
+			continue
+		}
+		var results framework.Results
+		if err := rf.GetResults().Document().Decode(&results); err != nil {
+			return errors.WrapPrefixf(err, "decoding the results of function %s", functionIdentifier(f))
+		}
+		if len(results) > 0 {
+			r.ResultsFunc(functionIdentifier(f), results)
+		}
+	}
+	return nil
+}
+
 // getFunctionsFromInput scans the input for functions and runs them
 func (r RunFns) getFunctionsFromInput(nodes []*yaml.RNode) ([]kio.Filter, error) {
 	if *r.NoFunctionsFromInput {
@@ -303,6 +485,50 @@ func (r RunFns) mergeContainerEnv(envs []string) []string {
 	return declarative.Raw()
 }
 
+// execEnv returns the variables of Env with a value, which are set for
+// exec functions.  The variables without a value are exported to
+// containers, and exec functions have them already.
+func (r RunFns) execEnv() []string {
+	var env []string
+	for _, e := range r.Env {
+		if strings.Contains(e, "=") {
+			env = append(env, e)
+		}
+	}
+	return env
+}
+
+// envNamePattern matches the names of environment variables.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// declaredExecEnv returns the variables of the env allowlist of the exec
+// function spec as KEY=VALUE, taking the values of the variables declared
+// without one from Env, or from the current process if Env exports them.
+func (r RunFns) declaredExecEnv(spec runtimeutil.ExecSpec) ([]string, error) {
+	given := runtimeutil.NewContainerEnvFromStringSlice(r.Env)
+	var env []string
+	for _, e := range spec.Env {
+		key, value, hasValue := strings.Cut(e, "=")
+		if !envNamePattern.MatchString(key) {
+			return nil, errors.Errorf(
+				"exec function %s declares the invalid environment variable %q", spec.Path, e)
+		}
+		if !hasValue {
+			var ok bool
+			if value, ok = given.EnvVars[key]; !ok && given.HasExportedKey(key) {
+				value, ok = os.LookupEnv(key)
+			}
+			if !ok {
+				return nil, errors.Errorf(
+					"exec function %s requires the environment variable %s, which isn't given to the runner",
+					spec.Path, key)
+			}
+		}
+		env = append(env, key+"="+value)
+	}
+	return env, nil
+}
+
 func (r RunFns) getFunctionFilters(global bool, fns ...*yaml.RNode) (
 	[]kio.Filter, error) {
 	var fltrs []kio.Filter
@@ -331,6 +557,7 @@ func (r RunFns) getFunctionFilters(global bool, fns ...*yaml.RNode) (
 		if c == nil {
 			continue
 		}
+		r.setLog(c)
 		cf, ok := c.(*container.Filter)
 		if ok {
 			if global {
@@ -425,10 +652,10 @@ func (r *RunFns) init() {
 
 	// if no path is specified, default reading from stdin and writing to stdout
 	if r.Path == "" {
-		if r.Output == nil {
+		if r.Output == nil && r.Sink == nil {
 			r.Output = os.Stdout
 		}
-		if r.Input == nil {
+		if r.Input == nil && r.Source == nil {
 			r.Input = os.Stdin
 		}
 	}
@@ -479,21 +706,39 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 		// or from the declarative function mounts field.
 		storageMounts := spec.Container.StorageMounts
 		storageMounts = append(storageMounts, r.StorageMounts...)
+		for _, m := range storageMounts {
+			if err := m.Validate(); err != nil {
+				return nil, errors.WrapPrefixf(err, "function %s", spec.Container.Image)
+			}
+		}
+
+		// the policy declared by the function takes precedence
+		pullPolicy := spec.Container.ImagePullPolicy
+		if pullPolicy == "" {
+			pullPolicy = r.ImagePullPolicy
+		}
 
 		c := container.NewContainer(
 			runtimeutil.ContainerSpec{
-				Image:         spec.Container.Image,
-				Network:       spec.Container.Network,
-				StorageMounts: storageMounts,
-				Env:           spec.Container.Env,
+				Image:           spec.Container.Image,
+				Network:         spec.Container.Network,
+				AllowedHosts:    spec.Container.AllowedHosts,
+				StorageMounts:   storageMounts,
+				Env:             spec.Container.Env,
+				ImagePullPolicy: pullPolicy,
+				Limits:          spec.Container.Limits.WithDefaults(r.Limits),
 			},
 			uidgid,
 		)
 		cf := &c
+		cf.Runtime = r.ContainerRuntime
+		cf.Isolation = r.ContainerIsolation
+		cf.Pool = r.ContainerPool
 		cf.Exec.FunctionConfig = api
 		cf.Exec.GlobalScope = r.GlobalScope
 		cf.Exec.ResultsFile = resultsFile
 		cf.Exec.DeferFailure = spec.DeferFailure
+		cf.Exec.Cache = r.OutputCache
 		return cf, nil
 	}
 	if r.EnableStarlark && (spec.Starlark.Path != "" || spec.Starlark.URL != "") {
@@ -523,7 +768,8 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 			p = filepath.ToSlash(filepath.Join(r.Path, filepath.Dir(p), spec.Starlark.Path))
 		}
 
-		sf := &starlark.Filter{Name: spec.Starlark.Name, Path: p, URL: spec.Starlark.URL}
+		sf := &starlark.Filter{Name: spec.Starlark.Name, Path: p, URL: spec.Starlark.URL,
+			ReadModule: r.readStarlarkModule}
 
 		sf.FunctionConfig = api
 		sf.GlobalScope = r.GlobalScope
@@ -535,15 +781,63 @@ func (r *RunFns) ffp(spec runtimeutil.FunctionSpec, api *yaml.RNode, currentUser
 	if r.EnableExec && spec.Exec.Path != "" {
 		ef := &exec.Filter{
 			Path:       spec.Exec.Path,
+			Args:       spec.Exec.Args,
 			WorkingDir: r.WorkingDir,
+			Env:        r.execEnv(),
+			Allowlist:  r.ExecAllowlist,
+			Protocol:   spec.Exec.Protocol,
+			Servers:    r.ExecServers,
+		}
+		if r.SandboxExec {
+			ef.Sandbox = &exec.Sandbox{Mounts: r.StorageMounts, Network: r.Network}
+		}
+		if spec.Exec.Env != nil {
+			env, err := r.declaredExecEnv(spec.Exec)
+			if err != nil {
+				return nil, err
+			}
+			ef.Env, ef.IsolateEnv = env, true
+		}
+		var err error
+		if ef.Timeout, ef.MemoryLimit, err = r.execLimits(spec.Exec.Limits); err != nil {
+			return nil, errors.WrapPrefixf(err, "function %s", spec.Exec.Path)
 		}
 
 		ef.FunctionConfig = api
 		ef.GlobalScope = r.GlobalScope
 		ef.ResultsFile = resultsFile
 		ef.DeferFailure = spec.DeferFailure
+		ef.Cache = r.OutputCache
 		return ef, nil
 	}
 
+	if r.EnableWasm && spec.Wasm.Path != "" {
+		wf := wasm.NewWasm(spec.Wasm)
+		wf.Runtime = r.WasmRuntime
+		wf.Env = r.Env
+		wf.Exec.WorkingDir = r.WorkingDir
+		wf.Exec.Allowlist = r.ExecAllowlist
+
+		wf.Exec.FunctionConfig = api
+		wf.Exec.GlobalScope = r.GlobalScope
+		wf.Exec.ResultsFile = resultsFile
+		wf.Exec.DeferFailure = spec.DeferFailure
+		return &wf, nil
+	}
+
+	if r.EnableGo && spec.Go.Module != "" {
+		gf := gomodule.NewGoModule(spec.Go)
+		gf.Exec.WorkingDir = r.WorkingDir
+		gf.Exec.Env = r.execEnv()
+		gf.Exec.Allowlist = r.ExecAllowlist
+
+		gf.Exec.FunctionConfig = api
+		gf.Exec.GlobalScope = r.GlobalScope
+		gf.Exec.ResultsFile = resultsFile
+		gf.Exec.DeferFailure = spec.DeferFailure
+		gf.Exec.Cache = r.OutputCache
+		return &gf, nil
+	}
+
 	return nil, nil
 }
@@ -12,13 +12,19 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/kyaml/copyutil"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/container"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/exec"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/gomodule"
 	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/wasm"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -257,6 +263,10 @@ func TestRunFns_getFilters(t *testing.T) {
 
 		enableStarlark bool
 
+		enableWasm bool
+
+		enableGo bool
+
 		disableContainers bool
 	}{
 		// Test
@@ -671,6 +681,78 @@ metadata:
     config.kubernetes.io/function: |
       starlark:
         path: a/b/c
+`,
+				},
+			},
+		},
+
+		{name: "wasm-function",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: fn/validate.wasm
+`,
+				},
+			},
+			enableWasm: true,
+			out:        []string{"fn/validate.wasm"},
+		},
+
+		{name: "wasm-function-disabled",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      wasm:
+        path: fn/validate.wasm
+`,
+				},
+			},
+		},
+
+		{name: "go-function",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      go:
+        module: example.com/functions/set-labels@v1.2.0
+`,
+				},
+			},
+			enableGo: true,
+			out:      []string{"example.com/functions/set-labels@v1.2.0"},
+		},
+
+		{name: "go-function-disabled",
+			in: []f{
+				{
+					path: filepath.Join("foo", "bar.yaml"),
+					value: `
+apiVersion: example.com/v1alpha1
+kind: ExampleFunction
+metadata:
+  annotations:
+    config.kubernetes.io/function: |
+      go:
+        module: example.com/functions/set-labels@v1.2.0
 `,
 				},
 			},
@@ -723,6 +805,8 @@ metadata:
 			// init the instance
 			r := &RunFns{
 				EnableStarlark:       tt.enableStarlark,
+				EnableWasm:           tt.enableWasm,
+				EnableGo:             tt.enableGo,
 				DisableContainers:    tt.disableContainers,
 				FunctionPaths:        fnPaths,
 				Functions:            parsedFns,
@@ -763,6 +847,142 @@ metadata:
 	}
 }
 
+func TestRunFns_readStarlarkModule(t *testing.T) {
+	d := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(d, "lib.star"), []byte("x = 1"), 0600))
+	r := RunFns{Path: filepath.Join(d, "pkg")}
+
+	_, err := r.readStarlarkModule(filepath.Join(d, "lib.star"))
+	assert.EqualError(t, err, fmt.Sprintf("module %s not allowed outside of %s",
+		filepath.Join(d, "lib.star"), filepath.Join(d, "pkg")))
+
+	r.Path = d
+	b, err := r.readStarlarkModule(filepath.Join(d, "lib.star"))
+	assert.NoError(t, err)
+	assert.Equal(t, "x = 1", string(b))
+
+	r.StarlarkReadModule = func(path string) ([]byte, error) { return []byte(path), nil }
+	b, err = r.readStarlarkModule("/elsewhere/lib.star")
+	assert.NoError(t, err)
+	assert.Equal(t, "/elsewhere/lib.star", string(b))
+}
+
+func TestRunFns_declaredExecEnv(t *testing.T) {
+	t.Setenv("KUSTOMIZE_EXPORTED", "exported")
+	t.Setenv("KUSTOMIZE_AMBIENT", "ambient")
+	r := RunFns{Env: []string{"REGION=us", "KUSTOMIZE_EXPORTED"}}
+
+	env, err := r.declaredExecEnv(runtimeutil.ExecSpec{
+		Path: "fn.sh",
+		Env:  []string{"REGION", "KUSTOMIZE_EXPORTED", "TEAM=platform"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"REGION=us", "KUSTOMIZE_EXPORTED=exported", "TEAM=platform"}, env)
+
+	// the variables of the runner's process aren't given unless exported
+	_, err = r.declaredExecEnv(runtimeutil.ExecSpec{Path: "fn.sh", Env: []string{"KUSTOMIZE_AMBIENT"}})
+	assert.EqualError(t, err, "exec function fn.sh requires the environment variable "+
+		"KUSTOMIZE_AMBIENT, which isn't given to the runner")
+	_, err = r.declaredExecEnv(runtimeutil.ExecSpec{Path: "fn.sh", Env: []string{"A-B=c"}})
+	assert.EqualError(t, err, `exec function fn.sh declares the invalid environment variable "A-B=c"`)
+
+	r.EnableExec = true
+	f, err := r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{
+		Path: "fn.sh",
+		Args: []string{"--team", "platform"},
+		Env:  []string{"REGION"},
+	}}, nil, nil)
+	assert.NoError(t, err)
+	ef := f.(*exec.Filter)
+	assert.Equal(t, []string{"--team", "platform"}, ef.Args)
+	assert.Equal(t, []string{"REGION=us"}, ef.Env)
+	assert.True(t, ef.IsolateEnv)
+
+	f, err = r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{Path: "fn.sh"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.False(t, f.(*exec.Filter).IsolateEnv)
+}
+
+func TestRunFns_sandboxExec(t *testing.T) {
+	mounts := []runtimeutil.StorageMount{{MountType: "bind", Src: "charts", DstPath: "/charts"}}
+	r := RunFns{EnableExec: true, SandboxExec: true, Network: true, StorageMounts: mounts}
+	f, err := r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{Path: "fn.sh"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, &exec.Sandbox{Mounts: mounts, Network: true}, f.(*exec.Filter).Sandbox)
+
+	r.SandboxExec = false
+	f, err = r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{Path: "fn.sh"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, f.(*exec.Filter).Sandbox)
+}
+
+func TestRunFns_execServers(t *testing.T) {
+	servers := exec.NewServerPool(nil)
+	r := RunFns{EnableExec: true, ExecServers: servers}
+	f, err := r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{
+		Path: "fn", Protocol: runtimeutil.ExecProtocolServer}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, runtimeutil.ExecProtocolServer, f.(*exec.Filter).Protocol)
+	assert.Same(t, servers, f.(*exec.Filter).Servers)
+}
+
+func TestRunFns_execAllowlist(t *testing.T) {
+	allowlist := []exec.AllowedExecutable{{Path: "/usr/bin/fn", Sha256: strings.Repeat("0", 64)}}
+	r := RunFns{EnableExec: true, EnableWasm: true, EnableGo: true, ExecAllowlist: allowlist}
+	f, err := r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{Path: "fn"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, allowlist, f.(*exec.Filter).Allowlist)
+
+	// the allowlist restricts the functions run by executables too
+	f, err = r.ffp(runtimeutil.FunctionSpec{Wasm: runtimeutil.WasmSpec{Path: "fn.wasm"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, allowlist, f.(*wasm.Filter).Exec.Allowlist)
+	f, err = r.ffp(runtimeutil.FunctionSpec{Go: runtimeutil.GoSpec{
+		Module: "example.com/functions/set-labels@v1.2.0"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, allowlist, f.(*gomodule.Filter).Exec.Allowlist)
+}
+
+func TestRunFns_setLog(t *testing.T) {
+	var records []runtimeutil.LogRecord
+	r := RunFns{LogFunc: func(record runtimeutil.LogRecord) { records = append(records, record) }}
+	f := &container.Filter{ContainerSpec: runtimeutil.ContainerSpec{Image: "example.com/fn"}}
+	r.setLog(f)
+	f.Exec.Log(runtimeutil.LogRecord{Message: "done"})
+	assert.Equal(t, []runtimeutil.LogRecord{{Function: "example.com/fn", Message: "done"}}, records)
+}
+
+func TestRunFns_limits(t *testing.T) {
+	r := RunFns{EnableExec: true, Limits: runtimeutil.ResourceLimits{Timeout: "10m", CPU: "2", Memory: "1g"}}
+	f, err := r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{
+		Path: "fn", Limits: runtimeutil.ResourceLimits{Timeout: "30s"}}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, f.(*exec.Filter).Timeout)
+
+	f, err = r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{
+		Path: "fn", Limits: runtimeutil.ResourceLimits{CPU: "1"}}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, f.(*exec.Filter).Timeout)
+
+	f, err = r.ffp(runtimeutil.FunctionSpec{Container: runtimeutil.ContainerSpec{
+		Image: "example.com/fn", Limits: runtimeutil.ResourceLimits{Memory: "256m"}}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, runtimeutil.ResourceLimits{Timeout: "10m", CPU: "2", Memory: "256m"},
+		f.(*container.Filter).Limits)
+}
+
+func TestRunFns_outputCache(t *testing.T) {
+	cache := &runtimeutil.OutputCache{Dir: t.TempDir()}
+	r := RunFns{EnableExec: true, OutputCache: cache}
+	f, err := r.ffp(runtimeutil.FunctionSpec{Exec: runtimeutil.ExecSpec{Path: "fn.sh"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Same(t, cache, f.(*exec.Filter).Cache)
+
+	f, err = r.ffp(runtimeutil.FunctionSpec{Container: runtimeutil.ContainerSpec{Image: "example.com/fn"}}, nil, nil)
+	assert.NoError(t, err)
+	assert.Same(t, cache, f.(*container.Filter).Exec.Cache)
+}
+
 func TestRunFns_sortFns(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -1162,6 +1382,45 @@ func TestCmd_Execute_setInput(t *testing.T) {
 	assert.Contains(t, string(b), "kind: StatefulSet")
 }
 
+// TestCmd_Execute_setSourceAndSink tests the execution of a filter reading from
+// a kio.Reader and writing to a kio.Writer
+func TestCmd_Execute_setSourceAndSink(t *testing.T) {
+	dir := setupTest(t)
+	if !assert.NoError(t, os.WriteFile(
+		filepath.Join(dir, "filter.yaml"), []byte(ValueReplacerYAMLData), 0600)) {
+		return
+	}
+
+	read, err := kio.LocalPackageReader{PackagePath: dir}.Read()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	sink := &kio.PackageBuffer{}
+	instance := RunFns{
+		Source:                 &kio.PackageBuffer{Nodes: read},
+		Sink:                   sink,
+		Path:                   dir,
+		functionFilterProvider: getFilterProvider(t),
+	}
+	// initialize the defaults
+	instance.init()
+
+	if !assert.NoError(t, instance.Execute()) {
+		return
+	}
+	b, err := os.ReadFile(
+		filepath.Join(dir, "java", "java-deployment.resource.yaml"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NotContains(t, string(b), "kind: StatefulSet")
+	out, err := kio.StringAll(sink.Nodes)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, out, "kind: StatefulSet")
+}
+
 // TestCmd_Execute_enableLogSteps tests the execution of a filter with LogSteps enabled.
 func TestCmd_Execute_enableLogSteps(t *testing.T) {
 	dir := setupTest(t)
@@ -1371,3 +1630,53 @@ func TestRunFns_mergeContainerEnv(t *testing.T) {
 		})
 	}
 }
+
+// TestCmd_Execute_resultsFunc tests the results of a failed function are
+// reported to ResultsFunc
+func TestCmd_Execute_resultsFunc(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the function is a shell script")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "validate.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+cat <<RESULTS
+apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items: []
+results:
+- message: replicas must be set
+  severity: error
+  resourceRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: app
+- message: image is not pinned
+  severity: warning
+RESULTS
+exit 1
+`), 0700))
+	fn := yaml.MustParse(`apiVersion: example.com/v1
+kind: Validator
+metadata:
+  name: validate
+  annotations:
+    config.kubernetes.io/function: |
+      exec:
+        path: ` + script + `
+`)
+
+	reported := map[string]framework.Results{}
+	err := RunFns{
+		Input:       bytes.NewBufferString("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: app\n"),
+		Output:      &bytes.Buffer{},
+		Functions:   []*yaml.RNode{fn},
+		EnableExec:  true,
+		WorkingDir:  dir,
+		ResultsFunc: func(function string, results framework.Results) { reported[function] = results },
+	}.Execute()
+	require.Error(t, err)
+	require.Len(t, reported[script], 2)
+	assert.Equal(t, "[error] apps/v1/Deployment/app: replicas must be set", reported[script][0].String())
+	assert.Equal(t, framework.Warning, reported[script][1].Severity)
+}
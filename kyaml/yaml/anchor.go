@@ -0,0 +1,304 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"strconv"
+
+	"sigs.k8s.io/kustomize/kyaml/internal/forked/github.com/go-yaml/yaml"
+)
+
+// AnchorRecords records the YAML anchors, aliases and merge keys of a node
+// before they were inflated by DeAnchorWithRecords, so that they can later be
+// restored by ReAnchor.
+type AnchorRecords struct {
+	// Anchors are the anchors defined in the node.
+	Anchors []AnchorRecord `json:"anchors,omitempty" yaml:"anchors,omitempty"`
+
+	// Merges are the mappings that merged anchors using the '<<' merge key.
+	Merges []MergeRecord `json:"merges,omitempty" yaml:"merges,omitempty"`
+}
+
+// AnchorRecord records a single anchor definition and its aliases.
+// Paths are lists of mapping keys and sequence indexes from the root node.
+type AnchorRecord struct {
+	// Anchor is the name of the anchor.
+	Anchor string `json:"anchor" yaml:"anchor"`
+
+	// Path is the path to the node defining the anchor.
+	Path []string `json:"path" yaml:"path"`
+
+	// Aliases are the paths to the nodes that were aliases of the anchor.
+	Aliases [][]string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+}
+
+// MergeRecord records a '<<' merge key.
+type MergeRecord struct {
+	// Path is the path to the mapping containing the merge key.
+	Path []string `json:"path" yaml:"path"`
+
+	// Anchors are the names of the merged anchors, in order of precedence.
+	Anchors []string `json:"anchors" yaml:"anchors"`
+
+	// Keys are the keys which were explicitly set in the mapping.
+	Keys []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+}
+
+// IsEmpty returns true if no anchors or merge keys were recorded.
+func (r AnchorRecords) IsEmpty() bool {
+	return len(r.Anchors) == 0 && len(r.Merges) == 0
+}
+
+// DeAnchorWithRecords inflates all YAML aliases with their anchor values like
+// DeAnchor, and returns a record of the anchors, aliases and merge keys it
+// removed.
+func (rn *RNode) DeAnchorWithRecords() (AnchorRecords, error) {
+	var records AnchorRecords
+	defs := map[*yaml.Node]int{}
+	recordAnchors(rn.YNode(), nil, defs, &records)
+	if err := rn.DeAnchor(); err != nil {
+		return AnchorRecords{}, err
+	}
+	return records, nil
+}
+
+// recordAnchors walks yn adding anchor definitions, aliases and merge keys to records.
+// defs maps anchor definition nodes to their index in records.Anchors.
+func recordAnchors(yn *yaml.Node, path []string, defs map[*yaml.Node]int, records *AnchorRecords) {
+	if yn == nil {
+		return
+	}
+	if yn.Kind == yaml.AliasNode {
+		if i, found := defs[yn.Alias]; found {
+			records.Anchors[i].Aliases = append(records.Anchors[i].Aliases, copyPath(path))
+		}
+		return
+	}
+	if yn.Anchor != "" {
+		defs[yn] = len(records.Anchors)
+		records.Anchors = append(records.Anchors, AnchorRecord{Anchor: yn.Anchor, Path: copyPath(path)})
+	}
+	switch yn.Kind {
+	case yaml.MappingNode:
+		var keys []string
+		var merge *MergeRecord
+		for i := 0; i+1 < len(yn.Content); i += 2 {
+			key, value := yn.Content[i], yn.Content[i+1]
+			if isMerge(key) {
+				if anchors := mergeAnchorNames(value, defs); anchors != nil {
+					merge = &MergeRecord{Path: copyPath(path), Anchors: anchors}
+				}
+				continue
+			}
+			keys = append(keys, key.Value)
+			recordAnchors(value, append(path, key.Value), defs, records)
+		}
+		if merge != nil {
+			merge.Keys = keys
+			records.Merges = append(records.Merges, *merge)
+		}
+	case yaml.SequenceNode:
+		for i := range yn.Content {
+			recordAnchors(yn.Content[i], append(path, strconv.Itoa(i)), defs, records)
+		}
+	}
+}
+
+// mergeAnchorNames returns the names of the anchors referenced by a merge key value,
+// or nil if the value is not an alias or a sequence of aliases.
+func mergeAnchorNames(yn *yaml.Node, defs map[*yaml.Node]int) []string {
+	var aliases []*yaml.Node
+	switch yn.Kind {
+	case yaml.AliasNode:
+		aliases = []*yaml.Node{yn}
+	case yaml.SequenceNode:
+		aliases = yn.Content
+	default:
+		return nil
+	}
+	var names []string
+	for _, a := range aliases {
+		if a.Kind != yaml.AliasNode {
+			return nil
+		}
+		if _, found := defs[a.Alias]; !found {
+			return nil
+		}
+		names = append(names, a.Value)
+	}
+	return names
+}
+
+// ReAnchor restores the anchors, aliases and merge keys recorded by DeAnchorWithRecords.
+// An alias is only restored if the node at its path is still equal to the anchor
+// definition and follows it in the document, and a merged field is only removed if
+// its value is still equal to the merged value.  Everything that cannot be restored
+// is left inflated, so ReAnchor never changes the data represented by the node.
+func (rn *RNode) ReAnchor(records AnchorRecords) {
+	root := rn.YNode()
+	order := map[*yaml.Node]int{}
+	documentOrder(root, order)
+
+	anchors := map[string]*yaml.Node{}
+	for _, rec := range records.Anchors {
+		def := lookupAnchorPath(root, rec.Path)
+		if def == nil || def.Kind == yaml.AliasNode {
+			continue
+		}
+		restored := false
+		for _, p := range rec.Aliases {
+			parent, i := lookupAnchorPathParent(root, p)
+			if parent == nil {
+				continue
+			}
+			node := parent.Content[i]
+			if node == def || order[node] <= order[def] || !yNodesEqual(node, def) {
+				continue
+			}
+			parent.Content[i] = &yaml.Node{Kind: yaml.AliasNode, Value: rec.Anchor, Alias: def}
+			restored = true
+		}
+		// keep anchors which were never aliased, they were written that way
+		if restored || len(rec.Aliases) == 0 {
+			def.Anchor = rec.Anchor
+		}
+		anchors[rec.Anchor] = def
+	}
+
+	for _, rec := range records.Merges {
+		restoreMerge(root, rec, anchors, order)
+	}
+}
+
+// restoreMerge restores a single merge key, removing the fields provided by the
+// merged anchors from the mapping.
+func restoreMerge(root *yaml.Node, rec MergeRecord, anchors map[string]*yaml.Node, order map[*yaml.Node]int) {
+	m := lookupAnchorPath(root, rec.Path)
+	if m == nil || m.Kind != yaml.MappingNode {
+		return
+	}
+	var defs []*yaml.Node
+	for _, name := range rec.Anchors {
+		def := anchors[name]
+		if def == nil || def.Kind != yaml.MappingNode || order[m] <= order[def] {
+			return
+		}
+		defs = append(defs, def)
+	}
+
+	// the first anchor providing a field takes precedence
+	merged := map[string]*yaml.Node{}
+	for _, def := range defs {
+		for i := 0; i+1 < len(def.Content); i += 2 {
+			if _, found := merged[def.Content[i].Value]; !found {
+				merged[def.Content[i].Value] = def.Content[i+1]
+			}
+		}
+	}
+
+	var value *yaml.Node
+	if len(defs) == 1 {
+		value = &yaml.Node{Kind: yaml.AliasNode, Value: rec.Anchors[0], Alias: defs[0]}
+	} else {
+		value = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := range defs {
+			value.Content = append(value.Content,
+				&yaml.Node{Kind: yaml.AliasNode, Value: rec.Anchors[i], Alias: defs[i]})
+		}
+	}
+	explicit := map[string]bool{}
+	for _, k := range rec.Keys {
+		explicit[k] = true
+	}
+	// leave the tag empty, the encoder would otherwise emit an explicit !!merge tag
+	content := []*yaml.Node{{Kind: yaml.ScalarNode, Value: "<<"}, value}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k := m.Content[i].Value
+		if v, found := merged[k]; found && !explicit[k] && yNodesEqual(v, m.Content[i+1]) {
+			continue
+		}
+		content = append(content, m.Content[i], m.Content[i+1])
+	}
+	m.Content = content
+}
+
+// documentOrder records the position of each node in a pre-order traversal
+func documentOrder(yn *yaml.Node, order map[*yaml.Node]int) {
+	if yn == nil {
+		return
+	}
+	order[yn] = len(order) + 1
+	for i := range yn.Content {
+		documentOrder(yn.Content[i], order)
+	}
+}
+
+// lookupAnchorPath returns the node at path, or nil if it doesn't exist
+func lookupAnchorPath(root *yaml.Node, path []string) *yaml.Node {
+	if len(path) == 0 {
+		return root
+	}
+	parent, i := lookupAnchorPathParent(root, path)
+	if parent == nil {
+		return nil
+	}
+	return parent.Content[i]
+}
+
+// lookupAnchorPathParent returns the parent of the node at path and the node's
+// index in the parent's Content, or nil if it doesn't exist
+func lookupAnchorPathParent(root *yaml.Node, path []string) (*yaml.Node, int) {
+	if len(path) == 0 {
+		return nil, 0
+	}
+	parent := lookupAnchorPath(root, path[:len(path)-1])
+	if parent == nil {
+		return nil, 0
+	}
+	field := path[len(path)-1]
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == field {
+				return parent, i + 1
+			}
+		}
+	case yaml.SequenceNode:
+		i, err := strconv.Atoi(field)
+		if err == nil && i >= 0 && i < len(parent.Content) {
+			return parent, i
+		}
+	}
+	return nil, 0
+}
+
+// yNodesEqual returns true if a and b represent the same data, ignoring
+// style, comments and position.
+func yNodesEqual(a, b *yaml.Node) bool {
+	if a.Kind == yaml.AliasNode {
+		a = a.Alias
+	}
+	if b.Kind == yaml.AliasNode {
+		b = b.Alias
+	}
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	if a.Kind == yaml.ScalarNode && (a.Value != b.Value || a.ShortTag() != b.ShortTag()) {
+		return false
+	}
+	for i := range a.Content {
+		if !yNodesEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func copyPath(path []string) []string {
+	return append([]string{}, path...)
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRNode_DeAnchorWithRecords_ReAnchor(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected AnchorRecords
+	}{
+		"alias": {
+			input: `a: &x
+  b: c
+d: *x
+e: [*x, *x]
+`,
+			expected: AnchorRecords{Anchors: []AnchorRecord{
+				{Anchor: "x", Path: []string{"a"}, Aliases: [][]string{{"d"}, {"e", "0"}, {"e", "1"}}},
+			}},
+		},
+		"unused anchor": {
+			input: `a: &x b
+`,
+			expected: AnchorRecords{Anchors: []AnchorRecord{{Anchor: "x", Path: []string{"a"}}}},
+		},
+		"multiple merges": {
+			input: `a: &x
+  b: c
+  d: e
+f: &y
+  d: g
+  h: i
+j:
+  <<: [*x, *y]
+  b: c
+  k: l
+`,
+			expected: AnchorRecords{
+				Anchors: []AnchorRecord{
+					{Anchor: "x", Path: []string{"a"}},
+					{Anchor: "y", Path: []string{"f"}},
+				},
+				Merges: []MergeRecord{{Path: []string{"j"}, Anchors: []string{"x", "y"}, Keys: []string{"b", "k"}}},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rn := MustParse(tc.input)
+			records, err := rn.DeAnchorWithRecords()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, records)
+
+			inflated := rn.MustString()
+			assert.NotContains(t, inflated, "&")
+			assert.NotContains(t, inflated, "*")
+
+			rn.ReAnchor(records)
+			assert.Equal(t, tc.input, rn.MustString())
+		})
+	}
+}
+
+func TestRNode_ReAnchor_modified(t *testing.T) {
+	rn := MustParse(`a: &x
+  b: c
+d: *x
+e: *x
+`)
+	records, err := rn.DeAnchorWithRecords()
+	require.NoError(t, err)
+	require.NoError(t, rn.PipeE(Lookup("e"), SetField("b", NewStringRNode("z"))))
+
+	rn.ReAnchor(records)
+	assert.Equal(t, `a: &x
+  b: c
+d: *x
+e:
+  b: z
+`, rn.MustString())
+
+	// an anchor whose aliases have all been modified is dropped
+	rn = MustParse(`a: &x b
+c: *x
+`)
+	records, err = rn.DeAnchorWithRecords()
+	require.NoError(t, err)
+	require.NoError(t, rn.PipeE(SetField("c", NewStringRNode("d"))))
+	rn.ReAnchor(records)
+	assert.Equal(t, `a: b
+c: d
+`, rn.MustString())
+}
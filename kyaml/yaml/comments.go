@@ -0,0 +1,169 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/internal/forked/github.com/go-yaml/yaml"
+)
+
+// The comment APIs place comments on the yaml nodes where the parser places the
+// comments it reads, so that comments set by them are returned by the same APIs
+// after the Resources are written and read back.
+//
+// The comments of a field are split between the key and value nodes of the field,
+// they should be managed with GetFieldComments and SetFieldComments rather than
+// with GetComments and SetComments of the value.  List elements have no key, so
+// their comments are managed with GetComments and SetComments.
+
+// GetComments returns the comments of the node.
+// For list elements these are the comments of the element.
+func (rn *RNode) GetComments() Comments {
+	if rn.IsNil() {
+		return Comments{}
+	}
+	n := rn.YNode()
+	return Comments{
+		HeadComment: n.HeadComment,
+		LineComment: n.LineComment,
+		FootComment: n.FootComment,
+	}
+}
+
+// SetComments sets the comments of the node, replacing its existing comments.
+// Block style mappings and sequences can only have a head comment, since their
+// line and foot comments are written on the lines of their contents.  An error
+// is returned for line or foot comments of non-empty block style mappings and
+// sequences.
+func (rn *RNode) SetComments(c Comments) error {
+	if rn.IsNil() {
+		return errors.Errorf("cannot set comments of a nil node")
+	}
+	n := rn.YNode()
+	if (c.LineComment != "" || c.FootComment != "") && isBlockCollection(n) {
+		return errors.Errorf(
+			"line and foot comments are not supported for block style %s", nodeKindName(n))
+	}
+	n.HeadComment = formatComment(c.HeadComment)
+	n.LineComment = formatComment(c.LineComment)
+	n.FootComment = formatComment(c.FootComment)
+	return nil
+}
+
+// GetFieldComments returns the comments of the field of a MappingNode, which are
+// split between the key and the value of the field.
+func (rn *RNode) GetFieldComments(field string) (Comments, error) {
+	f, err := rn.commentedField(field)
+	if err != nil {
+		return Comments{}, err
+	}
+	key, value := f.Key.YNode(), f.Value.YNode()
+	c := Comments{
+		HeadComment: joinComments(key.HeadComment, value.HeadComment),
+		LineComment: joinComments(key.LineComment, value.LineComment),
+		FootComment: joinComments(key.FootComment, value.FootComment),
+	}
+	return c, nil
+}
+
+// SetFieldComments sets the comments of the field of a MappingNode, replacing its
+// existing comments.  The line comment is set on the value of the field if it is
+// written on the line of the field, otherwise on the key.  The other comments are
+// set on the key.
+func (rn *RNode) SetFieldComments(field string, c Comments) error {
+	f, err := rn.commentedField(field)
+	if err != nil {
+		return err
+	}
+	key, value := f.Key.YNode(), f.Value.YNode()
+	key.HeadComment = formatComment(c.HeadComment)
+	key.FootComment = formatComment(c.FootComment)
+	value.HeadComment, value.FootComment = "", ""
+	if isBlockCollection(value) {
+		key.LineComment, value.LineComment = formatComment(c.LineComment), ""
+	} else {
+		key.LineComment, value.LineComment = "", formatComment(c.LineComment)
+	}
+	return nil
+}
+
+// MoveComments moves the comments of the node to the node to, replacing its
+// existing comments.  The comments of the node are cleared.
+func (rn *RNode) MoveComments(to *RNode) error {
+	if err := to.SetComments(rn.GetComments()); err != nil {
+		return err
+	}
+	return rn.SetComments(Comments{})
+}
+
+// MoveFieldComments moves the comments of the field from to the field to of the
+// MappingNode, replacing its existing comments.  The comments of from are cleared.
+func (rn *RNode) MoveFieldComments(from, to string) error {
+	c, err := rn.GetFieldComments(from)
+	if err != nil {
+		return err
+	}
+	if err := rn.SetFieldComments(to, c); err != nil {
+		return err
+	}
+	return rn.SetFieldComments(from, Comments{})
+}
+
+// commentedField returns the field of the MappingNode or an error if it doesn't exist.
+func (rn *RNode) commentedField(field string) (*MapNode, error) {
+	if err := ErrorIfInvalid(rn, yaml.MappingNode); err != nil {
+		return nil, err
+	}
+	var f *MapNode
+	if !rn.IsNil() {
+		f = rn.Field(field)
+	}
+	if f == nil {
+		return nil, errors.Errorf("field %q does not exist", field)
+	}
+	return f, nil
+}
+
+// isBlockCollection returns true if n is a non-empty block style mapping or sequence.
+func isBlockCollection(n *yaml.Node) bool {
+	return (n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode) &&
+		len(n.Content) > 0 && n.Style&yaml.FlowStyle == 0
+}
+
+// nodeKindName returns a name of the kind of n for messages.
+func nodeKindName(n *yaml.Node) string {
+	if n.Kind == yaml.MappingNode {
+		return "mappings"
+	}
+	return "sequences"
+}
+
+// formatComment prefixes each line of the comment with "# " unless it is
+// already a yaml comment.
+func formatComment(c string) string {
+	if c == "" {
+		return ""
+	}
+	lines := strings.Split(c, "\n")
+	for i := range lines {
+		if lines[i] != "" && !strings.HasPrefix(lines[i], "#") {
+			lines[i] = "# " + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinComments joins comments of the same kind split between nodes.
+func joinComments(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + "\n" + b
+	}
+}
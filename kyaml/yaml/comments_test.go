@@ -0,0 +1,136 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const commentsInput = `# head a
+a: b # line a
+# foot a
+
+c: # line c
+  d: e
+l:
+# head x
+- x: y
+  z: w
+# head s
+- s # line s
+`
+
+func TestGetFieldComments(t *testing.T) {
+	rn := MustParse(commentsInput)
+
+	c, err := rn.GetFieldComments("a")
+	require.NoError(t, err)
+	assert.Equal(t, Comments{HeadComment: "# head a", LineComment: "# line a", FootComment: "# foot a"}, c)
+
+	c, err = rn.GetFieldComments("c")
+	require.NoError(t, err)
+	assert.Equal(t, Comments{LineComment: "# line c"}, c)
+
+	_, err = rn.GetFieldComments("missing")
+	assert.EqualError(t, err, `field "missing" does not exist`)
+
+	elems, err := rn.Field("l").Value.Elements()
+	require.NoError(t, err)
+	assert.Equal(t, Comments{HeadComment: "# head x"}, elems[0].GetComments())
+	assert.Equal(t, Comments{HeadComment: "# head s", LineComment: "# line s"}, elems[1].GetComments())
+}
+
+func TestSetComments_roundTrip(t *testing.T) {
+	rn := MustParse(`a: b
+c:
+  d: e
+f: []
+l:
+- x: y
+- s
+- t
+`)
+	require.NoError(t, rn.SetFieldComments("a", Comments{
+		HeadComment: "head a", LineComment: "line a", FootComment: "foot a"}))
+	require.NoError(t, rn.SetFieldComments("c", Comments{HeadComment: "head c", LineComment: "line c"}))
+	require.NoError(t, rn.SetFieldComments("f", Comments{LineComment: "line f"}))
+	l := rn.Field("l").Value
+	elems, err := l.Elements()
+	require.NoError(t, err)
+	require.NoError(t, elems[0].SetComments(Comments{HeadComment: "head x"}))
+	require.NoError(t, elems[1].SetComments(Comments{
+		HeadComment: "head s", LineComment: "line s", FootComment: "foot s"}))
+
+	expected := `# head a
+a: b # line a
+# foot a
+
+# head c
+c: # line c
+  d: e
+f: [] # line f
+l:
+# head x
+- x: y
+# head s
+- s # line s
+# foot s
+
+- t
+`
+	out := rn.MustString()
+	assert.Equal(t, expected, out)
+
+	// the comments are read back from the same nodes
+	rn = MustParse(out)
+	c, err := rn.GetFieldComments("a")
+	require.NoError(t, err)
+	assert.Equal(t, Comments{HeadComment: "# head a", LineComment: "# line a", FootComment: "# foot a"}, c)
+	c, err = rn.GetFieldComments("c")
+	require.NoError(t, err)
+	assert.Equal(t, Comments{HeadComment: "# head c", LineComment: "# line c"}, c)
+	c, err = rn.GetFieldComments("f")
+	require.NoError(t, err)
+	assert.Equal(t, Comments{LineComment: "# line f"}, c)
+	elems, err = rn.Field("l").Value.Elements()
+	require.NoError(t, err)
+	assert.Equal(t, Comments{HeadComment: "# head x"}, elems[0].GetComments())
+	assert.Equal(t, Comments{HeadComment: "# head s", LineComment: "# line s", FootComment: "# foot s"},
+		elems[1].GetComments())
+}
+
+func TestSetComments_blockCollection(t *testing.T) {
+	rn := MustParse(`
+- x: y
+`)
+	elems, err := rn.Elements()
+	require.NoError(t, err)
+	err = elems[0].SetComments(Comments{LineComment: "line"})
+	assert.EqualError(t, err, "line and foot comments are not supported for block style mappings")
+	assert.NoError(t, elems[0].SetComments(Comments{HeadComment: "head"}))
+}
+
+func TestMoveComments(t *testing.T) {
+	rn := MustParse(`# head a
+a: b # line a
+c: d
+l:
+- s # line s
+- t
+`)
+	require.NoError(t, rn.MoveFieldComments("a", "c"))
+	elems, err := rn.Field("l").Value.Elements()
+	require.NoError(t, err)
+	require.NoError(t, elems[0].MoveComments(elems[1]))
+	assert.Equal(t, `a: b
+# head a
+c: d # line a
+l:
+- s
+- t # line s
+`, rn.MustString())
+}
@@ -4,11 +4,15 @@
 package yaml
 
 import (
+	"math"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 
 	y1_1 "gopkg.in/yaml.v2"
 	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/kyaml/errors"
 	y1_2 "sigs.k8s.io/kustomize/kyaml/internal/forked/github.com/go-yaml/yaml"
 )
 
@@ -98,3 +102,190 @@ func IsValueNonString(value string) bool {
 }
 
 var stringType = reflect.TypeOf("string")
+
+// ScalarCompatibility is how plain scalars which YAML 1.1 and YAML 1.2 parsers
+// interpret differently, e.g. `on`, `y` or `0644`, are handled.
+type ScalarCompatibility string
+
+const (
+	// ScalarCompatibilityDefault leaves the scalars unchanged, so that they are
+	// interpreted as YAML 1.2 by kyaml, e.g. `0644` is the integer 644.
+	ScalarCompatibilityDefault ScalarCompatibility = ""
+
+	// ScalarCompatibilityYAML1_1 interprets the scalars as YAML 1.1, like the
+	// Kubernetes apiserver and kubectl, and rewrites them so that both YAML 1.1 and
+	// YAML 1.2 parsers interpret them the same, e.g. `on` becomes `true` and `0644`
+	// becomes `420`.
+	ScalarCompatibilityYAML1_1 ScalarCompatibility = "yaml1.1"
+
+	// ScalarCompatibilityString interprets the scalars as strings, and quotes them
+	// so that both YAML 1.1 and YAML 1.2 parsers interpret them as strings.
+	ScalarCompatibilityString ScalarCompatibility = "string"
+)
+
+// ResolveAmbiguousScalars rewrites the plain scalar values of node which YAML 1.1
+// and YAML 1.2 parsers interpret differently, as configured by c.  Mapping keys
+// and scalars with an explicit tag or a quoted style are not changed.
+func ResolveAmbiguousScalars(node *RNode, c ScalarCompatibility) error {
+	switch c {
+	case ScalarCompatibilityDefault:
+		return nil
+	case ScalarCompatibilityYAML1_1, ScalarCompatibilityString:
+	default:
+		return errors.Errorf("unknown scalar compatibility %q", c)
+	}
+	if node.IsNil() {
+		return nil
+	}
+	return resolveAmbiguousScalars(node.YNode(), c)
+}
+
+func resolveAmbiguousScalars(n *Node, c ScalarCompatibility) error {
+	switch n.Kind {
+	case y1_2.DocumentNode, y1_2.SequenceNode:
+		for _, e := range n.Content {
+			if err := resolveAmbiguousScalars(e, c); err != nil {
+				return err
+			}
+		}
+	case y1_2.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			if err := resolveAmbiguousScalars(n.Content[i], c); err != nil {
+				return err
+			}
+		}
+	case y1_2.ScalarNode:
+		if n.Style != 0 {
+			// explicitly tagged or quoted scalars are not ambiguous
+			return nil
+		}
+		return resolveAmbiguousScalar(n, c)
+	}
+	return nil
+}
+
+// resolveAmbiguousScalar rewrites the plain scalar n if it is ambiguous.
+// The yaml 1.1 interpretation is the one of gopkg.in/yaml.v2, which is used by
+// the Kubernetes apiserver and kubectl.
+func resolveAmbiguousScalar(n *Node, c ScalarCompatibility) error {
+	var v1_1 interface{}
+	if err := y1_1.Unmarshal([]byte(n.Value), &v1_1); err != nil {
+		// not valid yaml 1.1, keep the yaml 1.2 interpretation
+		return nil
+	}
+	if reflect.DeepEqual(normalizeScalar(v1_1), normalizeScalar(resolveYAML1_2Core(n.Value))) {
+		return nil
+	}
+
+	if c == ScalarCompatibilityString {
+		n.Tag = NodeTagString
+		n.Style = DoubleQuotedStyle
+		return nil
+	}
+	switch v := v1_1.(type) {
+	case string:
+		n.Tag = NodeTagString
+		n.Style = DoubleQuotedStyle
+	case bool:
+		n.Tag = NodeTagBool
+		n.Value = strconv.FormatBool(v)
+	case int:
+		n.Tag = NodeTagInt
+		n.Value = strconv.Itoa(v)
+	case int64:
+		n.Tag = NodeTagInt
+		n.Value = strconv.FormatInt(v, 10)
+	case uint64:
+		n.Tag = NodeTagInt
+		n.Value = strconv.FormatUint(v, 10)
+	case float64:
+		n.Tag = NodeTagFloat
+		switch {
+		case math.IsNaN(v):
+			n.Value = ".nan"
+		case math.IsInf(v, 1):
+			n.Value = ".inf"
+		case math.IsInf(v, -1):
+			n.Value = "-.inf"
+		default:
+			n.Value = strconv.FormatFloat(v, 'g', -1, 64)
+			if !strings.ContainsAny(n.Value, ".e") {
+				// keep the value a float for yaml 1.2 parsers
+				n.Value += ".0"
+			}
+		}
+	default:
+		// e.g. timestamps, keep the value a string
+		n.Tag = NodeTagString
+		n.Style = DoubleQuotedStyle
+	}
+	return nil
+}
+
+var (
+	yaml1_2Null     = regexp.MustCompile(`^(~|null|Null|NULL|)$`)
+	yaml1_2Bool     = regexp.MustCompile(`^(true|True|TRUE|false|False|FALSE)$`)
+	yaml1_2Int      = regexp.MustCompile(`^([-+]?[0-9]+|0o[0-7]+|0x[0-9a-fA-F]+)$`)
+	yaml1_2Float    = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+	yaml1_2Infinity = regexp.MustCompile(`^[-+]?\.(inf|Inf|INF)$`)
+	yaml1_2NaN      = regexp.MustCompile(`^\.(nan|NaN|NAN)$`)
+)
+
+// resolveYAML1_2Core returns the value of a plain scalar following the rules of
+// the yaml 1.2 core schema, e.g. `0644` is the decimal integer 644 and `on` is a string.
+func resolveYAML1_2Core(value string) interface{} {
+	switch {
+	case yaml1_2Null.MatchString(value):
+		return nil
+	case yaml1_2Bool.MatchString(value):
+		return strings.ToLower(value) == "true"
+	case yaml1_2Int.MatchString(value):
+		base, digits := 10, value
+		switch {
+		case strings.HasPrefix(value, "0o"):
+			base, digits = 8, value[2:]
+		case strings.HasPrefix(value, "0x"):
+			base, digits = 16, value[2:]
+		}
+		if i, err := strconv.ParseInt(digits, base, 64); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(digits, base, 64); err == nil {
+			return u
+		}
+		return value
+	case yaml1_2Float.MatchString(value):
+		f, _ := strconv.ParseFloat(value, 64)
+		return f
+	case yaml1_2Infinity.MatchString(value):
+		if value[0] == '-' {
+			return math.Inf(-1)
+		}
+		return math.Inf(1)
+	case yaml1_2NaN.MatchString(value):
+		return math.NaN()
+	default:
+		return value
+	}
+}
+
+// normalizeScalar returns the numbers as float64, so that the same number decoded
+// as different Go types by the yaml 1.1 and yaml 1.2 parsers is equal, and NaN
+// as a string, since NaN is not equal to itself.
+func normalizeScalar(v interface{}) interface{} {
+	switch n := v.(type) {
+	case float64:
+		if math.IsNaN(n) {
+			return ".nan"
+		}
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	default:
+		return v
+	}
+}
@@ -135,3 +135,76 @@ var valueToTagMap = func() map[string]string {
 
 	return val
 }()
+
+func TestResolveAmbiguousScalars(t *testing.T) {
+	input := `mode: 0644
+enabled: on
+short: y
+count: 1_000
+octal: 0o644
+quoted: "0644"
+tagged: !!str yes
+plain: value
+number: 10
+float: .nan
+list:
+- off
+- 0755
+on: key
+`
+	testCases := []struct {
+		compatibility yaml.ScalarCompatibility
+		expected      string
+	}{
+		{
+			compatibility: yaml.ScalarCompatibilityDefault,
+			expected:      input,
+		},
+		{
+			compatibility: yaml.ScalarCompatibilityYAML1_1,
+			expected: `mode: 420
+enabled: true
+short: true
+count: 1000
+octal: 0o644
+quoted: "0644"
+tagged: !!str yes
+plain: value
+number: 10
+float: .nan
+list:
+- false
+- 493
+on: key
+`,
+		},
+		{
+			compatibility: yaml.ScalarCompatibilityString,
+			expected: `mode: "0644"
+enabled: "on"
+short: "y"
+count: "1_000"
+octal: 0o644
+quoted: "0644"
+tagged: !!str yes
+plain: value
+number: 10
+float: .nan
+list:
+- "off"
+- "0755"
+on: key
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.compatibility), func(t *testing.T) {
+			node := yaml.MustParse(input)
+			assert.NoError(t, yaml.ResolveAmbiguousScalars(node, tc.compatibility))
+			assert.Equal(t, tc.expected, node.MustString())
+		})
+	}
+
+	assert.EqualError(t, yaml.ResolveAmbiguousScalars(yaml.MustParse(input), "yaml1.3"),
+		`unknown scalar compatibility "yaml1.3"`)
+}
@@ -155,7 +155,8 @@ func GetElementByIndex(index int) ElementIndexer {
 }
 
 // ElementIndexer picks the element with a specified index. Index starts from
-// 0 to len(list) - 1. a hyphen ("-") means the last index.
+// 0 to len(list) - 1. a hyphen ("-") means the last index.  A negative Index
+// counts from the end of the list, -1 being the last element.
 type ElementIndexer struct {
 	Index int
 }
@@ -168,9 +169,9 @@ func (i ElementIndexer) Filter(rn *RNode) (*RNode, error) {
 		return nil, err
 	}
 	if i.Index < 0 {
-		return elems[len(elems)-1], nil
+		i.Index += len(elems)
 	}
-	if i.Index >= len(elems) {
+	if i.Index < 0 || i.Index >= len(elems) {
 		return nil, nil
 	}
 	return elems[i.Index], nil
@@ -474,14 +475,16 @@ type PathGetter struct {
 	// Each path part may be one of:
 	// * FieldMatcher -- e.g. "spec"
 	// * Map Key -- e.g. "app.k8s.io/version"
-	// * List Entry -- e.g. "[name=nginx]" or "[=-jar]" or "0" or "-"
+	// * List Entry -- e.g. "[name=nginx]" or "[=-jar]" or "0" or "-" or "[-1]"
+	// * List Wildcard -- "[*]", only supported by FilterAll
 	//
 	// Map Keys and Fields are equivalent.
 	// See FieldMatcher for more on Fields and Map Keys.
 	//
 	// List Entries can be specified as map entry to match [fieldName=fieldValue]
 	// or a positional index like 0 to get the element. - (unquoted hyphen) is
-	// special and means the last element.
+	// special and means the last element.  A positional index in brackets may be
+	// negative to count from the end of the list, e.g. [-1] is the last element.
 	//
 	// See Elem for more on List Entries.
 	//
@@ -505,20 +508,56 @@ type PathGetter struct {
 }
 
 func (l PathGetter) Filter(rn *RNode) (*RNode, error) {
-	var err error
-	fieldPath := append([]string{}, rn.FieldPath()...)
-	match := rn
+	l.Path = cleanPath(l.Path)
+	for _, part := range l.Path {
+		if isWildcardIndex(part) {
+			return nil, errors.Errorf(
+				"wildcard %s may match multiple nodes, use PathGetter.FilterAll", part)
+		}
+	}
+	matches, err := l.filterAll(rn, append([]string{}, rn.FieldPath()...), 0)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+	return matches[0], nil
+}
 
-	// iterate over path until encountering an error or missing value
+// FilterAll returns all the nodes matching the Path.  Unlike Filter, the Path may
+// contain "[*]" wildcards matching every element of a list, e.g.
+// spec.template.spec.containers.[*].image returns the image of each container.
+// Paths which don't exist under some of the wildcard elements are skipped, unless
+// Create is set.
+func (l PathGetter) FilterAll(rn *RNode) ([]*RNode, error) {
 	l.Path = cleanPath(l.Path)
-	for i := range l.Path {
+	return l.filterAll(rn, append([]string{}, rn.FieldPath()...), 0)
+}
+
+// filterAll returns the nodes matching the Path from its start'th part under match.
+func (l PathGetter) filterAll(match *RNode, fieldPath []string, start int) ([]*RNode, error) {
+	// iterate over path until encountering an error or missing value
+	for i := start; i < len(l.Path); i++ {
 		var part, nextPart string
 		part = l.Path[i]
 		if len(l.Path) > i+1 {
 			nextPart = l.Path[i+1]
 		}
-		var fltr Filter
-		fltr, err = l.getFilter(part, nextPart, &fieldPath)
+		if isWildcardIndex(part) {
+			elems, err := match.Elements()
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			var matches []*RNode
+			for _, elem := range elems {
+				elem.AppendToFieldPath(fieldPath...)
+				elemMatches, err := l.filterAll(elem, append([]string{}, fieldPath...), i+1)
+				if err != nil {
+					return nil, err
+				}
+				matches = append(matches, elemMatches...)
+			}
+			return matches, nil
+		}
+		fltr, err := l.getFilter(part, nextPart, &fieldPath)
 		if err != nil {
 			return nil, err
 		}
@@ -528,7 +567,7 @@ func (l PathGetter) Filter(rn *RNode) (*RNode, error) {
 		}
 		match.AppendToFieldPath(fieldPath...)
 	}
-	return match, nil
+	return []*RNode{match}, nil
 }
 
 func (l PathGetter) getFilter(part, nextPart string, fieldPath *[]string) (Filter, error) {
@@ -546,6 +585,10 @@ func (l PathGetter) getFilter(part, nextPart string, fieldPath *[]string) (Filte
 	case part == "*":
 		// PathGetter is not support for wildcard matching
 		return nil, errors.Errorf("wildcard is not supported in PathGetter")
+	case isIndex(part):
+		// part is a number surrounded by brackets, which may be negative
+		idx, _ = strconv.Atoi(part[1 : len(part)-1])
+		return GetElementByIndex(idx), nil
 	case IsListIndex(part):
 		// part is surrounded by brackets
 		return l.elemFilter(part)
@@ -868,6 +911,23 @@ func IsWildcard(p string) bool {
 	return p == "*"
 }
 
+// isIndex returns true if p is a positional index surrounded by brackets.
+// e.g. [0]
+// e.g. [-1]
+func isIndex(p string) bool {
+	if !IsListIndex(p) {
+		return false
+	}
+	_, err := strconv.Atoi(p[1 : len(p)-1])
+	return err == nil
+}
+
+// isWildcardIndex returns true if p matches every element of a list.
+// e.g. [*]
+func isWildcardIndex(p string) bool {
+	return p == "[*]"
+}
+
 // SplitIndexNameValue splits a lookup part Val index into the field name
 // and field value to match.
 // e.g. splits [name=nginx] into (name, nginx)
@@ -1304,3 +1304,83 @@ func assertNoErrorString(t *testing.T) func(string, error) string {
 		return s
 	}
 }
+
+func TestPathGetter_negativeIndex(t *testing.T) {
+	node := MustParse(`
+a:
+  b:
+  - c: d
+  - c: e
+  - c: f
+`)
+	rn, err := node.Pipe(Lookup("a", "b", "[-1]", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, "f\n", assertNoErrorString(t)(rn.String()))
+
+	rn, err = node.Pipe(Lookup("a", "b", "[-3]", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, "d\n", assertNoErrorString(t)(rn.String()))
+
+	rn, err = node.Pipe(Lookup("a", "b", "[1]", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, "e\n", assertNoErrorString(t)(rn.String()))
+
+	rn, err = node.Pipe(Lookup("a", "b", "[-4]"))
+	require.NoError(t, err)
+	assert.Nil(t, rn)
+
+	rn, err = NewListRNode().Pipe(Lookup("[-1]"))
+	require.NoError(t, err)
+	assert.Nil(t, rn)
+}
+
+func TestPathGetter_FilterAll(t *testing.T) {
+	node := MustParse(`
+spec:
+  containers:
+  - name: a
+    image: a:1
+    ports:
+    - containerPort: 80
+    - containerPort: 8080
+  - name: b
+  - name: c
+    image: c:1
+`)
+	matches, err := PathGetter{Path: []string{"spec", "containers", "[*]", "image"}}.FilterAll(node)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "a:1", matches[0].YNode().Value)
+	assert.Equal(t, "c:1", matches[1].YNode().Value)
+	assert.Equal(t, []string{"spec", "containers", "image"}, matches[1].FieldPath())
+
+	matches, err = PathGetter{Path: []string{"spec", "containers", "[*]", "ports", "[-1]", "containerPort"}}.FilterAll(node)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "8080", matches[0].YNode().Value)
+
+	matches, err = PathGetter{Path: []string{"spec", "containers", "[*]", "imagePullPolicy"},
+		Create: yaml.ScalarNode}.FilterAll(node)
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	for _, m := range matches {
+		m.YNode().Value = "Always"
+	}
+	assert.Equal(t, `spec:
+  containers:
+  - name: a
+    image: a:1
+    ports:
+    - containerPort: 80
+    - containerPort: 8080
+    imagePullPolicy: Always
+  - name: b
+    imagePullPolicy: Always
+  - name: c
+    image: c:1
+    imagePullPolicy: Always
+`, assertNoErrorString(t)(node.String()))
+
+	_, err = node.Pipe(Lookup("spec", "containers", "[*]", "image"))
+	assert.EqualError(t, err, "wildcard [*] may match multiple nodes, use PathGetter.FilterAll")
+}
@@ -482,6 +482,120 @@ items:
 		expected: `
 kind: Deployment
 items: []
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+
+	//
+	// Test Case
+	//
+	{description: `replace k8s deployment containers missing from dest -- $patch directive`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - $patch: replace
+      - name: foo2
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec: {}
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: foo2
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+
+	//
+	// Test Case
+	//
+	{description: `remove k8s deployment containers missing from dest -- $patch directive`,
+		source: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - $patch: delete
+`,
+		dest: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec: {}
+`,
+		expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec: {}
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+
+	//
+	// Test Case
+	//
+	{description: `replace non-associative list -- $patch directive`,
+		source: `
+kind: Deployment
+items:
+- $patch: replace
+- a: 1
+`,
+		dest: `
+kind: Deployment
+items:
+- b: 2
+`,
+		expected: `
+kind: Deployment
+items:
+- a: 1
+`,
+		mergeOptions: yaml.MergeOptions{
+			ListIncreaseDirection: yaml.MergeOptionsListAppend,
+		},
+	},
+
+	//
+	// Test Case
+	//
+	{description: `remove non-associative list -- $patch directive`,
+		source: `
+kind: Deployment
+items:
+- $patch: delete
+`,
+		dest: `
+kind: Deployment
+items:
+- b: 2
+`,
+		expected: `
+kind: Deployment
 `,
 		mergeOptions: yaml.MergeOptions{
 			ListIncreaseDirection: yaml.MergeOptionsListAppend,
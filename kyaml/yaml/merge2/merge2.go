@@ -114,10 +114,13 @@ func (m Merger) VisitList(nodes walk.Sources, s *openapi.ResourceSchema, kind wa
 	if err := m.SetStyle(nodes); err != nil {
 		return nil, err
 	}
+	if kind == walk.AppendList {
+		return m.visitAppendList(nodes)
+	}
 	if kind == walk.NonAssociateList {
 		// Override value
 		if nodes.Origin() != nil {
-			return nodes.Origin(), nil
+			return listDirectiveResult(nodes.Origin())
 		}
 		// Keep
 		return nodes.Dest(), nil
@@ -125,7 +128,7 @@ func (m Merger) VisitList(nodes walk.Sources, s *openapi.ResourceSchema, kind wa
 
 	// Add
 	if yaml.IsMissingOrNull(nodes.Dest()) {
-		return nodes.Origin(), nil
+		return listDirectiveResult(nodes.Origin())
 	}
 	// Clear
 	if nodes.Origin().IsTaggedNull() {
@@ -147,6 +150,73 @@ func (m Merger) VisitList(nodes walk.Sources, s *openapi.ResourceSchema, kind wa
 	}
 }
 
+// visitAppendList keeps the elements of the dest list, and appends the elements
+// of the origin list which dest doesn't contain, compared by value.  The $patch
+// directives of origin are honored like those of associative lists.
+func (m Merger) visitAppendList(nodes walk.Sources) (*yaml.RNode, error) {
+	// Add
+	if yaml.IsMissingOrNull(nodes.Dest()) {
+		return listDirectiveResult(nodes.Origin())
+	}
+	// Clear
+	if nodes.Origin().IsTaggedNull() {
+		return walk.ClearNode, nil
+	}
+
+	ps, err := determineSmpDirective(nodes.Origin())
+	if err != nil {
+		return nil, err
+	}
+	switch ps {
+	case smpDelete:
+		return walk.ClearNode, nil
+	case smpReplace:
+		return nodes.Origin(), nil
+	}
+	// Keep
+	if nodes.Origin() == nil {
+		return nodes.Dest(), nil
+	}
+
+	// Append
+	dest := map[string]bool{}
+	for _, yn := range nodes.Dest().YNode().Content {
+		s, err := yaml.NewRNode(yn).String()
+		if err != nil {
+			return nil, err
+		}
+		dest[s] = true
+	}
+	for _, yn := range nodes.Origin().YNode().Content {
+		s, err := yaml.NewRNode(yn).String()
+		if err != nil {
+			return nil, err
+		}
+		if !dest[s] {
+			dest[s] = true
+			nodes.Dest().YNode().Content = append(nodes.Dest().YNode().Content, yn)
+		}
+	}
+	return nodes.Dest(), nil
+}
+
+// listDirectiveResult returns the result of setting a list to origin, honoring
+// the $patch directive of origin.  The list is cleared for the delete directive,
+// otherwise it is set to origin without the directive.
+func listDirectiveResult(origin *yaml.RNode) (*yaml.RNode, error) {
+	if origin.IsNil() || origin.YNode().Kind != yaml.SequenceNode {
+		return origin, nil
+	}
+	ps, err := determineSmpDirective(origin)
+	if err != nil {
+		return nil, err
+	}
+	if ps == smpDelete {
+		return walk.ClearNode, nil
+	}
+	return origin, nil
+}
+
 func (m Merger) SetStyle(sources walk.Sources) error {
 	source := sources.Origin()
 	dest := sources.Dest()
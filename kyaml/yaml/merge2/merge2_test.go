@@ -9,9 +9,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 	. "sigs.k8s.io/kustomize/kyaml/yaml/merge2"
+	"sigs.k8s.io/kustomize/kyaml/yaml/walk"
 )
 
 var testCases = [][]testCase{scalarTestCases, listTestCases, elementTestCases, mapTestCases}
@@ -51,3 +54,89 @@ type testCase struct {
 	infer        bool
 	mergeOptions yaml.MergeOptions
 }
+
+func TestMerge_listMapKeys(t *testing.T) {
+	openapi.ResetOpenAPI()
+	t.Cleanup(openapi.ResetOpenAPI)
+	require.NoError(t, openapi.AddSchema([]byte(`{"definitions": {"io.example.v1.Gateway": {
+  "properties": {"spec": {"properties": {"listeners": {
+    "type": "array",
+    "x-kubernetes-list-type": "map",
+    "x-kubernetes-list-map-keys": ["port", "protocol"],
+    "items": {"type": "object"}
+  }}}},
+  "x-kubernetes-group-version-kind": [{"group": "example.io", "kind": "Gateway", "version": "v1"}]
+}}}`)))
+
+	actual, err := MergeStrings(`
+apiVersion: example.io/v1
+kind: Gateway
+spec:
+  listeners:
+  - port: 53
+    protocol: UDP
+    tls: true
+`, `
+apiVersion: example.io/v1
+kind: Gateway
+spec:
+  listeners:
+  - port: 53
+    protocol: TCP
+    name: dns-tcp
+  - port: 53
+    protocol: UDP
+    name: dns-udp
+`, false, yaml.MergeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: example.io/v1
+kind: Gateway
+spec:
+  listeners:
+  - port: 53
+    protocol: TCP
+    name: dns-tcp
+  - port: 53
+    protocol: UDP
+    name: dns-udp
+    tls: true
+`), strings.TrimSpace(actual))
+}
+
+func TestMerge_appendList(t *testing.T) {
+	appendArgs := func(path []string) (walk.ListStrategy, []string) {
+		if strings.Join(path, ".") == "spec.args" {
+			return walk.ListStrategyAppend, nil
+		}
+		return walk.ListStrategyDefault, nil
+	}
+	for _, tc := range []struct {
+		description string
+		source      string
+		expected    string
+	}{
+		{description: "the elements missing from dest are appended",
+			source:   "spec:\n  args: [b, c]\n",
+			expected: "spec:\n  args: [a, b, c]\n"},
+		{description: "a missing list keeps dest",
+			source:   "spec: {}\n",
+			expected: "spec:\n  args: [a, b]\n"},
+		{description: "the replace directive replaces dest",
+			source:   "spec:\n  args: [c, {$patch: replace}]\n",
+			expected: "spec:\n  args: [c]\n"},
+		{description: "the delete directive clears dest",
+			source:   "spec:\n  args: [{$patch: delete}]\n",
+			expected: "spec: {}\n"},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := walk.Walker{
+				Sources:      []*yaml.RNode{yaml.MustParse("spec:\n  args: [a, b]\n"), yaml.MustParse(tc.source)},
+				Visitor:      Merger{},
+				ListStrategy: appendArgs,
+			}.Walk()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result.MustString())
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package merge2
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/walk"
+)
+
+// SetPath returns a PathSetter which sets value at path.
+//
+// e.g. set the env var FOO on the container app, creating the container and
+// the env var if they are missing:
+//
+//	SetPath(yaml.NewStringRNode("bar"),
+//	  "spec", "template", "spec", "containers", "[name=app]", "env", "[name=FOO]", "value")
+func SetPath(value *yaml.RNode, path ...string) PathSetter {
+	return PathSetter{Path: path, Value: value}
+}
+
+// UpsertElement returns a PathSetter which inserts element into the associative
+// list at path, or merges it into the existing element with the same merge key values.
+//
+// e.g. add or update the container app:
+//
+//	UpsertElement(container, "spec", "template", "spec", "containers")
+func UpsertElement(element *yaml.RNode, path ...string) PathSetter {
+	return PathSetter{Path: path, Element: element}
+}
+
+// PathSetter sets a value at a path in a Resource, merging it into the existing
+// fields and associative list elements according to the OpenAPI schema of the Resource.
+// Associative list elements are matched by the merge keys of the list, so the same
+// element is updated regardless of its position in the list, and missing fields and
+// list elements along the path are created.
+type PathSetter struct {
+	// Path is a slice of parts leading to the field to set.
+	// Each path part may be one of:
+	// * Field -- e.g. "spec"
+	// * Associative List Entry -- e.g. "[name=nginx]" or "[containerPort=80,protocol=TCP]"
+	// * Primitive List Entry -- e.g. "[=-jar]"
+	//
+	// List entries must be addressed by the merge keys of the list.  Entries of lists
+	// which are not associative according to the schema and cannot be inferred to be
+	// associative replace the whole list.
+	Path []string
+
+	// Value is the value to set at Path.
+	Value *yaml.RNode
+
+	// Element if set is inserted into, or merged with the matching element of, the
+	// associative list at Path.
+	Element *yaml.RNode
+
+	// Schema is the schema of the Resource.  Defaults to the schema for the
+	// Resource type.
+	Schema *openapi.ResourceSchema
+
+	// MergeOptions configures where new list elements are inserted.
+	MergeOptions yaml.MergeOptions
+}
+
+var _ yaml.Filter = PathSetter{}
+
+// Filter sets the value and returns the node at Path.
+func (s PathSetter) Filter(rn *yaml.RNode) (*yaml.RNode, error) {
+	value := s.Value
+	path := s.Path
+	if s.Element != nil {
+		value = yaml.NewListRNode()
+		if err := value.PipeE(yaml.Append(s.Element.YNode())); err != nil {
+			return nil, errors.Wrap(err)
+		}
+	}
+	if value == nil {
+		return nil, errors.Errorf("must specify a value to set")
+	}
+	value = value.Copy()
+	if s.Element == nil {
+		// keep the comments of a scalar value being replaced
+		existing, _ := lookupPath(rn, path)
+		if existing != nil && existing.YNode().Kind == yaml.ScalarNode &&
+			value.YNode().Kind == yaml.ScalarNode {
+			yn := value.YNode()
+			if yn.HeadComment == "" && yn.LineComment == "" && yn.FootComment == "" {
+				yn.HeadComment = existing.YNode().HeadComment
+				yn.LineComment = existing.YNode().LineComment
+				yn.FootComment = existing.YNode().FootComment
+			}
+		}
+	}
+	patch, err := patchForPath(path, value)
+	if err != nil {
+		return nil, err
+	}
+	result, err := walk.Walker{
+		Sources:               []*yaml.RNode{rn, patch},
+		Schema:                s.Schema,
+		Visitor:               Merger{},
+		InferAssociativeLists: true,
+		MergeOptions:          s.MergeOptions,
+	}.Walk()
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, errors.Errorf("setting %s removed the resource", strings.Join(path, "."))
+	}
+	if result.YNode() != rn.YNode() {
+		rn.SetYNode(result.YNode())
+	}
+	if s.Element != nil {
+		keys, values, err := elementKeys(rn, path, s.Element, s.Schema)
+		if err != nil || len(keys) == 0 {
+			return nil, err
+		}
+		path = append(append([]string{}, path...), "["+joinKeys(keys, values)+"]")
+	}
+	return lookupPath(rn, path)
+}
+
+// patchForPath returns a sparse document containing value at path.
+func patchForPath(path []string, value *yaml.RNode) (*yaml.RNode, error) {
+	current := value
+	for i := len(path) - 1; i >= 0; i-- {
+		part := path[i]
+		switch {
+		case yaml.IsListIndex(part):
+			keys, values, err := splitListEntry(part)
+			if err != nil {
+				return nil, err
+			}
+			elem := current
+			if len(keys) == 1 && keys[0] == "" {
+				// primitive element -- the value is the element
+				if i != len(path)-1 {
+					return nil, errors.Errorf("primitive list entry %s must be the last path part", part)
+				}
+				elem = newKeyValue(values[0])
+			} else {
+				// write the merge keys first so that new elements read naturally
+				elem = yaml.NewMapRNode(nil)
+				for j := range keys {
+					if err := elem.PipeE(yaml.SetField(keys[j], newKeyValue(values[j]))); err != nil {
+						return nil, errors.WrapPrefixf(err, "setting %s", part)
+					}
+				}
+				if err := current.VisitFields(func(node *yaml.MapNode) error {
+					return elem.PipeE(yaml.SetField(node.Key.YNode().Value, node.Value))
+				}); err != nil {
+					return nil, errors.Wrap(err)
+				}
+			}
+			current = yaml.NewListRNode()
+			if err := current.PipeE(yaml.Append(elem.YNode())); err != nil {
+				return nil, errors.Wrap(err)
+			}
+		case yaml.IsIdxNumber(part) || part == "-" || yaml.IsWildcard(part):
+			return nil, errors.Errorf(
+				"list entries must be addressed by their merge keys, not %q", part)
+		default:
+			m := yaml.NewMapRNode(nil)
+			if err := m.PipeE(yaml.SetField(part, current)); err != nil {
+				return nil, errors.Wrap(err)
+			}
+			current = m
+		}
+	}
+	return current, nil
+}
+
+// elementKeys returns the merge keys of the list at path and their values in element.
+func elementKeys(rn *yaml.RNode, path []string, element *yaml.RNode, s *openapi.ResourceSchema) ([]string, []string, error) {
+	var keys []string
+	if s == nil {
+		m, _ := rn.GetMeta()
+		s = openapi.SchemaForResourceType(m.TypeMeta)
+	}
+	if s != nil {
+		if ls := s.Lookup(schemaPath(path)...); ls != nil {
+			_, keys = ls.PatchStrategyAndKeyList()
+		}
+	}
+	if len(keys) == 0 {
+		if key := element.GetAssociativeKey(); key != "" {
+			keys = []string{key}
+		}
+	}
+	// only the first merge key is required, lists keyed by multiple keys match
+	// elements missing the other keys by the keys they have, as merges do
+	var presentKeys, values []string
+	for i, k := range keys {
+		v := element.Field(k)
+		if v.IsNilOrEmpty() {
+			if i == 0 {
+				return nil, nil, errors.Errorf("element is missing merge key %s", k)
+			}
+			continue
+		}
+		presentKeys = append(presentKeys, k)
+		values = append(values, v.Value.YNode().Value)
+	}
+	return presentKeys, values, nil
+}
+
+// lookupPath returns the node at path, addressing list entries by all of their keys.
+func lookupPath(rn *yaml.RNode, path []string) (*yaml.RNode, error) {
+	match := rn
+	for _, part := range path {
+		var f yaml.Filter = yaml.Get(part)
+		if yaml.IsListIndex(part) {
+			keys, values, err := splitListEntry(part)
+			if err != nil {
+				return nil, err
+			}
+			f = yaml.MatchElementList(keys, values)
+		}
+		var err error
+		match, err = match.Pipe(f)
+		if yaml.IsMissingOrError(match, err) {
+			return nil, err
+		}
+	}
+	return match, nil
+}
+
+// schemaPath converts a PathSetter path to a ResourceSchema lookup path.
+func schemaPath(path []string) []string {
+	var result []string
+	for _, p := range path {
+		if yaml.IsListIndex(p) {
+			result = append(result, openapi.Elements)
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// splitListEntry splits a list entry path part into the field names and values to match.
+// e.g. splits [name=nginx] into ([name], [nginx])
+// e.g. splits [containerPort=80,protocol=TCP] into ([containerPort protocol], [80 TCP])
+// e.g. splits [=-jar] into ([""], [-jar])
+func splitListEntry(part string) ([]string, []string, error) {
+	name, value, err := yaml.SplitIndexNameValue(part)
+	if err != nil {
+		return nil, nil, errors.Wrap(err)
+	}
+	if name == "" {
+		return []string{""}, []string{value}, nil
+	}
+	var keys, values []string
+	for _, kv := range strings.Split(strings.Trim(part, "[]"), ",") {
+		name, value, err := yaml.SplitIndexNameValue("[" + kv + "]")
+		if err != nil || name == "" {
+			return nil, nil, errors.Errorf("invalid list entry %s", part)
+		}
+		keys = append(keys, name)
+		values = append(values, value)
+	}
+	return keys, values, nil
+}
+
+// newKeyValue returns a scalar for a merge key value parsed from a path, tagged
+// with its resolved type so that e.g. ports are not written as strings.
+func newKeyValue(value string) *yaml.RNode {
+	yn := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	yn.Tag = yn.ShortTag()
+	return yaml.NewRNode(yn)
+}
+
+func joinKeys(keys, values []string) string {
+	var parts []string
+	for i := range keys {
+		parts = append(parts, keys[i]+"="+values[i])
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,242 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package merge2_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	. "sigs.k8s.io/kustomize/kyaml/yaml/merge2"
+)
+
+func TestPathSetter(t *testing.T) {
+	testCases := []struct {
+		description string
+		input       string
+		setter      PathSetter
+		expected    string
+		result      string
+		err         string
+	}{
+		{
+			description: "set env var on existing container",
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: proxy
+      - name: app
+        image: app
+        env:
+        - name: BAR
+          value: bar
+`,
+			setter: SetPath(yaml.NewStringRNode("foo"),
+				"spec", "template", "spec", "containers", "[name=app]", "env", "[name=FOO]", "value"),
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: proxy
+      - name: app
+        image: app
+        env:
+        - name: BAR
+          value: bar
+        - name: FOO
+          value: foo
+`,
+			result: "foo",
+		},
+		{
+			description: "update env var, creating nothing",
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        env:
+        - name: FOO
+          value: old # comment
+`,
+			setter: SetPath(yaml.NewStringRNode("new"),
+				"spec", "template", "spec", "containers", "[name=app]", "env", "[name=FOO]", "value"),
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        env:
+        - name: FOO
+          value: new # comment
+`,
+			result: "new",
+		},
+		{
+			description: "create container and env var",
+			input: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+`,
+			setter: SetPath(yaml.NewStringRNode("foo"),
+				"spec", "template", "spec", "containers", "[name=app]", "env", "[name=FOO]", "value"),
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        env:
+        - name: FOO
+          value: foo
+`,
+			result: "foo",
+		},
+		{
+			description: "upsert element merges by schema merge key",
+			input: `
+apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: app
+    image: app:v1
+    ports:
+    - containerPort: 80
+`,
+			setter: UpsertElement(yaml.MustParse(`
+image: app:v2
+name: app
+ports:
+- containerPort: 443
+`), "spec", "containers"),
+			expected: `
+apiVersion: v1
+kind: Pod
+spec:
+  containers:
+  - name: app
+    image: app:v2
+    ports:
+    - containerPort: 80
+    - containerPort: 443
+`,
+			result: "app",
+		},
+		{
+			description: "upsert element of list keyed by multiple keys",
+			input: `
+apiVersion: v1
+kind: Service
+spec:
+  ports:
+  - port: 53
+    protocol: TCP
+    name: dns-tcp
+`,
+			setter: SetPath(yaml.NewStringRNode("dns-udp"),
+				"spec", "ports", "[port=53,protocol=UDP]", "name"),
+			expected: `
+apiVersion: v1
+kind: Service
+spec:
+  ports:
+  - port: 53
+    protocol: TCP
+    name: dns-tcp
+  - port: 53
+    protocol: UDP
+    name: dns-udp
+`,
+			result: "dns-udp",
+		},
+		{
+			description: "upsert element of list keyed by multiple keys missing a key",
+			input: `
+apiVersion: v1
+kind: Service
+spec:
+  ports:
+  - port: 53
+    name: dns
+`,
+			setter: UpsertElement(yaml.MustParse(`
+port: 53
+targetPort: 5353
+`), "spec", "ports"),
+			expected: `
+apiVersion: v1
+kind: Service
+spec:
+  ports:
+  - port: 53
+    name: dns
+    targetPort: 5353
+`,
+			result: "dns",
+		},
+		{
+			description: "indexes are rejected",
+			input: `
+apiVersion: v1
+kind: Pod
+`,
+			setter: SetPath(yaml.NewStringRNode("foo"), "spec", "containers", "0", "image"),
+			err:    `list entries must be addressed by their merge keys, not "0"`,
+		},
+		{
+			description: "upsert element missing the merge key",
+			input: `
+apiVersion: v1
+kind: Pod
+`,
+			setter: UpsertElement(yaml.MustParse(`image: foo`), "spec", "containers"),
+			err:    "element is missing merge key name",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.description, func(t *testing.T) {
+			rn := yaml.MustParse(tc.input)
+			result, err := rn.Pipe(tc.setter)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tc.expected), strings.TrimSpace(rn.MustString()))
+			require.NotNil(t, result)
+			if result.YNode().Kind == yaml.ScalarNode {
+				assert.Equal(t, tc.result, result.YNode().Value)
+			} else {
+				assert.Equal(t, tc.result, result.Field("name").Value.YNode().Value)
+			}
+		})
+	}
+}
@@ -19,6 +19,65 @@ func Merge(dest, original, update *yaml.RNode) (*yaml.RNode, error) {
 		Sources:            []*yaml.RNode{dest, original, update}}.Walk()
 }
 
+// ListMergeStrategy configures how the list at Path is merged, independent of
+// the strategy specified by the openapi schema.
+type ListMergeStrategy struct {
+	// Path is the field path of the list, e.g. [spec, template, spec, containers].
+	// List elements are not part of the path, and "*" matches any field.
+	Path []string
+
+	// Strategy is the strategy used to merge the list.
+	Strategy walk.ListStrategy
+
+	// MergeKeys are the fields identifying the elements of an associative list.
+	// If empty, lists of scalars are merged as sets and the merge key of other
+	// lists is inferred from their elements.
+	MergeKeys []string
+}
+
+// Options configures a merge.
+type Options struct {
+	// InferAssociativeLists if set to true will infer the merge strategy of lists
+	// without a schema from the fields of their elements.
+	InferAssociativeLists bool
+
+	// ListStrategies override the merge strategy of the lists at their paths.
+	// The first matching strategy is used.
+	ListStrategies []ListMergeStrategy
+}
+
+// MergeWithOptions merges the changes between original and update into dest.
+func MergeWithOptions(dest, original, update *yaml.RNode, opts Options) (*yaml.RNode, error) {
+	return walk.Walker{
+		Visitor:               Visitor{},
+		VisitKeysAsScalars:    true,
+		InferAssociativeLists: opts.InferAssociativeLists,
+		ListStrategy:          opts.listStrategy,
+		Sources:               []*yaml.RNode{dest, original, update}}.Walk()
+}
+
+// listStrategy returns the first strategy configured for path.
+func (opts Options) listStrategy(path []string) (walk.ListStrategy, []string) {
+	for _, s := range opts.ListStrategies {
+		if pathMatches(s.Path, path) {
+			return s.Strategy, s.MergeKeys
+		}
+	}
+	return walk.ListStrategyDefault, nil
+}
+
+func pathMatches(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i := range pattern {
+		if pattern[i] != "*" && pattern[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func MergeStrings(dest, original, update string, infer bool) (string, error) {
 	srcOriginal, err := yaml.Parse(original)
 	if err != nil {
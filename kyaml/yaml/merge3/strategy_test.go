@@ -0,0 +1,262 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package merge3_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	. "sigs.k8s.io/kustomize/kyaml/yaml/merge3"
+	"sigs.k8s.io/kustomize/kyaml/yaml/walk"
+)
+
+func TestMergeWithOptions_listStrategies(t *testing.T) {
+	testCases := []struct {
+		description string
+		origin      string
+		update      string
+		local       string
+		expected    string
+		strategies  []ListMergeStrategy
+	}{
+		{
+			description: "append list",
+			origin: `
+args:
+- a
+- b`,
+			update: `
+args:
+- b
+- c`,
+			local: `
+args:
+- a
+- b
+- x`,
+			expected: `
+args:
+- b
+- x
+- c`,
+			strategies: []ListMergeStrategy{{Path: []string{"args"}, Strategy: walk.ListStrategyAppend}},
+		},
+		{
+			description: "associative list with configured merge key",
+			origin: `
+items:
+- id: a
+  value: 1
+- id: b
+  value: 2`,
+			update: `
+items:
+- id: a
+  value: 10
+- id: b
+  value: 2`,
+			local: `
+items:
+- id: b
+  value: 2
+  local: true
+- id: a
+  value: 1`,
+			expected: `
+items:
+- id: b
+  value: 2
+  local: true
+- id: a
+  value: 10`,
+			strategies: []ListMergeStrategy{
+				{Path: []string{"items"}, Strategy: walk.ListStrategyAssociative, MergeKeys: []string{"id"}},
+			},
+		},
+		{
+			description: "associative list of scalars",
+			origin: `
+finalizers:
+- a`,
+			update: `
+finalizers:
+- a
+- b`,
+			local: `
+finalizers:
+- x
+- a`,
+			expected: `
+finalizers:
+- x
+- a
+- b`,
+			strategies: []ListMergeStrategy{
+				{Path: []string{"finalizers"}, Strategy: walk.ListStrategyAssociative},
+			},
+		},
+		{
+			description: "replace list which is associative in the schema",
+			origin: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1`,
+			update: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v2`,
+			local: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v1
+      - name: sidecar
+        image: proxy`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: app:v2`,
+			strategies: []ListMergeStrategy{
+				{Path: []string{"spec", "template", "spec", "containers"}, Strategy: walk.ListStrategyReplace},
+			},
+		},
+		{
+			description: "wildcard path matches lists in associative list elements",
+			origin: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        args: [a]`,
+			update: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        args: [a, b]`,
+			local: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        args: [x, a]`,
+			expected: `
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        args: [x, a, b]`,
+			strategies: []ListMergeStrategy{
+				{Path: []string{"spec", "*", "spec", "containers", "args"}, Strategy: walk.ListStrategyAppend},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.description, func(t *testing.T) {
+			actual, err := MergeWithOptions(
+				yaml.MustParse(tc.local), yaml.MustParse(tc.origin), yaml.MustParse(tc.update),
+				Options{ListStrategies: tc.strategies})
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tc.expected), strings.TrimSpace(actual.MustString()))
+		})
+	}
+}
+
+func TestMerge_listMapKeys(t *testing.T) {
+	openapi.ResetOpenAPI()
+	t.Cleanup(openapi.ResetOpenAPI)
+	require.NoError(t, openapi.AddSchema([]byte(`{"definitions": {"io.example.v1.Gateway": {
+  "properties": {"spec": {"properties": {"listeners": {
+    "type": "array",
+    "x-kubernetes-list-type": "map",
+    "x-kubernetes-list-map-keys": ["port", "protocol"],
+    "items": {"type": "object"}
+  }}}},
+  "x-kubernetes-group-version-kind": [{"group": "example.io", "kind": "Gateway", "version": "v1"}]
+}}}`)))
+
+	origin := `
+apiVersion: example.io/v1
+kind: Gateway
+spec:
+  listeners:
+  - port: 53
+    protocol: TCP
+  - port: 53
+    protocol: UDP
+`
+	update := `
+apiVersion: example.io/v1
+kind: Gateway
+spec:
+  listeners:
+  - port: 53
+    protocol: TCP
+  - port: 53
+    protocol: UDP
+    name: dns
+`
+	local := `
+apiVersion: example.io/v1
+kind: Gateway
+spec:
+  listeners:
+  - port: 53
+    protocol: UDP
+    tls: true
+  - port: 53
+    protocol: TCP
+`
+	actual, err := MergeStrings(local, origin, update, false)
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(`
+apiVersion: example.io/v1
+kind: Gateway
+spec:
+  listeners:
+  - port: 53
+    protocol: UDP
+    tls: true
+    name: dns
+  - port: 53
+    protocol: TCP
+`), strings.TrimSpace(actual))
+}
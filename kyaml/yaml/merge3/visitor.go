@@ -115,10 +115,87 @@ func (m Visitor) visitNAList(nodes walk.Sources) (*yaml.RNode, error) {
 	return nodes.Dest(), nil
 }
 
+// visitAppendList keeps the elements of dest, removes the elements which were
+// removed in update and appends the elements which were added in update.
+// Elements are compared by value.
+func (m Visitor) visitAppendList(nodes walk.Sources) (*yaml.RNode, error) {
+	if nodes.Updated().IsTaggedNull() || nodes.Dest().IsTaggedNull() {
+		// explicitly cleared from either dest or update
+		return walk.ClearNode, nil
+	}
+	if yaml.IsMissingOrNull(nodes.Updated()) && !yaml.IsMissingOrNull(nodes.Origin()) {
+		// implicitly cleared from update
+		return walk.ClearNode, nil
+	}
+	if yaml.IsMissingOrNull(nodes.Dest()) {
+		// missing from dest, use the elements added in update
+		return nodes.Updated(), nil
+	}
+
+	origin, err := elementStrings(nodes.Origin())
+	if err != nil {
+		return nil, err
+	}
+	update, err := elementStrings(nodes.Updated())
+	if err != nil {
+		return nil, err
+	}
+	dest, err := elementStrings(nodes.Dest())
+	if err != nil {
+		return nil, err
+	}
+
+	var content []*yaml.Node
+	for i, s := range dest {
+		if contains(origin, s) && !contains(update, s) {
+			// removed in update
+			continue
+		}
+		content = append(content, nodes.Dest().YNode().Content[i])
+	}
+	for i, s := range update {
+		if contains(origin, s) || contains(dest, s) {
+			continue
+		}
+		// added in update
+		content = append(content, nodes.Updated().YNode().Content[i])
+	}
+	nodes.Dest().YNode().Content = content
+	return nodes.Dest(), nil
+}
+
+// elementStrings returns the elements of a list as strings.
+func elementStrings(rn *yaml.RNode) ([]string, error) {
+	if yaml.IsMissingOrNull(rn) {
+		return nil, nil
+	}
+	var result []string
+	for _, yn := range rn.YNode().Content {
+		s, err := yaml.NewRNode(yn).String()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func (m Visitor) VisitList(nodes walk.Sources, s *openapi.ResourceSchema, kind walk.ListKind) (*yaml.RNode, error) {
 	if kind == walk.AssociativeList {
 		return m.visitAList(nodes, s)
 	}
+	if kind == walk.AppendList {
+		return m.visitAppendList(nodes)
+	}
 	// non-associative list
 	return m.visitNAList(nodes)
 }
@@ -0,0 +1,555 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/internal/forked/github.com/go-yaml/yaml"
+)
+
+// Query returns all nodes matching a JSONPath-style expression, in document order.
+// It complements Lookup and PathGetter, which can only address a single node.
+//
+// The expression is a sequence of segments, optionally starting with "$":
+//   - .field or field -- the value of a mapping field
+//   - ['field.with.dots'] -- a quoted mapping field
+//   - [0], [-1] -- a list element by index, negative indexes count from the end
+//   - * or [*] -- every list element or mapping value
+//   - ..field -- the field at any depth
+//   - [?(@.name=='app')] -- every list element or mapping value matching a filter
+//   - [name=app], [=value] -- the list element matching a PathGetter style key
+//
+// Filters compare relative paths starting with "@" against other paths or literals
+// using ==, !=, <, <=, > and >=, and may be combined with && and ||.  A filter
+// consisting only of a relative path matches elements where the path exists.
+//
+// Examples:
+//   - spec.template.spec.containers[?(@.name=='app')].image
+//   - spec.template.spec.containers[*].ports[?(@.containerPort>=8000)]
+//   - metadata.annotations['config.kubernetes.io/local-config']
+//   - ..image
+func (rn *RNode) Query(expr string) ([]*RNode, error) {
+	segments, err := parseQuery(expr)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "invalid query %q", expr)
+	}
+	matches := []queryMatch{{node: rn.YNode(), fieldPath: rn.FieldPath()}}
+	for _, s := range segments {
+		if matches, err = s.apply(matches); err != nil {
+			return nil, err
+		}
+	}
+	var result []*RNode
+	for _, m := range matches {
+		n := NewRNode(m.node)
+		n.AppendToFieldPath(m.fieldPath...)
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// queryMatch is a node matched by a query and its field path.
+type queryMatch struct {
+	node      *yaml.Node
+	fieldPath []string
+}
+
+type querySegmentKind int
+
+const (
+	queryField querySegmentKind = iota
+	queryWildcard
+	queryIndex
+	queryFilter
+)
+
+// querySegment is a single step of a query.
+type querySegment struct {
+	kind      querySegmentKind
+	recursive bool
+	name      string
+	index     int
+	filter    queryExpr
+}
+
+// apply returns the nodes selected by the segment from each of the matches.
+func (s querySegment) apply(matches []queryMatch) ([]queryMatch, error) {
+	var result []queryMatch
+	for _, m := range matches {
+		candidates := []queryMatch{m}
+		if s.recursive {
+			candidates = descendants(m, nil)
+		}
+		for _, c := range candidates {
+			selected, err := s.selectFrom(c)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, selected...)
+		}
+	}
+	return result, nil
+}
+
+func (s querySegment) selectFrom(m queryMatch) ([]queryMatch, error) {
+	node := resolveAlias(m.node)
+	if node == nil {
+		return nil, nil
+	}
+	switch s.kind {
+	case queryField:
+		if node.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == s.name {
+				return []queryMatch{child(m, node.Content[i].Value, node.Content[i+1])}, nil
+			}
+		}
+	case queryIndex:
+		if node.Kind != yaml.SequenceNode {
+			return nil, nil
+		}
+		i := s.index
+		if i < 0 {
+			i += len(node.Content)
+		}
+		if i >= 0 && i < len(node.Content) {
+			return []queryMatch{child(m, "", node.Content[i])}, nil
+		}
+	case queryWildcard, queryFilter:
+		var result []queryMatch
+		for _, c := range children(m) {
+			if s.kind == queryFilter {
+				ok, err := s.filter.matches(c)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+			result = append(result, c)
+		}
+		return result, nil
+	}
+	return nil, nil
+}
+
+// children returns the values of a mapping or the elements of a sequence.
+func children(m queryMatch) []queryMatch {
+	node := resolveAlias(m.node)
+	var result []queryMatch
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			result = append(result, child(m, node.Content[i].Value, node.Content[i+1]))
+		}
+	case yaml.SequenceNode:
+		for i := range node.Content {
+			result = append(result, child(m, "", node.Content[i]))
+		}
+	}
+	return result
+}
+
+// descendants returns m and all nodes below it in document order.
+func descendants(m queryMatch, result []queryMatch) []queryMatch {
+	result = append(result, m)
+	for _, c := range children(m) {
+		result = descendants(c, result)
+	}
+	return result
+}
+
+func child(parent queryMatch, field string, node *yaml.Node) queryMatch {
+	fieldPath := parent.fieldPath
+	if field != "" {
+		fieldPath = append(append([]string{}, parent.fieldPath...), field)
+	}
+	return queryMatch{node: node, fieldPath: fieldPath}
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.AliasNode {
+		return node.Alias
+	}
+	return node
+}
+
+// parseQuery parses a query expression into segments.
+func parseQuery(expr string) ([]querySegment, error) {
+	p := &queryParser{s: strings.TrimSpace(expr)}
+	if strings.HasPrefix(p.s, "$") {
+		p.pos++
+	}
+	segments, err := p.parseSegments(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.s) {
+		return nil, errors.Errorf("unexpected %q at offset %d", p.s[p.pos:], p.pos)
+	}
+	return segments, nil
+}
+
+type queryParser struct {
+	s   string
+	pos int
+}
+
+// parseSegments parses segments until the end of the expression, or until the
+// end of a relative path if inFilter is set.
+func (p *queryParser) parseSegments(inFilter bool) ([]querySegment, error) {
+	var segments []querySegment
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case strings.HasPrefix(p.s[p.pos:], ".."):
+			p.pos += 2
+			s, err := p.parseNext()
+			if err != nil {
+				return nil, err
+			}
+			s.recursive = true
+			segments = append(segments, s)
+		case c == '.':
+			p.pos++
+			s, err := p.parseNext()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, s)
+		case c == '[':
+			s, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, s)
+		case inFilter:
+			return segments, nil
+		case len(segments) == 0:
+			s, err := p.parseNext()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, s)
+		default:
+			return nil, errors.Errorf("unexpected %q at offset %d", c, p.pos)
+		}
+	}
+	return segments, nil
+}
+
+// parseNext parses the segment following a '.' or '..'
+func (p *queryParser) parseNext() (querySegment, error) {
+	if p.pos < len(p.s) && p.s[p.pos] == '[' {
+		return p.parseBracket()
+	}
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(".[ =!<>&|)", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	name := p.s[start:p.pos]
+	switch name {
+	case "":
+		return querySegment{}, errors.Errorf("missing field name at offset %d", start)
+	case "*":
+		return querySegment{kind: queryWildcard}, nil
+	default:
+		return querySegment{kind: queryField, name: name}, nil
+	}
+}
+
+// parseBracket parses a bracketed segment
+func (p *queryParser) parseBracket() (querySegment, error) {
+	start := p.pos
+	end, err := p.matchingBracket()
+	if err != nil {
+		return querySegment{}, err
+	}
+	content := strings.TrimSpace(p.s[start+1 : end])
+	p.pos = end + 1
+
+	switch {
+	case content == "*":
+		return querySegment{kind: queryWildcard}, nil
+	case strings.HasPrefix(content, "?(") && strings.HasSuffix(content, ")"):
+		fp := &queryParser{s: content[2 : len(content)-1]}
+		e, err := fp.parseOr()
+		if err != nil {
+			return querySegment{}, err
+		}
+		fp.skipSpace()
+		if fp.pos != len(fp.s) {
+			return querySegment{}, errors.Errorf("unexpected %q in filter %q", fp.s[fp.pos:], content)
+		}
+		return querySegment{kind: queryFilter, filter: e}, nil
+	case isQuoted(content):
+		return querySegment{kind: queryField, name: content[1 : len(content)-1]}, nil
+	}
+	if i, err := strconv.Atoi(content); err == nil {
+		return querySegment{kind: queryIndex, index: i}, nil
+	}
+	if name, value, err := SplitIndexNameValue("[" + content + "]"); err == nil {
+		// PathGetter style list entry
+		left := queryExpr{path: []querySegment{}}
+		if name != "" {
+			left.path = []querySegment{{kind: queryField, name: name}}
+		}
+		return querySegment{kind: queryFilter, filter: queryExpr{
+			op: "==", left: &left, right: &queryExpr{literal: &value},
+		}}, nil
+	}
+	return querySegment{}, errors.Errorf("invalid bracket expression %q", content)
+}
+
+// matchingBracket returns the offset of the ']' closing the '[' at p.pos,
+// skipping over quoted strings and nested brackets.
+func (p *queryParser) matchingBracket() (int, error) {
+	depth := 0
+	var quote byte
+	for i := p.pos; i < len(p.s); i++ {
+		c := p.s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, errors.Errorf("unterminated '[' at offset %d", p.pos)
+}
+
+// queryExpr is a filter expression.  It is either a binary operation,
+// a relative path, or a literal.
+type queryExpr struct {
+	op          string
+	left, right *queryExpr
+	path        []querySegment
+	literal     *string
+}
+
+func (p *queryParser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *queryParser) consume(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.s[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) parseOr() (queryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return queryExpr{}, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return queryExpr{}, err
+		}
+		l := left
+		left = queryExpr{op: "||", left: &l, right: &right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryExpr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return queryExpr{}, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return queryExpr{}, err
+		}
+		l := left
+		left = queryExpr{op: "&&", left: &l, right: &right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseComparison() (queryExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return queryExpr{}, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return queryExpr{}, err
+			}
+			return queryExpr{op: op, left: &left, right: &right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseOperand() (queryExpr, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return queryExpr{}, errors.Errorf("unexpected end of filter %q", p.s)
+	}
+	c := p.s[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return queryExpr{}, err
+		}
+		if !p.consume(")") {
+			return queryExpr{}, errors.Errorf("missing ')' in filter %q", p.s)
+		}
+		return e, nil
+	case c == '@':
+		p.pos++
+		path, err := p.parseSegments(true)
+		if err != nil {
+			return queryExpr{}, err
+		}
+		return queryExpr{path: path}, nil
+	case c == '\'' || c == '"':
+		end := strings.IndexByte(p.s[p.pos+1:], c)
+		if end < 0 {
+			return queryExpr{}, errors.Errorf("unterminated string in filter %q", p.s)
+		}
+		value := p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return queryExpr{literal: &value}, nil
+	default:
+		start := p.pos
+		for p.pos < len(p.s) && !strings.ContainsRune(" =!<>&|)", rune(p.s[p.pos])) {
+			p.pos++
+		}
+		value := p.s[start:p.pos]
+		if value == "" {
+			return queryExpr{}, errors.Errorf("unexpected %q in filter %q", c, p.s)
+		}
+		return queryExpr{literal: &value}, nil
+	}
+}
+
+// matches returns true if the filter expression matches the node.
+func (e queryExpr) matches(m queryMatch) (bool, error) {
+	switch e.op {
+	case "":
+		if e.literal != nil {
+			return true, nil
+		}
+		matches, err := e.evalPath(m)
+		return len(matches) > 0, err
+	case "&&", "||":
+		l, err := e.left.matches(m)
+		if err != nil || (e.op == "&&" && !l) || (e.op == "||" && l) {
+			return l, err
+		}
+		return e.right.matches(m)
+	}
+	left, err := e.left.values(m)
+	if err != nil {
+		return false, err
+	}
+	right, err := e.right.values(m)
+	if err != nil {
+		return false, err
+	}
+	// the comparison matches if it is true for any pair of values
+	for _, l := range left {
+		for _, r := range right {
+			if compareQueryValues(e.op, l, r) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// values returns the scalar values of a relative path or literal.
+func (e queryExpr) values(m queryMatch) ([]string, error) {
+	if e.literal != nil {
+		return []string{*e.literal}, nil
+	}
+	matches, err := e.evalPath(m)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, m := range matches {
+		if n := resolveAlias(m.node); n != nil && n.Kind == yaml.ScalarNode {
+			result = append(result, n.Value)
+		}
+	}
+	return result, nil
+}
+
+// evalPath returns the nodes matching a relative path.
+func (e queryExpr) evalPath(m queryMatch) ([]queryMatch, error) {
+	matches := []queryMatch{m}
+	var err error
+	for _, s := range e.path {
+		if matches, err = s.apply(matches); err != nil {
+			return nil, err
+		}
+	}
+	return matches, nil
+}
+
+// compareQueryValues compares numerically if both values are numbers, otherwise
+// as strings.
+func compareQueryValues(op, l, r string) bool {
+	cmp := strings.Compare(l, r)
+	lf, lerr := strconv.ParseFloat(l, 64)
+	rf, rerr := strconv.ParseFloat(r, 64)
+	if lerr == nil && rerr == nil {
+		switch {
+		case lf < rf:
+			cmp = -1
+		case lf > rf:
+			cmp = 1
+		default:
+			cmp = 0
+		}
+	}
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+func isQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0]
+}
@@ -0,0 +1,161 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const queryTestInput = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  annotations:
+    config.kubernetes.io/local-config: "true"
+spec:
+  replicas: 3
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: app
+        image: app:v1
+        args: [--verbose, --port=8080]
+        ports:
+        - containerPort: 8080
+          protocol: TCP
+        - containerPort: 9090
+          protocol: UDP
+      - name: sidecar
+        image: proxy:v2
+        ports:
+        - containerPort: 15000
+`
+
+func TestRNode_Query(t *testing.T) {
+	testCases := []struct {
+		query     string
+		expected  []string
+		fieldPath []string
+	}{
+		{
+			query:     "spec.template.spec.containers[?(@.name=='app')].image",
+			expected:  []string{"app:v1"},
+			fieldPath: []string{"spec", "template", "spec", "containers", "image"},
+		},
+		{
+			query:    "$.spec.template.spec.containers[*].name",
+			expected: []string{"app", "sidecar"},
+		},
+		{
+			query:    "spec.template.spec.containers.*.name",
+			expected: []string{"app", "sidecar"},
+		},
+		{
+			query:    "spec.template.spec.containers[-1].name",
+			expected: []string{"sidecar"},
+		},
+		{
+			query:    "spec.template.spec.containers[0].args[1]",
+			expected: []string{"--port=8080"},
+		},
+		{
+			query:    "spec.template.spec.containers[5].name",
+			expected: nil,
+		},
+		{
+			query:    "spec.template.spec.containers[name=sidecar].image",
+			expected: []string{"proxy:v2"},
+		},
+		{
+			query:    "spec.template.spec.containers[0].args[=--verbose]",
+			expected: []string{"--verbose"},
+		},
+		{
+			query:    "spec.template.spec.containers[0].args[?(@ != '--verbose')]",
+			expected: []string{"--port=8080"},
+		},
+		{
+			query:    "metadata.annotations['config.kubernetes.io/local-config']",
+			expected: []string{"true"},
+		},
+		{
+			query:    "..image",
+			expected: []string{"busybox", "app:v1", "proxy:v2"},
+		},
+		{
+			query:    "spec..containerPort",
+			expected: []string{"8080", "9090", "15000"},
+		},
+		{
+			query:    "spec.template.spec.containers[*].ports[?(@.containerPort >= 9000)].containerPort",
+			expected: []string{"9090", "15000"},
+		},
+		{
+			query:    "spec.template.spec.containers[*].ports[?(@.containerPort < 9000 || @.protocol == 'UDP')].containerPort",
+			expected: []string{"8080", "9090"},
+		},
+		{
+			query:    "spec.template.spec.containers[?(@.args && @.name == \"app\")].name",
+			expected: []string{"app"},
+		},
+		{
+			query:    "spec.template.spec.containers[?(@.ports[?(@.protocol)])].name",
+			expected: []string{"app"},
+		},
+		{
+			query:    "spec[?(@ > 2)]",
+			expected: []string{"3"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			rn := MustParse(queryTestInput)
+			nodes, err := rn.Query(tc.query)
+			require.NoError(t, err)
+			var values []string
+			for _, n := range nodes {
+				values = append(values, n.YNode().Value)
+			}
+			assert.Equal(t, tc.expected, values)
+			if tc.fieldPath != nil {
+				assert.Equal(t, tc.fieldPath, nodes[0].FieldPath())
+			}
+		})
+	}
+}
+
+func TestRNode_Query_modify(t *testing.T) {
+	rn := MustParse(queryTestInput)
+	nodes, err := rn.Query("spec.template.spec.containers[?(@.name=='app')]")
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	require.NoError(t, nodes[0].PipeE(SetField("image", NewStringRNode("app:v2"))))
+
+	image, err := rn.Pipe(Lookup("spec", "template", "spec", "containers", "[name=app]", "image"))
+	require.NoError(t, err)
+	assert.Equal(t, "app:v2", image.YNode().Value)
+}
+
+func TestRNode_Query_errors(t *testing.T) {
+	for _, query := range []string{
+		"spec.",
+		"spec[0",
+		"spec[?(@.a ==)]",
+		"spec[?(@.a == 'b)]",
+		"spec[?(@.a) foo]",
+		"spec[foo]",
+		"spec[?(!@.name)]",
+	} {
+		t.Run(query, func(t *testing.T) {
+			_, err := MustParse(queryTestInput).Query(query)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -186,6 +186,8 @@ func (l *Walker) setAssociativeSequenceElements(valuesList [][]string, keys []st
 			Schema:                schema,
 			Sources:               l.elementValueList(validKeys, validValues),
 			MergeOptions:          l.MergeOptions,
+			ListStrategy:          l.ListStrategy,
+			Path:                  l.Path,
 		}.Walk()
 		if err != nil {
 			return nil, err
@@ -0,0 +1,86 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package walk
+
+import (
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ListStrategy is the strategy used to merge the elements of a list.
+type ListStrategy string
+
+const (
+	// ListStrategyDefault merges the list as specified by the openapi schema,
+	// inferring the strategy if InferAssociativeLists is set.
+	ListStrategyDefault ListStrategy = ""
+
+	// ListStrategyAssociative merges the list elements with the same merge key values.
+	ListStrategyAssociative ListStrategy = "associative"
+
+	// ListStrategyAppend keeps the elements of the destination list, and adds the
+	// elements of the other sources which it doesn't contain yet.
+	ListStrategyAppend ListStrategy = "append"
+
+	// ListStrategyReplace replaces the list as a whole.
+	ListStrategyReplace ListStrategy = "replace"
+)
+
+// ListStrategyFunc returns the strategy and merge keys to use for the list at path,
+// or ListStrategyDefault to merge the list as specified by the schema.
+// The path contains the field names leading to the list, list elements are not
+// part of the path.
+type ListStrategyFunc func(path []string) (ListStrategy, []string)
+
+// listStrategy returns the strategy configured for the list at l.Path.
+func (l Walker) listStrategy() (ListStrategy, []string) {
+	if l.ListStrategy == nil {
+		return ListStrategyDefault, nil
+	}
+	return l.ListStrategy(l.Path)
+}
+
+// associativeSchema returns a copy of s which merges the list by keys.  If keys
+// is empty, lists of scalars are merged as sets and the merge key of other lists
+// is inferred from their elements.
+func (l Walker) associativeSchema(s *openapi.ResourceSchema, keys []string) *openapi.ResourceSchema {
+	var sc spec.Schema
+	if s != nil && s.Schema != nil {
+		sc = *s.Schema
+	}
+	sc.Type = spec.StringOrArray{"array"}
+	sc.Extensions = spec.Extensions{}
+	if s != nil && s.Schema != nil {
+		for k, v := range s.Schema.Extensions {
+			sc.Extensions[k] = v
+		}
+	}
+	delete(sc.Extensions, "x-kubernetes-patch-strategy")
+	delete(sc.Extensions, "x-kubernetes-patch-merge-key")
+	delete(sc.Extensions, "x-kubernetes-list-map-keys")
+	if len(keys) > 0 || l.isScalarList() {
+		sc.Extensions["x-kubernetes-patch-strategy"] = "merge"
+	}
+	if len(keys) > 0 {
+		var mapKeys []interface{}
+		for _, k := range keys {
+			mapKeys = append(mapKeys, k)
+		}
+		sc.Extensions["x-kubernetes-patch-merge-key"] = keys[0]
+		sc.Extensions["x-kubernetes-list-map-keys"] = mapKeys
+	}
+	return &openapi.ResourceSchema{Schema: &sc}
+}
+
+// isScalarList returns true if the elements of the lists are scalars.
+func (l Walker) isScalarList() bool {
+	for _, s := range l.Sources {
+		if yaml.IsMissingOrNull(s) || len(s.YNode().Content) == 0 {
+			continue
+		}
+		return s.YNode().Content[0].Kind == yaml.ScalarNode
+	}
+	return false
+}
@@ -69,6 +69,7 @@ func (l Walker) walkMap() (*yaml.RNode, error) {
 			Schema:                s,
 			Sources:               fv,
 			MergeOptions:          l.MergeOptions,
+			ListStrategy:          l.ListStrategy,
 			Path:                  append(l.Path, key)}.Walk()
 		if err != nil {
 			return nil, err
@@ -11,3 +11,9 @@ import (
 func (l Walker) walkNonAssociativeSequence() (*yaml.RNode, error) {
 	return l.VisitList(l.Sources, l.Schema, NonAssociateList)
 }
+
+// walkAppendSequence returns the value of VisitList for a list whose
+// elements are appended
+func (l Walker) walkAppendSequence() (*yaml.RNode, error) {
+	return l.VisitList(l.Sources, l.Schema, AppendList)
+}
@@ -13,9 +13,17 @@ type ListKind int32
 const (
 	AssociativeList ListKind = 1 + iota
 	NonAssociateList
+	// AppendList is a list configured with ListStrategyAppend, whose
+	// elements are appended to the destination list.
+	AppendList
 )
 
-// Visitor is invoked by walk with source and destination node pairs
+// Visitor is invoked by walk with source and destination node pairs.
+//
+// VisitList is invoked with the ListKind of the list: AssociativeList or
+// NonAssociateList as specified by the schema, or AppendList for the lists
+// the ListStrategy of the Walker configures to be appended.  Walkers without
+// a ListStrategy never invoke VisitList with AppendList.
 type Visitor interface {
 	VisitMap(Sources, *openapi.ResourceSchema) (*yaml.RNode, error)
 
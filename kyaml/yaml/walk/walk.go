@@ -39,6 +39,10 @@ type Walker struct {
 
 	// MergeOptions is a struct to store options for merge
 	MergeOptions yaml.MergeOptions
+
+	// ListStrategy if set returns the strategy to merge the list at a path,
+	// overriding the strategy from the schema.
+	ListStrategy ListStrategyFunc
 }
 
 // Kind returns the kind of the first non-null node in Sources.
@@ -67,6 +71,15 @@ func (l Walker) Walk() (*yaml.RNode, error) {
 		if err := yaml.ErrorIfAnyInvalidAndNonNull(yaml.SequenceNode, l.Sources...); err != nil {
 			return nil, err
 		}
+		switch strategy, keys := l.listStrategy(); strategy {
+		case ListStrategyAssociative:
+			l.Schema = l.associativeSchema(l.Schema, keys)
+			return l.walkAssociativeSequence()
+		case ListStrategyAppend:
+			return l.walkAppendSequence()
+		case ListStrategyReplace:
+			return l.walkNonAssociativeSequence()
+		}
 		// AssociativeSequence means the items in the sequence are associative. They can be merged
 		// according to merge key.
 		if schema.IsAssociative(l.Schema, l.Sources, l.InferAssociativeLists) {
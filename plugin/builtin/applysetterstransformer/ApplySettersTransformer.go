@@ -0,0 +1,30 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate pluginator
+package main
+
+import (
+	"sigs.k8s.io/kustomize/api/filters/applysetters"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/yaml"
+)
+
+// Set the fields marked with setter comments, e.g.
+// `image: nginx:1.21 # kustomize-set: ${image}:${tag}`,
+// to the values of the setters in the data of the config.
+type plugin struct {
+	Data map[string]string `json:"data,omitempty" yaml:"data,omitempty"`
+}
+
+var KustomizePlugin plugin //nolint:gochecknoglobals
+
+func (p *plugin) Config(
+	_ *resmap.PluginHelpers, c []byte) (err error) {
+	p.Data = nil
+	return yaml.Unmarshal(c, p)
+}
+
+func (p *plugin) Transform(m resmap.ResMap) error {
+	return m.ApplyFilter(applysetters.Filter{Setters: p.Data})
+}
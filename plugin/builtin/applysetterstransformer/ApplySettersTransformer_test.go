@@ -0,0 +1,53 @@
+// Copyright 2023 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package main_test
+
+import (
+	"testing"
+
+	kusttest_test "sigs.k8s.io/kustomize/api/testutils/kusttest"
+)
+
+func TestApplySettersTransformer(t *testing.T) {
+	th := kusttest_test.MakeEnhancedHarness(t).
+		PrepBuiltin("ApplySettersTransformer")
+	defer th.Reset()
+
+	rm := th.LoadAndRunTransformer(`
+apiVersion: builtin
+kind: ApplySettersTransformer
+metadata:
+  name: notImportantHere
+data:
+  image: nginx
+  tag: "1.21"
+  replicas: "3"
+`, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 1 # kustomize-set: ${replicas}
+  template:
+    spec:
+      containers:
+      - name: app
+        image: nginx:1.0 # kustomize-set: ${image}:${tag}
+`)
+
+	th.AssertActualEqualsExpected(rm, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - image: nginx:1.21
+        name: app
+`)
+}
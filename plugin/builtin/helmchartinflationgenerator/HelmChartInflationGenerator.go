@@ -63,6 +63,13 @@ func (p *plugin) Config(
 	if err = yaml.Unmarshal(config, p); err != nil {
 		return
 	}
+	// the charts may target a cluster version given on the command line
+	if p.KubeVersion == "" {
+		p.KubeVersion = h.GeneralConfig().HelmConfig.KubeVersion
+	}
+	if len(p.ApiVersions) == 0 {
+		p.ApiVersions = h.GeneralConfig().HelmConfig.ApiVersions
+	}
 	return p.validateArgs()
 }
 
@@ -155,6 +162,7 @@ func (p *plugin) runHelmCommand(
 		fmt.Sprintf("HELM_CONFIG_HOME=%s", p.ConfigHome),
 		fmt.Sprintf("HELM_CACHE_HOME=%s/.cache", p.ConfigHome),
 		fmt.Sprintf("HELM_DATA_HOME=%s/.data", p.ConfigHome)}
+	env = append(env, p.h.GeneralConfig().HelmConfig.HTTP.ProxyEnv()...)
 	cmd.Env = append(os.Environ(), env...)
 	err := cmd.Run()
 	if err != nil {
@@ -242,7 +250,11 @@ func (p *plugin) Generate() (rm resmap.ResMap, err error) {
 			return nil, fmt.Errorf(
 				"no repo specified for pull, no chart found at '%s'", path)
 		}
-		if _, err := p.runHelmCommand(p.pullCommand()); err != nil {
+		args, err := p.pullCommand()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.runHelmCommand(args); err != nil {
 			return nil, err
 		}
 	}
@@ -282,7 +294,7 @@ func (p *plugin) Generate() (rm resmap.ResMap, err error) {
 	return nil, fmt.Errorf("could not parse bytes into resource map: %w", resMapErr)
 }
 
-func (p *plugin) pullCommand() []string {
+func (p *plugin) pullCommand() ([]string, error) {
 	args := []string{
 		"pull",
 		"--untar",
@@ -292,7 +304,18 @@ func (p *plugin) pullCommand() []string {
 	if p.Version != "" {
 		args = append(args, "--version", p.Version)
 	}
-	return args
+	httpConfig := p.h.GeneralConfig().HelmConfig.HTTP
+	if len(httpConfig.CAFiles) > 0 {
+		if err := p.establishTmpDir(); err != nil {
+			return nil, errors.WrapPrefixf(err, "unable to create tmp dir for the CA bundle")
+		}
+		caFile, err := httpConfig.WriteCABundle(p.tmpDir)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--ca-file", caFile)
+	}
+	return args, nil
 }
 
 // chartExistsLocally will return true if the chart does exist in